@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -60,7 +61,7 @@ func registerAgent(
 	}
 
 	payload, _ := json.Marshal(body)
-	resp, err := http.Post(serverURL+"/api/v1/agents/register", "application/json", bytes.NewBuffer(payload))
+	resp, err := httpClient.Post(serverURL+"/api/v1/agents/register", "application/json", bytes.NewBuffer(payload))
 	if err != nil {
 		return nil, fmt.Errorf("registration request failed: %w", err)
 	}
@@ -77,10 +78,14 @@ func registerAgent(
 		SessionToken   string `json:"session_token"`
 		SessionExpires string `json:"session_expires"`
 		ServerPubKey   string `json:"server_public_key"`
+		Approval       string `json:"approval"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("decode registration response: %w", err)
 	}
+	if result.Approval == "pending" {
+		log.Printf("⏳ Registered, awaiting approval: reports are refused until an admin approves this agent")
+	}
 
 	expires, _ := time.Parse(time.RFC3339, result.SessionExpires)
 
@@ -118,7 +123,7 @@ func authenticate(
 	}
 
 	payload, _ := json.Marshal(body)
-	resp, err := http.Post(state.ServerURL+"/api/v1/agents/auth", "application/json", bytes.NewBuffer(payload))
+	resp, err := httpClient.Post(state.ServerURL+"/api/v1/agents/auth", "application/json", bytes.NewBuffer(payload))
 	if err != nil {
 		return nil, fmt.Errorf("auth request failed: %w", err)
 	}
@@ -1,17 +1,24 @@
 package main
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"io"
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"vigil/cmd/agent/led"
+	"vigil/cmd/agent/zfs"
 )
 
 // startCommandServer runs a minimal HTTP server that accepts commands
 // from the Vigil server (proxied through the dashboard). This is
 // optional — only started when --listen or AGENT_LISTEN is set.
-func startCommandServer(addr string, ledCtrl *led.Controller) {
+func startCommandServer(addr string, ledCtrl *led.Controller, zfsAvailable, allowZFSClear bool) {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, _ *http.Request) {
@@ -20,6 +27,7 @@ func startCommandServer(addr string, ledCtrl *led.Controller) {
 	})
 
 	mux.HandleFunc("POST /api/identify", handleIdentify(ledCtrl))
+	mux.HandleFunc("POST /api/zfs/clear", handleZFSClear(zfsAvailable, allowZFSClear))
 
 	server := &http.Server{Addr: addr, Handler: mux}
 	if err := server.ListenAndServe(); err != nil {
@@ -63,3 +71,79 @@ func handleIdentify(ledCtrl *led.Controller) http.HandlerFunc {
 		json.NewEncoder(w).Encode(map[string]string{"status": "ok", "output": output}) //nolint:errcheck
 	}
 }
+
+// commandMaxAge bounds how old a signed command's timestamp may be, so a
+// captured request can't be replayed later.
+const commandMaxAge = 5 * time.Minute
+
+// handleZFSClear runs `zpool clear`. The listener has no authentication of
+// its own, so this is off unless the agent runs with --allow-zfs-clear, and
+// with a report HMAC secret the request must carry the server's signature
+// and a recent timestamp.
+func handleZFSClear(zfsAvailable, allowed bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !zfsAvailable {
+			http.Error(w, `{"error":"ZFS not available on this host"}`, http.StatusNotImplemented)
+			return
+		}
+		if !allowed {
+			http.Error(w, `{"error":"zpool clear is disabled on this agent (start it with --allow-zfs-clear)"}`, http.StatusForbidden)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+		if err != nil {
+			http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+			return
+		}
+		var req struct {
+			Pool      string `json:"pool"`
+			Device    string `json:"device"`
+			Timestamp int64  `json:"ts"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+			return
+		}
+		if len(reportHMACSecret) > 0 {
+			age := time.Since(time.Unix(req.Timestamp, 0))
+			if !validCommandSignature(body, r.Header.Get("X-Vigil-Signature")) || age > commandMaxAge || age < -commandMaxAge {
+				log.Printf("🚫 zpool clear rejected: invalid or stale signature")
+				http.Error(w, `{"error":"invalid command signature"}`, http.StatusForbidden)
+				return
+			}
+		}
+		if req.Pool == "" {
+			http.Error(w, `{"error":"pool is required"}`, http.StatusBadRequest)
+			return
+		}
+
+		log.Printf("🧹 zpool clear: pool=%s device=%s", req.Pool, req.Device)
+		output, err := zfs.ClearErrors(req.Pool, req.Device)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error(), "output": output}) //nolint:errcheck
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok", "output": output}) //nolint:errcheck
+	}
+}
+
+// validCommandSignature checks a "sha256=<hex>" HMAC of body, keyed with
+// the report HMAC secret, in constant time.
+func validCommandSignature(body []byte, header string) bool {
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, reportHMACSecret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// deltas implements --delta-reports: after a full report, drives whose
+// attributes haven't moved by more than the threshold are sent as stubs
+// (serial number and temperature) that the server fills in from the last
+// report it stored. Only the report loop touches it.
+var deltas deltaTracker
+
+// deltaTracker remembers what each drive looked like when it was last sent
+// in full.
+type deltaTracker struct {
+	enabled   bool
+	fullEvery int     // every Nth report is full, so the server resyncs
+	threshold float64 // raw-value change that makes a drive count as changed
+
+	sent      map[string]driveSnapshot // by serial, as of its last full send
+	sinceFull int                      // delta reports since the last full one
+	needFull  bool                     // the server asked for a full report
+}
+
+// driveSnapshot is the part of a drive's SMART data a delta is judged on.
+// Temperature is left out: stubs carry it on every report anyway.
+type driveSnapshot struct {
+	device   string
+	counters map[string]float64
+}
+
+// requestFull makes the next report a full one.
+func (t *deltaTracker) requestFull() {
+	t.needFull = true
+}
+
+// prepare returns the drives to send, with unchanged ones reduced to stubs
+// unless this report is due to be full, and a commit func to call once
+// the server has accepted the report.
+func (t *deltaTracker) prepare(drives []map[string]interface{}) ([]map[string]interface{}, func()) {
+	if !t.enabled {
+		return drives, func() {}
+	}
+
+	full := t.sent == nil || t.needFull || t.sinceFull >= t.fullEvery-1
+	out := make([]map[string]interface{}, 0, len(drives))
+	current := make(map[string]driveSnapshot, len(drives))
+	for _, d := range drives {
+		serial := driveSerial(d)
+		if serial == "" {
+			out = append(out, d)
+			continue
+		}
+		snap := snapshotDrive(d)
+		prev, seen := t.sent[serial]
+		if full || !seen || prev.changed(snap, t.threshold) {
+			current[serial] = snap
+			out = append(out, d)
+			continue
+		}
+		stub := map[string]interface{}{"serial_number": serial, "_unchanged": true}
+		if temp, ok := d["temperature"]; ok {
+			stub["temperature"] = temp
+		}
+		out = append(out, stub)
+	}
+
+	return out, func() {
+		if full {
+			t.sent, t.sinceFull, t.needFull = current, 0, false
+			return
+		}
+		for serial, snap := range current {
+			t.sent[serial] = snap
+		}
+		t.sinceFull++
+	}
+}
+
+// fullCount returns how many of drives were sent in full rather than as
+// stubs.
+func (t *deltaTracker) fullCount(drives []map[string]interface{}) int {
+	n := 0
+	for _, d := range drives {
+		if d["_unchanged"] != true {
+			n++
+		}
+	}
+	return n
+}
+
+// usageCounters tick with normal use (hours, power cycles, bytes moved) and
+// say nothing about the drive's health, so they are left out of snapshots;
+// the server still gets them with every full report.
+var usageCounters = map[string]bool{
+	"ata:9":                     true, // power-on hours
+	"ata:12":                    true, // power cycle count
+	"ata:241":                   true, // total LBAs written
+	"ata:242":                   true, // total LBAs read
+	"nvme:data_units_read":      true,
+	"nvme:data_units_written":   true,
+	"nvme:host_reads":           true, // host read commands
+	"nvme:host_writes":          true, // host write commands
+	"nvme:controller_busy_time": true,
+	"nvme:power_cycles":         true,
+	"nvme:power_on_hours":       true,
+}
+
+// failureCounters count media failures: any change sends the drive in full
+// whatever the threshold.
+var failureCounters = map[string]bool{
+	"failed":                true, // SMART self-assessment
+	"ata:5":                 true, // reallocated sectors
+	"ata:187":               true, // reported uncorrectable errors
+	"ata:197":               true, // current pending sectors
+	"ata:198":               true, // offline uncorrectable sectors
+	"nvme:media_errors":     true,
+	"nvme:critical_warning": true,
+}
+
+// changed reports whether cur differs from s: another device path, a
+// counter appearing or disappearing, a failure counter moving at all, or
+// another one moving by more than threshold.
+func (s driveSnapshot) changed(cur driveSnapshot, threshold float64) bool {
+	if s.device != cur.device || len(s.counters) != len(cur.counters) {
+		return true
+	}
+	for k, v := range cur.counters {
+		old, ok := s.counters[k]
+		if !ok || v != old && (failureCounters[k] || math.Abs(v-old) > threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshotDrive collects the SMART self-assessment, ATA attribute raw
+// values and NVMe health log counters of a drive, minus temperatures and
+// usage counters.
+func snapshotDrive(d map[string]interface{}) driveSnapshot {
+	snap := driveSnapshot{counters: make(map[string]float64)}
+	if dev, ok := d["device"].(map[string]interface{}); ok {
+		snap.device, _ = dev["name"].(string)
+	}
+	if status, ok := d["smart_status"].(map[string]interface{}); ok {
+		if passed, ok := status["passed"].(bool); ok && !passed {
+			snap.counters["failed"] = 1
+		}
+	}
+	if attrs, ok := d["ata_smart_attributes"].(map[string]interface{}); ok {
+		table, _ := attrs["table"].([]interface{})
+		for _, a := range table {
+			attr, _ := a.(map[string]interface{})
+			id, _ := attr["id"].(float64)
+			if id == 190 || id == 194 { // airflow / drive temperature
+				continue
+			}
+			raw, _ := attr["raw"].(map[string]interface{})
+			key := fmt.Sprintf("ata:%d", int(id))
+			if v, ok := raw["value"].(float64); ok && !usageCounters[key] {
+				snap.counters[key] = v
+			}
+		}
+	}
+	if health, ok := d["nvme_smart_health_information_log"].(map[string]interface{}); ok {
+		for k, v := range health {
+			if f, ok := v.(float64); ok && k != "temperature" && !usageCounters["nvme:"+k] {
+				snap.counters["nvme:"+k] = f
+			}
+		}
+	}
+	return snap
+}
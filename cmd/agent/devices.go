@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"vigil/cmd/agent/smart"
+)
+
+// extraDevices are drives given explicitly with --device PATH:TYPE, for
+// drives behind RAID HBAs and bridges that smartctl --scan doesn't list
+// (megaraid, cciss, areca, some USB enclosures).
+var extraDevices deviceFlag
+
+// extraScanTypes are additional -d types passed to smartctl --scan on top
+// of the default scan (--scan-types sat,nvme).
+var extraScanTypes []string
+
+// deviceFlag collects repeated --device PATH:TYPE flags.
+type deviceFlag []smart.Device
+
+func (d *deviceFlag) String() string {
+	parts := make([]string, len(*d))
+	for i, dev := range *d {
+		parts[i] = dev.Name + ":" + dev.Type
+	}
+	return strings.Join(parts, " ")
+}
+
+func (d *deviceFlag) Set(v string) error {
+	dev, err := smart.ParseDeviceSpec(v)
+	if err != nil {
+		return err
+	}
+	*d = append(*d, dev)
+	return nil
+}
+
+// parseDeviceList parses the SMART_DEVICES environment variable form:
+// whitespace-separated specs, since the types themselves contain commas.
+func (d *deviceFlag) parseDeviceList(s string) error {
+	for _, spec := range strings.Fields(s) {
+		if err := d.Set(spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanAllDevices runs the default smartctl scan plus one per extra scan
+// type, dropping devices already found under the same name and type. A
+// failed extra scan is logged and skipped; only a failed default scan is
+// returned as an error.
+func scanAllDevices(ctx context.Context) ([]smart.Device, error) {
+	devices, err := smart.ScanDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(devices))
+	for _, dev := range devices {
+		seen[dev.Name+"|"+dev.Type] = true
+	}
+	for _, devType := range extraScanTypes {
+		found, err := smart.ScanDevicesOfType(ctx, devType)
+		if err != nil {
+			log.Printf("⚠️  Device scan (-d %s) failed: %v", devType, err)
+			continue
+		}
+		for _, dev := range found {
+			if key := dev.Name + "|" + dev.Type; !seen[key] {
+				seen[key] = true
+				devices = append(devices, dev)
+			}
+		}
+	}
+	return devices, nil
+}
+
+// rescanEvery is how many collection cycles one device scan is reused for
+// (--rescan-interval). 1 scans every cycle.
+var rescanEvery = 1
+
+// scanCache is the last successful device scan and the number of cycles
+// it has served. Only the report loop touches it.
+var scanCache struct {
+	devices []smart.Device
+	uses    int
+	valid   bool
+}
+
+// cachedScan returns the devices to read this cycle: the cached scan until
+// it has served rescanEvery cycles, then a fresh one. Scanning is slow on
+// some controllers and can wake sleeping drives, while the device set of
+// a stable host rarely changes.
+func cachedScan(ctx context.Context) ([]smart.Device, error) {
+	if scanCache.valid && scanCache.uses < rescanEvery {
+		scanCache.uses++
+		return scanCache.devices, nil
+	}
+	devices, err := scanAllDevices(ctx)
+	if err != nil {
+		invalidateScan()
+		return nil, err
+	}
+	scanCache.devices, scanCache.uses, scanCache.valid = devices, 1, true
+	return devices, nil
+}
+
+// invalidateScan makes the next cycle scan again, e.g. after a cached
+// device failed to read, which suggests the topology changed.
+func invalidateScan() {
+	scanCache.valid = false
+}
+
+// driveSerial returns the serial number smartctl reported for a drive.
+func driveSerial(data map[string]interface{}) string {
+	serial, _ := data["serial_number"].(string)
+	return serial
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"vigil/internal/reportpb"
+)
+
+// defaultGRPCPort is where --protocol grpc looks for the server when
+// --grpc-server isn't given; the server's GRPC_PORT must match.
+const defaultGRPCPort = "9081"
+
+// grpcReports carries reports instead of POST /api/report when the agent runs
+// with --protocol grpc. Registration and authentication stay on HTTP.
+var grpcReports *grpcReporter
+
+type grpcReporter struct {
+	client  reportpb.ReportServiceClient
+	timeout time.Duration
+}
+
+// configureGRPCReports sets up the gRPC report transport. addr defaults to
+// the --server host on defaultGRPCPort; TLS is used when the server URL is
+// https, like the HTTP client. The connection is made on the first report.
+func configureGRPCReports(addr, serverURL string, connectTimeout, requestTimeout time.Duration, insecureSkipVerify bool) error {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return fmt.Errorf("invalid server URL: %v", err)
+	}
+	if addr == "" {
+		addr = net.JoinHostPort(u.Hostname(), defaultGRPCPort)
+	}
+
+	creds := insecure.NewCredentials()
+	if u.Scheme == "https" {
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: insecureSkipVerify}) // #nosec G402 -- explicit opt-in via --insecure-skip-verify
+	}
+	conn, err := grpc.NewClient(addr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithConnectParams(grpc.ConnectParams{MinConnectTimeout: connectTimeout}),
+	)
+	if err != nil {
+		return err
+	}
+
+	grpcReports = &grpcReporter{client: reportpb.NewReportServiceClient(conn), timeout: requestTimeout}
+	log.Printf("✓ Reports:  gRPC to %s", addr)
+	return nil
+}
+
+// post is postReport over gRPC, with the same errors.
+func (g *grpcReporter) post(ctx context.Context, report DriveReport, sessionToken string) (int, error) {
+	msg, err := reportToProto(report)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal report: %v", err)
+	}
+
+	md := metadata.Pairs("authorization", "Bearer "+sessionToken)
+	if len(reportHMACSecret) > 0 {
+		// The server signs the same deterministic encoding to compare.
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal report: %v", err)
+		}
+		md.Set("x-vigil-signature", signReport(body))
+	}
+
+	ctx, cancel := context.WithTimeout(metadata.NewOutgoingContext(ctx, md), g.timeout)
+	defer cancel()
+	var header metadata.MD
+	resp, err := g.client.Submit(ctx, msg, grpc.Header(&header))
+
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.Unauthenticated:
+		return 0, errUnauthorized
+	case codes.PermissionDenied:
+		// Also how an agent awaiting approval is turned away
+		msg := status.Convert(err).Message()
+		if len(reportHMACSecret) > 0 && msg == "Invalid report signature" {
+			return 0, fmt.Errorf("server rejected report signature (check REPORT_HMAC_SECRET)")
+		}
+		return 0, fmt.Errorf("server refused report: %s", msg)
+	case codes.FailedPrecondition:
+		return 0, fmt.Errorf("server refused report: %s", status.Convert(err).Message())
+	case codes.Unavailable:
+		return 0, fmt.Errorf("connection failed: %v", err)
+	default:
+		return 0, fmt.Errorf("server returned %v", err)
+	}
+	if v := header.Get("x-vigil-full-report"); len(v) > 0 && v[0] == "true" {
+		deltas.requestFull()
+	}
+	return int(resp.GetReportIntervalSeconds()), nil
+}
+
+// reportToProto converts a report to its protobuf form. Drives and ZFS data
+// go through JSON so they arrive exactly as the JSON report would carry them.
+func reportToProto(report DriveReport) (*reportpb.DriveReport, error) {
+	msg := &reportpb.DriveReport{
+		SchemaVersion:   int32(report.SchemaVersion),
+		Hostname:        report.Hostname,
+		Timestamp:       timestamppb.New(report.Timestamp),
+		AgentVersion:    report.Version,
+		SmartctlVersion: report.Smartctl,
+		Labels:          report.Labels,
+		Maintenance:     report.Maintenance,
+	}
+	for _, d := range report.Drives {
+		s, err := toStruct(d)
+		if err != nil {
+			return nil, err
+		}
+		msg.Drives = append(msg.Drives, s)
+	}
+	if report.ZFS != nil {
+		s, err := toStruct(report.ZFS)
+		if err != nil {
+			return nil, err
+		}
+		msg.Zfs = s
+	}
+	if c := report.Capabilities; c != nil {
+		msg.Capabilities = &reportpb.Capabilities{
+			LedIdentify:  c.LEDIdentify,
+			LatencyProbe: c.LatencyProbe,
+			ZfsClear:     c.ZFSClear,
+			ListenAddr:   c.ListenAddr,
+		}
+	}
+	return msg, nil
+}
+
+func toStruct(v interface{}) (*structpb.Struct, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(m)
+}
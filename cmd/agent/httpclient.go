@@ -0,0 +1,48 @@
+package main
+
+import (
+	"crypto/tls"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// httpClient is used for every request to the server (registration, auth,
+// reports). configureHTTPClient replaces it at startup; the default here
+// only matters before flags are applied.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// configureHTTPClient builds the shared client. Proxies come from
+// HTTP_PROXY / HTTPS_PROXY / NO_PROXY. connectTimeout bounds the TCP dial
+// and TLS handshake; requestTimeout bounds a whole request including
+// reading the response.
+func configureHTTPClient(serverURL string, connectTimeout, requestTimeout time.Duration, insecureSkipVerify bool) {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   connectTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   connectTimeout,
+		ResponseHeaderTimeout: requestTimeout,
+		IdleConnTimeout:       90 * time.Second,
+		MaxIdleConns:          4,
+	}
+	if insecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402 -- explicit opt-in via --insecure-skip-verify
+		log.Println("⚠️  ================================================================")
+		log.Println("⚠️  TLS CERTIFICATE VERIFICATION IS DISABLED (--insecure-skip-verify)")
+		log.Println("⚠️  Reports and credentials can be intercepted. Use only for testing")
+		log.Println("⚠️  or with a self-signed server on a network you trust.")
+		log.Println("⚠️  ================================================================")
+	}
+	httpClient = &http.Client{Transport: transport, Timeout: requestTimeout}
+
+	if u, err := url.Parse(serverURL); err == nil {
+		if proxy, err := http.ProxyFromEnvironment(&http.Request{URL: u}); err == nil && proxy != nil {
+			log.Printf("✓ Proxy:    %s", proxy.Redacted())
+		}
+	}
+}
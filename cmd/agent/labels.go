@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// labelFlag collects repeated --label key=value flags into a map.
+type labelFlag map[string]string
+
+func (l labelFlag) String() string {
+	keys := make([]string, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + l[k]
+	}
+	return strings.Join(parts, ",")
+}
+
+func (l labelFlag) Set(v string) error {
+	key, value, ok := strings.Cut(v, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return fmt.Errorf("label must be key=value, got %q", v)
+	}
+	l[key] = strings.TrimSpace(value)
+	return nil
+}
+
+// parseLabelList parses a comma-separated "key=value,key=value" list (the
+// LABELS environment variable form) into l.
+func (l labelFlag) parseLabelList(s string) error {
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		if err := l.Set(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+// Package latency provides an optional, non-destructive read-latency probe
+// for drives via ioping. If ioping is not installed the probe is disabled
+// and callers simply omit latency data from the report.
+package latency
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultRequests is how many read requests each probe issues.
+const DefaultRequests = 10
+
+// Prober wraps the ioping binary. A nil Prober is safe to use — Available
+// reports false and Probe returns ErrUnavailable.
+type Prober struct {
+	path     string
+	requests int
+}
+
+// ErrUnavailable is returned when ioping is not installed on the host.
+var ErrUnavailable = fmt.Errorf("ioping not available on this host")
+
+// Result summarises one probe run. All times are in microseconds.
+type Result struct {
+	AvgUs   float64 `json:"avg_us"`
+	P99Us   float64 `json:"p99_us"`
+	MinUs   float64 `json:"min_us"`
+	MaxUs   float64 `json:"max_us"`
+	Samples int     `json:"samples"`
+}
+
+// Detect probes for ioping on the system PATH. Returns nil if not found.
+func Detect() *Prober {
+	path, err := exec.LookPath("ioping")
+	if err != nil {
+		return nil
+	}
+	return &Prober{path: path, requests: DefaultRequests}
+}
+
+// Available reports whether latency probing is possible on this host.
+func (p *Prober) Available() bool {
+	return p != nil && p.path != ""
+}
+
+// Probe issues a short burst of direct reads against device and summarises
+// the per-request latencies. ioping only reads, so this is safe on live disks.
+func (p *Prober) Probe(ctx context.Context, device string) (*Result, error) {
+	if !p.Available() {
+		return nil, ErrUnavailable
+	}
+
+	path := device
+	if !strings.HasPrefix(path, "/") {
+		path = "/dev/" + path
+	}
+
+	args := []string{"-c", strconv.Itoa(p.requests), "-i", "0.1", "-D", path}
+	cmd := exec.CommandContext(ctx, p.path, args...) // #nosec G204 -- path is from LookPath at startup
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ioping %s: %w", path, err)
+	}
+
+	samples := parseIoping(string(out))
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("ioping %s: no samples in output", path)
+	}
+	return Summarize(samples), nil
+}
+
+// reRequestTime matches the per-request "time=7.58 ms" field in ioping output.
+var reRequestTime = regexp.MustCompile(`time=([\d.]+)\s*(ns|us|µs|ms|s)\b`)
+
+// parseIoping extracts per-request latencies (µs) from ioping's default
+// output, skipping the warmup request.
+func parseIoping(out string) []float64 {
+	var samples []float64
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "(warmup)") {
+			continue
+		}
+		m := reRequestTime.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		v, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			continue
+		}
+		switch m[2] {
+		case "ns":
+			v /= 1000
+		case "ms":
+			v *= 1000
+		case "s":
+			v *= 1_000_000
+		}
+		samples = append(samples, v)
+	}
+	return samples
+}
+
+// Summarize computes average, nearest-rank 99th percentile, min and max.
+func Summarize(samples []float64) *Result {
+	if len(samples) == 0 {
+		return &Result{}
+	}
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	rank := int(math.Ceil(0.99*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+
+	return &Result{
+		AvgUs:   math.Round(sum/float64(len(sorted))*10) / 10,
+		P99Us:   sorted[rank],
+		MinUs:   sorted[0],
+		MaxUs:   sorted[len(sorted)-1],
+		Samples: len(sorted),
+	}
+}
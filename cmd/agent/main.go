@@ -3,6 +3,9 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -13,14 +16,18 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync/atomic"
 	"syscall"
 	"time"
 
 	agentcrypto "vigil/cmd/agent/crypto"
+	"vigil/cmd/agent/latency"
 	"vigil/cmd/agent/led"
 	"vigil/cmd/agent/smart"
 	"vigil/cmd/agent/zfs"
+	"vigil/internal/logfmt"
 )
 
 var version = "dev"
@@ -29,26 +36,53 @@ var version = "dev"
 // runInterval re-arms its ticker when this changes; sendReport updates it.
 var desiredInterval atomic.Int64
 
+// latencyProbe holds the optional per-drive read latency probe. A nil
+// prober means the probe is disabled (not requested or ioping missing).
+var latencyProbe struct {
+	prober  *latency.Prober
+	skipSSD bool
+}
+
+// reportHMACSecret, when set, signs each report body so a server configured
+// with the same REPORT_HMAC_SECRET can verify it.
+var reportHMACSecret []byte
+
+// hostLabels are the operator-defined --label tags sent with every report.
+var hostLabels = labelFlag{}
+
+// reportSchemaVersion is the report payload version this agent sends. Bump
+// it together with the server's CurrentReportSchema when the payload's
+// shape changes; older servers refuse versions they don't know.
+const reportSchemaVersion = 2
+
 // DriveReport contains SMART data for drives
 type DriveReport struct {
-	Hostname     string                   `json:"hostname"`
-	Timestamp    time.Time                `json:"timestamp"`
-	Version      string                   `json:"agent_version"`
-	Drives       []map[string]interface{} `json:"drives"`
-	ZFS          *zfs.ZFSReport           `json:"zfs,omitempty"`
-	Capabilities *AgentCapabilities       `json:"capabilities,omitempty"`
+	SchemaVersion int                      `json:"schema_version"`
+	Hostname      string                   `json:"hostname"`
+	Timestamp     time.Time                `json:"timestamp"`
+	Version       string                   `json:"agent_version"`
+	Smartctl      string                   `json:"smartctl_version,omitempty"`
+	Drives        []map[string]interface{} `json:"drives"`
+	ZFS           *zfs.ZFSReport           `json:"zfs,omitempty"`
+	Capabilities  *AgentCapabilities       `json:"capabilities,omitempty"`
+	Labels        map[string]string        `json:"labels,omitempty"`
+	Maintenance   bool                     `json:"maintenance,omitempty"`
 }
 
 // AgentCapabilities reports optional features this agent supports.
 type AgentCapabilities struct {
-	LEDIdentify bool   `json:"led_identify"`
-	ListenAddr  string `json:"listen_addr,omitempty"`
+	LEDIdentify  bool   `json:"led_identify"`
+	LatencyProbe bool   `json:"latency_probe"`
+	ZFSClear     bool   `json:"zfs_clear"`
+	ListenAddr   string `json:"listen_addr,omitempty"`
 }
 
 func main() {
 	cfg := parseFlags()
+	agentStats.startedAt = time.Now()
 
 	log.SetFlags(log.Ltime | log.Ldate)
+	logfmt.Setup()
 	log.Printf("🚀 Vigil Agent v%s starting...", version)
 
 	if err := checkSmartctl(); err != nil {
@@ -69,14 +103,59 @@ func main() {
 		log.Println("ℹ️  ledctl not found (LED identification disabled)")
 	}
 
+	if cfg.latencyProbe {
+		if p := latency.Detect(); p.Available() {
+			latencyProbe.prober = p
+			latencyProbe.skipSSD = cfg.latencySkipSSD
+			log.Println("✓ ioping detected (drive latency probe enabled)")
+		} else {
+			log.Println("⚠️  --latency-probe set but ioping not found (latency probe disabled)")
+		}
+	}
+
+	if cfg.reportHMACSecret != "" {
+		reportHMACSecret = []byte(cfg.reportHMACSecret)
+		log.Println("✓ Report signing enabled")
+	}
+
 	hostname := getHostname(cfg.hostnameOverride)
 	log.Printf("✓ Hostname: %s", hostname)
 	log.Printf("✓ Server:   %s", cfg.serverURL)
+	configureHTTPClient(cfg.serverURL, cfg.connectTimeout, cfg.requestTimeout, cfg.insecureSkipTLS)
+	switch cfg.protocol {
+	case "http":
+	case "grpc":
+		if err := configureGRPCReports(cfg.grpcServer, cfg.serverURL, cfg.connectTimeout, cfg.requestTimeout, cfg.insecureSkipTLS); err != nil {
+			log.Fatalf("❌ gRPC setup failed: %v", err)
+		}
+	default:
+		log.Fatalf("❌ Unknown --protocol %q (use http or grpc)", cfg.protocol)
+	}
 	log.Printf("✓ Data dir: %s", cfg.dataDir)
+	if len(hostLabels) > 0 {
+		log.Printf("✓ Labels:   %s", hostLabels)
+	}
+	if len(extraScanTypes) > 0 {
+		log.Printf("✓ Extra scan types: %s", strings.Join(extraScanTypes, ", "))
+	}
+	if rescanEvery > 1 {
+		log.Printf("✓ Device scan: every %d reports", rescanEvery)
+	}
+	if deltas.enabled {
+		log.Printf("✓ Delta reports: unchanged drives sent as stubs, every %d reports in full", deltas.fullEvery)
+	}
+	if len(extraDevices) > 0 {
+		log.Printf("✓ Extra devices:    %s", extraDevices.String())
+	}
 
 	if err := os.MkdirAll(cfg.dataDir, 0o700); err != nil {
 		log.Fatalf("❌ Cannot create data dir %s: %v", cfg.dataDir, err)
 	}
+	maintenanceMode = cfg.maintenance
+	maintenanceFile = filepath.Join(cfg.dataDir, "maintenance")
+	if inMaintenance() {
+		log.Println("🔧 Maintenance mode: the server suppresses this host's alerts")
+	}
 
 	keys, err := agentcrypto.LoadOrGenerate(cfg.dataDir)
 	if err != nil {
@@ -127,20 +206,31 @@ func main() {
 
 	// Build capabilities for this agent.
 	caps := &AgentCapabilities{
-		LEDIdentify: ledCtrl.Available(),
-		ListenAddr:  cfg.listenAddr,
+		LEDIdentify:  ledCtrl.Available(),
+		LatencyProbe: latencyProbe.prober != nil,
+		ZFSClear:     zfsAvailable && cfg.listenAddr != "" && cfg.allowZFSClear,
+		ListenAddr:   cfg.listenAddr,
 	}
 
 	// Start optional command listener if --listen is set.
 	if cfg.listenAddr != "" {
-		go startCommandServer(cfg.listenAddr, ledCtrl)
+		go startCommandServer(cfg.listenAddr, ledCtrl, zfsAvailable, cfg.allowZFSClear)
 		log.Printf("✓ Command listener on %s", cfg.listenAddr)
+		if cfg.allowZFSClear && zfsAvailable {
+			log.Println("✓ zpool clear allowed from the command listener")
+		}
+	}
+
+	// Start optional self-monitoring endpoint if --metrics-addr is set.
+	if cfg.metricsAddr != "" {
+		go startMetricsServer(cfg.metricsAddr)
+		log.Printf("✓ Metrics on %s/metrics", cfg.metricsAddr)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	setupSignalHandler(cancel)
+	collectNow := setupSignalHandler(cancel)
 
 	authSt = sendReport(ctx, cfg.serverURL, hostname, zfsAvailable, caps, fingerprint, keys, authSt, cfg.dataDir)
 
@@ -149,7 +239,7 @@ func main() {
 		return
 	}
 
-	runInterval(ctx, cfg.serverURL, hostname, cfg.interval, zfsAvailable, caps, fingerprint, keys, authSt, cfg.dataDir)
+	runInterval(ctx, cfg.serverURL, hostname, cfg.interval, zfsAvailable, caps, fingerprint, keys, authSt, cfg.dataDir, collectNow)
 }
 
 type agentConfig struct {
@@ -160,6 +250,17 @@ type agentConfig struct {
 	register         bool
 	registerToken    string
 	listenAddr       string
+	allowZFSClear    bool
+	metricsAddr      string
+	latencyProbe     bool
+	latencySkipSSD   bool
+	reportHMACSecret string
+	connectTimeout   time.Duration
+	requestTimeout   time.Duration
+	insecureSkipTLS  bool
+	maintenance      bool
+	protocol         string
+	grpcServer       string
 }
 
 func parseFlags() agentConfig {
@@ -170,7 +271,25 @@ func parseFlags() agentConfig {
 	register := flag.Bool("register", false, "Register this agent with the server (requires --token)")
 	token := flag.String("token", "", "One-time registration token (used with --register)")
 	listenAddr := flag.String("listen", "", "Optional HTTP listen address for commands (e.g. :9090)")
+	allowZFSClear := flag.Bool("allow-zfs-clear", false, "Let the command server run zpool clear when the Vigil server asks (requests must be signed when --report-hmac-secret is set)")
+	metricsAddr := flag.String("metrics-addr", "", "Optional HTTP listen address for Prometheus self-monitoring metrics (e.g. :9101)")
+	latencyProbe := flag.Bool("latency-probe", false, "Run a short ioping read-latency probe per drive each report")
+	latencySkipSSD := flag.Bool("latency-skip-ssd", false, "Skip the latency probe for SSD and NVMe drives")
+	reportHMACSecret := flag.String("report-hmac-secret", "", "Shared secret for signing reports (must match the server's REPORT_HMAC_SECRET)")
+	connectTimeout := flag.Int("connect-timeout", 10, "Seconds to wait for the TCP connection and TLS handshake to the server")
+	requestTimeout := flag.Int("request-timeout", 30, "Seconds to wait for a whole request to the server, including the response")
+	insecureSkipTLS := flag.Bool("insecure-skip-verify", false, "Skip TLS certificate verification (self-signed servers; insecure)")
+	maintenance := flag.Bool("maintenance", false, "Report this host as in maintenance so the server suppresses its alerts")
+	protocol := flag.String("protocol", "http", "Report transport: http, or grpc for the server's GRPC_PORT")
+	grpcServer := flag.String("grpc-server", "", "gRPC address (host:port) for --protocol grpc (default: the --server host on port "+defaultGRPCPort+")")
+	deltaReports := flag.Bool("delta-reports", false, "After a full report, send drives whose SMART attributes haven't changed as serial and temperature only")
+	fullReportEvery := flag.Int("full-report-every", 10, "With --delta-reports, send every Nth report in full so the server resyncs")
+	deltaThreshold := flag.Int("delta-threshold", 0, "With --delta-reports, how far an attribute's raw value may move before the drive is sent in full again (usage counters are ignored, failure counters count on any change)")
+	rescanInterval := flag.Int("rescan-interval", 1, "Reuse the device scan for this many reports before scanning again (1 = every report); a failed drive read rescans right away")
+	scanTypes := flag.String("scan-types", "", "Extra smartctl --scan device types, comma-separated (e.g. sat,nvme)")
 	showVersion := flag.Bool("version", false, "Show version")
+	flag.Var(hostLabels, "label", "Host label as key=value (repeatable, e.g. --label dc=us-east --label env=prod)")
+	flag.Var(&extraDevices, "device", "Extra drive as PATH:TYPE for smartctl -d (repeatable, e.g. --device /dev/sda:megaraid,0)")
 	flag.Parse()
 
 	if *showVersion {
@@ -187,6 +306,39 @@ func parseFlags() agentConfig {
 		register:         *register,
 		registerToken:    envOrStr("TOKEN", *token),
 		listenAddr:       envOrStr("AGENT_LISTEN", *listenAddr),
+		allowZFSClear:    envOrStr("ALLOW_ZFS_CLEAR", fmt.Sprint(*allowZFSClear)) == "true",
+		metricsAddr:      envOrStr("METRICS_ADDR", *metricsAddr),
+		latencyProbe:     envOrStr("LATENCY_PROBE", fmt.Sprint(*latencyProbe)) == "true",
+		latencySkipSSD:   envOrStr("LATENCY_SKIP_SSD", fmt.Sprint(*latencySkipSSD)) == "true",
+		reportHMACSecret: envOrStr("REPORT_HMAC_SECRET", *reportHMACSecret),
+		connectTimeout:   time.Duration(envOrInt("CONNECT_TIMEOUT", *connectTimeout)) * time.Second,
+		requestTimeout:   time.Duration(envOrInt("REQUEST_TIMEOUT", *requestTimeout)) * time.Second,
+		insecureSkipTLS:  envOrStr("INSECURE_SKIP_VERIFY", fmt.Sprint(*insecureSkipTLS)) == "true",
+		maintenance:      envOrStr("MAINTENANCE", fmt.Sprint(*maintenance)) == "true",
+		protocol:         envOrStr("PROTOCOL", *protocol),
+		grpcServer:       envOrStr("GRPC_SERVER", *grpcServer),
+	}
+
+	if env := os.Getenv("LABELS"); env != "" {
+		if err := hostLabels.parseLabelList(env); err != nil {
+			log.Fatalf("❌ Invalid LABELS: %v", err)
+		}
+	}
+	if env := os.Getenv("SMART_DEVICES"); env != "" {
+		if err := extraDevices.parseDeviceList(env); err != nil {
+			log.Fatalf("❌ Invalid SMART_DEVICES: %v", err)
+		}
+	}
+	deltas.enabled = envOrStr("DELTA_REPORTS", fmt.Sprint(*deltaReports)) == "true"
+	deltas.fullEvery = envOrInt("FULL_REPORT_EVERY", *fullReportEvery)
+	deltas.threshold = float64(envOrInt("DELTA_THRESHOLD", *deltaThreshold))
+	if rescanEvery = envOrInt("RESCAN_INTERVAL", *rescanInterval); rescanEvery < 1 {
+		rescanEvery = 1
+	}
+	for _, t := range strings.Split(envOrStr("SCAN_TYPES", *scanTypes), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			extraScanTypes = append(extraScanTypes, t)
+		}
 	}
 
 	// If TOKEN env is set but --register wasn't passed, enable auto-registration
@@ -205,6 +357,14 @@ func envOrStr(key, fallback string) string {
 	return fallback
 }
 
+// envOrInt is envOrStr for positive integers; unparsable values fall back.
+func envOrInt(key string, fallback int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return fallback
+}
+
 func defaultDataDir() string {
 	if runtime.GOOS == "linux" && os.Getuid() == 0 {
 		return "/var/lib/vigil-agent"
@@ -232,15 +392,34 @@ func getHostname(override string) string {
 	return hostname
 }
 
-func setupSignalHandler(cancel context.CancelFunc) {
+// setupSignalHandler cancels ctx on SIGINT/SIGTERM. SIGUSR1 instead asks for
+// an immediate out-of-cycle report (e.g. `pkill -USR1 vigil-agent` after a
+// hardware change); requests that arrive while one is pending are coalesced.
+// SIGUSR2 toggles maintenance mode and reports right away so the server
+// picks up the change.
+func setupSignalHandler(cancel context.CancelFunc) <-chan struct{} {
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGUSR1, syscall.SIGUSR2)
+	collectNow := make(chan struct{}, 1)
 
 	go func() {
-		<-sigChan
-		log.Println("\n⏹️  Shutting down...")
-		cancel()
+		for sig := range sigChan {
+			if sig == syscall.SIGUSR2 {
+				toggleMaintenance()
+			}
+			if sig == syscall.SIGUSR1 || sig == syscall.SIGUSR2 {
+				select {
+				case collectNow <- struct{}{}:
+				default:
+				}
+				continue
+			}
+			log.Println("\n⏹️  Shutting down...")
+			cancel()
+			return
+		}
 	}()
+	return collectNow
 }
 
 func runInterval(
@@ -253,8 +432,9 @@ func runInterval(
 	keys *agentcrypto.AgentKeys,
 	state *authState,
 	dataDir string,
+	collectNow <-chan struct{},
 ) {
-	log.Printf("📊 Reporting every %d seconds", interval)
+	log.Printf("📊 Reporting every %d seconds (send SIGUSR1 to report now)", interval)
 	current := interval
 	desiredInterval.Store(int64(interval))
 	ticker := time.NewTicker(time.Duration(current) * time.Second)
@@ -265,11 +445,18 @@ func runInterval(
 		case <-ctx.Done():
 			log.Println("👋 Agent stopped")
 			return
+		case <-collectNow:
+			log.Println("⚡ Out-of-cycle report requested, collecting now")
+			invalidateScan() // e.g. right after adding a drive
+			state = sendReport(ctx, serverURL, hostname, zfsAvailable, caps, fingerprint, keys, state, dataDir)
+			// Restart the cycle so the next scheduled report is a full
+			// interval away rather than landing right after this one.
+			current = nextInterval(current)
+			ticker.Reset(time.Duration(current) * time.Second)
 		case <-ticker.C:
 			state = sendReport(ctx, serverURL, hostname, zfsAvailable, caps, fingerprint, keys, state, dataDir)
 			// Re-arm the ticker if the hub changed the interval (via sendReport).
-			if want := int(desiredInterval.Load()); want > 0 && want != current {
-				log.Printf("🔧 Report interval changed by hub: %ds → %ds", current, want)
+			if want := nextInterval(current); want != current {
 				current = want
 				ticker.Reset(time.Duration(current) * time.Second)
 			}
@@ -277,6 +464,16 @@ func runInterval(
 	}
 }
 
+// nextInterval returns the interval the hub last advertised, or current if
+// it hasn't changed.
+func nextInterval(current int) int {
+	if want := int(desiredInterval.Load()); want > 0 && want != current {
+		log.Printf("🔧 Report interval changed by hub: %ds → %ds", current, want)
+		return want
+	}
+	return current
+}
+
 // sendReport builds and POSTs a report, transparently handling session expiry.
 func sendReport(
 	ctx context.Context,
@@ -290,17 +487,34 @@ func sendReport(
 ) *authState {
 	if sessionNeedsRefresh(state) {
 		log.Println("🔄 Proactive re-auth before report...")
+		agentStats.reauths.Add(1)
 		if newState, err := authenticate(state, fingerprint, keys, dataDir); err == nil {
 			state = newState
+		} else {
+			agentStats.reauthFailures.Add(1)
 		}
 	}
 
+	collectStart := time.Now()
 	report := DriveReport{
-		Hostname:     hostname,
-		Timestamp:    time.Now().UTC(),
-		Version:      version,
-		Drives:       collectDriveData(ctx),
-		Capabilities: caps,
+		SchemaVersion: reportSchemaVersion,
+		Hostname:      hostname,
+		Timestamp:     time.Now().UTC(),
+		Version:       version,
+		Capabilities:  caps,
+	}
+	drives := collectDriveData(ctx)
+	var commitDelta func()
+	report.Drives, commitDelta = deltas.prepare(drives)
+	if len(hostLabels) > 0 {
+		report.Labels = hostLabels
+	}
+	report.Maintenance = inMaintenance()
+	for _, d := range drives {
+		if v := smart.SmartctlVersion(d); v != "" {
+			report.Smartctl = v
+			break
+		}
 	}
 
 	if zfsAvailable {
@@ -311,24 +525,34 @@ func sendReport(
 			log.Printf("📦 ZFS: %d pool(s) detected", len(zfsReport.Pools))
 		}
 	}
+	agentStats.lastCollection.Store(int64(time.Since(collectStart)))
+	agentStats.drivesScanned.Store(int64(len(report.Drives)))
 
 	wantInterval, err := postReport(ctx, serverURL, report, state.SessionToken)
 	if err == errUnauthorized {
 		log.Println("🔄 Session expired, re-authenticating...")
+		agentStats.reauths.Add(1)
 		newState, authErr := authenticate(state, fingerprint, keys, dataDir)
 		if authErr != nil {
 			log.Printf("❌ Re-authentication failed: %v", authErr)
+			agentStats.reauthFailures.Add(1)
+			agentStats.reportFailures.Add(1)
 			return state
 		}
 		state = newState
 		if wantInterval, err = postReport(ctx, serverURL, report, state.SessionToken); err != nil {
 			log.Printf("❌ Report failed after re-auth: %v", err)
+			agentStats.reportFailures.Add(1)
 			return state
 		}
 	} else if err != nil {
 		log.Printf("❌ %v", err)
+		agentStats.reportFailures.Add(1)
 		return state
 	}
+	agentStats.reportsSent.Add(1)
+	agentStats.lastReportOK.Store(time.Now().Unix())
+	commitDelta()
 
 	// Adopt the server-advertised report interval (0 = no change). runInterval
 	// reads this and re-arms its ticker when it differs from the current one.
@@ -337,6 +561,9 @@ func sendReport(
 	}
 
 	logMsg := fmt.Sprintf("✅ Report sent (%d drives", len(report.Drives))
+	if unchanged := len(drives) - deltas.fullCount(report.Drives); unchanged > 0 {
+		logMsg += fmt.Sprintf(", %d unchanged", unchanged)
+	}
 	if report.ZFS != nil && len(report.ZFS.Pools) > 0 {
 		logMsg += fmt.Sprintf(", %d ZFS pools", len(report.ZFS.Pools))
 	}
@@ -348,38 +575,115 @@ func sendReport(
 var errUnauthorized = fmt.Errorf("session token rejected (401)")
 
 func collectDriveData(ctx context.Context) []map[string]interface{} {
-	devices, err := smart.ScanDevices(ctx)
+	devices, err := cachedScan(ctx)
 	if err != nil {
 		log.Printf("⚠️  Device scan failed: %v", err)
-		return nil
+		agentStats.scanFailures.Add(1)
 	}
-	if len(devices) == 0 {
-		log.Println("⚠️  No drives detected (check permissions)")
+	if len(devices) == 0 && len(extraDevices) == 0 {
+		if err == nil {
+			log.Println("⚠️  No drives detected (check permissions)")
+		}
 		return nil
 	}
 
+	// Explicit --device entries go first so that when one also shows up in
+	// the scan (e.g. a USB bridge scanned as scsi), the configured type wins
+	// and the duplicate is dropped by serial.
 	var drives []map[string]interface{}
+	seenSerials := make(map[string]bool)
+	add := func(dev smart.Device, data map[string]interface{}) {
+		if serial := driveSerial(data); serial != "" {
+			if seenSerials[serial] {
+				return
+			}
+			seenSerials[serial] = true
+		}
+		// Behind a RAID controller (megaraid,N / cciss,N) the path is the
+		// controller's virtual disk, shared by every physical drive on it;
+		// its by-id link and latency would describe the array, not the drive.
+		if !strings.Contains(dev.Type, ",") {
+			attachByIDPath(dev.Name, data)
+			attachLatency(ctx, dev.Name, data)
+		}
+		drives = append(drives, data)
+	}
+
+	for _, dev := range extraDevices {
+		if data := smart.ReadDriveAs(ctx, dev.Name, dev.Type); data != nil {
+			add(dev, data)
+		} else {
+			agentStats.scanFailures.Add(1)
+		}
+	}
 	for _, dev := range devices {
 		if data := smart.ReadDrive(ctx, dev.Name, dev.Type); data != nil {
-			drives = append(drives, data)
+			add(dev, data)
+		} else {
+			agentStats.scanFailures.Add(1)
+			invalidateScan()
 		}
 	}
 	return drives
 }
 
+// attachByIDPath records the drive's stable /dev/disk/by-id path as
+// device.by_id next to smartctl's kernel device name, which can change
+// between reboots.
+func attachByIDPath(device string, data map[string]interface{}) {
+	byID := smart.ByIDPath(device)
+	if byID == "" {
+		return
+	}
+	if dev, ok := data["device"].(map[string]interface{}); ok {
+		dev["by_id"] = byID
+	}
+}
+
+// attachLatency runs the optional latency probe for a drive and stores the
+// summary under the drive's "latency" key. Failures are logged and skipped.
+func attachLatency(ctx context.Context, device string, data map[string]interface{}) {
+	if latencyProbe.prober == nil {
+		return
+	}
+	if latencyProbe.skipSSD {
+		if parsed, err := smart.ParseSmartAttributes(data, ""); err == nil &&
+			(parsed.DriveType == smart.DriveTypeSSD || parsed.DriveType == smart.DriveTypeNVMe) {
+			return
+		}
+	}
+	res, err := latencyProbe.prober.Probe(ctx, device)
+	if err != nil {
+		log.Printf("   ⚠️  Latency probe failed for %s: %v", device, err)
+		return
+	}
+	data["latency"] = res
+}
+
 func collectZFSData(hostname string) (*zfs.ZFSReport, error) {
 	return zfs.CollectZFSData(hostname)
 }
 
-// postReport POSTs a report and returns the server-advertised report interval
-// in seconds (0 if none/unchanged) along with any error.
+// signReport returns the X-Vigil-Signature value for a report body.
+func signReport(payload []byte) string {
+	mac := hmac.New(sha256.New, reportHMACSecret)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// postReport POSTs a report (or sends it over gRPC with --protocol grpc) and
+// returns the server-advertised report interval in seconds (0 if
+// none/unchanged) along with any error.
 func postReport(ctx context.Context, serverURL string, report DriveReport, sessionToken string) (int, error) {
+	if grpcReports != nil {
+		return grpcReports.post(ctx, report, sessionToken)
+	}
+
 	payload, err := json.Marshal(report)
 	if err != nil {
 		return 0, fmt.Errorf("failed to marshal report: %v", err)
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
 	req, err := http.NewRequestWithContext(ctx, "POST", serverURL+"/api/report", bytes.NewBuffer(payload))
 	if err != nil {
 		return 0, fmt.Errorf("failed to create request: %v", err)
@@ -388,8 +692,11 @@ func postReport(ctx context.Context, serverURL string, report DriveReport, sessi
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", fmt.Sprintf("vigil-agent/%s", version))
 	req.Header.Set("Authorization", "Bearer "+sessionToken)
+	if len(reportHMACSecret) > 0 {
+		req.Header.Set("X-Vigil-Signature", signReport(payload))
+	}
 
-	resp, err := client.Do(req) // #nosec G107 G704 -- URL is the configured server endpoint
+	resp, err := httpClient.Do(req) // #nosec G107 G704 -- URL is the configured server endpoint
 	if err != nil {
 		return 0, fmt.Errorf("connection failed: %v", err)
 	}
@@ -398,6 +705,17 @@ func postReport(ctx context.Context, serverURL string, report DriveReport, sessi
 	if resp.StatusCode == http.StatusUnauthorized {
 		return 0, errUnauthorized
 	}
+	if resp.StatusCode == http.StatusUnprocessableEntity || resp.StatusCode == http.StatusForbidden {
+		var e struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		// 403 is also how an agent awaiting approval is turned away
+		if resp.StatusCode == http.StatusForbidden && len(reportHMACSecret) > 0 && e.Error == "Invalid report signature" {
+			return 0, fmt.Errorf("server rejected report signature (check REPORT_HMAC_SECRET)")
+		}
+		return 0, fmt.Errorf("server refused report: %s", e.Error)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return 0, fmt.Errorf("server returned %d", resp.StatusCode)
 	}
@@ -406,10 +724,14 @@ func postReport(ctx context.Context, serverURL string, report DriveReport, sessi
 	// the cadence can be changed from the hub without touching each host. 0 (or
 	// a missing field) means "no change — keep the current interval".
 	var rr struct {
-		ReportIntervalSeconds int `json:"report_interval_seconds"`
+		ReportIntervalSeconds int  `json:"report_interval_seconds"`
+		FullReportRequired    bool `json:"full_report_required"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
 		return 0, nil // response body optional; not an error
 	}
+	if rr.FullReportRequired {
+		deltas.requestFull()
+	}
 	return rr.ReportIntervalSeconds, nil
 }
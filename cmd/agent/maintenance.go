@@ -0,0 +1,46 @@
+package main
+
+import (
+	"log"
+	"os"
+)
+
+// maintenanceMode is set by --maintenance (or MAINTENANCE=true) and keeps
+// the agent in maintenance for its whole run. maintenanceFile is the marker
+// in the data dir that SIGUSR2 toggles, so the state survives the reboots
+// that usually come with hardware work.
+var (
+	maintenanceMode bool
+	maintenanceFile string
+)
+
+// inMaintenance reports whether the next report should ask the server to
+// suppress this host's alerts.
+func inMaintenance() bool {
+	if maintenanceMode {
+		return true
+	}
+	_, err := os.Stat(maintenanceFile)
+	return err == nil
+}
+
+// toggleMaintenance flips the maintenance marker file (SIGUSR2).
+func toggleMaintenance() {
+	if _, err := os.Stat(maintenanceFile); err == nil {
+		if err := os.Remove(maintenanceFile); err != nil {
+			log.Printf("⚠️  Cannot leave maintenance: %v", err)
+			return
+		}
+		if maintenanceMode {
+			log.Println("🔧 Maintenance marker removed, but --maintenance keeps the host in maintenance")
+			return
+		}
+		log.Println("🔧 Maintenance off: server alerts for this host resume")
+		return
+	}
+	if err := os.WriteFile(maintenanceFile, nil, 0o600); err != nil {
+		log.Printf("⚠️  Cannot enter maintenance: %v", err)
+		return
+	}
+	log.Printf("🔧 Maintenance on: server alerts for this host are suppressed (send SIGUSR2 again or remove %s to clear)", maintenanceFile)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// agentStats tracks the agent's own health for the optional --metrics-addr
+// endpoint. Counters are cumulative since start; the rest describe the last
+// collection or report.
+var agentStats struct {
+	lastCollection atomic.Int64 // nanoseconds spent collecting the last report
+	drivesScanned  atomic.Int64 // drives in the last report
+	scanFailures   atomic.Int64 // failed device scans and drive reads
+	reportsSent    atomic.Int64
+	reportFailures atomic.Int64
+	lastReportOK   atomic.Int64 // unix seconds of the last accepted report
+	reauths        atomic.Int64
+	reauthFailures atomic.Int64
+	startedAt      time.Time
+}
+
+// startMetricsServer serves the agent's self-monitoring metrics in the
+// Prometheus text format on GET /metrics. Only started when --metrics-addr
+// or METRICS_ADDR is set.
+func startMetricsServer(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writeMetrics(w)
+	})
+
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	if err := server.ListenAndServe(); err != nil {
+		log.Printf("⚠️  Metrics server error: %v", err)
+	}
+}
+
+func writeMetrics(w io.Writer) {
+	metric := func(name, kind, help string, value interface{}) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+	}
+
+	fmt.Fprintf(w, "# HELP vigil_agent_info Agent version.\n# TYPE vigil_agent_info gauge\nvigil_agent_info{version=%q} 1\n", version)
+	metric("vigil_agent_uptime_seconds", "gauge", "Seconds since the agent started.",
+		int64(time.Since(agentStats.startedAt).Seconds()))
+	metric("vigil_agent_last_collection_duration_seconds", "gauge", "Time spent collecting drive and ZFS data for the last report.",
+		time.Duration(agentStats.lastCollection.Load()).Seconds())
+	metric("vigil_agent_drives_scanned", "gauge", "Drives included in the last report.",
+		agentStats.drivesScanned.Load())
+	metric("vigil_agent_scan_failures_total", "counter", "Failed device scans and drive reads.",
+		agentStats.scanFailures.Load())
+	metric("vigil_agent_reports_sent_total", "counter", "Reports accepted by the server.",
+		agentStats.reportsSent.Load())
+	metric("vigil_agent_report_failures_total", "counter", "Reports that could not be delivered.",
+		agentStats.reportFailures.Load())
+	metric("vigil_agent_last_report_success_timestamp_seconds", "gauge", "Unix time of the last report accepted by the server (0 = none yet).",
+		agentStats.lastReportOK.Load())
+	metric("vigil_agent_reauth_total", "counter", "Session re-authentications with the server.",
+		agentStats.reauths.Load())
+	metric("vigil_agent_reauth_failures_total", "counter", "Session re-authentications that failed.",
+		agentStats.reauthFailures.Load())
+}
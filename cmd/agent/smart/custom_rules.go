@@ -0,0 +1,124 @@
+package smart
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Custom rule operators and fields
+const (
+	RuleAbove      = "above"
+	RuleBelow      = "below"
+	RuleFieldRaw   = "raw"
+	RuleFieldValue = "value" // normalized value
+)
+
+// CustomAttributeRule is a user-defined watch on a SMART attribute, for
+// vendor-specific attributes CriticalAttributeDefinitions doesn't cover or
+// thresholds that differ from the built-in ones.
+type CustomAttributeRule struct {
+	ID          int64  `json:"id"`
+	AttributeID int    `json:"attribute_id"`
+	Model       string `json:"model,omitempty"` // case-insensitive substring of the model name; empty = every drive
+	Field       string `json:"field"`           // raw or value
+	Operator    string `json:"operator"`        // above or below
+	Threshold   int64  `json:"threshold"`
+	Severity    string `json:"severity"` // CRITICAL, WARNING or INFO
+	Description string `json:"description,omitempty"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// Normalize fills in the default field and operator and upper-cases the
+// severity, then checks the rule.
+func (r *CustomAttributeRule) Normalize() error {
+	r.Model = strings.TrimSpace(r.Model)
+	if r.Field == "" {
+		r.Field = RuleFieldRaw
+	}
+	if r.Operator == "" {
+		r.Operator = RuleAbove
+	}
+	r.Severity = strings.ToUpper(r.Severity)
+
+	if r.AttributeID < 1 || r.AttributeID > 255 {
+		return fmt.Errorf("attribute_id must be between 1 and 255")
+	}
+	if r.Field != RuleFieldRaw && r.Field != RuleFieldValue {
+		return fmt.Errorf("field must be %q or %q", RuleFieldRaw, RuleFieldValue)
+	}
+	if r.Operator != RuleAbove && r.Operator != RuleBelow {
+		return fmt.Errorf("operator must be %q or %q", RuleAbove, RuleBelow)
+	}
+	switch r.Severity {
+	case SeverityCritical, SeverityWarning, SeverityInfo:
+	default:
+		return fmt.Errorf("severity must be CRITICAL, WARNING or INFO")
+	}
+	return nil
+}
+
+// Applies reports whether the rule watches attribute id on a drive of the
+// given model.
+func (r CustomAttributeRule) Applies(id int, model string) bool {
+	if !r.Enabled || r.AttributeID != id {
+		return false
+	}
+	return r.Model == "" || strings.Contains(strings.ToLower(model), strings.ToLower(r.Model))
+}
+
+// Triggered reports whether the attribute's value crosses the rule's threshold.
+func (r CustomAttributeRule) Triggered(rawValue int64, value int) bool {
+	v := rawValue
+	if r.Field == RuleFieldValue {
+		v = int64(value)
+	}
+	if r.Operator == RuleBelow {
+		return v < r.Threshold
+	}
+	return v > r.Threshold
+}
+
+// customRules holds the rules the server loaded from custom_attribute_rules.
+var customRules struct {
+	sync.RWMutex
+	rules []CustomAttributeRule
+}
+
+// SetCustomAttributeRules replaces the custom rules consulted by
+// GetAttributeSeverity.
+func SetCustomAttributeRules(rules []CustomAttributeRule) {
+	customRules.Lock()
+	defer customRules.Unlock()
+	customRules.rules = rules
+}
+
+// customAttributeSeverity returns the worst severity of the custom rules
+// that apply to and are triggered by an attribute, or SeverityHealthy.
+func customAttributeSeverity(id int, model string, rawValue int64, value int) string {
+	customRules.RLock()
+	defer customRules.RUnlock()
+
+	severity := SeverityHealthy
+	for _, r := range customRules.rules {
+		if r.Applies(id, model) && r.Triggered(rawValue, value) {
+			severity = WorseSeverity(severity, r.Severity)
+		}
+	}
+	return severity
+}
+
+var severityRank = map[string]int{
+	SeverityHealthy:  0,
+	SeverityInfo:     1,
+	SeverityWarning:  2,
+	SeverityCritical: 3,
+}
+
+// WorseSeverity returns the more severe of a and b.
+func WorseSeverity(a, b string) string {
+	if severityRank[b] > severityRank[a] {
+		return b
+	}
+	return a
+}
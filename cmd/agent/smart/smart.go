@@ -3,8 +3,12 @@ package smart
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 )
 
 // FallbackDeviceTypes are tried when the detected type fails
@@ -26,6 +30,47 @@ func ScanDevices(ctx context.Context) ([]Device, error) {
 	return result.Devices, nil
 }
 
+// ScanDevicesOfType runs smartctl --scan restricted to one device type
+// (e.g. "sat", "nvme"), which finds drives the default scan skips.
+func ScanDevicesOfType(ctx context.Context, devType string) ([]Device, error) {
+	cmd := exec.CommandContext(ctx, "smartctl", "--scan", "--json", "-d", devType)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var result ScanResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Devices, nil
+}
+
+// ParseDeviceSpec parses an explicit "PATH:TYPE" device spec such as
+// "/dev/sda:megaraid,3", "/dev/sdc:sat" or "/dev/sg1:cciss,0". TYPE is
+// passed to smartctl -d unchanged.
+func ParseDeviceSpec(spec string) (Device, error) {
+	name, devType, ok := strings.Cut(strings.TrimSpace(spec), ":")
+	if !ok || name == "" || devType == "" {
+		return Device{}, fmt.Errorf("device must be PATH:TYPE (e.g. /dev/sda:megaraid,0), got %q", spec)
+	}
+	return Device{Name: name, Type: devType}, nil
+}
+
+// ReadDriveAs reads SMART data with exactly the given type. Used for
+// explicitly configured devices, where falling back to another type would
+// read the controller's virtual disk instead of the physical drive.
+func ReadDriveAs(ctx context.Context, name, devType string) map[string]interface{} {
+	logAttempt(name, devType, 0)
+	data := readWithType(ctx, name, devType)
+	if data != nil && hasValidSmartData(data) {
+		return data
+	}
+	log.Printf("   ⚠️  Skipping %s -d %s (no SMART data)", name, devType)
+	return nil
+}
+
 // ReadDrive attempts to read SMART data using detected type first, then fallbacks
 func ReadDrive(ctx context.Context, name, detectedType string) map[string]interface{} {
 	typesToTry := buildTypesToTry(detectedType)
@@ -80,6 +125,43 @@ func readWithType(ctx context.Context, name, devType string) map[string]interfac
 	return data
 }
 
+// ByIDPath returns the /dev/disk/by-id symlink for a kernel device such as
+// /dev/sda. Unlike the kernel name it survives reboots and controller
+// reordering. Model/serial based names (ata-, nvme-, scsi-) are preferred
+// over wwn- and nvme-eui. ones; "" means no link was found.
+func ByIDPath(device string) string {
+	const byIDDir = "/dev/disk/by-id"
+
+	entries, err := os.ReadDir(byIDDir)
+	if err != nil {
+		return ""
+	}
+
+	deviceName := filepath.Base(device)
+	var fallback string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type()&os.ModeSymlink == 0 || strings.Contains(name, "-part") {
+			continue
+		}
+
+		linkPath := filepath.Join(byIDDir, name)
+		target, err := os.Readlink(linkPath)
+		if err != nil || filepath.Base(target) != deviceName {
+			continue
+		}
+
+		if strings.HasPrefix(name, "wwn-") || strings.HasPrefix(name, "nvme-eui.") {
+			if fallback == "" {
+				fallback = linkPath
+			}
+			continue
+		}
+		return linkPath
+	}
+	return fallback
+}
+
 func hasValidSmartData(data map[string]interface{}) bool {
 	if _, ok := data["device"]; !ok {
 		return false
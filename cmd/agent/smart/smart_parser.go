@@ -2,6 +2,7 @@ package smart
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -24,6 +25,7 @@ type DriveSmartData struct {
 	Hostname        string           `json:"hostname"`
 	SerialNumber    string           `json:"serial_number"`
 	DeviceName      string           `json:"device_name"`
+	DeviceByID      string           `json:"device_by_id,omitempty"` // stable /dev/disk/by-id path
 	ModelName       string           `json:"model_name"`
 	FirmwareVersion string           `json:"firmware_version"`
 	DriveType       string           `json:"drive_type"` // HDD, SSD, NVMe
@@ -32,6 +34,7 @@ type DriveSmartData struct {
 	Temperature     int              `json:"temperature"`
 	PowerOnHours    int64            `json:"power_on_hours"`
 	PowerCycles     int64            `json:"power_cycles"`
+	SmartctlVersion string           `json:"smartctl_version,omitempty"` // e.g. "7.3"; empty if smartctl didn't say
 	SmartPassed     bool             `json:"smart_passed"`
 	Attributes      []SmartAttribute `json:"attributes"`
 	Timestamp       time.Time        `json:"timestamp"`
@@ -369,6 +372,8 @@ func ParseSmartAttributes(data map[string]interface{}, hostname string) (*DriveS
 		Timestamp:  time.Now().UTC(),
 	}
 
+	result.SmartctlVersion = SmartctlVersion(data)
+
 	// Extract device information
 	extractDeviceInfo(data, result)
 
@@ -391,13 +396,66 @@ func ParseSmartAttributes(data map[string]interface{}, hostname string) (*DriveS
 	return result, nil
 }
 
-// extractDeviceInfo extracts basic device information
+// SmartctlVersion returns the smartctl version that produced data, from its
+// "smartctl" block (e.g. {"version": [7, 3]} → "7.3"), or "" if absent.
+func SmartctlVersion(data map[string]interface{}) string {
+	block, ok := data["smartctl"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	parts, ok := block["version"].([]interface{})
+	if !ok || len(parts) == 0 {
+		return ""
+	}
+	version := ""
+	for i, p := range parts {
+		n, ok := p.(float64)
+		if !ok {
+			return ""
+		}
+		if i > 0 {
+			version += "."
+		}
+		version += fmt.Sprint(int(n))
+	}
+	return version
+}
+
+// firstString returns the first non-empty string among keys in data. The
+// parser uses it for fields smartctl has renamed between versions.
+func firstString(data map[string]interface{}, keys ...string) string {
+	for _, k := range keys {
+		if v, ok := data[k].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ModelName returns a drive's model from smartctl output. SCSI drives:
+// smartctl 7.3 added scsi_model_name and renamed vendor/product to
+// scsi_vendor/scsi_product; older versions only have the latter pair.
+func ModelName(data map[string]interface{}) string {
+	if model := firstString(data, "model_name", "model_family", "scsi_model_name"); model != "" {
+		return model
+	}
+	vendor := firstString(data, "scsi_vendor", "vendor")
+	product := firstString(data, "scsi_product", "product")
+	return strings.TrimSpace(vendor + " " + product)
+}
+
+// extractDeviceInfo extracts basic device information. Field names differ
+// by smartctl version and device class; each field lists its fallbacks
+// newest-first.
 func extractDeviceInfo(data map[string]interface{}, result *DriveSmartData) {
 	// Device name
 	if device, ok := data["device"].(map[string]interface{}); ok {
 		if name, ok := device["name"].(string); ok {
 			result.DeviceName = name
 		}
+		if byID, ok := device["by_id"].(string); ok {
+			result.DeviceByID = byID
+		}
 	}
 
 	// Serial number
@@ -405,31 +463,28 @@ func extractDeviceInfo(data map[string]interface{}, result *DriveSmartData) {
 		result.SerialNumber = serial
 	}
 
-	// Model name (try multiple fields)
-	if model, ok := data["model_name"].(string); ok {
-		result.ModelName = model
-	} else if model, ok := data["model_family"].(string); ok {
-		result.ModelName = model
-	} else if model, ok := data["scsi_model_name"].(string); ok {
-		result.ModelName = model
-	}
+	result.ModelName = ModelName(data)
 
-	// Firmware version
-	if fw, ok := data["firmware_version"].(string); ok {
-		result.FirmwareVersion = fw
-	}
+	// Firmware version (SCSI: scsi_revision since 7.3, revision before)
+	result.FirmwareVersion = firstString(data, "firmware_version", "scsi_revision", "revision")
 
 	// Rotation rate
 	if rate, ok := data["rotation_rate"].(float64); ok {
 		result.RotationRate = int(rate)
 	}
 
-	// Capacity
+	// Capacity: user_capacity.bytes, else NVMe's total capacity (some NVMe
+	// controllers only report that one).
 	if capacity, ok := data["user_capacity"].(map[string]interface{}); ok {
 		if bytes, ok := capacity["bytes"].(float64); ok {
 			result.Capacity = int64(bytes)
 		}
 	}
+	if result.Capacity == 0 {
+		if bytes, ok := data["nvme_total_capacity"].(float64); ok {
+			result.Capacity = int64(bytes)
+		}
+	}
 }
 
 // extractSmartStatus extracts SMART overall status
@@ -731,10 +786,15 @@ func extractAdditionalMetrics(data map[string]interface{}, result *DriveSmartDat
 		}
 	}
 
-	// Power cycle count from root level
+	// Power cycle count from root level; SCSI drives report start/stop
+	// cycles instead.
 	if result.PowerCycles == 0 {
 		if pcc, ok := data["power_cycle_count"].(float64); ok {
 			result.PowerCycles = int64(pcc)
+		} else if ss, ok := data["scsi_start_stop_cycle_counter"].(map[string]interface{}); ok {
+			if n, ok := ss["accumulated_start_stop_cycles"].(float64); ok {
+				result.PowerCycles = int64(n)
+			}
 		}
 	}
 }
@@ -763,8 +823,30 @@ func GetAttributeDefinition(id int) (CriticalAttribute, bool) {
 	return def, exists
 }
 
-// GetAttributeSeverity determines severity level of an attribute based on its value
+// AccumulatingCounters are warning-level error counters that only ever grow.
+// Their lifetime total says little once the cause (e.g. a bad cable) is fixed,
+// so their severity is judged on the recent increase when history is known.
+var AccumulatingCounters = map[int]bool{
+	11:  true, // Calibration Retry Count
+	199: true, // UltraDMA CRC Error Count
+}
+
+// GetAttributeSeverity determines severity level of an attribute based on its value.
+// Custom rules that aren't scoped to a model are consulted as well.
 func GetAttributeSeverity(id int, rawValue int64, value int, threshold int) string {
+	return GetModelAttributeSeverity(id, "", rawValue, value, threshold)
+}
+
+// GetModelAttributeSeverity is GetAttributeSeverity for a drive of the
+// given model: the worse of the built-in rating and every custom rule that
+// applies to the attribute on that model.
+func GetModelAttributeSeverity(id int, model string, rawValue int64, value int, threshold int) string {
+	return WorseSeverity(builtinAttributeSeverity(id, rawValue, value, threshold),
+		customAttributeSeverity(id, model, rawValue, value))
+}
+
+// builtinAttributeSeverity rates an attribute against CriticalAttributeDefinitions.
+func builtinAttributeSeverity(id int, rawValue int64, value int, threshold int) string {
 	def, exists := CriticalAttributeDefinitions[id]
 
 	// Check if normalized value has hit threshold (SMART failure)
@@ -852,6 +934,14 @@ func GetAttributeSeverity(id int, rawValue int64, value int, threshold int) stri
 
 // AnalyzeDriveHealth performs comprehensive health analysis on drive data
 func AnalyzeDriveHealth(driveData *DriveSmartData) *DriveHealthAnalysis {
+	return AnalyzeDriveHealthWithHistory(driveData, nil)
+}
+
+// AnalyzeDriveHealthWithHistory is AnalyzeDriveHealth with the recent raw
+// increase of accumulating counters, keyed by attribute ID. Counters with a
+// known increase are rated on that increase instead of their lifetime total;
+// counters missing from the map fall back to the absolute value.
+func AnalyzeDriveHealthWithHistory(driveData *DriveSmartData, increases map[int]int64) *DriveHealthAnalysis {
 	analysis := &DriveHealthAnalysis{
 		Hostname:      driveData.Hostname,
 		SerialNumber:  driveData.SerialNumber,
@@ -878,7 +968,16 @@ func AnalyzeDriveHealth(driveData *DriveSmartData) *DriveHealthAnalysis {
 
 	// Analyze each attribute
 	for _, attr := range driveData.Attributes {
-		severity := GetAttributeSeverity(attr.ID, attr.RawValue, attr.Value, attr.Threshold)
+		severity := GetModelAttributeSeverity(attr.ID, driveData.ModelName, attr.RawValue, attr.Value, attr.Threshold)
+		if isNVMeSpareWithThreshold(driveData, attr) {
+			severity = WorseSeverity(nvmeSpareSeverity(attr),
+				customAttributeSeverity(attr.ID, driveData.ModelName, attr.RawValue, attr.Value))
+		}
+		var recent *int64
+		if inc, ok := increases[attr.ID]; ok && AccumulatingCounters[attr.ID] {
+			severity = GetModelAttributeSeverity(attr.ID, driveData.ModelName, inc, attr.Value, attr.Threshold)
+			recent = &inc
+		}
 
 		switch severity {
 		case SeverityCritical:
@@ -889,6 +988,7 @@ func AnalyzeDriveHealth(driveData *DriveSmartData) *DriveHealthAnalysis {
 				Severity:      SeverityCritical,
 				RawValue:      attr.RawValue,
 				Threshold:     attr.Threshold,
+				Increase:      recent,
 				Message:       generateIssueMessage(attr, severity),
 			})
 		case SeverityWarning:
@@ -899,6 +999,7 @@ func AnalyzeDriveHealth(driveData *DriveSmartData) *DriveHealthAnalysis {
 				Severity:      SeverityWarning,
 				RawValue:      attr.RawValue,
 				Threshold:     attr.Threshold,
+				Increase:      recent,
 				Message:       generateIssueMessage(attr, severity),
 			})
 		}
@@ -914,6 +1015,23 @@ func AnalyzeDriveHealth(driveData *DriveSmartData) *DriveHealthAnalysis {
 	return analysis
 }
 
+// isNVMeSpareWithThreshold reports whether attr is an NVMe drive's available
+// spare and the drive declared its available_spare_threshold.
+func isNVMeSpareWithThreshold(driveData *DriveSmartData, attr SmartAttribute) bool {
+	return driveData.DriveType == DriveTypeNVMe && attr.ID == NVMeAttrAvailableSpare && attr.Threshold > 0
+}
+
+// nvmeSpareSeverity rates an NVMe drive's available spare against the
+// threshold the drive itself declares instead of the generic reserved-space
+// percentages: below it the controller considers the drive in danger and
+// sets bit 0 of its critical warning.
+func nvmeSpareSeverity(attr SmartAttribute) string {
+	if attr.Value < attr.Threshold {
+		return SeverityCritical
+	}
+	return SeverityHealthy
+}
+
 // DriveHealthAnalysis represents comprehensive health analysis results
 type DriveHealthAnalysis struct {
 	Hostname      string        `json:"hostname"`
@@ -935,6 +1053,7 @@ type HealthIssue struct {
 	Severity      string `json:"severity"`
 	RawValue      int64  `json:"raw_value"`
 	Threshold     int    `json:"threshold,omitempty"`
+	Increase      *int64 `json:"increase,omitempty"` // recent raw increase of an accumulating counter
 	Message       string `json:"message"`
 }
 
@@ -960,7 +1079,11 @@ func generateIssueMessage(attr SmartAttribute, severity string) string {
 		case 199:
 			message = fmt.Sprintf("%d CRC errors detected (check cables)", attr.RawValue)
 		case 232:
-			message = fmt.Sprintf("Only %d%% reserved space remaining", attr.RawValue)
+			if attr.Threshold > 0 && attr.Value < attr.Threshold {
+				message = fmt.Sprintf("Available spare %d%% is below the drive's threshold of %d%%", attr.Value, attr.Threshold)
+			} else {
+				message = fmt.Sprintf("Only %d%% reserved space remaining", attr.RawValue)
+			}
 		case 233:
 			message = fmt.Sprintf("Drive is %d%% worn", attr.RawValue)
 		default:
@@ -0,0 +1,100 @@
+package zfs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// iostatSampleSeconds is how long `zpool iostat` samples for. Without an
+// interval it reports averages since import, which hide current load.
+const iostatSampleSeconds = 5
+
+// GetPoolIOStats samples read/write activity of the named pools and their
+// vdevs, keyed by pool name. It blocks for iostatSampleSeconds.
+func GetPoolIOStats(poolNames []string) (map[string]*PoolIOStats, error) {
+	if len(poolNames) == 0 {
+		return map[string]*PoolIOStats{}, nil
+	}
+
+	zpoolPath := findZpoolCommand()
+	if zpoolPath == "" {
+		return nil, fmt.Errorf("zpool command not found")
+	}
+
+	// -H: no header, tab-separated; -p: exact values; -v: per vdev;
+	// -y: skip the since-import report, keeping only the sampled one
+	cmd := exec.Command(zpoolPath, "iostat", "-H", "-p", "-v", "-y", strconv.Itoa(iostatSampleSeconds), "1")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if strings.Contains(stderr.String(), "no pools available") {
+			return map[string]*PoolIOStats{}, nil
+		}
+		return nil, fmt.Errorf("zpool iostat failed: %v - %s", err, stderr.String())
+	}
+
+	return parsePoolIOStats(stdout.String(), poolNames), nil
+}
+
+// parsePoolIOStats parses `zpool iostat -H -p -v` output (name, alloc, free,
+// read ops, write ops, read bytes, write bytes per line). Scripted output
+// has no separators between pools, so a line whose name is one of
+// poolNames starts a new pool and the lines after it are its vdevs.
+// Section headers (logs, cache, spares) carry no numbers and are skipped.
+func parsePoolIOStats(output string, poolNames []string) map[string]*PoolIOStats {
+	isPool := make(map[string]bool, len(poolNames))
+	for _, name := range poolNames {
+		isPool[name] = true
+	}
+
+	stats := make(map[string]*PoolIOStats)
+	var current *PoolIOStats
+	scanner := bufio.NewScanner(strings.NewReader(output))
+
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 7 {
+			continue
+		}
+		name := strings.TrimSpace(fields[0])
+		io, ok := parseIOStatFields(fields[3:7])
+
+		if isPool[name] && stats[name] == nil {
+			current = &PoolIOStats{IOStats: io}
+			stats[name] = current
+			continue
+		}
+		if current == nil || !ok {
+			continue
+		}
+		current.Vdevs = append(current.Vdevs, VdevIOStats{Name: name, IOStats: io})
+	}
+
+	return stats
+}
+
+// parseIOStatFields reads read ops, write ops, read bytes and write bytes.
+// Rates may be fractional; they're rounded down.
+func parseIOStatFields(fields []string) (IOStats, bool) {
+	var values [4]int64
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			return IOStats{}, false
+		}
+		values[i] = int64(v)
+	}
+	return IOStats{
+		ReadOps:    values[0],
+		WriteOps:   values[1],
+		ReadBytes:  values[2],
+		WriteBytes: values[3],
+	}, true
+}
@@ -0,0 +1,72 @@
+package zfs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// reportedPoolProperties is the subset of `zpool get all` sent to the
+// server: the settings that are easy to get wrong and that pool health
+// alone won't reveal. Features, GUIDs and sizes are left out.
+var reportedPoolProperties = map[string]bool{
+	"ashift":        true,
+	"autoexpand":    true,
+	"autoreplace":   true,
+	"autotrim":      true,
+	"cachefile":     true,
+	"compatibility": true,
+	"failmode":      true,
+	"listsnapshots": true,
+	"multihost":     true,
+	"readonly":      true,
+}
+
+// GetPoolProperties returns the reported properties of every pool, keyed by
+// pool name.
+func GetPoolProperties() (map[string][]PoolProperty, error) {
+	zpoolPath := findZpoolCommand()
+	if zpoolPath == "" {
+		return nil, fmt.Errorf("zpool command not found")
+	}
+
+	// -H: no header, tab-separated; -p: exact values
+	cmd := exec.Command(zpoolPath, "get", "-H", "-p", "all")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if strings.Contains(stderr.String(), "no pools available") {
+			return map[string][]PoolProperty{}, nil
+		}
+		return nil, fmt.Errorf("zpool get failed: %v - %s", err, stderr.String())
+	}
+
+	return parsePoolProperties(stdout.String()), nil
+}
+
+// parsePoolProperties parses `zpool get -H -p all` output
+// (name, property, value, source per line), keeping reportedPoolProperties.
+func parsePoolProperties(output string) map[string][]PoolProperty {
+	props := make(map[string][]PoolProperty)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 3 || !reportedPoolProperties[fields[1]] {
+			continue
+		}
+
+		p := PoolProperty{Name: fields[1], Value: fields[2], Source: "-"}
+		if len(fields) > 3 {
+			p.Source = fields[3]
+		}
+		props[fields[0]] = append(props[fields[0]], p)
+	}
+
+	return props
+}
@@ -6,29 +6,60 @@ import "time"
 
 // Pool represents a ZFS storage pool
 type Pool struct {
-	Hostname       string    `json:"hostname"`
-	Name           string    `json:"name"`
-	GUID           string    `json:"guid,omitempty"`
-	Status         string    `json:"status"`          // ONLINE, DEGRADED, FAULTED, OFFLINE, REMOVED, UNAVAIL
-	Health         string    `json:"health"`          // ONLINE, DEGRADED, FAULTED
-	Size           int64     `json:"size_bytes"`      // Total size in bytes
-	Allocated      int64     `json:"allocated_bytes"` // Used space in bytes
-	Free           int64     `json:"free_bytes"`      // Free space in bytes
-	Fragmentation  int       `json:"fragmentation"`   // Fragmentation percentage
-	CapacityPct    int       `json:"capacity_pct"`    // Capacity percentage used
-	DedupRatio     float64   `json:"dedup_ratio"`     // Deduplication ratio
-	Altroot        string    `json:"altroot,omitempty"`
-	ReadErrors     int64     `json:"read_errors"`
-	WriteErrors    int64     `json:"write_errors"`
-	ChecksumErrors int64     `json:"checksum_errors"`
-	Scan           *ScanInfo `json:"scan,omitempty"`
-	Devices        []Device  `json:"devices,omitempty"`
-	LastSeen       time.Time `json:"last_seen"`
-}
-
-// ScanInfo represents scrub or resilver operation status
+	Hostname       string     `json:"hostname"`
+	Name           string     `json:"name"`
+	GUID           string     `json:"guid,omitempty"`
+	Status         string     `json:"status"`          // ONLINE, DEGRADED, FAULTED, OFFLINE, REMOVED, UNAVAIL
+	Health         string     `json:"health"`          // ONLINE, DEGRADED, FAULTED
+	Size           int64      `json:"size_bytes"`      // Total size in bytes
+	Allocated      int64      `json:"allocated_bytes"` // Used space in bytes
+	Free           int64      `json:"free_bytes"`      // Free space in bytes
+	Fragmentation  int        `json:"fragmentation"`   // Fragmentation percentage
+	CapacityPct    int        `json:"capacity_pct"`    // Capacity percentage used
+	DedupRatio     float64    `json:"dedup_ratio"`     // Deduplication ratio
+	Altroot        string     `json:"altroot,omitempty"`
+	ReadErrors     int64      `json:"read_errors"`
+	WriteErrors    int64      `json:"write_errors"`
+	ChecksumErrors int64      `json:"checksum_errors"`
+	Scan           *ScanInfo  `json:"scan,omitempty"`
+	Operations     []ScanInfo `json:"operations,omitempty"` // Per-vdev trim/initialize progress, one entry per type
+	Devices        []Device   `json:"devices,omitempty"`
+	Properties     []PoolProperty `json:"properties,omitempty"` // Selected `zpool get` properties
+	IOStats        *PoolIOStats   `json:"iostats,omitempty"`    // `zpool iostat` sample, nil if unavailable
+	LastSeen       time.Time  `json:"last_seen"`
+}
+
+// PoolProperty is one pool property as reported by `zpool get`
+type PoolProperty struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"` // default, local, -
+}
+
+// IOStats is read/write activity averaged over the iostat sample window
+type IOStats struct {
+	ReadOps    int64 `json:"read_ops"`    // Read operations per second
+	WriteOps   int64 `json:"write_ops"`   // Write operations per second
+	ReadBytes  int64 `json:"read_bytes"`  // Bytes read per second
+	WriteBytes int64 `json:"write_bytes"` // Bytes written per second
+}
+
+// PoolIOStats is a pool's I/O activity and the breakdown per vdev and leaf
+// device, in `zpool iostat -v` order
+type PoolIOStats struct {
+	IOStats
+	Vdevs []VdevIOStats `json:"vdevs,omitempty"`
+}
+
+// VdevIOStats is the I/O activity of one vdev or leaf device
+type VdevIOStats struct {
+	Name string `json:"name"`
+	IOStats
+}
+
+// ScanInfo represents scrub, resilver, trim or initialize operation status
 type ScanInfo struct {
-	Function      string    `json:"function"` // scrub, resilver, none
+	Function      string    `json:"function"` // scrub, resilver, trim, initialize, none
 	State         string    `json:"state"`    // scanning, finished, canceled, none
 	StartTime     time.Time `json:"start_time"`
 	EndTime       time.Time `json:"end_time,omitempty"`
@@ -50,10 +81,10 @@ type Device struct {
 	Path           string   `json:"path,omitempty"` // Full path (e.g., /dev/sda)
 	GUID           string   `json:"guid,omitempty"`
 	SerialNumber   string   `json:"serial_number,omitempty"` // Linked from SMART
-	VdevType       string   `json:"vdev_type"`               // disk, mirror, raidz1, raidz2, raidz3, spare, log, cache
+	VdevType       string   `json:"vdev_type"`               // disk, mirror, raidz1-3, draid1-3, spare, log, cache, special, dedup
 	VdevParent     string   `json:"vdev_parent,omitempty"`   // Parent vdev name for nested structures
 	VdevIndex      int      `json:"vdev_index"`              // Position in vdev
-	State          string   `json:"state"`                   // ONLINE, DEGRADED, FAULTED, OFFLINE, REMOVED, UNAVAIL
+	State          string   `json:"state"`                   // ONLINE, DEGRADED, FAULTED, OFFLINE, REMOVED, UNAVAIL; spares AVAIL, INUSE
 	ReadErrors     int64    `json:"read_errors"`
 	WriteErrors    int64    `json:"write_errors"`
 	ChecksumErrors int64    `json:"checksum_errors"`
@@ -98,7 +129,7 @@ type ScrubRecord struct {
 	PoolID         int64     `json:"pool_id"`
 	Hostname       string    `json:"hostname"`
 	PoolName       string    `json:"pool_name"`
-	ScanType       string    `json:"scan_type"` // scrub, resilver
+	ScanType       string    `json:"scan_type"` // scrub, resilver, trim, initialize
 	State          string    `json:"state"`     // finished, canceled, in_progress
 	StartTime      time.Time `json:"start_time"`
 	EndTime        time.Time `json:"end_time,omitempty"`
@@ -124,10 +155,16 @@ const (
 	StateRemoved  = "REMOVED"
 	StateUnavail  = "UNAVAIL"
 
+	// Hot spare states, shown in the spares section
+	StateAvail = "AVAIL" // ready to take over
+	StateInUse = "INUSE" // standing in for a failed disk
+
 	// Scan Functions
 	ScanNone     = "none"
 	ScanScrub    = "scrub"
 	ScanResilver = "resilver"
+	ScanTrim     = "trim"
+	ScanInit     = "initialize"
 
 	// Scan States
 	ScanStateNone     = "none"
@@ -136,14 +173,19 @@ const (
 	ScanStateCanceled = "canceled"
 
 	// Vdev Types
-	VdevTypeDisk   = "disk"
-	VdevTypeMirror = "mirror"
-	VdevTypeRaidz1 = "raidz1"
-	VdevTypeRaidz2 = "raidz2"
-	VdevTypeRaidz3 = "raidz3"
-	VdevTypeSpare  = "spare"
-	VdevTypeLog    = "log"
-	VdevTypeCache  = "cache"
+	VdevTypeDisk    = "disk"
+	VdevTypeMirror  = "mirror"
+	VdevTypeRaidz1  = "raidz1"
+	VdevTypeRaidz2  = "raidz2"
+	VdevTypeRaidz3  = "raidz3"
+	VdevTypeDraid1  = "draid1"
+	VdevTypeDraid2  = "draid2"
+	VdevTypeDraid3  = "draid3"
+	VdevTypeSpare   = "spare"
+	VdevTypeLog     = "log"
+	VdevTypeCache   = "cache"
+	VdevTypeSpecial = "special"
+	VdevTypeDedup   = "dedup"
 )
 
 // ─── Helper Functions ────────────────────────────────────────────────────────
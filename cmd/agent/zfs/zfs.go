@@ -79,6 +79,50 @@ func IsZFSAvailable() bool {
 	return findZpoolCommand() != ""
 }
 
+// ─── Error Clearing ──────────────────────────────────────────────────────────
+
+// ClearErrors runs `zpool clear` for a pool, or for a single device within it
+// when device is non-empty, resetting the read/write/checksum counters.
+func ClearErrors(pool, device string) (string, error) {
+	zpoolPath := findZpoolCommand()
+	if zpoolPath == "" {
+		return "", fmt.Errorf("zpool command not found")
+	}
+	if !validZFSName(pool) {
+		return "", fmt.Errorf("invalid pool name %q", pool)
+	}
+	args := []string{"clear", pool}
+	if device != "" {
+		if !validZFSName(device) {
+			return "", fmt.Errorf("invalid device name %q", device)
+		}
+		args = append(args, device)
+	}
+
+	out, err := exec.Command(zpoolPath, args...).CombinedOutput() // #nosec G204 -- names validated above
+	if err != nil {
+		return string(out), fmt.Errorf("zpool clear failed: %v", err)
+	}
+	return string(out), nil
+}
+
+// validZFSName rejects anything that is not a plausible pool or vdev name,
+// including option-like values starting with "-".
+func validZFSName(name string) bool {
+	if name == "" || strings.HasPrefix(name, "-") {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '_', c == '-', c == '.', c == ':', c == '/':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // ─── Pool List Parsing ───────────────────────────────────────────────────────
 
 func ListPools() ([]Pool, error) {
@@ -160,28 +204,34 @@ func GetPoolStatus(poolName string) (*Pool, error) {
 		return nil, fmt.Errorf("zpool command not found")
 	}
 
-	// Try with -L flag first (shows device names instead of GUIDs)
+	// Try the richest flag set first and fall back for older ZFS releases
 	// -L: Display real paths for vdevs resolving all symbolic links
 	// -P: Display real paths for vdevs instead of only the last component
-	cmd := exec.Command(zpoolPath, "status", "-v", "-p", "-L", "-P", poolName)
+	// -t: Display vdev TRIM status (OpenZFS 0.8+)
+	// -i: Display vdev initialization status (OpenZFS 2.2+, shown by default before)
+	attempts := [][]string{
+		{"status", "-v", "-p", "-L", "-P", "-t", "-i", poolName},
+		{"status", "-v", "-p", "-L", "-P", "-t", poolName},
+		{"status", "-v", "-p", "-L", "-P", poolName},
+		{"status", "-v", "-p", poolName},
+	}
 
 	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
-	if err != nil {
-		// If -L/-P flags not supported, try without them
-		cmd = exec.Command(zpoolPath, "status", "-v", "-p", poolName)
+	var err error
+	for _, args := range attempts {
 		stdout.Reset()
 		stderr.Reset()
+		cmd := exec.Command(zpoolPath, args...)
 		cmd.Stdout = &stdout
 		cmd.Stderr = &stderr
 
-		if err := cmd.Run(); err != nil {
-			return nil, fmt.Errorf("zpool status failed: %v - %s", err, stderr.String())
+		if err = cmd.Run(); err == nil {
+			break
 		}
 	}
+	if err != nil {
+		return nil, fmt.Errorf("zpool status failed: %v - %s", err, stderr.String())
+	}
 
 	return parsePoolStatus(poolName, stdout.String())
 }
@@ -196,6 +246,7 @@ func parsePoolStatus(poolName, output string) (*Pool, error) {
 	var inConfig bool
 	var currentVdev *Device
 	var vdevStack []*Device
+	var vdevOps []ScanInfo
 
 	for i := 0; i < len(lines); i++ {
 		line := lines[i]
@@ -232,9 +283,14 @@ func parsePoolStatus(poolName, output string) (*Pool, error) {
 			if device != nil {
 				addDeviceToPool(pool, device, &currentVdev, &vdevStack, line)
 			}
+			if op := parseVdevOperation(trimmed); op != nil {
+				vdevOps = append(vdevOps, *op)
+			}
 		}
 	}
 
+	pool.Operations = aggregateVdevOperations(vdevOps)
+
 	return pool, nil
 }
 
@@ -262,10 +318,15 @@ func parseScanLine(firstLine string, lines []string, lineIdx *int) *ScanInfo {
 		return scan
 	}
 
-	if strings.Contains(lowerText, "resilver") {
+	switch {
+	case strings.Contains(lowerText, "resilver"):
 		scan.Function = ScanResilver
-	} else if strings.Contains(lowerText, "scrub") {
+	case strings.Contains(lowerText, "scrub"):
 		scan.Function = ScanScrub
+	case strings.Contains(lowerText, "trim"):
+		scan.Function = ScanTrim
+	case strings.Contains(lowerText, "initializ"):
+		scan.Function = ScanInit
 	}
 
 	if strings.Contains(lowerText, "in progress") {
@@ -318,6 +379,100 @@ func parseScanLine(firstLine string, lines []string, lineIdx *int) *ScanInfo {
 	return scan
 }
 
+// parseVdevOperation parses the trailing trim/initialize annotation that
+// `zpool status -t -i` appends to a vdev line, e.g.
+// "(45% trimmed, started at Mon Jan  1 10:00:00 2024)" or "(initializing)".
+// Vdevs that are untrimmed, uninitialized or don't support TRIM return nil.
+func parseVdevOperation(line string) *ScanInfo {
+	openIdx := strings.LastIndex(line, "(")
+	closeIdx := strings.LastIndex(line, ")")
+	if openIdx < 0 || closeIdx < openIdx {
+		return nil
+	}
+	note := line[openIdx : closeIdx+1]
+	lower := strings.ToLower(note)
+
+	op := &ScanInfo{}
+	switch {
+	case strings.Contains(lower, "untrimmed"), strings.Contains(lower, "uninitialized"),
+		strings.Contains(lower, "unsupported"):
+		return nil
+	case strings.Contains(lower, "trim"):
+		op.Function = ScanTrim
+	case strings.Contains(lower, "initializ"):
+		op.Function = ScanInit
+	default:
+		return nil
+	}
+
+	switch {
+	case strings.Contains(lower, "completed"):
+		op.State = ScanStateFinished
+		op.EndTime = parseZFSTimestamp(note, "completed at")
+	case strings.Contains(lower, "canceled"), strings.Contains(lower, "cancelled"):
+		op.State = ScanStateCanceled
+	default:
+		op.State = ScanStateScanning
+	}
+
+	op.StartTime = parseZFSTimestamp(note, "started at")
+	op.ProgressPct = parsePercentage(lower, "% trimmed")
+	if op.Function == ScanInit {
+		op.ProgressPct = parsePercentage(lower, "% initialized")
+	}
+	if op.ProgressPct == 0 && op.State == ScanStateFinished {
+		op.ProgressPct = 100.0
+	}
+
+	return op
+}
+
+// aggregateVdevOperations folds per-vdev trim/initialize progress into one
+// entry per operation type. The pool-level operation is in progress while
+// any vdev is still working, and its progress is the mean across vdevs.
+func aggregateVdevOperations(ops []ScanInfo) []ScanInfo {
+	var result []ScanInfo
+	for _, function := range []string{ScanTrim, ScanInit} {
+		var agg *ScanInfo
+		var count int
+		var totalPct float64
+		for _, op := range ops {
+			if op.Function != function {
+				continue
+			}
+			if agg == nil {
+				agg = &ScanInfo{Function: function, State: op.State}
+			}
+			count++
+			totalPct += op.ProgressPct
+
+			if op.State == ScanStateScanning {
+				agg.State = ScanStateScanning
+			} else if op.State == ScanStateCanceled && agg.State != ScanStateScanning {
+				agg.State = ScanStateCanceled
+			}
+			if !op.StartTime.IsZero() && (agg.StartTime.IsZero() || op.StartTime.Before(agg.StartTime)) {
+				agg.StartTime = op.StartTime
+			}
+			if op.EndTime.After(agg.EndTime) {
+				agg.EndTime = op.EndTime
+			}
+		}
+		if agg == nil {
+			continue
+		}
+
+		agg.ProgressPct = totalPct / float64(count)
+		if agg.State != ScanStateFinished {
+			agg.EndTime = time.Time{}
+		} else if !agg.StartTime.IsZero() && !agg.EndTime.IsZero() {
+			agg.Duration = int64(agg.EndTime.Sub(agg.StartTime).Seconds())
+		}
+		result = append(result, *agg)
+	}
+	return result
+}
+
 func parseZFSTimestamp(text, keyword string) time.Time {
 	lowerText := strings.ToLower(text)
 	idx := strings.Index(lowerText, keyword+" ")
@@ -495,7 +650,9 @@ func parseDeviceLine(line string) *Device {
 	}
 
 	fields := strings.Fields(trimmed)
-	if len(fields) < 2 {
+	// Section headers (spares, logs, cache) are the only lines without a
+	// state column.
+	if len(fields) < 2 && !isSectionHeader(fields[0]) {
 		return nil
 	}
 
@@ -513,7 +670,7 @@ func parseDeviceLine(line string) *Device {
 	if len(fields) >= 2 {
 		state := strings.ToUpper(fields[1])
 		switch state {
-		case "ONLINE", "DEGRADED", "FAULTED", "OFFLINE", "REMOVED", "UNAVAIL":
+		case "ONLINE", "DEGRADED", "FAULTED", "OFFLINE", "REMOVED", "UNAVAIL", StateAvail, StateInUse:
 			device.State = state
 		}
 	}
@@ -534,9 +691,29 @@ func parseDeviceLine(line string) *Device {
 		device.VdevType = VdevTypeRaidz2
 	case strings.HasPrefix(nameLower, "raidz"):
 		device.VdevType = VdevTypeRaidz1
-	case nameLower == "spares" || strings.HasPrefix(nameLower, "spare"):
+	case strings.HasPrefix(nameLower, "draid") && strings.Contains(nameLower, ":"):
+		// draid2:4d:8c:1s-0; the distributed spares (draid2-0-0) listed
+		// under spares have no colon and stay disks.
+		switch {
+		case strings.HasPrefix(nameLower, "draid3"):
+			device.VdevType = VdevTypeDraid3
+		case strings.HasPrefix(nameLower, "draid2"):
+			device.VdevType = VdevTypeDraid2
+		default:
+			device.VdevType = VdevTypeDraid1
+		}
+	case nameLower == "special":
+		device.VdevType = VdevTypeSpecial
+	case nameLower == "dedup":
+		device.VdevType = VdevTypeDedup
+	case nameLower == "spares":
 		device.VdevType = VdevTypeSpare
 		device.IsSpare = true
+	case strings.HasPrefix(nameLower, "spare-"):
+		// An activated spare: spare-N groups the failed disk with the spare
+		// standing in for it inside a data vdev. Like replacing-N, its
+		// members stay children of the enclosing vdev.
+		device.VdevType = VdevTypeDisk
 	case nameLower == "logs" || strings.HasPrefix(nameLower, "log"):
 		device.VdevType = VdevTypeLog
 		device.IsLog = true
@@ -553,6 +730,16 @@ func parseDeviceLine(line string) *Device {
 	return device
 }
 
+// isSectionHeader reports whether name heads the spares, log, cache,
+// special or dedup section of zpool status' config.
+func isSectionHeader(name string) bool {
+	switch name {
+	case "spares", "logs", "cache", "special", "dedup":
+		return true
+	}
+	return false
+}
+
 // resolveDeviceName converts GUIDs, paths, or symlinks to actual device names
 func resolveDeviceName(name string) (deviceName string, devicePath string) {
 	// If it's already a simple device name (sda, nvme0n1, etc.), use it
@@ -758,6 +945,9 @@ func addDeviceToPool(pool *Pool, device *Device, currentVdev **Device, vdevStack
 
 	if level <= 3 && (device.VdevType == VdevTypeMirror || device.VdevType == VdevTypeRaidz1 ||
 		device.VdevType == VdevTypeRaidz2 || device.VdevType == VdevTypeRaidz3 ||
+		device.VdevType == VdevTypeDraid1 || device.VdevType == VdevTypeDraid2 ||
+		device.VdevType == VdevTypeDraid3 || device.VdevType == VdevTypeSpecial ||
+		device.VdevType == VdevTypeDedup ||
 		device.VdevType == VdevTypeSpare || device.VdevType == VdevTypeLog ||
 		device.VdevType == VdevTypeCache) {
 		pool.Devices = append(pool.Devices, *device)
@@ -910,6 +1100,24 @@ func CollectZFSData(hostname string) (*ZFSReport, error) {
 		}
 	}
 
+	// Properties are best-effort too: older zpool builds may reject some.
+	if props, err := GetPoolProperties(); err == nil {
+		for i := range pools {
+			pools[i].Properties = props[pools[i].Name]
+		}
+	}
+
+	// I/O stats are best-effort as well.
+	names := make([]string, len(pools))
+	for i := range pools {
+		names[i] = pools[i].Name
+	}
+	if stats, err := GetPoolIOStats(names); err == nil {
+		for i := range pools {
+			pools[i].IOStats = stats[pools[i].Name]
+		}
+	}
+
 	report.Pools = pools
 
 	// Datasets are best-effort: a failure here shouldn't drop the pool data.
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -19,15 +20,23 @@ import (
 	"vigil/internal/config"
 	"vigil/internal/crypto"
 	"vigil/internal/db"
+	"vigil/internal/drivealerts"
 	"vigil/internal/drivegroups"
+	"vigil/internal/enclosures"
 	"vigil/internal/events"
 	"vigil/internal/handlers"
+	"vigil/internal/latency"
+	"vigil/internal/logfmt"
 	"vigil/internal/metrics"
 	"vigil/internal/middleware"
 	"vigil/internal/models"
 	"vigil/internal/notify"
+	"vigil/internal/projects"
+	"vigil/internal/recovery"
+	"vigil/internal/relocation"
 	"vigil/internal/settings"
 	"vigil/internal/smart"
+	"vigil/internal/temperature"
 	"vigil/internal/wearout"
 )
 
@@ -39,6 +48,7 @@ func main() {
 	flag.Parse()
 
 	log.SetFlags(log.Ltime | log.Ldate)
+	logfmt.Setup()
 
 	// Handle key rotation before anything else
 	if *rotateKeys {
@@ -72,11 +82,39 @@ func main() {
 
 	cfg := config.Load()
 
+	if err := handlers.SetDisplayTimezone(cfg.DisplayTimezone); err != nil {
+		log.Printf("⚠️  Invalid DISPLAY_TIMEZONE %q, using %s: %v", cfg.DisplayTimezone, handlers.DisplayLocation, err)
+	}
+
+	if cfg.ReportHMACSecret != "" {
+		handlers.ReportHMACSecret = []byte(cfg.ReportHMACSecret)
+		log.Printf("✓ Report signing: X-Vigil-Signature required")
+	}
+
+	if cfg.DBEncryptionKey != "" {
+		if err := crypto.SetReportKey(cfg.DBEncryptionKey); err != nil {
+			log.Fatalf("❌ Invalid DB_ENCRYPTION_KEY: %v", err)
+		}
+		log.Printf("✓ Report encryption at rest: enabled")
+	} else {
+		log.Printf("⚠️  DB_ENCRYPTION_KEY not set: raw reports are stored unencrypted")
+	}
+
+	if cfg.EmergencyWebhook != "" {
+		handlers.EmergencyHook = temperature.NewEmergencyHook(cfg.EmergencyWebhook)
+		log.Printf("✓ Emergency temperature webhook enabled")
+	}
+
 	if err := db.Init(cfg.DBPath); err != nil {
 		log.Fatalf("❌ Database error: %v", err)
 	}
 	defer db.DB.Close()
 	log.Printf("✓ Database: %s", cfg.DBPath)
+	if cfg.DBMaxOpenConns > 0 {
+		db.DB.SetMaxOpenConns(cfg.DBMaxOpenConns)
+		log.Printf("✓ Database connections: max %d", cfg.DBMaxOpenConns)
+	}
+	db.SetSerializeWrites(cfg.DBSerializeWrites)
 
 	// Initialise settings table (must run before anything that reads settings)
 	if err := settings.InitSettingsTable(db.DB); err != nil {
@@ -87,6 +125,9 @@ func main() {
 	if err := smart.MigrateSmartAttributes(db.DB); err != nil {
 		log.Printf("⚠️  SMART migration warning: %v", err)
 	}
+	if err := smart.LoadCustomRules(db.DB); err != nil {
+		log.Printf("⚠️  Custom attribute rules not loaded: %v", err)
+	}
 
 	// Run extended schema migrations
 	if err := db.MigrateSchemaExtensions(db.DB); err != nil {
@@ -118,6 +159,41 @@ func main() {
 		log.Printf("⚠️  Drive groups migration warning: %v", err)
 	}
 
+	// Run enclosures migration
+	if err := enclosures.Migrate(db.DB); err != nil {
+		log.Printf("⚠️  Enclosures migration warning: %v", err)
+	}
+
+	// Run projects migration
+	if err := projects.Migrate(db.DB); err != nil {
+		log.Printf("⚠️  Projects migration warning: %v", err)
+	}
+
+	if err := drivealerts.Migrate(db.DB); err != nil {
+		log.Printf("⚠️  Drive alerts migration warning: %v", err)
+	}
+
+	// Run recovery tracking migration
+	if err := recovery.Migrate(db.DB); err != nil {
+		log.Printf("⚠️  Recovery tracking migration warning: %v", err)
+	}
+
+	// Run drive latency migration
+	if err := latency.Migrate(db.DB); err != nil {
+		log.Printf("⚠️  Latency migration warning: %v", err)
+	}
+
+	// Run drive relocation migration
+	if err := relocation.Migrate(db.DB); err != nil {
+		log.Printf("⚠️  Relocation migration warning: %v", err)
+	}
+
+	// Temperature alert/spike tables: drive purges and alert escalation
+	// query them even when no temperature alerts have been raised yet.
+	if err := temperature.InitializeTables(db.DB); err != nil {
+		log.Printf("⚠️  Temperature migration warning: %v", err)
+	}
+
 	// Load or generate server Ed25519 key pair
 	dataDir := filepath.Dir(cfg.DBPath)
 	if dataDir == "." {
@@ -145,27 +221,47 @@ func main() {
 	// Run the startup retention sweep in the background. On a large/overdue DB
 	// the cleanup DELETEs (and the VACUUM) can take minutes; doing it inline
 	// would block ListenAndServe and leave the server stuck "starting".
-	go runRetentionSweep()
+	go func() {
+		runRetentionSweep()
+		refreshTemperatureBaselines()
+	}()
 
-	// Periodic cleanup (sessions, data retention, backups)
+	// Periodic cleanup (sessions, data retention, backups). Settings are
+	// re-read every pass; POST /api/settings/reload triggers a pass right away.
+	reload := settings.SubscribeReload()
 	go func() {
 		var lastBackupUnix int64
 		ticker := time.NewTicker(1 * time.Hour)
-		for range ticker.C {
+		for {
+			select {
+			case <-ticker.C:
+			case <-reload:
+				log.Printf("🔄 Settings reloaded: running maintenance pass")
+			}
 			auth.CleanupExpiredSessions()
 			agents.CleanupExpiredAgentSessions(db.DB)
 			runRetentionSweep()
+			refreshTemperatureBaselines()
 			handlers.RunScheduledBackup(&lastBackupUnix)
 		}
 	}()
 
-	// Periodic update checking (every 12 hours)
+	// Fleet aggregates the dashboard polls are recomputed off the request path.
+	go handlers.RunAggregateWorker()
+
+	// Periodic update checking (every 12 hours, opt-in via system.update_check_enabled)
 	go func() {
-		// Check immediately on startup
-		if handlers.VersionChecker != nil {
+		if handlers.VersionChecker == nil {
+			return
+		}
+		// The checker has internal caching, so frequent checks from clients won't hit GitHub API
+		handlers.VersionChecker.SetCacheTTL(12 * time.Hour)
+
+		checkForUpdates := func() {
+			if !handlers.VersionChecker.Enabled() {
+				return
+			}
 			log.Printf("🔄 Checking for updates...")
-			// The checker has internal caching, so frequent checks from clients won't hit GitHub API
-			handlers.VersionChecker.SetCacheTTL(12 * time.Hour)
 			info, err := handlers.VersionChecker.Check()
 			if err != nil {
 				log.Printf("⚠️  Update check failed: %v", err)
@@ -176,27 +272,20 @@ func main() {
 			}
 		}
 
-		// Then check every 12 hours
+		// Check immediately on startup, then every 12 hours
+		checkForUpdates()
 		ticker := time.NewTicker(12 * time.Hour)
 		for range ticker.C {
-			if handlers.VersionChecker != nil {
-				log.Printf("🔄 Checking for updates...")
-				info, err := handlers.VersionChecker.Check()
-				if err != nil {
-					log.Printf("⚠️  Update check failed: %v", err)
-				} else if info.UpdateAvailable {
-					log.Printf("📦 Update available: v%s → v%s", info.CurrentVersion, info.LatestVersion)
-				} else {
-					log.Printf("✓ Running latest version: v%s", info.CurrentVersion)
-				}
-			}
+			checkForUpdates()
 		}
 	}()
 
 	// Add-on runtime: event bus, telemetry broker, websocket hub, heartbeat monitor
 	eventBus := events.NewBus()
+	handlers.EventBus = eventBus // report processing (SMART, wearout, relocation) publishes here
 	broker := addons.NewTelemetryBroker()
 	handlers.TelemetryBroker = broker
+	go handlers.RunTelemetryReaper()
 	handlers.WebSocketHub = addons.NewWebSocketHub(db.DB, eventBus, broker)
 	hbm := addons.NewHeartbeatMonitor(db.DB, eventBus, 1*time.Minute, 3)
 	hbm.Start()
@@ -216,11 +305,21 @@ func main() {
 	dispatcher := notify.NewDispatcher(db.DB, eventBus, nil)
 	dispatcher.OnSent = func() { m.NotificationsSent.Add(1) }
 	dispatcher.OnFailed = func() { m.NotificationsFailed.Add(1) }
+	if cfg.SyslogHost != "" {
+		addr := net.JoinHostPort(cfg.SyslogHost, cfg.SyslogPort)
+		sink, err := notify.NewSyslogSink(cfg.SyslogProtocol, addr, cfg.SyslogFacility)
+		if err != nil {
+			log.Fatalf("❌ Invalid syslog configuration: %v", err)
+		}
+		defer sink.Close()
+		dispatcher.Syslog = sink
+		log.Printf("✓ Syslog: every event to %s over %s", addr, cfg.SyslogProtocol)
+	}
 	dispatcher.Start()
 	defer dispatcher.Stop()
 
 	mux := setupRoutes(cfg)
-	handler := middleware.MaxBodySize(1<<20, middleware.RequestID(middleware.Logging(middleware.CORS(middleware.CSRFCheck(mux)))))
+	handler := middleware.MaxBodySize(1<<20, middleware.RequestID(middleware.Logging(middleware.Recover(middleware.CORS(middleware.CSRFCheck(mux))))))
 
 	server := &http.Server{
 		Addr:         ":" + cfg.Port,
@@ -232,6 +331,21 @@ func main() {
 
 	go gracefulShutdown(server)
 
+	if cfg.GRPCPort != "" {
+		lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			log.Fatalf("❌ gRPC listen: %v", err)
+		}
+		grpcServer := handlers.NewReportGRPCServer()
+		go func() {
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Printf("❌ gRPC server error: %v", err)
+			}
+		}()
+		defer grpcServer.GracefulStop()
+		log.Printf("✓ gRPC report ingestion on port %s", cfg.GRPCPort)
+	}
+
 	log.Printf("✓ Listening on port %s", cfg.Port)
 	log.Printf("🌐 Dashboard: http://localhost:%s", cfg.Port)
 
@@ -242,14 +356,14 @@ func main() {
 	log.Println("👋 Server stopped")
 }
 
-// lastVacuum tracks when the DB was last VACUUMed. Retention DELETEs free
-// pages inside the SQLite file but do not shrink it on disk; without a periodic
-// VACUUM the file grows unbounded (observed: vigil.db at ~5.8 GB while retention
-// was working). VACUUM is heavy and briefly locks the DB, so it runs at most
-// once per vacuumInterval, not every hourly sweep.
-var lastVacuum time.Time
-
-const vacuumInterval = 24 * time.Hour
+// vacuumBatchPages is how many free pages one incremental_vacuum step hands
+// back to the filesystem. Retention DELETEs free pages inside the SQLite file
+// but do not shrink it on disk; without reclaiming them the file grows
+// unbounded (observed: vigil.db at ~5.8 GB while retention was working).
+// A full VACUUM rewrites the whole file under the write lock, stalling report
+// ingestion for minutes on a big install, so pages are returned in small
+// steps instead, each under its own lock.
+const vacuumBatchPages = 2000
 
 // runRetentionSweep applies all configured data-retention policies once.
 // Each *_days setting of 0 means "keep forever" and is skipped by the
@@ -263,13 +377,57 @@ func runRetentionSweep() {
 	// attribute per disk per poll). On a multi-host install 90d reached ~20M
 	// rows / multi-GB; SMART history older than ~2 weeks has little diagnostic
 	// value (recent trend + the drive's own cumulative counters suffice).
-	if deleted, err := smart.CleanupOldSmartData(db.DB, settings.GetInt(db.DB, "retention", "smart_data_days", 15)); err != nil {
-		log.Printf("⚠️  SMART/temperature data cleanup: %v", err)
+	// With smart_downsample on, those rows are first rolled up into daily
+	// averages so multi-year trends survive. Temperature history is small
+	// and has its own period, so it can be kept far longer than SMART.
+	smartDays := settings.RetentionDays(db.DB, "smart_data_days", 15)
+	tempDays := settings.RetentionDays(db.DB, "temperature_days", smartDays)
+	if settings.GetBool(db.DB, "retention", "smart_downsample", true) {
+		if deleted, err := smart.DownsampleOldData(db.DB, smartDays, tempDays, db.Write); err != nil {
+			log.Printf("⚠️  SMART/temperature downsample: %v", err)
+		} else if deleted > 0 {
+			log.Printf("🧹 SMART/temperature downsample: rolled %d old records into daily averages", deleted)
+		}
+	} else {
+		if deleted, err := lockedDelete(func() (int64, error) { return smart.CleanupOldSmartAttributes(db.DB, smartDays) }); err != nil {
+			log.Printf("⚠️  SMART data cleanup: %v", err)
+		} else if deleted > 0 {
+			log.Printf("🧹 SMART data cleanup: removed %d old records", deleted)
+		}
+		if deleted, err := lockedDelete(func() (int64, error) { return smart.CleanupOldTemperatureHistory(db.DB, tempDays) }); err != nil {
+			log.Printf("⚠️  Temperature history cleanup: %v", err)
+		} else if deleted > 0 {
+			log.Printf("🧹 Temperature history cleanup: removed %d old records", deleted)
+		}
+	}
+
+	if deleted, err := lockedDelete(func() (int64, error) { return temperature.CleanupOldSpikes(db.DB, tempDays) }); err != nil {
+		log.Printf("⚠️  Temperature spike cleanup: %v", err)
+	} else if deleted > 0 {
+		log.Printf("🧹 Temperature spike cleanup: removed %d old records", deleted)
+	}
+
+	alertDays := settings.RetentionDays(db.DB, "alert_days", 365)
+	if deleted, err := lockedDelete(func() (int64, error) { return temperature.CleanupOldAlerts(db.DB, alertDays) }); err != nil {
+		log.Printf("⚠️  Temperature alert cleanup: %v", err)
 	} else if deleted > 0 {
-		log.Printf("🧹 SMART/temperature data cleanup: removed %d old records", deleted)
+		log.Printf("🧹 Temperature alert cleanup: removed %d old alerts", deleted)
 	}
 
-	if deleted, err := handlers.CleanupOldReportsByAge(settings.GetInt(db.DB, "retention", "report_history_days", 90)); err != nil {
+	if deleted, err := lockedDelete(func() (int64, error) { return smart.CleanupOldIngestionErrors(db.DB, smartDays) }); err != nil {
+		log.Printf("⚠️  Ingestion error cleanup: %v", err)
+	} else if deleted > 0 {
+		log.Printf("🧹 Ingestion error cleanup: removed %d old records", deleted)
+	}
+
+	if deleted, err := lockedDelete(func() (int64, error) { return latency.PurgeOld(db.DB, smartDays) }); err != nil {
+		log.Printf("⚠️  Latency history cleanup: %v", err)
+	} else if deleted > 0 {
+		log.Printf("🧹 Latency history cleanup: removed %d old records", deleted)
+	}
+
+	reportDays := settings.GetInt(db.DB, "retention", "report_history_days", 90)
+	if deleted, err := lockedDelete(func() (int64, error) { return handlers.CleanupOldReportsByAge(reportDays) }); err != nil {
 		log.Printf("⚠️  Report age cleanup: %v", err)
 	} else if deleted > 0 {
 		log.Printf("🧹 Report age cleanup: removed %d old records", deleted)
@@ -290,33 +448,119 @@ func runRetentionSweep() {
 
 	// Count-based per-host report cap (independent of the age-based policy).
 	if limit := settings.GetInt(db.DB, "retention", "host_history_limit", 50); limit > 0 {
-		if deleted, err := handlers.CleanupOldReports(limit); err != nil {
+		if deleted, err := lockedDelete(func() (int64, error) { return handlers.CleanupOldReports(limit) }); err != nil {
 			log.Printf("⚠️  Report count cleanup: %v", err)
 		} else if deleted > 0 {
 			log.Printf("🧹 Report count cleanup: removed %d old records", deleted)
 		}
 	}
 
-	// Reclaim disk space from the pages freed by the deletes above. SQLite keeps
-	// freed pages inside the file unless VACUUMed (auto_vacuum is off here), so the
-	// file only ever grows. Throttled to once/day — VACUUM rewrites the whole file
-	// and takes a brief exclusive lock. Set retention.vacuum_enabled=0 to disable.
-	if settings.GetInt(db.DB, "retention", "vacuum_enabled", 1) > 0 &&
-		time.Since(lastVacuum) >= vacuumInterval {
+	// Reclaim disk space from the pages freed by the deletes above. Set
+	// retention.vacuum_enabled=0 to disable.
+	if settings.GetInt(db.DB, "retention", "vacuum_enabled", 1) > 0 {
 		start := time.Now()
-		if _, err := db.DB.Exec("VACUUM"); err != nil {
-			log.Printf("⚠️  DB VACUUM: %v", err)
-		} else {
-			lastVacuum = time.Now()
-			log.Printf("🧹 DB VACUUM completed in %s", time.Since(start).Round(time.Millisecond))
+		if pages, err := reclaimFreePages(); err != nil {
+			log.Printf("⚠️  DB vacuum: %v", err)
+		} else if pages > 0 {
+			log.Printf("🧹 DB vacuum: reclaimed %d free pages in %s", pages, time.Since(start).Round(time.Millisecond))
 		}
 	}
 }
 
+// reclaimFreePages hands the database's free pages back to the filesystem
+// vacuumBatchPages at a time, taking the write lock per batch so report
+// ingestion can run in between. This needs auto_vacuum=INCREMENTAL; a
+// database created before that was set is converted by one full VACUUM on
+// the first sweep. Returns the number of pages reclaimed.
+func reclaimFreePages() (int, error) {
+	var mode int
+	if err := db.DB.QueryRow("PRAGMA auto_vacuum").Scan(&mode); err != nil {
+		return 0, err
+	}
+	if mode != 2 { // 2 = INCREMENTAL
+		var free int
+		if err := db.DB.QueryRow("PRAGMA freelist_count").Scan(&free); err != nil {
+			return 0, err
+		}
+		err := db.Write(func() error {
+			if _, err := db.DB.Exec("PRAGMA auto_vacuum = INCREMENTAL"); err != nil {
+				return err
+			}
+			_, err := db.DB.Exec("VACUUM")
+			return err
+		})
+		return free, err
+	}
+
+	reclaimed := 0
+	for {
+		var free int
+		if err := db.DB.QueryRow("PRAGMA freelist_count").Scan(&free); err != nil {
+			return reclaimed, err
+		}
+		if free == 0 {
+			return reclaimed, nil
+		}
+		var after int
+		err := db.Write(func() error {
+			// Exec would only step the pragma once, freeing a single page;
+			// it has to be run to completion as a query.
+			rows, err := db.DB.Query(fmt.Sprintf("PRAGMA incremental_vacuum(%d)", vacuumBatchPages))
+			if err != nil {
+				return err
+			}
+			for rows.Next() {
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return err
+			}
+			return db.DB.QueryRow("PRAGMA freelist_count").Scan(&after)
+		})
+		if err != nil {
+			return reclaimed, err
+		}
+		if after >= free {
+			return reclaimed, nil // nothing freed; don't spin
+		}
+		reclaimed += free - after
+	}
+}
+
+// lockedDelete runs one of the retention sweep's bulk deletes under the
+// database write lock, so report ingestion waits for it instead of hitting
+// SQLITE_BUSY halfway through.
+func lockedDelete(fn func() (int64, error)) (int64, error) {
+	var deleted int64
+	err := db.Write(func() (err error) {
+		deleted, err = fn()
+		return err
+	})
+	return deleted, err
+}
+
+// refreshTemperatureBaselines relearns each drive's normal temperature range
+// from recent history. Only consulted when temperature.alert_mode is
+// "learned" or "both", but always kept current so the drive detail can show
+// it. The window is effectively capped by retention.smart_data_days, since
+// older raw readings have been rolled up.
+func refreshTemperatureBaselines() {
+	windowDays := settings.GetInt(db.DB, "temperature", "baseline_window_days", temperature.DefaultBaselineWindowDays)
+	sigma, err := settings.GetFloatSetting(db.DB, "temperature", "baseline_sigma")
+	if err != nil {
+		sigma = temperature.DefaultBaselineSigma
+	}
+	if _, err := temperature.ComputeBaselines(db.DB, windowDays, sigma); err != nil {
+		log.Printf("⚠️  Temperature baselines: %v", err)
+	}
+}
+
 func setupRoutes(cfg models.Config) *http.ServeMux {
 	mux := http.NewServeMux()
+	// Users in a project only reach their projects' hosts; see
+	// handlers.ProjectScope.
 	protect := func(h http.HandlerFunc) http.HandlerFunc {
-		return auth.Middleware(cfg, h)
+		return auth.Middleware(cfg, handlers.ProjectScope(h))
 	}
 
 	// Rate limiters for public auth endpoints
@@ -332,6 +576,12 @@ func setupRoutes(cfg models.Config) *http.ServeMux {
 	// Auth endpoints (rate limited)
 	mux.HandleFunc("POST /api/auth/login", loginLimiter.Limit(auth.Login(cfg)))
 	mux.HandleFunc("POST /api/auth/logout", auth.Logout)
+	mux.HandleFunc("GET /setup", handlers.SetupPage)
+	mux.HandleFunc("GET /api/setup", auth.SetupStatus(cfg))
+	mux.HandleFunc("POST /api/setup", loginLimiter.Limit(auth.Setup(cfg)))
+	oidcLogin, oidcCallback := auth.OIDCHandlers(cfg)
+	mux.HandleFunc("GET /api/auth/oidc/login", oidcLogin)
+	mux.HandleFunc("GET /api/auth/oidc/callback", loginLimiter.Limit(oidcCallback))
 
 	// ─── Agent authentication (public, rate limited) ─────────────────────
 	mux.HandleFunc("GET /api/v1/server/pubkey", handlers.GetServerPublicKey)
@@ -344,16 +594,19 @@ func setupRoutes(cfg models.Config) *http.ServeMux {
 	// ─── Agent management (admin-protected) ───────────────────────────────
 	mux.HandleFunc("GET /api/v1/agents", protect(handlers.ListAgents))
 	mux.HandleFunc("DELETE /api/v1/agents/{id}", protect(handlers.DeleteRegisteredAgent))
+	mux.HandleFunc("POST /api/v1/agents/{id}/approve", protect(handlers.ApproveAgent))
+	mux.HandleFunc("POST /api/v1/agents/{id}/reject", protect(handlers.RejectAgent))
 	mux.HandleFunc("POST /api/v1/agents/{hostname}/identify", protect(handlers.IdentifyDrive))
 	mux.HandleFunc("POST /api/v1/tokens", protect(handlers.CreateToken))
 	mux.HandleFunc("GET /api/v1/tokens", protect(handlers.ListTokens))
 	mux.HandleFunc("DELETE /api/v1/tokens/{id}", protect(handlers.DeleteToken))
 
-	// Protected endpoints
-	mux.HandleFunc("GET /api/history", protect(handlers.History))
-	mux.HandleFunc("GET /api/hosts", protect(handlers.Hosts))
+	// Protected endpoints. The dashboard polls these, so they answer
+	// If-None-Match with 304 when nothing changed.
+	mux.HandleFunc("GET /api/history", protect(middleware.ETag(handlers.History)))
+	mux.HandleFunc("GET /api/hosts", protect(middleware.ETag(handlers.Hosts)))
 	mux.HandleFunc("DELETE /api/hosts/{hostname}", protect(handlers.DeleteHost))
-	mux.HandleFunc("GET /api/hosts/{hostname}/history", protect(handlers.HostHistory))
+	mux.HandleFunc("GET /api/hosts/{hostname}/history", protect(middleware.ETag(handlers.HostHistory)))
 
 	// Alias endpoints
 	mux.HandleFunc("GET /api/aliases", protect(handlers.GetAliases))
@@ -361,7 +614,12 @@ func setupRoutes(cfg models.Config) *http.ServeMux {
 	mux.HandleFunc("DELETE /api/aliases/{id}", protect(handlers.DeleteAlias))
 
 	// User endpoints
+	mux.HandleFunc("GET /api/users", protect(auth.ListUsers))
+	mux.HandleFunc("POST /api/users", protect(auth.CreateUser))
+	mux.HandleFunc("PUT /api/users/{id}/admin", protect(auth.SetUserAdmin))
 	mux.HandleFunc("GET /api/users/me", protect(auth.GetCurrentUser))
+	mux.HandleFunc("GET /api/users/me/sessions", protect(auth.ListMySessions))
+	mux.HandleFunc("DELETE /api/users/me/sessions/{token}", protect(auth.RevokeMySession))
 	mux.HandleFunc("POST /api/users/password", protect(auth.ChangePassword))
 	mux.HandleFunc("POST /api/users/username", protect(auth.ChangeUsername))
 
@@ -369,12 +627,41 @@ func setupRoutes(cfg models.Config) *http.ServeMux {
 	mux.HandleFunc("GET /api/smart/attributes", protect(handlers.GetSmartAttributes))
 	mux.HandleFunc("GET /api/smart/attributes/history", protect(handlers.GetSmartAttributeHistory))
 	mux.HandleFunc("GET /api/smart/attributes/trend", protect(handlers.GetSmartAttributeTrend))
+	mux.HandleFunc("GET /api/smart/attributes/definitions", protect(handlers.GetSmartAttributeDefinitions))
+	mux.HandleFunc("GET /api/smart/query", protect(handlers.QuerySmartAttributes))
 	mux.HandleFunc("GET /api/smart/health/summary", protect(handlers.GetDriveHealthSummary))
-	mux.HandleFunc("GET /api/smart/health/all", protect(handlers.GetAllDrivesHealthSummary))
+	mux.HandleFunc("GET /api/smart/health/all", protect(middleware.ETag(handlers.GetAllDrivesHealthSummary)))
 	mux.HandleFunc("GET /api/smart/health/issues", protect(handlers.GetDrivesWithIssues))
 	mux.HandleFunc("GET /api/smart/critical-attributes", protect(handlers.GetCriticalAttributes))
 	mux.HandleFunc("GET /api/smart/temperature/history", protect(handlers.GetTemperatureHistory))
+	mux.HandleFunc("GET /api/temperature/summary", protect(handlers.TemperatureSummary))
+	mux.HandleFunc("GET /api/temperature/preview", protect(handlers.PreviewTemperatureThresholds))
+	mux.HandleFunc("GET /api/dashboard/overview", protect(handlers.DashboardOverview))
 	mux.HandleFunc("POST /api/smart/cleanup", protect(handlers.CleanupOldSmartData))
+	mux.HandleFunc("GET /api/smart/ingestion-errors", protect(handlers.GetIngestionErrors))
+	mux.HandleFunc("GET /api/smart/custom-rules", protect(handlers.ListCustomAttributeRules))
+	mux.HandleFunc("POST /api/smart/custom-rules", protect(handlers.CreateCustomAttributeRule))
+	mux.HandleFunc("PUT /api/smart/custom-rules/{id}", protect(handlers.UpdateCustomAttributeRule))
+	mux.HandleFunc("DELETE /api/smart/custom-rules/{id}", protect(handlers.DeleteCustomAttributeRule))
+
+	// ─── Temperature Endpoints ───────────────────────────────────────────
+	tempHandler := temperature.NewTemperatureHandler(db.DB)
+	mux.HandleFunc("GET /api/temperature/stats/host/{hostname}", protect(tempHandler.GetHostTemperatureStats))
+	mux.HandleFunc("GET /api/temperature/timeseries", protect(tempHandler.GetTemperatureTimeSeries))
+	mux.HandleFunc("GET /api/temperature/fleet/timeseries", protect(tempHandler.GetFleetTemperatureTimeSeries))
+	mux.HandleFunc("GET /api/temperature/heatmap", protect(tempHandler.GetTemperatureHeatmap))
+	mux.HandleFunc("POST /api/temperature/current/batch", protect(tempHandler.GetCurrentTemperaturesBatch))
+	mux.HandleFunc("GET /api/temperature/range", protect(tempHandler.GetTemperatureRange))
+
+	spikeHandler := temperature.NewSpikeHandler(db.DB)
+	mux.HandleFunc("GET /api/temperature/spikes", protect(spikeHandler.GetSpikes))
+
+	alertHandler := temperature.NewAlertHandler(db.DB)
+	mux.HandleFunc("GET /api/alerts/temperature", protect(alertHandler.GetAlerts))
+	mux.HandleFunc("GET /api/alerts/temperature/active", protect(alertHandler.GetActiveAlerts))
+	mux.HandleFunc("POST /api/alerts/temperature/{id}/acknowledge", protect(alertHandler.AcknowledgeAlert))
+	mux.HandleFunc("POST /api/alerts/temperature/acknowledge", protect(alertHandler.AcknowledgeMatchingAlerts))
+	mux.HandleFunc("POST /api/alerts/temperature/acknowledge-all", protect(alertHandler.AcknowledgeAllAlerts))
 
 	// ─── ZFS Endpoints ────────────────────────────────────────────────────
 	handlers.RegisterZFSRoutes(mux, protect)
@@ -388,6 +675,8 @@ func setupRoutes(cfg models.Config) *http.ServeMux {
 	// ─── Health & Report Endpoints ──────────────────────────────────────
 	handlers.RegisterHealthRoutes(mux, protect)
 	handlers.RegisterReportRoutes(mux, protect)
+	handlers.RegisterExportRoutes(mux, protect)
+	handlers.RegisterImportRoutes(mux, protect)
 
 	// ─── Notification Endpoints ──────────────────────────────────────────
 	handlers.RegisterNotificationRoutes(mux, protect)
@@ -395,6 +684,9 @@ func setupRoutes(cfg models.Config) *http.ServeMux {
 	// ─── Settings Endpoints ──────────────────────────────────────────────
 	handlers.RegisterSettingsRoutes(mux, protect)
 
+	// ─── Audit Endpoints ─────────────────────────────────────────────────
+	handlers.RegisterAuditRoutes(mux, protect)
+
 	// ─── Backup Endpoints ────────────────────────────────────────────────
 	handlers.RegisterBackupRoutes(mux, protect)
 
@@ -404,6 +696,18 @@ func setupRoutes(cfg models.Config) *http.ServeMux {
 	// ─── Drive Group Endpoints ───────────────────────────────────────────
 	handlers.RegisterDriveGroupRoutes(mux, protect)
 
+	// ─── Enclosure Endpoints ─────────────────────────────────────────────
+	handlers.RegisterEnclosureRoutes(mux, protect)
+
+	// ─── Drive Endpoints ─────────────────────────────────────────────────
+	handlers.RegisterDriveRoutes(mux, protect)
+
+	// ─── Maintenance Endpoints ───────────────────────────────────────────
+	handlers.RegisterMaintenanceRoutes(mux, protect)
+
+	// ─── Project Endpoints ───────────────────────────────────────────────
+	handlers.RegisterProjectRoutes(mux, protect)
+
 	// Static files
 	mux.HandleFunc("/", handlers.StaticFiles(cfg))
 
@@ -2,9 +2,16 @@ package addons
 
 import (
 	"encoding/json"
+	"errors"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// ErrTooManySubscribers is returned by SubscribeLimited when a subscriber
+// cap is reached.
+var ErrTooManySubscribers = errors.New("too many telemetry subscribers")
+
 // TelemetryEvent is a typed telemetry frame sent from an add-on.
 type TelemetryEvent struct {
 	AddonID int64           `json:"addon_id"`
@@ -12,54 +19,136 @@ type TelemetryEvent struct {
 	Payload json.RawMessage `json:"payload"`
 }
 
+// telemetrySub is one subscriber channel and when its reader last showed
+// it was alive (see Touch).
+type telemetrySub struct {
+	ch         chan TelemetryEvent
+	lastActive atomic.Int64 // unix nanoseconds
+}
+
 // TelemetryBroker fans out telemetry events to per-addon SSE subscribers.
 type TelemetryBroker struct {
-	mu   sync.RWMutex
-	subs map[int64][]chan TelemetryEvent
+	mu    sync.RWMutex
+	subs  map[int64][]*telemetrySub
+	total int
 }
 
 // NewTelemetryBroker creates a ready-to-use broker.
 func NewTelemetryBroker() *TelemetryBroker {
 	return &TelemetryBroker{
-		subs: make(map[int64][]chan TelemetryEvent),
+		subs: make(map[int64][]*telemetrySub),
 	}
 }
 
 // Subscribe returns a channel that receives telemetry events for the
 // given add-on.  The caller must call Unsubscribe when done.
 func (b *TelemetryBroker) Subscribe(addonID int64) chan TelemetryEvent {
-	ch := make(chan TelemetryEvent, 64)
-	b.mu.Lock()
-	b.subs[addonID] = append(b.subs[addonID], ch)
-	b.mu.Unlock()
+	ch, _ := b.SubscribeLimited(addonID, 0, 0)
 	return ch
 }
 
+// SubscribeLimited is Subscribe with caps on the add-on's subscribers and
+// on all subscribers (0 = no cap). It returns ErrTooManySubscribers when
+// either is reached.
+func (b *TelemetryBroker) SubscribeLimited(addonID int64, perAddon, total int) (chan TelemetryEvent, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if (perAddon > 0 && len(b.subs[addonID]) >= perAddon) || (total > 0 && b.total >= total) {
+		return nil, ErrTooManySubscribers
+	}
+
+	sub := &telemetrySub{ch: make(chan TelemetryEvent, 64)}
+	sub.lastActive.Store(time.Now().UnixNano())
+	b.subs[addonID] = append(b.subs[addonID], sub)
+	b.total++
+	return sub.ch, nil
+}
+
 // Unsubscribe removes a channel from the subscriber list and closes it.
+// Unsubscribing a channel that was already removed is a no-op.
 func (b *TelemetryBroker) Unsubscribe(addonID int64, ch chan TelemetryEvent) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	subs := b.subs[addonID]
 	for i, s := range subs {
-		if s == ch {
-			b.subs[addonID] = append(subs[:i], subs[i+1:]...)
-			close(ch)
+		if s.ch == ch {
+			b.removeLocked(addonID, i)
 			return
 		}
 	}
 }
 
+// removeLocked drops the i-th subscriber of an add-on and closes its
+// channel. b.mu must be held for writing.
+func (b *TelemetryBroker) removeLocked(addonID int64, i int) {
+	subs := b.subs[addonID]
+	close(subs[i].ch)
+	subs = append(subs[:i], subs[i+1:]...)
+	if len(subs) == 0 {
+		delete(b.subs, addonID)
+	} else {
+		b.subs[addonID] = subs
+	}
+	b.total--
+}
+
+// Touch records that the subscriber behind ch is still being written to
+// successfully, keeping Reap from dropping it.
+func (b *TelemetryBroker) Touch(addonID int64, ch chan TelemetryEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, s := range b.subs[addonID] {
+		if s.ch == ch {
+			s.lastActive.Store(time.Now().UnixNano())
+			return
+		}
+	}
+}
+
+// Reap unsubscribes (and so closes) every subscriber that hasn't been
+// touched within maxIdle, and returns how many it dropped. Their readers
+// see the closed channel and end their streams.
+func (b *TelemetryBroker) Reap(maxIdle time.Duration) int {
+	cutoff := time.Now().Add(-maxIdle).UnixNano()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	reaped := 0
+	for addonID := range b.subs {
+		subs := b.subs[addonID]
+		for i := len(subs) - 1; i >= 0; i-- {
+			if subs[i].lastActive.Load() < cutoff {
+				b.removeLocked(addonID, i)
+				reaped++
+			}
+		}
+	}
+	return reaped
+}
+
+// SubscriberCount returns the number of subscribers of an add-on, and of
+// all add-ons.
+func (b *TelemetryBroker) SubscriberCount(addonID int64) (addon, total int) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs[addonID]), b.total
+}
+
 // Publish sends a telemetry event to all subscribers of the given add-on.
 // Non-blocking: if a subscriber's buffer is full the event is dropped.
+// The read lock is held while sending so a concurrent Unsubscribe can't
+// close a channel mid-send.
 func (b *TelemetryBroker) Publish(evt TelemetryEvent) {
 	b.mu.RLock()
-	subs := b.subs[evt.AddonID]
-	b.mu.RUnlock()
+	defer b.mu.RUnlock()
 
-	for _, ch := range subs {
+	for _, s := range b.subs[evt.AddonID] {
 		select {
-		case ch <- evt:
+		case s.ch <- evt:
 		default:
 			// subscriber too slow — drop
 		}
@@ -92,3 +92,61 @@ func TestTelemetryBroker_Unsubscribe(t *testing.T) {
 		t.Error("channel should be closed after unsubscribe")
 	}
 }
+
+func TestTelemetryBroker_SubscribeLimited(t *testing.T) {
+	b := NewTelemetryBroker()
+
+	ch1, err := b.SubscribeLimited(1, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.SubscribeLimited(1, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.SubscribeLimited(1, 2, 3); err != ErrTooManySubscribers {
+		t.Errorf("third subscriber of add-on 1: err = %v, want ErrTooManySubscribers", err)
+	}
+	if _, err := b.SubscribeLimited(2, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.SubscribeLimited(3, 2, 3); err != ErrTooManySubscribers {
+		t.Errorf("fourth subscriber overall: err = %v, want ErrTooManySubscribers", err)
+	}
+
+	b.Unsubscribe(1, ch1)
+	b.Unsubscribe(1, ch1) // already gone: no-op
+	if addon, total := b.SubscriberCount(1); addon != 1 || total != 2 {
+		t.Errorf("counts after unsubscribe = %d/%d, want 1/2", addon, total)
+	}
+	if _, err := b.SubscribeLimited(1, 2, 3); err != nil {
+		t.Errorf("slot should be free after unsubscribe: %v", err)
+	}
+}
+
+func TestTelemetryBroker_Reap(t *testing.T) {
+	b := NewTelemetryBroker()
+
+	stale := b.Subscribe(1)
+	live := b.Subscribe(1)
+	time.Sleep(20 * time.Millisecond)
+	b.Touch(1, live)
+
+	if n := b.Reap(10 * time.Millisecond); n != 1 {
+		t.Fatalf("reaped %d subscribers, want 1", n)
+	}
+	if _, ok := <-stale; ok {
+		t.Error("reaped channel should be closed")
+	}
+
+	b.Publish(TelemetryEvent{AddonID: 1, Type: "log"})
+	select {
+	case <-live:
+	case <-time.After(time.Second):
+		t.Fatal("touched subscriber should still receive events")
+	}
+	b.Unsubscribe(1, live)
+
+	if _, total := b.SubscriberCount(1); total != 0 {
+		t.Errorf("total = %d after all unsubscribed, want 0", total)
+	}
+}
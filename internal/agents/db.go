@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"log"
 	"time"
+
+	vigildb "vigil/internal/db"
 )
 
 // ─── Agent Registry ───────────────────────────────────────────────────────────
@@ -33,7 +35,7 @@ func RegisterAgent(db *sql.DB, hostname, name, fingerprint, publicKey string) (*
 func GetAgentByID(db *sql.DB, id int64) (*Agent, error) {
 	row := db.QueryRow(`
 		SELECT id, hostname, name, fingerprint, public_key,
-		       registered_at, last_auth_at, last_seen_at, enabled
+		       registered_at, last_auth_at, last_seen_at, enabled, approval
 		FROM agent_registry WHERE id = ?
 	`, id)
 	return scanAgentRow(row)
@@ -43,7 +45,7 @@ func GetAgentByID(db *sql.DB, id int64) (*Agent, error) {
 func GetAgentByFingerprint(db *sql.DB, fingerprint string) (*Agent, error) {
 	row := db.QueryRow(`
 		SELECT id, hostname, name, fingerprint, public_key,
-		       registered_at, last_auth_at, last_seen_at, enabled
+		       registered_at, last_auth_at, last_seen_at, enabled, approval
 		FROM agent_registry WHERE fingerprint = ? AND enabled = 1
 	`, fingerprint)
 	return scanAgentRow(row)
@@ -53,7 +55,7 @@ func GetAgentByFingerprint(db *sql.DB, fingerprint string) (*Agent, error) {
 func GetAgentByPublicKey(db *sql.DB, publicKey string) (*Agent, error) {
 	row := db.QueryRow(`
 		SELECT id, hostname, name, fingerprint, public_key,
-		       registered_at, last_auth_at, last_seen_at, enabled
+		       registered_at, last_auth_at, last_seen_at, enabled, approval
 		FROM agent_registry WHERE public_key = ? AND enabled = 1
 	`, publicKey)
 	return scanAgentRow(row)
@@ -63,7 +65,7 @@ func GetAgentByPublicKey(db *sql.DB, publicKey string) (*Agent, error) {
 func ListAgents(db *sql.DB) ([]Agent, error) {
 	rows, err := db.Query(`
 		SELECT id, hostname, name, fingerprint, public_key,
-		       registered_at, last_auth_at, last_seen_at, enabled
+		       registered_at, last_auth_at, last_seen_at, enabled, approval
 		FROM agent_registry ORDER BY hostname
 	`)
 	if err != nil {
@@ -82,6 +84,38 @@ func ListAgents(db *sql.DB) ([]Agent, error) {
 	return out, nil
 }
 
+// SetAgentApproval moves an agent to approval. Rejecting also disables the
+// agent and ends its sessions; approving re-enables it.
+func SetAgentApproval(db *sql.DB, agentID int64, approval string) error {
+	enabled := 1
+	switch approval {
+	case ApprovalApproved, ApprovalPending:
+	case ApprovalRejected:
+		enabled = 0
+	default:
+		return fmt.Errorf("unknown approval state %q", approval)
+	}
+	if _, err := db.Exec(
+		"UPDATE agent_registry SET approval = ?, enabled = ? WHERE id = ?",
+		approval, enabled, agentID,
+	); err != nil {
+		return err
+	}
+	if approval == ApprovalRejected {
+		_, err := db.Exec("DELETE FROM agent_sessions WHERE agent_id = ?", agentID)
+		return err
+	}
+	return nil
+}
+
+// AgentPending reports whether an agent is waiting for approval. Errors
+// count as not pending, as for agents registered before approval existed.
+func AgentPending(db *sql.DB, agentID int64) bool {
+	var approval string
+	err := db.QueryRow("SELECT approval FROM agent_registry WHERE id = ?", agentID).Scan(&approval)
+	return err == nil && approval == ApprovalPending
+}
+
 // UpdateAgentLastAuth stamps last_auth_at to now (UTC).
 func UpdateAgentLastAuth(db *sql.DB, agentID int64) error {
 	_, err := db.Exec(
@@ -137,6 +171,95 @@ func UpdateAgentCapabilities(db *sql.DB, hostname, listenAddr, capabilities stri
 	return err
 }
 
+// UpdateAgentSmartctlVersion records the smartctl version a host's agent
+// last reported. Called during report processing.
+func UpdateAgentSmartctlVersion(db *sql.DB, hostname, version string) error {
+	_, err := db.Exec(`
+		UPDATE agent_registry
+		SET smartctl_version = ?
+		WHERE hostname = ? AND enabled = 1
+	`, version, hostname)
+	return err
+}
+
+// GetSmartctlVersions returns the last reported smartctl version keyed by
+// hostname, for hosts that have reported one.
+func GetSmartctlVersions(db *sql.DB) (map[string]string, error) {
+	rows, err := db.Query(`
+		SELECT hostname, smartctl_version FROM agent_registry
+		WHERE enabled = 1 AND COALESCE(smartctl_version, '') != ''
+		ORDER BY last_seen_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]string)
+	for rows.Next() {
+		var host, version string
+		if err := rows.Scan(&host, &version); err != nil {
+			return nil, err
+		}
+		out[host] = version // ordered by last_seen_at: the newest agent wins
+	}
+	return out, rows.Err()
+}
+
+// SetAgentMaintenance records whether a host's agent last reported itself
+// in maintenance. maintenance_since keeps the time it was first reported,
+// and is cleared once a report comes in without the flag. Returns true when
+// the state changed.
+func SetAgentMaintenance(db *sql.DB, hostname string, on bool) (bool, error) {
+	query := `UPDATE agent_registry SET maintenance_since = NULL
+		WHERE hostname = ? AND enabled = 1 AND maintenance_since IS NOT NULL`
+	if on {
+		query = `UPDATE agent_registry SET maintenance_since = CURRENT_TIMESTAMP
+		WHERE hostname = ? AND enabled = 1 AND maintenance_since IS NULL`
+	}
+	res, err := db.Exec(query, hostname)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// GetMaintenanceHosts returns when each host in agent-declared maintenance
+// entered it, keyed by hostname.
+func GetMaintenanceHosts(db *sql.DB) (map[string]time.Time, error) {
+	rows, err := db.Query(`
+		SELECT hostname, maintenance_since FROM agent_registry
+		WHERE enabled = 1 AND maintenance_since IS NOT NULL
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]time.Time)
+	for rows.Next() {
+		var host string
+		var since time.Time
+		if err := rows.Scan(&host, &since); err != nil {
+			return nil, err
+		}
+		out[host] = since
+	}
+	return out, rows.Err()
+}
+
+// HostInMaintenance reports whether a host's agent has declared itself in
+// maintenance. Errors (including a missing table) count as not in maintenance.
+func HostInMaintenance(db *sql.DB, hostname string) bool {
+	var n int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM agent_registry
+		WHERE hostname = ? AND enabled = 1 AND maintenance_since IS NOT NULL
+	`, hostname).Scan(&n)
+	return err == nil && n > 0
+}
+
 // GetAgentByHostname returns the agent record for a hostname.
 func GetAgentByHostname(db *sql.DB, hostname string) (listenAddr, capabilities string, err error) {
 	err = db.QueryRow(`
@@ -148,6 +271,49 @@ func GetAgentByHostname(db *sql.DB, hostname string) (listenAddr, capabilities s
 	return
 }
 
+// SetHostLabels replaces the labels stored for a host with the set from its
+// latest report. An empty map clears them.
+func SetHostLabels(db *sql.DB, hostname string, labels map[string]string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM host_labels WHERE hostname = ?`, hostname); err != nil {
+		return err
+	}
+	for k, v := range labels {
+		if _, err := tx.Exec(`INSERT INTO host_labels (hostname, label_key, label_value) VALUES (?, ?, ?)`,
+			hostname, k, v); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetAllHostLabels returns every host's labels keyed by hostname.
+func GetAllHostLabels(db *sql.DB) (map[string]map[string]string, error) {
+	rows, err := db.Query(`SELECT hostname, label_key, label_value FROM host_labels`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]map[string]string)
+	for rows.Next() {
+		var host, k, v string
+		if err := rows.Scan(&host, &k, &v); err != nil {
+			return nil, err
+		}
+		if out[host] == nil {
+			out[host] = make(map[string]string)
+		}
+		out[host][k] = v
+	}
+	return out, rows.Err()
+}
+
 // DeleteAgent removes an agent and all its sessions (ON DELETE CASCADE).
 func DeleteAgent(db *sql.DB, id int64) error {
 	_, err := db.Exec("DELETE FROM agent_registry WHERE id = ?", id)
@@ -173,8 +339,22 @@ func DeleteHostData(db *sql.DB, hostname string) (deleted map[string]int64) {
 		{"reports", "DELETE FROM reports WHERE LOWER(hostname) = LOWER(?)"},
 		{"drive_aliases", "DELETE FROM drive_aliases WHERE LOWER(hostname) = LOWER(?)"},
 		{"zfs_pools", "DELETE FROM zfs_pools WHERE LOWER(hostname) = LOWER(?)"},
+		{"zfs_pool_properties", "DELETE FROM zfs_pool_properties WHERE LOWER(hostname) = LOWER(?)"},
+		{"zfs_pool_usage", "DELETE FROM zfs_pool_usage WHERE LOWER(hostname) = LOWER(?)"},
 		{"wearout_history", "DELETE FROM wearout_history WHERE LOWER(hostname) = LOWER(?)"},
+		{"write_snapshots", "DELETE FROM write_snapshots WHERE LOWER(hostname) = LOWER(?)"},
 		{"smart_attributes", "DELETE FROM smart_attributes WHERE LOWER(hostname) = LOWER(?)"},
+		{"smart_attributes_daily", "DELETE FROM smart_attributes_daily WHERE LOWER(hostname) = LOWER(?)"},
+		{"temperature_daily", "DELETE FROM temperature_daily WHERE LOWER(hostname) = LOWER(?)"},
+		{"temperature_baselines", "DELETE FROM temperature_baselines WHERE LOWER(hostname) = LOWER(?)"},
+		{"ingestion_errors", "DELETE FROM ingestion_errors WHERE LOWER(hostname) = LOWER(?)"},
+		{"latency_history", "DELETE FROM latency_history WHERE LOWER(hostname) = LOWER(?)"},
+		{"drive_presence", "DELETE FROM drive_presence WHERE LOWER(hostname) = LOWER(?)"},
+		{"drive_replacements", "DELETE FROM drive_replacements WHERE LOWER(hostname) = LOWER(?)"},
+		{"host_labels", "DELETE FROM host_labels WHERE LOWER(hostname) = LOWER(?)"},
+		{"project_hosts", "DELETE FROM project_hosts WHERE LOWER(hostname) = LOWER(?)"},
+		{"drive_alert_settings", "DELETE FROM drive_alert_settings WHERE LOWER(hostname) = LOWER(?)"},
+		{"drive_temperature_sampling", "DELETE FROM drive_temperature_sampling WHERE LOWER(hostname) = LOWER(?)"},
 	}
 
 	for _, t := range tables {
@@ -191,6 +371,58 @@ func DeleteHostData(db *sql.DB, hostname string) (deleted map[string]int64) {
 	return deleted
 }
 
+// DeleteDriveData removes one drive's history from a host — SMART attributes,
+// temperatures, alerts, spikes, health status changes, latency and wearout
+// samples, alias, group membership, presence and alert setting — leaving the
+// rest of the host untouched. Useful after a drive swap. Everything is
+// deleted in one transaction; the returned map holds the per-table row
+// counts (zero counts included).
+func DeleteDriveData(db *sql.DB, hostname, serial string) (map[string]int64, error) {
+	tables := []struct {
+		label string
+		sql   string
+	}{
+		{"smart_attributes", "DELETE FROM smart_attributes WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+		{"smart_attributes_daily", "DELETE FROM smart_attributes_daily WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+		{"temperature_history", "DELETE FROM temperature_history WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+		{"temperature_daily", "DELETE FROM temperature_daily WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+		{"temperature_alerts", "DELETE FROM temperature_alerts WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+		{"temperature_spikes", "DELETE FROM temperature_spikes WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+		{"temperature_baselines", "DELETE FROM temperature_baselines WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+		{"drive_health_snapshots", "DELETE FROM drive_health_snapshots WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+		{"health_status_history", "DELETE FROM health_status_history WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+		{"ingestion_errors", "DELETE FROM ingestion_errors WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+		{"latency_history", "DELETE FROM latency_history WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+		{"wearout_history", "DELETE FROM wearout_history WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+		{"write_snapshots", "DELETE FROM write_snapshots WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+		{"drive_aliases", "DELETE FROM drive_aliases WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+		{"drive_group_members", "DELETE FROM drive_group_members WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+		{"drive_presence", "DELETE FROM drive_presence WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+		{"drive_alert_settings", "DELETE FROM drive_alert_settings WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+		{"drive_temperature_sampling", "DELETE FROM drive_temperature_sampling WHERE LOWER(hostname) = LOWER(?) AND serial_number = ?"},
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	deleted := make(map[string]int64, len(tables))
+	for _, t := range tables {
+		result, err := tx.Exec(t.sql, hostname, serial)
+		if err != nil {
+			return nil, fmt.Errorf("delete from %s: %w", t.label, err)
+		}
+		deleted[t.label], _ = result.RowsAffected()
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return deleted, nil
+}
+
 // ─── Registration Tokens ─────────────────────────────────────────────────────
 
 // CreateRegistrationToken generates and stores a one-time token.
@@ -251,14 +483,14 @@ func GetRegistrationToken(db *sql.DB, token string) (*RegistrationToken, error)
 		return nil, err
 	}
 
-	t.CreatedAt = parseDBTime(createdAt)
+	t.CreatedAt = vigildb.ParseTime(createdAt)
 	if expiresAt.Valid {
-		ts := parseDBTime(expiresAt.String)
+		ts := vigildb.ParseTime(expiresAt.String)
 		t.ExpiresAt = &ts
 	}
 
 	if usedAt.Valid {
-		ts := parseDBTime(usedAt.String)
+		ts := vigildb.ParseTime(usedAt.String)
 		t.UsedAt = &ts
 	}
 	if usedByAgentID.Valid {
@@ -314,14 +546,14 @@ func ListRegistrationTokens(db *sql.DB) ([]RegistrationToken, error) {
 			return nil, err
 		}
 
-		t.CreatedAt = parseDBTime(createdAt)
+		t.CreatedAt = vigildb.ParseTime(createdAt)
 		if expiresAt.Valid {
-			ts := parseDBTime(expiresAt.String)
+			ts := vigildb.ParseTime(expiresAt.String)
 			t.ExpiresAt = &ts
 		}
 
 		if usedAt.Valid {
-			ts := parseDBTime(usedAt.String)
+			ts := vigildb.ParseTime(usedAt.String)
 			t.UsedAt = &ts
 		}
 		if usedByAgentID.Valid {
@@ -389,8 +621,8 @@ func GetAgentSession(db *sql.DB, token string) (*AgentSession, error) {
 		return nil, err
 	}
 
-	s.ExpiresAt = parseDBTime(expiresAt)
-	s.CreatedAt = parseDBTime(createdAt)
+	s.ExpiresAt = vigildb.ParseTime(expiresAt)
+	s.CreatedAt = vigildb.ParseTime(createdAt)
 	return &s, nil
 }
 
@@ -409,7 +641,7 @@ func scanAgentRow(row *sql.Row) (*Agent, error) {
 
 	err := row.Scan(
 		&a.ID, &a.Hostname, &name, &a.Fingerprint, &a.PublicKey,
-		&registeredAt, &lastAuthAt, &lastSeenAt, &enabled,
+		&registeredAt, &lastAuthAt, &lastSeenAt, &enabled, &a.Approval,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -430,7 +662,7 @@ func scanAgentRows(rows *sql.Rows) (*Agent, error) {
 
 	if err := rows.Scan(
 		&a.ID, &a.Hostname, &name, &a.Fingerprint, &a.PublicKey,
-		&registeredAt, &lastAuthAt, &lastSeenAt, &enabled,
+		&registeredAt, &lastAuthAt, &lastSeenAt, &enabled, &a.Approval,
 	); err != nil {
 		return nil, err
 	}
@@ -445,14 +677,14 @@ func applyAgentFields(a *Agent, name sql.NullString, registeredAt, lastAuthAt, l
 	}
 	a.Enabled = enabled == 1
 	if registeredAt.Valid {
-		a.RegisteredAt = parseDBTime(registeredAt.String)
+		a.RegisteredAt = vigildb.ParseTime(registeredAt.String)
 	}
 	if lastAuthAt.Valid {
-		t := parseDBTime(lastAuthAt.String)
+		t := vigildb.ParseTime(lastAuthAt.String)
 		a.LastAuthAt = &t
 	}
 	if lastSeenAt.Valid {
-		t := parseDBTime(lastSeenAt.String)
+		t := vigildb.ParseTime(lastSeenAt.String)
 		a.LastSeenAt = &t
 	}
 }
@@ -57,6 +57,14 @@ func Migrate(db *sql.DB) error {
 		{"agent_sessions indexes", `
 			CREATE INDEX IF NOT EXISTS idx_agent_sessions_agent   ON agent_sessions(agent_id);
 			CREATE INDEX IF NOT EXISTS idx_agent_sessions_expires ON agent_sessions(expires_at);`},
+
+		{"host_labels", `
+			CREATE TABLE IF NOT EXISTS host_labels (
+				hostname    TEXT NOT NULL,
+				label_key   TEXT NOT NULL,
+				label_value TEXT NOT NULL DEFAULT '',
+				PRIMARY KEY (hostname, label_key)
+			);`},
 	}
 
 	for _, s := range statements {
@@ -77,6 +85,21 @@ func Migrate(db *sql.DB) error {
 		return fmt.Errorf("migration failed at [agent capabilities]: %w", err)
 	}
 
+	// Migration: add smartctl_version, reported by agents alongside their drives.
+	if err := migrateAgentSmartctlVersion(db); err != nil {
+		return fmt.Errorf("migration failed at [agent smartctl version]: %w", err)
+	}
+
+	// Migration: add maintenance_since, set while an agent reports itself in maintenance.
+	if err := migrateAgentMaintenance(db); err != nil {
+		return fmt.Errorf("migration failed at [agent maintenance]: %w", err)
+	}
+
+	// Migration: add approval, for trust-on-first-use approval of new agents.
+	if err := migrateAgentApproval(db); err != nil {
+		return fmt.Errorf("migration failed at [agent approval]: %w", err)
+	}
+
 	log.Println("🔐 Migration completed: agent authentication tables ready")
 	return nil
 }
@@ -161,3 +184,57 @@ func migrateAgentCapabilities(db *sql.DB) error {
 	log.Println("  ✓ agent_registry: listen_addr and capabilities columns added")
 	return nil
 }
+
+// migrateAgentSmartctlVersion adds the smartctl_version column to
+// agent_registry. No-op if already present.
+func migrateAgentSmartctlVersion(db *sql.DB) error {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('agent_registry') WHERE name = 'smartctl_version'`).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if _, err := db.Exec(`ALTER TABLE agent_registry ADD COLUMN smartctl_version TEXT DEFAULT ''`); err != nil {
+		return fmt.Errorf("agent smartctl version migration: %w", err)
+	}
+	log.Println("  ✓ agent_registry: smartctl_version column added")
+	return nil
+}
+
+// migrateAgentMaintenance adds the maintenance_since column to
+// agent_registry. No-op if already present.
+func migrateAgentMaintenance(db *sql.DB) error {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('agent_registry') WHERE name = 'maintenance_since'`).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if _, err := db.Exec(`ALTER TABLE agent_registry ADD COLUMN maintenance_since DATETIME`); err != nil {
+		return fmt.Errorf("agent maintenance migration: %w", err)
+	}
+	log.Println("  ✓ agent_registry: maintenance_since column added")
+	return nil
+}
+
+// migrateAgentApproval adds the approval column to agent_registry. Agents
+// registered before it existed count as approved. No-op if already present.
+func migrateAgentApproval(db *sql.DB) error {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('agent_registry') WHERE name = 'approval'`).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if _, err := db.Exec(`ALTER TABLE agent_registry ADD COLUMN approval TEXT NOT NULL DEFAULT 'approved'`); err != nil {
+		return fmt.Errorf("agent approval migration: %w", err)
+	}
+	log.Println("  ✓ agent_registry: approval column added")
+	return nil
+}
@@ -13,8 +13,18 @@ type Agent struct {
 	LastAuthAt   *time.Time `json:"last_auth_at,omitempty"`
 	LastSeenAt   *time.Time `json:"last_seen_at,omitempty"`
 	Enabled      bool       `json:"enabled"`
+	Approval     string     `json:"approval"` // approved, pending or rejected
 }
 
+// Agent approval states. Agents registered while agents.require_approval is
+// on start pending: they can authenticate, but their reports are refused
+// until an admin approves them. Rejected agents are disabled.
+const (
+	ApprovalApproved = "approved"
+	ApprovalPending  = "pending"
+	ApprovalRejected = "rejected"
+)
+
 // RegistrationToken is a one-time-use token for enrolling a new agent.
 // ExpiresAt is nil for tokens that never expire.
 type RegistrationToken struct {
@@ -36,21 +46,3 @@ type AgentSession struct {
 }
 
 const timeFormat = "2006-01-02 15:04:05"
-
-// parseDBTime parses a timestamp read from SQLite, tolerating both formats that
-// show up in this DB: the bare `2006-01-02 15:04:05` written by Go/CURRENT_TIMESTAMP,
-// and RFC3339 (`2006-01-02T15:04:05Z`) which the driver/some write paths produce.
-// Parsing with only the bare layout silently failed on RFC3339 values and left the
-// zero value — that's the "Registered never / Not Reporting" bug on the Agents page.
-// Returns zero time only if neither layout matches.
-func parseDBTime(s string) time.Time {
-	if s == "" {
-		return time.Time{}
-	}
-	for _, layout := range []string{time.RFC3339, timeFormat, "2006-01-02 15:04:05.999999999-07:00"} {
-		if t, err := time.Parse(layout, s); err == nil {
-			return t.UTC()
-		}
-	}
-	return time.Time{}
-}
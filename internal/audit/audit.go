@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"vigil/internal/middleware"
 )
@@ -45,3 +46,56 @@ func LogEvent(db *sql.DB, r *http.Request, userID int, username, action, resourc
 		log.Printf("⚠️  audit.LogEvent: %v", err)
 	}
 }
+
+// Entry is a single row of the audit log.
+type Entry struct {
+	ID         int64     `json:"id"`
+	UserID     *int64    `json:"user_id,omitempty"`
+	Username   string    `json:"username"`
+	Action     string    `json:"action"`
+	Resource   string    `json:"resource"`
+	ResourceID string    `json:"resource_id,omitempty"`
+	Details    string    `json:"details,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// List returns the most recent audit entries, newest first. A zero since
+// returns entries regardless of age.
+func List(db *sql.DB, limit int, since time.Time) ([]Entry, error) {
+	query := `
+		SELECT id, user_id, COALESCE(username, ''), action, resource, COALESCE(resource_id, ''),
+		       COALESCE(details, ''), COALESCE(ip_address, ''), COALESCE(user_agent, ''),
+		       COALESCE(status, ''), created_at
+		FROM audit_log`
+	var args []interface{}
+	if !since.IsZero() {
+		query += ` WHERE created_at >= ?`
+		args = append(args, since.UTC().Format("2006-01-02 15:04:05"))
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		var uid sql.NullInt64
+		if err := rows.Scan(&e.ID, &uid, &e.Username, &e.Action, &e.Resource, &e.ResourceID,
+			&e.Details, &e.IPAddress, &e.UserAgent, &e.Status, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan audit entry: %w", err)
+		}
+		if uid.Valid {
+			e.UserID = &uid.Int64
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"database/sql"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec(`
+		CREATE TABLE audit_log (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id     INTEGER,
+			username    TEXT,
+			action      TEXT    NOT NULL,
+			resource    TEXT    NOT NULL,
+			resource_id TEXT,
+			details     TEXT,
+			ip_address  TEXT,
+			user_agent  TEXT,
+			status      TEXT    DEFAULT 'success',
+			created_at  DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`)
+	if err != nil {
+		t.Fatalf("create audit_log: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestListNewestFirstWithSince(t *testing.T) {
+	db := setupTestDB(t)
+	r := httptest.NewRequest("POST", "/", nil)
+
+	LogEvent(db, r, 1, "admin", "host_delete", "host", "nas01", "", "success")
+	LogEvent(db, r, 0, "", "login_failed", "user", "", "invalid credentials", "failure")
+	db.Exec(`UPDATE audit_log SET created_at = datetime('now', '-2 days') WHERE action = 'host_delete'`)
+
+	all, err := List(db, 10, time.Time{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(all) != 2 || all[0].Action != "login_failed" {
+		t.Fatalf("expected 2 entries, newest first, got %+v", all)
+	}
+	if all[0].UserID != nil || all[1].UserID == nil || *all[1].UserID != 1 {
+		t.Errorf("unexpected user ids: %v, %v", all[0].UserID, all[1].UserID)
+	}
+	if all[1].CreatedAt.IsZero() {
+		t.Error("created_at not parsed")
+	}
+
+	recent, err := List(db, 10, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("List since: %v", err)
+	}
+	if len(recent) != 1 || recent[0].Action != "login_failed" {
+		t.Errorf("since filter returned %+v", recent)
+	}
+}
@@ -2,13 +2,16 @@ package auth
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"vigil/internal/audit"
 	"vigil/internal/db"
+	"vigil/internal/middleware"
 	"vigil/internal/models"
 	"vigil/internal/validate"
 )
@@ -22,6 +25,19 @@ func isSecureRequest(r *http.Request) bool {
 	return strings.EqualFold(proto, "https")
 }
 
+// setSessionCookie hands a new session to the browser
+func setSessionCookie(w http.ResponseWriter, r *http.Request, token string, expiresAt time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "session",
+		Value:    token,
+		Path:     "/",
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
 // Status returns authentication status
 func Status(config models.Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -39,9 +55,11 @@ func Status(config models.Config) http.HandlerFunc {
 
 		jsonResponse(w, map[string]interface{}{
 			"auth_enabled":         config.AuthEnabled,
+			"oidc_enabled":         config.AuthEnabled && config.OIDCEnabled(),
 			"authenticated":        session != nil,
 			"username":             username,
 			"must_change_password": mustChangePassword,
+			"setup_required":       config.AuthEnabled && SetupRequired(),
 		})
 	}
 }
@@ -82,21 +100,13 @@ func Login(config models.Config) http.HandlerFunc {
 			return
 		}
 
-		token, expiresAt, err := CreateSession(user.ID)
+		token, expiresAt, err := CreateSession(user.ID, middleware.ExtractIP(r), r.UserAgent())
 		if err != nil {
 			jsonError(w, "Failed to create session", http.StatusInternalServerError)
 			return
 		}
 
-		http.SetCookie(w, &http.Cookie{
-			Name:     "session",
-			Value:    token,
-			Path:     "/",
-			Expires:  expiresAt,
-			HttpOnly: true,
-			Secure:   isSecureRequest(r),
-			SameSite: http.SameSiteLaxMode,
-		})
+		setSessionCookie(w, r, token, expiresAt)
 
 		log.Printf("🔓 Login: %s", user.Username)
 		audit.LogEvent(db.DB, r, user.ID, user.Username, "login", "user", "", "", "success")
@@ -134,9 +144,12 @@ func Logout(w http.ResponseWriter, r *http.Request) {
 // GetCurrentUser returns current user info
 func GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	session := GetSessionFromContext(r)
+	var isAdmin bool
+	db.DB.QueryRow("SELECT is_admin FROM users WHERE id = ?", session.UserID).Scan(&isAdmin)
 	jsonResponse(w, map[string]interface{}{
 		"id":       session.UserID,
 		"username": session.Username,
+		"is_admin": isAdmin,
 	})
 }
 
@@ -235,6 +248,176 @@ func ChangeUsername(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ListUsers returns every user account, for assigning users to projects.
+func ListUsers(w http.ResponseWriter, r *http.Request) {
+	rows, err := db.DB.Query("SELECT id, username, is_admin, created_at FROM users ORDER BY username")
+	if err != nil {
+		jsonError(w, "Failed to list users", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	users := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		var id int
+		var username, createdAt string
+		var isAdmin bool
+		if err := rows.Scan(&id, &username, &isAdmin, &createdAt); err != nil {
+			continue
+		}
+		users = append(users, map[string]interface{}{
+			"id":         id,
+			"username":   username,
+			"is_admin":   isAdmin,
+			"created_at": createdAt,
+		})
+	}
+	jsonResponse(w, users)
+}
+
+// CreateUser adds a local user who must change the initial password at
+// first login. New users are admins only if is_admin is set; otherwise
+// they see no hosts until they are added to a project.
+func CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		IsAdmin  bool   `json:"is_admin"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	req.Username = strings.TrimSpace(req.Username)
+	if err := validate.Username(req.Username); err != nil {
+		jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Password) < 6 {
+		jsonError(w, "Password must be at least 6 characters", http.StatusBadRequest)
+		return
+	}
+
+	hash, err := HashPassword(req.Password)
+	if err != nil {
+		jsonError(w, "Failed to hash password", http.StatusInternalServerError)
+		return
+	}
+	res, err := db.DB.Exec(
+		"INSERT INTO users (username, password_hash, must_change_password, is_admin) VALUES (?, ?, 1, ?)",
+		req.Username, hash, req.IsAdmin,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint") {
+			jsonError(w, "Username already taken", http.StatusConflict)
+			return
+		}
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	id, _ := res.LastInsertId()
+
+	log.Printf("👤 User created: %s (admin: %t)", req.Username, req.IsAdmin)
+	if session := GetSessionFromContext(r); session != nil {
+		audit.LogEvent(db.DB, r, session.UserID, session.Username, "user_create", "user", req.Username, fmt.Sprintf("admin: %t", req.IsAdmin), "success")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{"id": id, "username": req.Username, "is_admin": req.IsAdmin})
+}
+
+// SetUserAdmin grants or revokes a user's admin flag. Admins see every
+// host whatever their projects; other users only see their projects'
+// hosts. You can't revoke your own, so there's always an admin left.
+// PUT /api/users/{id}/admin
+func SetUserAdmin(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		jsonError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		IsAdmin *bool `json:"is_admin"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.IsAdmin == nil {
+		jsonError(w, "is_admin is required", http.StatusBadRequest)
+		return
+	}
+	session := GetSessionFromContext(r)
+	if session != nil && session.UserID == id && !*req.IsAdmin {
+		jsonError(w, "You can't revoke your own admin access", http.StatusBadRequest)
+		return
+	}
+
+	res, err := db.DB.Exec("UPDATE users SET is_admin = ? WHERE id = ?", *req.IsAdmin, id)
+	if err != nil {
+		jsonError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		jsonError(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	log.Printf("👤 Admin access for user %d set to %t", id, *req.IsAdmin)
+	if session != nil {
+		audit.LogEvent(db.DB, r, session.UserID, session.Username, "user_admin", "user", strconv.Itoa(id), fmt.Sprintf("admin: %t", *req.IsAdmin), "success")
+	}
+	jsonResponse(w, map[string]interface{}{"id": id, "is_admin": *req.IsAdmin})
+}
+
+// ListMySessions lists the current user's active sessions
+func ListMySessions(w http.ResponseWriter, r *http.Request) {
+	session := GetSessionFromContext(r)
+	if session == nil {
+		jsonError(w, "No user session", http.StatusUnauthorized)
+		return
+	}
+
+	sessions, err := ListUserSessions(session.UserID, session.Token)
+	if err != nil {
+		log.Printf("❌ Failed to list sessions: %v", err)
+		jsonError(w, "Failed to list sessions", http.StatusInternalServerError)
+		return
+	}
+	jsonResponse(w, map[string]interface{}{
+		"sessions": sessions,
+		"count":    len(sessions),
+	})
+}
+
+// RevokeMySession ends one of the current user's sessions, addressed by the
+// ID from ListMySessions or by its token. Revoking the current session is
+// the same as logging out.
+func RevokeMySession(w http.ResponseWriter, r *http.Request) {
+	session := GetSessionFromContext(r)
+	if session == nil {
+		jsonError(w, "No user session", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := DeleteUserSession(session.UserID, r.PathValue("token"))
+	if err != nil {
+		log.Printf("❌ Failed to revoke session: %v", err)
+		jsonError(w, "Failed to revoke session", http.StatusInternalServerError)
+		return
+	}
+	if token == "" {
+		jsonError(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	id := SessionID(token)
+	log.Printf("🔒 Session revoked: %s (%s)", session.Username, id)
+	audit.LogEvent(db.DB, r, session.UserID, session.Username, "session_revoke", "session", id, "", "success")
+	jsonResponse(w, map[string]interface{}{
+		"status":  "revoked",
+		"id":      id,
+		"current": token == session.Token,
+	})
+}
+
 func jsonResponse(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(data); err != nil {
@@ -0,0 +1,331 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"vigil/internal/audit"
+	"vigil/internal/db"
+	"vigil/internal/middleware"
+	"vigil/internal/models"
+	"vigil/internal/projects"
+)
+
+const (
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/api/auth/oidc"
+	oidcStateTTL    = 10 * time.Minute
+	oidcCallback    = "/api/auth/oidc/callback"
+)
+
+// oidcClient discovers the provider on first use rather than at startup, so
+// an unreachable identity provider doesn't keep Vigil (and its local
+// password login) from starting.
+type oidcClient struct {
+	config models.Config
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+func (c *oidcClient) discover(ctx context.Context) (*oidc.Provider, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.provider != nil {
+		return c.provider, nil
+	}
+	p, err := oidc.NewProvider(ctx, c.config.OIDCIssuer)
+	if err != nil {
+		return nil, err
+	}
+	c.provider = p
+	return p, nil
+}
+
+// oauth2Config builds the authorization code flow configuration, deriving
+// the callback URL from the request when none is configured.
+func (c *oidcClient) oauth2Config(r *http.Request, p *oidc.Provider) *oauth2.Config {
+	redirect := c.config.OIDCRedirectURL
+	if redirect == "" {
+		scheme := "http"
+		if isSecureRequest(r) {
+			scheme = "https"
+		}
+		redirect = scheme + "://" + r.Host + oidcCallback
+	}
+	return &oauth2.Config{
+		ClientID:     c.config.OIDCClientID,
+		ClientSecret: c.config.OIDCClientSecret,
+		RedirectURL:  redirect,
+		Endpoint:     p.Endpoint(),
+		Scopes:       []string{oidc.ScopeOpenID, "profile", "email"},
+	}
+}
+
+// OIDCHandlers returns the login and callback handlers of the OpenID
+// Connect flow. Both answer 404 when OIDC isn't configured.
+func OIDCHandlers(config models.Config) (login, callback http.HandlerFunc) {
+	c := &oidcClient{config: config}
+	return c.login, c.callback
+}
+
+// login redirects the browser to the provider, keeping the state, nonce
+// and PKCE verifier in a short-lived cookie for the callback to check.
+func (c *oidcClient) login(w http.ResponseWriter, r *http.Request) {
+	if !c.config.OIDCEnabled() {
+		http.NotFound(w, r)
+		return
+	}
+	if !c.config.AuthEnabled {
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+
+	p, err := c.discover(r.Context())
+	if err != nil {
+		log.Printf("⚠️  OIDC discovery for %s failed: %v", c.config.OIDCIssuer, err)
+		oidcFail(w, r, "Single sign-on provider unavailable")
+		return
+	}
+
+	state, nonce, verifier := GenerateToken(), GenerateToken(), oauth2.GenerateVerifier()
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state + "." + nonce + "." + verifier,
+		Path:     oidcCookiePath,
+		MaxAge:   int(oidcStateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	authURL := c.oauth2Config(r, p).AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// callback completes the flow: it exchanges the code, verifies the ID
+// token, provisions the user on first login and starts a Vigil session
+// exactly as password login does.
+func (c *oidcClient) callback(w http.ResponseWriter, r *http.Request) {
+	if !c.config.OIDCEnabled() {
+		http.NotFound(w, r)
+		return
+	}
+	if !c.config.AuthEnabled {
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+
+	var state, nonce, verifier string
+	if cookie, err := r.Cookie(oidcStateCookie); err == nil {
+		if parts := strings.Split(cookie.Value, "."); len(parts) == 3 {
+			state, nonce, verifier = parts[0], parts[1], parts[2]
+		}
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    "",
+		Path:     oidcCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	if msg := r.URL.Query().Get("error"); msg != "" {
+		audit.LogEvent(db.DB, r, 0, "", "login_failed", "user", "", "oidc: "+msg, "failure")
+		oidcFail(w, r, "Single sign-on was cancelled or denied")
+		return
+	}
+	if state == "" || r.URL.Query().Get("state") != state {
+		oidcFail(w, r, "Single sign-on session expired, please try again")
+		return
+	}
+
+	p, err := c.discover(r.Context())
+	if err != nil {
+		log.Printf("⚠️  OIDC discovery for %s failed: %v", c.config.OIDCIssuer, err)
+		oidcFail(w, r, "Single sign-on provider unavailable")
+		return
+	}
+
+	token, err := c.oauth2Config(r, p).Exchange(r.Context(), r.URL.Query().Get("code"), oauth2.VerifierOption(verifier))
+	if err != nil {
+		log.Printf("⚠️  OIDC code exchange failed: %v", err)
+		oidcFail(w, r, "Single sign-on failed")
+		return
+	}
+	rawID, ok := token.Extra("id_token").(string)
+	if !ok {
+		oidcFail(w, r, "Single sign-on provider returned no ID token")
+		return
+	}
+	idToken, err := p.Verifier(&oidc.Config{ClientID: c.config.OIDCClientID}).Verify(r.Context(), rawID)
+	if err != nil || idToken.Nonce != nonce {
+		log.Printf("⚠️  OIDC ID token rejected: %v", err)
+		oidcFail(w, r, "Single sign-on failed")
+		return
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		oidcFail(w, r, "Single sign-on failed")
+		return
+	}
+	if !inAllowedGroups(claims, c.config.OIDCAllowedGroups) {
+		name := oidcUsername(claims, c.config.OIDCUsernameClaim, idToken.Subject)
+		audit.LogEvent(db.DB, r, 0, name, "login_failed", "user", "", "oidc: not in an allowed group", "failure")
+		oidcFail(w, r, "Your account is not allowed to sign in to Vigil")
+		return
+	}
+
+	userID, username, created, err := provisionOIDCUser(db.DB,
+		idToken.Issuer+"|"+idToken.Subject,
+		oidcUsername(claims, c.config.OIDCUsernameClaim, idToken.Subject),
+		c.config.OIDCDefaultAdmin, c.config.OIDCDefaultProject)
+	if err != nil {
+		log.Printf("❌ OIDC user provisioning failed: %v", err)
+		oidcFail(w, r, "Failed to create user")
+		return
+	}
+	if created {
+		log.Printf("👤 Provisioned user %s from %s", username, idToken.Issuer)
+		audit.LogEvent(db.DB, r, userID, username, "user_create", "user", fmt.Sprintf("%d", userID), "oidc", "success")
+	}
+
+	sessionToken, expiresAt, err := CreateSession(userID, middleware.ExtractIP(r), r.UserAgent())
+	if err != nil {
+		oidcFail(w, r, "Failed to create session")
+		return
+	}
+	setSessionCookie(w, r, sessionToken, expiresAt)
+
+	log.Printf("🔓 Login (OIDC): %s", username)
+	audit.LogEvent(db.DB, r, userID, username, "login", "user", "", "oidc", "success")
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// oidcFail sends the browser back to the login page with a message.
+func oidcFail(w http.ResponseWriter, r *http.Request, msg string) {
+	http.Redirect(w, r, "/login.html?error="+url.QueryEscape(msg), http.StatusFound)
+}
+
+var invalidUsernameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// oidcUsername derives a valid Vigil username from the configured claim,
+// falling back to the email's local part and then the subject.
+func oidcUsername(claims map[string]interface{}, claim, subject string) string {
+	candidates := []string{}
+	if v, ok := claims[claim].(string); ok {
+		candidates = append(candidates, v)
+	}
+	if v, ok := claims["email"].(string); ok {
+		local, _, _ := strings.Cut(v, "@")
+		candidates = append(candidates, local)
+	}
+	candidates = append(candidates, subject)
+
+	for _, c := range candidates {
+		name := strings.Trim(invalidUsernameChars.ReplaceAllString(c, "_"), "_")
+		if len(name) > 56 {
+			name = name[:56]
+		}
+		if len(name) >= 3 {
+			return name
+		}
+	}
+	return "sso-user"
+}
+
+// inAllowedGroups reports whether the "groups" claim contains one of the
+// allowed groups. An empty allow list admits everyone.
+func inAllowedGroups(claims map[string]interface{}, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	var groups []string
+	switch v := claims["groups"].(type) {
+	case string:
+		groups = []string{v}
+	case []interface{}:
+		for _, g := range v {
+			if s, ok := g.(string); ok {
+				groups = append(groups, s)
+			}
+		}
+	}
+	for _, g := range groups {
+		for _, a := range allowed {
+			if g == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// provisionOIDCUser returns the user linked to the provider identity,
+// creating one on first login. New users get no password, so they can only
+// sign in through the provider, and a name already taken by another user
+// gets a numeric suffix rather than being linked to that account. A new
+// user is made an admin if admin is set, otherwise a member of the project
+// named project, if any; a project that doesn't exist is logged and leaves
+// the user without access.
+func provisionOIDCUser(conn *sql.DB, subject, username string, admin bool, project string) (id int, name string, created bool, err error) {
+	err = conn.QueryRow("SELECT id, username FROM users WHERE oidc_subject = ?", subject).Scan(&id, &name)
+	if err == nil {
+		return id, name, false, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, "", false, err
+	}
+
+	name = username
+	for i := 2; ; i++ {
+		var taken int
+		conn.QueryRow("SELECT COUNT(*) FROM users WHERE username = ?", name).Scan(&taken)
+		if taken == 0 {
+			break
+		}
+		if i > 100 {
+			return 0, "", false, fmt.Errorf("no free username for %q", username)
+		}
+		name = fmt.Sprintf("%s-%d", username, i)
+	}
+
+	res, err := conn.Exec(
+		"INSERT INTO users (username, password_hash, must_change_password, oidc_subject, is_admin) VALUES (?, '', 0, ?, ?)",
+		name, subject, admin,
+	)
+	if err != nil {
+		return 0, "", false, err
+	}
+	newID, _ := res.LastInsertId()
+
+	if !admin && project != "" {
+		var projectID int64
+		err := conn.QueryRow("SELECT id FROM projects WHERE name = ?", project).Scan(&projectID)
+		switch {
+		case err == sql.ErrNoRows:
+			log.Printf("⚠️  OIDC default project %q not found; %s starts without access", project, name)
+		case err != nil:
+			return 0, "", false, err
+		default:
+			if err := projects.AddUser(conn, projectID, newID); err != nil {
+				return 0, "", false, fmt.Errorf("add %s to project %q: %w", name, project, err)
+			}
+		}
+	}
+	return int(newID), name, true, nil
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"testing"
+
+	"vigil/internal/db"
+	"vigil/internal/projects"
+)
+
+func TestOIDCUsername(t *testing.T) {
+	tests := []struct {
+		claims map[string]interface{}
+		want   string
+	}{
+		{map[string]interface{}{"preferred_username": "alice"}, "alice"},
+		{map[string]interface{}{"preferred_username": "Alice Smith"}, "Alice_Smith"},
+		{map[string]interface{}{"email": "bob@example.com"}, "bob"},
+		{map[string]interface{}{"preferred_username": "x", "email": "carol@example.com"}, "carol"},
+		{map[string]interface{}{}, "sub-123"},
+	}
+	for _, tt := range tests {
+		if got := oidcUsername(tt.claims, "preferred_username", "sub-123"); got != tt.want {
+			t.Errorf("oidcUsername(%v) = %q, want %q", tt.claims, got, tt.want)
+		}
+	}
+}
+
+func TestInAllowedGroups(t *testing.T) {
+	claims := map[string]interface{}{"groups": []interface{}{"users", "vigil-admins"}}
+	if !inAllowedGroups(claims, nil) {
+		t.Error("an empty allow list should admit everyone")
+	}
+	if !inAllowedGroups(claims, []string{"vigil-admins"}) {
+		t.Error("expected member of vigil-admins to be admitted")
+	}
+	if inAllowedGroups(claims, []string{"ops"}) || inAllowedGroups(map[string]interface{}{}, []string{"ops"}) {
+		t.Error("expected users outside the allowed groups to be refused")
+	}
+}
+
+func TestProvisionOIDCUser(t *testing.T) {
+	setupSessionTestDB(t) // creates local user "alice"
+
+	id, name, created, err := provisionOIDCUser(db.DB, "https://idp|abc", "alice", false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created || name != "alice-2" {
+		t.Fatalf("got %q created=%v, want a new user alice-2 rather than the local alice", name, created)
+	}
+
+	again, name, created, err := provisionOIDCUser(db.DB, "https://idp|abc", "renamed", true, "")
+	if err != nil || created || again != id || name != "alice-2" {
+		t.Fatalf("second login: id=%d name=%q created=%v err=%v, want existing user %d", again, name, created, err, id)
+	}
+
+	var hash string
+	db.DB.QueryRow("SELECT password_hash FROM users WHERE id = ?", id).Scan(&hash)
+	if CheckPassword(hash, "") {
+		t.Error("provisioned user must not be able to log in with an empty password")
+	}
+}
+
+func TestProvisionOIDCUserDefaults(t *testing.T) {
+	setupSessionTestDB(t)
+	if err := projects.Migrate(db.DB); err != nil {
+		t.Fatal(err)
+	}
+	projectID, err := projects.CreateProject(db.DB, &projects.Project{Name: "storage"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isAdmin := func(id int) bool {
+		var admin bool
+		db.DB.QueryRow("SELECT is_admin FROM users WHERE id = ?", id).Scan(&admin)
+		return admin
+	}
+	member := func(id int) bool {
+		scope, err := projects.ForUser(db.DB, int64(id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return scope.AllowsProject(projectID)
+	}
+
+	admin, _, _, err := provisionOIDCUser(db.DB, "https://idp|1", "root", true, "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isAdmin(admin) {
+		t.Error("OIDC_DEFAULT_ADMIN: expected an admin")
+	}
+
+	user, _, _, err := provisionOIDCUser(db.DB, "https://idp|2", "bob", false, "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isAdmin(user) || !member(user) {
+		t.Error("OIDC_DEFAULT_PROJECT: expected a non-admin member of storage")
+	}
+
+	stray, _, created, err := provisionOIDCUser(db.DB, "https://idp|3", "carol", false, "missing")
+	if err != nil || !created {
+		t.Fatalf("unknown default project should still provision the user: created=%v err=%v", created, err)
+	}
+	if isAdmin(stray) || member(stray) {
+		t.Error("unknown default project: expected no access")
+	}
+}
@@ -2,10 +2,9 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
-	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -52,22 +51,113 @@ func GetSession(token string) *models.Session {
 		return nil
 	}
 
-	session.ExpiresAt, _ = time.Parse("2006-01-02 15:04:05", expiresAt)
+	session.ExpiresAt = db.ParseTime(expiresAt)
+
+	// Track activity for the sessions list, at most once a minute per
+	// session so authenticated requests don't each cost a write.
+	db.DB.Exec(`
+		UPDATE sessions SET last_seen = datetime('now')
+		WHERE token = ? AND (last_seen IS NULL OR last_seen < datetime('now', '-1 minute'))
+	`, token)
+
 	return &session
 }
 
-// CreateSession creates a new session for a user
-func CreateSession(userID int) (string, time.Time, error) {
+// CreateSession creates a new session for a user, recording the client IP
+// and user agent it was created from.
+func CreateSession(userID int, ipAddress, userAgent string) (string, time.Time, error) {
 	token := GenerateToken()
 	expiresAt := time.Now().Add(24 * time.Hour * 7)
 
-	_, err := db.DB.Exec(
-		"INSERT INTO sessions (token, user_id, expires_at) VALUES (?, ?, ?)",
-		token, userID, expiresAt.Format("2006-01-02 15:04:05"),
-	)
+	_, err := db.DB.Exec(`
+		INSERT INTO sessions (token, user_id, expires_at, created_at, last_seen, ip_address, user_agent)
+		VALUES (?, ?, ?, datetime('now'), datetime('now'), ?, ?)
+	`, token, userID, expiresAt.Format("2006-01-02 15:04:05"), ipAddress, userAgent)
 	return token, expiresAt, err
 }
 
+// SessionInfo describes an active session without exposing its token.
+type SessionInfo struct {
+	ID        string     `json:"id"`
+	Current   bool       `json:"current"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	LastSeen  *time.Time `json:"last_seen,omitempty"`
+	IPAddress string     `json:"ip_address,omitempty"`
+	UserAgent string     `json:"user_agent,omitempty"`
+}
+
+// SessionID derives the public identifier of a session. Listing other
+// sessions' tokens would let anyone who can read the list take them over,
+// so sessions are addressed by a hash prefix instead.
+func SessionID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ListUserSessions returns the unexpired sessions of a user, most recently
+// active first. currentToken marks the session making the request.
+func ListUserSessions(userID int, currentToken string) ([]SessionInfo, error) {
+	rows, err := db.DB.Query(`
+		SELECT token, expires_at, COALESCE(created_at, ''), COALESCE(last_seen, ''),
+			COALESCE(ip_address, ''), COALESCE(user_agent, '')
+		FROM sessions
+		WHERE user_id = ? AND expires_at > datetime('now')
+		ORDER BY COALESCE(last_seen, created_at, '') DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []SessionInfo{}
+	for rows.Next() {
+		var token, expiresAt, createdAt, lastSeen string
+		var s SessionInfo
+		if err := rows.Scan(&token, &expiresAt, &createdAt, &lastSeen, &s.IPAddress, &s.UserAgent); err != nil {
+			return nil, err
+		}
+		s.ID = SessionID(token)
+		s.Current = token == currentToken
+		s.ExpiresAt = db.ParseTime(expiresAt)
+		s.CreatedAt = optionalDBTime(createdAt)
+		s.LastSeen = optionalDBTime(lastSeen)
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// DeleteUserSession removes one of a user's sessions, given either its ID
+// or its token. It returns the deleted token, or "" if the user has no
+// such session.
+func DeleteUserSession(userID int, idOrToken string) (string, error) {
+	rows, err := db.DB.Query("SELECT token FROM sessions WHERE user_id = ?", userID)
+	if err != nil {
+		return "", err
+	}
+	var match string
+	for rows.Next() {
+		var token string
+		if err := rows.Scan(&token); err != nil {
+			rows.Close()
+			return "", err
+		}
+		if token == idOrToken || SessionID(token) == idOrToken {
+			match = token
+			break
+		}
+	}
+	rows.Close()
+	if match == "" {
+		return "", nil
+	}
+
+	if _, err := db.DB.Exec("DELETE FROM sessions WHERE token = ? AND user_id = ?", match, userID); err != nil {
+		return "", err
+	}
+	return match, nil
+}
+
 // DeleteSession removes a session
 func DeleteSession(token string) {
 	db.DB.Exec("DELETE FROM sessions WHERE token = ?", token)
@@ -78,35 +168,31 @@ func CleanupExpiredSessions() {
 	db.DB.Exec("DELETE FROM sessions WHERE expires_at < datetime('now')")
 }
 
-// CreateDefaultAdmin creates the initial admin user if none exists
+// CreateDefaultAdmin creates the initial admin user from ADMIN_USER and
+// ADMIN_PASS if no user exists. Without ADMIN_PASS the server waits for the
+// admin to be created through first-run setup (see Setup) instead.
 func CreateDefaultAdmin(config models.Config) {
-	var count int
-	db.DB.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
-	if count > 0 {
+	if !SetupRequired() {
 		return
 	}
 
-	password := config.AdminPass
-	mustChange := 1
-
-	if password == "" {
-		password = GenerateToken()[:12]
-		log.Printf("🔑 Generated admin password — check server logs only on first run")
-		fmt.Fprintf(os.Stderr, "\n  Admin password: %s\n\n", password)
-		log.Printf("   Set ADMIN_PASS environment variable to use a custom password")
-	} else {
-		mustChange = 0
+	if config.AdminPass == "" {
+		log.Printf("🧭 No users yet — open /setup to create the admin account")
+		if config.SetupToken == "" {
+			log.Printf("   Anyone who reaches the server first can claim it; set SETUP_TOKEN or ADMIN_PASS to prevent that")
+		}
+		return
 	}
 
-	hash, err := HashPassword(password)
+	hash, err := HashPassword(config.AdminPass)
 	if err != nil {
 		log.Printf("⚠️  Could not hash admin password: %v", err)
 		return
 	}
 
 	_, err = db.DB.Exec(
-		"INSERT INTO users (username, password_hash, must_change_password) VALUES (?, ?, ?)",
-		config.AdminUser, hash, mustChange,
+		"INSERT INTO users (username, password_hash, must_change_password, is_admin) VALUES (?, ?, 0, 1)",
+		config.AdminUser, hash,
 	)
 	if err != nil {
 		log.Printf("⚠️  Could not create admin user: %v", err)
@@ -114,3 +200,15 @@ func CreateDefaultAdmin(config models.Config) {
 		log.Printf("✓ Created admin user: %s", config.AdminUser)
 	}
 }
+
+// optionalDBTime is db.ParseTime for nullable columns: nil when unset.
+func optionalDBTime(s string) *time.Time {
+	if s == "" {
+		return nil
+	}
+	t := db.ParseTime(s)
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
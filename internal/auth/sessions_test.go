@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"path/filepath"
+	"testing"
+
+	"vigil/internal/db"
+)
+
+func setupSessionTestDB(t *testing.T) int {
+	t.Helper()
+	if err := db.Init(filepath.Join(t.TempDir(), "vigil.db")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.DB.Close() })
+
+	res, err := db.DB.Exec("INSERT INTO users (username, password_hash) VALUES ('alice', 'x')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := res.LastInsertId()
+	return int(id)
+}
+
+func TestListAndRevokeSessions(t *testing.T) {
+	userID := setupSessionTestDB(t)
+
+	laptop, _, err := CreateSession(userID, "10.0.0.5", "Firefox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	phone, _, err := CreateSession(userID, "10.0.0.9", "Safari")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := ListUserSessions(userID, laptop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	var current *SessionInfo
+	for i := range sessions {
+		s := &sessions[i]
+		if s.ID == laptop || s.ID == phone {
+			t.Fatalf("session list exposes a token: %+v", s)
+		}
+		if s.CreatedAt == nil || s.LastSeen == nil {
+			t.Errorf("expected created/last-seen times, got %+v", s)
+		}
+		if s.Current {
+			current = s
+		}
+	}
+	if current == nil || current.IPAddress != "10.0.0.5" || current.UserAgent != "Firefox" {
+		t.Fatalf("expected the laptop session marked current, got %+v", sessions)
+	}
+
+	// Another user can't revoke it.
+	if tok, _ := DeleteUserSession(userID+1, SessionID(phone)); tok != "" {
+		t.Fatal("revoked another user's session")
+	}
+
+	tok, err := DeleteUserSession(userID, SessionID(phone))
+	if err != nil || tok != phone {
+		t.Fatalf("revoke by id: tok=%q err=%v", tok, err)
+	}
+	if GetSession(phone) != nil {
+		t.Error("revoked session still valid")
+	}
+	if GetSession(laptop) == nil {
+		t.Error("other session was revoked too")
+	}
+
+	// Revoking by raw token works as well.
+	if tok, _ := DeleteUserSession(userID, laptop); tok != laptop {
+		t.Errorf("revoke by token: got %q", tok)
+	}
+}
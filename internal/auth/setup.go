@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"vigil/internal/audit"
+	"vigil/internal/db"
+	"vigil/internal/middleware"
+	"vigil/internal/models"
+	"vigil/internal/validate"
+)
+
+// SetupRequired reports whether no user exists yet, so the first admin
+// still has to be created.
+func SetupRequired() bool {
+	var exists int
+	db.DB.QueryRow("SELECT EXISTS (SELECT 1 FROM users)").Scan(&exists)
+	return exists == 0
+}
+
+// SetupStatus reports whether first-run setup is open and whether it needs
+// the SETUP_TOKEN.
+// GET /api/setup
+func SetupStatus(config models.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, map[string]interface{}{
+			"setup_required": config.AuthEnabled && SetupRequired(),
+			"token_required": config.SetupToken != "",
+		})
+	}
+}
+
+// Setup creates the first admin account and signs it in. It only works
+// while no user exists; afterwards it answers 409, so setup can't be used
+// to take over a configured server.
+// POST /api/setup
+func Setup(config models.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !config.AuthEnabled {
+			jsonError(w, "Authentication is disabled", http.StatusNotFound)
+			return
+		}
+
+		var req struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			Token    string `json:"token"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			jsonError(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+
+		if !SetupRequired() {
+			jsonError(w, "Setup already completed", http.StatusConflict)
+			return
+		}
+		if config.SetupToken != "" && subtle.ConstantTimeCompare([]byte(req.Token), []byte(config.SetupToken)) != 1 {
+			audit.LogEvent(db.DB, r, 0, req.Username, "setup_failed", "user", "", "invalid setup token", "failure")
+			jsonError(w, "Invalid setup token", http.StatusForbidden)
+			return
+		}
+
+		req.Username = strings.TrimSpace(req.Username)
+		if err := validate.Username(req.Username); err != nil {
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(req.Password) < 6 {
+			jsonError(w, "Password must be at least 6 characters", http.StatusBadRequest)
+			return
+		}
+
+		hash, err := HashPassword(req.Password)
+		if err != nil {
+			jsonError(w, "Failed to hash password", http.StatusInternalServerError)
+			return
+		}
+
+		// One statement, so two racing requests can't both create an admin
+		res, err := db.DB.Exec(`
+			INSERT INTO users (username, password_hash, must_change_password, is_admin)
+			SELECT ?, ?, 0, 1 WHERE NOT EXISTS (SELECT 1 FROM users)`,
+			req.Username, hash,
+		)
+		if err != nil {
+			jsonError(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			jsonError(w, "Setup already completed", http.StatusConflict)
+			return
+		}
+		userID, _ := res.LastInsertId()
+
+		log.Printf("👤 Admin created through first-run setup: %s", req.Username)
+		audit.LogEvent(db.DB, r, int(userID), req.Username, "setup", "user", req.Username, "", "success")
+
+		token, expiresAt, err := CreateSession(int(userID), middleware.ExtractIP(r), r.UserAgent())
+		if err != nil {
+			jsonError(w, "Admin created, but failed to create session", http.StatusInternalServerError)
+			return
+		}
+		setSessionCookie(w, r, token, expiresAt)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"token":    token,
+			"username": req.Username,
+		})
+	}
+}
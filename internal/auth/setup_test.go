@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"vigil/internal/db"
+	"vigil/internal/models"
+)
+
+func TestSetupCreatesFirstAdminOnce(t *testing.T) {
+	if err := db.Init(filepath.Join(t.TempDir(), "vigil.db")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.DB.Close() })
+
+	cfg := models.Config{AuthEnabled: true, AdminUser: "admin", SetupToken: "s3cret"}
+	CreateDefaultAdmin(cfg) // no ADMIN_PASS: leaves the admin to setup
+	if !SetupRequired() {
+		t.Fatal("expected setup to be required without ADMIN_PASS")
+	}
+
+	setup := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		Setup(cfg)(rec, httptest.NewRequest(http.MethodPost, "/api/setup", strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := setup(`{"username":"root","password":"hunter22","token":"wrong"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("wrong token: status %d, want 403", rec.Code)
+	}
+	if rec := setup(`{"username":"root","password":"abc","token":"s3cret"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("short password: status %d, want 400", rec.Code)
+	}
+
+	rec := setup(`{"username":"root","password":"hunter22","token":"s3cret"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("setup: status %d (%s), want 201", rec.Code, rec.Body)
+	}
+	if len(rec.Result().Cookies()) == 0 || GetSession(rec.Result().Cookies()[0].Value) == nil {
+		t.Error("expected setup to sign the new admin in")
+	}
+	if SetupRequired() {
+		t.Error("setup should be closed once a user exists")
+	}
+
+	if rec := setup(`{"username":"mallory","password":"hunter22","token":"s3cret"}`); rec.Code != http.StatusConflict {
+		t.Errorf("second setup: status %d, want 409", rec.Code)
+	}
+	var count, admins int
+	db.DB.QueryRow("SELECT COUNT(*), COALESCE(SUM(is_admin), 0) FROM users").Scan(&count, &admins)
+	if count != 1 || admins != 1 {
+		t.Errorf("expected exactly one user, an admin; got %d users, %d admins", count, admins)
+	}
+}
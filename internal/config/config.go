@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 
 	"vigil/internal/models"
 )
@@ -9,11 +11,31 @@ import (
 // Load returns the server configuration from environment variables
 func Load() models.Config {
 	return models.Config{
-		Port:        getEnv("PORT", "9080"),
-		DBPath:      getEnv("DB_PATH", "vigil.db"),
-		AdminUser:   getEnv("ADMIN_USER", "admin"),
-		AdminPass:   getEnv("ADMIN_PASS", ""),
-		AuthEnabled: getEnv("AUTH_ENABLED", "true") == "true",
+		Port:               getEnv("PORT", "9080"),
+		DBPath:             getEnv("DB_PATH", "vigil.db"),
+		AdminUser:          getEnv("ADMIN_USER", "admin"),
+		AdminPass:          getEnv("ADMIN_PASS", ""),
+		AuthEnabled:        getEnv("AUTH_ENABLED", "true") == "true",
+		SetupToken:         getEnv("SETUP_TOKEN", ""),
+		DisplayTimezone:    getEnv("DISPLAY_TIMEZONE", ""),
+		ReportHMACSecret:   getEnv("REPORT_HMAC_SECRET", ""),
+		DBEncryptionKey:    getEnv("DB_ENCRYPTION_KEY", ""),
+		EmergencyWebhook:   getEnv("EMERGENCY_WEBHOOK_URL", ""),
+		SyslogHost:         getEnv("SYSLOG_HOST", ""),
+		SyslogPort:         getEnv("SYSLOG_PORT", "514"),
+		SyslogProtocol:     getEnv("SYSLOG_PROTOCOL", "udp"),
+		SyslogFacility:     getEnv("SYSLOG_FACILITY", "local0"),
+		DBMaxOpenConns:     getEnvInt("DB_MAX_OPEN_CONNS", 0),
+		DBSerializeWrites:  getEnv("DB_SERIALIZE_WRITES", "true") == "true",
+		GRPCPort:           getEnv("GRPC_PORT", ""),
+		OIDCIssuer:         getEnv("OIDC_ISSUER", ""),
+		OIDCClientID:       getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:   getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:    getEnv("OIDC_REDIRECT_URL", ""),
+		OIDCUsernameClaim:  getEnv("OIDC_USERNAME_CLAIM", "preferred_username"),
+		OIDCAllowedGroups:  getEnvList("OIDC_ALLOWED_GROUPS"),
+		OIDCDefaultAdmin:   getEnv("OIDC_DEFAULT_ADMIN", "false") == "true",
+		OIDCDefaultProject: getEnv("OIDC_DEFAULT_PROJECT", ""),
 	}
 }
 
@@ -23,3 +45,23 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvInt(key string, fallback int) int {
+	if value, ok := os.LookupEnv(key); ok {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return fallback
+}
+
+// getEnvList splits a comma-separated variable, dropping empty entries.
+func getEnvList(key string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,93 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// sealedReportPrefix marks a reports.data value written by SealReport.
+// Raw report JSON always starts with '{', so rows stored before a key was
+// configured are told apart and read as they are.
+const sealedReportPrefix = "enc:v1:"
+
+// reportAEAD seals report blobs; nil stores them as plaintext.
+var reportAEAD cipher.AEAD
+
+// SetReportKey enables at-rest encryption of report blobs with AES-256-GCM.
+// key is 32 bytes, base64 or hex encoded (e.g. `openssl rand -base64 32`).
+func SetReportKey(key string) error {
+	raw, err := decodeReportKey(key)
+	if err != nil {
+		return err
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	reportAEAD = aead
+	return nil
+}
+
+func decodeReportKey(key string) ([]byte, error) {
+	if raw, err := base64.StdEncoding.DecodeString(key); err == nil && len(raw) == 32 {
+		return raw, nil
+	}
+	if raw, err := hex.DecodeString(key); err == nil && len(raw) == 32 {
+		return raw, nil
+	}
+	return nil, errors.New("key must be 32 bytes, base64 or hex encoded")
+}
+
+// ReportEncryptionEnabled reports whether SetReportKey has been called.
+func ReportEncryptionEnabled() bool {
+	return reportAEAD != nil
+}
+
+// SealReport returns data the way it is stored in reports.data: sealed
+// with the report key, or unchanged when none is set.
+func SealReport(data []byte) ([]byte, error) {
+	if reportAEAD == nil {
+		return data, nil
+	}
+	nonce := make([]byte, reportAEAD.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	sealed := reportAEAD.Seal(nonce, nonce, data, nil)
+	return []byte(sealedReportPrefix + base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// OpenReport reverses SealReport. Plaintext values are returned as they
+// are, so a database that predates the key keeps working.
+func OpenReport(stored []byte) ([]byte, error) {
+	encoded, ok := bytes.CutPrefix(stored, []byte(sealedReportPrefix))
+	if !ok {
+		return stored, nil
+	}
+	if reportAEAD == nil {
+		return nil, errors.New("report is encrypted but DB_ENCRYPTION_KEY is not set")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("decode encrypted report: %w", err)
+	}
+	n := reportAEAD.NonceSize()
+	if len(sealed) < n {
+		return nil, errors.New("encrypted report is truncated")
+	}
+	data, err := reportAEAD.Open(nil, sealed[:n], sealed[n:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt report (wrong DB_ENCRYPTION_KEY?): %w", err)
+	}
+	return data, nil
+}
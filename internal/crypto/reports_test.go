@@ -0,0 +1,57 @@
+package crypto
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReportSealRoundTrip(t *testing.T) {
+	t.Cleanup(func() { reportAEAD = nil })
+	plain := []byte(`{"hostname":"nas01","drives":[{"serial_number":"WD-123"}]}`)
+
+	// Without a key reports are stored as they are.
+	stored, err := SealReport(plain)
+	if err != nil || !bytes.Equal(stored, plain) {
+		t.Fatalf("SealReport without key = %q, %v", stored, err)
+	}
+
+	if err := SetReportKey("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="); err != nil {
+		t.Fatalf("SetReportKey: %v", err)
+	}
+	sealed, err := SealReport(plain)
+	if err != nil {
+		t.Fatalf("SealReport: %v", err)
+	}
+	if !strings.HasPrefix(string(sealed), sealedReportPrefix) || bytes.Contains(sealed, []byte("WD-123")) {
+		t.Fatalf("report not sealed: %q", sealed)
+	}
+
+	for _, in := range [][]byte{sealed, plain} {
+		got, err := OpenReport(in)
+		if err != nil || !bytes.Equal(got, plain) {
+			t.Errorf("OpenReport(%.20q) = %q, %v", in, got, err)
+		}
+	}
+
+	if err := SetReportKey(strings.Repeat("ab", 32)); err != nil {
+		t.Fatalf("SetReportKey hex: %v", err)
+	}
+	if _, err := OpenReport(sealed); err == nil {
+		t.Error("expected an error opening a report sealed with another key")
+	}
+
+	reportAEAD = nil
+	if _, err := OpenReport(sealed); err == nil {
+		t.Error("expected an error opening a sealed report without a key")
+	}
+}
+
+func TestSetReportKeyRejectsBadKeys(t *testing.T) {
+	t.Cleanup(func() { reportAEAD = nil })
+	for _, key := range []string{"", "short", strings.Repeat("ab", 16)} {
+		if err := SetReportKey(key); err == nil {
+			t.Errorf("SetReportKey(%q) should fail", key)
+		}
+	}
+}
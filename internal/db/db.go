@@ -24,7 +24,13 @@ func Init(path string) error {
 	// agent reports + login session inserts + dashboard polls) writes can queue.
 	// 5s was too short and caused SQLITE_BUSY → 500 on /api/report and
 	// /api/auth/login while a slow read held the lock.
-	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(30000)", path)
+	//
+	// _txlock=immediate: a deferred transaction that reads first and then
+	// writes has to upgrade its lock, and in WAL mode that upgrade fails with
+	// "database is locked" straight away if another writer got in between —
+	// busy_timeout doesn't apply. Taking the write lock at BEGIN makes
+	// transactions wait their turn like single statements do.
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(30000)&_txlock=immediate", path)
 	DB, err = sql.Open("sqlite", dsn)
 	if err != nil {
 		return fmt.Errorf("failed to open database at %s: %w", path, err)
@@ -83,6 +89,7 @@ func createSchema() error {
 		username TEXT UNIQUE NOT NULL,
 		password_hash TEXT NOT NULL,
 		must_change_password INTEGER DEFAULT 0,
+		is_admin INTEGER NOT NULL DEFAULT 0,
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	);
 
@@ -90,6 +97,10 @@ func createSchema() error {
 		token TEXT PRIMARY KEY,
 		user_id INTEGER NOT NULL,
 		expires_at DATETIME NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		last_seen DATETIME,
+		ip_address TEXT,
+		user_agent TEXT,
 		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 	);
 	CREATE INDEX IF NOT EXISTS idx_sessions_expires ON sessions(expires_at);
@@ -134,6 +145,20 @@ func migrateSchema() {
 	// Add must_change_password column if it doesn't exist
 	DB.Exec("ALTER TABLE users ADD COLUMN must_change_password INTEGER DEFAULT 0")
 
+	// Users provisioned by OpenID Connect login are linked to their
+	// provider identity ("issuer|subject") rather than a password.
+	DB.Exec("ALTER TABLE users ADD COLUMN oidc_subject TEXT")
+	DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_users_oidc_subject ON users(oidc_subject) WHERE oidc_subject IS NOT NULL")
+
+	// Admins are flagged explicitly. Before the flag, every user outside
+	// a project was an admin, so those users keep admin access.
+	if _, err := DB.Exec("ALTER TABLE users ADD COLUMN is_admin INTEGER NOT NULL DEFAULT 0"); err == nil {
+		if _, err := DB.Exec("UPDATE users SET is_admin = 1 WHERE id NOT IN (SELECT user_id FROM project_users)"); err != nil {
+			// No projects yet
+			DB.Exec("UPDATE users SET is_admin = 1")
+		}
+	}
+
 	// Phase 2: Active scan progress columns on zfs_pools
 	DB.Exec("ALTER TABLE zfs_pools ADD COLUMN scan_speed INTEGER DEFAULT 0")
 	DB.Exec("ALTER TABLE zfs_pools ADD COLUMN scan_errors INTEGER DEFAULT 0")
@@ -141,4 +166,12 @@ func migrateSchema() {
 
 	// Phase 3: Pool compression ratio
 	DB.Exec("ALTER TABLE zfs_pools ADD COLUMN compress_ratio REAL DEFAULT 1.0")
+
+	// Session metadata for the active-sessions list. ALTER TABLE can't add a
+	// CURRENT_TIMESTAMP default, so sessions from before this have no
+	// created_at.
+	DB.Exec("ALTER TABLE sessions ADD COLUMN created_at DATETIME")
+	DB.Exec("ALTER TABLE sessions ADD COLUMN last_seen DATETIME")
+	DB.Exec("ALTER TABLE sessions ADD COLUMN ip_address TEXT")
+	DB.Exec("ALTER TABLE sessions ADD COLUMN user_agent TEXT")
 }
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// HealthStatus is the result of a database liveness probe.
+type HealthStatus struct {
+	OK          bool   `json:"ok"`
+	JournalMode string `json:"journal_mode,omitempty"`
+	Write       string `json:"write"` // "ok", "busy" or "failed"
+	LatencyMs   int64  `json:"latency_ms"`
+	Error       string `json:"error,omitempty"`
+}
+
+// CheckHealth runs a cheap read (SELECT 1), reports the journal mode, and
+// verifies the database can still take a write lock. A lock that is merely
+// contended ("busy") does not count as unhealthy; a read-only or I/O-failing
+// database does.
+func CheckHealth(ctx context.Context, d *sql.DB) (st HealthStatus) {
+	start := time.Now()
+	st.Write = "failed"
+	defer func() { st.LatencyMs = time.Since(start).Milliseconds() }()
+
+	if d == nil {
+		st.Error = "database not initialised"
+		return st
+	}
+
+	var one int
+	if err := d.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		st.Error = "read: " + err.Error()
+		return st
+	}
+	if err := d.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&st.JournalMode); err != nil {
+		st.Error = "journal_mode: " + err.Error()
+		return st
+	}
+
+	conn, err := d.Conn(ctx)
+	if err != nil {
+		st.Error = "conn: " + err.Error()
+		return st
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		if isBusy(ctx, err) {
+			st.Write = "busy"
+			st.OK = true
+			return st
+		}
+		st.Error = "write: " + err.Error()
+		return st
+	}
+	// Roll back on a fresh context so a cancelled probe never leaves the lock held.
+	if _, err := conn.ExecContext(context.Background(), "ROLLBACK"); err != nil {
+		st.Error = "write: " + err.Error()
+		return st
+	}
+
+	st.Write = "ok"
+	st.OK = true
+	return st
+}
+
+func isBusy(ctx context.Context, err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "busy") || strings.Contains(msg, "locked") || strings.Contains(msg, "interrupted")
+}
@@ -117,7 +117,8 @@ func MigrateSchemaExtensions(db *sql.DB) error {
 			CREATE INDEX IF NOT EXISTS idx_zfs_scrub_pool      ON zfs_scrub_history(pool_id);
 			CREATE INDEX IF NOT EXISTS idx_zfs_scrub_hostname  ON zfs_scrub_history(hostname);
 			CREATE INDEX IF NOT EXISTS idx_zfs_scrub_start     ON zfs_scrub_history(start_time);
-			CREATE INDEX IF NOT EXISTS idx_zfs_scrub_state     ON zfs_scrub_history(state);`},
+			CREATE INDEX IF NOT EXISTS idx_zfs_scrub_state     ON zfs_scrub_history(state);
+			CREATE INDEX IF NOT EXISTS idx_zfs_scrub_type      ON zfs_scrub_history(pool_id, scan_type);`},
 
 		// ─── zfs_pool_devices ────────────────────────────────────────────
 		{"zfs_pool_devices", `
@@ -178,6 +179,55 @@ func MigrateSchemaExtensions(db *sql.DB) error {
 			CREATE INDEX IF NOT EXISTS idx_zfs_ds_hostname ON zfs_datasets(hostname);
 			CREATE INDEX IF NOT EXISTS idx_zfs_ds_name     ON zfs_datasets(dataset_name);`},
 
+		// ─── zfs_pool_properties ─────────────────────────────────────────
+		{"zfs_pool_properties", `
+			CREATE TABLE IF NOT EXISTS zfs_pool_properties (
+				pool_id    INTEGER NOT NULL,
+				hostname   TEXT    NOT NULL,
+				pool_name  TEXT    NOT NULL,
+				property   TEXT    NOT NULL,
+				value      TEXT    NOT NULL,
+				source     TEXT,
+				last_seen  DATETIME DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (pool_id, property),
+				FOREIGN KEY (pool_id) REFERENCES zfs_pools(id) ON DELETE CASCADE
+			);`},
+		{"zfs_pool_properties indexes", `
+			CREATE INDEX IF NOT EXISTS idx_zfs_prop_hostname ON zfs_pool_properties(hostname);`},
+
+		// ─── zfs_pool_usage (hourly allocated-bytes samples) ────────────
+		{"zfs_pool_usage", `
+			CREATE TABLE IF NOT EXISTS zfs_pool_usage (
+				pool_id         INTEGER NOT NULL,
+				hostname        TEXT    NOT NULL,
+				pool_name       TEXT    NOT NULL,
+				allocated_bytes INTEGER NOT NULL,
+				size_bytes      INTEGER NOT NULL,
+				timestamp       DATETIME NOT NULL, -- truncated to the hour
+				PRIMARY KEY (pool_id, timestamp),
+				FOREIGN KEY (pool_id) REFERENCES zfs_pools(id) ON DELETE CASCADE
+			);`},
+		{"zfs_pool_usage indexes", `
+			CREATE INDEX IF NOT EXISTS idx_zfs_usage_hostname ON zfs_pool_usage(hostname);`},
+
+		// ─── zfs_pool_iostat (per-report pool and vdev I/O samples) ─────
+		{"zfs_pool_iostat", `
+			CREATE TABLE IF NOT EXISTS zfs_pool_iostat (
+				pool_id     INTEGER NOT NULL,
+				hostname    TEXT    NOT NULL,
+				pool_name   TEXT    NOT NULL,
+				vdev        TEXT    NOT NULL DEFAULT '', -- '' for the pool total
+				read_ops    INTEGER NOT NULL DEFAULT 0,
+				write_ops   INTEGER NOT NULL DEFAULT 0,
+				read_bytes  INTEGER NOT NULL DEFAULT 0,
+				write_bytes INTEGER NOT NULL DEFAULT 0,
+				timestamp   DATETIME NOT NULL, -- truncated to the minute
+				PRIMARY KEY (pool_id, vdev, timestamp),
+				FOREIGN KEY (pool_id) REFERENCES zfs_pools(id) ON DELETE CASCADE
+			);`},
+		{"zfs_pool_iostat indexes", `
+			CREATE INDEX IF NOT EXISTS idx_zfs_iostat_pool_time ON zfs_pool_iostat(pool_id, timestamp);`},
+
 		// ─── api_tokens ──────────────────────────────────────────────────
 		{"api_tokens", `
 			CREATE TABLE IF NOT EXISTS api_tokens (
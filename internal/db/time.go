@@ -0,0 +1,19 @@
+package db
+
+import "time"
+
+// ParseTime parses a DATETIME column scanned into a string. Values are
+// written in the bare UTC layout, but the driver hands them back as RFC3339
+// and older rows may carry a fractional offset form; anything else yields
+// the zero time.
+func ParseTime(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04:05.999999999-07:00"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
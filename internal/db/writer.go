@@ -0,0 +1,29 @@
+package db
+
+import "sync"
+
+// writeMu serialises the server's bulk writers — report ingestion and the
+// retention sweep — so they queue in Go instead of racing for SQLite's
+// single write lock. Readers never take it; WAL lets them run alongside
+// whichever writer holds the lock. Small one-off writes (sessions, settings,
+// audit) don't go through it and rely on busy_timeout instead.
+var (
+	writeMu         sync.Mutex
+	serializeWrites = true
+)
+
+// SetSerializeWrites turns the write lock on or off. Call before serving;
+// it is not safe to change while writers are running.
+func SetSerializeWrites(on bool) {
+	serializeWrites = on
+}
+
+// Write runs fn while holding the write lock and returns its error. fn must
+// not call Write itself.
+func Write(fn func() error) error {
+	if serializeWrites {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+	}
+	return fn()
+}
@@ -0,0 +1,74 @@
+// Package drivealerts stores the per-drive switch that turns off alerting
+// for a drive while its data keeps being recorded, for drives whose
+// readings can't be trusted (e.g. a USB disk without a thermal sensor).
+package drivealerts
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Migrate creates the drive_alert_settings table if it doesn't exist.
+func Migrate(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS drive_alert_settings (
+			hostname       TEXT NOT NULL COLLATE NOCASE,
+			serial_number  TEXT NOT NULL,
+			alerts_enabled INTEGER NOT NULL DEFAULT 1,
+			updated_at     DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (hostname, serial_number)
+		)`)
+	if err != nil {
+		return fmt.Errorf("drivealerts migration: %w", err)
+	}
+	return nil
+}
+
+// Enabled reports whether alerts are on for the drive. Drives without a
+// row, and any lookup failure, count as enabled: an unreadable switch
+// shouldn't silence a failing drive.
+func Enabled(db *sql.DB, hostname, serial string) bool {
+	var enabled bool
+	err := db.QueryRow(
+		`SELECT alerts_enabled FROM drive_alert_settings WHERE hostname = ? AND serial_number = ?`,
+		hostname, serial,
+	).Scan(&enabled)
+	return err != nil || enabled
+}
+
+// SetEnabled turns alerts for the drive on or off. Turning them back on
+// removes the row, since enabled is the default.
+func SetEnabled(db *sql.DB, hostname, serial string, enabled bool) error {
+	if enabled {
+		_, err := db.Exec(`DELETE FROM drive_alert_settings WHERE hostname = ? AND serial_number = ?`, hostname, serial)
+		return err
+	}
+	_, err := db.Exec(`
+		INSERT INTO drive_alert_settings (hostname, serial_number, alerts_enabled) VALUES (?, ?, 0)
+		ON CONFLICT(hostname, serial_number) DO UPDATE SET
+			alerts_enabled = 0,
+			updated_at = CURRENT_TIMESTAMP`,
+		hostname, serial,
+	)
+	return err
+}
+
+// Disabled returns the drives with alerts turned off, keyed by lowercased
+// hostname + ":" + serial.
+func Disabled(db *sql.DB) (map[string]bool, error) {
+	rows, err := db.Query(`SELECT LOWER(hostname), serial_number FROM drive_alert_settings WHERE alerts_enabled = 0`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]bool)
+	for rows.Next() {
+		var hostname, serial string
+		if err := rows.Scan(&hostname, &serial); err != nil {
+			return nil, err
+		}
+		out[hostname+":"+serial] = true
+	}
+	return out, rows.Err()
+}
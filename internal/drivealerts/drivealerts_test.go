@@ -0,0 +1,70 @@
+package drivealerts
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestEnabled(t *testing.T) {
+	db := setupTestDB(t)
+
+	if !Enabled(db, "nas01", "SER1") {
+		t.Error("expected alerts enabled by default")
+	}
+
+	if err := SetEnabled(db, "nas01", "SER1", false); err != nil {
+		t.Fatal(err)
+	}
+	if Enabled(db, "NAS01", "SER1") {
+		t.Error("expected alerts disabled, whatever the hostname's case")
+	}
+	if !Enabled(db, "nas01", "SER2") {
+		t.Error("expected other drives unaffected")
+	}
+
+	disabled, err := Disabled(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(disabled) != 1 || !disabled["nas01:SER1"] {
+		t.Errorf("unexpected disabled drives %v", disabled)
+	}
+
+	// Disabling twice is fine, and re-enabling restores the default
+	if err := SetEnabled(db, "nas01", "SER1", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetEnabled(db, "nas01", "SER1", true); err != nil {
+		t.Fatal(err)
+	}
+	if !Enabled(db, "nas01", "SER1") {
+		t.Error("expected alerts re-enabled")
+	}
+}
+
+func TestEnabledWithoutTable(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if !Enabled(db, "nas01", "SER1") {
+		t.Error("expected alerts enabled when the table is missing")
+	}
+}
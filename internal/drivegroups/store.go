@@ -3,7 +3,8 @@ package drivegroups
 import (
 	"database/sql"
 	"fmt"
-	"time"
+
+	vigildb "vigil/internal/db"
 )
 
 // ── Group CRUD ──────────────────────────────────────────────────────────
@@ -59,7 +60,7 @@ func ListGroups(db *sql.DB) ([]DriveGroup, error) {
 		if err := rows.Scan(&g.ID, &g.Name, &g.Color, &ts, &g.MemberCount); err != nil {
 			continue
 		}
-		g.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", ts)
+		g.CreatedAt = vigildb.ParseTime(ts)
 		groups = append(groups, g)
 	}
 	return groups, nil
@@ -78,7 +79,7 @@ func GetGroup(db *sql.DB, id int64) (*DriveGroup, error) {
 	if err != nil {
 		return nil, err
 	}
-	g.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", ts)
+	g.CreatedAt = vigildb.ParseTime(ts)
 	return &g, nil
 }
 
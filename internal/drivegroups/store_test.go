@@ -56,6 +56,9 @@ func TestGroupCRUD(t *testing.T) {
 	if got.Color != "#00ff00" {
 		t.Fatalf("expected color #00ff00, got %s", got.Color)
 	}
+	if got.CreatedAt.IsZero() {
+		t.Error("expected created_at to be parsed")
+	}
 
 	// Update
 	got.Name = "Critical"
@@ -0,0 +1,231 @@
+package drivename
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"strings"
+
+	agentsmart "vigil/cmd/agent/smart"
+	"vigil/internal/crypto"
+	"vigil/internal/settings"
+)
+
+// Label sources, in the order drives/display_name_order may list them.
+const (
+	SourceAlias  = "alias"  // user-assigned drive alias
+	SourceBay    = "bay"    // enclosure and slot, e.g. "Shelf A / Bay 3"
+	SourceModel  = "model"  // model name from the latest report
+	SourceSerial = "serial" // serial number
+	SourceDevice = "device" // by-id path, else kernel name, without /dev/
+)
+
+// DefaultOrder is the label priority used when the setting is empty or
+// names no known source.
+const DefaultOrder = "alias,bay,model,serial,device"
+
+var knownSources = map[string]bool{
+	SourceAlias: true, SourceBay: true, SourceModel: true, SourceSerial: true, SourceDevice: true,
+}
+
+// ParseOrder parses a comma-separated source list, dropping unknown and
+// repeated entries. A list with no known source yields DefaultOrder.
+func ParseOrder(spec string) []string {
+	var order []string
+	seen := make(map[string]bool)
+	for _, s := range strings.Split(spec, ",") {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if !knownSources[s] || seen[s] {
+			continue
+		}
+		seen[s] = true
+		order = append(order, s)
+	}
+	if len(order) == 0 && spec != DefaultOrder {
+		return ParseOrder(DefaultOrder)
+	}
+	return order
+}
+
+// driveInfo is what the latest report says about a drive.
+type driveInfo struct {
+	model  string
+	device string
+}
+
+// Resolver picks the human-facing label of drives, so every view names a
+// drive the same way. Aliases and bays are loaded up front; models and
+// device names come from each host's latest report, read the first time a
+// drive of that host is asked for unless the caller passed them to Know.
+// A Resolver is meant for one request or one batch and isn't safe for
+// concurrent use.
+type Resolver struct {
+	db      *sql.DB
+	order   []string
+	aliases map[string]string
+	bays    map[string]string
+	drives  map[string]driveInfo
+	loaded  map[string]bool // hostnames whose latest report was read
+}
+
+// NewResolver loads the configured order (drives/display_name_order),
+// aliases and enclosure slots. Lookup failures are logged and leave the
+// label to the next source in the order.
+func NewResolver(db *sql.DB) *Resolver {
+	r := &Resolver{
+		db:      db,
+		order:   ParseOrder(settings.GetStringSettingWithDefault(db, "drives", "display_name_order", DefaultOrder)),
+		aliases: make(map[string]string),
+		bays:    make(map[string]string),
+		drives:  make(map[string]driveInfo),
+		loaded:  make(map[string]bool),
+	}
+	for _, src := range r.order {
+		switch src {
+		case SourceAlias:
+			r.loadAliases()
+		case SourceBay:
+			r.loadBays()
+		}
+	}
+	return r
+}
+
+// DisplayName returns a drive's label for one-off lookups; use a Resolver
+// when labelling many drives.
+func DisplayName(db *sql.DB, hostname, serial string) string {
+	return NewResolver(db).DisplayName(hostname, serial)
+}
+
+// Order returns the sources the resolver tries, in priority order.
+func (r *Resolver) Order() []string {
+	return r.order
+}
+
+// Know records a drive's model and device path from a report the caller
+// already holds, sparing the resolver from reading it again.
+func (r *Resolver) Know(hostname, serial, model, device string) {
+	r.drives[hostname+":"+serial] = driveInfo{model: strings.TrimSpace(model), device: device}
+}
+
+// DisplayName returns the first non-empty source for the drive, in the
+// configured order. It only comes back empty when every source is.
+func (r *Resolver) DisplayName(hostname, serial string) string {
+	key := hostname + ":" + serial
+	for _, src := range r.order {
+		var name string
+		switch src {
+		case SourceAlias:
+			name = r.aliases[key]
+		case SourceBay:
+			name = r.bays[key]
+		case SourceModel:
+			name = r.info(hostname, serial).model
+		case SourceSerial:
+			name = serial
+		case SourceDevice:
+			name = r.info(hostname, serial).device
+		}
+		if name = strings.TrimSpace(name); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+func (r *Resolver) info(hostname, serial string) driveInfo {
+	key := hostname + ":" + serial
+	if info, ok := r.drives[key]; ok || r.loaded[hostname] {
+		return info
+	}
+	r.loadHost(hostname)
+	return r.drives[key]
+}
+
+func (r *Resolver) loadAliases() {
+	rows, err := r.db.Query("SELECT hostname, serial_number, alias FROM drive_aliases")
+	if err != nil {
+		log.Printf("drivename: load aliases: %v", err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var hostname, serial, alias string
+		if rows.Scan(&hostname, &serial, &alias) == nil {
+			r.aliases[hostname+":"+serial] = alias
+		}
+	}
+}
+
+// loadBays names each enclosure member with a slot after its enclosure and
+// slot. A member without a slot has no bay to show.
+func (r *Resolver) loadBays() {
+	rows, err := r.db.Query(`
+		SELECT m.hostname, m.serial_number, e.name, m.slot
+		FROM enclosure_members m
+		JOIN enclosures e ON e.id = m.enclosure_id
+		WHERE m.slot != ''`)
+	if err != nil {
+		log.Printf("drivename: load enclosure slots: %v", err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var hostname, serial, enclosure, slot string
+		if rows.Scan(&hostname, &serial, &enclosure, &slot) == nil {
+			r.bays[hostname+":"+serial] = enclosure + " / " + slot
+		}
+	}
+}
+
+// loadHost reads models and device paths from the host's latest report.
+func (r *Resolver) loadHost(hostname string) {
+	r.loaded[hostname] = true
+
+	var data []byte
+	err := r.db.QueryRow(
+		"SELECT data FROM reports WHERE hostname = ? ORDER BY id DESC LIMIT 1", hostname,
+	).Scan(&data)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("drivename: load latest report for %s: %v", hostname, err)
+		}
+		return
+	}
+	if data, err = crypto.OpenReport(data); err != nil {
+		log.Printf("drivename: latest report for %s: %v", hostname, err)
+		return
+	}
+
+	var report struct {
+		Drives []map[string]interface{} `json:"drives"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		log.Printf("drivename: unmarshal report for %s: %v", hostname, err)
+		return
+	}
+	for _, d := range report.Drives {
+		serial, _ := d["serial_number"].(string)
+		if serial == "" {
+			continue
+		}
+		if _, ok := r.drives[hostname+":"+serial]; !ok {
+			r.Know(hostname, serial, agentsmart.ModelName(d), DevicePath(d))
+		}
+	}
+}
+
+// DevicePath returns a smartctl drive entry's device for labels: the
+// stable by-id name when the agent reports one, else the kernel name, both
+// without their /dev/ prefix.
+func DevicePath(d map[string]interface{}) string {
+	dev, ok := d["device"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if byID, _ := dev["by_id"].(string); byID != "" {
+		return strings.TrimPrefix(byID, "/dev/disk/by-id/")
+	}
+	name, _ := dev["name"].(string)
+	return strings.TrimPrefix(name, "/dev/")
+}
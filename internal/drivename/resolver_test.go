@@ -0,0 +1,124 @@
+package drivename
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"vigil/internal/settings"
+
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(`
+		CREATE TABLE drive_aliases (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			hostname TEXT NOT NULL,
+			serial_number TEXT NOT NULL,
+			alias TEXT NOT NULL,
+			UNIQUE(hostname, serial_number)
+		);
+		CREATE TABLE reports (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			hostname TEXT NOT NULL,
+			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+			data JSON NOT NULL
+		);
+		CREATE TABLE enclosures (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE
+		);
+		CREATE TABLE enclosure_members (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			enclosure_id INTEGER NOT NULL,
+			hostname TEXT NOT NULL,
+			serial_number TEXT NOT NULL,
+			slot TEXT NOT NULL DEFAULT ''
+		);`); err != nil {
+		t.Fatal(err)
+	}
+	if err := settings.InitSettingsTable(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestParseOrder(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"", []string{"alias", "bay", "model", "serial", "device"}},
+		{"bogus", []string{"alias", "bay", "model", "serial", "device"}},
+		{" Model , alias,model,nope", []string{"model", "alias"}},
+		{"serial", []string{"serial"}},
+	}
+	for _, tt := range tests {
+		if got := ParseOrder(tt.spec); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseOrder(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestDisplayNameOrder(t *testing.T) {
+	db := setupTestDB(t)
+
+	db.Exec(`INSERT INTO reports (hostname, data) VALUES ('nas01', ?)`, `{"drives": [
+		{"serial_number": "SN1", "model_name": "WDC WD40EFRX", "device": {"name": "/dev/sda", "by_id": "/dev/disk/by-id/ata-WDC_SN1"}},
+		{"serial_number": "SN2", "model_name": "ST8000VN004", "device": {"name": "/dev/sdb"}},
+		{"serial_number": "SN3", "device": {"name": "/dev/sdc"}}
+	]}`)
+	db.Exec(`INSERT INTO drive_aliases (hostname, serial_number, alias) VALUES ('nas01', 'SN1', 'Plex Media')`)
+	db.Exec(`INSERT INTO enclosures (id, name) VALUES (1, 'Shelf A')`)
+	db.Exec(`INSERT INTO enclosure_members (enclosure_id, hostname, serial_number, slot) VALUES (1, 'nas01', 'SN1', 'Bay 1'), (1, 'nas01', 'SN2', 'Bay 2')`)
+
+	names := NewResolver(db)
+	for serial, want := range map[string]string{
+		"SN1": "Plex Media",
+		"SN2": "Shelf A / Bay 2",
+		"SN3": "SN3",
+	} {
+		if got := names.DisplayName("nas01", serial); got != want {
+			t.Errorf("default order: %s = %q, want %q", serial, got, want)
+		}
+	}
+
+	if err := settings.UpdateSetting(db, "drives", "display_name_order", "device,model"); err != nil {
+		t.Fatal(err)
+	}
+	names = NewResolver(db)
+	for serial, want := range map[string]string{
+		"SN1": "ata-WDC_SN1",
+		"SN2": "sdb",
+		"SN3": "sdc",
+		"SN4": "",
+	} {
+		if got := names.DisplayName("nas01", serial); got != want {
+			t.Errorf("device,model: %s = %q, want %q", serial, got, want)
+		}
+	}
+}
+
+func TestDisplayNameKnow(t *testing.T) {
+	db := setupTestDB(t)
+	if err := settings.UpdateSetting(db, "drives", "display_name_order", "model,serial"); err != nil {
+		t.Fatal(err)
+	}
+
+	names := NewResolver(db)
+	names.Know("nas02", "SN9", "Samsung SSD 870", "sdd")
+	if got := names.DisplayName("nas02", "SN9"); got != "Samsung SSD 870" {
+		t.Errorf("known drive = %q", got)
+	}
+	// No report for the host: falls through to the serial
+	if got := names.DisplayName("nas02", "SN8"); got != "SN8" {
+		t.Errorf("unknown drive = %q", got)
+	}
+}
@@ -0,0 +1,44 @@
+package enclosures
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Migrate creates the enclosures tables if they don't exist.
+func Migrate(db *sql.DB) error {
+	stmts := []struct {
+		name string
+		sql  string
+	}{
+		{"enclosures", `
+			CREATE TABLE IF NOT EXISTS enclosures (
+				id            INTEGER PRIMARY KEY AUTOINCREMENT,
+				name          TEXT    NOT NULL UNIQUE,
+				location      TEXT    NOT NULL DEFAULT '',
+				hot_threshold INTEGER NOT NULL DEFAULT 0,
+				created_at    DATETIME DEFAULT CURRENT_TIMESTAMP
+			)`},
+		{"enclosure_members", `
+			CREATE TABLE IF NOT EXISTS enclosure_members (
+				id            INTEGER PRIMARY KEY AUTOINCREMENT,
+				enclosure_id  INTEGER NOT NULL,
+				hostname      TEXT    NOT NULL,
+				serial_number TEXT    NOT NULL,
+				slot          TEXT    NOT NULL DEFAULT '',
+				created_at    DATETIME DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE(hostname, serial_number),
+				FOREIGN KEY (enclosure_id) REFERENCES enclosures(id) ON DELETE CASCADE
+			)`},
+		{"enclosure_members indexes", `
+			CREATE INDEX IF NOT EXISTS idx_em_enclosure ON enclosure_members(enclosure_id);
+			CREATE INDEX IF NOT EXISTS idx_em_hostname  ON enclosure_members(hostname);`},
+	}
+
+	for _, s := range stmts {
+		if _, err := db.Exec(s.sql); err != nil {
+			return fmt.Errorf("enclosures migration %s: %w", s.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package enclosures
+
+import (
+	"database/sql"
+	"fmt"
+
+	vigildb "vigil/internal/db"
+)
+
+// ── Enclosure CRUD ──────────────────────────────────────────────────────
+
+// CreateEnclosure inserts a new enclosure and returns its ID.
+func CreateEnclosure(db *sql.DB, e *Enclosure) (int64, error) {
+	res, err := db.Exec(
+		`INSERT INTO enclosures (name, location, hot_threshold) VALUES (?, ?, ?)`,
+		e.Name, e.Location, e.HotThreshold,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("create enclosure: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// UpdateEnclosure updates an enclosure's name, location and hot threshold.
+func UpdateEnclosure(db *sql.DB, e *Enclosure) error {
+	_, err := db.Exec(
+		`UPDATE enclosures SET name = ?, location = ?, hot_threshold = ? WHERE id = ?`,
+		e.Name, e.Location, e.HotThreshold, e.ID,
+	)
+	return err
+}
+
+// DeleteEnclosure removes an enclosure. Members are cascade-deleted.
+func DeleteEnclosure(db *sql.DB, id int64) error {
+	_, err := db.Exec(`DELETE FROM enclosures WHERE id = ?`, id)
+	return err
+}
+
+// ListEnclosures returns all enclosures with member counts.
+func ListEnclosures(db *sql.DB) ([]Enclosure, error) {
+	rows, err := db.Query(`
+		SELECT e.id, e.name, e.location, e.hot_threshold, e.created_at, COUNT(m.id)
+		FROM enclosures e
+		LEFT JOIN enclosure_members m ON m.enclosure_id = e.id
+		GROUP BY e.id
+		ORDER BY e.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []Enclosure
+	for rows.Next() {
+		var e Enclosure
+		var ts string
+		if err := rows.Scan(&e.ID, &e.Name, &e.Location, &e.HotThreshold, &ts, &e.MemberCount); err != nil {
+			continue
+		}
+		e.CreatedAt = vigildb.ParseTime(ts)
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// GetEnclosure returns a single enclosure by ID, or nil if not found.
+func GetEnclosure(db *sql.DB, id int64) (*Enclosure, error) {
+	var e Enclosure
+	var ts string
+	err := db.QueryRow(`
+		SELECT e.id, e.name, e.location, e.hot_threshold, e.created_at,
+		       (SELECT COUNT(*) FROM enclosure_members WHERE enclosure_id = e.id)
+		FROM enclosures e WHERE e.id = ?`, id,
+	).Scan(&e.ID, &e.Name, &e.Location, &e.HotThreshold, &ts, &e.MemberCount)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.CreatedAt = vigildb.ParseTime(ts)
+	return &e, nil
+}
+
+// ── Member Management ───────────────────────────────────────────────────
+
+// AssignDrive places a drive in an enclosure slot. A drive sits in one
+// enclosure at a time, so assigning it again moves it.
+func AssignDrive(db *sql.DB, enclosureID int64, hostname, serial, slot string) error {
+	_, err := db.Exec(`
+		INSERT INTO enclosure_members (enclosure_id, hostname, serial_number, slot)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(hostname, serial_number) DO UPDATE SET
+			enclosure_id = excluded.enclosure_id, slot = excluded.slot`,
+		enclosureID, hostname, serial, slot,
+	)
+	return err
+}
+
+// UnassignDrive removes a drive from its enclosure.
+func UnassignDrive(db *sql.DB, hostname, serial string) error {
+	_, err := db.Exec(
+		`DELETE FROM enclosure_members WHERE hostname = ? AND serial_number = ?`,
+		hostname, serial,
+	)
+	return err
+}
+
+// ListMembers returns the drives in an enclosure, by slot.
+func ListMembers(db *sql.DB, enclosureID int64) ([]Member, error) {
+	rows, err := db.Query(`
+		SELECT id, enclosure_id, hostname, serial_number, slot
+		FROM enclosure_members WHERE enclosure_id = ?
+		ORDER BY slot, hostname, serial_number`,
+		enclosureID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []Member
+	for rows.Next() {
+		var m Member
+		if err := rows.Scan(&m.ID, &m.EnclosureID, &m.Hostname, &m.SerialNumber, &m.Slot); err != nil {
+			continue
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// enclosuresForHost returns the IDs of the enclosures holding any of the
+// host's drives.
+func enclosuresForHost(db *sql.DB, hostname string) ([]int64, error) {
+	rows, err := db.Query(
+		`SELECT DISTINCT enclosure_id FROM enclosure_members WHERE hostname = ? ORDER BY enclosure_id`,
+		hostname,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,130 @@
+package enclosures
+
+import (
+	"database/sql"
+	"testing"
+
+	"vigil/internal/temperature"
+
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Exec("PRAGMA foreign_keys = ON")
+
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestEnclosureCRUD(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	id, err := CreateEnclosure(db, &Enclosure{Name: "E1", Location: "Rack A", HotThreshold: 42})
+	if err != nil {
+		t.Fatalf("CreateEnclosure: %v", err)
+	}
+	if _, err := CreateEnclosure(db, &Enclosure{Name: "E1"}); err == nil {
+		t.Error("expected duplicate name to fail")
+	}
+
+	if err := UpdateEnclosure(db, &Enclosure{ID: id, Name: "Shelf 1", Location: "Rack B"}); err != nil {
+		t.Fatalf("UpdateEnclosure: %v", err)
+	}
+	e, err := GetEnclosure(db, id)
+	if err != nil || e == nil {
+		t.Fatalf("GetEnclosure: %v, %v", e, err)
+	}
+	if e.Name != "Shelf 1" || e.Location != "Rack B" || e.HotThreshold != 0 {
+		t.Errorf("enclosure = %+v", e)
+	}
+
+	if err := DeleteEnclosure(db, id); err != nil {
+		t.Fatalf("DeleteEnclosure: %v", err)
+	}
+	if e, _ := GetEnclosure(db, id); e != nil {
+		t.Error("expected enclosure to be gone")
+	}
+}
+
+func TestAssignDriveMovesBetweenEnclosures(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	e1, _ := CreateEnclosure(db, &Enclosure{Name: "E1"})
+	e2, _ := CreateEnclosure(db, &Enclosure{Name: "E2"})
+
+	AssignDrive(db, e1, "nas01", "SN1", "1")
+	AssignDrive(db, e1, "nas01", "SN2", "2")
+	if err := AssignDrive(db, e2, "nas01", "SN1", "7"); err != nil {
+		t.Fatalf("AssignDrive: %v", err)
+	}
+
+	m1, _ := ListMembers(db, e1)
+	m2, _ := ListMembers(db, e2)
+	if len(m1) != 1 || len(m2) != 1 || m2[0].SerialNumber != "SN1" || m2[0].Slot != "7" {
+		t.Errorf("members e1=%+v e2=%+v", m1, m2)
+	}
+
+	ids, _ := enclosuresForHost(db, "nas01")
+	if len(ids) != 2 {
+		t.Errorf("enclosuresForHost = %v, want both", ids)
+	}
+
+	// Deleting an enclosure drops its members
+	DeleteEnclosure(db, e2)
+	var n int
+	db.QueryRow(`SELECT COUNT(*) FROM enclosure_members`).Scan(&n)
+	if n != 1 {
+		t.Errorf("members after delete = %d, want 1", n)
+	}
+
+	UnassignDrive(db, "nas01", "SN2")
+	if m, _ := ListMembers(db, e1); len(m) != 0 {
+		t.Errorf("members after unassign = %+v", m)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	members := []Member{
+		{Hostname: "nas01", SerialNumber: "A", Slot: "1"},
+		{Hostname: "nas01", SerialNumber: "B", Slot: "2"},
+		{Hostname: "nas02", SerialNumber: "C", Slot: "3"},
+	}
+	temps := map[string]temperature.CurrentTemperature{
+		"nas01:A": {Temperature: 44, Status: "normal"},
+		"nas01:B": {Temperature: 47, Status: "warning"},
+	}
+	health := map[string]string{"nas01:A": "HEALTHY", "nas01:B": "WARNING"}
+
+	s := aggregate(Enclosure{Name: "E1"}, members, temps, health, 45)
+	if s.DriveCount != 3 || s.ReportingDrives != 2 {
+		t.Errorf("drives = %d/%d, want 3/2", s.DriveCount, s.ReportingDrives)
+	}
+	if s.AvgTemperature == nil || *s.AvgTemperature != 45.5 || *s.MinTemperature != 44 || *s.MaxTemperature != 47 {
+		t.Errorf("temperatures avg=%v min=%v max=%v", s.AvgTemperature, s.MinTemperature, s.MaxTemperature)
+	}
+	if !s.Hot {
+		t.Error("average 45.5 at threshold 45 should be hot")
+	}
+	if s.Health != "WARNING" || s.HealthCounts[HealthUnknown] != 1 {
+		t.Errorf("health = %s, counts %v", s.Health, s.HealthCounts)
+	}
+	if s.Drives[2].Temperature != nil || s.Drives[2].Health != HealthUnknown {
+		t.Errorf("unreported drive = %+v", s.Drives[2])
+	}
+
+	if s := aggregate(Enclosure{}, members, temps, health, 46); s.Hot {
+		t.Error("average 45.5 below threshold 46 should not be hot")
+	}
+	if s := aggregate(Enclosure{}, nil, nil, nil, 45); s.Hot || s.AvgTemperature != nil || s.Health != HealthUnknown {
+		t.Errorf("empty enclosure = %+v", s)
+	}
+}
@@ -0,0 +1,197 @@
+package enclosures
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"vigil/internal/drivename"
+	"vigil/internal/events"
+	"vigil/internal/settings"
+	"vigil/internal/smart"
+	"vigil/internal/temperature"
+)
+
+// HealthUnknown is the health of a member with no SMART data to analyse.
+const HealthUnknown = "UNKNOWN"
+
+// healthRank orders member health from best to worst.
+var healthRank = map[string]int{
+	"HEALTHY":     0,
+	HealthUnknown: 1,
+	"WARNING":     2,
+	"CRITICAL":    3,
+}
+
+// Summarize aggregates the latest temperature and SMART health of the
+// enclosure's drives.
+func Summarize(db *sql.DB, e *Enclosure) (*Summary, error) {
+	members, err := ListMembers(db, e.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list enclosure members: %w", err)
+	}
+
+	refs := make([]temperature.DriveRef, len(members))
+	for i, m := range members {
+		refs[i] = temperature.DriveRef{Hostname: m.Hostname, SerialNumber: m.SerialNumber}
+	}
+	current, err := temperature.GetCurrentTemperaturesFor(db, refs)
+	if err != nil {
+		return nil, err
+	}
+	temps := make(map[string]temperature.CurrentTemperature, len(current))
+	for _, ct := range current {
+		temps[ct.Hostname+":"+ct.SerialNumber] = ct
+	}
+
+	health := make(map[string]string, len(members))
+	for _, m := range members {
+		key := m.Hostname + ":" + m.SerialNumber
+		attrs, err := smart.GetLatestSmartAttributes(db, m.Hostname, m.SerialNumber)
+		if err != nil || len(attrs) == 0 {
+			health[key] = HealthUnknown
+			continue
+		}
+		analysis, err := smart.GetDriveHealthSummary(db, m.Hostname, m.SerialNumber)
+		if err != nil {
+			health[key] = HealthUnknown
+			continue
+		}
+		health[key] = strings.ToUpper(analysis.OverallHealth)
+	}
+
+	s := aggregate(*e, members, temps, health, hotThreshold(db, e))
+	names := drivename.NewResolver(db)
+	for i := range s.Drives {
+		s.Drives[i].DisplayName = names.DisplayName(s.Drives[i].Hostname, s.Drives[i].SerialNumber)
+	}
+	return s, nil
+}
+
+// SummarizeAll summarizes every enclosure, by name.
+func SummarizeAll(db *sql.DB) ([]Summary, error) {
+	list, err := ListEnclosures(db)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]Summary, 0, len(list))
+	for i := range list {
+		s, err := Summarize(db, &list[i])
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, *s)
+	}
+	return summaries, nil
+}
+
+// hotThreshold is the enclosure's own hot threshold, or the fleet-wide
+// temperature warning threshold when it has none.
+func hotThreshold(db *sql.DB, e *Enclosure) int {
+	if e.HotThreshold > 0 {
+		return e.HotThreshold
+	}
+	return settings.GetInt(db, "temperature", "warning_threshold", 45)
+}
+
+// aggregate builds a summary from the members' latest temperatures and
+// health, keyed by "hostname:serial". The enclosure is hot when the
+// average temperature of its reporting drives reaches threshold: one warm
+// drive is the drive's problem, a warm average is the enclosure's
+// (a failed fan, a blocked intake, a hot rack).
+func aggregate(e Enclosure, members []Member, temps map[string]temperature.CurrentTemperature, health map[string]string, threshold int) *Summary {
+	s := &Summary{
+		Enclosure:    e,
+		DriveCount:   len(members),
+		HotThreshold: threshold,
+		Health:       HealthUnknown,
+		HealthCounts: make(map[string]int),
+		Drives:       make([]DriveStatus, 0, len(members)),
+	}
+
+	total := 0
+	worst := -1
+	for _, m := range members {
+		key := m.Hostname + ":" + m.SerialNumber
+		d := DriveStatus{Member: m, Health: HealthUnknown}
+		if h, ok := health[key]; ok && h != "" {
+			d.Health = h
+		}
+		if ct, ok := temps[key]; ok {
+			t := ct.Temperature
+			d.Temperature = &t
+			d.TempStatus = ct.Status
+			d.Model = ct.Model
+
+			s.ReportingDrives++
+			total += t
+			if s.MinTemperature == nil || t < *s.MinTemperature {
+				s.MinTemperature = &t
+			}
+			if s.MaxTemperature == nil || t > *s.MaxTemperature {
+				s.MaxTemperature = &t
+			}
+		}
+
+		s.HealthCounts[d.Health]++
+		if rank, ok := healthRank[d.Health]; ok && rank > worst {
+			worst = rank
+			s.Health = d.Health
+		}
+		s.Drives = append(s.Drives, d)
+	}
+
+	if s.ReportingDrives > 0 {
+		avg := math.Round(float64(total)/float64(s.ReportingDrives)*10) / 10
+		s.AvgTemperature = &avg
+		s.Hot = threshold > 0 && avg >= float64(threshold)
+	}
+	return s
+}
+
+// ProcessReport re-evaluates the enclosures holding any of hostname's
+// drives after its report was stored, and publishes an EnclosureHot event
+// for each one that is hot. Repeats are left to the notification cooldown.
+func ProcessReport(db *sql.DB, bus *events.Bus, hostname string) {
+	if bus == nil {
+		return
+	}
+	ids, err := enclosuresForHost(db, hostname)
+	if err != nil {
+		log.Printf("⚠️  Enclosures for %s: %v", hostname, err)
+		return
+	}
+	for _, id := range ids {
+		e, err := GetEnclosure(db, id)
+		if err != nil || e == nil {
+			continue
+		}
+		s, err := Summarize(db, e)
+		if err != nil {
+			log.Printf("⚠️  Enclosure %q summary: %v", e.Name, err)
+			continue
+		}
+		if s.Hot {
+			publishHot(bus, hostname, s)
+		}
+	}
+}
+
+func publishHot(bus *events.Bus, hostname string, s *Summary) {
+	bus.Publish(events.Event{
+		Type:     events.EnclosureHot,
+		Severity: events.SeverityWarning,
+		Hostname: hostname,
+		Message: fmt.Sprintf("Enclosure %q is running hot: its %d drives average %.1f°C (threshold %d°C, hottest %d°C)",
+			s.Enclosure.Name, s.ReportingDrives, *s.AvgTemperature, s.HotThreshold, *s.MaxTemperature),
+		Metadata: map[string]string{
+			"enclosure_id":    fmt.Sprintf("%d", s.Enclosure.ID),
+			"enclosure":       s.Enclosure.Name,
+			"avg_temperature": fmt.Sprintf("%.1f", *s.AvgTemperature),
+			"max_temperature": fmt.Sprintf("%d", *s.MaxTemperature),
+			"threshold":       fmt.Sprintf("%d", s.HotThreshold),
+		},
+	})
+}
@@ -0,0 +1,52 @@
+package enclosures
+
+import "time"
+
+// Enclosure is a physical drive enclosure (JBOD shelf, chassis, ...) that
+// drives from one or more hosts sit in.
+type Enclosure struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	// HotThreshold is the average drive temperature (°C) at which the
+	// enclosure counts as hot; 0 uses temperature.warning_threshold.
+	HotThreshold int       `json:"hot_threshold"`
+	MemberCount  int       `json:"member_count,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Member places a drive (hostname + serial) in an enclosure, optionally in
+// a named slot.
+type Member struct {
+	ID           int64  `json:"id"`
+	EnclosureID  int64  `json:"enclosure_id"`
+	Hostname     string `json:"hostname"`
+	SerialNumber string `json:"serial_number"`
+	Slot         string `json:"slot"`
+}
+
+// DriveStatus is one member's latest temperature and health.
+type DriveStatus struct {
+	Member
+	DisplayName string `json:"display_name,omitempty"`
+	Model       string `json:"model,omitempty"`
+	Temperature *int   `json:"temperature"`
+	TempStatus  string `json:"temp_status,omitempty"` // "normal", "warning", "critical"
+	Health      string `json:"health"`                // "HEALTHY", "WARNING", "CRITICAL" or "UNKNOWN"
+}
+
+// Summary aggregates the latest temperature and health of an enclosure's
+// drives. Temperature figures only count drives that have reported one.
+type Summary struct {
+	Enclosure       Enclosure      `json:"enclosure"`
+	DriveCount      int            `json:"drive_count"`
+	ReportingDrives int            `json:"reporting_drives"`
+	AvgTemperature  *float64       `json:"avg_temperature"`
+	MinTemperature  *int           `json:"min_temperature"`
+	MaxTemperature  *int           `json:"max_temperature"`
+	HotThreshold    int            `json:"hot_threshold"`
+	Hot             bool           `json:"hot"`
+	Health          string         `json:"health"` // worst member health
+	HealthCounts    map[string]int `json:"health_counts"`
+	Drives          []DriveStatus  `json:"drives"`
+}
@@ -43,13 +43,17 @@ func (b *Bus) Subscribe(handler Handler, types ...EventType) {
 }
 
 // Publish sends an event to all matching subscribers.
-// The timestamp is set automatically if zero.
+// The timestamp, and the cause from the event type, are set automatically
+// if zero.
 // Handlers are called synchronously in the caller's goroutine;
 // the dispatcher (Task 1.2) is responsible for its own concurrency.
 func (b *Bus) Publish(e Event) {
 	if e.Timestamp.IsZero() {
 		e.Timestamp = time.Now().UTC()
 	}
+	if e.Cause == "" {
+		e.Cause = CauseOf(e.Type)
+	}
 
 	b.mu.RLock()
 	subs := make([]subscription, len(b.subscribers))
@@ -88,6 +88,26 @@ func TestPublishPreservesExplicitTimestamp(t *testing.T) {
 	}
 }
 
+func TestPublishSetsCause(t *testing.T) {
+	bus := NewBus()
+	var got []Cause
+
+	bus.Subscribe(func(e Event) {
+		got = append(got, e.Cause)
+	})
+
+	bus.Publish(Event{Type: TempAlert})
+	bus.Publish(Event{Type: SmartWarning, Cause: CauseInterface})
+	bus.Publish(Event{Type: ZFSPoolDegraded})
+
+	want := []Cause{CauseThermal, CauseInterface, CausePool}
+	for i, c := range want {
+		if got[i] != c {
+			t.Errorf("event %d: cause = %q, want %q", i, got[i], c)
+		}
+	}
+}
+
 func TestConcurrentPublishSubscribe(t *testing.T) {
 	bus := NewBus()
 	var count atomic.Int32
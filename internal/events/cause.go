@@ -0,0 +1,77 @@
+package events
+
+// Cause classifies what is wrong behind an event (overheating, failing
+// media, a bad cable, ...) independently of which check raised it, so
+// alerts from different sources can be triaged together. It is exposed as
+// "category"; Category already names the notification UI groups.
+type Cause string
+
+const (
+	CauseThermal   Cause = "thermal"   // drive temperature
+	CauseMedia     Cause = "media"     // sectors, read/write errors, SMART failure
+	CauseInterface Cause = "interface" // link to the host: cabling, controller, power, drive presence
+	CauseEndurance Cause = "endurance" // wear and rated lifetime
+	CausePool      Cause = "pool"      // ZFS pool state, capacity and layout
+	CauseAgent     Cause = "agent"     // collectors and add-ons going offline or joining
+)
+
+// AllCauses lists every cause, for validating filters.
+var AllCauses = []Cause{CauseThermal, CauseMedia, CauseInterface, CauseEndurance, CausePool, CauseAgent}
+
+// ValidCause reports whether c is one of AllCauses.
+func ValidCause(c string) bool {
+	for _, known := range AllCauses {
+		if string(known) == c {
+			return true
+		}
+	}
+	return false
+}
+
+// typeCauses is the default cause of each event type; publishers that know
+// better (e.g. which SMART attribute tripped) set Event.Cause themselves.
+// Job and add-on workflow events have none.
+var typeCauses = map[EventType]Cause{
+	TempAlert:               CauseThermal,
+	TempCritical:            CauseThermal,
+	EnclosureHot:            CauseThermal,
+	SmartWarning:            CauseMedia,
+	SmartCritical:           CauseMedia,
+	DriveRecovered:          CauseMedia,
+	ReallocatedSectors:      CauseMedia,
+	DriveAppeared:           CauseInterface,
+	DriveDisappeared:        CauseInterface,
+	DriveRelocated:          CauseInterface,
+	UnsafeShutdowns:         CauseInterface,
+	PowerCycleSpike:         CauseInterface,
+	WearoutWarning:          CauseEndurance,
+	WearoutCritical:         CauseEndurance,
+	WearoutPredicted:        CauseEndurance,
+	ZFSPoolDegraded:         CausePool,
+	ZFSPoolFaulted:          CausePool,
+	ZFSPoolRecovered:        CausePool,
+	ZFSDeviceFailed:         CausePool,
+	ZFSCapacityWarning:      CausePool,
+	ZFSCapacityCritical:     CausePool,
+	ZFSFragmentationWarning: CausePool,
+	ZFSVdevErrors:           CausePool,
+	ZFSScrubOverdue:         CausePool,
+	ZFSResilverStarted:      CausePool,
+	ZFSScrubCompleted:       CausePool,
+	ZFSResilverCompleted:    CausePool,
+	ZFSDatasetQuotaWarning:  CausePool,
+	ZFSPropertyWarning:      CausePool,
+	ZFSNoRedundancy:         CausePool,
+	ZFSFillPredicted:        CausePool,
+	ZFSSpareActivated:       CausePool,
+	SnapraidAgentOffline:    CauseAgent,
+	SnapraidAgentOnline:     CauseAgent,
+	AddonDegraded:           CauseAgent,
+	AddonOnline:             CauseAgent,
+	AgentRegistered:         CauseAgent,
+}
+
+// CauseOf returns the event type's default cause, or "" if it has none.
+func CauseOf(t EventType) Cause {
+	return typeCauses[t]
+}
@@ -23,9 +23,19 @@ const (
 	ZFSScrubCompleted          EventType = "zfs_scrub_completed"
 	ZFSResilverCompleted       EventType = "zfs_resilver_completed"
 	ZFSDatasetQuotaWarning     EventType = "zfs_dataset_quota_warning"
+	ZFSPropertyWarning         EventType = "zfs_property_warning"
+	ZFSNoRedundancy            EventType = "zfs_no_redundancy"
+	ZFSFillPredicted           EventType = "zfs_fill_predicted"
+	ZFSPoolRecovered           EventType = "zfs_pool_recovered"
+	ZFSSpareActivated          EventType = "zfs_spare_activated"
 	DriveAppeared      EventType = "drive_appeared"
 	DriveDisappeared   EventType = "drive_disappeared"
+	DriveRelocated     EventType = "drive_relocated"
+	DriveRecovered     EventType = "drive_recovered"
+	EnclosureHot       EventType = "enclosure_hot"
 	ReallocatedSectors EventType = "reallocated_sectors"
+	UnsafeShutdowns    EventType = "unsafe_shutdowns"
+	PowerCycleSpike    EventType = "power_cycle_spike"
 	WearoutWarning     EventType = "wearout_warning"
 	WearoutCritical    EventType = "wearout_critical"
 	WearoutPredicted   EventType = "wearout_predicted"
@@ -65,6 +75,7 @@ const (
 	// System events
 	AddonDegraded EventType = "addon_degraded"
 	AddonOnline   EventType = "addon_online"
+	AgentRegistered EventType = "agent_registered"
 )
 
 // Category groups related event types for the notification settings UI.
@@ -97,7 +108,9 @@ var AllEventTypes = []EventType{
 	ZFSCapacityWarning, ZFSCapacityCritical, ZFSFragmentationWarning,
 	ZFSVdevErrors, ZFSScrubOverdue,
 	ZFSResilverStarted, ZFSScrubCompleted, ZFSResilverCompleted, ZFSDatasetQuotaWarning,
-	DriveAppeared, DriveDisappeared, ReallocatedSectors,
+	ZFSPropertyWarning, ZFSNoRedundancy, ZFSFillPredicted, ZFSPoolRecovered, ZFSSpareActivated,
+	DriveAppeared, DriveDisappeared, DriveRelocated, DriveRecovered, EnclosureHot, ReallocatedSectors,
+	UnsafeShutdowns, PowerCycleSpike,
 	WearoutWarning, WearoutCritical, WearoutPredicted,
 	// Add-on / job
 	JobStarted, PhaseComplete, BurninPassed, JobComplete, JobFailed,
@@ -109,7 +122,7 @@ var AllEventTypes = []EventType{
 	ZMSnapshotTaskSucceeded, ZMScrubTaskStarted, ZMReplicationSucceeded, ZMRetentionCleanupDone,
 	ZMScrubCompleted, ZMResilverCompleted, ZMPoolExpansionCompleted, ZMDriveReplacementStarted,
 	// System
-	AddonDegraded, AddonOnline,
+	AddonDegraded, AddonOnline, AgentRegistered,
 }
 
 // AllEventTypeMeta provides enriched metadata for every known event type.
@@ -131,9 +144,19 @@ var AllEventTypeMeta = []EventTypeMeta{
 	{ZFSScrubCompleted, CategoryMonitoring, "ZFS Scrub Completed", SeverityInfo, 0, true},
 	{ZFSResilverCompleted, CategoryMonitoring, "ZFS Resilver Completed", SeverityInfo, 0, true},
 	{ZFSDatasetQuotaWarning, CategoryMonitoring, "ZFS Dataset Quota Warning", SeverityWarning, 3600, true},
+	{ZFSPropertyWarning, CategoryMonitoring, "ZFS Pool Property Warning", SeverityWarning, 86400, true},
+	{ZFSNoRedundancy, CategoryMonitoring, "ZFS Pool Without Redundancy", SeverityInfo, 0, true},
+	{ZFSFillPredicted, CategoryMonitoring, "ZFS Pool Filling Up", SeverityWarning, 86400, true},
+	{ZFSPoolRecovered, CategoryMonitoring, "ZFS Pool Recovered", SeverityInfo, 0, true},
+	{ZFSSpareActivated, CategoryMonitoring, "ZFS Hot Spare Activated", SeverityWarning, 0, true},
 	{DriveAppeared, CategoryMonitoring, "Drive Appeared", SeverityInfo, 0, true},
 	{DriveDisappeared, CategoryMonitoring, "Drive Disappeared", SeverityWarning, 0, true},
+	{DriveRelocated, CategoryMonitoring, "Drive Relocated", SeverityInfo, 0, true},
+	{DriveRecovered, CategoryMonitoring, "Drive Recovered", SeverityInfo, 0, true},
+	{EnclosureHot, CategoryMonitoring, "Enclosure Hot", SeverityWarning, 3600, true},
 	{ReallocatedSectors, CategoryMonitoring, "Reallocated Sectors", SeverityWarning, 86400, true},
+	{UnsafeShutdowns, CategoryMonitoring, "Unsafe Shutdowns", SeverityInfo, 3600, true},
+	{PowerCycleSpike, CategoryMonitoring, "Power Cycle Spike", SeverityWarning, 3600, true},
 	{WearoutWarning, CategoryMonitoring, "Wearout Warning", SeverityWarning, 86400, true},
 	{WearoutCritical, CategoryMonitoring, "Wearout Critical", SeverityCritical, 86400, true},
 	{WearoutPredicted, CategoryMonitoring, "Failure Predicted", SeverityWarning, 604800, true},
@@ -166,6 +189,7 @@ var AllEventTypeMeta = []EventTypeMeta{
 	// System
 	{AddonDegraded, CategorySystem, "Add-on Degraded", SeverityWarning, 300, true},
 	{AddonOnline, CategorySystem, "Add-on Online", SeverityInfo, 0, true},
+	{AgentRegistered, CategorySystem, "Agent Registered", SeverityWarning, 0, true},
 }
 
 // Severity indicates the urgency of an event.
@@ -197,6 +221,7 @@ type Event struct {
 	Hostname     string            `json:"hostname,omitempty"`
 	SerialNumber string            `json:"serial_number,omitempty"`
 	Message      string            `json:"message"`
+	Cause        Cause             `json:"category,omitempty"` // defaults to CauseOf(Type)
 	Metadata     map[string]string `json:"metadata,omitempty"`
 	Timestamp    time.Time         `json:"timestamp"`
 }
@@ -10,13 +10,16 @@ import (
 	"net/url"
 	"os"
 	stdpath "path"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"vigil/internal/addons"
 	"vigil/internal/audit"
 	"vigil/internal/auth"
 	"vigil/internal/db"
+	"vigil/internal/settings"
 	"vigil/internal/validate"
 )
 
@@ -26,15 +29,65 @@ var TelemetryBroker *addons.TelemetryBroker
 // WebSocketHub is set from main.go during startup.
 var WebSocketHub *addons.WebSocketHub
 
-// addonClient is a shared HTTP client for outbound requests to add-ons.
-// Long timeout because some add-on actions (ZFS `zpool create`, `zpool scrub`,
-// long running SMART long-selftests, etc.) legitimately take minutes — a short
-// timeout would cancel the context mid-operation and SIGKILL the agent's child
-// process. The per-request context still cuts off if the client disconnects.
-var addonClient = &http.Client{Timeout: 5 * time.Minute}
+// addonClient returns the HTTP client for outbound requests to add-ons, with
+// the addons.proxy_timeout_seconds timeout. It defaults to minutes because
+// some add-on actions (ZFS `zpool create`, `zpool scrub`, long running SMART
+// long-selftests, etc.) legitimately take that long — a short timeout would
+// cancel the context mid-operation and SIGKILL the agent's child process.
+// The per-request context still cuts off if the client disconnects.
+func addonClient() *http.Client {
+	return &http.Client{Timeout: settingSeconds("proxy_timeout_seconds", 300)}
+}
+
+// registryClient returns the HTTP client for container registry lookups,
+// with the addons.registry_timeout_seconds timeout.
+func registryClient() *http.Client {
+	return &http.Client{Timeout: settingSeconds("registry_timeout_seconds", 10)}
+}
 
-// registryClient is a shared HTTP client for container registry lookups.
-var registryClient = &http.Client{Timeout: 10 * time.Second}
+func settingSeconds(key string, fallback int) time.Duration {
+	secs := settings.GetInt(db.DB, "addons", key, fallback)
+	if secs <= 0 {
+		secs = fallback
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// retryBackoff is the wait before the one retry doWithRetry makes.
+var retryBackoff = 2 * time.Second
+
+// doWithRetry sends req and, when it fails transiently, retries once after
+// retryBackoff. Transient means a network error or a 502/503/504; any other
+// status, 4xx included, won't improve on a second try and is returned as
+// is. Only GET and HEAD are retried: re-sending an add-on action could run
+// it twice.
+func doWithRetry(client *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return resp, err
+	}
+	if err == nil {
+		switch resp.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		default:
+			return resp, nil
+		}
+	}
+	// The caller went away: nobody is waiting for a retry.
+	if req.Context().Err() != nil {
+		return resp, err
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+
+	select {
+	case <-time.After(retryBackoff):
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	return client.Do(req)
+}
 
 // ─── Add-on CRUD ─────────────────────────────────────────────────────────
 
@@ -167,6 +220,7 @@ func DeregisterAddon(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("📦 Add-on deregistered: %s (id=%d, by=%s)", addon.Name, id, session.Username)
+	recordAudit(r, "addon_deregister", "addon", fmt.Sprintf("%d", id), addon.Name)
 	JSONResponse(w, map[string]string{"status": "deregistered"})
 }
 
@@ -292,9 +346,7 @@ func CreateAddonFromUI(w http.ResponseWriter, r *http.Request) {
 
 	addon, _ := addons.Get(db.DB, addonID)
 	log.Printf("📦 Add-on registered from UI: %s (id=%d, token=%.16s…)", req.Name, addonID, req.Token)
-	if s := auth.GetSessionFromContext(r); s != nil {
-		audit.LogEvent(db.DB, r, s.UserID, s.Username, "addon_create", "addon", fmt.Sprintf("%d", addonID), req.Name, "success")
-	}
+	recordAudit(r, "addon_create", "addon", fmt.Sprintf("%d", addonID), req.Name)
 
 	w.WriteHeader(http.StatusCreated)
 	JSONResponse(w, map[string]interface{}{
@@ -427,9 +479,7 @@ func CreateAddonToken(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("🔑 Add-on registration token created: %.16s… expires=%v (name=%q)", tok.Token, tok.ExpiresAt, tok.Name)
-	if s := auth.GetSessionFromContext(r); s != nil {
-		audit.LogEvent(db.DB, r, s.UserID, s.Username, "addon_token_create", "addon_token", "", tok.Name, "success")
-	}
+	recordAudit(r, "addon_token_create", "addon_token", "", tok.Name)
 	w.WriteHeader(http.StatusCreated)
 	JSONResponse(w, tok)
 }
@@ -473,9 +523,7 @@ func DeleteAddonToken(w http.ResponseWriter, r *http.Request) {
 		JSONError(w, "Failed to delete token", http.StatusInternalServerError)
 		return
 	}
-	if s := auth.GetSessionFromContext(r); s != nil {
-		audit.LogEvent(db.DB, r, s.UserID, s.Username, "addon_token_delete", "addon_token", fmt.Sprintf("%d", id), "", "success")
-	}
+	recordAudit(r, "addon_token_delete", "addon_token", fmt.Sprintf("%d", id), "")
 	JSONResponse(w, map[string]string{"status": "deleted"})
 }
 
@@ -502,9 +550,7 @@ func RotateAddonToken(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("🔑 Add-on token rotated: %s (id=%d, token=%.16s…)", addon.Name, id, tok.Token)
-	if s := auth.GetSessionFromContext(r); s != nil {
-		audit.LogEvent(db.DB, r, s.UserID, s.Username, "addon_token_rotate", "addon", fmt.Sprintf("%d", id), addon.Name, "success")
-	}
+	recordAudit(r, "addon_token_rotate", "addon", fmt.Sprintf("%d", id), addon.Name)
 	JSONResponse(w, map[string]string{"token": tok.Token})
 }
 
@@ -536,31 +582,65 @@ func AddonTelemetrySSE(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	perAddon := settings.GetInt(db.DB, "addons", "sse_max_per_addon", 8)
+	total := settings.GetInt(db.DB, "addons", "sse_max_subscribers", 64)
+	keepAlive := telemetryKeepAlive()
+	ch, err := TelemetryBroker.SubscribeLimited(id, perAddon, total)
+	if err != nil {
+		log.Printf("🚫 Telemetry stream for add-on %d rejected: %v", id, err)
+		w.Header().Set("Retry-After", strconv.Itoa(int(keepAlive.Seconds())))
+		JSONError(w, "Too many telemetry streams open", http.StatusTooManyRequests)
+		return
+	}
+	defer TelemetryBroker.Unsubscribe(id, ch)
+
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("X-Accel-Buffering", "no") // Nginx buffering bypass
 
-	ch := TelemetryBroker.Subscribe(id)
-	defer TelemetryBroker.Unsubscribe(id, ch)
+	// The server's WriteTimeout would end the stream; each frame instead
+	// gets its own deadline, so only a client that stops reading is cut off.
+	rc := http.NewResponseController(w)
+	send := func(format string, args ...interface{}) bool {
+		rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout)) //nolint:errcheck
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return false
+		}
+		flusher.Flush()
+		TelemetryBroker.Touch(id, ch)
+		return true
+	}
 
 	// Send initial connection event
-	fmt.Fprintf(w, "event: connected\ndata: {\"addon_id\":%d}\n\n", id)
-	flusher.Flush()
+	if !send("event: connected\ndata: {\"addon_id\":%d}\n\n", id) {
+		return
+	}
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
 
 	ctx := r.Context()
 	for {
 		select {
 		case evt, ok := <-ch:
 			if !ok {
-				return
+				return // unsubscribed by the reaper
 			}
 			data, err := json.Marshal(evt)
 			if err != nil {
 				continue
 			}
-			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.Type, data)
-			flusher.Flush()
+			if !send("event: %s\ndata: %s\n\n", evt.Type, data) {
+				return
+			}
+
+		case <-ticker.C:
+			// Comment frame: ignored by EventSource, but keeps proxies
+			// from closing an idle stream and proves the client still reads.
+			if !send(": keep-alive\n\n") {
+				return
+			}
 
 		case <-ctx.Done():
 			return
@@ -568,6 +648,34 @@ func AddonTelemetrySSE(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// sseWriteTimeout bounds each write to a telemetry stream.
+const sseWriteTimeout = 10 * time.Second
+
+// telemetryKeepAlive returns the addons.sse_keepalive_seconds interval.
+func telemetryKeepAlive() time.Duration {
+	secs := settings.GetInt(db.DB, "addons", "sse_keepalive_seconds", 15)
+	if secs < 1 {
+		secs = 1
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// RunTelemetryReaper drops telemetry subscribers whose stream hasn't taken
+// a frame for three keep-alive intervals, e.g. a connection that died
+// without the server noticing. It never returns.
+func RunTelemetryReaper() {
+	for {
+		keepAlive := telemetryKeepAlive()
+		time.Sleep(keepAlive)
+		if TelemetryBroker == nil {
+			continue
+		}
+		if n := TelemetryBroker.Reap(3 * keepAlive); n > 0 {
+			log.Printf("🧹 Dropped %d stale telemetry stream(s)", n)
+		}
+	}
+}
+
 // ─── Addon Proxy ─────────────────────────────────────────────────────────
 
 // ProxyAddonRequest proxies a request to the add-on's own API.
@@ -660,7 +768,7 @@ func ExecuteAddonAction(w http.ResponseWriter, r *http.Request) {
 	}
 	proxyReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := addonClient.Do(proxyReq) // #nosec G107 G704 -- URL validated via buildAddonURL (scheme whitelist + ParseRequestURI)
+	resp, err := addonClient().Do(proxyReq) // #nosec G107 G704 -- URL validated via buildAddonURL (scheme whitelist + ParseRequestURI)
 	if err != nil {
 		log.Printf("❌ Action proxy to addon %d: %v", id, err)
 		JSONError(w, "Failed to reach add-on", http.StatusBadGateway)
@@ -765,7 +873,7 @@ func ProxyAddonRequest(w http.ResponseWriter, r *http.Request) {
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
-	resp, err := addonClient.Do(req) // #nosec G107 G704 -- URL validated: scheme whitelisted, host from admin-registered addon, path restricted to /api/*
+	resp, err := doWithRetry(addonClient(), req) // #nosec G107 G704 -- URL validated: scheme whitelisted, host from admin-registered addon, path restricted to /api/*
 	if err != nil {
 		log.Printf("❌ Proxy request to addon %d: %v", id, err)
 		JSONError(w, "Failed to reach add-on", http.StatusBadGateway)
@@ -900,9 +1008,48 @@ func parseImageAndTag(ref string) (image, tag string) {
 	return ref[:idx], after
 }
 
-// queryRegistryTags fetches available tags from a container registry.
-// Supports ghcr.io and Docker Hub via the OCI distribution API.
+// registryTagCache holds recent registry answers per image, so repeated
+// update checks (every add-on card on page load) don't get rate-limited.
+var registryTagCache = struct {
+	sync.Mutex
+	entries map[string]registryTags
+}{entries: make(map[string]registryTags)}
+
+type registryTags struct {
+	tags    []string
+	fetched time.Time
+}
+
+// queryRegistryTags returns the image's tags, from registryTagCache when
+// they were fetched less than addons.registry_cache_minutes ago. Failures
+// aren't cached.
 func queryRegistryTags(ctx context.Context, image string) ([]string, error) {
+	ttl := time.Duration(settings.GetInt(db.DB, "addons", "registry_cache_minutes", 5)) * time.Minute
+
+	registryTagCache.Lock()
+	cached, ok := registryTagCache.entries[image]
+	registryTagCache.Unlock()
+	if ok && time.Since(cached.fetched) < ttl {
+		return cached.tags, nil
+	}
+
+	tags, err := fetchRegistryTags(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	if ttl > 0 {
+		registryTagCache.Lock()
+		registryTagCache.entries[image] = registryTags{tags: tags, fetched: time.Now()}
+		registryTagCache.Unlock()
+	}
+	return tags, nil
+}
+
+// fetchRegistryTags fetches available tags from a container registry.
+// Supports ghcr.io and Docker Hub via the OCI distribution API.
+func fetchRegistryTags(ctx context.Context, image string) ([]string, error) {
+	client := registryClient()
+
 	// Parse image into registry + repository
 	registry, repo := parseImageRef(image)
 
@@ -935,7 +1082,7 @@ func queryRegistryTags(ctx context.Context, image string) ([]string, error) {
 			// Obtain an anonymous pull token from ghcr.io
 			tokenURL := fmt.Sprintf("https://ghcr.io/token?service=ghcr.io&scope=repository:%s:pull", repo)
 			tokenReq, _ := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL, nil)
-			tokenResp, err := registryClient.Do(tokenReq)
+			tokenResp, err := doWithRetry(client, tokenReq)
 			if err == nil {
 				defer tokenResp.Body.Close()
 				var tokenData struct {
@@ -953,7 +1100,7 @@ func queryRegistryTags(ctx context.Context, image string) ([]string, error) {
 		// Get anonymous token for Docker Hub
 		tokenURL := fmt.Sprintf("https://auth.docker.io/token?service=registry.docker.io&scope=repository:%s:pull", repo)
 		tokenReq, _ := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL, nil)
-		tokenResp, err := registryClient.Do(tokenReq)
+		tokenResp, err := doWithRetry(client, tokenReq)
 		if err == nil {
 			defer tokenResp.Body.Close()
 			var tokenData struct {
@@ -965,7 +1112,7 @@ func queryRegistryTags(ctx context.Context, image string) ([]string, error) {
 		}
 	}
 
-	resp, err := registryClient.Do(req)
+	resp, err := doWithRetry(client, req)
 	if err != nil {
 		return nil, fmt.Errorf("fetching tags: %w", err)
 	}
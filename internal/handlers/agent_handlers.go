@@ -15,10 +15,11 @@ import (
 	"time"
 
 	"vigil/internal/agents"
-	"vigil/internal/audit"
-	"vigil/internal/auth"
 	"vigil/internal/crypto"
 	"vigil/internal/db"
+	"vigil/internal/events"
+	"vigil/internal/middleware"
+	"vigil/internal/settings"
 	"vigil/internal/validate"
 )
 
@@ -144,6 +145,17 @@ func RegisterAgent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Trust on first use: hold the agent's reports until an admin approves it
+	if settings.GetBool(db.DB, "agents", "require_approval", false) {
+		if err := agents.SetAgentApproval(db.DB, agent.ID, agents.ApprovalPending); err != nil {
+			log.Printf("❌ Failed to mark agent %d pending: %v", agent.ID, err)
+			agents.DeleteAgent(db.DB, agent.ID)
+			JSONError(w, "Failed to register agent", http.StatusInternalServerError)
+			return
+		}
+		agent.Approval = agents.ApprovalPending
+	}
+
 	// Consume the token
 	if err := agents.ConsumeRegistrationToken(db.DB, req.Token, agent.ID); err != nil {
 		log.Printf("⚠️  Could not mark registration token used: %v", err)
@@ -163,7 +175,9 @@ func RegisterAgent(w http.ResponseWriter, r *http.Request) {
 		serverPubKey = ServerKeys.PublicKeyBase64()
 	}
 
-	log.Printf("✅ Agent registered: %s (id=%d, fingerprint=%.16s...)", agent.Hostname, agent.ID, agent.Fingerprint)
+	sourceIP := middleware.ExtractIP(r)
+	log.Printf("✅ Agent registered: %s (id=%d, fingerprint=%.16s..., ip=%s, %s)", agent.Hostname, agent.ID, agent.Fingerprint, sourceIP, agent.Approval)
+	publishAgentRegistered(agent, sourceIP)
 
 	JSONResponse(w, map[string]interface{}{
 		"agent_id":         agent.ID,
@@ -171,6 +185,32 @@ func RegisterAgent(w http.ResponseWriter, r *http.Request) {
 		"session_token":    session.Token,
 		"session_expires":  session.ExpiresAt.UTC().Format(time.RFC3339),
 		"server_public_key": serverPubKey,
+		"approval":         agent.Approval,
+	})
+}
+
+// publishAgentRegistered announces a newly enrolled agent, unless
+// agents.notify_registration is off, so an unexpected machine joining
+// doesn't go unnoticed. Reconnects of known agents aren't announced.
+func publishAgentRegistered(agent *agents.Agent, sourceIP string) {
+	if EventBus == nil || !settings.GetBool(db.DB, "agents", "notify_registration", true) {
+		return
+	}
+	msg := fmt.Sprintf("🆕 New agent registered: %s from %s (fingerprint %.16s...)", agent.Hostname, sourceIP, agent.Fingerprint)
+	if agent.Approval == agents.ApprovalPending {
+		msg += ", awaiting approval"
+	}
+	EventBus.Publish(events.Event{
+		Type:     events.AgentRegistered,
+		Severity: events.SeverityWarning,
+		Hostname: agent.Hostname,
+		Message:  msg,
+		Metadata: map[string]string{
+			"agent_id":    strconv.FormatInt(agent.ID, 10),
+			"fingerprint": agent.Fingerprint,
+			"source_ip":   sourceIP,
+			"approval":    agent.Approval,
+		},
 	})
 }
 
@@ -316,15 +356,59 @@ func DeleteRegisteredAgent(w http.ResponseWriter, r *http.Request) {
 	deleted := agents.DeleteHostData(db.DB, hostname)
 
 	log.Printf("🗑️  Deleted agent id=%d (%s) — cascade: %v", id, hostname, deleted)
-	if s := auth.GetSessionFromContext(r); s != nil {
-		audit.LogEvent(db.DB, r, s.UserID, s.Username, "agent_delete", "agent", idStr, hostname, "success")
-	}
+	recordAudit(r, "agent_delete", "agent", idStr, hostname)
 	JSONResponse(w, map[string]interface{}{
 		"status":  "deleted",
 		"cascade": deleted,
 	})
 }
 
+// ApproveAgent lets a pending (or previously rejected) agent's reports in.
+// POST /api/v1/agents/{id}/approve
+func ApproveAgent(w http.ResponseWriter, r *http.Request) {
+	setAgentApproval(w, r, agents.ApprovalApproved)
+}
+
+// RejectAgent disables an agent and ends its sessions. Its record is kept,
+// so the machine can't register again with the same fingerprint until the
+// agent is deleted.
+// POST /api/v1/agents/{id}/reject
+func RejectAgent(w http.ResponseWriter, r *http.Request) {
+	setAgentApproval(w, r, agents.ApprovalRejected)
+}
+
+func setAgentApproval(w http.ResponseWriter, r *http.Request, approval string) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		JSONError(w, "Invalid agent ID", http.StatusBadRequest)
+		return
+	}
+	agent, err := agents.GetAgentByID(db.DB, id)
+	if err != nil || agent == nil {
+		JSONError(w, "Agent not found", http.StatusNotFound)
+		return
+	}
+
+	if err := agents.SetAgentApproval(db.DB, id, approval); err != nil {
+		log.Printf("❌ Failed to set agent %d %s: %v", id, approval, err)
+		JSONError(w, "Failed to update agent", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("🔐 Agent %s (id=%d): %s → %s", agent.Hostname, id, agent.Approval, approval)
+	action := "agent_approve"
+	if approval == agents.ApprovalRejected {
+		action = "agent_reject"
+	}
+	recordAudit(r, action, "agent", idStr, agent.Hostname)
+	JSONResponse(w, map[string]interface{}{
+		"id":       id,
+		"hostname": agent.Hostname,
+		"approval": approval,
+	})
+}
+
 // ─── Admin: registration token management ─────────────────────────────────────
 
 type createTokenRequest struct {
@@ -359,9 +443,7 @@ func CreateToken(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("🔑 Registration token created: %.16s... (name=%q)", tok.Token, tok.Name)
-	if s := auth.GetSessionFromContext(r); s != nil {
-		audit.LogEvent(db.DB, r, s.UserID, s.Username, "token_create", "registration_token", "", tok.Name, "success")
-	}
+	recordAudit(r, "token_create", "registration_token", "", tok.Name)
 	JSONResponse(w, tok)
 }
 
@@ -394,50 +476,60 @@ func DeleteToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if s := auth.GetSessionFromContext(r); s != nil {
-		audit.LogEvent(db.DB, r, s.UserID, s.Username, "token_delete", "registration_token", idStr, "", "success")
-	}
+	recordAudit(r, "token_delete", "registration_token", idStr, "")
 	JSONResponse(w, map[string]string{"status": "deleted"})
 }
 
 // ─── LED Identify proxy ─────────────────────────────────────────────────────
 
-// IdentifyDrive proxies a LED identify request to the agent's command server.
-// POST /api/v1/agents/{hostname}/identify
-func IdentifyDrive(w http.ResponseWriter, r *http.Request) {
-	hostname := r.PathValue("hostname")
-	if hostname == "" {
-		JSONError(w, "Missing hostname", http.StatusBadRequest)
-		return
-	}
-
+// agentCommandURL resolves the command-server URL for path on the agent that
+// reports as hostname, rejecting addresses that are not routable. On failure it
+// writes the error response and returns false.
+func agentCommandURL(w http.ResponseWriter, hostname, path string) (string, bool) {
 	listenAddr, _, err := agents.GetAgentByHostname(db.DB, hostname)
 	if err != nil {
 		JSONError(w, "Agent not found", http.StatusNotFound)
-		return
+		return "", false
 	}
 	if listenAddr == "" {
 		JSONError(w, "Agent does not have a command server enabled", http.StatusNotImplemented)
-		return
+		return "", false
 	}
 
 	host, port, err := net.SplitHostPort(listenAddr)
 	if err != nil {
 		JSONError(w, "Invalid agent address", http.StatusBadGateway)
-		return
+		return "", false
 	}
 	ip := net.ParseIP(host)
 	if ip == nil {
 		JSONError(w, "Invalid agent host", http.StatusBadGateway)
-		return
+		return "", false
 	}
 	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
 		ip.IsMulticast() || ip.IsUnspecified() {
 		JSONError(w, "Agent address is not routable", http.StatusBadGateway)
-		return
+		return "", false
 	}
 	if p, perr := strconv.Atoi(port); perr != nil || p < 1 || p > 65535 {
 		JSONError(w, "Invalid agent port", http.StatusBadGateway)
+		return "", false
+	}
+
+	return (&url.URL{Scheme: "http", Host: net.JoinHostPort(host, port), Path: path}).String(), true
+}
+
+// IdentifyDrive proxies a LED identify request to the agent's command server.
+// POST /api/v1/agents/{hostname}/identify
+func IdentifyDrive(w http.ResponseWriter, r *http.Request) {
+	hostname := r.PathValue("hostname")
+	if hostname == "" {
+		JSONError(w, "Missing hostname", http.StatusBadRequest)
+		return
+	}
+
+	agentURL, ok := agentCommandURL(w, hostname, "/api/identify")
+	if !ok {
 		return
 	}
 
@@ -448,11 +540,10 @@ func IdentifyDrive(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	agentURL := (&url.URL{Scheme: "http", Host: net.JoinHostPort(host, port), Path: "/api/identify"}).String()
 	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Post(agentURL, "application/json", bytes.NewReader(body)) // #nosec G107 G_SSRP -- host validated above
+	resp, err := client.Post(agentURL, "application/json", bytes.NewReader(body)) // #nosec G107 G_SSRP -- host validated in agentCommandURL
 	if err != nil {
-		log.Printf("⚠️  LED identify proxy to %s failed: %v", listenAddr, err)
+		log.Printf("⚠️  LED identify proxy to %s failed: %v", agentURL, err)
 		JSONError(w, "Failed to reach agent command server", http.StatusBadGateway)
 		return
 	}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"vigil/internal/db"
+	"vigil/internal/settings"
+	"vigil/internal/temperature"
+	"vigil/internal/zfs"
+)
+
+// fleetAggregates caches the fleet-wide summaries the dashboard polls, so
+// concurrent viewers read one precomputed copy instead of each rescanning
+// every drive and pool. RunAggregateWorker recomputes them after each
+// processed report and every dashboard.aggregate_refresh_seconds.
+type fleetAggregates struct {
+	mu  sync.RWMutex
+	set aggregateSet
+
+	stale chan struct{} // buffered: reports arriving mid-refresh coalesce into one more pass
+}
+
+// aggregateSet is one computation of every cached aggregate.
+type aggregateSet struct {
+	overview    *temperature.DashboardOverview
+	tempSummary *temperature.TemperatureSummary
+	zfsSummary  *zfs.ZFSPoolSummary
+	computedAt  time.Time
+}
+
+var aggregates = &fleetAggregates{stale: make(chan struct{}, 1)}
+
+// invalidate asks the worker for a recompute without waiting for it.
+func (a *fleetAggregates) invalidate() {
+	select {
+	case a.stale <- struct{}{}:
+	default:
+	}
+}
+
+// refresh recomputes every aggregate and replaces the cached set. The
+// previous set is kept if any of them fails.
+func (a *fleetAggregates) refresh() error {
+	overview, err := temperature.GetDashboardOverview(db.DB)
+	if err != nil {
+		return err
+	}
+	tempSummary, err := temperature.GetTemperatureSummary(db.DB)
+	if err != nil {
+		return err
+	}
+	zfsSummary, err := zfs.GetGlobalZFSSummary(db.DB)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.set = aggregateSet{overview, tempSummary, zfsSummary, time.Now().UTC()}
+	a.mu.Unlock()
+	return nil
+}
+
+// load returns the cached set, computing it first when forced or when the
+// worker hasn't produced one yet.
+func (a *fleetAggregates) load(force bool) (aggregateSet, error) {
+	a.mu.RLock()
+	set := a.set
+	a.mu.RUnlock()
+	if !force && !set.computedAt.IsZero() {
+		return set, nil
+	}
+
+	if err := a.refresh(); err != nil {
+		return aggregateSet{}, err
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.set, nil
+}
+
+// cachedAggregates serves the aggregates for r; ?refresh=true recomputes
+// them first. The X-Vigil-Computed-At header tells how fresh they are.
+func cachedAggregates(w http.ResponseWriter, r *http.Request) (aggregateSet, bool) {
+	set, err := aggregates.load(r.URL.Query().Get("refresh") == "true")
+	if err != nil {
+		log.Printf("❌ Failed to compute fleet aggregates: %v", err)
+		JSONError(w, "Failed to compute fleet aggregates", http.StatusInternalServerError)
+		return aggregateSet{}, false
+	}
+	w.Header().Set("X-Vigil-Computed-At", set.computedAt.Format(time.RFC3339))
+	return set, true
+}
+
+// RunAggregateWorker keeps the fleet aggregates fresh: it recomputes them
+// at startup, after every processed report and at least every
+// dashboard.aggregate_refresh_seconds. It never returns.
+func RunAggregateWorker() {
+	for {
+		if err := aggregates.refresh(); err != nil {
+			log.Printf("⚠️  Fleet aggregates refresh: %v", err)
+		}
+		interval := settings.GetInt(db.DB, "dashboard", "aggregate_refresh_seconds", 30)
+		if interval < 1 {
+			interval = 1
+		}
+		select {
+		case <-aggregates.stale:
+		case <-time.After(time.Duration(interval) * time.Second):
+		}
+	}
+}
+
+// DashboardOverview returns the cached fleet overview: drive count, open
+// alerts, temperatures and fleet health.
+// GET /api/dashboard/overview[?refresh=true]
+func DashboardOverview(w http.ResponseWriter, r *http.Request) {
+	if set, ok := cachedAggregates(w, r); ok {
+		jsonResponseFor(w, r, set.overview)
+	}
+}
+
+// TemperatureSummary returns the cached fleet temperature summary.
+// GET /api/temperature/summary[?refresh=true]
+func TemperatureSummary(w http.ResponseWriter, r *http.Request) {
+	if set, ok := cachedAggregates(w, r); ok {
+		jsonResponseFor(w, r, set.tempSummary)
+	}
+}
@@ -5,10 +5,7 @@ import (
 	"log"
 	"net/http"
 	"strings"
-	"time"
 
-	"vigil/internal/audit"
-	"vigil/internal/auth"
 	"vigil/internal/db"
 	"vigil/internal/models"
 	"vigil/internal/validate"
@@ -35,6 +32,7 @@ func GetAliases(w http.ResponseWriter, r *http.Request) {
 	}
 	defer rows.Close()
 
+	scope := requestScope(r)
 	aliases := make([]models.DriveAlias, 0)
 	for rows.Next() {
 		var a models.DriveAlias
@@ -42,7 +40,10 @@ func GetAliases(w http.ResponseWriter, r *http.Request) {
 		if err := rows.Scan(&a.ID, &a.Hostname, &a.SerialNumber, &a.Alias, &createdAt); err != nil {
 			continue
 		}
-		a.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
+		if !scope.AllowsHost(a.Hostname) {
+			continue
+		}
+		a.CreatedAt = db.ParseTime(createdAt)
 		aliases = append(aliases, a)
 	}
 
@@ -96,9 +97,7 @@ func SetAlias(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("📝 Alias set: %s/%s -> %s", req.Hostname, req.SerialNumber, req.Alias)
-	if s := auth.GetSessionFromContext(r); s != nil {
-		audit.LogEvent(db.DB, r, s.UserID, s.Username, "alias_set", "alias", req.SerialNumber, req.Hostname+"/"+req.SerialNumber+" -> "+req.Alias, "success")
-	}
+	recordAudit(r, "alias_set", "alias", req.SerialNumber, req.Hostname+"/"+req.SerialNumber+" -> "+req.Alias)
 	JSONResponse(w, map[string]string{"status": "ok"})
 }
 
@@ -121,8 +120,6 @@ func DeleteAlias(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if s := auth.GetSessionFromContext(r); s != nil {
-		audit.LogEvent(db.DB, r, s.UserID, s.Username, "alias_delete", "alias", id, "", "success")
-	}
+	recordAudit(r, "alias_delete", "alias", id, "")
 	JSONResponse(w, map[string]string{"status": "deleted"})
 }
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// anonymizer rewrites hardware identifiers in a response so it can be shared
+// for support. Serials always become stable pseudonyms (same serial → same
+// pseudonym); host and model names are redacted only when asked for.
+//
+// Query parameters: ?anonymize=true, plus &redact_hosts=true and/or
+// &redact_models=true.
+type anonymizer struct {
+	hosts  bool
+	models bool
+	key    []byte
+}
+
+// Keys whose string values are replaced. Anything under "wwn" is zeroed:
+// a WWN identifies the drive as uniquely as its serial.
+var (
+	serialKeys = map[string]bool{"serial_number": true, "serial": true}
+	hostKeys   = map[string]bool{"hostname": true, "host": true}
+	modelKeys  = map[string]bool{"model_name": true, "model_family": true, "model": true}
+)
+
+const minEmbeddedSerialLen = 4
+
+// anonymizerFromRequest returns nil unless the request asked for anonymized
+// output.
+func anonymizerFromRequest(r *http.Request) *anonymizer {
+	q := r.URL.Query()
+	if q.Get("anonymize") != "true" {
+		return nil
+	}
+	a := &anonymizer{
+		hosts:  q.Get("redact_hosts") == "true",
+		models: q.Get("redact_models") == "true",
+	}
+	// Keying the hash with the server's private key keeps pseudonyms stable
+	// across exports from this install while making them impossible to
+	// reverse by hashing candidate serials.
+	if ServerKeys != nil {
+		a.key = ServerKeys.PrivateKey.Seed()
+	}
+	return a
+}
+
+// anonymizedResponse writes data as JSON, anonymized if the request asked
+// for it.
+func anonymizedResponse(w http.ResponseWriter, r *http.Request, data interface{}) {
+	a := anonymizerFromRequest(r)
+	if a == nil {
+		jsonResponseFor(w, r, data)
+		return
+	}
+
+	// Round-trip through JSON so typed structs and raw report maps are
+	// walked the same way, keyed by their JSON field names.
+	raw, err := json.Marshal(data)
+	if err != nil {
+		JSONError(w, "Failed to process data", http.StatusInternalServerError)
+		return
+	}
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		JSONError(w, "Failed to process data", http.StatusInternalServerError)
+		return
+	}
+
+	serials := map[string]string{}
+	a.collectSerials(generic, serials)
+	jsonResponseFor(w, r, a.rewrite(generic, "", serials))
+}
+
+func (a *anonymizer) pseudonym(prefix, value string) string {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(prefix + ":" + value))
+	return prefix + "-" + hex.EncodeToString(mac.Sum(nil))[:10]
+}
+
+// collectSerials records a pseudonym for every serial in v, so the serial can
+// also be scrubbed where it is embedded in other strings (by-id paths, ZFS
+// vdev names, aliases). Very short values are skipped: replacing them as
+// substrings would mangle unrelated text.
+func (a *anonymizer) collectSerials(v interface{}, serials map[string]string) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if s, ok := child.(string); ok && serialKeys[k] && len(s) >= minEmbeddedSerialLen {
+				serials[s] = a.pseudonym("SN", s)
+				continue
+			}
+			a.collectSerials(child, serials)
+		}
+	case []interface{}:
+		for _, child := range t {
+			a.collectSerials(child, serials)
+		}
+	}
+}
+
+func (a *anonymizer) rewrite(v interface{}, key string, serials map[string]string) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if k == "wwn" {
+				t[k] = zeroLeaves(child)
+				continue
+			}
+			t[k] = a.rewrite(child, k, serials)
+		}
+		return t
+	case []interface{}:
+		for i, child := range t {
+			t[i] = a.rewrite(child, key, serials)
+		}
+		return t
+	case string:
+		switch {
+		case t == "":
+			return t
+		case serialKeys[key]:
+			return a.pseudonym("SN", t)
+		case a.hosts && hostKeys[key]:
+			return a.pseudonym("host", t)
+		case a.models && modelKeys[key]:
+			return a.pseudonym("model", t)
+		}
+		for serial, alias := range serials {
+			if strings.Contains(t, serial) {
+				t = strings.ReplaceAll(t, serial, alias)
+			}
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+// zeroLeaves keeps the shape of v but blanks every value in it.
+func zeroLeaves(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			t[k] = zeroLeaves(child)
+		}
+		return t
+	case []interface{}:
+		for i, child := range t {
+			t[i] = zeroLeaves(child)
+		}
+		return t
+	case string:
+		return ""
+	case float64:
+		return 0
+	default:
+		return v
+	}
+}
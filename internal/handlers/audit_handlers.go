@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"vigil/internal/audit"
+	"vigil/internal/auth"
+	"vigil/internal/db"
+)
+
+// recordAudit persists a successful mutating action on behalf of the
+// request's session. Requests without a session are ignored.
+func recordAudit(r *http.Request, action, resource, resourceID, details string) {
+	if s := auth.GetSessionFromContext(r); s != nil {
+		audit.LogEvent(db.DB, r, s.UserID, s.Username, action, resource, resourceID, details, "success")
+	}
+}
+
+// GetAuditLog returns recent audit log entries, newest first.
+// GET /api/audit?limit=100&since=2026-01-01T00:00:00Z
+func GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if n, err := strconv.Atoi(l); err == nil && n > 0 && n <= 1000 {
+			limit = n
+		}
+	}
+
+	var since time.Time
+	if s := r.URL.Query().Get("since"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			JSONError(w, "since must be an RFC3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		since = t
+	}
+
+	entries, err := audit.List(db.DB, limit, since)
+	if err != nil {
+		JSONError(w, "Failed to load audit log", http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []audit.Entry{}
+	}
+
+	JSONResponse(w, entries)
+}
+
+// RegisterAuditRoutes registers the audit log API route.
+func RegisterAuditRoutes(mux *http.ServeMux, protect func(http.HandlerFunc) http.HandlerFunc) {
+	mux.HandleFunc("GET /api/audit", protect(GetAuditLog))
+}
@@ -54,6 +54,7 @@ func TriggerBackup(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("💾 Manual backup created: %s (%d bytes)", info.Filename, info.SizeBytes)
+	recordAudit(r, "backup_create", "backup", info.Filename, "")
 	JSONResponse(w, info)
 }
 
@@ -86,6 +87,7 @@ func DeleteBackupFile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("🗑️ Backup deleted: %s", filename)
+	recordAudit(r, "backup_delete", "backup", filename, "")
 	JSONResponse(w, map[string]string{"status": "deleted"})
 }
 
@@ -212,6 +214,7 @@ func RestoreBackup(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("✅ Database restored from upload: %s (%d bytes)", header.Filename, header.Size)
+	recordAudit(r, "backup_restore", "database", header.Filename, "safety_backup="+safetyName)
 	JSONResponse(w, map[string]string{"status": "restored", "safety_backup": safetyName})
 }
 
@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"vigil/internal/auth"
+	"vigil/internal/db"
 	"vigil/internal/events"
 	"vigil/internal/metrics"
 	"vigil/internal/models"
@@ -21,14 +25,110 @@ var Metrics *metrics.Collector
 // DBPath is the path to the database file, used for size reporting.
 var DBPath string
 
+// DisplayLocation is the zone timestamps are rendered in, set from main.go
+// via SetDisplayTimezone. SQLite stores everything in UTC.
+var DisplayLocation = time.Local
+
+// SetDisplayTimezone sets DisplayLocation from an IANA zone name. An empty
+// name keeps the server's local zone (TZ).
+func SetDisplayTimezone(name string) error {
+	if name == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return err
+	}
+	DisplayLocation = loc
+	return nil
+}
+
+// formatTimestamp renders a timestamp read from SQLite as RFC3339 in
+// DisplayLocation. Anything that doesn't parse is returned unchanged.
+func formatTimestamp(s string) string {
+	if t := db.ParseTime(s); !t.IsZero() {
+		return t.In(DisplayLocation).Format(time.RFC3339)
+	}
+	return s
+}
+
 // JSONResponse sends a JSON response
 func JSONResponse(w http.ResponseWriter, data interface{}) {
+	writeJSON(w, data, false)
+}
+
+// jsonResponseFor sends data like JSONResponse, shaped by the request:
+// ?fields=a,b,c keeps only those top-level keys (of the object, or of each
+// object in an array) and ?pretty=true indents the output. The read
+// endpoints with large responses use it.
+func jsonResponseFor(w http.ResponseWriter, r *http.Request, data interface{}) {
+	q := r.URL.Query()
+	if fields := q.Get("fields"); fields != "" {
+		selected, err := selectFields(data, strings.Split(fields, ","))
+		if err != nil {
+			JSONError(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+		data = selected
+	}
+	writeJSON(w, data, q.Get("pretty") == "true")
+}
+
+func writeJSON(w http.ResponseWriter, data interface{}, pretty bool) {
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(data); err != nil {
+	enc := json.NewEncoder(w)
+	if pretty {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(data); err != nil {
 		log.Printf("⚠️  Failed to encode JSON response: %v", err)
 	}
 }
 
+// selectFields round-trips data through JSON, so structs are pruned by their
+// JSON names, and drops every top-level key not in fields. Anything that
+// isn't an object, or an array of objects, is returned as is.
+func selectFields(data interface{}, fields []string) (interface{}, error) {
+	keep := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			keep[f] = true
+		}
+	}
+	if len(keep) == 0 {
+		return data, nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	prune := func(v interface{}) {
+		if m, ok := v.(map[string]interface{}); ok {
+			for k := range m {
+				if !keep[k] {
+					delete(m, k)
+				}
+			}
+		}
+	}
+	if items, ok := doc.([]interface{}); ok {
+		for _, item := range items {
+			prune(item)
+		}
+	} else {
+		prune(doc)
+	}
+	return doc, nil
+}
+
 // JSONError sends a JSON error response
 func JSONError(w http.ResponseWriter, message string, code int) {
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,659 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	agentsmart "vigil/cmd/agent/smart"
+	"vigil/internal/agents"
+	"vigil/internal/auth"
+	"vigil/internal/crypto"
+	"vigil/internal/db"
+	"vigil/internal/drivealerts"
+	"vigil/internal/drivename"
+	"vigil/internal/latency"
+	"vigil/internal/middleware"
+	"vigil/internal/relocation"
+	"vigil/internal/smart"
+	"vigil/internal/temperature"
+	"vigil/internal/validate"
+)
+
+// DriveEntry is one drive in the fleet-wide inventory.
+type DriveEntry struct {
+	Hostname      string `json:"hostname"`
+	SerialNumber  string `json:"serial_number"`
+	Alias         string `json:"alias,omitempty"`
+	DisplayName   string `json:"display_name"` // label per drives/display_name_order
+	Model         string `json:"model"`
+	DeviceName    string `json:"device_name,omitempty"`  // kernel name, e.g. /dev/sda; may change across reboots
+	DeviceByID    string `json:"device_by_id,omitempty"` // stable /dev/disk/by-id path when the agent reports it
+	DriveType     string `json:"drive_type"`
+	CapacityBytes int64  `json:"capacity_bytes"`
+	Temperature   *int   `json:"temperature,omitempty"`
+	Health        string `json:"health"` // healthy, warning or critical
+	SmartPassed   bool   `json:"smart_passed"`
+	AlertsEnabled bool   `json:"alerts_enabled"` // false: data is recorded, but the drive raises no alerts
+	LastSeen      string `json:"last_seen"`
+	// Sparklines holds the requested ?sparklines= series: daily values
+	// over the last 30 days, oldest first.
+	Sparklines map[string][]float64 `json:"sparklines,omitempty"`
+}
+
+// ListDrives returns one entry per (hostname, serial) from each host's latest
+// report. Filters: ?type=SSD, ?health=critical, ?hostname=nas01. Sorting:
+// ?sort=hostname|serial|model|type|capacity|temperature|health, prefixed
+// with "-" for descending (default "hostname"). ?anonymize=true pseudonymizes
+// serials for sharing. ?sparklines=temp,5,197 adds 30-day trend series for
+// temperature and the given SMART attribute IDs.
+// GET /api/drives
+func ListDrives(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	typeFilter := q.Get("type")
+	healthFilter := q.Get("health")
+	hostFilter := q.Get("hostname")
+
+	sortKey := q.Get("sort")
+	desc := strings.HasPrefix(sortKey, "-")
+	sortKey = strings.TrimPrefix(sortKey, "-")
+	if sortKey == "" {
+		sortKey = "hostname"
+	}
+	less, ok := driveSorters[sortKey]
+	if !ok {
+		JSONError(w, "Invalid sort field", http.StatusBadRequest)
+		return
+	}
+
+	sparkKeys, err := smart.ParseSparklineKeys(q.Get("sparklines"))
+	if err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	scope := requestScope(r)
+	drives := make([]DriveEntry, 0)
+	err = forEachLatestDrive(func(host, ts string, d map[string]interface{}, entry DriveEntry) {
+		if hostFilter != "" && !strings.EqualFold(host, hostFilter) {
+			return
+		}
+		if !scope.AllowsHost(host) {
+			return
+		}
+		if typeFilter != "" && !strings.EqualFold(entry.DriveType, typeFilter) {
+			return
+		}
+		if healthFilter != "" && !strings.EqualFold(entry.Health, healthFilter) {
+			return
+		}
+		drives = append(drives, entry)
+	})
+	if err != nil {
+		log.Printf("❌ Failed to list drives: %v", err)
+		JSONError(w, "Failed to list drives", http.StatusInternalServerError)
+		return
+	}
+
+	if len(sparkKeys) > 0 {
+		lines, err := smart.GetSparklines(db.DB, sparkKeys)
+		if err != nil {
+			log.Printf("❌ Failed to load sparklines: %v", err)
+			JSONError(w, "Failed to load sparklines", http.StatusInternalServerError)
+			return
+		}
+		for i := range drives {
+			drives[i].Sparklines = lines[drives[i].Hostname+":"+drives[i].SerialNumber]
+		}
+	}
+
+	sort.SliceStable(drives, func(i, j int) bool {
+		if desc {
+			return less(drives[j], drives[i])
+		}
+		return less(drives[i], drives[j])
+	})
+
+	anonymizedResponse(w, r, drives)
+}
+
+// forEachLatestDrive calls fn for every drive with a serial number in each
+// host's latest report, passing the raw smartctl entry alongside its
+// inventory entry (alias and analysed health already filled in).
+func forEachLatestDrive(fn func(host, ts string, d map[string]interface{}, entry DriveEntry)) error {
+	rows, err := db.DB.Query(`
+		SELECT r.hostname, r.timestamp, r.data
+		FROM reports r
+		INNER JOIN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (PARTITION BY hostname ORDER BY timestamp DESC, id DESC) AS rn
+				FROM reports
+			) WHERE rn = 1
+		) latest ON r.id = latest.id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	aliases := loadAliases()
+	alertsOff, err := drivealerts.Disabled(db.DB)
+	if err != nil {
+		log.Printf("drives: load alert settings: %v", err)
+	}
+	names := drivename.NewResolver(db.DB)
+	health := make(map[string]string)
+	if summaries, err := smart.GetAllDrivesHealthSummary(db.DB); err == nil {
+		for _, s := range summaries {
+			health[s.Hostname+":"+s.SerialNumber] = strings.ToLower(s.OverallHealth)
+		}
+	}
+
+	for rows.Next() {
+		var host, ts string
+		var dataRaw []byte
+		if err := rows.Scan(&host, &ts, &dataRaw); err != nil {
+			continue
+		}
+		if dataRaw, err = crypto.OpenReport(dataRaw); err != nil {
+			log.Printf("drives: report for %s: %v", host, err)
+			continue
+		}
+
+		var report struct {
+			Drives []map[string]interface{} `json:"drives"`
+		}
+		if err := json.Unmarshal(dataRaw, &report); err != nil {
+			log.Printf("drives: unmarshal report for %s: %v", host, err)
+			continue
+		}
+
+		for _, d := range report.Drives {
+			entry := driveEntryFromReport(host, ts, d)
+			if entry.SerialNumber == "" {
+				continue
+			}
+			key := host + ":" + entry.SerialNumber
+			entry.Alias = aliases[key]
+			entry.AlertsEnabled = !alertsOff[strings.ToLower(host)+":"+entry.SerialNumber]
+			names.Know(host, entry.SerialNumber, entry.Model, drivename.DevicePath(d))
+			entry.DisplayName = names.DisplayName(host, entry.SerialNumber)
+			if h, ok := health[key]; ok {
+				entry.Health = h
+			}
+			fn(host, ts, d, entry)
+		}
+	}
+	return rows.Err()
+}
+
+// driveEntryFromReport extracts inventory fields from a smartctl drive entry.
+// Health falls back to the SMART self-assessment when no attribute analysis
+// is stored for the drive.
+func driveEntryFromReport(host, ts string, d map[string]interface{}) DriveEntry {
+	e := DriveEntry{Hostname: host, LastSeen: formatTimestamp(ts), SmartPassed: true}
+	e.SerialNumber, _ = d["serial_number"].(string)
+	e.Model = agentsmart.ModelName(d)
+	if dev, ok := d["device"].(map[string]interface{}); ok {
+		e.DeviceName, _ = dev["name"].(string)
+		e.DeviceByID, _ = dev["by_id"].(string)
+	}
+	e.DriveType = smart.DriveTypeFromReport(d)
+	if c, ok := d["user_capacity"].(map[string]interface{}); ok {
+		if b, ok := c["bytes"].(float64); ok {
+			e.CapacityBytes = int64(b)
+		}
+	}
+	if t, ok := d["temperature"].(map[string]interface{}); ok {
+		if cur, ok := t["current"].(float64); ok {
+			v := int(cur)
+			e.Temperature = &v
+		}
+	}
+	if st, ok := d["smart_status"].(map[string]interface{}); ok {
+		if passed, ok := st["passed"].(bool); ok {
+			e.SmartPassed = passed
+		}
+	}
+	e.Health = "healthy"
+	if !e.SmartPassed {
+		e.Health = "critical"
+	}
+	return e
+}
+
+var healthRank = map[string]int{"healthy": 0, "warning": 1, "critical": 2}
+
+var driveSorters = map[string]func(a, b DriveEntry) bool{
+	"hostname": func(a, b DriveEntry) bool {
+		if !strings.EqualFold(a.Hostname, b.Hostname) {
+			return strings.ToLower(a.Hostname) < strings.ToLower(b.Hostname)
+		}
+		return a.SerialNumber < b.SerialNumber
+	},
+	"serial":   func(a, b DriveEntry) bool { return a.SerialNumber < b.SerialNumber },
+	"model":    func(a, b DriveEntry) bool { return a.Model < b.Model },
+	"type":     func(a, b DriveEntry) bool { return a.DriveType < b.DriveType },
+	"capacity": func(a, b DriveEntry) bool { return a.CapacityBytes < b.CapacityBytes },
+	"temperature": func(a, b DriveEntry) bool {
+		if a.Temperature == nil || b.Temperature == nil {
+			return a.Temperature == nil && b.Temperature != nil
+		}
+		return *a.Temperature < *b.Temperature
+	},
+	"health": func(a, b DriveEntry) bool { return healthRank[a.Health] < healthRank[b.Health] },
+}
+
+// GetDriveLatency returns the read-latency probe history for a drive.
+// GET /api/drives/{hostname}/{serial}/latency?days=30
+func GetDriveLatency(w http.ResponseWriter, r *http.Request) {
+	hostname := r.PathValue("hostname")
+	serial := r.PathValue("serial")
+	if hostname == "" || serial == "" {
+		JSONError(w, "Missing hostname or serial", http.StatusBadRequest)
+		return
+	}
+
+	days := 30
+	if d := r.URL.Query().Get("days"); d != "" {
+		if v, err := strconv.Atoi(d); err == nil && v > 0 && v <= 365 {
+			days = v
+		}
+	}
+
+	samples, err := latency.GetHistory(db.DB, hostname, serial, days)
+	if err != nil {
+		log.Printf("❌ Failed to get latency history: %v", err)
+		JSONError(w, "Failed to retrieve latency history", http.StatusInternalServerError)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"hostname":      hostname,
+		"serial_number": serial,
+		"days":          days,
+		"samples":       samples,
+		"count":         len(samples),
+	}
+	if len(samples) > 0 {
+		resp["latest"] = samples[len(samples)-1]
+	}
+	JSONResponse(w, resp)
+}
+
+// GetDriveTrends returns the trend of every critical and warning SMART
+// attribute the drive reported, for its health detail view.
+// GET /api/drives/{hostname}/{serial}/trends?days=30
+func GetDriveTrends(w http.ResponseWriter, r *http.Request) {
+	hostname := r.PathValue("hostname")
+	serial := r.PathValue("serial")
+	if hostname == "" || serial == "" {
+		JSONError(w, "Missing hostname or serial", http.StatusBadRequest)
+		return
+	}
+
+	days := 30
+	if d := r.URL.Query().Get("days"); d != "" {
+		if v, err := strconv.Atoi(d); err == nil && v > 0 && v <= 365 {
+			days = v
+		}
+	}
+
+	trends, err := smart.GetDriveTrends(db.DB, hostname, serial, days)
+	if err != nil {
+		log.Printf("❌ Failed to get drive trends: %v", err)
+		JSONError(w, "Failed to retrieve drive trends", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, map[string]interface{}{
+		"hostname":      hostname,
+		"serial_number": serial,
+		"days":          days,
+		"trends":        trends,
+	})
+}
+
+// GetDriveThermalStress returns how long the drive spent above the
+// temperature warning and critical thresholds, estimated from its
+// temperature history.
+// GET /api/drives/{hostname}/{serial}/thermal-stress?period=30d
+func GetDriveThermalStress(w http.ResponseWriter, r *http.Request) {
+	hostname := r.PathValue("hostname")
+	serial := r.PathValue("serial")
+	if hostname == "" || serial == "" {
+		JSONError(w, "Missing hostname or serial", http.StatusBadRequest)
+		return
+	}
+
+	period := temperature.Period30Days
+	if p := r.URL.Query().Get("period"); p != "" {
+		period = temperature.ParsePeriod(p)
+	}
+
+	stress, err := temperature.GetThermalStress(db.DB, hostname, serial, period)
+	if err != nil {
+		log.Printf("❌ Failed to get thermal stress: %v", err)
+		JSONError(w, "Failed to retrieve thermal stress", http.StatusInternalServerError)
+		return
+	}
+	if stress == nil {
+		JSONError(w, "No temperature readings for this drive in the period", http.StatusNotFound)
+		return
+	}
+
+	JSONResponse(w, stress)
+}
+
+// GetDriveHealthHistory returns the drive's health status changes
+// (healthy, warning, critical), oldest first, with the issues behind each.
+// GET /api/drives/{hostname}/{serial}/health-history
+func GetDriveHealthHistory(w http.ResponseWriter, r *http.Request) {
+	hostname := r.PathValue("hostname")
+	serial := r.PathValue("serial")
+	if hostname == "" || serial == "" {
+		JSONError(w, "Missing hostname or serial", http.StatusBadRequest)
+		return
+	}
+
+	history, err := smart.GetHealthStatusHistory(db.DB, hostname, serial)
+	if err != nil {
+		log.Printf("❌ Failed to get health status history: %v", err)
+		JSONError(w, "Failed to retrieve health status history", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, map[string]interface{}{
+		"hostname":      hostname,
+		"serial_number": serial,
+		"changes":       history,
+	})
+}
+
+// GetDriveAlerts reports whether the drive raises alerts.
+// GET /api/drives/{hostname}/{serial}/alerts
+func GetDriveAlerts(w http.ResponseWriter, r *http.Request) {
+	hostname := r.PathValue("hostname")
+	serial := r.PathValue("serial")
+	if hostname == "" || serial == "" {
+		JSONError(w, "Missing hostname or serial", http.StatusBadRequest)
+		return
+	}
+
+	JSONResponse(w, map[string]interface{}{
+		"hostname":       hostname,
+		"serial_number":  serial,
+		"alerts_enabled": drivealerts.Enabled(db.DB, hostname, serial),
+	})
+}
+
+// SetDriveAlerts turns alerting for one drive on or off. A drive with
+// alerts off keeps recording SMART and temperature history but raises no
+// temperature alerts and never calls the emergency webhook — for drives
+// whose sensors report nonsense, where retiring the drive would lose its
+// history.
+// PUT /api/drives/{hostname}/{serial}/alerts
+func SetDriveAlerts(w http.ResponseWriter, r *http.Request) {
+	hostname := r.PathValue("hostname")
+	serial := r.PathValue("serial")
+	if hostname == "" || serial == "" {
+		JSONError(w, "Missing hostname or serial", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		AlertsEnabled *bool `json:"alerts_enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.AlertsEnabled == nil {
+		JSONError(w, "alerts_enabled is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := drivealerts.SetEnabled(db.DB, hostname, serial, *req.AlertsEnabled); err != nil {
+		log.Printf("❌ Failed to set alerts for %s/%s: %v", hostname, serial, err)
+		JSONError(w, "Failed to update drive alerts", http.StatusInternalServerError)
+		return
+	}
+
+	state := "disabled"
+	if *req.AlertsEnabled {
+		state = "enabled"
+	}
+	log.Printf("🔔 Alerts %s for %s/%s", state, hostname, serial)
+	recordAudit(r, "drive_alerts_"+state, "drive", serial, hostname+"/"+serial)
+
+	JSONResponse(w, map[string]interface{}{
+		"hostname":       hostname,
+		"serial_number":  serial,
+		"alerts_enabled": *req.AlertsEnabled,
+	})
+}
+
+// maxTemperatureSampleInterval caps a drive's temperature sampling
+// interval; a day between readings is already too coarse to be useful.
+const maxTemperatureSampleInterval = 86400
+
+// GetDriveTemperatureSampling returns the drive's own temperature sampling
+// interval, or null when it follows its drive type's setting.
+// GET /api/drives/{hostname}/{serial}/temperature-sampling
+func GetDriveTemperatureSampling(w http.ResponseWriter, r *http.Request) {
+	hostname := r.PathValue("hostname")
+	serial := r.PathValue("serial")
+	if hostname == "" || serial == "" {
+		JSONError(w, "Missing hostname or serial", http.StatusBadRequest)
+		return
+	}
+
+	secs, ok, err := smart.DriveSampleInterval(db.DB, hostname, serial)
+	if err != nil {
+		log.Printf("❌ Failed to get temperature sampling for %s/%s: %v", hostname, serial, err)
+		JSONError(w, "Failed to get temperature sampling", http.StatusInternalServerError)
+		return
+	}
+	var interval *int
+	if ok {
+		interval = &secs
+	}
+	JSONResponse(w, map[string]interface{}{
+		"hostname":         hostname,
+		"serial_number":    serial,
+		"interval_seconds": interval,
+	})
+}
+
+// SetDriveTemperatureSampling overrides how often the drive's temperature
+// is stored: at most one reading per interval_seconds (0 keeps every
+// reading), whatever its drive type's temperature/sample_interval_*
+// setting. Readings near a threshold or spike are always stored. A null
+// interval_seconds removes the override.
+// PUT /api/drives/{hostname}/{serial}/temperature-sampling
+func SetDriveTemperatureSampling(w http.ResponseWriter, r *http.Request) {
+	hostname := r.PathValue("hostname")
+	serial := r.PathValue("serial")
+	if hostname == "" || serial == "" {
+		JSONError(w, "Missing hostname or serial", http.StatusBadRequest)
+		return
+	}
+
+	var req map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	raw, ok := req["interval_seconds"]
+	if !ok {
+		JSONError(w, "interval_seconds is required (null to follow the drive type)", http.StatusBadRequest)
+		return
+	}
+	var interval *int
+	if err := json.Unmarshal(raw, &interval); err != nil {
+		JSONError(w, "interval_seconds must be a number of seconds or null", http.StatusBadRequest)
+		return
+	}
+	if interval != nil && (*interval < 0 || *interval > maxTemperatureSampleInterval) {
+		JSONError(w, fmt.Sprintf("interval_seconds must be between 0 and %d", maxTemperatureSampleInterval), http.StatusBadRequest)
+		return
+	}
+
+	if err := smart.SetDriveSampleInterval(db.DB, hostname, serial, interval); err != nil {
+		log.Printf("❌ Failed to set temperature sampling for %s/%s: %v", hostname, serial, err)
+		JSONError(w, "Failed to update temperature sampling", http.StatusInternalServerError)
+		return
+	}
+
+	detail := hostname + "/" + serial + ": drive type default"
+	if interval != nil {
+		detail = fmt.Sprintf("%s/%s: %ds", hostname, serial, *interval)
+	}
+	log.Printf("🌡️  Temperature sampling for %s", detail)
+	recordAudit(r, "drive_temperature_sampling", "drive", serial, detail)
+
+	JSONResponse(w, map[string]interface{}{
+		"hostname":         hostname,
+		"serial_number":    serial,
+		"interval_seconds": interval,
+	})
+}
+
+// GetDriveTimeline returns every host a drive serial has been attached to and
+// the relocations between them, so history can be followed across hosts.
+// GET /api/drives/{hostname}/{serial}/timeline
+func GetDriveTimeline(w http.ResponseWriter, r *http.Request) {
+	serial := r.PathValue("serial")
+	if serial == "" {
+		JSONError(w, "Missing serial", http.StatusBadRequest)
+		return
+	}
+
+	timeline, err := relocation.GetTimeline(db.DB, serial)
+	if err != nil {
+		log.Printf("❌ Failed to get drive timeline: %v", err)
+		JSONError(w, "Failed to retrieve drive timeline", http.StatusInternalServerError)
+		return
+	}
+	JSONResponse(w, timeline)
+}
+
+// DeleteDrive purges a single drive's stored history from a host, e.g. after
+// the drive was replaced, and returns the number of rows removed per table.
+// DELETE /api/drives/{hostname}/{serial}
+func DeleteDrive(w http.ResponseWriter, r *http.Request) {
+	hostname := strings.TrimSpace(r.PathValue("hostname"))
+	serial := strings.TrimSpace(r.PathValue("serial"))
+	if err := validate.Hostname(hostname); err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if serial == "" {
+		JSONError(w, "Missing serial", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := agents.DeleteDriveData(db.DB, hostname, serial)
+	if err != nil {
+		log.Printf("❌ Failed to delete drive %s/%s: %v", hostname, serial, err)
+		JSONError(w, "Failed to delete drive data", http.StatusInternalServerError)
+		return
+	}
+
+	var total int64
+	for _, n := range deleted {
+		total += n
+	}
+	if total == 0 {
+		JSONError(w, "Drive not found", http.StatusNotFound)
+		return
+	}
+
+	log.Printf("🗑️  Deleted drive history: %s/%s — %v", hostname, serial, deleted)
+	recordAudit(r, "drive_delete", "drive", serial, fmt.Sprintf("%s/%s: %v", hostname, serial, deleted))
+	JSONResponse(w, map[string]interface{}{
+		"status":        "deleted",
+		"hostname":      hostname,
+		"serial_number": serial,
+		"deleted":       deleted,
+	})
+}
+
+// ReplaceDrive records that a drive in a host was swapped for a new one so
+// the new drive starts fresh: the old drive's history is kept under its
+// serial (see its timeline), its open temperature alerts and spikes are
+// acknowledged, and learned temperature baselines are reset. With
+// "carry_over" the old drive's alias and drive groups move to the new
+// serial; otherwise the response's "alias" is there to offer it.
+// POST /api/drives/replace
+func ReplaceDrive(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Hostname  string `json:"hostname"`
+		OldSerial string `json:"old_serial"`
+		NewSerial string `json:"new_serial"`
+		CarryOver bool   `json:"carry_over"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	req.Hostname = strings.TrimSpace(req.Hostname)
+	req.OldSerial = strings.TrimSpace(req.OldSerial)
+	req.NewSerial = strings.TrimSpace(req.NewSerial)
+	if err := validate.Hostname(req.Hostname); err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.OldSerial == "" || req.NewSerial == "" {
+		JSONError(w, "Missing old_serial or new_serial", http.StatusBadRequest)
+		return
+	}
+	if req.OldSerial == req.NewSerial {
+		JSONError(w, "old_serial and new_serial must differ", http.StatusBadRequest)
+		return
+	}
+
+	var by string
+	if s := auth.GetSessionFromContext(r); s != nil {
+		by = s.Username
+	}
+
+	var rep *relocation.Replacement
+	err := db.Write(func() error {
+		var err error
+		rep, err = relocation.ReplaceDrive(db.DB, req.Hostname, req.OldSerial, req.NewSerial, req.CarryOver, by, time.Now())
+		return err
+	})
+	if errors.Is(err, relocation.ErrUnknownDrive) {
+		JSONError(w, "Drive "+req.OldSerial+" has not been seen on "+req.Hostname, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to replace drive %s/%s: %v", req.Hostname, req.OldSerial, err)
+		JSONError(w, "Failed to replace drive", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("🔁 Drive replaced on %s: %s -> %s", req.Hostname, req.OldSerial, req.NewSerial)
+	recordAudit(r, "drive_replace", "drive", req.OldSerial,
+		fmt.Sprintf("%s: %s -> %s (carry_over=%t)", req.Hostname, req.OldSerial, req.NewSerial, req.CarryOver))
+	JSONResponse(w, rep)
+}
+
+// RegisterDriveRoutes registers per-drive API routes.
+func RegisterDriveRoutes(mux *http.ServeMux, protect func(http.HandlerFunc) http.HandlerFunc) {
+	mux.HandleFunc("GET /api/drives", protect(middleware.ETag(ListDrives)))
+	mux.HandleFunc("GET /api/drives/{hostname}/{serial}/latency", protect(GetDriveLatency))
+	mux.HandleFunc("GET /api/drives/{hostname}/{serial}/timeline", protect(GetDriveTimeline))
+	mux.HandleFunc("GET /api/drives/{hostname}/{serial}/trends", protect(GetDriveTrends))
+	mux.HandleFunc("GET /api/drives/{hostname}/{serial}/thermal-stress", protect(GetDriveThermalStress))
+	mux.HandleFunc("GET /api/drives/{hostname}/{serial}/health-history", protect(GetDriveHealthHistory))
+	mux.HandleFunc("GET /api/drives/{hostname}/{serial}/alerts", protect(GetDriveAlerts))
+	mux.HandleFunc("PUT /api/drives/{hostname}/{serial}/alerts", protect(SetDriveAlerts))
+	mux.HandleFunc("GET /api/drives/{hostname}/{serial}/temperature-sampling", protect(GetDriveTemperatureSampling))
+	mux.HandleFunc("PUT /api/drives/{hostname}/{serial}/temperature-sampling", protect(SetDriveTemperatureSampling))
+	mux.HandleFunc("DELETE /api/drives/{hostname}/{serial}", protect(DeleteDrive))
+	mux.HandleFunc("POST /api/drives/replace", protect(ReplaceDrive))
+	mux.HandleFunc("GET /api/fleet/inventory", protect(middleware.ETag(GetFleetInventory)))
+}
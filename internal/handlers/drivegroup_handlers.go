@@ -2,12 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
 
 	"vigil/internal/db"
 	"vigil/internal/drivegroups"
+	"vigil/internal/middleware"
 	"vigil/internal/validate"
 )
 
@@ -24,7 +26,7 @@ func ListDriveGroups(w http.ResponseWriter, r *http.Request) {
 	if groups == nil {
 		groups = []drivegroups.DriveGroup{}
 	}
-	JSONResponse(w, groups)
+	jsonResponseFor(w, r, groups)
 }
 
 // CreateDriveGroup creates a new group.
@@ -50,6 +52,7 @@ func CreateDriveGroup(w http.ResponseWriter, r *http.Request) {
 	}
 	g.ID = id
 	log.Printf("🏷️ Drive group created: %s", req.Name)
+	recordAudit(r, "drive_group_create", "drive_group", strconv.FormatInt(id, 10), req.Name)
 	w.WriteHeader(http.StatusCreated)
 	JSONResponse(w, g)
 }
@@ -108,6 +111,7 @@ func UpdateDriveGroup(w http.ResponseWriter, r *http.Request) {
 		JSONError(w, "Failed to update group", http.StatusInternalServerError)
 		return
 	}
+	recordAudit(r, "drive_group_update", "drive_group", strconv.FormatInt(id, 10), req.Name)
 	JSONResponse(w, map[string]string{"status": "updated"})
 }
 
@@ -123,6 +127,7 @@ func DeleteDriveGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	log.Printf("🗑️ Drive group deleted: id=%d", id)
+	recordAudit(r, "drive_group_delete", "drive_group", strconv.FormatInt(id, 10), "")
 	JSONResponse(w, map[string]string{"status": "deleted"})
 }
 
@@ -153,6 +158,7 @@ func AssignDriveToGroup(w http.ResponseWriter, r *http.Request) {
 		JSONError(w, "Failed to assign drive", http.StatusInternalServerError)
 		return
 	}
+	recordAudit(r, "drive_group_assign", "drive_group", strconv.FormatInt(id, 10), req.Hostname+"/"+req.SerialNumber)
 	JSONResponse(w, map[string]string{"status": "assigned"})
 }
 
@@ -168,6 +174,7 @@ func UnassignDriveFromGroup(w http.ResponseWriter, r *http.Request) {
 		JSONError(w, "Failed to unassign drive", http.StatusInternalServerError)
 		return
 	}
+	recordAudit(r, "drive_group_unassign", "drive", hostname+"/"+serial, "")
 	JSONResponse(w, map[string]string{"status": "unassigned"})
 }
 
@@ -178,7 +185,7 @@ func GetDriveGroupAssignments(w http.ResponseWriter, r *http.Request) {
 		JSONError(w, "Failed to list assignments", http.StatusInternalServerError)
 		return
 	}
-	JSONResponse(w, m)
+	jsonResponseFor(w, r, m)
 }
 
 // ── Group Event Rules ───────────────────────────────────────────────────
@@ -230,6 +237,7 @@ func UpdateGroupRules(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	recordAudit(r, "group_rules_update", "notification_service", strconv.FormatInt(serviceID, 10), fmt.Sprintf("group=%d", groupID))
 	JSONResponse(w, map[string]string{"status": "updated"})
 }
 
@@ -250,6 +258,7 @@ func DeleteGroupRulesHandler(w http.ResponseWriter, r *http.Request) {
 		JSONError(w, "Failed to delete group rules", http.StatusInternalServerError)
 		return
 	}
+	recordAudit(r, "group_rules_delete", "notification_service", strconv.FormatInt(serviceID, 10), fmt.Sprintf("group=%d", groupID))
 	JSONResponse(w, map[string]string{"status": "deleted"})
 }
 
@@ -257,14 +266,14 @@ func DeleteGroupRulesHandler(w http.ResponseWriter, r *http.Request) {
 
 // RegisterDriveGroupRoutes registers drive group API routes.
 func RegisterDriveGroupRoutes(mux *http.ServeMux, protect func(http.HandlerFunc) http.HandlerFunc) {
-	mux.HandleFunc("GET /api/drive-groups", protect(ListDriveGroups))
+	mux.HandleFunc("GET /api/drive-groups", protect(middleware.ETag(ListDriveGroups)))
 	mux.HandleFunc("POST /api/drive-groups", protect(CreateDriveGroup))
 	mux.HandleFunc("GET /api/drive-groups/{id}", protect(GetDriveGroup))
 	mux.HandleFunc("PUT /api/drive-groups/{id}", protect(UpdateDriveGroup))
 	mux.HandleFunc("DELETE /api/drive-groups/{id}", protect(DeleteDriveGroup))
 	mux.HandleFunc("POST /api/drive-groups/{id}/members", protect(AssignDriveToGroup))
 	mux.HandleFunc("DELETE /api/drive-groups/members/{hostname}/{serial}", protect(UnassignDriveFromGroup))
-	mux.HandleFunc("GET /api/drive-groups/assignments", protect(GetDriveGroupAssignments))
+	mux.HandleFunc("GET /api/drive-groups/assignments", protect(middleware.ETag(GetDriveGroupAssignments)))
 
 	// Group-specific notification rules
 	mux.HandleFunc("GET /api/notifications/services/{id}/group-rules", protect(GetGroupRulesForService))
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"vigil/internal/db"
+	"vigil/internal/enclosures"
+	"vigil/internal/validate"
+)
+
+// enclosureRequest is the body of create and update requests.
+type enclosureRequest struct {
+	Name         string `json:"name"`
+	Location     string `json:"location"`
+	HotThreshold int    `json:"hot_threshold"`
+}
+
+// decodeEnclosure reads and validates an enclosure request, answering the
+// client itself when it's invalid.
+func decodeEnclosure(w http.ResponseWriter, r *http.Request) (*enclosureRequest, bool) {
+	var req enclosureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, "Invalid JSON", http.StatusBadRequest)
+		return nil, false
+	}
+	if err := validate.Name(req.Name, 64); err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if len(req.Location) > 128 {
+		JSONError(w, "location must be at most 128 characters", http.StatusBadRequest)
+		return nil, false
+	}
+	if req.HotThreshold < 0 || req.HotThreshold > 100 {
+		JSONError(w, "hot_threshold must be between 0 and 100", http.StatusBadRequest)
+		return nil, false
+	}
+	return &req, true
+}
+
+// ── Enclosure CRUD ──────────────────────────────────────────────────────
+
+// ListEnclosures returns all enclosures with member counts.
+func ListEnclosures(w http.ResponseWriter, r *http.Request) {
+	list, err := enclosures.ListEnclosures(db.DB)
+	if err != nil {
+		log.Printf("❌ List enclosures: %v", err)
+		JSONError(w, "Failed to list enclosures", http.StatusInternalServerError)
+		return
+	}
+	if list == nil {
+		list = []enclosures.Enclosure{}
+	}
+	JSONResponse(w, list)
+}
+
+// CreateEnclosure creates a new enclosure.
+func CreateEnclosure(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeEnclosure(w, r)
+	if !ok {
+		return
+	}
+
+	e := &enclosures.Enclosure{Name: req.Name, Location: req.Location, HotThreshold: req.HotThreshold}
+	id, err := enclosures.CreateEnclosure(db.DB, e)
+	if err != nil {
+		JSONError(w, "Enclosure name already exists", http.StatusConflict)
+		return
+	}
+	e.ID = id
+	log.Printf("🗄️ Enclosure created: %s", req.Name)
+	recordAudit(r, "enclosure_create", "enclosure", strconv.FormatInt(id, 10), req.Name)
+	w.WriteHeader(http.StatusCreated)
+	JSONResponse(w, e)
+}
+
+// GetEnclosure returns an enclosure with its members.
+func GetEnclosure(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		JSONError(w, "Invalid enclosure ID", http.StatusBadRequest)
+		return
+	}
+
+	e, err := enclosures.GetEnclosure(db.DB, id)
+	if err != nil {
+		JSONError(w, "Failed to get enclosure", http.StatusInternalServerError)
+		return
+	}
+	if e == nil {
+		JSONError(w, "Enclosure not found", http.StatusNotFound)
+		return
+	}
+
+	members, _ := enclosures.ListMembers(db.DB, id)
+	if members == nil {
+		members = []enclosures.Member{}
+	}
+
+	JSONResponse(w, map[string]interface{}{
+		"enclosure": e,
+		"members":   members,
+	})
+}
+
+// UpdateEnclosure updates an enclosure's name, location and hot threshold.
+func UpdateEnclosure(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		JSONError(w, "Invalid enclosure ID", http.StatusBadRequest)
+		return
+	}
+	req, ok := decodeEnclosure(w, r)
+	if !ok {
+		return
+	}
+
+	e := &enclosures.Enclosure{ID: id, Name: req.Name, Location: req.Location, HotThreshold: req.HotThreshold}
+	if err := enclosures.UpdateEnclosure(db.DB, e); err != nil {
+		JSONError(w, "Failed to update enclosure", http.StatusInternalServerError)
+		return
+	}
+	recordAudit(r, "enclosure_update", "enclosure", strconv.FormatInt(id, 10), req.Name)
+	JSONResponse(w, map[string]string{"status": "updated"})
+}
+
+// DeleteEnclosure removes an enclosure (cascade deletes its members).
+func DeleteEnclosure(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		JSONError(w, "Invalid enclosure ID", http.StatusBadRequest)
+		return
+	}
+	if err := enclosures.DeleteEnclosure(db.DB, id); err != nil {
+		JSONError(w, "Failed to delete enclosure", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("🗑️ Enclosure deleted: id=%d", id)
+	recordAudit(r, "enclosure_delete", "enclosure", strconv.FormatInt(id, 10), "")
+	JSONResponse(w, map[string]string{"status": "deleted"})
+}
+
+// ── Member Management ───────────────────────────────────────────────────
+
+// AssignDriveToEnclosure places a drive in an enclosure, moving it out of
+// any other one.
+func AssignDriveToEnclosure(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		JSONError(w, "Invalid enclosure ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Hostname     string `json:"hostname"`
+		SerialNumber string `json:"serial_number"`
+		Slot         string `json:"slot"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.Hostname == "" || req.SerialNumber == "" {
+		JSONError(w, "hostname and serial_number required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Slot) > 32 {
+		JSONError(w, "slot must be at most 32 characters", http.StatusBadRequest)
+		return
+	}
+
+	e, err := enclosures.GetEnclosure(db.DB, id)
+	if err != nil {
+		JSONError(w, "Failed to get enclosure", http.StatusInternalServerError)
+		return
+	}
+	if e == nil {
+		JSONError(w, "Enclosure not found", http.StatusNotFound)
+		return
+	}
+
+	if err := enclosures.AssignDrive(db.DB, id, req.Hostname, req.SerialNumber, req.Slot); err != nil {
+		JSONError(w, "Failed to assign drive", http.StatusInternalServerError)
+		return
+	}
+	recordAudit(r, "enclosure_assign", "enclosure", strconv.FormatInt(id, 10), req.Hostname+"/"+req.SerialNumber)
+	JSONResponse(w, map[string]string{"status": "assigned"})
+}
+
+// UnassignDriveFromEnclosure removes a drive from its enclosure.
+func UnassignDriveFromEnclosure(w http.ResponseWriter, r *http.Request) {
+	hostname := r.PathValue("hostname")
+	serial := r.PathValue("serial")
+	if hostname == "" || serial == "" {
+		JSONError(w, "hostname and serial required", http.StatusBadRequest)
+		return
+	}
+	if err := enclosures.UnassignDrive(db.DB, hostname, serial); err != nil {
+		JSONError(w, "Failed to unassign drive", http.StatusInternalServerError)
+		return
+	}
+	recordAudit(r, "enclosure_unassign", "drive", hostname+"/"+serial, "")
+	JSONResponse(w, map[string]string{"status": "unassigned"})
+}
+
+// ── Aggregation ─────────────────────────────────────────────────────────
+
+// GetEnclosureSummary returns an enclosure's aggregated temperature and
+// health with each drive's latest status.
+func GetEnclosureSummary(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		JSONError(w, "Invalid enclosure ID", http.StatusBadRequest)
+		return
+	}
+
+	e, err := enclosures.GetEnclosure(db.DB, id)
+	if err != nil {
+		JSONError(w, "Failed to get enclosure", http.StatusInternalServerError)
+		return
+	}
+	if e == nil {
+		JSONError(w, "Enclosure not found", http.StatusNotFound)
+		return
+	}
+
+	s, err := enclosures.Summarize(db.DB, e)
+	if err != nil {
+		log.Printf("❌ Enclosure summary %d: %v", id, err)
+		JSONError(w, "Failed to summarize enclosure", http.StatusInternalServerError)
+		return
+	}
+	JSONResponse(w, s)
+}
+
+// GetEnclosureSummaries returns the aggregated summary of every enclosure.
+func GetEnclosureSummaries(w http.ResponseWriter, r *http.Request) {
+	summaries, err := enclosures.SummarizeAll(db.DB)
+	if err != nil {
+		log.Printf("❌ Enclosure summaries: %v", err)
+		JSONError(w, "Failed to summarize enclosures", http.StatusInternalServerError)
+		return
+	}
+	JSONResponse(w, summaries)
+}
+
+// ── Route Registration ──────────────────────────────────────────────────
+
+// RegisterEnclosureRoutes registers enclosure API routes.
+func RegisterEnclosureRoutes(mux *http.ServeMux, protect func(http.HandlerFunc) http.HandlerFunc) {
+	mux.HandleFunc("GET /api/enclosures", protect(ListEnclosures))
+	mux.HandleFunc("POST /api/enclosures", protect(CreateEnclosure))
+	mux.HandleFunc("GET /api/enclosures/summary", protect(GetEnclosureSummaries))
+	mux.HandleFunc("GET /api/enclosures/{id}", protect(GetEnclosure))
+	mux.HandleFunc("PUT /api/enclosures/{id}", protect(UpdateEnclosure))
+	mux.HandleFunc("DELETE /api/enclosures/{id}", protect(DeleteEnclosure))
+	mux.HandleFunc("GET /api/enclosures/{id}/summary", protect(GetEnclosureSummary))
+	mux.HandleFunc("POST /api/enclosures/{id}/members", protect(AssignDriveToEnclosure))
+	mux.HandleFunc("DELETE /api/enclosures/members/{hostname}/{serial}", protect(UnassignDriveFromEnclosure))
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"vigil/internal/db"
+	"vigil/internal/zfs"
+)
+
+// FleetExportRow is one drive in the flat fleet export. Every field is a
+// scalar so table-oriented consumers (Grafana's JSON / Infinity datasources)
+// can render the array directly.
+type FleetExportRow struct {
+	Hostname     string `json:"hostname"`
+	Serial       string `json:"serial"`
+	Alias        string `json:"alias"`
+	DisplayName  string `json:"display_name"`
+	Model        string `json:"model"`
+	Type         string `json:"type"`
+	Device       string `json:"device"`
+	Temp         *int   `json:"temp"`
+	Status       string `json:"status"` // host online/offline
+	SmartPassed  bool   `json:"smart_passed"`
+	PowerOnHours *int64 `json:"power_on_hours"`
+	Capacity     int64  `json:"capacity"` // bytes
+	Pool         string `json:"pool"`
+	Health       string `json:"health"` // healthy, warning or critical
+	LastSeen     string `json:"last_seen"`
+}
+
+// minOfflineThreshold matches the dashboard: a host is offline after three
+// missed report intervals, but never sooner than ten minutes.
+const minOfflineThreshold = 10 * time.Minute
+
+// ExportFleet returns the current state of every drive as a flat array,
+// one object per drive, sorted by hostname then serial. Nullable fields
+// (temp, power_on_hours) are null rather than omitted so every row has the
+// same columns. ?anonymize=true pseudonymizes serials.
+// GET /api/export/fleet
+func ExportFleet(w http.ResponseWriter, r *http.Request) {
+	pools := make(map[string]string)
+	if devices, err := zfs.GetAllZFSPoolDevices(db.DB); err == nil {
+		for _, d := range devices {
+			if d.SerialNumber != "" {
+				pools[d.Hostname+":"+d.SerialNumber] = d.PoolName
+			}
+		}
+	}
+
+	offlineAfter := time.Duration(agentReportInterval()) * 3 * time.Second
+	if offlineAfter < minOfflineThreshold {
+		offlineAfter = minOfflineThreshold
+	}
+	now := time.Now()
+
+	rows := make([]FleetExportRow, 0)
+	err := forEachLatestDrive(func(host, ts string, d map[string]interface{}, e DriveEntry) {
+		row := FleetExportRow{
+			Hostname:    host,
+			Serial:      e.SerialNumber,
+			Alias:       e.Alias,
+			DisplayName: e.DisplayName,
+			Model:       e.Model,
+			Type:        e.DriveType,
+			Device:      e.DeviceName,
+			Temp:        e.Temperature,
+			Status:      "offline",
+			SmartPassed: e.SmartPassed,
+			Capacity:    e.CapacityBytes,
+			Pool:        pools[host+":"+e.SerialNumber],
+			Health:      e.Health,
+			LastSeen:    e.LastSeen,
+		}
+		if t := db.ParseTime(ts); !t.IsZero() && now.Sub(t) < offlineAfter {
+			row.Status = "online"
+		}
+		if pot, ok := d["power_on_time"].(map[string]interface{}); ok {
+			if h, ok := pot["hours"].(float64); ok {
+				hours := int64(h)
+				row.PowerOnHours = &hours
+			}
+		}
+		rows = append(rows, row)
+	})
+	if err != nil {
+		log.Printf("❌ Failed to export fleet: %v", err)
+		JSONError(w, "Failed to export fleet", http.StatusInternalServerError)
+		return
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if !strings.EqualFold(rows[i].Hostname, rows[j].Hostname) {
+			return strings.ToLower(rows[i].Hostname) < strings.ToLower(rows[j].Hostname)
+		}
+		return rows[i].Serial < rows[j].Serial
+	})
+
+	anonymizedResponse(w, r, rows)
+}
+
+// RegisterExportRoutes registers export API routes.
+func RegisterExportRoutes(mux *http.ServeMux, protect func(http.HandlerFunc) http.HandlerFunc) {
+	mux.HandleFunc("GET /api/export/fleet", protect(ExportFleet))
+}
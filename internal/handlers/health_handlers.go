@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
 	"vigil/internal/db"
 	"vigil/internal/health"
+	"vigil/internal/middleware"
 )
 
 // GetHealthScore returns the aggregate health score.
@@ -14,10 +16,32 @@ func GetHealthScore(w http.ResponseWriter, r *http.Request) {
 		JSONError(w, "Failed to calculate health score: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
-	JSONResponse(w, score)
+	jsonResponseFor(w, r, score)
+}
+
+// GetReplaceSoon returns drives ranked by replace priority, highest first,
+// with the factors behind each score. ?limit= defaults to 20.
+func GetReplaceSoon(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if n, err := strconv.Atoi(l); err == nil && n > 0 && n <= 500 {
+			limit = n
+		}
+	}
+
+	drives, err := health.ReplacePriorities(db.DB, limit)
+	if err != nil {
+		JSONError(w, "Failed to rank drives: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	JSONResponse(w, map[string]interface{}{
+		"drives": drives,
+		"count":  len(drives),
+	})
 }
 
 // RegisterHealthRoutes registers health-related API routes.
 func RegisterHealthRoutes(mux *http.ServeMux, protect func(http.HandlerFunc) http.HandlerFunc) {
-	mux.HandleFunc("GET /api/health/score", protect(GetHealthScore))
+	mux.HandleFunc("GET /api/health/score", protect(middleware.ETag(GetHealthScore)))
+	mux.HandleFunc("GET /api/fleet/replace-soon", protect(GetReplaceSoon))
 }
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	agentsmart "vigil/cmd/agent/smart"
+	"vigil/internal/db"
+	"vigil/internal/scrutiny"
+	"vigil/internal/validate"
+)
+
+// maxImportSize caps an imported smartctl document; `smartctl -x -j` output
+// for a single drive is well under 1 MiB.
+const maxImportSize = 4 << 20
+
+// ImportSmartctl stores a saved `smartctl -j` document for a drive, e.g.
+// for a post-mortem of a drive that is no longer attached anywhere. The
+// body is the raw smartctl JSON; it is stored as a one-drive report of the
+// host through the same path as imported agent reports, so it lands in the
+// host's report history and the drive's SMART attribute, temperature and
+// health history at the time smartctl captured it (local_time.time_t, else
+// now). It leaves the host's labels, maintenance state and drive presence
+// alone and raises no alerts.
+// ?serial= fills in a serial the document lacks and must match otherwise.
+// POST /api/import/smartctl?hostname=&serial=
+func ImportSmartctl(w http.ResponseWriter, r *http.Request) {
+	hostname := strings.TrimSpace(r.URL.Query().Get("hostname"))
+	if err := validate.Hostname(hostname); err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	serial := strings.TrimSpace(r.URL.Query().Get("serial"))
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	var data map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		JSONError(w, "Body must be smartctl JSON output (smartctl -j): "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	drive, err := agentsmart.ParseSmartAttributes(data, hostname)
+	if err != nil {
+		JSONError(w, "Failed to parse smartctl output: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch {
+	case drive.SerialNumber == "" && serial == "":
+		JSONError(w, "The smartctl output has no serial number; pass ?serial=", http.StatusBadRequest)
+		return
+	case drive.SerialNumber == "":
+		drive.SerialNumber = serial
+		data["serial_number"] = serial
+	case serial != "" && serial != drive.SerialNumber:
+		JSONError(w, fmt.Sprintf("serial %q does not match the smartctl output (%q)", serial, drive.SerialNumber), http.StatusBadRequest)
+		return
+	}
+	drive.Timestamp = time.Now().UTC().Truncate(time.Second)
+	if ts := smartctlCaptureTime(data); !ts.IsZero() {
+		drive.Timestamp = ts
+	}
+
+	report := map[string]interface{}{
+		"schema_version": float64(CurrentReportSchema),
+		"hostname":       hostname,
+		"timestamp":      drive.Timestamp.Format(time.RFC3339),
+		"drives":         []interface{}{data},
+	}
+	if _, _, rerr := ingestReportFrom(report, 0, drive.Timestamp, true); rerr != nil {
+		JSONError(w, "Failed to store SMART data: "+rerr.msg, rerr.status)
+		return
+	}
+	recordAudit(r, "smartctl_import", "drive", hostname+"/"+drive.SerialNumber,
+		fmt.Sprintf("%d attributes captured %s", len(drive.Attributes), drive.Timestamp.Format(time.RFC3339)))
+
+	JSONResponse(w, map[string]interface{}{
+		"status":       "imported",
+		"hostname":     hostname,
+		"serial":       drive.SerialNumber,
+		"model":        drive.ModelName,
+		"drive_type":   drive.DriveType,
+		"smart_passed": drive.SmartPassed,
+		"temperature":  drive.Temperature,
+		"attributes":   len(drive.Attributes),
+		"timestamp":    drive.Timestamp,
+	})
+}
+
+// smartctlCaptureTime returns when smartctl produced data, from its
+// local_time.time_t field, or the zero time if absent.
+func smartctlCaptureTime(data map[string]interface{}) time.Time {
+	lt, ok := data["local_time"].(map[string]interface{})
+	if !ok {
+		return time.Time{}
+	}
+	sec, ok := lt["time_t"].(float64)
+	if !ok || sec <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(sec), 0).UTC()
+}
+
+// maxScrutinyImportSize caps a Scrutiny export; months of history for a
+// few dozen drives fits comfortably. The export is decoded as it streams in,
+// not buffered whole.
+const maxScrutinyImportSize = 256 << 20
+
+// ImportScrutiny stores the SMART and temperature history of a Scrutiny
+// export (its /api/device/{wwn}/details and /api/summary responses, alone
+// or in a JSON array). Drives are matched on serial number to the host
+// Vigil already monitors them on; others go to ?hostname=, else Scrutiny's
+// host_id, else are skipped. Like the smartctl import it raises no alerts.
+// POST /api/import/scrutiny?hostname=
+func ImportScrutiny(w http.ResponseWriter, r *http.Request) {
+	hostname := strings.TrimSpace(r.URL.Query().Get("hostname"))
+	if hostname != "" {
+		if err := validate.Hostname(hostname); err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	exp, err := scrutiny.Read(http.MaxBytesReader(w, r.Body, maxScrutinyImportSize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		JSONError(w, "Failed to read export: "+err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		JSONError(w, "Body must be Scrutiny API JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := scrutiny.Import(db.DB, exp, hostname, db.Write)
+	if err != nil {
+		log.Printf("❌ Scrutiny import: %v", err)
+		JSONError(w, "Failed to import: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("📥 Scrutiny import: %d devices, %d SMART results, %d temperatures",
+		exp.Devices()-result.Skipped, result.SmartResults, result.Temperatures)
+	recordAudit(r, "scrutiny_import", "import", "",
+		fmt.Sprintf("%d devices (%d skipped), %d SMART results, %d temperatures",
+			exp.Devices(), result.Skipped, result.SmartResults, result.Temperatures))
+	JSONResponse(w, result)
+}
+
+// RegisterImportRoutes registers data import API routes.
+func RegisterImportRoutes(mux *http.ServeMux, protect func(http.HandlerFunc) http.HandlerFunc) {
+	mux.HandleFunc("POST /api/import/smartctl", protect(ImportSmartctl))
+	mux.HandleFunc("POST /api/import/scrutiny", protect(ImportScrutiny))
+	mux.HandleFunc("POST /api/reports/bulk", protect(ImportReports))
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// InventoryHealth counts drives by health.
+type InventoryHealth struct {
+	Healthy  int `json:"healthy"`
+	Warning  int `json:"warning"`
+	Critical int `json:"critical"`
+}
+
+func (h *InventoryHealth) add(health string) {
+	switch health {
+	case "critical":
+		h.Critical++
+	case "warning":
+		h.Warning++
+	default:
+		h.Healthy++
+	}
+}
+
+// InventoryGroup is one row of the fleet inventory: the drives sharing a
+// model, drive type and capacity class. Rollups leave the fields they don't
+// group by empty.
+type InventoryGroup struct {
+	Model         string          `json:"model,omitempty"`
+	DriveType     string          `json:"drive_type,omitempty"`
+	Capacity      string          `json:"capacity,omitempty"`       // e.g. "4 TB"
+	CapacityBytes int64           `json:"capacity_bytes,omitempty"` // nominal, for sorting
+	Count         int             `json:"count"`
+	Hosts         int             `json:"hosts"`
+	Health        InventoryHealth `json:"health"`
+
+	hosts map[string]bool
+}
+
+// FleetInventory is the response of GET /api/fleet/inventory.
+type FleetInventory struct {
+	Total      int               `json:"total"`
+	Health     InventoryHealth   `json:"health"`
+	Groups     []*InventoryGroup `json:"groups"`
+	ByModel    []*InventoryGroup `json:"by_model"`
+	ByType     []*InventoryGroup `json:"by_type"`
+	ByCapacity []*InventoryGroup `json:"by_capacity"`
+}
+
+// inventoryIndex accumulates drives into groups keyed by key.
+type inventoryIndex map[string]*InventoryGroup
+
+func (idx inventoryIndex) add(key string, proto InventoryGroup, e DriveEntry) {
+	g, ok := idx[key]
+	if !ok {
+		g = &proto
+		g.hosts = make(map[string]bool)
+		idx[key] = g
+	}
+	g.Count++
+	g.hosts[e.Hostname] = true
+	g.Hosts = len(g.hosts)
+	g.Health.add(e.Health)
+}
+
+// sorted returns the groups largest first, ties broken by capacity, model
+// and type so the order is stable.
+func (idx inventoryIndex) sorted() []*InventoryGroup {
+	out := make([]*InventoryGroup, 0, len(idx))
+	for _, g := range idx {
+		out = append(out, g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.CapacityBytes != b.CapacityBytes {
+			return a.CapacityBytes > b.CapacityBytes
+		}
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		return a.DriveType < b.DriveType
+	})
+	return out
+}
+
+// GetFleetInventory rolls the latest report of every host up into drive
+// counts per model, drive type and capacity class, each with a health
+// breakdown. ?type=SSD and ?hostname=nas01 narrow the drives counted.
+// GET /api/fleet/inventory
+func GetFleetInventory(w http.ResponseWriter, r *http.Request) {
+	typeFilter := r.URL.Query().Get("type")
+	hostFilter := r.URL.Query().Get("hostname")
+
+	inv := FleetInventory{}
+	groups := inventoryIndex{}
+	byModel := inventoryIndex{}
+	byType := inventoryIndex{}
+	byCapacity := inventoryIndex{}
+
+	err := forEachLatestDrive(func(host, _ string, _ map[string]interface{}, e DriveEntry) {
+		if typeFilter != "" && !strings.EqualFold(e.DriveType, typeFilter) {
+			return
+		}
+		if hostFilter != "" && host != hostFilter {
+			return
+		}
+		model := e.Model
+		if model == "" {
+			model = "Unknown"
+		}
+		capLabel, capBytes := capacityClass(e.CapacityBytes)
+
+		inv.Total++
+		inv.Health.add(e.Health)
+		groups.add(model+"\x00"+e.DriveType+"\x00"+capLabel, InventoryGroup{
+			Model: model, DriveType: e.DriveType, Capacity: capLabel, CapacityBytes: capBytes,
+		}, e)
+		byModel.add(model, InventoryGroup{Model: model}, e)
+		byType.add(e.DriveType, InventoryGroup{DriveType: e.DriveType}, e)
+		byCapacity.add(capLabel, InventoryGroup{Capacity: capLabel, CapacityBytes: capBytes}, e)
+	})
+	if err != nil {
+		log.Printf("❌ Failed to build fleet inventory: %v", err)
+		JSONError(w, "Failed to build fleet inventory", http.StatusInternalServerError)
+		return
+	}
+
+	inv.Groups = groups.sorted()
+	inv.ByModel = byModel.sorted()
+	inv.ByType = byType.sorted()
+	inv.ByCapacity = byCapacity.sorted()
+	jsonResponseFor(w, r, inv)
+}
+
+// capacityClass maps a drive's exact byte count to the decimal size it is
+// sold as, so a 4,000,787,030,016-byte drive lands in "4 TB" next to its
+// siblings: TB to two decimals (1.92 TB, 3.84 TB), GB whole. The second
+// return value is the nominal size in bytes.
+func capacityClass(bytes int64) (string, int64) {
+	const gb, tb = 1e9, 1e12
+	switch {
+	case bytes <= 0:
+		return "Unknown", 0
+	case bytes >= tb:
+		v := math.Round(float64(bytes)/tb*100) / 100
+		return strconv.FormatFloat(v, 'f', -1, 64) + " TB", int64(v * tb)
+	case bytes >= gb:
+		v := math.Round(float64(bytes) / gb)
+		return fmt.Sprintf("%.0f GB", v), int64(v * gb)
+	default:
+		v := math.Round(float64(bytes) / 1e6)
+		return fmt.Sprintf("%.0f MB", v), int64(v * 1e6)
+	}
+}
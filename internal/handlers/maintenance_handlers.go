@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"vigil/internal/db"
+	"vigil/internal/events"
+	"vigil/internal/smart"
+	"vigil/internal/temperature"
+)
+
+// Reevaluate re-runs temperature alert evaluation, spike detection and SMART
+// health analysis over the data already stored, against the current
+// settings — after changing thresholds, or after importing history that
+// never went through report ingestion. Alerts it creates are published like
+// live ones unless ?notify=false.
+// POST /api/maintenance/reevaluate
+func Reevaluate(w http.ResponseWriter, r *http.Request) {
+	var bus *events.Bus
+	if r.URL.Query().Get("notify") != "false" {
+		bus = EventBus
+	}
+
+	// Only the temperature pass writes, a drive at a time; the health
+	// pass just reads and publishes, so neither holds the write lock while
+	// scanning history.
+	temps, err := temperature.Reevaluate(db.DB, bus, db.Write)
+	if err != nil {
+		JSONError(w, "Re-evaluation failed: temperature: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	health, err := smart.ReevaluateHealth(db.DB, bus)
+	if err != nil {
+		JSONError(w, "Re-evaluation failed: health: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	recordAudit(r, "reevaluate", "maintenance", "",
+		fmt.Sprintf("%d temperature alerts, %d spikes, %d drives with health issues",
+			len(temps.Alerts), len(temps.Spikes), health.Critical+health.Warning))
+
+	JSONResponse(w, map[string]interface{}{
+		"temperature": temps,
+		"health":      health,
+		"notified":    bus != nil,
+	})
+}
+
+// RegisterMaintenanceRoutes registers the maintenance API routes.
+func RegisterMaintenanceRoutes(mux *http.ServeMux, protect func(http.HandlerFunc) http.HandlerFunc) {
+	mux.HandleFunc("POST /api/maintenance/reevaluate", protect(Reevaluate))
+}
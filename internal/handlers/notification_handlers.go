@@ -7,12 +7,11 @@ import (
 	"strconv"
 	"time"
 
-	"vigil/internal/audit"
-	"vigil/internal/auth"
 	"vigil/internal/db"
 	"vigil/internal/events"
 	"vigil/internal/notify"
 	"vigil/internal/settings"
+	"vigil/internal/summary"
 	"vigil/internal/validate"
 )
 
@@ -48,11 +47,15 @@ func ListNotificationServices(w http.ResponseWriter, r *http.Request) {
 	if services == nil {
 		services = []notify.NotificationService{}
 	}
+	pause := notify.GetGlobalPause(db.DB)
+	for i := range services {
+		services[i].GlobalPause = pause
+	}
 	JSONResponse(w, services)
 }
 
 // GetNotificationService returns a single service with its rules, quiet
-// hours, and digest config. Password fields in config are masked.
+// hours, digest and daily summary config. Password fields in config are masked.
 // GET /api/notifications/services/{id}
 func GetNotificationService(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(r, "id")
@@ -75,11 +78,14 @@ func GetNotificationService(w http.ResponseWriter, r *http.Request) {
 	rules, _ := notify.GetEventRules(db.DB, id)
 	qh, _ := notify.GetQuietHours(db.DB, id)
 	digest, _ := notify.GetDigestConfig(db.DB, id)
+	dailySummary, _ := notify.GetSummaryConfig(db.DB, id)
 
 	if rules == nil {
 		rules = []notify.EventRule{}
 	}
 
+	svc.GlobalPause = notify.GetGlobalPause(db.DB)
+
 	// Mask secrets in config_json before returning
 	svc.ConfigJSON = maskConfigSecrets(svc.ServiceType, svc.ConfigJSON)
 
@@ -88,6 +94,7 @@ func GetNotificationService(w http.ResponseWriter, r *http.Request) {
 		"event_rules": rules,
 		"quiet_hours": qh,
 		"digest":      digest,
+		"summary":     dailySummary,
 	})
 }
 
@@ -104,6 +111,9 @@ func CreateNotificationService(w http.ResponseWriter, r *http.Request) {
 		NotifyOnCritical bool              `json:"notify_on_critical"`
 		NotifyOnWarning  bool              `json:"notify_on_warning"`
 		NotifyOnHealthy  bool              `json:"notify_on_healthy"`
+		MinIntervalSecs  int               `json:"min_interval_seconds"`
+		MessageTemplates map[string]string `json:"message_templates"`
+		DryRun           bool              `json:"dry_run"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		JSONError(w, "Invalid JSON", http.StatusBadRequest)
@@ -117,6 +127,10 @@ func CreateNotificationService(w http.ResponseWriter, r *http.Request) {
 		JSONError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if err := notify.ValidateTemplates(req.MessageTemplates); err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	configJSON := req.ConfigJSON
 
@@ -143,6 +157,9 @@ func CreateNotificationService(w http.ResponseWriter, r *http.Request) {
 		NotifyOnCritical: req.NotifyOnCritical,
 		NotifyOnWarning:  req.NotifyOnWarning,
 		NotifyOnHealthy:  req.NotifyOnHealthy,
+		MinIntervalSecs:  req.MinIntervalSecs,
+		MessageTemplates: req.MessageTemplates,
+		DryRun:           req.DryRun,
 	}
 
 	id, err := notify.CreateService(db.DB, svc)
@@ -169,14 +186,13 @@ func CreateNotificationService(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("🔔 Notification service created: %s (%s)", svc.Name, svc.ServiceType)
-	if s := auth.GetSessionFromContext(r); s != nil {
-		audit.LogEvent(db.DB, r, s.UserID, s.Username, "notification_service_create", "notification_service", strconv.FormatInt(id, 10), svc.Name, "success")
-	}
+	recordAudit(r, "notification_service_create", "notification_service", strconv.FormatInt(id, 10), svc.Name)
 	w.WriteHeader(http.StatusCreated)
 	JSONResponse(w, svc)
 }
 
-// UpdateNotificationService modifies a service.
+// UpdateNotificationService modifies a service. Only the fields present in
+// the body change; the rest keep their stored values.
 // Accepts either legacy config_json or structured config_fields.
 // PUT /api/notifications/services/{id}
 func UpdateNotificationService(w http.ResponseWriter, r *http.Request) {
@@ -187,46 +203,85 @@ func UpdateNotificationService(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Name             string            `json:"name"`
-		ServiceType      string            `json:"service_type"`
-		ConfigJSON       string            `json:"config_json"`
+		Name             *string           `json:"name"`
+		ServiceType      *string           `json:"service_type"`
+		ConfigJSON       *string           `json:"config_json"`
 		ConfigFields     map[string]string `json:"config_fields"`
-		Enabled          bool              `json:"enabled"`
-		NotifyOnCritical bool              `json:"notify_on_critical"`
-		NotifyOnWarning  bool              `json:"notify_on_warning"`
-		NotifyOnHealthy  bool              `json:"notify_on_healthy"`
+		Enabled          *bool             `json:"enabled"`
+		NotifyOnCritical *bool             `json:"notify_on_critical"`
+		NotifyOnWarning  *bool             `json:"notify_on_warning"`
+		NotifyOnHealthy  *bool             `json:"notify_on_healthy"`
+		MinIntervalSecs  *int              `json:"min_interval_seconds"`
+		MessageTemplates map[string]string `json:"message_templates"`
+		DryRun           *bool             `json:"dry_run"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		JSONError(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
-	configJSON := req.ConfigJSON
+	svc, err := notify.GetService(db.DB, id)
+	if err != nil {
+		log.Printf("❌ Update notification service: %v", err)
+		JSONError(w, "Failed to update service", http.StatusInternalServerError)
+		return
+	}
+	if svc == nil {
+		JSONError(w, "Service not found", http.StatusNotFound)
+		return
+	}
 
-	if req.ConfigFields != nil && req.ServiceType != "" {
+	if req.MessageTemplates != nil {
+		if err := notify.ValidateTemplates(req.MessageTemplates); err != nil {
+			JSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		svc.MessageTemplates = req.MessageTemplates
+	}
+
+	if req.ConfigFields != nil {
+		serviceType := svc.ServiceType
+		if req.ServiceType != nil && *req.ServiceType != "" {
+			serviceType = *req.ServiceType
+		}
 		// Recover masked secrets from existing config
-		existing, _ := notify.GetService(db.DB, id)
-		if existing != nil {
-			mergeExistingSecrets(req.ServiceType, req.ConfigFields, existing.ConfigJSON)
+		if serviceType == svc.ServiceType {
+			mergeExistingSecrets(serviceType, req.ConfigFields, svc.ConfigJSON)
 		}
 
-		built, err := buildConfigJSON(req.ServiceType, req.ConfigFields)
+		built, err := buildConfigJSON(serviceType, req.ConfigFields)
 		if err != nil {
 			JSONError(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		configJSON = built
+		svc.ConfigJSON = built
+	} else if req.ConfigJSON != nil {
+		svc.ConfigJSON = *req.ConfigJSON
 	}
 
-	svc := &notify.NotificationService{
-		ID:               id,
-		Name:             req.Name,
-		ServiceType:      req.ServiceType,
-		ConfigJSON:       configJSON,
-		Enabled:          req.Enabled,
-		NotifyOnCritical: req.NotifyOnCritical,
-		NotifyOnWarning:  req.NotifyOnWarning,
-		NotifyOnHealthy:  req.NotifyOnHealthy,
+	if req.Name != nil {
+		svc.Name = *req.Name
+	}
+	if req.ServiceType != nil && *req.ServiceType != "" {
+		svc.ServiceType = *req.ServiceType
+	}
+	if req.Enabled != nil {
+		svc.Enabled = *req.Enabled
+	}
+	if req.NotifyOnCritical != nil {
+		svc.NotifyOnCritical = *req.NotifyOnCritical
+	}
+	if req.NotifyOnWarning != nil {
+		svc.NotifyOnWarning = *req.NotifyOnWarning
+	}
+	if req.NotifyOnHealthy != nil {
+		svc.NotifyOnHealthy = *req.NotifyOnHealthy
+	}
+	if req.MinIntervalSecs != nil {
+		svc.MinIntervalSecs = *req.MinIntervalSecs
+	}
+	if req.DryRun != nil {
+		svc.DryRun = *req.DryRun
 	}
 
 	if err := notify.UpdateService(db.DB, svc); err != nil {
@@ -235,9 +290,7 @@ func UpdateNotificationService(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if s := auth.GetSessionFromContext(r); s != nil {
-		audit.LogEvent(db.DB, r, s.UserID, s.Username, "notification_service_update", "notification_service", strconv.FormatInt(id, 10), "", "success")
-	}
+	recordAudit(r, "notification_service_update", "notification_service", strconv.FormatInt(id, 10), "")
 	JSONResponse(w, map[string]string{"status": "updated"})
 }
 
@@ -257,9 +310,7 @@ func DeleteNotificationService(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("🔔 Notification service deleted: id=%d", id)
-	if s := auth.GetSessionFromContext(r); s != nil {
-		audit.LogEvent(db.DB, r, s.UserID, s.Username, "notification_service_delete", "notification_service", strconv.FormatInt(id, 10), "", "success")
-	}
+	recordAudit(r, "notification_service_delete", "notification_service", strconv.FormatInt(id, 10), "")
 	JSONResponse(w, map[string]string{"status": "deleted"})
 }
 
@@ -289,9 +340,7 @@ func UpdateEventRules(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if s := auth.GetSessionFromContext(r); s != nil {
-		audit.LogEvent(db.DB, r, s.UserID, s.Username, "notification_rules_update", "notification_service", strconv.FormatInt(id, 10), "", "success")
-	}
+	recordAudit(r, "notification_rules_update", "notification_service", strconv.FormatInt(id, 10), "")
 	JSONResponse(w, map[string]string{"status": "updated"})
 }
 
@@ -319,12 +368,152 @@ func UpdateQuietHours(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if s := auth.GetSessionFromContext(r); s != nil {
-		audit.LogEvent(db.DB, r, s.UserID, s.Username, "notification_quiet_hours_update", "notification_service", strconv.FormatInt(id, 10), "", "success")
-	}
+	recordAudit(r, "notification_quiet_hours_update", "notification_service", strconv.FormatInt(id, 10), "")
 	JSONResponse(w, map[string]string{"status": "updated"})
 }
 
+// ─── Temporary Mute ──────────────────────────────────────────────────────
+
+// maxMuteDuration bounds a one-off mute; anything longer should be a
+// disabled service rather than one that is easy to forget about.
+const maxMuteDuration = 7 * 24 * time.Hour
+
+// MuteNotificationService silences a service until a given time, either
+// {"minutes": 180} or {"until": "2026-01-02T15:04:05Z"}. Unlike quiet hours
+// it is one-off and applies to every event, critical included; skipped
+// notifications are recorded in history with status "muted".
+// PUT /api/notifications/services/{id}/mute
+func MuteNotificationService(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		JSONError(w, "Invalid service ID", http.StatusBadRequest)
+		return
+	}
+	if svc, err := notify.GetService(db.DB, id); err != nil || svc == nil {
+		JSONError(w, "Service not found", http.StatusNotFound)
+		return
+	}
+
+	var req struct {
+		Minutes int       `json:"minutes"`
+		Until   time.Time `json:"until"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	until := req.Until
+	if req.Minutes > 0 {
+		until = now.Add(time.Duration(req.Minutes) * time.Minute)
+	}
+	if !until.After(now) {
+		JSONError(w, "Provide minutes > 0 or an until time in the future", http.StatusBadRequest)
+		return
+	}
+	if until.Sub(now) > maxMuteDuration {
+		JSONError(w, "Mute cannot exceed 7 days; disable the service instead", http.StatusBadRequest)
+		return
+	}
+
+	if err := notify.SetServiceMute(db.DB, id, &until); err != nil {
+		log.Printf("❌ Mute notification service: %v", err)
+		JSONError(w, "Failed to mute service", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "notification_service_mute", "notification_service", strconv.FormatInt(id, 10),
+		"until "+until.UTC().Format(time.RFC3339))
+	JSONResponse(w, map[string]interface{}{"status": "muted", "muted_until": until.UTC()})
+}
+
+// UnmuteNotificationService ends a temporary mute early.
+// DELETE /api/notifications/services/{id}/mute
+func UnmuteNotificationService(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		JSONError(w, "Invalid service ID", http.StatusBadRequest)
+		return
+	}
+	if svc, err := notify.GetService(db.DB, id); err != nil || svc == nil {
+		JSONError(w, "Service not found", http.StatusNotFound)
+		return
+	}
+
+	if err := notify.SetServiceMute(db.DB, id, nil); err != nil {
+		log.Printf("❌ Unmute notification service: %v", err)
+		JSONError(w, "Failed to unmute service", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "notification_service_unmute", "notification_service", strconv.FormatInt(id, 10), "")
+	JSONResponse(w, map[string]string{"status": "unmuted"})
+}
+
+// ─── Global Pause ────────────────────────────────────────────────────────
+
+// PauseNotifications is the kill switch for every service at once:
+// nothing is sent, critical included, until it is resumed or the optional
+// {"minutes": 60} or {"until": "2026-01-02T15:04:05Z"} passes. Skipped
+// notifications are recorded in history with status "paused".
+// POST /api/notifications/pause
+func PauseNotifications(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Minutes int       `json:"minutes"`
+		Until   time.Time `json:"until"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			JSONError(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var until *time.Time
+	now := time.Now()
+	switch {
+	case req.Minutes < 0:
+		JSONError(w, "minutes must be positive", http.StatusBadRequest)
+		return
+	case req.Minutes > 0:
+		t := now.Add(time.Duration(req.Minutes) * time.Minute)
+		until = &t
+	case !req.Until.IsZero():
+		if !req.Until.After(now) {
+			JSONError(w, "until must be in the future", http.StatusBadRequest)
+			return
+		}
+		until = &req.Until
+	}
+
+	if err := notify.SetGlobalPause(db.DB, until); err != nil {
+		log.Printf("❌ Pause notifications: %v", err)
+		JSONError(w, "Failed to pause notifications", http.StatusInternalServerError)
+		return
+	}
+
+	details := "until resumed"
+	if until != nil {
+		details = "until " + until.UTC().Format(time.RFC3339)
+	}
+	recordAudit(r, "notifications_pause", "notifications", "", details)
+	JSONResponse(w, notify.GetGlobalPause(db.DB))
+}
+
+// ResumeNotifications turns the global pause off.
+// POST /api/notifications/resume
+func ResumeNotifications(w http.ResponseWriter, r *http.Request) {
+	if err := notify.ClearGlobalPause(db.DB); err != nil {
+		log.Printf("❌ Resume notifications: %v", err)
+		JSONError(w, "Failed to resume notifications", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "notifications_resume", "notifications", "", "")
+	JSONResponse(w, notify.GetGlobalPause(db.DB))
+}
+
 // ─── Digest Config ───────────────────────────────────────────────────────
 
 // UpdateDigestConfig sets digest config for a service.
@@ -349,20 +538,74 @@ func UpdateDigestConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if s := auth.GetSessionFromContext(r); s != nil {
-		audit.LogEvent(db.DB, r, s.UserID, s.Username, "notification_digest_update", "notification_service", strconv.FormatInt(id, 10), "", "success")
+	recordAudit(r, "notification_digest_update", "notification_service", strconv.FormatInt(id, 10), "")
+	JSONResponse(w, map[string]string{"status": "updated"})
+}
+
+// ─── Daily Summary ───────────────────────────────────────────────────────
+
+// UpdateSummaryConfig enables or reschedules the daily "what changed"
+// summary for a service.
+// PUT /api/notifications/services/{id}/summary
+func UpdateSummaryConfig(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		JSONError(w, "Invalid service ID", http.StatusBadRequest)
+		return
+	}
+
+	var sc notify.SummaryConfig
+	if err := json.NewDecoder(r.Body).Decode(&sc); err != nil {
+		JSONError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	sc.ServiceID = id
+	if sc.SendAt == "" {
+		sc.SendAt = "08:00"
+	}
+	if _, err := time.Parse("15:04", sc.SendAt); err != nil {
+		JSONError(w, "send_at must be HH:MM", http.StatusBadRequest)
+		return
 	}
+
+	if err := notify.UpsertSummaryConfig(db.DB, &sc); err != nil {
+		log.Printf("❌ Upsert summary config: %v", err)
+		JSONError(w, "Failed to update summary config", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "notification_summary_update", "notification_service", strconv.FormatInt(id, 10), "")
 	JSONResponse(w, map[string]string{"status": "updated"})
 }
 
+// PreviewDailySummary returns the summary for the last 24 hours as it would
+// be sent right now, both structured and as the rendered message.
+// GET /api/notifications/summary/preview
+func PreviewDailySummary(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	s, err := summary.Build(db.DB, now.Add(-24*time.Hour), now)
+	if err != nil {
+		log.Printf("❌ Build daily summary: %v", err)
+		JSONError(w, "Failed to build summary", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, map[string]interface{}{
+		"summary": s,
+		"message": s.Format(),
+	})
+}
+
 // ─── Test Fire ───────────────────────────────────────────────────────────
 
 // TestFireNotification sends a test message through the given service.
+// With validate_only it checks the service instead of sending.
 // POST /api/notifications/test
 func TestFireNotification(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		ServiceID int64  `json:"service_id"`
-		Message   string `json:"message"`
+		ServiceID    int64  `json:"service_id"`
+		Message      string `json:"message"`
+		ValidateOnly bool   `json:"validate_only"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		JSONError(w, "Invalid JSON", http.StatusBadRequest)
@@ -388,6 +631,11 @@ func TestFireNotification(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.ValidateOnly {
+		validateResponse(w, cfg.ShoutrrrURL)
+		return
+	}
+
 	msg := req.Message
 	if msg == "" {
 		msg = "Vigil test notification from " + svc.Name
@@ -423,7 +671,9 @@ func TestFireNotification(w http.ResponseWriter, r *http.Request) {
 }
 
 // TestNotificationURL sends a test message to a Shoutrrr URL.
-// Accepts either a raw URL or structured config_fields.
+// Accepts either a raw URL or structured config_fields. With validate_only
+// the URL is checked (config and, where possible, reachability and
+// credentials) without delivering anything.
 // POST /api/notifications/test-url
 func TestNotificationURL(w http.ResponseWriter, r *http.Request) {
 	var req struct {
@@ -431,6 +681,7 @@ func TestNotificationURL(w http.ResponseWriter, r *http.Request) {
 		ServiceType  string            `json:"service_type"`
 		ConfigFields map[string]string `json:"config_fields"`
 		Message      string            `json:"message"`
+		ValidateOnly bool              `json:"validate_only"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		JSONError(w, "Invalid JSON", http.StatusBadRequest)
@@ -457,6 +708,11 @@ func TestNotificationURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.ValidateOnly {
+		validateResponse(w, testURL)
+		return
+	}
+
 	msg := req.Message
 	if msg == "" {
 		msg = "Vigil test notification"
@@ -482,10 +738,32 @@ func TestNotificationURL(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// validateResponse writes the result of checking a Shoutrrr URL without
+// sending to it.
+func validateResponse(w http.ResponseWriter, shoutrrrURL string) {
+	res := notify.ValidateURL(shoutrrrURL)
+	resp := map[string]interface{}{
+		"success":       res.OK(),
+		"validate_only": true,
+		"validation":    res,
+	}
+	if res.OK() {
+		resp["message"] = "Configuration is valid"
+		if res.Reachable != nil {
+			resp["message"] = "Configuration is valid and the service is reachable"
+		}
+	} else {
+		resp["error"] = res.Error
+	}
+	JSONResponse(w, resp)
+}
+
 // ─── History ─────────────────────────────────────────────────────────────
 
-// GetNotificationHistory returns recent notification records.
-// GET /api/notifications/history?limit=50
+// GetNotificationHistory returns recent notification records, optionally
+// only those of one cause category (thermal, media, interface, endurance,
+// pool or agent).
+// GET /api/notifications/history?limit=50&category=media
 func GetNotificationHistory(w http.ResponseWriter, r *http.Request) {
 	limit := settings.GetInt(db.DB, "retention", "notification_display_limit", 50)
 	if l := r.URL.Query().Get("limit"); l != "" {
@@ -493,8 +771,14 @@ func GetNotificationHistory(w http.ResponseWriter, r *http.Request) {
 			limit = n
 		}
 	}
+	category := r.URL.Query().Get("category")
+	if category != "" && !events.ValidCause(category) {
+		JSONError(w, "Unknown category: "+category, http.StatusBadRequest)
+		return
+	}
 
-	history, err := notify.RecentHistory(db.DB, limit)
+	// Project users only see notifications about their hosts
+	history, err := notify.RecentHistoryForHosts(db.DB, limit, category, requestScope(r).Hosts())
 	if err != nil {
 		log.Printf("❌ Notification history: %v", err)
 		JSONError(w, "Failed to get history", http.StatusInternalServerError)
@@ -523,7 +807,13 @@ func RegisterNotificationRoutes(mux *http.ServeMux, protect func(http.HandlerFun
 
 	mux.HandleFunc("PUT /api/notifications/services/{id}/rules", protect(UpdateEventRules))
 	mux.HandleFunc("PUT /api/notifications/services/{id}/quiet-hours", protect(UpdateQuietHours))
+	mux.HandleFunc("PUT /api/notifications/services/{id}/mute", protect(MuteNotificationService))
+	mux.HandleFunc("DELETE /api/notifications/services/{id}/mute", protect(UnmuteNotificationService))
+	mux.HandleFunc("POST /api/notifications/pause", protect(PauseNotifications))
+	mux.HandleFunc("POST /api/notifications/resume", protect(ResumeNotifications))
 	mux.HandleFunc("PUT /api/notifications/services/{id}/digest", protect(UpdateDigestConfig))
+	mux.HandleFunc("PUT /api/notifications/services/{id}/summary", protect(UpdateSummaryConfig))
+	mux.HandleFunc("GET /api/notifications/summary/preview", protect(PreviewDailySummary))
 
 	mux.HandleFunc("POST /api/notifications/test", protect(TestFireNotification))
 	mux.HandleFunc("POST /api/notifications/test-url", protect(TestNotificationURL))
@@ -0,0 +1,445 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"vigil/internal/db"
+	"vigil/internal/projects"
+	"vigil/internal/validate"
+)
+
+// ── Scoping ─────────────────────────────────────────────────────────────
+
+type scopeKey struct{}
+
+// projectRoutes are the routes open to users who belong to a project; every
+// other protected route is admin-only. Routes marked true return data from
+// every host unless the request names one with ?hostname=, so project users
+// must name one of theirs. Any {hostname} in the path or ?hostname= is
+// checked against the user's hosts; list routes filter their results.
+var projectRoutes = map[string]bool{
+	"GET /api/history":                                         false,
+	"GET /api/hosts":                                           false,
+	"GET /api/hosts/{hostname}/history":                        false,
+	"GET /api/aliases":                                         false,
+	"GET /api/drives":                                          false,
+	"GET /api/drives/{hostname}/{serial}/latency":              false,
+	"GET /api/drives/{hostname}/{serial}/trends":               false,
+	"GET /api/drives/{hostname}/{serial}/thermal-stress":       false,
+	"GET /api/drives/{hostname}/{serial}/health-history":       false,
+	"GET /api/drives/{hostname}/{serial}/alerts":               false,
+	"GET /api/drives/{hostname}/{serial}/temperature-sampling": false,
+	"GET /api/smart/attributes":                                true,
+	"GET /api/smart/attributes/history":                        true,
+	"GET /api/smart/attributes/trend":                          true,
+	"GET /api/smart/attributes/definitions":                    false,
+	"GET /api/smart/query":                                     true,
+	"GET /api/smart/health/summary":                            true,
+	"GET /api/smart/health/all":                                false,
+	"GET /api/smart/temperature/history":                       true,
+	"GET /api/wearout/drive":                                   true,
+	"GET /api/wearout/history":                                 true,
+	"GET /api/wearout/trend":                                   true,
+	"GET /api/zfs/drive/{hostname}/{serial}":                   false,
+	"GET /api/zfs/pools/{hostname}/{poolname}":                 false,
+	"GET /api/zfs/pools/{hostname}/{poolname}/devices":         false,
+	"GET /api/zfs/pools/{hostname}/{poolname}/scrubs":          false,
+	"GET /api/zfs/pools/{hostname}/{poolname}/iostat":          false,
+	"GET /api/notifications/event-types":                       false,
+	"GET /api/notifications/history":                           false,
+	"GET /api/projects":                                        false,
+	"GET /api/projects/{id}":                                   false,
+	"GET /api/users/me":                                        false,
+	"GET /api/users/me/sessions":                               false,
+	"DELETE /api/users/me/sessions/{token}":                    false,
+	"POST /api/users/password":                                 false,
+	"POST /api/users/username":                                 false,
+}
+
+// ProjectScope limits users who belong to a project to projectRoutes and
+// their projects' hosts, and stores their scope for the handler (see
+// requestScope). It runs after auth.Middleware; requests without a session
+// (authentication disabled) are unrestricted.
+func ProjectScope(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := GetSessionFromContext(r)
+		if session == nil {
+			next(w, r)
+			return
+		}
+
+		scope, err := projects.ForUser(db.DB, int64(session.UserID))
+		if err != nil {
+			log.Printf("❌ Load project scope for user %d: %v", session.UserID, err)
+			JSONError(w, "Failed to load project access", http.StatusInternalServerError)
+			return
+		}
+		if scope.IsAdmin() {
+			next(w, r)
+			return
+		}
+
+		needsHost, open := projectRoutes[r.Pattern]
+		if !open {
+			JSONError(w, "Not available to project users", http.StatusForbidden)
+			return
+		}
+		hostname := r.PathValue("hostname")
+		if hostname == "" {
+			hostname = r.URL.Query().Get("hostname")
+		}
+		if hostname == "" && needsHost {
+			JSONError(w, "hostname is required", http.StatusBadRequest)
+			return
+		}
+		if hostname != "" && !scope.AllowsHost(hostname) {
+			JSONError(w, "Host not found", http.StatusNotFound)
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), scopeKey{}, scope)))
+	}
+}
+
+// requestScope returns the project scope ProjectScope stored for the
+// request; nil (an admin, or no authentication) allows every host.
+func requestScope(r *http.Request) *projects.Scope {
+	scope, _ := r.Context().Value(scopeKey{}).(*projects.Scope)
+	return scope
+}
+
+// ── Project CRUD ────────────────────────────────────────────────────────
+
+// projectRequest is the body of create and update requests.
+type projectRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// decodeProject reads and validates a project request, answering the
+// client itself when it's invalid.
+func decodeProject(w http.ResponseWriter, r *http.Request) (*projectRequest, bool) {
+	var req projectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, "Invalid JSON", http.StatusBadRequest)
+		return nil, false
+	}
+	if err := validate.Name(req.Name, 64); err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if len(req.Description) > 256 {
+		JSONError(w, "description must be at most 256 characters", http.StatusBadRequest)
+		return nil, false
+	}
+	return &req, true
+}
+
+// ListProjects returns all projects, or a project user's own.
+func ListProjects(w http.ResponseWriter, r *http.Request) {
+	list, err := projects.ListProjects(db.DB)
+	if err != nil {
+		log.Printf("❌ List projects: %v", err)
+		JSONError(w, "Failed to list projects", http.StatusInternalServerError)
+		return
+	}
+
+	scope := requestScope(r)
+	visible := []projects.Project{}
+	for _, p := range list {
+		if scope.AllowsProject(p.ID) {
+			visible = append(visible, p)
+		}
+	}
+	JSONResponse(w, visible)
+}
+
+// CreateProject creates a new project.
+func CreateProject(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeProject(w, r)
+	if !ok {
+		return
+	}
+
+	p := &projects.Project{Name: req.Name, Description: req.Description}
+	id, err := projects.CreateProject(db.DB, p)
+	if err != nil {
+		JSONError(w, "Project name already exists", http.StatusConflict)
+		return
+	}
+	if created, err := projects.GetProject(db.DB, id); err == nil && created != nil {
+		p = created
+	} else {
+		p.ID = id
+	}
+	log.Printf("📁 Project created: %s", req.Name)
+	recordAudit(r, "project_create", "project", strconv.FormatInt(id, 10), req.Name)
+	w.WriteHeader(http.StatusCreated)
+	JSONResponse(w, p)
+}
+
+// GetProject returns a project with its hosts, users and notification
+// services. Project users only see their own projects.
+func GetProject(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		JSONError(w, "Invalid project ID", http.StatusBadRequest)
+		return
+	}
+
+	p, err := projects.GetProject(db.DB, id)
+	if err != nil {
+		JSONError(w, "Failed to get project", http.StatusInternalServerError)
+		return
+	}
+	if p == nil || !requestScope(r).AllowsProject(id) {
+		JSONError(w, "Project not found", http.StatusNotFound)
+		return
+	}
+
+	hosts, err := projects.ListHosts(db.DB, id)
+	if err != nil {
+		JSONError(w, "Failed to list project hosts", http.StatusInternalServerError)
+		return
+	}
+	users, err := projects.ListUsers(db.DB, id)
+	if err != nil {
+		JSONError(w, "Failed to list project users", http.StatusInternalServerError)
+		return
+	}
+	services, err := projects.ListServices(db.DB, id)
+	if err != nil {
+		JSONError(w, "Failed to list project services", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, map[string]interface{}{
+		"project":     p,
+		"hosts":       hosts,
+		"users":       users,
+		"service_ids": services,
+	})
+}
+
+// UpdateProject updates a project's name and description.
+func UpdateProject(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		JSONError(w, "Invalid project ID", http.StatusBadRequest)
+		return
+	}
+	req, ok := decodeProject(w, r)
+	if !ok {
+		return
+	}
+
+	if p, err := projects.GetProject(db.DB, id); err != nil || p == nil {
+		JSONError(w, "Project not found", http.StatusNotFound)
+		return
+	}
+	if err := projects.UpdateProject(db.DB, &projects.Project{ID: id, Name: req.Name, Description: req.Description}); err != nil {
+		JSONError(w, "Project name already exists", http.StatusConflict)
+		return
+	}
+	recordAudit(r, "project_update", "project", strconv.FormatInt(id, 10), req.Name)
+	JSONResponse(w, map[string]string{"status": "updated"})
+}
+
+// DeleteProject removes a project (cascade deletes its host, user and
+// service links).
+func DeleteProject(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		JSONError(w, "Invalid project ID", http.StatusBadRequest)
+		return
+	}
+	if err := projects.DeleteProject(db.DB, id); err != nil {
+		JSONError(w, "Failed to delete project", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("🗑️ Project deleted: id=%d", id)
+	recordAudit(r, "project_delete", "project", strconv.FormatInt(id, 10), "")
+	JSONResponse(w, map[string]string{"status": "deleted"})
+}
+
+// ── Hosts, Users & Services ─────────────────────────────────────────────
+
+// projectFromPath parses the {id} path value and checks the project
+// exists, answering the client itself when it doesn't.
+func projectFromPath(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		JSONError(w, "Invalid project ID", http.StatusBadRequest)
+		return 0, false
+	}
+	p, err := projects.GetProject(db.DB, id)
+	if err != nil {
+		JSONError(w, "Failed to get project", http.StatusInternalServerError)
+		return 0, false
+	}
+	if p == nil {
+		JSONError(w, "Project not found", http.StatusNotFound)
+		return 0, false
+	}
+	return id, true
+}
+
+// AssignHostToProject puts a host in a project, moving it out of any
+// other one.
+func AssignHostToProject(w http.ResponseWriter, r *http.Request) {
+	id, ok := projectFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Hostname string `json:"hostname"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	req.Hostname = strings.TrimSpace(req.Hostname)
+	if err := validate.Hostname(req.Hostname); err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := projects.AssignHost(db.DB, id, req.Hostname); err != nil {
+		JSONError(w, "Failed to assign host", http.StatusInternalServerError)
+		return
+	}
+	recordAudit(r, "project_assign_host", "project", strconv.FormatInt(id, 10), req.Hostname)
+	JSONResponse(w, map[string]string{"status": "assigned"})
+}
+
+// UnassignHostFromProject takes a host out of its project.
+func UnassignHostFromProject(w http.ResponseWriter, r *http.Request) {
+	hostname := r.PathValue("hostname")
+	removed, err := projects.UnassignHost(db.DB, hostname)
+	if err != nil {
+		JSONError(w, "Failed to unassign host", http.StatusInternalServerError)
+		return
+	}
+	if !removed {
+		JSONError(w, "Host is not in a project", http.StatusNotFound)
+		return
+	}
+	recordAudit(r, "project_unassign_host", "host", hostname, "")
+	JSONResponse(w, map[string]string{"status": "unassigned"})
+}
+
+// AddUserToProject makes a user, by username, a member of a project. From
+// then on the user only sees the hosts of their projects.
+func AddUserToProject(w http.ResponseWriter, r *http.Request) {
+	id, ok := projectFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Username string `json:"username"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	var userID int64
+	err := db.DB.QueryRow("SELECT id FROM users WHERE username = ?", req.Username).Scan(&userID)
+	if err == sql.ErrNoRows {
+		JSONError(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		JSONError(w, "Failed to look up user", http.StatusInternalServerError)
+		return
+	}
+	if session := GetSessionFromContext(r); session != nil && int64(session.UserID) == userID {
+		JSONError(w, "You can't add yourself to a project: you would lose admin access", http.StatusBadRequest)
+		return
+	}
+
+	if err := projects.AddUser(db.DB, id, userID); err != nil {
+		JSONError(w, "Failed to add user", http.StatusInternalServerError)
+		return
+	}
+	recordAudit(r, "project_add_user", "project", strconv.FormatInt(id, 10), req.Username)
+	JSONResponse(w, map[string]interface{}{"status": "added", "user_id": userID})
+}
+
+// RemoveUserFromProject takes a user out of a project. A user left in no
+// project sees no hosts; only PUT /api/users/{id}/admin makes them an admin.
+func RemoveUserFromProject(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		JSONError(w, "Invalid project ID", http.StatusBadRequest)
+		return
+	}
+	userID, err := parseID(r, "userId")
+	if err != nil {
+		JSONError(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	removed, err := projects.RemoveUser(db.DB, id, userID)
+	if err != nil {
+		JSONError(w, "Failed to remove user", http.StatusInternalServerError)
+		return
+	}
+	if !removed {
+		JSONError(w, "User is not in this project", http.StatusNotFound)
+		return
+	}
+	recordAudit(r, "project_remove_user", "project", strconv.FormatInt(id, 10), strconv.FormatInt(userID, 10))
+	JSONResponse(w, map[string]string{"status": "removed"})
+}
+
+// SetProjectServices replaces the notification services that receive the
+// project's events. Linked services stop hearing about hosts outside their
+// projects.
+func SetProjectServices(w http.ResponseWriter, r *http.Request) {
+	id, ok := projectFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		ServiceIDs []int64 `json:"service_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := projects.SetServices(db.DB, id, req.ServiceIDs); err != nil {
+		// Unknown service IDs fail the foreign key
+		JSONError(w, "Failed to set services: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	recordAudit(r, "project_set_services", "project", strconv.FormatInt(id, 10), "")
+	JSONResponse(w, map[string]string{"status": "updated"})
+}
+
+// ── Route Registration ──────────────────────────────────────────────────
+
+// RegisterProjectRoutes registers project API routes. Everything but the
+// two GET routes is admin-only (see projectRoutes).
+func RegisterProjectRoutes(mux *http.ServeMux, protect func(http.HandlerFunc) http.HandlerFunc) {
+	mux.HandleFunc("GET /api/projects", protect(ListProjects))
+	mux.HandleFunc("POST /api/projects", protect(CreateProject))
+	mux.HandleFunc("GET /api/projects/{id}", protect(GetProject))
+	mux.HandleFunc("PUT /api/projects/{id}", protect(UpdateProject))
+	mux.HandleFunc("DELETE /api/projects/{id}", protect(DeleteProject))
+	mux.HandleFunc("POST /api/projects/{id}/hosts", protect(AssignHostToProject))
+	mux.HandleFunc("DELETE /api/projects/hosts/{hostname}", protect(UnassignHostFromProject))
+	mux.HandleFunc("POST /api/projects/{id}/users", protect(AddUserToProject))
+	mux.HandleFunc("DELETE /api/projects/{id}/users/{userId}", protect(RemoveUserFromProject))
+	mux.HandleFunc("PUT /api/projects/{id}/services", protect(SetProjectServices))
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"vigil/internal/validate"
+)
+
+const (
+	// maxBulkReportSize caps a bulk upload; a full report for a host with
+	// a few dozen drives is a few hundred KiB.
+	maxBulkReportSize = 64 << 20
+	// maxBulkReports caps the number of reports in one bulk upload.
+	maxBulkReports = 1000
+	// bulkReportClockSkew is how far in the future a report's timestamp
+	// may be, to allow for an air-gapped host's clock drifting.
+	bulkReportClockSkew = 5 * time.Minute
+)
+
+// BulkReportResult is the outcome of one report of a bulk upload.
+type BulkReportResult struct {
+	Index           int    `json:"index"`
+	Hostname        string `json:"hostname,omitempty"`
+	Timestamp       string `json:"timestamp,omitempty"`
+	Status          string `json:"status"` // imported, failed
+	Error           string `json:"error,omitempty"`
+	IngestionErrors int    `json:"ingestion_errors,omitempty"`
+}
+
+// ImportReports ingests agent reports collected where the agent can't
+// reach the server, e.g. air-gapped hosts whose reports are carried over
+// by hand. The body is a JSON array of full agent reports, each with its
+// own hostname and RFC 3339 timestamp. Reports go through the normal
+// ingestion pipeline in timestamp order and are stored at their
+// timestamps, and so is the history derived from them (SMART attributes,
+// temperatures, health changes, wearout, latency, ZFS usage and I/O). They don't update the agent's last-seen time,
+// publish events (so send no notifications), raise temperature alerts or
+// fire the emergency temperature webhook, and aren't rate limited; run
+// POST /api/maintenance/reevaluate to assess them.
+// Delta reports can't be imported. Results are in request order.
+// POST /api/reports/bulk
+func ImportReports(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBulkReportSize)
+	var reports []map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&reports); err != nil {
+		JSONError(w, "Body must be a JSON array of agent reports: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(reports) == 0 {
+		JSONError(w, "No reports to import", http.StatusBadRequest)
+		return
+	}
+	if len(reports) > maxBulkReports {
+		JSONError(w, fmt.Sprintf("Too many reports (%d); at most %d per upload", len(reports), maxBulkReports), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	results := make([]BulkReportResult, len(reports))
+	collected := make([]time.Time, len(reports))
+	var order []int
+	now := time.Now()
+	for i, report := range reports {
+		res := &results[i]
+		res.Index = i
+		res.Status = "failed"
+		if report == nil {
+			res.Error = "report must be a JSON object"
+			continue
+		}
+		hostname, _ := report["hostname"].(string)
+		res.Hostname = hostname
+		if err := validate.Hostname(hostname); err != nil {
+			res.Error = err.Error()
+			continue
+		}
+		raw, _ := report["timestamp"].(string)
+		res.Timestamp = raw
+		ts, err := time.Parse(time.RFC3339, strings.TrimSpace(raw))
+		switch {
+		case raw == "":
+			res.Error = "missing timestamp"
+			continue
+		case err != nil:
+			res.Error = "timestamp must be RFC 3339: " + err.Error()
+			continue
+		case ts.After(now.Add(bulkReportClockSkew)):
+			res.Error = "timestamp is in the future"
+			continue
+		}
+		if hasDeltaStubs(report) {
+			res.Error = "delta reports can't be imported; collect full reports"
+			continue
+		}
+		collected[i] = ts
+		order = append(order, i)
+	}
+
+	// Ingest oldest first so each host's latest report ends up the one
+	// collected last, whatever order the files were gathered in.
+	sort.SliceStable(order, func(a, b int) bool { return collected[order[a]].Before(collected[order[b]]) })
+
+	imported := 0
+	for _, i := range order {
+		res := &results[i]
+		parseErrors, _, rerr := ingestReportFrom(reports[i], 0, collected[i], false)
+		if rerr != nil {
+			res.Error = rerr.msg
+			continue
+		}
+		res.Status = "imported"
+		res.IngestionErrors = parseErrors
+		imported++
+	}
+	failed := len(reports) - imported
+
+	log.Printf("📥 Bulk report import: %d imported, %d failed", imported, failed)
+	recordAudit(r, "report_bulk_import", "import", "", fmt.Sprintf("%d reports imported, %d failed", imported, failed))
+
+	JSONResponse(w, map[string]interface{}{
+		"imported": imported,
+		"failed":   failed,
+		"results":  results,
+	})
+}
+
+// hasDeltaStubs reports whether any of report's drives is a delta stub.
+func hasDeltaStubs(report map[string]interface{}) bool {
+	drives, _ := report["drives"].([]interface{})
+	for _, d := range drives {
+		if isDeltaStub(d) {
+			return true
+		}
+	}
+	return false
+}
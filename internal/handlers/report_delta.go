@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+
+	"vigil/internal/crypto"
+	"vigil/internal/db"
+)
+
+// deltaStubKey marks a drive a delta report (agent --delta-reports) sent
+// as a stub: {"serial_number": ..., "_unchanged": true, "temperature": ...}.
+// Its attributes haven't changed since the agent last sent it in full.
+const deltaStubKey = "_unchanged"
+
+// mergeDeltaDrives replaces every stub in payload's drives with the same
+// drive from the host's last stored report, carrying over the stub's
+// temperature, so the stored report is complete again. It returns false
+// when a stub has nothing to merge with (the server lost the earlier
+// report, or never had it); such stubs are dropped and the agent is asked
+// for a full report.
+func mergeDeltaDrives(hostname string, payload map[string]interface{}) bool {
+	drives, _ := payload["drives"].([]interface{})
+	stubs := 0
+	for _, d := range drives {
+		if isDeltaStub(d) {
+			stubs++
+		}
+	}
+	if stubs == 0 {
+		return true
+	}
+
+	previous, err := lastReportDrives(hostname)
+	if err != nil {
+		log.Printf("⚠️  Report: %s: load previous report for delta merge: %v", hostname, err)
+	}
+
+	complete := true
+	merged := make([]interface{}, 0, len(drives))
+	for _, d := range drives {
+		if !isDeltaStub(d) {
+			merged = append(merged, d)
+			continue
+		}
+		stub := d.(map[string]interface{})
+		serial, _ := stub["serial_number"].(string)
+		full, ok := previous[serial]
+		if !ok {
+			complete = false
+			continue
+		}
+		if temp, ok := stub["temperature"]; ok {
+			full["temperature"] = temp
+		}
+		merged = append(merged, full)
+	}
+	payload["drives"] = merged
+
+	if !complete {
+		log.Printf("⚠️  Report: %s sent unchanged drives with no earlier report to merge; asking for a full report", hostname)
+	}
+	return complete
+}
+
+func isDeltaStub(d interface{}) bool {
+	m, ok := d.(map[string]interface{})
+	return ok && m[deltaStubKey] == true
+}
+
+// lastReportDrives returns the drives of the host's latest stored report
+// by serial number. Latest is by collection time: an imported report can be
+// stored after a newer live one.
+func lastReportDrives(hostname string) (map[string]map[string]interface{}, error) {
+	var data []byte
+	err := db.DB.QueryRow("SELECT data FROM reports WHERE hostname = ? ORDER BY timestamp DESC, id DESC LIMIT 1", hostname).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err == nil {
+		data, err = crypto.OpenReport(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var report struct {
+		Drives []map[string]interface{} `json:"drives"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	drives := make(map[string]map[string]interface{}, len(report.Drives))
+	for _, d := range report.Drives {
+		if serial, _ := d["serial_number"].(string); serial != "" {
+			drives[serial] = d
+		}
+	}
+	return drives, nil
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"vigil/internal/agents"
+	"vigil/internal/db"
+	"vigil/internal/reportpb"
+)
+
+// fullReportHeader is the gRPC response header that asks a delta-reporting
+// agent for a full report, like full_report_required over HTTP.
+const fullReportHeader = "x-vigil-full-report"
+
+// maxGRPCReportSize matches the request body limit of the HTTP server.
+const maxGRPCReportSize = 1 << 20
+
+// NewReportGRPCServer returns a gRPC server offering ReportService, the
+// binary alternative to POST /api/report. Reports go through the same
+// checks and storage as the HTTP handler.
+func NewReportGRPCServer() *grpc.Server {
+	s := grpc.NewServer(grpc.MaxRecvMsgSize(maxGRPCReportSize))
+	reportpb.RegisterReportServiceServer(s, reportService{})
+	return s
+}
+
+type reportService struct {
+	reportpb.UnimplementedReportServiceServer
+}
+
+// Submit is Report over gRPC. The session token comes from the
+// "authorization" metadata; with REPORT_HMAC_SECRET set, "x-vigil-signature"
+// must sign the deterministic protobuf encoding of the report.
+func (reportService) Submit(ctx context.Context, rep *reportpb.DriveReport) (*reportpb.ReportResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var session *agents.AgentSession
+	if token, ok := strings.CutPrefix(firstMetadata(md, "authorization"), "Bearer "); ok {
+		session, _ = agents.GetAgentSession(db.DB, token)
+	}
+	if session == nil {
+		return nil, status.Error(codes.Unauthenticated, "Agent authentication required — obtain a session token via POST /api/v1/agents/auth")
+	}
+
+	if len(ReportHMACSecret) > 0 {
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(rep)
+		if err != nil || !validReportSignature(body, firstMetadata(md, strings.ToLower(ReportSignatureHeader))) {
+			return nil, status.Error(codes.PermissionDenied, "Invalid report signature")
+		}
+	}
+
+	parseErrors, fullReportRequired, rerr := ingestReport(session.AgentID, reportPayload(rep))
+	if rerr != nil {
+		if rerr.retryAfter > 0 {
+			grpc.SetTrailer(ctx, metadata.Pairs("retry-after", fmt.Sprint(int(rerr.retryAfter.Seconds())+1)))
+		}
+		return nil, status.Error(grpcCode(rerr.status), rerr.msg)
+	}
+	// ReportResponse predates delta reports; the request for a full one
+	// travels as a header instead.
+	if fullReportRequired {
+		grpc.SetHeader(ctx, metadata.Pairs(fullReportHeader, "true")) //nolint:errcheck
+	}
+
+	return &reportpb.ReportResponse{
+		Status:                "ok",
+		ReportIntervalSeconds: int32(agentReportInterval()),
+		IngestionErrors:       int32(parseErrors),
+		SchemaVersion:         CurrentReportSchema,
+	}, nil
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// grpcCode maps the HTTP status ingestReport answers with to a gRPC code.
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnprocessableEntity:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	default:
+		return codes.Internal
+	}
+}
+
+// reportPayload converts a gRPC report into the map the JSON report decodes
+// to, omitting the same empty fields the agent's JSON does, so both
+// transports store identical data.
+func reportPayload(rep *reportpb.DriveReport) map[string]interface{} {
+	drives := make([]interface{}, 0, len(rep.GetDrives()))
+	for _, d := range rep.GetDrives() {
+		drives = append(drives, d.AsMap())
+	}
+	payload := map[string]interface{}{
+		"hostname":      rep.GetHostname(),
+		"agent_version": rep.GetAgentVersion(),
+		"drives":        drives,
+	}
+	// A zero schema_version is an agent that didn't set one: version 1.
+	if v := rep.GetSchemaVersion(); v != 0 {
+		payload["schema_version"] = float64(v)
+	}
+	if ts := rep.GetTimestamp(); ts != nil {
+		payload["timestamp"] = ts.AsTime().Format(time.RFC3339Nano)
+	}
+	if v := rep.GetSmartctlVersion(); v != "" {
+		payload["smartctl_version"] = v
+	}
+	if z := rep.GetZfs(); z != nil {
+		payload["zfs"] = z.AsMap()
+	}
+	if c := rep.GetCapabilities(); c != nil {
+		caps := map[string]interface{}{
+			"led_identify":  c.GetLedIdentify(),
+			"latency_probe": c.GetLatencyProbe(),
+			"zfs_clear":     c.GetZfsClear(),
+		}
+		if c.GetListenAddr() != "" {
+			caps["listen_addr"] = c.GetListenAddr()
+		}
+		payload["capabilities"] = caps
+	}
+	if len(rep.GetLabels()) > 0 {
+		labels := make(map[string]interface{}, len(rep.GetLabels()))
+		for k, v := range rep.GetLabels() {
+			labels[k] = v
+		}
+		payload["labels"] = labels
+	}
+	if rep.GetMaintenance() {
+		payload["maintenance"] = true
+	}
+	return payload
+}
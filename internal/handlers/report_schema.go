@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Report payload schema versions. Agents send schema_version with every
+// report; reports without one come from agents that predate it.
+const (
+	// ReportSchemaV1 is everything before schema_version: only hostname is
+	// guaranteed, drives may be null and smartctl_version is only found
+	// inside each drive.
+	ReportSchemaV1 = 1
+	// ReportSchemaV2 adds schema_version and the top-level
+	// smartctl_version, and types labels, capabilities, maintenance and
+	// zfs strictly.
+	ReportSchemaV2 = 2
+
+	// CurrentReportSchema is the newest version this server understands.
+	// Reports are stored upgraded to it.
+	CurrentReportSchema = ReportSchemaV2
+)
+
+// reportUpgrades bring a payload of the key's version up to the next one.
+var reportUpgrades = map[int]func(payload map[string]interface{}){
+	ReportSchemaV1: upgradeReportV1,
+}
+
+// reportSchemaError is why the server can't accept a report, with the
+// status to answer it with.
+type reportSchemaError struct {
+	status int
+	msg    string
+}
+
+// normalizeReport validates a report against the schema version it declares
+// and upgrades it in place to CurrentReportSchema. It returns the version
+// the agent sent. Reports from a newer agent than the server are refused
+// rather than half-understood.
+func normalizeReport(payload map[string]interface{}) (int, *reportSchemaError) {
+	version := ReportSchemaV1
+	if raw, ok := payload["schema_version"]; ok && raw != nil {
+		f, isNum := raw.(float64)
+		if !isNum || f != float64(int(f)) || f < ReportSchemaV1 {
+			return 0, &reportSchemaError{http.StatusBadRequest, fmt.Sprintf("Invalid schema_version %v", raw)}
+		}
+		version = int(f)
+	}
+	if version > CurrentReportSchema {
+		return version, &reportSchemaError{http.StatusUnprocessableEntity, fmt.Sprintf(
+			"Report schema_version %d is newer than this server supports (%d); upgrade the Vigil server or run an older agent",
+			version, CurrentReportSchema)}
+	}
+
+	if err := validateReport(payload, version); err != nil {
+		return version, &reportSchemaError{http.StatusBadRequest, err.Error()}
+	}
+	for v := version; v < CurrentReportSchema; v++ {
+		reportUpgrades[v](payload)
+	}
+	payload["schema_version"] = CurrentReportSchema
+	return version, nil
+}
+
+// validateReport checks the types of a report's top-level fields. Version 1
+// agents only promised hostname, so anything else they sent that doesn't fit
+// is dropped rather than refused.
+func validateReport(payload map[string]interface{}, version int) error {
+	fields := []struct {
+		key   string
+		valid func(interface{}) bool
+	}{
+		{"drives", func(v interface{}) bool { _, ok := v.([]interface{}); return ok }},
+		{"zfs", isObject},
+		{"capabilities", isObject},
+		{"labels", isObject},
+		{"maintenance", func(v interface{}) bool { _, ok := v.(bool); return ok }},
+		{"smartctl_version", func(v interface{}) bool { _, ok := v.(string); return ok }},
+		{"agent_version", func(v interface{}) bool { _, ok := v.(string); return ok }},
+	}
+	for _, f := range fields {
+		v, ok := payload[f.key]
+		if !ok || v == nil || f.valid(v) {
+			continue
+		}
+		if version == ReportSchemaV1 {
+			delete(payload, f.key)
+			continue
+		}
+		return fmt.Errorf("Invalid report: %q has the wrong type for schema_version %d", f.key, version)
+	}
+	return nil
+}
+
+func isObject(v interface{}) bool {
+	_, ok := v.(map[string]interface{})
+	return ok
+}
+
+// upgradeReportV1 fills in what version 2 agents always send: a drives
+// array and the top-level smartctl version.
+func upgradeReportV1(payload map[string]interface{}) {
+	if _, ok := payload["drives"].([]interface{}); !ok {
+		payload["drives"] = []interface{}{}
+	}
+	if _, ok := payload["smartctl_version"]; !ok {
+		if v := reportSmartctlVersion(payload); v != "" {
+			payload["smartctl_version"] = v
+		}
+	}
+}
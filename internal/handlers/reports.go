@@ -1,32 +1,48 @@
 package handlers
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
-
+	agentsmart "vigil/cmd/agent/smart"
 	"vigil/internal/agents"
-	"vigil/internal/audit"
-	"vigil/internal/auth"
+	"vigil/internal/crypto"
 	"vigil/internal/db"
+	"vigil/internal/drivename"
+	"vigil/internal/enclosures"
+	"vigil/internal/latency"
+	"vigil/internal/relocation"
 	"vigil/internal/settings"
 	"vigil/internal/smart"
+	"vigil/internal/temperature"
 	"vigil/internal/validate"
 	"vigil/internal/wearout"
 )
 
 // reportWork is a unit of background processing enqueued after the HTTP
 // response has been sent.  Processing is serialised through a single worker
-// goroutine so concurrent SMART / wearout / ZFS writes never compete for
-// the SQLite write lock and cannot starve dashboard reads.
+// goroutine, and holds db.Write so the handler's own insert and the
+// retention sweep queue behind it rather than contending in SQLite.
 type reportWork struct {
 	hostname string
 	agentID  int64
 	payload  map[string]interface{}
+	// collectedAt is set for imported reports (see ImportReports): when
+	// the report was taken. Zero for live reports.
+	collectedAt time.Time
+	// driveOnly marks an import of single drives (see ImportSmartctl):
+	// it feeds the drives' history but says nothing about the rest of the
+	// host, so host-wide state is left alone.
+	driveOnly bool
 }
 
 // reportQueue buffers pending background work.  The buffer is generous so
@@ -80,7 +96,7 @@ func init() {
 
 func reportWorker() {
 	for w := range reportQueue {
-		func() {
+		db.Write(func() error {
 			start := time.Now()
 			defer func() {
 				if r := recover(); r != nil {
@@ -88,15 +104,20 @@ func reportWorker() {
 				}
 			}()
 
-			if err := agents.UpdateAgentLastSeen(db.DB, w.agentID); err != nil {
-				log.Printf("⚠️  Failed to update last_seen_at for agent %d: %v", w.agentID, err)
-			}
-			if err := agents.UpdateAgentLastSeenByHostname(db.DB, w.hostname); err != nil {
-				log.Printf("⚠️  Failed to update agent status by hostname %s: %v", w.hostname, err)
+			// An imported report says nothing about whether the agent is
+			// reachable now.
+			imported := !w.collectedAt.IsZero()
+			if !imported {
+				if err := agents.UpdateAgentLastSeen(db.DB, w.agentID); err != nil {
+					log.Printf("⚠️  Failed to update last_seen_at for agent %d: %v", w.agentID, err)
+				}
+				if err := agents.UpdateAgentLastSeenByHostname(db.DB, w.hostname); err != nil {
+					log.Printf("⚠️  Failed to update agent status by hostname %s: %v", w.hostname, err)
+				}
 			}
 
 			// Extract and persist agent capabilities (LED identify, listen address).
-			if caps, ok := w.payload["capabilities"].(map[string]interface{}); ok {
+			if caps, ok := w.payload["capabilities"].(map[string]interface{}); ok && !imported {
 				listenAddr, _ := caps["listen_addr"].(string)
 				capsJSON, _ := json.Marshal(caps)
 				if err := agents.UpdateAgentCapabilities(db.DB, w.hostname, listenAddr, string(capsJSON)); err != nil {
@@ -104,21 +125,120 @@ func reportWorker() {
 				}
 			}
 
-			wearout.ProcessWearoutFromReport(db.DB, EventBus, w.hostname, w.payload)
-			smart.ProcessReportWithEvents(db.DB, EventBus, w.hostname, w.payload)
+			if !w.driveOnly {
+				if err := agents.SetHostLabels(db.DB, w.hostname, reportLabels(w.payload)); err != nil {
+					log.Printf("⚠️  Failed to update labels for %s: %v", w.hostname, err)
+				}
+
+				if version := reportSmartctlVersion(w.payload); version != "" {
+					if err := agents.UpdateAgentSmartctlVersion(db.DB, w.hostname, version); err != nil {
+						log.Printf("⚠️  Failed to update smartctl version for %s: %v", w.hostname, err)
+					}
+				}
 
-			if _, ok := w.payload["zfs"].(map[string]interface{}); ok {
-				ProcessZFSFromReport(w.hostname, w.payload)
+				// Before any event-producing processing, so alerts from this
+				// very report already honour the agent's maintenance flag.
+				maintenance, _ := w.payload["maintenance"].(bool)
+				if changed, err := agents.SetAgentMaintenance(db.DB, w.hostname, maintenance); err != nil {
+					log.Printf("⚠️  Failed to update maintenance state for %s: %v", w.hostname, err)
+				} else if changed && maintenance {
+					log.Printf("🔧 %s entered maintenance: alerts suppressed until its agent clears the flag", w.hostname)
+				} else if changed {
+					log.Printf("🔧 %s left maintenance: alerts resumed", w.hostname)
+				}
+			}
+
+			// Imported readings are history, not news: they raise no
+			// events, notifications or temperature alerts.
+			bus := EventBus
+			if imported {
+				bus = nil
+			}
+			wearout.ProcessWearoutFromReportAt(db.DB, bus, w.hostname, w.payload, w.collectedAt)
+			smart.ProcessReportAt(db.DB, bus, w.hostname, w.payload, w.collectedAt)
+			latency.ProcessReportAt(db.DB, w.hostname, w.payload, w.collectedAt)
+			if !imported {
+				temperature.ProcessReport(db.DB, bus, w.hostname, w.payload)
+			}
+			if !w.driveOnly {
+				relocation.ProcessReport(db.DB, bus, w.hostname, w.payload, relocationWindow())
+				enclosures.ProcessReport(db.DB, bus, w.hostname)
+
+				if _, ok := w.payload["zfs"].(map[string]interface{}); ok {
+					ProcessZFSFromReport(bus, w.hostname, w.payload, w.collectedAt)
+				}
 			}
 
 			if Metrics != nil {
 				Metrics.ReportsProcessed.Add(1)
 				Metrics.RecordReportLatency(time.Since(start))
 			}
-		}()
+			return nil
+		})
+		aggregates.invalidate()
 	}
 }
 
+// reportSmartctlVersion returns the smartctl version a report was collected
+// with. Agents that predate the top-level field still carry it in each
+// drive's own "smartctl" block.
+func reportSmartctlVersion(payload map[string]interface{}) string {
+	if v, ok := payload["smartctl_version"].(string); ok && v != "" {
+		return v
+	}
+	drives, _ := payload["drives"].([]interface{})
+	for _, d := range drives {
+		if dm, ok := d.(map[string]interface{}); ok {
+			if v := agentsmart.SmartctlVersion(dm); v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// relocationWindow is how long a drive may be missing from one host before
+// turning up on another and still count as the same drive being moved.
+func relocationWindow() time.Duration {
+	hours := settings.GetInt(db.DB, "drives", "relocation_window_hours", relocation.DefaultWindowHours)
+	return time.Duration(hours) * time.Hour
+}
+
+// ReportSignatureHeader carries "sha256=<hex>", the HMAC-SHA256 of the raw
+// report body keyed with REPORT_HMAC_SECRET.
+const ReportSignatureHeader = "X-Vigil-Signature"
+
+// ReportHMACSecret is set from main.go when REPORT_HMAC_SECRET is configured.
+// Empty disables signature checks.
+var ReportHMACSecret []byte
+
+// validReportSignature checks the X-Vigil-Signature header against the
+// HMAC of body in constant time.
+func validReportSignature(body []byte, header string) bool {
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(got, reportMAC(body))
+}
+
+// signAgentCommand returns the X-Vigil-Signature value for a command sent
+// to an agent's command server, which checks it with the same secret.
+func signAgentCommand(body []byte) string {
+	return "sha256=" + hex.EncodeToString(reportMAC(body))
+}
+
+// reportMAC is the HMAC-SHA256 of body keyed with REPORT_HMAC_SECRET.
+func reportMAC(body []byte) []byte {
+	mac := hmac.New(sha256.New, ReportHMACSecret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
 // Report handles incoming agent reports.
 // Requires a valid agent session token: Authorization: Bearer <token>
 // allowedAgentIntervals are the report-interval presets (seconds) agents may
@@ -142,6 +262,69 @@ func agentReportInterval() int {
 	return v
 }
 
+// defaultMinReportInterval is the shortest gap (seconds) allowed between two
+// accepted reports from the same host. Well under the smallest interval preset,
+// so a healthy agent that restarts or re-auths never trips it.
+const defaultMinReportInterval = 30
+
+// lastReportAt tracks, per hostname, when the last report was accepted.
+// Kept in memory only: after a restart every host simply gets one free report.
+var lastReportAt = struct {
+	sync.Mutex
+	hosts map[string]time.Time
+}{hosts: make(map[string]time.Time)}
+
+// reserveReportSlot records now as hostname's last accepted report unless one
+// was accepted less than minInterval ago, in which case it returns how long
+// the caller should wait. minInterval <= 0 disables the check.
+func reserveReportSlot(hostname string, now time.Time, minInterval time.Duration) (time.Duration, bool) {
+	if minInterval <= 0 {
+		return 0, true
+	}
+	lastReportAt.Lock()
+	defer lastReportAt.Unlock()
+	if last, ok := lastReportAt.hosts[hostname]; ok {
+		if wait := minInterval - now.Sub(last); wait > 0 {
+			return wait, false
+		}
+	}
+	lastReportAt.hosts[hostname] = now
+	return 0, true
+}
+
+// releaseReportSlot forgets a slot reserved for a report that then failed to
+// store, so the agent's retry isn't rejected as too frequent.
+func releaseReportSlot(hostname string, reserved time.Time) {
+	lastReportAt.Lock()
+	defer lastReportAt.Unlock()
+	if lastReportAt.hosts[hostname].Equal(reserved) {
+		delete(lastReportAt.hosts, hostname)
+	}
+}
+
+// EmergencyHook, when set, is checked for every drive of every report
+// before the response goes out, so the emergency webhook never waits on the
+// background queue (which drops work when full).
+var EmergencyHook *temperature.EmergencyHook
+
+// checkEmergencyTemperatures runs the emergency temperature hook over a
+// report's drives.
+func checkEmergencyTemperatures(hostname string, payload map[string]interface{}) {
+	drives, _ := payload["drives"].([]interface{})
+	bounds := smart.LoadTemperatureBounds(db.DB)
+	for _, d := range drives {
+		dm, ok := d.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		drive, err := agentsmart.ParseSmartAttributes(dm, hostname)
+		if err != nil || drive.SerialNumber == "" || !bounds.Valid(drive.Temperature) {
+			continue
+		}
+		EmergencyHook.Check(db.DB, hostname, drive.SerialNumber, drive.DeviceName, drive.ModelName, drive.Temperature)
+	}
+}
+
 func Report(w http.ResponseWriter, r *http.Request) {
 	session := GetAgentSessionFromRequest(r)
 	if session == nil {
@@ -150,37 +333,133 @@ func Report(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		JSONError(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	// 403 rather than 401: a bad signature is a secret mismatch, and the
+	// agent answers 401 by re-authenticating, which wouldn't help.
+	if len(ReportHMACSecret) > 0 && !validReportSignature(body, r.Header.Get(ReportSignatureHeader)) {
+		log.Printf("⚠️  Rejected report from agent %d: missing or invalid %s", session.AgentID, ReportSignatureHeader)
+		JSONError(w, "Invalid report signature", http.StatusForbidden)
+		return
+	}
+
 	var payload map[string]interface{}
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+	if err := json.Unmarshal(body, &payload); err != nil {
 		JSONError(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
+	parseErrors, fullReportRequired, rerr := ingestReport(session.AgentID, payload)
+	if rerr != nil {
+		if rerr.retryAfter > 0 {
+			w.Header().Set("Retry-After", fmt.Sprint(int(rerr.retryAfter.Seconds())+1))
+		}
+		JSONError(w, rerr.msg, rerr.status)
+		return
+	}
+
+	// Echo back the centrally-configured report interval so agents can adopt it
+	// without per-host reconfiguration. Allowed presets (seconds): 60, 900, 1800,
+	// 3600 (default), 43200, 86400. Agents clamp to these and ignore anything else.
+	JSONResponse(w, map[string]interface{}{
+		"status":                  "ok",
+		"report_interval_seconds": agentReportInterval(),
+		"ingestion_errors":        parseErrors,
+		"schema_version":          CurrentReportSchema,
+		"full_report_required":    fullReportRequired,
+	})
+}
+
+// reportError is why a report wasn't stored, with the HTTP status to answer
+// it with.
+type reportError struct {
+	status     int
+	msg        string
+	retryAfter time.Duration // set for 429
+}
+
+// ingestReport validates, stores and queues a decoded report from an
+// authenticated agent, returning how many of its drives can't be ingested
+// and whether the agent should send its next report in full (see
+// mergeDeltaDrives). It is the storage path shared by POST /api/report and
+// the gRPC ReportService.
+func ingestReport(agentID int64, payload map[string]interface{}) (int, bool, *reportError) {
+	return ingestReportFrom(payload, agentID, time.Time{}, false)
+}
+
+// ingestReportFrom is ingestReport for live reports (zero collectedAt) and
+// imported ones. An imported report is stored at collectedAt, skips the
+// agent approval check, rate limit and emergency webhook, and waits for
+// room in the background queue instead of being dropped. driveOnly is set
+// for imports that cover only the drives they carry (see reportWork).
+func ingestReportFrom(payload map[string]interface{}, agentID int64, collectedAt time.Time, driveOnly bool) (int, bool, *reportError) {
+	imported := !collectedAt.IsZero()
 	hostname, ok := payload["hostname"].(string)
 	if !ok || hostname == "" {
-		JSONError(w, "Missing hostname", http.StatusBadRequest)
-		return
+		return 0, false, &reportError{status: http.StatusBadRequest, msg: "Missing hostname"}
+	}
+	if !imported && agents.AgentPending(db.DB, agentID) {
+		log.Printf("🚫 Report from %s rejected: agent %d is awaiting approval", hostname, agentID)
+		return 0, false, &reportError{status: http.StatusForbidden, msg: "Agent is awaiting approval"}
+	}
+
+	schemaVersion, schemaErr := normalizeReport(payload)
+	if schemaErr != nil {
+		log.Printf("🚫 Report from %s rejected: %s", hostname, schemaErr.msg)
+		return 0, false, &reportError{status: schemaErr.status, msg: schemaErr.msg}
+	}
+
+	// Guard the SQLite writer against a runaway or misconfigured agent.
+	received := time.Now()
+	if !imported {
+		minInterval := settings.GetInt(db.DB, "agents", "min_report_interval_seconds", defaultMinReportInterval)
+		if wait, ok := reserveReportSlot(hostname, received, time.Duration(minInterval)*time.Second); !ok {
+			log.Printf("🚫 Report from %s rejected: less than %ds since the previous one", hostname, minInterval)
+			return 0, false, &reportError{status: http.StatusTooManyRequests, msg: "Reports from this host are arriving too frequently", retryAfter: wait}
+		}
 	}
 
+	fullReportRequired := !mergeDeltaDrives(hostname, payload)
+
 	jsonData, err := json.Marshal(payload)
+	if err == nil {
+		jsonData, err = crypto.SealReport(jsonData)
+	}
 	if err != nil {
-		JSONError(w, "Failed to process data", http.StatusInternalServerError)
-		return
+		releaseReportSlot(hostname, received)
+		return 0, false, &reportError{status: http.StatusInternalServerError, msg: "Failed to process data"}
 	}
 
 	// Store timestamps in UTC for consistency with SQLite datetime('now')
-	now := time.Now().UTC().Format("2006-01-02 15:04:05")
-	if _, err = db.DB.Exec("INSERT INTO reports (hostname, timestamp, data) VALUES (?, ?, ?)", hostname, now, string(jsonData)); err != nil {
+	storedAt := received
+	if imported {
+		storedAt = collectedAt
+	}
+	now := storedAt.UTC().Format("2006-01-02 15:04:05")
+	limit := settings.GetInt(db.DB, "retention", "host_history_limit", 50)
+	err = db.Write(func() error {
+		if _, err := db.DB.Exec("INSERT INTO reports (hostname, timestamp, data) VALUES (?, ?, ?)", hostname, now, string(jsonData)); err != nil {
+			return err
+		}
+		// Trim old reports for this host to stay within the retention limit.
+		db.DB.Exec(`DELETE FROM reports WHERE hostname = ? AND id NOT IN (
+			SELECT id FROM reports WHERE hostname = ? ORDER BY timestamp DESC LIMIT ?
+		)`, hostname, hostname, limit)
+		return nil
+	})
+	if err != nil {
 		log.Printf("❌ DB Write Error: %v", err)
-		JSONError(w, "Database Error", http.StatusInternalServerError)
-		return
+		releaseReportSlot(hostname, received)
+		return 0, false, &reportError{status: http.StatusInternalServerError, msg: "Database Error"}
 	}
 
-	// Trim old reports for this host to stay within the retention limit.
-	limit := settings.GetInt(db.DB, "retention", "host_history_limit", 50)
-	db.DB.Exec(`DELETE FROM reports WHERE hostname = ? AND id NOT IN (
-		SELECT id FROM reports WHERE hostname = ? ORDER BY timestamp DESC LIMIT ?
-	)`, hostname, hostname, limit)
+	if EmergencyHook != nil && !imported {
+		checkEmergencyTemperatures(hostname, payload)
+	}
 
 	// Count drives and pools for logging.
 	driveCount := 0
@@ -194,48 +473,66 @@ func Report(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if poolCount > 0 {
+	if imported {
+		log.Printf("📥 Imported report: %s (%d drives, %d ZFS pools) collected %s", hostname, driveCount, poolCount, collectedAt.UTC().Format(time.RFC3339))
+	} else if poolCount > 0 {
 		log.Printf("💾 Report: %s (%d drives, %d ZFS pools)", hostname, driveCount, poolCount)
 	} else {
 		log.Printf("💾 Report: %s (%d drives)", hostname, driveCount)
 	}
+	if schemaVersion < CurrentReportSchema {
+		log.Printf("⚠️  Report: %s sent schema_version %d (current %d); upgraded with defaults, consider updating its agent", hostname, schemaVersion, CurrentReportSchema)
+	}
 
-	// Respond immediately — heavy processing is serialised through a single
-	// background worker so it never holds the SQLite write lock while the
-	// dashboard is trying to read /api/history.
-	//
-	// Echo back the centrally-configured report interval so agents can adopt it
-	// without per-host reconfiguration. Allowed presets (seconds): 60, 900, 1800,
-	// 3600 (default), 43200, 86400. Agents clamp to these and ignore anything else.
-	JSONResponse(w, map[string]interface{}{
-		"status":                 "ok",
-		"report_interval_seconds": agentReportInterval(),
-	})
+	// Drives that won't make it into the SMART history; the background
+	// worker records them in ingestion_errors along with any store failures.
+	parseErrors := len(smart.ReportParseErrors(hostname, payload))
+	if parseErrors > 0 {
+		log.Printf("⚠️  Report: %s has %d of %d drives that can't be ingested (see /api/smart/ingestion-errors)", hostname, parseErrors, driveCount)
+	}
 
-	// Enqueue background work (non-blocking; drops if queue is full).
+	// Heavy processing is serialised through a single background worker so
+	// it never holds the SQLite write lock while the dashboard is trying to
+	// read /api/history; the agent gets its answer right away.
+	// Enqueue is non-blocking and drops the work if the queue is full;
+	// imports wait, since nothing would resend them.
+	work := reportWork{hostname: hostname, agentID: agentID, payload: payload, collectedAt: collectedAt, driveOnly: driveOnly}
+	if imported {
+		reportQueue <- work
+		return parseErrors, fullReportRequired, nil
+	}
 	select {
-	case reportQueue <- reportWork{hostname: hostname, agentID: session.AgentID, payload: payload}:
+	case reportQueue <- work:
 	default:
 		log.Printf("⚠️  Report processing queue full, dropping background work for %s", hostname)
 		if Metrics != nil {
 			Metrics.ReportsDropped.Add(1)
 		}
 	}
+	return parseErrors, fullReportRequired, nil
 }
 
-// History returns latest reports for all hosts with aliases
+// History returns latest reports for all hosts, each drive carrying its
+// alias (_alias) and display name (_display_name).
+// Optional ?label=key:value (repeatable) keeps only hosts carrying every label.
+// ?anonymize=true pseudonymizes serials (see anonymizer).
 func History(w http.ResponseWriter, r *http.Request) {
 	aliases := loadAliases()
+	names := drivename.NewResolver(db.DB)
+	filters := parseLabelFilters(r)
+	labels := loadHostLabels()
+	scope := requestScope(r)
 
 	query := `
 	SELECT r.hostname, r.timestamp, r.data,
 	       COALESCE(ag.last_seen, r.timestamp) AS last_seen
 	FROM reports r
 	INNER JOIN (
-		SELECT hostname, MAX(id) AS max_id
-		FROM reports
-		GROUP BY hostname
-	) latest ON r.id = latest.max_id
+		SELECT id FROM (
+			SELECT id, ROW_NUMBER() OVER (PARTITION BY hostname ORDER BY timestamp DESC, id DESC) AS rn
+			FROM reports
+		) WHERE rn = 1
+	) latest ON r.id = latest.id
 	LEFT JOIN (
 		SELECT hostname, MAX(last_seen_at) AS last_seen
 		FROM agent_registry
@@ -258,27 +555,48 @@ func History(w http.ResponseWriter, r *http.Request) {
 		if err := rows.Scan(&host, &ts, &dataRaw, &lastSeen); err != nil {
 			continue
 		}
+		if !scope.AllowsHost(host) || !matchesLabels(labels[host], filters) {
+			continue
+		}
+		if dataRaw, err = crypto.OpenReport(dataRaw); err != nil {
+			log.Printf("reports: history data for %s: %v", host, err)
+			continue
+		}
 
 		var dataMap map[string]interface{}
 		if err := json.Unmarshal(dataRaw, &dataMap); err != nil {
 			log.Printf("reports: unmarshal history data for %s: %v", host, err)
 			continue
 		}
-		enrichDrivesWithAliases(dataMap, host, aliases)
+		enrichDrivesWithNames(dataMap, host, aliases, names)
 
 		history = append(history, map[string]interface{}{
 			"hostname":  host,
-			"timestamp": ts,
-			"last_seen": lastSeen,
+			"timestamp": formatTimestamp(ts),
+			"last_seen": formatTimestamp(lastSeen),
 			"details":   dataMap,
 		})
 	}
 
-	JSONResponse(w, history)
+	anonymizedResponse(w, r, history)
 }
 
-// Hosts returns list of all hosts
+// Hosts returns list of all hosts with their labels.
+// Optional ?label=key:value (repeatable) keeps only hosts carrying every label.
+// ?anonymize=true&redact_hosts=true pseudonymizes the hostnames.
 func Hosts(w http.ResponseWriter, r *http.Request) {
+	filters := parseLabelFilters(r)
+	labels := loadHostLabels()
+	scope := requestScope(r)
+	smartctlVersions, err := agents.GetSmartctlVersions(db.DB)
+	if err != nil {
+		log.Printf("⚠️  Failed to load smartctl versions: %v", err)
+	}
+	maintenance, err := agents.GetMaintenanceHosts(db.DB)
+	if err != nil {
+		log.Printf("⚠️  Failed to load maintenance state: %v", err)
+	}
+
 	query := `
 	SELECT hostname, MAX(timestamp) as last_seen, COUNT(*) as report_count
 	FROM reports GROUP BY hostname ORDER BY last_seen DESC`
@@ -297,19 +615,34 @@ func Hosts(w http.ResponseWriter, r *http.Request) {
 		if err := rows.Scan(&hostname, &lastSeen, &reportCount); err != nil {
 			continue
 		}
+		if !scope.AllowsHost(hostname) || !matchesLabels(labels[hostname], filters) {
+			continue
+		}
+		hostLabels := labels[hostname]
+		if hostLabels == nil {
+			hostLabels = map[string]string{}
+		}
+		var maintenanceSince interface{}
+		if since, ok := maintenance[hostname]; ok {
+			maintenanceSince = since.UTC()
+		}
 		hosts = append(hosts, map[string]interface{}{
-			"hostname":     hostname,
-			"last_seen":    lastSeen,
-			"report_count": reportCount,
+			"hostname":          hostname,
+			"last_seen":         formatTimestamp(lastSeen),
+			"report_count":      reportCount,
+			"labels":            hostLabels,
+			"smartctl_version":  smartctlVersions[hostname],
+			"maintenance":       maintenanceSince != nil,
+			"maintenance_since": maintenanceSince,
 		})
 	}
 
-	JSONResponse(w, hosts)
+	anonymizedResponse(w, r, hosts)
 }
 
 // DeleteHost removes a host and all of its hostname-keyed data: reports,
 // drive aliases, ZFS pools (cascades to devices/scrub history/datasets),
-// wearout history, and SMART attributes. Without the full cascade, a
+// wearout history, and SMART attributes (raw and daily rollups). Without the full cascade, a
 // later registration under the same hostname would adopt the orphan rows
 // (e.g. ZFS pools "coming back" attached to a fresh agent).
 func DeleteHost(w http.ResponseWriter, r *http.Request) {
@@ -327,9 +660,7 @@ func DeleteHost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("🗑️  Deleted host: %s — cascade: %v", hostname, deleted)
-	if s := auth.GetSessionFromContext(r); s != nil {
-		audit.LogEvent(db.DB, r, s.UserID, s.Username, "host_delete", "host", hostname, fmt.Sprintf("cascade: %v", deleted), "success")
-	}
+	recordAudit(r, "host_delete", "host", hostname, fmt.Sprintf("cascade: %v", deleted))
 	JSONResponse(w, map[string]interface{}{
 		"status":  "deleted",
 		"deleted": reportCount,
@@ -337,7 +668,7 @@ func DeleteHost(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// HostHistory returns history for a specific host
+// HostHistory returns history for a specific host. Honours ?anonymize=true.
 func HostHistory(w http.ResponseWriter, r *http.Request) {
 	hostname := r.PathValue("hostname")
 	if hostname == "" {
@@ -363,6 +694,10 @@ func HostHistory(w http.ResponseWriter, r *http.Request) {
 		if err := rows.Scan(&ts, &dataRaw); err != nil {
 			continue
 		}
+		if dataRaw, err = crypto.OpenReport(dataRaw); err != nil {
+			log.Printf("reports: host history data for %s: %v", hostname, err)
+			continue
+		}
 
 		var dataMap map[string]interface{}
 		if err := json.Unmarshal(dataRaw, &dataMap); err != nil {
@@ -371,16 +706,77 @@ func HostHistory(w http.ResponseWriter, r *http.Request) {
 		}
 
 		history = append(history, map[string]interface{}{
-			"timestamp": ts,
+			"timestamp": formatTimestamp(ts),
 			"details":   dataMap,
 		})
 	}
 
-	JSONResponse(w, history)
+	anonymizedResponse(w, r, history)
 }
 
 // Helper functions
 
+// labelFilter is one ?label= query term. An empty value with hasValue false
+// matches any host that has the key at all.
+type labelFilter struct {
+	key      string
+	value    string
+	hasValue bool
+}
+
+// parseLabelFilters reads repeated ?label=key:value (or key=value, or bare
+// key) query parameters.
+func parseLabelFilters(r *http.Request) []labelFilter {
+	var filters []labelFilter
+	for _, raw := range r.URL.Query()["label"] {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(raw, ":")
+		if !ok {
+			key, value, ok = strings.Cut(raw, "=")
+		}
+		filters = append(filters, labelFilter{key: strings.TrimSpace(key), value: strings.TrimSpace(value), hasValue: ok})
+	}
+	return filters
+}
+
+func matchesLabels(labels map[string]string, filters []labelFilter) bool {
+	for _, f := range filters {
+		v, ok := labels[f.key]
+		if !ok || (f.hasValue && v != f.value) {
+			return false
+		}
+	}
+	return true
+}
+
+func loadHostLabels() map[string]map[string]string {
+	labels, err := agents.GetAllHostLabels(db.DB)
+	if err != nil {
+		log.Printf("reports: load host labels: %v", err)
+		return map[string]map[string]string{}
+	}
+	return labels
+}
+
+// reportLabels extracts the agent's "labels" object from a report payload,
+// keeping only string values.
+func reportLabels(payload map[string]interface{}) map[string]string {
+	raw, ok := payload["labels"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	out := make(map[string]string, len(raw))
+	for k, v := range raw {
+		if s, ok := v.(string); ok && strings.TrimSpace(k) != "" {
+			out[k] = s
+		}
+	}
+	return out
+}
+
 func loadAliases() map[string]string {
 	aliases := make(map[string]string)
 	rows, err := db.DB.Query("SELECT hostname, serial_number, alias FROM drive_aliases")
@@ -402,7 +798,7 @@ func loadAliases() map[string]string {
 	return aliases
 }
 
-func enrichDrivesWithAliases(data map[string]interface{}, hostname string, aliases map[string]string) {
+func enrichDrivesWithNames(data map[string]interface{}, hostname string, aliases map[string]string, names *drivename.Resolver) {
 	drives, ok := data["drives"].([]interface{})
 	if !ok {
 		return
@@ -416,8 +812,12 @@ func enrichDrivesWithAliases(data map[string]interface{}, hostname string, alias
 		if serial, ok := drive["serial_number"].(string); ok {
 			if alias, exists := aliases[hostname+":"+serial]; exists {
 				drive["_alias"] = alias
-				drives[i] = drive
 			}
+			if serial != "" {
+				names.Know(hostname, serial, agentsmart.ModelName(drive), drivename.DevicePath(drive))
+				drive["_display_name"] = names.DisplayName(hostname, serial)
+			}
+			drives[i] = drive
 		}
 	}
 	data["drives"] = drives
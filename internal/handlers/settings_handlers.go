@@ -2,12 +2,81 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"sort"
+	"strings"
 
 	"vigil/internal/db"
 	"vigil/internal/settings"
 )
 
+// GetAllSettings returns every setting grouped by category, each with its
+// value_type, default and description, so clients can build a settings UI
+// without hard-coding the list.
+// GET /api/settings
+func GetAllSettings(w http.ResponseWriter, r *http.Request) {
+	grouped, err := settings.GetSettingsGrouped(db.DB)
+	if err != nil {
+		JSONError(w, "Failed to get settings", http.StatusInternalServerError)
+		return
+	}
+	JSONResponse(w, grouped)
+}
+
+// UpdateSettings updates several settings at once. The body is grouped like
+// the GET response, {"temperature": {"warning_threshold": 50}}; values may
+// be JSON strings, numbers, booleans or (for json settings) objects. Either
+// every value is valid and all are saved, or nothing is and the response
+// lists the rejected keys.
+// PUT /api/settings
+func UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var req map[string]map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		JSONError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	updates := make(map[string]map[string]string, len(req))
+	var changed []string
+	for category, values := range req {
+		updates[category] = make(map[string]string, len(values))
+		for key, raw := range values {
+			// Strings arrive quoted; numbers, booleans and objects are
+			// stored as their JSON text.
+			var value string
+			if err := json.Unmarshal(raw, &value); err != nil {
+				value = string(raw)
+			}
+			updates[category][key] = value
+			changed = append(changed, category+"."+key+"="+value)
+		}
+	}
+	if len(changed) == 0 {
+		JSONError(w, "No settings given", http.StatusBadRequest)
+		return
+	}
+
+	if err := settings.UpdateSettings(db.DB, updates); err != nil {
+		var invalid settings.ValidationErrors
+		if errors.As(err, &invalid) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":  "Invalid settings",
+				"fields": invalid,
+			})
+			return
+		}
+		JSONError(w, "Failed to update settings", http.StatusInternalServerError)
+		return
+	}
+
+	sort.Strings(changed)
+	recordAudit(r, "settings_update", "setting", "", strings.Join(changed, ", "))
+	JSONResponse(w, map[string]interface{}{"status": "updated", "updated": len(changed)})
+}
+
 // GetSettingsByCategory returns all settings for a given category.
 // GET /api/settings/{category}
 func GetSettingsByCategory(w http.ResponseWriter, r *http.Request) {
@@ -52,11 +121,24 @@ func UpdateSettingValue(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, "setting_update", "setting", category+"."+key, req.Value)
 	JSONResponse(w, map[string]string{"status": "updated"})
 }
 
+// ReloadSettings signals background workers (retention sweep, scheduled
+// backups) to re-read their settings now instead of on their next tick.
+// POST /api/settings/reload
+func ReloadSettings(w http.ResponseWriter, r *http.Request) {
+	settings.NotifyReload()
+	recordAudit(r, "settings_reload", "setting", "", "")
+	JSONResponse(w, map[string]string{"status": "reloaded"})
+}
+
 // RegisterSettingsRoutes registers settings API routes.
 func RegisterSettingsRoutes(mux *http.ServeMux, protect func(http.HandlerFunc) http.HandlerFunc) {
+	mux.HandleFunc("GET /api/settings", protect(GetAllSettings))
+	mux.HandleFunc("PUT /api/settings", protect(UpdateSettings))
 	mux.HandleFunc("GET /api/settings/{category}", protect(GetSettingsByCategory))
 	mux.HandleFunc("PUT /api/settings/{category}/{key}", protect(UpdateSettingValue))
+	mux.HandleFunc("POST /api/settings/reload", protect(ReloadSettings))
 }
@@ -1,11 +1,18 @@
 package handlers
 
 import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
+	agentsmart "vigil/cmd/agent/smart"
 	"vigil/internal/db"
 	"vigil/internal/smart"
+	"vigil/internal/temperature"
 )
 
 // GetSmartAttributes returns the latest SMART attributes for a drive
@@ -40,6 +47,10 @@ func GetSmartAttributes(w http.ResponseWriter, r *http.Request) {
 		response["smart_passed"] = driveInfo.SmartPassed
 	}
 
+	// Learned normal temperature range; null until enough history exists
+	baseline, _ := temperature.GetBaseline(db.DB, hostname, serialNumber)
+	response["temperature_baseline"] = baseline
+
 	JSONResponse(w, response)
 }
 
@@ -157,6 +168,15 @@ func GetAllDrivesHealthSummary(w http.ResponseWriter, r *http.Request) {
 		JSONError(w, "Failed to retrieve health summaries: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if scope := requestScope(r); !scope.IsAdmin() {
+		visible := summaries[:0]
+		for _, s := range summaries {
+			if scope.AllowsHost(s.Hostname) {
+				visible = append(visible, s)
+			}
+		}
+		summaries = visible
+	}
 
 	// Calculate aggregate stats
 	totalDrives := len(summaries)
@@ -175,7 +195,7 @@ func GetAllDrivesHealthSummary(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	JSONResponse(w, map[string]interface{}{
+	jsonResponseFor(w, r, map[string]interface{}{
 		"summaries":      summaries,
 		"total_drives":   totalDrives,
 		"healthy_count":  healthyCount,
@@ -239,6 +259,36 @@ func GetTemperatureHistory(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// PreviewTemperatureThresholds re-classifies every drive's latest
+// temperature against the given thresholds without saving them, returning
+// the status counts before and after and the drives that would change.
+// GET /api/temperature/preview?warning=50&critical=60
+func PreviewTemperatureThresholds(w http.ResponseWriter, r *http.Request) {
+	warning, err := strconv.Atoi(r.URL.Query().Get("warning"))
+	if err != nil {
+		JSONError(w, "warning must be an integer", http.StatusBadRequest)
+		return
+	}
+	critical, err := strconv.Atoi(r.URL.Query().Get("critical"))
+	if err != nil {
+		JSONError(w, "critical must be an integer", http.StatusBadRequest)
+		return
+	}
+	if warning >= critical {
+		JSONError(w, "warning must be below critical", http.StatusBadRequest)
+		return
+	}
+
+	preview, err := temperature.PreviewThresholds(db.DB, temperature.TemperatureThresholds{Warning: warning, Critical: critical})
+	if err != nil {
+		log.Printf("❌ Failed to preview temperature thresholds: %v", err)
+		JSONError(w, "Failed to preview thresholds", http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, preview)
+}
+
 // CleanupOldSmartData removes old SMART data (admin endpoint)
 func CleanupOldSmartData(w http.ResponseWriter, r *http.Request) {
 	daysStr := r.URL.Query().Get("days")
@@ -314,3 +364,213 @@ func GetDrivesWithIssues(w http.ResponseWriter, r *http.Request) {
 		"count":  len(drivesWithIssues),
 	})
 }
+
+// GetIngestionErrors returns drives from agent reports that couldn't be
+// parsed or stored, newest first.
+// GET /api/smart/ingestion-errors?hostname=&limit=
+func GetIngestionErrors(w http.ResponseWriter, r *http.Request) {
+	hostname := r.URL.Query().Get("hostname")
+
+	limit := 100
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 1000 {
+		limit = l
+	}
+
+	list, err := smart.ListIngestionErrors(db.DB, hostname, limit)
+	if err != nil {
+		JSONError(w, "Failed to list ingestion errors: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	JSONResponse(w, map[string]interface{}{
+		"errors": list,
+		"count":  len(list),
+	})
+}
+
+// QuerySmartAttributes returns raw smart_attributes rows matching any mix
+// of host, drive, attribute and time window filters, newest first, for
+// ad-hoc analysis.
+// GET /api/smart/query?hostname=&serial=&attribute_id=&from=&to=&limit=
+func QuerySmartAttributes(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	q := smart.AttributeQuery{
+		Hostname:     params.Get("hostname"),
+		SerialNumber: params.Get("serial"),
+	}
+
+	if v := params.Get("attribute_id"); v != "" {
+		attrID, err := strconv.Atoi(v)
+		if err != nil {
+			JSONError(w, "Invalid attribute ID", http.StatusBadRequest)
+			return
+		}
+		q.AttributeID = &attrID
+	}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &q.From}, {"to", &q.To}} {
+		v := params.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			JSONError(w, p.name+" must be an RFC3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		*p.dst = t
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		JSONError(w, "from must be before to", http.StatusBadRequest)
+		return
+	}
+
+	q.Limit = smart.DefaultQueryLimit
+	if l, err := strconv.Atoi(params.Get("limit")); err == nil && l > 0 && l <= smart.MaxQueryLimit {
+		q.Limit = l
+	}
+
+	rows, truncated, err := smart.QueryAttributes(db.DB, q)
+	if err != nil {
+		JSONError(w, "Failed to query SMART attributes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	JSONResponse(w, map[string]interface{}{
+		"rows":      rows,
+		"count":     len(rows),
+		"limit":     q.Limit,
+		"truncated": truncated,
+	})
+}
+
+// GetSmartAttributeDefinitions returns every SMART attribute Vigil judges,
+// keyed by ID, with its built-in definition and the custom rules on it.
+// GET /api/smart/attributes/definitions
+func GetSmartAttributeDefinitions(w http.ResponseWriter, r *http.Request) {
+	defs, err := smart.AttributeDefinitions(db.DB)
+	if err != nil {
+		JSONError(w, "Failed to list attribute definitions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	JSONResponse(w, map[string]interface{}{
+		"attributes": defs,
+		"count":      len(defs),
+	})
+}
+
+// ── Custom attribute rules ──────────────────────────────────────────────
+
+// ListCustomAttributeRules returns every custom attribute rule.
+// GET /api/smart/custom-rules
+func ListCustomAttributeRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := smart.ListCustomRules(db.DB)
+	if err != nil {
+		JSONError(w, "Failed to list custom rules: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	JSONResponse(w, rules)
+}
+
+// CreateCustomAttributeRule adds a watch on a SMART attribute, e.g.
+// {"attribute_id": 173, "model": "MX500", "threshold": 100, "severity": "WARNING"}.
+// field defaults to raw, operator to above, enabled to true.
+// POST /api/smart/custom-rules
+func CreateCustomAttributeRule(w http.ResponseWriter, r *http.Request) {
+	rule := agentsmart.CustomAttributeRule{Enabled: true}
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		JSONError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := rule.Normalize(); err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := smart.CreateCustomRule(db.DB, &rule)
+	if err != nil {
+		JSONError(w, "Failed to create custom rule: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rule.ID = id
+	reloadCustomAttributeRules()
+	recordAudit(r, "custom_rule_create", "custom_attribute_rule", strconv.FormatInt(id, 10), customRuleSummary(rule))
+	w.WriteHeader(http.StatusCreated)
+	JSONResponse(w, rule)
+}
+
+// UpdateCustomAttributeRule changes a rule; fields left out of the body keep
+// their current values.
+// PUT /api/smart/custom-rules/{id}
+func UpdateCustomAttributeRule(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		JSONError(w, "Invalid rule ID", http.StatusBadRequest)
+		return
+	}
+	rule, err := smart.GetCustomRule(db.DB, id)
+	if err != nil {
+		JSONError(w, "Failed to get custom rule: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if rule == nil {
+		JSONError(w, "Rule not found", http.StatusNotFound)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(rule); err != nil {
+		JSONError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	rule.ID = id
+	if err := rule.Normalize(); err != nil {
+		JSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := smart.UpdateCustomRule(db.DB, rule); err == sql.ErrNoRows {
+		JSONError(w, "Rule not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		JSONError(w, "Failed to update custom rule: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	reloadCustomAttributeRules()
+	recordAudit(r, "custom_rule_update", "custom_attribute_rule", strconv.FormatInt(id, 10), customRuleSummary(*rule))
+	JSONResponse(w, rule)
+}
+
+// DeleteCustomAttributeRule removes a rule.
+// DELETE /api/smart/custom-rules/{id}
+func DeleteCustomAttributeRule(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		JSONError(w, "Invalid rule ID", http.StatusBadRequest)
+		return
+	}
+	if err := smart.DeleteCustomRule(db.DB, id); err == sql.ErrNoRows {
+		JSONError(w, "Rule not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		JSONError(w, "Failed to delete custom rule: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	reloadCustomAttributeRules()
+	recordAudit(r, "custom_rule_delete", "custom_attribute_rule", strconv.FormatInt(id, 10), "")
+	JSONResponse(w, map[string]string{"status": "deleted"})
+}
+
+func reloadCustomAttributeRules() {
+	if err := smart.LoadCustomRules(db.DB); err != nil {
+		log.Printf("⚠️  Failed to reload custom attribute rules: %v", err)
+	}
+}
+
+func customRuleSummary(r agentsmart.CustomAttributeRule) string {
+	s := fmt.Sprintf("attr %d %s %s %d → %s", r.AttributeID, r.Field, r.Operator, r.Threshold, r.Severity)
+	if r.Model != "" {
+		s += " (model " + r.Model + ")"
+	}
+	return s
+}
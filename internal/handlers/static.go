@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 
 	"vigil/internal/auth"
+	"vigil/internal/db"
 	"vigil/internal/models"
 )
 
@@ -14,12 +18,27 @@ var Version = "dev"
 // VersionChecker handles version update checking
 var VersionChecker *VersionHandler
 
-// Health returns server health status
+// Health returns server health status. It probes the database and answers
+// 503 with status "unhealthy" when it cannot be read or written, so container
+// liveness/readiness checks notice a broken database.
 func Health(w http.ResponseWriter, r *http.Request) {
-	JSONResponse(w, map[string]string{
-		"status":  "healthy",
-		"version": Version,
-	})
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	dbHealth := db.CheckHealth(ctx, db.DB)
+	resp := map[string]interface{}{
+		"status":   "healthy",
+		"version":  Version,
+		"database": dbHealth,
+	}
+	if !dbHealth.OK {
+		resp["status"] = "unhealthy"
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(resp) //nolint:errcheck
+		return
+	}
+	JSONResponse(w, resp)
 }
 
 // GetVersion returns server version
@@ -27,6 +46,13 @@ func GetVersion(w http.ResponseWriter, r *http.Request) {
 	JSONResponse(w, map[string]string{"version": Version})
 }
 
+// SetupPage serves the first-run setup page. It is the login page, which
+// switches to creating the admin while no user exists and shows the normal
+// sign-in afterwards; login.html sends visitors here until setup is done.
+func SetupPage(w http.ResponseWriter, r *http.Request) {
+	http.ServeFile(w, r, "./web/login.html")
+}
+
 // StaticFiles serves static files with auth check
 func StaticFiles(config models.Config) http.HandlerFunc {
 	fs := http.FileServer(http.Dir("./web"))
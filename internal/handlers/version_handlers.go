@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"time"
 
+	"vigil/internal/db"
+	"vigil/internal/settings"
 	"vigil/internal/version"
 )
 
@@ -30,6 +32,17 @@ func (h *VersionHandler) Check() (*version.ReleaseInfo, error) {
 	return h.checker.Check()
 }
 
+// Enabled reports whether update checks are switched on in settings and
+// applies the configured release URL to the checker. Checks are opt-in so
+// a fresh install makes no outbound calls until an admin enables them.
+func (h *VersionHandler) Enabled() bool {
+	if db.DB == nil || !settings.GetBool(db.DB, "system", "update_check_enabled", false) {
+		return false
+	}
+	h.checker.SetReleaseURL(settings.GetStringSettingWithDefault(db.DB, "system", "update_check_url", ""))
+	return true
+}
+
 // CheckVersion handles GET /api/version/check
 // Returns information about available updates
 func (h *VersionHandler) CheckVersion(w http.ResponseWriter, r *http.Request) {
@@ -39,6 +52,16 @@ func (h *VersionHandler) CheckVersion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.Enabled() {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"current_version":  h.checker.GetCurrentVersion(),
+			"update_available": false,
+			"check_enabled":    false,
+		})
+		return
+	}
+
 	// Check for force refresh parameter
 	var info *version.ReleaseInfo
 	var err error
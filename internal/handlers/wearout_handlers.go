@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	"vigil/internal/db"
+	"vigil/internal/middleware"
 	"vigil/internal/wearout"
 )
 
@@ -29,7 +30,14 @@ func GetDriveWearout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	JSONResponse(w, snapshot)
+	// SSD/NVMe write rate and amplification; absent for HDDs and drives
+	// without a host-writes counter.
+	writeStats, _ := wearout.GetWriteStats(db.DB, hostname, serial, 7)
+
+	JSONResponse(w, struct {
+		*wearout.WearoutSnapshot
+		WriteStats *wearout.WriteStats `json:"write_stats,omitempty"`
+	}{snapshot, writeStats})
 }
 
 // GetAllWearout returns the latest wearout for every monitored drive.
@@ -40,7 +48,7 @@ func GetAllWearout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	JSONResponse(w, map[string]interface{}{
+	jsonResponseFor(w, r, map[string]interface{}{
 		"drives": snapshots,
 		"count":  len(snapshots),
 	})
@@ -163,7 +171,7 @@ func DeleteDriveSpec(w http.ResponseWriter, r *http.Request) {
 // RegisterWearoutRoutes registers all wearout API endpoints.
 func RegisterWearoutRoutes(mux *http.ServeMux, protect func(http.HandlerFunc) http.HandlerFunc) {
 	mux.HandleFunc("GET /api/wearout/drive", protect(GetDriveWearout))
-	mux.HandleFunc("GET /api/wearout/all", protect(GetAllWearout))
+	mux.HandleFunc("GET /api/wearout/all", protect(middleware.ETag(GetAllWearout)))
 	mux.HandleFunc("GET /api/wearout/history", protect(GetWearoutHistory))
 	mux.HandleFunc("GET /api/wearout/trend", protect(GetWearoutTrend))
 	mux.HandleFunc("GET /api/wearout/specs", protect(GetDriveSpecs))
@@ -1,13 +1,23 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	agentsmart "vigil/cmd/agent/smart"
 	"vigil/internal/db"
+	"vigil/internal/events"
+	"vigil/internal/middleware"
+	"vigil/internal/settings"
+	"vigil/internal/smart"
+	"vigil/internal/temperature"
 	"vigil/internal/zfs"
 )
 
@@ -18,6 +28,79 @@ type ZFSPoolWithCount struct {
 	DaysSinceLastScrub int `json:"days_since_last_scrub"`
 }
 
+// ZFSDeviceHealth is a pool device joined with the SMART analysis and current
+// temperature of the drive behind it. Both are null for devices without a
+// serial (partitions on unknown disks, files) or with no SMART data yet.
+type ZFSDeviceHealth struct {
+	zfs.ZFSPoolDevice
+	SmartHealth *agentsmart.DriveHealthAnalysis `json:"smart_health"`
+	Temperature *temperature.CurrentTemperature `json:"temperature"`
+}
+
+// zfsByteFields are the byte-count fields of ZFS responses whose names don't
+// end in _bytes; zfsRateFields hold bytes per second.
+var (
+	zfsByteFields = map[string]bool{"data_examined": true, "data_total": true, "bytes_repaired": true}
+	zfsRateFields = map[string]bool{"rate_bytes_sec": true, "scan_speed": true, "read_bytes": true, "write_bytes": true}
+)
+
+// zfsResponse writes v like JSONResponse. With ?human=true every byte count
+// in it gets a formatted companion field, e.g. "size_bytes_human": "3.62T"
+// next to "size_bytes", which stays the canonical raw number.
+func zfsResponse(w http.ResponseWriter, r *http.Request, v interface{}) {
+	if r.URL.Query().Get("human") != "true" {
+		jsonResponseFor(w, r, v)
+		return
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		JSONError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber() // keep byte counts above 2^53 exact
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		JSONError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+	humanizeZFSBytes(doc)
+	jsonResponseFor(w, r, doc)
+}
+
+// humanizeZFSBytes adds a <field>_human string next to every byte-count
+// field in a decoded JSON document.
+func humanizeZFSBytes(v interface{}) {
+	switch t := v.(type) {
+	case []interface{}:
+		for _, e := range t {
+			humanizeZFSBytes(e)
+		}
+	case map[string]interface{}:
+		added := make(map[string]interface{})
+		for k, e := range t {
+			n, ok := e.(json.Number)
+			if !ok {
+				humanizeZFSBytes(e)
+				continue
+			}
+			b, err := n.Int64()
+			if err != nil {
+				continue
+			}
+			switch {
+			case zfsRateFields[k]:
+				added[k+"_human"] = zfs.FormatBytes(b) + "/s"
+			case strings.HasSuffix(k, "_bytes") || zfsByteFields[k]:
+				added[k+"_human"] = zfs.FormatBytes(b)
+			}
+		}
+		for k, e := range added {
+			t[k] = e
+		}
+	}
+}
+
 // ─── ZFS Pool Endpoints ──────────────────────────────────────────────────────
 
 // ZFSPools returns all ZFS pools with device counts
@@ -65,7 +148,7 @@ func ZFSPools(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	JSONResponse(w, response)
+	zfsResponse(w, r, response)
 }
 
 // ZFSPool returns a single ZFS pool with its devices
@@ -120,32 +203,34 @@ func ZFSPool(w http.ResponseWriter, r *http.Request) {
 		"datasets":              datasets,
 		"scrub_history":         scrubHistory,
 		"days_since_last_scrub": daysSinceLastScrub,
+		"redundancy":            zfs.PoolRedundancy(devices),
+		"fill_projection":       zfs.PoolFillProjection(db.DB, pool.ID),
 	}
 
-	JSONResponse(w, response)
+	zfsResponse(w, r, response)
 }
 
-// ZFSPoolSummary returns aggregate ZFS stats
+// ZFSPoolSummary returns aggregate ZFS stats. The fleet-wide summary is
+// served from the aggregate cache (?refresh=true recomputes it).
 // GET /api/zfs/summary
+// GET /api/zfs/summary?hostname=server1
 func ZFSPoolSummary(w http.ResponseWriter, r *http.Request) {
 	hostname := r.URL.Query().Get("hostname")
-
-	var summary *zfs.ZFSPoolSummary
-	var err error
-
-	if hostname != "" {
-		summary, err = zfs.GetZFSPoolSummary(db.DB, hostname)
-	} else {
-		summary, err = zfs.GetGlobalZFSSummary(db.DB)
+	if hostname == "" {
+		if set, ok := cachedAggregates(w, r); ok {
+			zfsResponse(w, r, set.zfsSummary)
+		}
+		return
 	}
 
+	summary, err := zfs.GetZFSPoolSummary(db.DB, hostname)
 	if err != nil {
 		log.Printf("❌ Failed to get ZFS summary: %v", err)
 		JSONError(w, "Failed to retrieve ZFS summary", http.StatusInternalServerError)
 		return
 	}
 
-	JSONResponse(w, summary)
+	zfsResponse(w, r, summary)
 }
 
 // ─── ZFS Device Endpoints ────────────────────────────────────────────────────
@@ -184,7 +269,62 @@ func ZFSPoolDevices(w http.ResponseWriter, r *http.Request) {
 		devices = []zfs.ZFSPoolDevice{}
 	}
 
-	JSONResponse(w, devices)
+	zfsResponse(w, r, devices)
+}
+
+// ZFSPoolDevicesHealth returns the pool's devices, each joined with the
+// latest SMART analysis and current temperature of its drive, so a device
+// with read/checksum errors can be checked against the drive's own health.
+// GET /api/zfs/pools/{hostname}/{poolname}/devices/health
+func ZFSPoolDevicesHealth(w http.ResponseWriter, r *http.Request) {
+	hostname := r.PathValue("hostname")
+	poolName := r.PathValue("poolname")
+
+	if hostname == "" || poolName == "" {
+		JSONError(w, "Missing hostname or pool name", http.StatusBadRequest)
+		return
+	}
+
+	pool, err := zfs.GetZFSPool(db.DB, hostname, poolName)
+	if err != nil {
+		log.Printf("❌ Failed to get ZFS pool: %v", err)
+		JSONError(w, "Failed to retrieve ZFS pool", http.StatusInternalServerError)
+		return
+	}
+
+	if pool == nil {
+		JSONError(w, "Pool not found", http.StatusNotFound)
+		return
+	}
+
+	devices, err := zfs.GetZFSPoolDevices(db.DB, pool.ID)
+	if err != nil {
+		log.Printf("❌ Failed to get pool devices: %v", err)
+		JSONError(w, "Failed to retrieve pool devices", http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]ZFSDeviceHealth, 0, len(devices))
+	for _, dev := range devices {
+		entry := ZFSDeviceHealth{ZFSPoolDevice: dev}
+		if dev.SerialNumber != "" {
+			// Without stored attributes the analysis would report a drive
+			// Vigil knows nothing about as healthy.
+			if attrs, err := smart.GetLatestSmartAttributes(db.DB, pool.Hostname, dev.SerialNumber); err == nil && len(attrs) > 0 {
+				entry.SmartHealth, _ = smart.GetDriveHealthSummary(db.DB, pool.Hostname, dev.SerialNumber)
+			}
+			entry.Temperature, _ = temperature.GetCurrentTemperature(db.DB, pool.Hostname, dev.SerialNumber)
+		}
+		result = append(result, entry)
+	}
+
+	zfsResponse(w, r, map[string]interface{}{
+		"hostname":   pool.Hostname,
+		"pool_name":  pool.PoolName,
+		"health":     pool.Health,
+		"redundancy": zfs.PoolRedundancy(devices),
+		"devices":    result,
+	})
 }
 
 // ZFSDeviceBySerial returns a ZFS device by its serial number
@@ -217,7 +357,7 @@ func ZFSDeviceBySerial(w http.ResponseWriter, r *http.Request) {
 		"pool":   pool,
 	}
 
-	JSONResponse(w, response)
+	zfsResponse(w, r, response)
 }
 
 // ─── ZFS Scrub History Endpoints ─────────────────────────────────────────────
@@ -263,7 +403,7 @@ func ZFSScrubHistory(w http.ResponseWriter, r *http.Request) {
 		history = []zfs.ZFSScrubHistory{}
 	}
 
-	JSONResponse(w, history)
+	zfsResponse(w, r, history)
 }
 
 // ZFSLastScrub returns the most recent scrub for a pool
@@ -297,13 +437,104 @@ func ZFSLastScrub(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if lastScrub == nil {
-		JSONResponse(w, map[string]interface{}{
+		zfsResponse(w, r, map[string]interface{}{
 			"message": "No scrub history available",
 		})
 		return
 	}
 
-	JSONResponse(w, lastScrub)
+	zfsResponse(w, r, lastScrub)
+}
+
+// ZFSPoolProperties returns a pool's reported properties and the ones the
+// zfs.property_rules setting flags
+// GET /api/zfs/pools/{hostname}/{poolname}/properties
+func ZFSPoolProperties(w http.ResponseWriter, r *http.Request) {
+	hostname := r.PathValue("hostname")
+	poolName := r.PathValue("poolname")
+
+	if hostname == "" || poolName == "" {
+		JSONError(w, "Missing hostname or pool name", http.StatusBadRequest)
+		return
+	}
+
+	pool, err := zfs.GetZFSPool(db.DB, hostname, poolName)
+	if err != nil {
+		log.Printf("❌ Failed to get ZFS pool: %v", err)
+		JSONError(w, "Failed to retrieve ZFS pool", http.StatusInternalServerError)
+		return
+	}
+
+	if pool == nil {
+		JSONError(w, "Pool not found", http.StatusNotFound)
+		return
+	}
+
+	props, err := zfs.GetPoolProperties(db.DB, pool.ID)
+	if err != nil {
+		log.Printf("❌ Failed to get pool properties: %v", err)
+		JSONError(w, "Failed to retrieve pool properties", http.StatusInternalServerError)
+		return
+	}
+
+	solidState := zfs.PoolIsSolidState(db.DB, pool.ID)
+	JSONResponse(w, map[string]interface{}{
+		"pool_id":     pool.ID,
+		"hostname":    pool.Hostname,
+		"pool_name":   pool.PoolName,
+		"solid_state": solidState,
+		"properties":  props,
+		"violations":  zfs.CheckPoolProperties(zfs.LoadPropertyRules(db.DB), pool.PoolName, props, solidState),
+	})
+}
+
+// ZFSPoolIOStats returns a pool's sampled read/write activity and the
+// per-vdev breakdown over a period (1h, 24h, 7d, 30d; default 24h)
+// GET /api/zfs/pools/{hostname}/{poolname}/iostat?period=24h
+func ZFSPoolIOStats(w http.ResponseWriter, r *http.Request) {
+	hostname := r.PathValue("hostname")
+	poolName := r.PathValue("poolname")
+
+	if hostname == "" || poolName == "" {
+		JSONError(w, "Missing hostname or pool name", http.StatusBadRequest)
+		return
+	}
+
+	pool, err := zfs.GetZFSPool(db.DB, hostname, poolName)
+	if err != nil {
+		log.Printf("❌ Failed to get ZFS pool: %v", err)
+		JSONError(w, "Failed to retrieve ZFS pool", http.StatusInternalServerError)
+		return
+	}
+
+	if pool == nil {
+		JSONError(w, "Pool not found", http.StatusNotFound)
+		return
+	}
+
+	period := temperature.Period24Hours
+	if p := r.URL.Query().Get("period"); p != "" {
+		period = temperature.ParsePeriod(p)
+	}
+	if period == temperature.PeriodAllTime || period == temperature.Period90Days {
+		period = temperature.Period30Days // iostat history isn't kept longer
+	}
+
+	history, err := zfs.GetPoolIOStatHistory(db.DB, pool.ID, time.Now().Add(-temperature.PeriodToDuration(period)))
+	if err != nil {
+		log.Printf("❌ Failed to get pool iostat history: %v", err)
+		JSONError(w, "Failed to retrieve pool I/O statistics", http.StatusInternalServerError)
+		return
+	}
+
+	zfsResponse(w, r, map[string]interface{}{
+		"pool_id":   pool.ID,
+		"hostname":  pool.Hostname,
+		"pool_name": pool.PoolName,
+		"period":    period,
+		"pool":      history.Pool,
+		"vdevs":     history.Vdevs,
+	})
 }
 
 // ─── ZFS Pool Management Endpoints ───────────────────────────────────────────
@@ -338,6 +569,7 @@ func DeleteZFSPool(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("🗑️  Deleted ZFS pool: %s/%s", hostname, poolName)
+	recordAudit(r, "zfs_pool_delete", "zfs_pool", hostname+"/"+poolName, "")
 	JSONResponse(w, map[string]string{
 		"status":   "deleted",
 		"pool":     poolName,
@@ -345,6 +577,148 @@ func DeleteZFSPool(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ZFSClearErrors asks the pool's agent to run `zpool clear` and, on success,
+// resets the stored error counters so the dashboard reflects it immediately.
+// Body (optional): {"device": "sdb"} to clear a single device.
+// POST /api/zfs/pools/{hostname}/{poolname}/clear-errors
+func ZFSClearErrors(w http.ResponseWriter, r *http.Request) {
+	hostname := r.PathValue("hostname")
+	poolName := r.PathValue("poolname")
+	if hostname == "" || poolName == "" {
+		JSONError(w, "Missing hostname or pool name", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Device string `json:"device"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			JSONError(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+	}
+
+	pool, err := zfs.GetZFSPool(db.DB, hostname, poolName)
+	if err != nil {
+		log.Printf("❌ Failed to check ZFS pool: %v", err)
+		JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if pool == nil {
+		JSONError(w, "Pool not found", http.StatusNotFound)
+		return
+	}
+
+	agentURL, ok := agentCommandURL(w, hostname, "/api/zfs/clear")
+	if !ok {
+		return
+	}
+
+	// The timestamp and signature let an agent with a report HMAC secret
+	// reject forged or replayed requests.
+	body, _ := json.Marshal(map[string]interface{}{"pool": poolName, "device": req.Device, "ts": time.Now().Unix()})
+	agentReq, err := http.NewRequestWithContext(r.Context(), http.MethodPost, agentURL, bytes.NewReader(body))
+	if err != nil {
+		JSONError(w, "Failed to build agent request", http.StatusInternalServerError)
+		return
+	}
+	agentReq.Header.Set("Content-Type", "application/json")
+	if len(ReportHMACSecret) > 0 {
+		agentReq.Header.Set(ReportSignatureHeader, signAgentCommand(body))
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(agentReq) // #nosec G107 G704 -- host validated in agentCommandURL
+	if err != nil {
+		log.Printf("⚠️  zpool clear proxy to %s failed: %v", agentURL, err)
+		JSONError(w, "Failed to reach agent command server", http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(resp.StatusCode)
+		w.Write(respBody) //nolint:errcheck
+		return
+	}
+
+	cleared, err := zfs.ClearZFSDeviceErrors(db.DB, pool.ID, req.Device)
+	if err != nil {
+		log.Printf("⚠️  zpool clear succeeded but resetting stored counters failed: %v", err)
+	}
+
+	log.Printf("🧹 Cleared ZFS errors: %s/%s %s", hostname, poolName, req.Device)
+	recordAudit(r, "zfs_clear_errors", "zfs_pool", hostname+"/"+poolName, req.Device)
+	JSONResponse(w, map[string]interface{}{
+		"status":          "cleared",
+		"hostname":        hostname,
+		"pool":            poolName,
+		"device":          req.Device,
+		"devices_updated": cleared,
+	})
+}
+
+// DeleteStaleZFSPoolDevices removes device rows for a pool that were not in
+// the pool's latest report (e.g. a replaced disk). ?older_than_hours=N
+// instead removes devices not seen in the last N hours. Device last_seen
+// values are written in local time, so cutoffs are compared in local time.
+// DELETE /api/zfs/pools/{hostname}/{poolname}/devices/stale
+func DeleteStaleZFSPoolDevices(w http.ResponseWriter, r *http.Request) {
+	hostname := r.PathValue("hostname")
+	poolName := r.PathValue("poolname")
+	if hostname == "" || poolName == "" {
+		JSONError(w, "Missing hostname or pool name", http.StatusBadRequest)
+		return
+	}
+
+	pool, err := zfs.GetZFSPool(db.DB, hostname, poolName)
+	if err != nil {
+		log.Printf("❌ Failed to check ZFS pool: %v", err)
+		JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if pool == nil {
+		JSONError(w, "Pool not found", http.StatusNotFound)
+		return
+	}
+
+	// By default anything older than the latest ingest (less a minute of
+	// slack for devices written during the same report) is stale.
+	latest, err := zfs.LatestZFSDeviceSeen(db.DB, pool.ID)
+	if err != nil {
+		log.Printf("❌ Failed to read ZFS device timestamps: %v", err)
+		JSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	cutoff := latest.Add(-time.Minute).In(time.Local)
+	if h := r.URL.Query().Get("older_than_hours"); h != "" {
+		hours, err := strconv.Atoi(h)
+		if err != nil || hours < 1 {
+			JSONError(w, "older_than_hours must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		cutoff = time.Now().Add(-time.Duration(hours) * time.Hour)
+	}
+
+	deleted, err := zfs.DeleteStaleZFSDevices(db.DB, pool.ID, cutoff)
+	if err != nil {
+		log.Printf("❌ Failed to delete stale ZFS devices: %v", err)
+		JSONError(w, "Failed to delete stale devices", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("🗑️  Removed %d stale device(s) from ZFS pool %s/%s", deleted, hostname, poolName)
+	recordAudit(r, "zfs_delete_stale_devices", "zfs_pool", hostname+"/"+poolName, fmt.Sprintf("deleted: %d", deleted))
+	JSONResponse(w, map[string]interface{}{
+		"status":   "deleted",
+		"hostname": hostname,
+		"pool":     poolName,
+		"deleted":  deleted,
+	})
+}
+
 // ─── ZFS Health Check Endpoint ───────────────────────────────────────────────
 
 // ZFSHealthCheck returns pools that need attention
@@ -367,6 +741,11 @@ func ZFSHealthCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Same thresholds as the capacity and fragmentation notifications
+	capWarning := settings.GetInt(db.DB, "zfs", "capacity_warning_pct", 80)
+	capCritical := settings.GetInt(db.DB, "zfs", "capacity_critical_pct", 90)
+	fragWarning := settings.GetInt(db.DB, "zfs", "fragmentation_warning_pct", 75)
+
 	var needsAttention []map[string]interface{}
 	for _, pool := range pools {
 		issues := []string{}
@@ -389,13 +768,13 @@ func ZFSHealthCheck(w http.ResponseWriter, r *http.Request) {
 			issues = append(issues, "Checksum errors detected")
 		}
 
-		if pool.CapacityPct >= 90 {
-			issues = append(issues, "Capacity above 90%")
-		} else if pool.CapacityPct >= 80 {
-			issues = append(issues, "Capacity above 80%")
+		if pool.CapacityPct >= capCritical {
+			issues = append(issues, fmt.Sprintf("Capacity above %d%%", capCritical))
+		} else if pool.CapacityPct >= capWarning {
+			issues = append(issues, fmt.Sprintf("Capacity above %d%%", capWarning))
 		}
 
-		if pool.Fragmentation >= 75 {
+		if pool.Fragmentation >= fragWarning {
 			issues = append(issues, "High fragmentation")
 		}
 
@@ -478,9 +857,10 @@ func ZFSDriveInfo(w http.ResponseWriter, r *http.Request) {
 
 // ─── Report Handler ──────────────────────────────────────────────────────────
 
-// ProcessZFSFromReport extracts and processes ZFS data from an agent report.
-// When EventBus is set, pool degradation and device failure events are published.
-func ProcessZFSFromReport(hostname string, payload map[string]interface{}) {
+// ProcessZFSFromReport extracts and processes ZFS data from an agent report
+// collected at collectedAt (zero for a live report).
+// When bus is set, pool degradation and device failure events are published.
+func ProcessZFSFromReport(bus *events.Bus, hostname string, payload map[string]interface{}, collectedAt time.Time) {
 	zfsData, ok := payload["zfs"]
 	if !ok || zfsData == nil {
 		return
@@ -492,12 +872,12 @@ func ProcessZFSFromReport(hostname string, payload map[string]interface{}) {
 		return
 	}
 
-	if EventBus != nil {
-		if err := zfs.ProcessZFSReportWithEvents(db.DB, EventBus, hostname, zfsJSON); err != nil {
+	if bus != nil {
+		if err := zfs.ProcessZFSReportWithEventsAt(db.DB, bus, hostname, zfsJSON, collectedAt); err != nil {
 			log.Printf("⚠️  Failed to process ZFS report: %v", err)
 		}
 	} else {
-		if err := zfs.ProcessZFSReport(db.DB, hostname, zfsJSON); err != nil {
+		if err := zfs.ProcessZFSReportAt(db.DB, hostname, zfsJSON, collectedAt); err != nil {
 			log.Printf("⚠️  Failed to process ZFS report: %v", err)
 		}
 	}
@@ -526,7 +906,7 @@ func ZFSDatasets(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	JSONResponse(w, datasets)
+	zfsResponse(w, r, datasets)
 }
 
 // ZFSAllDevices returns every pool-device across every host.
@@ -538,7 +918,36 @@ func ZFSAllDevices(w http.ResponseWriter, r *http.Request) {
 		JSONError(w, "Failed to retrieve devices", http.StatusInternalServerError)
 		return
 	}
-	JSONResponse(w, devices)
+	zfsResponse(w, r, devices)
+}
+
+// ZFSSpares returns every hot spare across every pool with its state, plus
+// counts per state so a glance shows whether any pool has used up its
+// spares.
+// GET /api/zfs/spares
+func ZFSSpares(w http.ResponseWriter, r *http.Request) {
+	spares, err := zfs.GetAllZFSSpares(db.DB)
+	if err != nil {
+		log.Printf("❌ Failed to get ZFS spares: %v", err)
+		JSONError(w, "Failed to retrieve spares", http.StatusInternalServerError)
+		return
+	}
+
+	counts := map[string]int{
+		zfs.SpareAvailable:   0,
+		zfs.SpareInUse:       0,
+		zfs.SpareUnavailable: 0,
+		zfs.SpareUnknown:     0,
+	}
+	for _, s := range spares {
+		counts[s.Status]++
+	}
+
+	zfsResponse(w, r, map[string]interface{}{
+		"spares": spares,
+		"total":  len(spares),
+		"counts": counts,
+	})
 }
 
 // ZFSAllScrubHistory returns the most recent scrub runs across every host.
@@ -556,26 +965,32 @@ func ZFSAllScrubHistory(w http.ResponseWriter, r *http.Request) {
 		JSONError(w, "Failed to retrieve scrub history", http.StatusInternalServerError)
 		return
 	}
-	JSONResponse(w, records)
+	zfsResponse(w, r, records)
 }
 
 // ─── Route Registration ──────────────────────────────────────────────────────
 
 // RegisterZFSRoutes registers all ZFS API routes
 func RegisterZFSRoutes(mux *http.ServeMux, authMiddleware func(http.HandlerFunc) http.HandlerFunc) {
-	mux.HandleFunc("GET /api/zfs/pools", authMiddleware(ZFSPools))
+	mux.HandleFunc("GET /api/zfs/pools", authMiddleware(middleware.ETag(ZFSPools)))
 	mux.HandleFunc("GET /api/zfs/pools/{hostname}/{poolname}", authMiddleware(ZFSPool))
 	mux.HandleFunc("DELETE /api/zfs/pools/{hostname}/{poolname}", authMiddleware(DeleteZFSPool))
+	mux.HandleFunc("POST /api/zfs/pools/{hostname}/{poolname}/clear-errors", authMiddleware(ZFSClearErrors))
 
 	mux.HandleFunc("GET /api/zfs/pools/{hostname}/{poolname}/devices", authMiddleware(ZFSPoolDevices))
+	mux.HandleFunc("GET /api/zfs/pools/{hostname}/{poolname}/devices/health", authMiddleware(ZFSPoolDevicesHealth))
+	mux.HandleFunc("DELETE /api/zfs/pools/{hostname}/{poolname}/devices/stale", authMiddleware(DeleteStaleZFSPoolDevices))
 	mux.HandleFunc("GET /api/zfs/devices/serial/{hostname}/{serial}", authMiddleware(ZFSDeviceBySerial))
 
 	mux.HandleFunc("GET /api/zfs/pools/{hostname}/{poolname}/scrubs", authMiddleware(ZFSScrubHistory))
 	mux.HandleFunc("GET /api/zfs/pools/{hostname}/{poolname}/scrubs/last", authMiddleware(ZFSLastScrub))
+	mux.HandleFunc("GET /api/zfs/pools/{hostname}/{poolname}/properties", authMiddleware(ZFSPoolProperties))
+	mux.HandleFunc("GET /api/zfs/pools/{hostname}/{poolname}/iostat", authMiddleware(ZFSPoolIOStats))
 
 	mux.HandleFunc("GET /api/zfs/datasets", authMiddleware(ZFSDatasets))
 	mux.HandleFunc("GET /api/zfs/devices", authMiddleware(ZFSAllDevices))
 	mux.HandleFunc("GET /api/zfs/scrubs", authMiddleware(ZFSAllScrubHistory))
+	mux.HandleFunc("GET /api/zfs/spares", authMiddleware(ZFSSpares))
 
 	mux.HandleFunc("GET /api/zfs/summary", authMiddleware(ZFSPoolSummary))
 	mux.HandleFunc("GET /api/zfs/health", authMiddleware(ZFSHealthCheck))
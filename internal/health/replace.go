@@ -0,0 +1,252 @@
+package health
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	agentsmart "vigil/cmd/agent/smart"
+	"vigil/internal/smart"
+	"vigil/internal/wearout"
+)
+
+// Replace-priority weights. The factors add up to at most 100: a drive
+// failing SMART with heavy wear, past its service life and still degrading
+// sits at the top.
+const (
+	replaceSmartCritical = 40.0
+	replaceSmartWarning  = 15.0
+	replaceWearoutMax    = 30.0
+	replaceAgeMax        = 15.0
+	replaceTrendMax      = 15.0
+
+	// replaceTrendPerCounter is added for each degradation counter that
+	// grew over the trend window.
+	replaceTrendPerCounter = 5.0
+	// replaceTrendHorizonMonths: a wearout prediction inside this horizon
+	// adds trend points, scaled by how close it is.
+	replaceTrendHorizonMonths = 12.0
+
+	// typicalServiceLifeHours is the age at which drives are commonly
+	// retired (5 years powered on), independent of the far longer MTBF
+	// ratings the wearout score uses.
+	typicalServiceLifeHours = 5 * 8766
+)
+
+// degradationCounters are the attributes whose recent growth means a drive
+// is actively getting worse: reallocated, pending and uncorrectable sectors,
+// and NVMe media errors (187 on both).
+var degradationCounters = []int{
+	wearout.AttrReallocatedSectors,
+	wearout.AttrNVMeMediaErrors,
+	wearout.AttrPendingSectors,
+	wearout.AttrUncorrectable,
+}
+
+// ReplaceFactor is one contribution to a drive's replace priority.
+type ReplaceFactor struct {
+	Name    string  `json:"name"`
+	Points  float64 `json:"points"`
+	Max     float64 `json:"max"`
+	Details string  `json:"details"`
+}
+
+// ReplaceCandidate is a drive ranked by how soon it should be replaced.
+type ReplaceCandidate struct {
+	Hostname       string          `json:"hostname"`
+	SerialNumber   string          `json:"serial_number"`
+	ModelName      string          `json:"model_name"`
+	DriveType      string          `json:"drive_type"`
+	Priority       float64         `json:"priority"`
+	OverallHealth  string          `json:"overall_health"`
+	WearoutPercent *float64        `json:"wearout_percent,omitempty"`
+	PowerOnHours   int64           `json:"power_on_hours"`
+	MonthsLeft     *float64        `json:"months_remaining,omitempty"`
+	Factors        []ReplaceFactor `json:"factors"`
+}
+
+// replaceInput is everything scoreReplacement looks at for one drive.
+type replaceInput struct {
+	health       *agentsmart.DriveHealthAnalysis
+	wearout      *float64
+	prediction   *wearout.TrendPrediction
+	powerOnHours int64
+	increases    map[int]int64
+	trendDays    int
+}
+
+// ReplacePriorities ranks every monitored drive by replace priority and
+// returns the top limit (all of them if limit <= 0).
+func ReplacePriorities(db *sql.DB, limit int) ([]ReplaceCandidate, error) {
+	summaries, err := smart.GetAllDrivesHealthSummary(db)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := wearout.GetAllLatestSnapshots(db)
+	if err != nil {
+		return nil, err
+	}
+	wear := make(map[string]float64, len(snapshots))
+	for _, s := range snapshots {
+		wear[s.Hostname+":"+s.SerialNumber] = s.Percentage
+	}
+
+	trendDays := smart.CounterTrendDays(db)
+	candidates := make([]ReplaceCandidate, 0, len(summaries))
+	for _, h := range summaries {
+		attrs, err := smart.GetLatestSmartAttributes(db, h.Hostname, h.SerialNumber)
+		if err != nil {
+			continue
+		}
+		in := replaceInput{
+			health:    h,
+			increases: degradationIncreases(db, h.Hostname, h.SerialNumber, attrs, trendDays),
+			trendDays: trendDays,
+		}
+		for _, a := range attrs {
+			if a.ID == wearout.AttrPowerOnHours {
+				in.powerOnHours = a.RawValue
+			}
+		}
+		if pct, ok := wear[h.Hostname+":"+h.SerialNumber]; ok {
+			in.wearout = &pct
+			if history, err := wearout.GetSnapshotHistory(db, h.Hostname, h.SerialNumber, 365); err == nil {
+				in.prediction = wearout.PredictTrend(history)
+			}
+		}
+		candidates = append(candidates, scoreReplacement(in))
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Priority > candidates[j].Priority
+	})
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates, nil
+}
+
+// scoreReplacement combines SMART health, wearout, age and recent
+// degradation into a 0–100 replace priority.
+func scoreReplacement(in replaceInput) ReplaceCandidate {
+	h := in.health
+	c := ReplaceCandidate{
+		Hostname:       h.Hostname,
+		SerialNumber:   h.SerialNumber,
+		ModelName:      h.ModelName,
+		DriveType:      h.DriveType,
+		OverallHealth:  h.OverallHealth,
+		WearoutPercent: in.wearout,
+		PowerOnHours:   in.powerOnHours,
+	}
+
+	// SMART health
+	smartFactor := ReplaceFactor{Name: "SMART health", Max: replaceSmartCritical, Details: "healthy"}
+	switch strings.ToUpper(h.OverallHealth) {
+	case "CRITICAL":
+		smartFactor.Points = replaceSmartCritical
+		smartFactor.Details = pluralize(h.CriticalCount, "critical issue")
+	case "WARNING":
+		smartFactor.Points = replaceSmartWarning
+		smartFactor.Details = pluralize(h.WarningCount, "warning")
+	}
+
+	// Wearout (SSD endurance used, HDD hours vs MTBF, sectors, load cycles)
+	wearFactor := ReplaceFactor{Name: "Wearout", Max: replaceWearoutMax, Details: "no wearout data"}
+	if in.wearout != nil {
+		wearFactor.Points = clampFloat(*in.wearout/100, 0, 1) * replaceWearoutMax
+		wearFactor.Details = formatPct(*in.wearout) + "% worn"
+	}
+
+	// Age vs typical service life
+	ageFactor := ReplaceFactor{Name: "Age", Max: replaceAgeMax, Details: "unknown power-on hours"}
+	if in.powerOnHours > 0 {
+		ageFactor.Points = clampFloat(float64(in.powerOnHours)/typicalServiceLifeHours, 0, 1) * replaceAgeMax
+		ageFactor.Details = fmt.Sprintf("%.1f years powered on", float64(in.powerOnHours)/8766)
+	}
+
+	// Recent degradation
+	trendFactor := ReplaceFactor{Name: "Degradation trend", Max: replaceTrendMax}
+	var notes []string
+	ids := make([]int, 0, len(in.increases))
+	for id := range in.increases {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		trendFactor.Points += replaceTrendPerCounter
+		name := fmt.Sprintf("attribute %d", id)
+		if def, ok := agentsmart.GetAttributeDefinition(id); ok {
+			name = def.Name
+		}
+		notes = append(notes, fmt.Sprintf("%s +%d", name, in.increases[id]))
+	}
+	if p := in.prediction; p != nil && p.MonthsRemaining != nil {
+		c.MonthsLeft = p.MonthsRemaining
+		if m := *p.MonthsRemaining; m < replaceTrendHorizonMonths {
+			trendFactor.Points += (1 - clampFloat(m/replaceTrendHorizonMonths, 0, 1)) * replaceTrendMax
+			notes = append(notes, fmt.Sprintf("worn out in ~%.0f months", m))
+		}
+	}
+	trendFactor.Points = clampFloat(trendFactor.Points, 0, replaceTrendMax)
+	if len(notes) == 0 {
+		trendFactor.Details = fmt.Sprintf("stable over %d days", in.trendDays)
+	} else {
+		trendFactor.Details = strings.Join(notes, ", ")
+	}
+
+	c.Factors = []ReplaceFactor{smartFactor, wearFactor, ageFactor, trendFactor}
+	for _, f := range c.Factors {
+		c.Priority += f.Points
+	}
+	c.Priority = float64(int(c.Priority*10+0.5)) / 10
+	return c
+}
+
+// degradationIncreases returns how much each non-zero degradation counter
+// grew over the last days, omitting counters that didn't grow. The counters
+// only ever rise (pending sectors can fall when remapped), so growth is the
+// latest value minus the window's minimum.
+func degradationIncreases(db *sql.DB, hostname, serial string, attrs []agentsmart.SmartAttribute, days int) map[int]int64 {
+	if days <= 0 {
+		return nil
+	}
+	since := time.Now().AddDate(0, 0, -days).Format("2006-01-02 15:04:05")
+
+	latest := make(map[int]int64, len(attrs))
+	for _, a := range attrs {
+		latest[a.ID] = a.RawValue
+	}
+
+	increases := make(map[int]int64)
+	for _, id := range degradationCounters {
+		raw, ok := latest[id]
+		if !ok || raw <= 0 {
+			continue
+		}
+		var min sql.NullInt64
+		err := db.QueryRow(`
+			SELECT MIN(raw_value) FROM smart_attributes
+			WHERE hostname = ? AND serial_number = ? AND attribute_id = ? AND timestamp >= ?
+		`, hostname, serial, id, since).Scan(&min)
+		if err != nil || !min.Valid {
+			continue
+		}
+		if inc := raw - min.Int64; inc > 0 {
+			increases[id] = inc
+		}
+	}
+	return increases
+}
+
+func clampFloat(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,47 @@
+package health
+
+import (
+	"testing"
+
+	agentsmart "vigil/cmd/agent/smart"
+	"vigil/internal/wearout"
+)
+
+func TestScoreReplacement(t *testing.T) {
+	months := 3.0
+	worn := 80.0
+	old := scoreReplacement(replaceInput{
+		health: &agentsmart.DriveHealthAnalysis{
+			Hostname: "nas", SerialNumber: "OLD", OverallHealth: agentsmart.SeverityCritical, CriticalCount: 1,
+		},
+		wearout:      &worn,
+		prediction:   &wearout.TrendPrediction{MonthsRemaining: &months},
+		powerOnHours: 2 * typicalServiceLifeHours,
+		increases:    map[int]int64{wearout.AttrReallocatedSectors: 8},
+		trendDays:    30,
+	})
+	// 40 + 24 + 15 + min(5 + 11.25, 15)
+	if old.Priority != 94 {
+		t.Errorf("priority = %v, want 94", old.Priority)
+	}
+	if len(old.Factors) != 4 {
+		t.Fatalf("expected 4 factors, got %d", len(old.Factors))
+	}
+	for _, f := range old.Factors {
+		if f.Points > f.Max {
+			t.Errorf("%s: %v points exceeds max %v", f.Name, f.Points, f.Max)
+		}
+	}
+
+	healthy := scoreReplacement(replaceInput{
+		health:       &agentsmart.DriveHealthAnalysis{Hostname: "nas", SerialNumber: "NEW", OverallHealth: agentsmart.SeverityHealthy},
+		powerOnHours: typicalServiceLifeHours / 10,
+		trendDays:    30,
+	})
+	if healthy.Priority != 1.5 {
+		t.Errorf("healthy priority = %v, want 1.5", healthy.Priority)
+	}
+	if healthy.Factors[3].Details != "stable over 30 days" {
+		t.Errorf("trend details = %q", healthy.Factors[3].Details)
+	}
+}
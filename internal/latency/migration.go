@@ -0,0 +1,43 @@
+package latency
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// Migrate creates the latency_history table.
+func Migrate(db *sql.DB) error {
+	log.Println("⏱️  Running migration: Drive latency tables")
+
+	statements := []struct {
+		label string
+		sql   string
+	}{
+		{"latency_history", `
+			CREATE TABLE IF NOT EXISTS latency_history (
+				id            INTEGER PRIMARY KEY AUTOINCREMENT,
+				hostname      TEXT    NOT NULL,
+				serial_number TEXT    NOT NULL,
+				avg_us        REAL    NOT NULL,
+				p99_us        REAL    NOT NULL,
+				min_us        REAL    DEFAULT 0,
+				max_us        REAL    DEFAULT 0,
+				samples       INTEGER DEFAULT 0,
+				timestamp     DATETIME DEFAULT CURRENT_TIMESTAMP
+			);`},
+		{"latency_history indexes", `
+			CREATE INDEX IF NOT EXISTS idx_latency_host_serial ON latency_history(hostname, serial_number);
+			CREATE INDEX IF NOT EXISTS idx_latency_timestamp   ON latency_history(timestamp);`},
+	}
+
+	for _, s := range statements {
+		if _, err := db.Exec(s.sql); err != nil {
+			return fmt.Errorf("latency migration failed at [%s]: %w", s.label, err)
+		}
+		log.Printf("  ✓ %s", s.label)
+	}
+
+	log.Println("⏱️  Migration completed: Drive latency tables ready")
+	return nil
+}
@@ -0,0 +1,134 @@
+// Package latency stores the optional per-drive read-latency probe results
+// agents attach to each drive in their reports (see cmd/agent/latency).
+package latency
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	vigildb "vigil/internal/db"
+)
+
+const timeFormat = "2006-01-02 15:04:05"
+
+// Sample is one latency probe result for a drive. Times are microseconds.
+type Sample struct {
+	ID           int64     `json:"id"`
+	Hostname     string    `json:"hostname"`
+	SerialNumber string    `json:"serial_number"`
+	AvgUs        float64   `json:"avg_us"`
+	P99Us        float64   `json:"p99_us"`
+	MinUs        float64   `json:"min_us"`
+	MaxUs        float64   `json:"max_us"`
+	Samples      int       `json:"samples"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// StoreSample persists a latency probe result.
+func StoreSample(db *sql.DB, s Sample) error {
+	if s.Timestamp.IsZero() {
+		s.Timestamp = time.Now()
+	}
+	_, err := db.Exec(`
+		INSERT INTO latency_history (hostname, serial_number, avg_us, p99_us, min_us, max_us, samples, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, s.Hostname, s.SerialNumber, s.AvgUs, s.P99Us, s.MinUs, s.MaxUs, s.Samples, s.Timestamp.UTC().Format(timeFormat))
+	if err != nil {
+		return fmt.Errorf("store latency sample: %w", err)
+	}
+	return nil
+}
+
+// GetHistory returns latency samples for a drive from the last N days, oldest first.
+func GetHistory(db *sql.DB, hostname, serial string, days int) ([]Sample, error) {
+	since := time.Now().AddDate(0, 0, -days).UTC().Format(timeFormat)
+
+	rows, err := db.Query(`
+		SELECT id, hostname, serial_number, avg_us, p99_us, min_us, max_us, samples, timestamp
+		FROM latency_history
+		WHERE hostname = ? AND serial_number = ? AND timestamp >= ?
+		ORDER BY timestamp ASC
+	`, hostname, serial, since)
+	if err != nil {
+		return nil, fmt.Errorf("get latency history: %w", err)
+	}
+	defer rows.Close()
+
+	out := []Sample{}
+	for rows.Next() {
+		var s Sample
+		var ts string
+		if err := rows.Scan(&s.ID, &s.Hostname, &s.SerialNumber, &s.AvgUs, &s.P99Us, &s.MinUs, &s.MaxUs, &s.Samples, &ts); err != nil {
+			return nil, fmt.Errorf("scan latency sample: %w", err)
+		}
+		s.Timestamp = vigildb.ParseTime(ts)
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// PurgeOld deletes samples older than the given number of days.
+// A days value of 0 or less is a no-op ("keep forever").
+func PurgeOld(db *sql.DB, days int) (int64, error) {
+	if days <= 0 {
+		return 0, nil
+	}
+	res, err := db.Exec(`DELETE FROM latency_history WHERE timestamp < datetime('now', ?)`,
+		fmt.Sprintf("-%d days", days))
+	if err != nil {
+		return 0, fmt.Errorf("purge latency history: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// ProcessReport stores the "latency" object of every drive in an agent
+// report. Drives without a probe result (probe disabled, SSD skipped,
+// ioping missing) are ignored.
+func ProcessReport(db *sql.DB, hostname string, payload map[string]interface{}) {
+	ProcessReportAt(db, hostname, payload, time.Time{})
+}
+
+// ProcessReportAt is ProcessReport for a report collected at the given
+// time, e.g. one imported after the fact. A zero time means now.
+func ProcessReportAt(db *sql.DB, hostname string, payload map[string]interface{}, collectedAt time.Time) {
+	drives, ok := payload["drives"].([]interface{})
+	if !ok {
+		return
+	}
+
+	for _, d := range drives {
+		drive, ok := d.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		serial, _ := drive["serial_number"].(string)
+		lat, ok := drive["latency"].(map[string]interface{})
+		if serial == "" || !ok {
+			continue
+		}
+
+		s := Sample{
+			Hostname:     hostname,
+			SerialNumber: serial,
+			AvgUs:        floatField(lat, "avg_us"),
+			P99Us:        floatField(lat, "p99_us"),
+			MinUs:        floatField(lat, "min_us"),
+			MaxUs:        floatField(lat, "max_us"),
+			Samples:      int(floatField(lat, "samples")),
+			Timestamp:    collectedAt,
+		}
+		if s.Samples == 0 {
+			continue
+		}
+		if err := StoreSample(db, s); err != nil {
+			log.Printf("⚠️  Failed to store latency for %s/%s: %v", hostname, serial, err)
+		}
+	}
+}
+
+func floatField(m map[string]interface{}, key string) float64 {
+	v, _ := m[key].(float64)
+	return v
+}
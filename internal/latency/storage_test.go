@@ -0,0 +1,100 @@
+package latency
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestProcessReportStoresLatency(t *testing.T) {
+	db := setupTestDB(t)
+
+	payload := map[string]interface{}{
+		"drives": []interface{}{
+			map[string]interface{}{
+				"serial_number": "SN1",
+				"latency": map[string]interface{}{
+					"avg_us": 8200.5, "p99_us": 15000.0, "min_us": 4000.0, "max_us": 15000.0, "samples": 10.0,
+				},
+			},
+			// No probe result — skipped.
+			map[string]interface{}{"serial_number": "SN2"},
+		},
+	}
+	ProcessReport(db, "host1", payload)
+
+	hist, err := GetHistory(db, "host1", "SN1", 1)
+	if err != nil {
+		t.Fatalf("GetHistory: %v", err)
+	}
+	if len(hist) != 1 {
+		t.Fatalf("expected 1 sample, got %d", len(hist))
+	}
+	if hist[0].AvgUs != 8200.5 || hist[0].P99Us != 15000 || hist[0].Samples != 10 {
+		t.Errorf("unexpected sample: %+v", hist[0])
+	}
+	if hist[0].Timestamp.IsZero() {
+		t.Error("expected timestamp to be parsed")
+	}
+
+	hist, _ = GetHistory(db, "host1", "SN2", 1)
+	if len(hist) != 0 {
+		t.Errorf("expected no samples for SN2, got %d", len(hist))
+	}
+}
+
+func TestProcessReportAtStoresCollectionTime(t *testing.T) {
+	db := setupTestDB(t)
+
+	collected := time.Now().Add(-36 * time.Hour).UTC().Truncate(time.Second)
+	payload := map[string]interface{}{
+		"drives": []interface{}{
+			map[string]interface{}{
+				"serial_number": "SN1",
+				"latency":       map[string]interface{}{"avg_us": 8200.5, "samples": 10.0},
+			},
+		},
+	}
+	ProcessReportAt(db, "host1", payload, collected)
+
+	hist, err := GetHistory(db, "host1", "SN1", 7)
+	if err != nil {
+		t.Fatalf("GetHistory: %v", err)
+	}
+	if len(hist) != 1 || !hist[0].Timestamp.Equal(collected) {
+		t.Fatalf("expected one sample at %v, got %+v", collected, hist)
+	}
+}
+
+func TestPurgeOld(t *testing.T) {
+	db := setupTestDB(t)
+
+	StoreSample(db, Sample{Hostname: "h", SerialNumber: "s", AvgUs: 1, P99Us: 2, Samples: 1, Timestamp: time.Now().AddDate(0, 0, -40)})
+	StoreSample(db, Sample{Hostname: "h", SerialNumber: "s", AvgUs: 1, P99Us: 2, Samples: 1})
+
+	n, err := PurgeOld(db, 30)
+	if err != nil {
+		t.Fatalf("PurgeOld: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 purged row, got %d", n)
+	}
+	if n, _ := PurgeOld(db, 0); n != 0 {
+		t.Errorf("days=0 should be a no-op, purged %d", n)
+	}
+}
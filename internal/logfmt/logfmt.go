@@ -0,0 +1,121 @@
+// Package logfmt keeps log output plain ASCII for terminals, consoles and
+// log shippers that mangle the emoji the server and agent log with.
+package logfmt
+
+import (
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// tags replaces the emoji that carry a status with an ASCII tag; other
+// emoji are decoration and are dropped.
+var tags = strings.NewReplacer(
+	"⚠️", "[WARN]",
+	"⚠", "[WARN]",
+	"❌", "[ERR]",
+	"✅", "[OK]",
+	"✓", "[OK]",
+	"ℹ️", "[INFO]",
+	"🚫", "[DENY]",
+	"🔴", "[CRIT]",
+	"🟡", "[WARN]",
+	"🟢", "[OK]",
+	"→", "->",
+	"←", "<-",
+)
+
+// Plain returns s with status emoji replaced by ASCII tags and any other
+// emoji removed.
+func Plain(s string) string {
+	s = tags.Replace(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	dropped := false
+	for _, r := range s {
+		if isEmoji(r) {
+			dropped = true
+			continue
+		}
+		// Don't leave a double space where a leading emoji was.
+		if dropped && r == ' ' && (b.Len() == 0 || strings.HasSuffix(b.String(), " ")) {
+			continue
+		}
+		dropped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // pictographs, emoticons, transport, ...
+		return true
+	case r >= 0x2190 && r <= 0x21FF: // arrows
+		return true
+	case r >= 0x2300 && r <= 0x23FF: // technical (⏱, ⌛)
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // symbols and dingbats
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF:
+		return true
+	case r == 0xFE0F || r == 0x200D: // variation selector, zero-width joiner
+		return true
+	}
+	return false
+}
+
+type plainWriter struct{ w io.Writer }
+
+// Write strips p and reports the whole of p as written, as the log package
+// expects.
+func (pw plainWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(pw.w, Plain(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// PlainWriter wraps w so everything written through it is passed through
+// Plain. Each write must hold whole characters, as log's do.
+func PlainWriter(w io.Writer) io.Writer {
+	return plainWriter{w}
+}
+
+// EmojiEnabled reports whether logs may contain emoji. LOG_EMOJI=false
+// (or 0/no/off) disables them and LOG_EMOJI=true forces them on; when it
+// is unset they are off on Windows and under a locale that isn't UTF-8.
+func EmojiEnabled() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("LOG_EMOJI"))) {
+	case "false", "0", "no", "off":
+		return false
+	case "true", "1", "yes", "on":
+		return true
+	}
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	return utf8Locale()
+}
+
+// utf8Locale reports whether the effective locale is UTF-8. No locale at
+// all, as in most containers, counts as UTF-8: it's what Go writes anyway.
+func utf8Locale() bool {
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := os.Getenv(key); v != "" {
+			v = strings.ToLower(v)
+			return v != "c" && v != "posix" && (strings.Contains(v, "utf-8") || strings.Contains(v, "utf8"))
+		}
+	}
+	return true
+}
+
+// Setup routes the standard logger through PlainWriter unless emoji are
+// enabled. Call it before the first log line.
+func Setup() {
+	if !EmojiEnabled() {
+		log.SetOutput(PlainWriter(log.Writer()))
+	}
+}
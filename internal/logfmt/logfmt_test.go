@@ -0,0 +1,59 @@
+package logfmt
+
+import (
+	"bytes"
+	"log"
+	"testing"
+)
+
+func TestPlain(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"⚠️  Failed to update labels for h1", "[WARN]  Failed to update labels for h1"},
+		{"❌ Key rotation failed", "[ERR] Key rotation failed"},
+		{"✓ ZFS detected", "[OK] ZFS detected"},
+		{"🧹 Cleaned up 3 old records", "Cleaned up 3 old records"},
+		{"🏷️ Drive group created: hot", "Drive group created: hot"},
+		{"Update available: v1 → v2", "Update available: v1 -> v2"},
+		{"Alert: 🔴 Temperature 60°C", "Alert: [CRIT] Temperature 60°C"},
+		{"plain line", "plain line"},
+	}
+	for _, tt := range tests {
+		if got := Plain(tt.in); got != tt.want {
+			t.Errorf("Plain(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPlainWriter(t *testing.T) {
+	var buf bytes.Buffer
+	l := log.New(PlainWriter(&buf), "", 0)
+	l.Printf("🚀 Vigil Server v%s starting...", "1.0")
+
+	if got, want := buf.String(), "Vigil Server v1.0 starting...\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestEmojiEnabled(t *testing.T) {
+	tests := []struct {
+		logEmoji, lang string
+		want           bool
+	}{
+		{"false", "en_US.UTF-8", false},
+		{"true", "C", true},
+		{"", "en_US.UTF-8", true},
+		{"", "C", false},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		t.Setenv("LOG_EMOJI", tt.logEmoji)
+		t.Setenv("LC_ALL", "")
+		t.Setenv("LC_CTYPE", "")
+		t.Setenv("LANG", tt.lang)
+		if got := EmojiEnabled(); got != tt.want {
+			t.Errorf("LOG_EMOJI=%q LANG=%q: EmojiEnabled() = %v, want %v", tt.logEmoji, tt.lang, got, tt.want)
+		}
+	}
+}
@@ -2,11 +2,15 @@ package middleware
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"runtime/debug"
 	"strings"
 	"sync"
 	"time"
@@ -80,10 +84,11 @@ func CSRFCheck(next http.Handler) http.Handler {
 }
 
 // MaxBodySize limits request body size to prevent abuse.
-// The restore endpoint is exempted since it handles its own limit for file uploads.
+// The restore, Scrutiny import and bulk report endpoints are exempted since
+// they handle their own limits for large uploads.
 func MaxBodySize(maxBytes int64, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Body != nil && r.URL.Path != "/api/backups/restore" {
+		if r.Body != nil && r.URL.Path != "/api/backups/restore" && r.URL.Path != "/api/import/scrutiny" && r.URL.Path != "/api/reports/bulk" {
 			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
 		}
 		next.ServeHTTP(w, r)
@@ -124,16 +129,26 @@ func RequestID(next http.Handler) http.Handler {
 type statusRecorder struct {
 	http.ResponseWriter
 	status int
+	// wrote is set once the response has started (or the connection was
+	// hijacked), after which the status can no longer change.
+	wrote bool
 }
 
 func (sr *statusRecorder) WriteHeader(code int) {
 	sr.status = code
+	sr.wrote = true
 	sr.ResponseWriter.WriteHeader(code)
 }
 
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	sr.wrote = true
+	return sr.ResponseWriter.Write(b)
+}
+
 // Hijack implements http.Hijacker for websocket support.
 func (sr *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	if hj, ok := sr.ResponseWriter.(http.Hijacker); ok {
+		sr.wrote = true
 		return hj.Hijack()
 	}
 	return nil, nil, fmt.Errorf("underlying ResponseWriter does not support Hijack")
@@ -147,6 +162,12 @@ func (sr *statusRecorder) Flush() {
 	}
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer, e.g.
+// to extend the write deadline of a long-lived stream.
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
 // Logging logs request details with request ID and response status.
 func Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -163,6 +184,36 @@ func Logging(next http.Handler) http.Handler {
 	})
 }
 
+// ─── Panic Recovery ──────────────────────────────────────────────────────────
+
+// Recover turns a panicking handler into a 500 JSON error instead of a
+// dropped connection, logging the panic with the request and its stack.
+// If the handler had already started its response, the response is left
+// as is and only logged. http.ErrAbortHandler is re-raised: it's the
+// deliberate way to abort a response.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+			log.Printf("[%s] 💥 Panic in %s %s: %v\n%s", GetRequestID(r), r.Method, r.URL.Path, p, debug.Stack())
+			if rec.wrote {
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"Internal server error"}`))
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
+
 // ─── Rate Limiter ────────────────────────────────────────────────────────────
 
 type visitor struct {
@@ -279,3 +330,75 @@ func ExtractIP(r *http.Request) string {
 	}
 	return ip
 }
+
+// ─── Conditional GET (ETag) ─────────────────────────────────────────────────
+
+// etagRecorder buffers a response so its ETag can be computed before
+// anything is sent.
+type etagRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (er *etagRecorder) Header() http.Header         { return er.header }
+func (er *etagRecorder) WriteHeader(code int)        { er.status = code }
+func (er *etagRecorder) Write(b []byte) (int, error) { return er.body.Write(b) }
+
+// ETag wraps a read handler with conditional GET support: 200 responses get
+// an ETag (a hash of the body) and Cache-Control: no-cache, so browsers
+// revalidate on every poll, and a request whose If-None-Match matches gets
+// 304 Not Modified without the body. The handler still runs in full; what
+// is saved is the transfer and the client's re-parse and re-render.
+// Only for handlers that write a bounded response; streams are buffered.
+func ETag(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next(w, r)
+			return
+		}
+
+		rec := &etagRecorder{header: make(http.Header), status: http.StatusOK}
+		next(rec, r)
+
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		if rec.status != http.StatusOK {
+			w.WriteHeader(rec.status)
+			w.Write(rec.body.Bytes()) //nolint:errcheck
+			return
+		}
+
+		sum := sha256.Sum256(rec.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", etag)
+		if w.Header().Get("Cache-Control") == "" {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.Header().Del("Content-Type")
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(rec.body.Bytes()) //nolint:errcheck
+	}
+}
+
+// etagMatches reports whether an If-None-Match header matches etag, using
+// the weak comparison RFC 9110 prescribes for If-None-Match.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -46,4 +46,58 @@ type Config struct {
 	AdminUser   string
 	AdminPass   string
 	AuthEnabled bool
+	// SetupToken, when set, must accompany the first-run setup request
+	// that creates the admin account (only used without ADMIN_PASS).
+	SetupToken string
+	// DisplayTimezone is the IANA zone used for timestamps in API
+	// responses. Empty means the server's local zone (TZ).
+	DisplayTimezone string
+	// ReportHMACSecret, when set, requires every agent report to carry an
+	// X-Vigil-Signature HMAC-SHA256 of its body keyed with this secret.
+	ReportHMACSecret string
+	// DBEncryptionKey, when set, encrypts the raw report JSON in
+	// reports.data with AES-256-GCM. 32 bytes, base64 or hex encoded.
+	DBEncryptionKey string
+	// EmergencyWebhook is called directly, outside notifications, when a
+	// drive reaches temperature.emergency_threshold. Empty disables it.
+	EmergencyWebhook string
+	// SyslogHost, when set, mirrors every event to this syslog server as
+	// RFC 5424, regardless of notification services and their rules.
+	SyslogHost     string
+	SyslogPort     string
+	SyslogProtocol string // udp or tcp
+	SyslogFacility string // name (local0) or code
+	// DBMaxOpenConns caps the SQLite connection pool; 0 means no limit.
+	// 1 serialises everything, reads included.
+	DBMaxOpenConns int
+	// DBSerializeWrites queues report ingestion and retention writes
+	// behind a single lock instead of letting them contend in SQLite.
+	DBSerializeWrites bool
+	// GRPCPort, when set, serves report ingestion over gRPC on this port
+	// alongside the HTTP API. Empty disables it.
+	GRPCPort string
+	// OIDCIssuer, OIDCClientID and OIDCClientSecret enable single sign-on
+	// through an OpenID Connect provider when all three are set.
+	OIDCIssuer       string
+	OIDCClientID     string
+	OIDCClientSecret string
+	// OIDCRedirectURL is the callback URL registered with the provider.
+	// Empty derives it from the request's host.
+	OIDCRedirectURL string
+	// OIDCUsernameClaim names the ID token claim new users are named after.
+	OIDCUsernameClaim string
+	// OIDCAllowedGroups, when non-empty, only admits users whose "groups"
+	// claim contains one of these groups.
+	OIDCAllowedGroups []string
+	// OIDCDefaultAdmin makes users provisioned on first SSO login admins.
+	OIDCDefaultAdmin bool
+	// OIDCDefaultProject names the project users provisioned on first SSO
+	// login join, unless OIDCDefaultAdmin is set. Empty leaves them
+	// without access until an admin grants it.
+	OIDCDefaultProject string
+}
+
+// OIDCEnabled reports whether OpenID Connect login is configured.
+func (c Config) OIDCEnabled() bool {
+	return c.OIDCIssuer != "" && c.OIDCClientID != "" && c.OIDCClientSecret != ""
 }
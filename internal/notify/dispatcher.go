@@ -5,12 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/nicholas-fedor/shoutrrr"
+	"vigil/internal/agents"
 	"vigil/internal/drivegroups"
 	"vigil/internal/events"
+	"vigil/internal/projects"
 )
 
 // Sender abstracts message dispatch so the dispatcher can be tested
@@ -42,11 +45,20 @@ type Dispatcher struct {
 	OnSent func()
 	// OnFailed is called after each failed send (for metrics).
 	OnFailed func()
+	// Syslog, when set, receives every event before any service rule,
+	// pause or quiet hours are considered.
+	Syslog *SyslogSink
 
 	// cooldowns tracks the last dispatch time per (service_id, event_type).
 	mu        sync.Mutex
 	cooldowns map[string]time.Time
 
+	// lastSent and suppressed implement the per-service min_interval_seconds
+	// rate cap: lastSent is the last dispatch per service, suppressed counts
+	// messages dropped since then so the next send can mention them.
+	lastSent   map[int64]time.Time
+	suppressed map[int64]int
+
 	stopCh chan struct{}
 	wg     sync.WaitGroup
 }
@@ -57,11 +69,13 @@ func NewDispatcher(db *sql.DB, bus *events.Bus, sender Sender) *Dispatcher {
 		sender = ShoutrrrSender{}
 	}
 	d := &Dispatcher{
-		db:        db,
-		bus:       bus,
-		sender:    sender,
-		cooldowns: make(map[string]time.Time),
-		stopCh:    make(chan struct{}),
+		db:         db,
+		bus:        bus,
+		sender:     sender,
+		cooldowns:  make(map[string]time.Time),
+		lastSent:   make(map[int64]time.Time),
+		suppressed: make(map[int64]int),
+		stopCh:     make(chan struct{}),
 	}
 	return d
 }
@@ -78,7 +92,8 @@ func (d *Dispatcher) Start() {
 		}
 	})
 
-	d.wg.Add(1)
+	d.wg.Add(2)
+	go d.runScheduler()
 	go func() {
 		defer d.wg.Done()
 		for {
@@ -108,14 +123,38 @@ func (d *Dispatcher) Stop() {
 
 // handle processes a single event against all enabled services.
 func (d *Dispatcher) handle(e events.Event) {
+	if d.Syslog != nil {
+		if err := d.Syslog.Send(e); err != nil {
+			log.Printf("notify: syslog: %v", err)
+		}
+	}
+
 	services, err := ListEnabledServices(d.db)
 	if err != nil {
 		log.Printf("notify: list services: %v", err)
 		return
 	}
 
+	routes := d.projectRoutes()
+
+	// The global kill switch is checked before any service's rules, quiet
+	// hours or rate limit: while it is on, every service the event could
+	// reach gets a paused history entry and nothing else is evaluated.
+	if p := GetGlobalPause(d.db); p.Paused {
+		for _, svc := range services {
+			if routeAllows(routes, svc.ID, e.Hostname) {
+				d.withheld(svc, d.eventRecord(svc, e))
+			}
+		}
+		return
+	}
+
 	for _, svc := range services {
-		allowed, explicit := d.eventRuleAllowed(svc.ID, e)
+		if !routeAllows(routes, svc.ID, e.Hostname) {
+			continue
+		}
+
+		allowed, explicit, cooldownKey := d.eventRuleAllowed(svc.ID, e)
 		if !allowed {
 			continue
 		}
@@ -126,12 +165,44 @@ func (d *Dispatcher) handle(e events.Event) {
 			continue
 		}
 
+		// Recovery messages close out earlier alerts; they only go to
+		// services that asked for healthy notifications, whatever the rules.
+		if recoveryEvents[e.Type] && !svc.NotifyOnHealthy {
+			continue
+		}
+
 		if d.inQuietHours(svc.ID, e) {
 			continue
 		}
 
-		d.dispatch(svc, e)
+		d.dispatch(svc, e, cooldownKey)
+	}
+}
+
+// projectRoutes loads which hosts each project-linked service hears about
+// (see projects.ServiceHosts). A failure is logged and leaves every service
+// unrestricted.
+func (d *Dispatcher) projectRoutes() map[int64]map[string]bool {
+	routes, err := projects.ServiceHosts(d.db)
+	if err != nil {
+		log.Printf("notify: load project routing: %v", err)
 	}
+	return routes
+}
+
+// routeAllows reports whether a service may hear about the host: services
+// linked to projects only hear about those projects' hosts, so events
+// without a host don't reach them either.
+func routeAllows(routes map[int64]map[string]bool, serviceID int64, hostname string) bool {
+	hosts, linked := routes[serviceID]
+	return !linked || hosts[strings.ToLower(hostname)]
+}
+
+// recoveryEvents are published when a drive or pool is confirmed healthy
+// again after a warning or critical state.
+var recoveryEvents = map[events.EventType]bool{
+	events.DriveRecovered:   true,
+	events.ZFSPoolRecovered: true,
 }
 
 // severityAllowed checks the service's severity flags.
@@ -149,11 +220,13 @@ func (d *Dispatcher) severityAllowed(svc NotificationService, sev events.Severit
 }
 
 // eventRuleAllowed checks per-event-type rules and enforces cooldowns.
-// Returns (allowed, explicit) where explicit is true when a DB rule for this
-// event type exists and is enabled. An explicit rule bypasses the service-level
-// severity filter in handle(), allowing specific event types to fire regardless
-// of the global Critical/Warning/Healthy threshold.
-func (d *Dispatcher) eventRuleAllowed(serviceID int64, e events.Event) (allowed bool, explicit bool) {
+// Returns (allowed, explicit, cooldownKey) where explicit is true when a DB
+// rule for this event type exists and is enabled. An explicit rule bypasses
+// the service-level severity filter in handle(), allowing specific event types
+// to fire regardless of the global Critical/Warning/Healthy threshold. A
+// non-empty cooldownKey is claimed by dispatch once the message is actually
+// going out.
+func (d *Dispatcher) eventRuleAllowed(serviceID int64, e events.Event) (allowed bool, explicit bool, cooldownKey string) {
 	// If the event identifies a specific drive (both hostname and serial present),
 	// check for group-specific rules first. Events without a serial (e.g. SnapRAID,
 	// addon events) skip straight to service-level rules.
@@ -173,10 +246,10 @@ func (d *Dispatcher) eventRuleAllowed(serviceID int64, e events.Event) (allowed
 	rules, err := GetEventRules(d.db, serviceID)
 	if err != nil {
 		log.Printf("notify: get rules for service %d: %v", serviceID, err)
-		return true, false // fail open
+		return true, false, "" // fail open
 	}
 	if len(rules) == 0 {
-		return true, false
+		return true, false, ""
 	}
 
 	return d.evaluateRules(serviceID, e, rules, source)
@@ -194,14 +267,15 @@ func (d *Dispatcher) eventSource(e events.Event) string {
 }
 
 // evaluateRules checks a set of event rules against the event, enforcing
-// cooldowns. Used for both service-level and group-level rules.
-func (d *Dispatcher) evaluateRules(serviceID int64, e events.Event, rules []EventRule, source string) (allowed bool, explicit bool) {
+// cooldowns. Used for both service-level and group-level rules. The cooldown
+// isn't started here; its key is returned for claimCooldown.
+func (d *Dispatcher) evaluateRules(serviceID int64, e events.Event, rules []EventRule, source string) (allowed bool, explicit bool, cooldownKey string) {
 	for _, r := range rules {
 		if r.EventType != string(e.Type) {
 			continue
 		}
 		if !r.Enabled {
-			return false, true
+			return false, true, ""
 		}
 
 		// Cooldown check.
@@ -212,26 +286,34 @@ func (d *Dispatcher) evaluateRules(serviceID int64, e events.Event, rules []Even
 			key := fmt.Sprintf("%d:%s:%s", serviceID, e.Type, source)
 			d.mu.Lock()
 			last, seen := d.cooldowns[key]
-			now := time.Now()
+			d.mu.Unlock()
 			if seen {
 				if r.Cooldown < 0 {
-					d.mu.Unlock()
-					return false, true
+					return false, true, ""
 				}
-				if now.Sub(last) < time.Duration(r.Cooldown)*time.Second {
-					d.mu.Unlock()
-					return false, true
+				if time.Since(last) < time.Duration(r.Cooldown)*time.Second {
+					return false, true, ""
 				}
 			}
-			d.cooldowns[key] = now
-			d.mu.Unlock()
+			return true, true, key
 		}
 
-		return true, true
+		return true, true, ""
 	}
 
 	// Event type not in rules list — allow by default, not explicit.
-	return true, false
+	return true, false, ""
+}
+
+// claimCooldown starts the cooldown evaluateRules found for a message that
+// is being sent. An empty key (no cooldown) is a no-op.
+func (d *Dispatcher) claimCooldown(key string) {
+	if key == "" {
+		return
+	}
+	d.mu.Lock()
+	d.cooldowns[key] = time.Now()
+	d.mu.Unlock()
 }
 
 // groupRulesToEventRules converts group-specific rules to the common EventRule
@@ -274,30 +356,121 @@ func (d *Dispatcher) inQuietHours(serviceID int64, e events.Event) bool {
 	return nowMinutes >= start || nowMinutes < end
 }
 
-// dispatch sends the notification and records the result.
-func (d *Dispatcher) dispatch(svc NotificationService, e events.Event) {
-	var cfg serviceConfig
-	if err := json.Unmarshal([]byte(svc.ConfigJSON), &cfg); err != nil {
-		log.Printf("notify: bad config for service %d (%s): %v", svc.ID, svc.Name, err)
+// throttled enforces the service's min_interval_seconds. When a send is
+// allowed it claims the slot immediately; otherwise the message is counted
+// as suppressed so the next send to this service can report it.
+func (d *Dispatcher) throttled(svc NotificationService) bool {
+	if svc.MinIntervalSecs <= 0 {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	if last, ok := d.lastSent[svc.ID]; ok && now.Sub(last) < time.Duration(svc.MinIntervalSecs)*time.Second {
+		d.suppressed[svc.ID]++
+		return true
+	}
+	d.lastSent[svc.ID] = now
+	return false
+}
+
+// takeSuppressed returns and resets the suppressed-message count for a service.
+func (d *Dispatcher) takeSuppressed(serviceID int64) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := d.suppressed[serviceID]
+	delete(d.suppressed, serviceID)
+	return n
+}
+
+// dispatch sends the notification and records the result. A message held
+// back by the global pause, a mute, maintenance or dry run is recorded
+// before the rate limit and the rule's cooldown are claimed, so it doesn't
+// hold back the next message that is really sent.
+func (d *Dispatcher) dispatch(svc NotificationService, e events.Event, cooldownKey string) {
+	rec := d.eventRecord(svc, e)
+	if d.withheld(svc, rec) {
 		return
 	}
-	if cfg.ShoutrrrURL == "" {
-		log.Printf("notify: service %d (%s) has no shoutrrr_url", svc.ID, svc.Name)
+	if d.throttled(svc) {
 		return
 	}
+	d.claimCooldown(cooldownKey)
 
-	msg := formatMessage(e)
-	err := d.sender.Send(cfg.ShoutrrrURL, msg)
+	if n := d.takeSuppressed(svc.ID); n > 0 {
+		rec.Message = fmt.Sprintf("%s\n(%d more notification(s) suppressed by rate limit since last send)", rec.Message, n)
+	}
+	d.send(svc, rec)
+}
 
-	rec := &NotificationRecord{
+// eventRecord is the history record of e's message to svc.
+func (d *Dispatcher) eventRecord(svc NotificationService, e events.Event) *NotificationRecord {
+	return &NotificationRecord{
 		SettingID:    svc.ID,
 		EventType:    string(e.Type),
 		Hostname:     e.Hostname,
 		SerialNumber: e.SerialNumber,
-		Message:      msg,
+		Message:      d.buildMessage(svc, e),
+		Category:     string(e.Cause),
 	}
+}
 
-	if err != nil {
+// deliver sends rec.Message through the service and records the outcome in
+// notification_history.
+func (d *Dispatcher) deliver(svc NotificationService, rec *NotificationRecord) {
+	if d.withheld(svc, rec) {
+		return
+	}
+	d.send(svc, rec)
+}
+
+// withheld records rec without sending it, and returns true, when
+// notifications are paused globally, the service is muted, the host is in
+// maintenance or the service is in dry-run mode.
+func (d *Dispatcher) withheld(svc NotificationService, rec *NotificationRecord) bool {
+	// The global kill switch comes before anything else, service config
+	// included; skipped sends are still recorded.
+	if p := GetGlobalPause(d.db); p.Paused {
+		rec.Status = "paused"
+		log.Printf("notify: notifications paused globally, skipping %s: %s", svc.Name, rec.Message)
+	} else if svc.MutedUntil != nil && time.Now().Before(*svc.MutedUntil) {
+		// A temporarily muted service drops everything, critical included,
+		// but keeps a history entry so nothing vanishes silently.
+		rec.Status = "muted"
+		log.Printf("notify: %s muted until %s, skipping: %s", svc.Name, svc.MutedUntil.Format(time.RFC3339), rec.Message)
+	} else if rec.Hostname != "" && agents.HostInMaintenance(d.db, rec.Hostname) {
+		// Likewise while the host's agent reports itself in maintenance.
+		rec.Status = "maintenance"
+		log.Printf("notify: %s in maintenance, skipping %s: %s", rec.Hostname, svc.Name, rec.Message)
+	} else if svc.DryRun {
+		// Dry-run services go through rules and quiet hours like any
+		// other, but only record what would have been sent. They don't
+		// use up the rate limit or cooldowns of the real sends to come.
+		rec.Status = "would_send"
+		log.Printf("notify: [dry run] %s would send: %s", svc.Name, rec.Message)
+	} else {
+		return false
+	}
+	if _, dbErr := RecordNotification(d.db, rec); dbErr != nil {
+		log.Printf("notify: record history: %v", dbErr)
+	}
+	return true
+}
+
+// send delivers rec.Message through the service's shoutrrr URL and records
+// the outcome.
+func (d *Dispatcher) send(svc NotificationService, rec *NotificationRecord) {
+	var cfg serviceConfig
+	if err := json.Unmarshal([]byte(svc.ConfigJSON), &cfg); err != nil {
+		log.Printf("notify: bad config for service %d (%s): %v", svc.ID, svc.Name, err)
+		return
+	}
+	if cfg.ShoutrrrURL == "" {
+		log.Printf("notify: service %d (%s) has no shoutrrr_url", svc.ID, svc.Name)
+		return
+	}
+
+	if err := d.sender.Send(cfg.ShoutrrrURL, rec.Message); err != nil {
 		rec.Status = "failed"
 		rec.ErrorMessage = err.Error()
 		log.Printf("notify: send to %s failed: %v", svc.Name, err)
@@ -3,13 +3,17 @@ package notify
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"vigil/internal/agents"
 	"vigil/internal/drivegroups"
 	"vigil/internal/events"
+	"vigil/internal/projects"
+	"vigil/internal/settings"
 
 	_ "modernc.org/sqlite"
 )
@@ -146,6 +150,58 @@ func TestDispatcherEnforcesCooldown(t *testing.T) {
 	}
 }
 
+func TestDispatcherEnforcesMinInterval(t *testing.T) {
+	db, bus, sender, d := setupDispatcherTest(t)
+
+	svcID, _ := CreateService(db, &NotificationService{
+		Name:             "rate-capped",
+		ServiceType:      "generic",
+		ConfigJSON:       `{"shoutrrr_url":"generic://example.com"}`,
+		Enabled:          true,
+		NotifyOnCritical: true,
+		MinIntervalSecs:  60,
+	})
+
+	d.Start()
+	defer d.Stop()
+
+	for i := 0; i < 3; i++ {
+		bus.Publish(events.Event{
+			Type:         events.SmartCritical,
+			Severity:     events.SeverityCritical,
+			SerialNumber: fmt.Sprintf("SN%d", i),
+			Message:      "Critical SMART error",
+		})
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if sender.callCount() != 1 {
+		t.Fatalf("expected 1 send (rest rate-limited), got %d", sender.callCount())
+	}
+
+	// Pretend the interval elapsed; the next send reports what was dropped.
+	d.mu.Lock()
+	d.lastSent[svcID] = time.Now().Add(-2 * time.Minute)
+	d.mu.Unlock()
+
+	bus.Publish(events.Event{
+		Type:     events.SmartCritical,
+		Severity: events.SeverityCritical,
+		Message:  "Critical SMART error",
+	})
+	time.Sleep(100 * time.Millisecond)
+
+	if sender.callCount() != 2 {
+		t.Fatalf("expected 2 sends, got %d", sender.callCount())
+	}
+	sender.mu.Lock()
+	last := sender.calls[1]
+	sender.mu.Unlock()
+	if !strings.Contains(last, "2 more notification(s) suppressed") {
+		t.Errorf("expected suppressed count in message, got %q", last)
+	}
+}
+
 func TestDispatcherDisabledEventRule(t *testing.T) {
 	db, bus, sender, d := setupDispatcherTest(t)
 
@@ -289,6 +345,52 @@ func TestDispatcherExplicitRuleBypassesSeverityFilter(t *testing.T) {
 	}
 }
 
+func TestDispatcherRecoveryNeedsNotifyOnHealthy(t *testing.T) {
+	db, bus, sender, d := setupDispatcherTest(t)
+
+	// An explicit rule doesn't send recoveries to a service without
+	// healthy notifications...
+	quiet, _ := CreateService(db, &NotificationService{
+		Name:             "no-healthy",
+		ServiceType:      "generic",
+		ConfigJSON:       `{"shoutrrr_url":"generic://quiet.example.com"}`,
+		Enabled:          true,
+		NotifyOnCritical: true,
+		NotifyOnWarning:  true,
+	})
+	UpsertEventRule(db, &EventRule{ServiceID: quiet, EventType: string(events.DriveRecovered), Enabled: true})
+
+	// ...while one with them gets recoveries without any rule.
+	CreateService(db, &NotificationService{
+		Name:            "healthy",
+		ServiceType:     "generic",
+		ConfigJSON:      `{"shoutrrr_url":"generic://healthy.example.com"}`,
+		Enabled:         true,
+		NotifyOnHealthy: true,
+	})
+
+	d.Start()
+	defer d.Stop()
+
+	bus.Publish(events.Event{
+		Type:         events.DriveRecovered,
+		Severity:     events.SeverityInfo,
+		Hostname:     "nas01",
+		SerialNumber: "SN1",
+		Message:      "✅ Drive SN1 on nas01 recovered",
+	})
+
+	time.Sleep(100 * time.Millisecond)
+
+	if sender.callCount() != 1 {
+		t.Fatalf("expected 1 send to the healthy service, got %d", sender.callCount())
+	}
+	history, _ := RecentHistory(db, 10)
+	if len(history) != 1 || history[0].SettingID == quiet {
+		t.Errorf("expected the recovery to reach only the healthy service, history %+v", history)
+	}
+}
+
 func TestFormatMessage(t *testing.T) {
 	tests := []struct {
 		name string
@@ -316,6 +418,75 @@ func TestFormatMessage(t *testing.T) {
 	}
 }
 
+func TestDispatcherUsesSeverityTemplate(t *testing.T) {
+	db, bus, sender, d := setupDispatcherTest(t)
+
+	CreateService(db, &NotificationService{
+		Name:             "templated",
+		ServiceType:      "generic",
+		ConfigJSON:       `{"shoutrrr_url":"generic://example.com"}`,
+		Enabled:          true,
+		NotifyOnCritical: true,
+		NotifyOnWarning:  true,
+		MessageTemplates: map[string]string{
+			"critical": "DISK ALERT {{.Hostname}}/{{.Serial}} attr={{.Attribute}}",
+			"default":  "{{.Severity}}: {{.Message}}",
+		},
+	})
+
+	d.Start()
+	bus.Publish(events.Event{
+		Type:         events.SmartCritical,
+		Severity:     events.SeverityCritical,
+		Hostname:     "node1",
+		SerialNumber: "SN1",
+		Message:      "reallocated sectors",
+		Metadata:     map[string]string{"attribute_id": "5"},
+	})
+	bus.Publish(events.Event{
+		Type:     events.TempAlert,
+		Severity: events.SeverityWarning,
+		Hostname: "node1",
+		Message:  "temp high",
+	})
+	time.Sleep(100 * time.Millisecond)
+	d.Stop()
+
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	if len(sender.calls) != 2 {
+		t.Fatalf("expected 2 sends, got %d", len(sender.calls))
+	}
+	if got, want := sender.calls[0], "DISK ALERT node1/SN1 attr=5"; got != want {
+		t.Errorf("critical message = %q, want %q", got, want)
+	}
+	if got, want := sender.calls[1], "warning: temp high"; got != want {
+		t.Errorf("default message = %q, want %q", got, want)
+	}
+}
+
+func TestValidateTemplates(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      map[string]string
+		wantErr bool
+	}{
+		{"empty", nil, false},
+		{"valid", map[string]string{"warning": "{{.Hostname}} {{.Temperature}}C"}, false},
+		{"unknown key", map[string]string{"fatal": "x"}, true},
+		{"syntax error", map[string]string{"default": "{{.Hostname"}, true},
+		{"unknown field", map[string]string{"default": "{{.Nope}}"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTemplates(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTemplates() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestParseHHMM(t *testing.T) {
 	tests := []struct {
 		input string
@@ -520,3 +691,322 @@ func TestDispatcherStopDrains(t *testing.T) {
 		t.Error("expected at least 1 dispatch after stop/drain")
 	}
 }
+
+func TestDispatcherDryRunRecordsWithoutSending(t *testing.T) {
+	db, bus, sender, d := setupDispatcherTest(t)
+
+	id, _ := CreateService(db, &NotificationService{
+		Name:             "staging",
+		ServiceType:      "generic",
+		ConfigJSON:       `{"shoutrrr_url":"generic://example.com"}`,
+		Enabled:          true,
+		NotifyOnCritical: true,
+		DryRun:           true,
+	})
+	if svc, _ := GetService(db, id); svc == nil || !svc.DryRun {
+		t.Fatalf("dry_run not persisted: %+v", svc)
+	}
+
+	d.Start()
+	bus.Publish(events.Event{
+		Type:     events.SmartCritical,
+		Severity: events.SeverityCritical,
+		Hostname: "node1",
+		Message:  "SMART failed",
+	})
+	time.Sleep(100 * time.Millisecond)
+	d.Stop()
+
+	if sender.callCount() != 0 {
+		t.Errorf("dry run service should not send, got %d calls", sender.callCount())
+	}
+	history, err := RecentHistory(db, 10)
+	if err != nil {
+		t.Fatalf("RecentHistory: %v", err)
+	}
+	if len(history) != 1 || history[0].Status != "would_send" {
+		t.Fatalf("expected one would_send record, got %+v", history)
+	}
+	if history[0].Message != "[critical] [node1] SMART failed" {
+		t.Errorf("recorded message = %q", history[0].Message)
+	}
+}
+
+func TestDispatcherSkipsMutedService(t *testing.T) {
+	db, bus, sender, d := setupDispatcherTest(t)
+
+	id, _ := CreateService(db, &NotificationService{
+		Name:             "slack",
+		ServiceType:      "generic",
+		ConfigJSON:       `{"shoutrrr_url":"generic://example.com"}`,
+		Enabled:          true,
+		NotifyOnCritical: true,
+	})
+	until := time.Now().Add(time.Hour)
+	if err := SetServiceMute(db, id, &until); err != nil {
+		t.Fatalf("SetServiceMute: %v", err)
+	}
+	if svc, _ := GetService(db, id); svc == nil || !svc.Muted || svc.MutedUntil == nil {
+		t.Fatalf("mute not persisted: %+v", svc)
+	}
+
+	d.Start()
+	bus.Publish(events.Event{
+		Type:     events.SmartCritical,
+		Severity: events.SeverityCritical,
+		Hostname: "node1",
+		Message:  "SMART failed",
+	})
+	time.Sleep(100 * time.Millisecond)
+	d.Stop()
+
+	if sender.callCount() != 0 {
+		t.Errorf("muted service should not send, got %d calls", sender.callCount())
+	}
+	history, err := RecentHistory(db, 10)
+	if err != nil {
+		t.Fatalf("RecentHistory: %v", err)
+	}
+	if len(history) != 1 || history[0].Status != "muted" {
+		t.Fatalf("expected one muted record, got %+v", history)
+	}
+
+	// An expired mute no longer applies.
+	past := time.Now().Add(-time.Minute)
+	if err := SetServiceMute(db, id, &past); err != nil {
+		t.Fatalf("SetServiceMute: %v", err)
+	}
+	if svc, _ := GetService(db, id); svc.Muted {
+		t.Error("expired mute still reported as muted")
+	}
+	if err := SetServiceMute(db, id, nil); err != nil {
+		t.Fatalf("unmute: %v", err)
+	}
+	if svc, _ := GetService(db, id); svc.MutedUntil != nil {
+		t.Errorf("unmute left muted_until = %v", svc.MutedUntil)
+	}
+}
+
+func TestDispatcherMutedSendKeepsRateLimitSlot(t *testing.T) {
+	db, bus, sender, d := setupDispatcherTest(t)
+
+	id, _ := CreateService(db, &NotificationService{
+		Name:             "slack",
+		ServiceType:      "generic",
+		ConfigJSON:       `{"shoutrrr_url":"generic://example.com"}`,
+		Enabled:          true,
+		NotifyOnCritical: true,
+		MinIntervalSecs:  3600,
+	})
+	until := time.Now().Add(time.Hour)
+	if err := SetServiceMute(db, id, &until); err != nil {
+		t.Fatalf("SetServiceMute: %v", err)
+	}
+	evt := events.Event{Type: events.SmartCritical, Severity: events.SeverityCritical, Hostname: "node1", Message: "SMART failed"}
+
+	d.Start()
+	bus.Publish(evt)
+	time.Sleep(100 * time.Millisecond)
+	if err := SetServiceMute(db, id, nil); err != nil {
+		t.Fatalf("unmute: %v", err)
+	}
+	bus.Publish(evt)
+	time.Sleep(100 * time.Millisecond)
+	d.Stop()
+
+	if sender.callCount() != 1 {
+		t.Errorf("expected the first send after unmuting to go out, got %d calls", sender.callCount())
+	}
+}
+
+func TestDispatcherSkipsHostInMaintenance(t *testing.T) {
+	db, bus, sender, d := setupDispatcherTest(t)
+	if err := agents.Migrate(db); err != nil {
+		t.Fatalf("agents.Migrate: %v", err)
+	}
+	if _, err := agents.RegisterAgent(db, "node1", "node1", "fp1", "pk1"); err != nil {
+		t.Fatalf("RegisterAgent: %v", err)
+	}
+	if changed, err := agents.SetAgentMaintenance(db, "node1", true); err != nil || !changed {
+		t.Fatalf("SetAgentMaintenance: changed=%v err=%v", changed, err)
+	}
+
+	CreateService(db, &NotificationService{
+		Name:             "slack",
+		ServiceType:      "generic",
+		ConfigJSON:       `{"shoutrrr_url":"generic://example.com"}`,
+		Enabled:          true,
+		NotifyOnCritical: true,
+	})
+
+	d.Start()
+	bus.Publish(events.Event{Type: events.SmartCritical, Severity: events.SeverityCritical, Hostname: "node1", Message: "SMART failed"})
+	bus.Publish(events.Event{Type: events.SmartCritical, Severity: events.SeverityCritical, Hostname: "node2", Message: "SMART failed"})
+	time.Sleep(100 * time.Millisecond)
+	d.Stop()
+
+	if sender.callCount() != 1 {
+		t.Errorf("expected only node2 to be sent, got %d calls", sender.callCount())
+	}
+	history, err := RecentHistory(db, 10)
+	if err != nil {
+		t.Fatalf("RecentHistory: %v", err)
+	}
+	var skipped int
+	for _, h := range history {
+		if h.Status == "maintenance" {
+			skipped++
+			if h.Hostname != "node1" {
+				t.Errorf("maintenance record for wrong host: %+v", h)
+			}
+		}
+	}
+	if skipped != 1 {
+		t.Fatalf("expected one maintenance record, got %+v", history)
+	}
+
+	if hosts, err := agents.GetMaintenanceHosts(db); err != nil || hosts["node1"].IsZero() || len(hosts) != 1 {
+		t.Errorf("GetMaintenanceHosts = %v, %v", hosts, err)
+	}
+	if changed, _ := agents.SetAgentMaintenance(db, "node1", false); !changed {
+		t.Error("clearing maintenance reported no change")
+	}
+	if agents.HostInMaintenance(db, "node1") {
+		t.Error("node1 still in maintenance after clearing")
+	}
+}
+
+func TestDispatcherGlobalPause(t *testing.T) {
+	db, bus, sender, d := setupDispatcherTest(t)
+	if err := settings.InitSettingsTable(db); err != nil {
+		t.Fatalf("InitSettingsTable: %v", err)
+	}
+	CreateService(db, &NotificationService{
+		Name:             "slack",
+		ServiceType:      "generic",
+		ConfigJSON:       `{"shoutrrr_url":"generic://example.com"}`,
+		Enabled:          true,
+		NotifyOnCritical: true,
+	})
+
+	if err := SetGlobalPause(db, nil); err != nil {
+		t.Fatalf("SetGlobalPause: %v", err)
+	}
+	if p := GetGlobalPause(db); !p.Paused || p.Until != nil {
+		t.Fatalf("GetGlobalPause = %+v, want paused until resumed", p)
+	}
+
+	d.Start()
+	bus.Publish(events.Event{Type: events.SmartCritical, Severity: events.SeverityCritical, Hostname: "node1", Message: "SMART failed"})
+	time.Sleep(100 * time.Millisecond)
+	d.Stop()
+
+	if sender.callCount() != 0 {
+		t.Errorf("paused dispatcher should not send, got %d calls", sender.callCount())
+	}
+	history, err := RecentHistory(db, 10)
+	if err != nil {
+		t.Fatalf("RecentHistory: %v", err)
+	}
+	if len(history) != 1 || history[0].Status != "paused" {
+		t.Fatalf("expected one paused record, got %+v", history)
+	}
+
+	// An expired pause no longer applies.
+	past := time.Now().Add(-time.Minute)
+	if err := SetGlobalPause(db, &past); err != nil {
+		t.Fatalf("SetGlobalPause: %v", err)
+	}
+	if p := GetGlobalPause(db); p.Paused {
+		t.Error("expired pause still reported as paused")
+	}
+	future := time.Now().Add(time.Hour)
+	SetGlobalPause(db, &future)
+	if p := GetGlobalPause(db); !p.Paused || p.Until == nil || p.Until.Unix() != future.Unix() {
+		t.Errorf("GetGlobalPause = %+v, want paused until %v", p, future)
+	}
+	if err := ClearGlobalPause(db); err != nil {
+		t.Fatalf("ClearGlobalPause: %v", err)
+	}
+	if p := GetGlobalPause(db); p.Paused {
+		t.Error("still paused after ClearGlobalPause")
+	}
+}
+
+func TestDispatcherGlobalPauseCheckedFirst(t *testing.T) {
+	db, bus, sender, d := setupDispatcherTest(t)
+	if err := settings.InitSettingsTable(db); err != nil {
+		t.Fatalf("InitSettingsTable: %v", err)
+	}
+	id, _ := CreateService(db, &NotificationService{
+		Name:            "slack",
+		ServiceType:     "generic",
+		ConfigJSON:      `{"shoutrrr_url":"generic://example.com"}`,
+		Enabled:         true,
+		NotifyOnWarning: true,
+	})
+	// Quiet all day: a warning would be dropped there without a trace.
+	if err := UpsertQuietHours(db, &QuietHours{ServiceID: id, StartTime: "00:00", EndTime: "00:00", Enabled: true}); err != nil {
+		t.Fatalf("UpsertQuietHours: %v", err)
+	}
+	if err := SetGlobalPause(db, nil); err != nil {
+		t.Fatalf("SetGlobalPause: %v", err)
+	}
+
+	d.Start()
+	bus.Publish(events.Event{Type: events.SmartWarning, Severity: events.SeverityWarning, Hostname: "node1", Message: "reallocated sectors"})
+	time.Sleep(100 * time.Millisecond)
+	d.Stop()
+
+	if sender.callCount() != 0 {
+		t.Errorf("paused dispatcher should not send, got %d calls", sender.callCount())
+	}
+	history, err := RecentHistory(db, 10)
+	if err != nil {
+		t.Fatalf("RecentHistory: %v", err)
+	}
+	if len(history) != 1 || history[0].Status != "paused" {
+		t.Fatalf("expected the pause to be recorded ahead of quiet hours, got %+v", history)
+	}
+}
+
+func TestDispatcherProjectRouting(t *testing.T) {
+	db, bus, sender, d := setupDispatcherTest(t)
+	if err := projects.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"client-a", "ops"} {
+		CreateService(db, &NotificationService{
+			Name:             name,
+			ServiceType:      "generic",
+			ConfigJSON:       `{"shoutrrr_url":"generic://example.com"}`,
+			Enabled:          true,
+			NotifyOnCritical: true,
+		})
+	}
+	pid, _ := projects.CreateProject(db, &projects.Project{Name: "Client A"})
+	projects.AssignHost(db, pid, "host1")
+	if err := projects.SetServices(db, pid, []int64{1}); err != nil {
+		t.Fatal(err)
+	}
+
+	d.Start()
+	defer d.Stop()
+
+	// client-a only hears about host1; ops, linked to no project, hears
+	// about everything
+	for _, host := range []string{"HOST1", "host2", ""} {
+		bus.Publish(events.Event{
+			Type:     events.SmartCritical,
+			Severity: events.SeverityCritical,
+			Hostname: host,
+			Message:  "critical on " + host,
+		})
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	if sender.callCount() != 4 {
+		t.Errorf("expected 4 sends (2 for host1, 1 each for host2 and no host), got %d", sender.callCount())
+	}
+}
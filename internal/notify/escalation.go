@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"vigil/internal/settings"
+	"vigil/internal/temperature"
+)
+
+// EscalationEventType is the notification_history event_type for
+// escalations of unacknowledged critical alerts.
+const EscalationEventType = "alert_escalation"
+
+// EscalateUnacknowledged re-notifies about critical alerts nobody has
+// acknowledged within the "alerts" / "escalation_minutes" setting, repeating
+// every period until they are acknowledged or the drive recovers. Escalations
+// go to "escalation_service_id" if set, otherwise to every enabled service
+// that notifies on critical. Like critical events they bypass quiet hours
+// and the rate cap.
+func (d *Dispatcher) EscalateUnacknowledged(now time.Time) {
+	minutes := settings.GetInt(d.db, "alerts", "escalation_minutes", 0)
+	if minutes <= 0 {
+		return
+	}
+
+	alerts, err := temperature.AlertsDueForEscalation(d.db, time.Duration(minutes)*time.Minute, now)
+	if err != nil {
+		log.Printf("notify: list alerts due for escalation: %v", err)
+		return
+	}
+	if len(alerts) == 0 {
+		return
+	}
+
+	targets, err := d.escalationTargets()
+	if err != nil {
+		log.Printf("notify: escalation targets: %v", err)
+		return
+	}
+
+	// A drive that stays hot raises a new critical alert every cooldown;
+	// escalate once per drive (about the oldest) and mark them all.
+	escalated := make(map[string]bool)
+	routes := d.projectRoutes()
+	for _, a := range alerts {
+		key := a.Hostname + ":" + a.SerialNumber
+		if !escalated[key] {
+			escalated[key] = true
+			msg := fmt.Sprintf("🚨 ESCALATION: critical alert unacknowledged for %s\n%s (%s)\n%s",
+				now.Sub(a.CreatedAt).Round(time.Minute), a.Hostname, a.SerialNumber, a.Message)
+			for _, svc := range targets {
+				if !routeAllows(routes, svc.ID, a.Hostname) {
+					continue
+				}
+				d.deliver(svc, &NotificationRecord{
+					SettingID:    svc.ID,
+					EventType:    EscalationEventType,
+					Hostname:     a.Hostname,
+					SerialNumber: a.SerialNumber,
+					Message:      msg,
+					Category:     a.Category,
+				})
+			}
+		}
+		// Marked even with no targets or a failed send, so a missing
+		// service doesn't turn into a retry every minute.
+		if err := temperature.MarkAlertEscalated(d.db, a.ID, now); err != nil {
+			log.Printf("notify: %v", err)
+		}
+	}
+}
+
+// escalationTargets returns the services escalations are delivered to.
+func (d *Dispatcher) escalationTargets() ([]NotificationService, error) {
+	if id := settings.GetInt(d.db, "alerts", "escalation_service_id", 0); id > 0 {
+		svc, err := GetService(d.db, int64(id))
+		if err != nil {
+			return nil, err
+		}
+		if svc == nil || !svc.Enabled {
+			return nil, fmt.Errorf("escalation service %d not found or disabled", id)
+		}
+		return []NotificationService{*svc}, nil
+	}
+
+	services, err := ListEnabledServices(d.db)
+	if err != nil {
+		return nil, err
+	}
+	var targets []NotificationService
+	for _, svc := range services {
+		if svc.NotifyOnCritical {
+			targets = append(targets, svc)
+		}
+	}
+	return targets, nil
+}
@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"vigil/internal/events"
+	"vigil/internal/settings"
+	"vigil/internal/temperature"
+)
+
+func TestEscalateAlertFromIngestedReport(t *testing.T) {
+	db, bus, sender, d := setupDispatcherTest(t)
+	if _, err := db.Exec(`CREATE TABLE temperature_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		hostname TEXT NOT NULL,
+		serial_number TEXT NOT NULL,
+		temperature INTEGER NOT NULL,
+		timestamp DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		t.Fatal(err)
+	}
+	if err := temperature.InitializeTables(db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateService(db, &NotificationService{
+		Name:             "oncall",
+		ServiceType:      "generic",
+		ConfigJSON:       `{"shoutrrr_url":"generic://example.com"}`,
+		Enabled:          true,
+		NotifyOnCritical: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var published []events.Event
+	bus.Subscribe(func(e events.Event) { published = append(published, e) })
+
+	report := map[string]interface{}{
+		"drives": []interface{}{
+			map[string]interface{}{
+				"serial_number": "HOT1",
+				"model_name":    "TestHDD",
+				"device":        map[string]interface{}{"name": "/dev/sda"},
+				"temperature":   map[string]interface{}{"current": float64(62)},
+			},
+		},
+	}
+	temperature.ProcessReport(db, bus, "nas01", report)
+
+	if len(published) != 1 || published[0].Type != events.TempCritical || published[0].SerialNumber != "HOT1" {
+		t.Fatalf("expected one temp_critical event for HOT1, got %+v", published)
+	}
+	alerts, err := temperature.GetAlertsByDrive(db, "nas01", "HOT1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || alerts[0].AlertType != temperature.AlertTypeCritical {
+		t.Fatalf("expected one stored critical alert, got %+v", alerts)
+	}
+
+	if err := settings.UpdateSetting(db, "alerts", "escalation_minutes", "30"); err != nil {
+		t.Fatal(err)
+	}
+	d.EscalateUnacknowledged(time.Now().Add(31 * time.Minute))
+
+	if sender.callCount() != 1 {
+		t.Fatalf("expected 1 escalation, got %d sends", sender.callCount())
+	}
+	if msg := sender.calls[0]; !strings.Contains(msg, "ESCALATION") || !strings.Contains(msg, "HOT1") {
+		t.Errorf("unexpected escalation message %q", msg)
+	}
+}
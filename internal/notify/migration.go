@@ -49,6 +49,17 @@ func Migrate(db *sql.DB) error {
 				send_at    TEXT    NOT NULL DEFAULT '08:00',
 				FOREIGN KEY (service_id) REFERENCES notification_settings(id) ON DELETE CASCADE
 			);`},
+
+		// Daily "what changed" summary per service
+		{"notification_summary_config", `
+			CREATE TABLE IF NOT EXISTS notification_summary_config (
+				id           INTEGER PRIMARY KEY AUTOINCREMENT,
+				service_id   INTEGER NOT NULL UNIQUE,
+				enabled      INTEGER DEFAULT 0,
+				send_at      TEXT    NOT NULL DEFAULT '08:00',
+				last_sent_at DATETIME,
+				FOREIGN KEY (service_id) REFERENCES notification_settings(id) ON DELETE CASCADE
+			);`},
 	}
 
 	for _, s := range statements {
@@ -58,6 +69,26 @@ func Migrate(db *sql.DB) error {
 		log.Printf("  ✓ %s", s.label)
 	}
 
+	// Columns added to the base notification_settings table after release.
+	columns := []struct {
+		table, column, ddl string
+	}{
+		{"notification_settings", "min_interval_seconds", "INTEGER DEFAULT 0"},
+		{"notification_settings", "message_templates", "TEXT DEFAULT ''"},
+		{"notification_settings", "dry_run", "INTEGER DEFAULT 0"},
+		{"notification_settings", "muted_until", "DATETIME"},
+		{"notification_history", "category", "TEXT DEFAULT ''"},
+	}
+	for _, c := range columns {
+		if err := addColumnIfMissing(db, c.table, c.column, c.ddl); err != nil {
+			return fmt.Errorf("notification migration failed at [%s.%s]: %w", c.table, c.column, err)
+		}
+	}
+
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_notif_hist_category ON notification_history(category, created_at)`); err != nil {
+		return fmt.Errorf("notification migration failed at [notification_history.category index]: %w", err)
+	}
+
 	// Backfill: ensure monitoring event rules that previously had 0 cooldown
 	// get sensible defaults so notifications are not spammed every report cycle.
 	backfills := []struct {
@@ -78,3 +109,21 @@ func Migrate(db *sql.DB) error {
 	log.Println("🔔 Migration completed: Notification extensions ready")
 	return nil
 }
+
+// addColumnIfMissing adds a column to an existing table unless it is
+// already present, so the migration stays idempotent across restarts.
+func addColumnIfMissing(db *sql.DB, table, column, ddl string) error {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, ddl)); err != nil {
+		return err
+	}
+	log.Printf("  ✓ %s.%s column", table, column)
+	return nil
+}
@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"vigil/internal/settings"
+)
+
+// GlobalPause is the notification kill switch. While it is on no service
+// sends anything; each skipped notification is recorded with status "paused".
+type GlobalPause struct {
+	Paused bool       `json:"paused"`
+	Until  *time.Time `json:"until"` // nil while paused means until resumed
+}
+
+// GetGlobalPause reads the notifications.paused settings. A pause whose
+// expiry has passed reads as not paused.
+func GetGlobalPause(db *sql.DB) GlobalPause {
+	if !settings.GetBool(db, "notifications", "paused", false) {
+		return GlobalPause{}
+	}
+	until := settings.GetInt(db, "notifications", "paused_until", 0)
+	if until <= 0 {
+		return GlobalPause{Paused: true}
+	}
+	t := time.Unix(int64(until), 0).UTC()
+	if !time.Now().Before(t) {
+		return GlobalPause{}
+	}
+	return GlobalPause{Paused: true, Until: &t}
+}
+
+// SetGlobalPause pauses all notifications until the given time, or until
+// ClearGlobalPause when until is nil.
+func SetGlobalPause(db *sql.DB, until *time.Time) error {
+	value := "0"
+	if until != nil {
+		value = strconv.FormatInt(until.Unix(), 10)
+	}
+	return settings.UpdateSettings(db, map[string]map[string]string{
+		"notifications": {"paused": "true", "paused_until": value},
+	})
+}
+
+// ClearGlobalPause lets notifications through again.
+func ClearGlobalPause(db *sql.DB) error {
+	return settings.UpdateSettings(db, map[string]map[string]string{
+		"notifications": {"paused": "false", "paused_until": "0"},
+	})
+}
@@ -2,7 +2,9 @@ package notify
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"vigil/internal/events"
@@ -17,13 +19,17 @@ func CreateService(db *sql.DB, svc *NotificationService) (int64, error) {
 	res, err := db.Exec(`
 		INSERT INTO notification_settings
 			(name, service_type, config_json, enabled,
-			 notify_on_critical, notify_on_warning, notify_on_healthy)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			 notify_on_critical, notify_on_warning, notify_on_healthy,
+			 min_interval_seconds, message_templates, dry_run)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		svc.Name, svc.ServiceType, svc.ConfigJSON,
 		boolInt(svc.Enabled),
 		boolInt(svc.NotifyOnCritical),
 		boolInt(svc.NotifyOnWarning),
-		boolInt(svc.NotifyOnHealthy))
+		boolInt(svc.NotifyOnHealthy),
+		svc.MinIntervalSecs,
+		encodeTemplates(svc.MessageTemplates),
+		boolInt(svc.DryRun))
 	if err != nil {
 		return 0, fmt.Errorf("create notification service: %w", err)
 	}
@@ -35,6 +41,7 @@ func GetService(db *sql.DB, id int64) (*NotificationService, error) {
 	row := db.QueryRow(`
 		SELECT id, name, service_type, config_json, enabled,
 		       notify_on_critical, notify_on_warning, notify_on_healthy,
+		       COALESCE(min_interval_seconds, 0), COALESCE(message_templates, ''), COALESCE(dry_run, 0), COALESCE(muted_until, ''),
 		       created_at, updated_at
 		FROM notification_settings WHERE id = ?`, id)
 	return scanService(row)
@@ -45,6 +52,7 @@ func ListServices(db *sql.DB) ([]NotificationService, error) {
 	rows, err := db.Query(`
 		SELECT id, name, service_type, config_json, enabled,
 		       notify_on_critical, notify_on_warning, notify_on_healthy,
+		       COALESCE(min_interval_seconds, 0), COALESCE(message_templates, ''), COALESCE(dry_run, 0), COALESCE(muted_until, ''),
 		       created_at, updated_at
 		FROM notification_settings ORDER BY name`)
 	if err != nil {
@@ -68,6 +76,7 @@ func ListEnabledServices(db *sql.DB) ([]NotificationService, error) {
 	rows, err := db.Query(`
 		SELECT id, name, service_type, config_json, enabled,
 		       notify_on_critical, notify_on_warning, notify_on_healthy,
+		       COALESCE(min_interval_seconds, 0), COALESCE(message_templates, ''), COALESCE(dry_run, 0), COALESCE(muted_until, ''),
 		       created_at, updated_at
 		FROM notification_settings WHERE enabled = 1 ORDER BY name`)
 	if err != nil {
@@ -92,6 +101,7 @@ func UpdateService(db *sql.DB, svc *NotificationService) error {
 		UPDATE notification_settings SET
 			name = ?, service_type = ?, config_json = ?, enabled = ?,
 			notify_on_critical = ?, notify_on_warning = ?, notify_on_healthy = ?,
+			min_interval_seconds = ?, message_templates = ?, dry_run = ?,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?`,
 		svc.Name, svc.ServiceType, svc.ConfigJSON,
@@ -99,6 +109,9 @@ func UpdateService(db *sql.DB, svc *NotificationService) error {
 		boolInt(svc.NotifyOnCritical),
 		boolInt(svc.NotifyOnWarning),
 		boolInt(svc.NotifyOnHealthy),
+		svc.MinIntervalSecs,
+		encodeTemplates(svc.MessageTemplates),
+		boolInt(svc.DryRun),
 		svc.ID)
 	if err != nil {
 		return fmt.Errorf("update notification service: %w", err)
@@ -106,6 +119,21 @@ func UpdateService(db *sql.DB, svc *NotificationService) error {
 	return expectOneRow(res, "update notification service")
 }
 
+// SetServiceMute silences a service until the given time; nil unmutes it.
+// Kept separate from UpdateService so editing a service doesn't clear a
+// running mute.
+func SetServiceMute(db *sql.DB, id int64, until *time.Time) error {
+	var value interface{}
+	if until != nil {
+		value = until.UTC().Format(timeFormat)
+	}
+	res, err := db.Exec(`UPDATE notification_settings SET muted_until = ? WHERE id = ?`, value, id)
+	if err != nil {
+		return fmt.Errorf("set notification service mute: %w", err)
+	}
+	return expectOneRow(res, "set notification service mute")
+}
+
 // DeleteService removes a notification service and its related rules
 // (cascaded by foreign keys).
 func DeleteService(db *sql.DB, id int64) error {
@@ -272,6 +300,81 @@ func GetDigestConfig(db *sql.DB, serviceID int64) (*DigestConfig, error) {
 	return &dc, nil
 }
 
+// ── SummaryConfig CRUD ──────────────────────────────────────────────────
+
+// UpsertSummaryConfig sets the daily summary configuration for a service.
+// last_sent_at is left alone so re-saving doesn't trigger a second send.
+func UpsertSummaryConfig(db *sql.DB, sc *SummaryConfig) error {
+	_, err := db.Exec(`
+		INSERT INTO notification_summary_config (service_id, enabled, send_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(service_id) DO UPDATE SET
+			enabled = excluded.enabled,
+			send_at = excluded.send_at`,
+		sc.ServiceID, boolInt(sc.Enabled), sc.SendAt)
+	if err != nil {
+		return fmt.Errorf("upsert summary config: %w", err)
+	}
+	return nil
+}
+
+// GetSummaryConfig returns the summary config for a service, or nil if unset.
+func GetSummaryConfig(db *sql.DB, serviceID int64) (*SummaryConfig, error) {
+	sc, err := scanSummaryConfig(db.QueryRow(`
+		SELECT id, service_id, enabled, send_at, COALESCE(last_sent_at, '')
+		FROM notification_summary_config WHERE service_id = ?`, serviceID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get summary config: %w", err)
+	}
+	return &sc, nil
+}
+
+// ListEnabledSummaryConfigs returns summary configs that are switched on.
+func ListEnabledSummaryConfigs(db *sql.DB) ([]SummaryConfig, error) {
+	rows, err := db.Query(`
+		SELECT id, service_id, enabled, send_at, COALESCE(last_sent_at, '')
+		FROM notification_summary_config WHERE enabled = 1 ORDER BY service_id`)
+	if err != nil {
+		return nil, fmt.Errorf("list summary configs: %w", err)
+	}
+	defer rows.Close()
+
+	var out []SummaryConfig
+	for rows.Next() {
+		sc, err := scanSummaryConfig(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan summary config: %w", err)
+		}
+		out = append(out, sc)
+	}
+	return out, rows.Err()
+}
+
+// MarkSummarySent records when a service last received its daily summary.
+func MarkSummarySent(db *sql.DB, serviceID int64, at time.Time) error {
+	_, err := db.Exec(`UPDATE notification_summary_config SET last_sent_at = ? WHERE service_id = ?`,
+		at.UTC().Format(timeFormat), serviceID)
+	if err != nil {
+		return fmt.Errorf("mark summary sent: %w", err)
+	}
+	return nil
+}
+
+func scanSummaryConfig(s scannable) (SummaryConfig, error) {
+	var sc SummaryConfig
+	var enabled int
+	var lastSent string
+	if err := s.Scan(&sc.ID, &sc.ServiceID, &enabled, &sc.SendAt, &lastSent); err != nil {
+		return sc, err
+	}
+	sc.Enabled = enabled == 1
+	sc.LastSentAt = parseTime(lastSent)
+	return sc, nil
+}
+
 // ── NotificationHistory ─────────────────────────────────────────────────
 
 // RecordNotification inserts a row into notification_history.
@@ -283,10 +386,10 @@ func RecordNotification(db *sql.DB, rec *NotificationRecord) (int64, error) {
 
 	res, err := db.Exec(`
 		INSERT INTO notification_history
-			(setting_id, event_type, hostname, serial_number, message, status, error_message, sent_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			(setting_id, event_type, hostname, serial_number, message, category, status, error_message, sent_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		rec.SettingID, rec.EventType, rec.Hostname, rec.SerialNumber,
-		rec.Message, rec.Status, rec.ErrorMessage, sentAt)
+		rec.Message, rec.Category, rec.Status, rec.ErrorMessage, sentAt)
 	if err != nil {
 		return 0, fmt.Errorf("record notification: %w", err)
 	}
@@ -309,14 +412,40 @@ func PurgeOldHistory(db *sql.DB, days int) error {
 
 // RecentHistory returns the latest N notification records.
 func RecentHistory(db *sql.DB, limit int) ([]NotificationRecord, error) {
-	rows, err := db.Query(`
+	return RecentHistoryByCategory(db, limit, "")
+}
+
+// RecentHistoryByCategory returns the latest N notification records of one
+// category (an events.Cause), or of every category when it is empty.
+func RecentHistoryByCategory(db *sql.DB, limit int, category string) ([]NotificationRecord, error) {
+	return RecentHistoryForHosts(db, limit, category, nil)
+}
+
+// RecentHistoryForHosts is RecentHistoryByCategory restricted to records
+// about the given hosts (compared case-insensitively); a nil hosts slice
+// means every record, an empty one none.
+func RecentHistoryForHosts(db *sql.DB, limit int, category string, hosts []string) ([]NotificationRecord, error) {
+	if hosts != nil && len(hosts) == 0 {
+		return nil, nil
+	}
+	query := `
 		SELECT id, COALESCE(setting_id,0), event_type,
 		       COALESCE(hostname,''), COALESCE(serial_number,''),
-		       message, status, COALESCE(error_message,''),
+		       message, COALESCE(category,''), status, COALESCE(error_message,''),
 		       COALESCE(sent_at,''), created_at
 		FROM notification_history
-		WHERE created_at IS NOT NULL
-		ORDER BY created_at DESC, id DESC LIMIT ?`, limit)
+		WHERE created_at IS NOT NULL AND (? = '' OR category = ?)`
+	args := []interface{}{category, category}
+	if hosts != nil {
+		query += ` AND LOWER(hostname) IN (?` + strings.Repeat(", ?", len(hosts)-1) + `)`
+		for _, h := range hosts {
+			args = append(args, strings.ToLower(h))
+		}
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("recent history: %w", err)
 	}
@@ -327,7 +456,7 @@ func RecentHistory(db *sql.DB, limit int) ([]NotificationRecord, error) {
 		var r NotificationRecord
 		var sentAt, createdAt string
 		if err := rows.Scan(&r.ID, &r.SettingID, &r.EventType,
-			&r.Hostname, &r.SerialNumber, &r.Message, &r.Status,
+			&r.Hostname, &r.SerialNumber, &r.Message, &r.Category, &r.Status,
 			&r.ErrorMessage, &sentAt, &createdAt); err != nil {
 			return nil, fmt.Errorf("scan history: %w", err)
 		}
@@ -342,11 +471,11 @@ func RecentHistory(db *sql.DB, limit int) ([]NotificationRecord, error) {
 
 func scanService(row *sql.Row) (*NotificationService, error) {
 	var svc NotificationService
-	var enabled, critical, warning, healthy int
-	var createdAt, updatedAt string
+	var enabled, critical, warning, healthy, dryRun int
+	var templates, mutedUntil, createdAt, updatedAt string
 
 	err := row.Scan(&svc.ID, &svc.Name, &svc.ServiceType, &svc.ConfigJSON,
-		&enabled, &critical, &warning, &healthy, &createdAt, &updatedAt)
+		&enabled, &critical, &warning, &healthy, &svc.MinIntervalSecs, &templates, &dryRun, &mutedUntil, &createdAt, &updatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -357,6 +486,9 @@ func scanService(row *sql.Row) (*NotificationService, error) {
 	svc.NotifyOnCritical = critical == 1
 	svc.NotifyOnWarning = warning == 1
 	svc.NotifyOnHealthy = healthy == 1
+	svc.MessageTemplates = decodeTemplates(templates)
+	svc.DryRun = dryRun == 1
+	setMute(&svc, mutedUntil)
 	svc.CreatedAt = parseTime(createdAt)
 	svc.UpdatedAt = parseTime(updatedAt)
 	return &svc, nil
@@ -368,11 +500,11 @@ type scannable interface {
 
 func scanServiceRow(s scannable) (NotificationService, error) {
 	var svc NotificationService
-	var enabled, critical, warning, healthy int
-	var createdAt, updatedAt string
+	var enabled, critical, warning, healthy, dryRun int
+	var templates, mutedUntil, createdAt, updatedAt string
 
 	err := s.Scan(&svc.ID, &svc.Name, &svc.ServiceType, &svc.ConfigJSON,
-		&enabled, &critical, &warning, &healthy, &createdAt, &updatedAt)
+		&enabled, &critical, &warning, &healthy, &svc.MinIntervalSecs, &templates, &dryRun, &mutedUntil, &createdAt, &updatedAt)
 	if err != nil {
 		return svc, fmt.Errorf("scan notification service row: %w", err)
 	}
@@ -380,11 +512,28 @@ func scanServiceRow(s scannable) (NotificationService, error) {
 	svc.NotifyOnCritical = critical == 1
 	svc.NotifyOnWarning = warning == 1
 	svc.NotifyOnHealthy = healthy == 1
+	svc.MessageTemplates = decodeTemplates(templates)
+	svc.DryRun = dryRun == 1
+	setMute(&svc, mutedUntil)
 	svc.CreatedAt = parseTime(createdAt)
 	svc.UpdatedAt = parseTime(updatedAt)
 	return svc, nil
 }
 
+// setMute fills MutedUntil and Muted from the stored muted_until value.
+// A mute that has already expired reads as not muted.
+func setMute(svc *NotificationService, mutedUntil string) {
+	if mutedUntil == "" {
+		return
+	}
+	t := parseTime(mutedUntil)
+	if t.IsZero() {
+		return
+	}
+	svc.MutedUntil = &t
+	svc.Muted = time.Now().Before(t)
+}
+
 func parseTime(s string) time.Time {
 	if s == "" {
 		return time.Time{}
@@ -402,6 +551,33 @@ func parseTime(s string) time.Time {
 	return time.Time{}
 }
 
+// encodeTemplates serialises message templates for the message_templates
+// column, dropping empty entries.
+func encodeTemplates(t map[string]string) string {
+	clean := make(map[string]string, len(t))
+	for k, v := range t {
+		if strings.TrimSpace(v) != "" {
+			clean[k] = v
+		}
+	}
+	if len(clean) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(clean)
+	return string(b)
+}
+
+func decodeTemplates(s string) map[string]string {
+	if s == "" {
+		return nil
+	}
+	var t map[string]string
+	if err := json.Unmarshal([]byte(s), &t); err != nil {
+		return nil
+	}
+	return t
+}
+
 func boolInt(b bool) int {
 	if b {
 		return 1
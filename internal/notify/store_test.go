@@ -290,3 +290,43 @@ func TestRecordAndRecentHistory(t *testing.T) {
 		t.Errorf("hostname = %q, want %q", history[0].Hostname, "host1")
 	}
 }
+
+func TestRecentHistoryByCategory(t *testing.T) {
+	db := setupTestDB(t)
+	svcID := createTestService(t, db)
+
+	for _, cat := range []string{"media", "thermal", "media"} {
+		rec := &NotificationRecord{
+			SettingID: svcID,
+			EventType: "smart_warning",
+			Hostname:  "host1",
+			Message:   cat + " alert",
+			Status:    "sent",
+			Category:  cat,
+		}
+		if _, err := RecordNotification(db, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err := RecentHistoryByCategory(db, 10, "media")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 media records, got %d", len(history))
+	}
+	for _, h := range history {
+		if h.Category != "media" {
+			t.Errorf("category = %q, want media", h.Category)
+		}
+	}
+
+	all, err := RecentHistory(db, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Errorf("expected 3 records unfiltered, got %d", len(all))
+	}
+}
@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"log"
+	"time"
+
+	"vigil/internal/summary"
+)
+
+// SummaryEventType is the notification_history event_type for daily summaries.
+const SummaryEventType = "daily_summary"
+
+// summaryCheckInterval is how often the scheduler looks for due summaries
+// and escalations.
+const summaryCheckInterval = time.Minute
+
+// runScheduler runs the time-driven jobs — daily summaries and alert
+// escalation — until Stop is called.
+func (d *Dispatcher) runScheduler() {
+	defer d.wg.Done()
+	ticker := time.NewTicker(summaryCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			d.SendDailySummaries(now)
+			d.EscalateUnacknowledged(now)
+		case <-d.stopCh:
+			return
+		}
+	}
+}
+
+// SendDailySummaries sends the "what changed" summary for the 24 hours
+// before now to each service whose send_at has passed today and which
+// hasn't had one yet today. The summary is a scheduled report rather than
+// an alert, so event rules, quiet hours and the rate cap don't apply.
+func (d *Dispatcher) SendDailySummaries(now time.Time) {
+	configs, err := ListEnabledSummaryConfigs(d.db)
+	if err != nil {
+		log.Printf("notify: list summary configs: %v", err)
+		return
+	}
+
+	// The summary covers the whole fleet, so services linked to projects
+	// don't get it.
+	routes := d.projectRoutes()
+	var msg string
+	for _, sc := range configs {
+		if !summaryDue(sc, now) {
+			continue
+		}
+		if _, linked := routes[sc.ServiceID]; linked {
+			continue
+		}
+		svc, err := GetService(d.db, sc.ServiceID)
+		if err != nil || svc == nil || !svc.Enabled {
+			continue
+		}
+
+		if msg == "" {
+			s, err := summary.Build(d.db, now.Add(-24*time.Hour), now)
+			if err != nil {
+				log.Printf("notify: build daily summary: %v", err)
+				return
+			}
+			msg = s.Format()
+		}
+
+		d.deliver(*svc, &NotificationRecord{
+			SettingID: svc.ID,
+			EventType: SummaryEventType,
+			Message:   msg,
+		})
+		// Marked even when the send failed so a broken service gets one
+		// failed attempt per day rather than one per minute.
+		if err := MarkSummarySent(d.db, svc.ID, now); err != nil {
+			log.Printf("notify: %v", err)
+		}
+	}
+}
+
+// summaryDue reports whether a summary should go out at now: the send_at
+// time (UTC) has passed and nothing has been sent yet on this UTC day.
+func summaryDue(sc SummaryConfig, now time.Time) bool {
+	now = now.UTC()
+	if now.Hour()*60+now.Minute() < parseHHMM(sc.SendAt) {
+		return false
+	}
+	if sc.LastSentAt.IsZero() {
+		return true
+	}
+	last := sc.LastSentAt.UTC()
+	return last.YearDay() != now.YearDay() || last.Year() != now.Year()
+}
@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummaryDue(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name     string
+		lastSent string
+		now      string
+		want     bool
+	}{
+		{"before send time", "", "2026-10-15T07:59:00Z", false},
+		{"never sent", "", "2026-10-15T08:00:00Z", true},
+		{"already sent today", "2026-10-15T08:00:30Z", "2026-10-15T09:00:00Z", false},
+		{"sent yesterday", "2026-10-14T08:00:30Z", "2026-10-15T08:01:00Z", true},
+		{"catch up later in the day", "2026-10-14T08:00:30Z", "2026-10-15T20:00:00Z", true},
+		{"same day of year, different year", "2025-10-15T08:00:00Z", "2026-10-15T08:00:00Z", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := SummaryConfig{Enabled: true, SendAt: "08:00"}
+			if tt.lastSent != "" {
+				sc.LastSentAt = at(tt.lastSent)
+			}
+			if got := summaryDue(sc, at(tt.now)); got != tt.want {
+				t.Errorf("summaryDue = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummaryConfigRoundTrip(t *testing.T) {
+	db := setupTestDB(t)
+	svcID := createTestService(t, db)
+
+	if sc, err := GetSummaryConfig(db, svcID); err != nil || sc != nil {
+		t.Fatalf("expected no config, got %+v, %v", sc, err)
+	}
+
+	if err := UpsertSummaryConfig(db, &SummaryConfig{ServiceID: svcID, Enabled: true, SendAt: "06:30"}); err != nil {
+		t.Fatal(err)
+	}
+	sent := time.Date(2026, 10, 15, 6, 30, 0, 0, time.UTC)
+	if err := MarkSummarySent(db, svcID, sent); err != nil {
+		t.Fatal(err)
+	}
+
+	// Re-saving the schedule must not clear last_sent_at, or the summary
+	// would go out a second time the same day.
+	if err := UpsertSummaryConfig(db, &SummaryConfig{ServiceID: svcID, Enabled: true, SendAt: "07:00"}); err != nil {
+		t.Fatal(err)
+	}
+
+	configs, err := ListEnabledSummaryConfigs(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) != 1 {
+		t.Fatalf("expected 1 enabled config, got %d", len(configs))
+	}
+	if configs[0].SendAt != "07:00" {
+		t.Errorf("send_at = %q, want 07:00", configs[0].SendAt)
+	}
+	if !configs[0].LastSentAt.Equal(sent) {
+		t.Errorf("last_sent_at = %v, want %v", configs[0].LastSentAt, sent)
+	}
+}
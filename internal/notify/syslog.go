@@ -0,0 +1,252 @@
+package notify
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"vigil/internal/events"
+)
+
+// syslogFacilities are the RFC 5424 facility codes by name.
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSDID is the structured-data ID event fields are sent under; 32473
+// is the private enterprise number RFC 5612 reserves for examples.
+const syslogSDID = "vigil@32473"
+
+// syslogWriteTimeout bounds a single connect or write attempt.
+const syslogWriteTimeout = 5 * time.Second
+
+// syslogQueueSize is how many messages wait for the collector before new
+// events are dropped.
+const syslogQueueSize = 256
+
+// syslogMinBackoff and syslogMaxBackoff bound the wait between attempts
+// while the collector is unreachable; the wait doubles after each failure.
+var (
+	syslogMinBackoff = time.Second
+	syslogMaxBackoff = time.Minute
+)
+
+// SyslogSink mirrors every event to a syslog server as RFC 5424 messages,
+// over UDP or TCP (octet-counted framing, RFC 6587). It is configured
+// outside notification services and isn't subject to their rules, so a
+// SIEM receives everything. Messages are queued and written by a
+// background goroutine, so a slow or unreachable collector never stalls
+// event dispatch.
+type SyslogSink struct {
+	network  string
+	addr     string
+	facility int
+	hostname string
+
+	queue     chan string
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+
+	conn net.Conn // owned by run
+}
+
+// NewSyslogSink creates a sink for addr (host:port). network is "udp" or
+// "tcp"; facility a name such as "local0" or a code 0-23. The connection
+// is made on the first event; Close stops the sink.
+func NewSyslogSink(network, addr, facility string) (*SyslogSink, error) {
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("protocol must be udp or tcp, got %q", network)
+	}
+	code, ok := syslogFacilities[strings.ToLower(facility)]
+	if !ok {
+		n, err := strconv.Atoi(facility)
+		if err != nil || n < 0 || n > 23 {
+			return nil, fmt.Errorf("unknown facility %q", facility)
+		}
+		code = n
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	s := &SyslogSink{
+		network:  network,
+		addr:     addr,
+		facility: code,
+		hostname: hostname,
+		queue:    make(chan string, syslogQueueSize),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Send queues e for the syslog server and returns without waiting for it.
+// It fails only when the queue is full or the sink is closed, in which
+// case e is dropped.
+func (s *SyslogSink) Send(e events.Event) error {
+	msg := formatSyslog(s.facility, s.hostname, e)
+	if s.network == "tcp" {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+
+	select {
+	case <-s.done:
+		return fmt.Errorf("sink closed, dropped %s event", e.Type)
+	default:
+	}
+	select {
+	case s.queue <- msg:
+		return nil
+	default:
+		return fmt.Errorf("%s unreachable and queue full, dropped %s event", s.addr, e.Type)
+	}
+}
+
+// Close stops the sink and closes the connection, if any. Messages still
+// queued are discarded.
+func (s *SyslogSink) Close() {
+	s.closeOnce.Do(func() { close(s.done) })
+	<-s.stopped
+}
+
+// run writes queued messages in order. A message that can't be written is
+// retried, with exponential backoff, until it goes through or the sink is
+// closed; meanwhile later messages wait in the queue.
+func (s *SyslogSink) run() {
+	defer close(s.stopped)
+	defer func() {
+		if s.conn != nil {
+			s.conn.Close()
+		}
+	}()
+
+	var backoff time.Duration
+	for {
+		var msg string
+		select {
+		case <-s.done:
+			return
+		case msg = <-s.queue:
+		}
+		for {
+			err := s.write(msg)
+			if err == nil {
+				if backoff > 0 {
+					log.Printf("notify: syslog: %s reachable again", s.addr)
+					backoff = 0
+				}
+				break
+			}
+			backoff = min(max(2*backoff, syslogMinBackoff), syslogMaxBackoff)
+			log.Printf("notify: syslog: %v; retrying in %s", err, backoff)
+			select {
+			case <-s.done:
+				return
+			case <-time.After(backoff):
+			}
+		}
+	}
+}
+
+// write sends one message, reconnecting once if the previous connection
+// has gone away.
+func (s *SyslogSink) write(msg string) error {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.conn == nil {
+			if s.conn, err = net.DialTimeout(s.network, s.addr, syslogWriteTimeout); err != nil {
+				return fmt.Errorf("connect to %s: %w", s.addr, err)
+			}
+		}
+		s.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout)) //nolint:errcheck
+		if _, err = s.conn.Write([]byte(msg)); err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+	return fmt.Errorf("write to %s: %w", s.addr, err)
+}
+
+// formatSyslog renders e as an RFC 5424 message: the event type is the
+// MSGID and its host, serial, cause and metadata go in structured data.
+func formatSyslog(facility int, hostname string, e events.Event) string {
+	severity := 6 // informational
+	switch e.Severity {
+	case events.SeverityCritical:
+		severity = 2
+	case events.SeverityWarning:
+		severity = 4
+	}
+
+	ts := e.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	params := []string{sdParam("type", string(e.Type))}
+	if e.Hostname != "" {
+		params = append(params, sdParam("host", e.Hostname))
+	}
+	if e.SerialNumber != "" {
+		params = append(params, sdParam("serial", e.SerialNumber))
+	}
+	if e.Cause != "" {
+		params = append(params, sdParam("cause", string(e.Cause)))
+	}
+	keys := make([]string, 0, len(e.Metadata))
+	for k := range e.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		params = append(params, sdParam(k, e.Metadata[k]))
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s vigil - %s [%s %s] %s",
+		facility*8+severity,
+		ts.UTC().Format("2006-01-02T15:04:05.000Z"),
+		hostname,
+		syslogToken(string(e.Type), 32),
+		syslogSDID,
+		strings.Join(params, " "),
+		e.Message,
+	)
+}
+
+// sdParam renders one structured-data parameter, escaping the value as
+// RFC 5424 requires and reducing the name to a valid token.
+func sdParam(name, value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+	return syslogToken(name, 32) + `="` + value + `"`
+}
+
+// syslogToken keeps the printable ASCII characters RFC 5424 allows in a
+// header or SD name, up to max, and returns "-" for an empty result.
+func syslogToken(s string, max int) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r > 32 && r < 127 && r != '=' && r != ']' && r != '"' {
+			b.WriteRune(r)
+		}
+		if b.Len() == max {
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return "-"
+	}
+	return b.String()
+}
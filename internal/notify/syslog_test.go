@@ -0,0 +1,169 @@
+package notify
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"vigil/internal/events"
+)
+
+func TestFormatSyslog(t *testing.T) {
+	e := events.Event{
+		Type:         events.SmartCritical,
+		Severity:     events.SeverityCritical,
+		Hostname:     "nas01",
+		SerialNumber: "WD-123",
+		Message:      "Reallocated sectors rising",
+		Cause:        events.CauseOf(events.SmartCritical),
+		Metadata:     map[string]string{"model": `WDC "Red"`, "attribute": "5"},
+		Timestamp:    time.Date(2026, 10, 15, 12, 30, 0, 0, time.UTC),
+	}
+
+	// local0 (16) * 8 + crit (2) = 130
+	want := `<130>1 2026-10-15T12:30:00.000Z vigil-srv vigil - smart_critical ` +
+		`[vigil@32473 type="smart_critical" host="nas01" serial="WD-123" cause="` + string(e.Cause) + `" attribute="5" model="WDC \"Red\""] ` +
+		`Reallocated sectors rising`
+	if got := formatSyslog(16, "vigil-srv", e); got != want {
+		t.Errorf("formatSyslog =\n%s\nwant\n%s", got, want)
+	}
+
+	e.Severity = events.SeverityInfo
+	if got := formatSyslog(3, "h", e); !strings.HasPrefix(got, "<30>1 ") {
+		t.Errorf("daemon.info should be <30>, got %q", got[:8])
+	}
+}
+
+func TestNewSyslogSinkValidates(t *testing.T) {
+	if _, err := NewSyslogSink("udp", "127.0.0.1:514", "local3"); err != nil {
+		t.Errorf("local3: %v", err)
+	}
+	if _, err := NewSyslogSink("tcp", "127.0.0.1:514", "3"); err != nil {
+		t.Errorf("numeric facility: %v", err)
+	}
+	if _, err := NewSyslogSink("tls", "127.0.0.1:514", "local0"); err == nil {
+		t.Error("expected an error for an unsupported protocol")
+	}
+	if _, err := NewSyslogSink("udp", "127.0.0.1:514", "local9"); err == nil {
+		t.Error("expected an error for an unknown facility")
+	}
+}
+
+func TestSyslogSinkTCPFraming(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	got := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		r := bufio.NewReader(conn)
+		length, _ := r.ReadString(' ')
+		rest := make([]byte, 512)
+		n, _ := r.Read(rest)
+		got <- length + string(rest[:n])
+	}()
+
+	sink, err := NewSyslogSink("tcp", ln.Addr().String(), "local0")
+	if err != nil {
+		t.Fatalf("NewSyslogSink: %v", err)
+	}
+	defer sink.Close()
+	if err := sink.Send(events.Event{Type: events.TempAlert, Severity: events.SeverityWarning, Message: "hot"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	select {
+	case frame := <-got:
+		length, msg, _ := strings.Cut(frame, " ")
+		if length == "" || len(msg) == 0 || !strings.HasPrefix(msg, "<132>1 ") || !strings.HasSuffix(msg, " hot") {
+			t.Errorf("unexpected frame %q", frame)
+		}
+		if want := len(msg); length != strconv.Itoa(want) {
+			t.Errorf("octet count %s, message is %d bytes", length, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+}
+
+func TestSyslogSinkRetriesUnreachableCollector(t *testing.T) {
+	defer func(lo, hi time.Duration) { syslogMinBackoff, syslogMaxBackoff = lo, hi }(syslogMinBackoff, syslogMaxBackoff)
+	syslogMinBackoff, syslogMaxBackoff = 10*time.Millisecond, 50*time.Millisecond
+
+	// Reserve a port, then free it so the first attempts are refused.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	sink, err := NewSyslogSink("tcp", addr, "local0")
+	if err != nil {
+		t.Fatalf("NewSyslogSink: %v", err)
+	}
+	defer sink.Close()
+
+	start := time.Now()
+	if err := sink.Send(events.Event{Type: events.TempAlert, Message: "queued"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Send blocked for %s on an unreachable collector", d)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if ln, err = net.Listen("tcp", addr); err != nil {
+		t.Skipf("port %s taken meanwhile: %v", addr, err)
+	}
+	defer ln.Close()
+
+	got := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		r := bufio.NewReader(conn)
+		length, _ := r.ReadString(' ')
+		n, _ := strconv.Atoi(strings.TrimSpace(length))
+		msg := make([]byte, n)
+		io.ReadFull(r, msg)
+		got <- string(msg)
+	}()
+
+	select {
+	case frame := <-got:
+		if !strings.HasSuffix(frame, " queued") {
+			t.Errorf("unexpected frame %q", frame)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued message never delivered")
+	}
+}
+
+func TestSyslogSinkSendAfterClose(t *testing.T) {
+	sink, err := NewSyslogSink("udp", "127.0.0.1:514", "local0")
+	if err != nil {
+		t.Fatalf("NewSyslogSink: %v", err)
+	}
+	sink.Close()
+	sink.Close()
+	if err := sink.Send(events.Event{Type: events.TempAlert}); err == nil {
+		t.Error("expected an error sending on a closed sink")
+	}
+}
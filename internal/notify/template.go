@@ -0,0 +1,155 @@
+package notify
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"vigil/internal/drivename"
+	"vigil/internal/events"
+)
+
+// Template keys accepted in a service's message_templates. "default" applies
+// to any severity without its own template.
+var templateKeys = map[string]bool{
+	"default":  true,
+	"critical": true,
+	"warning":  true,
+	"info":     true,
+}
+
+// TemplateData is the set of placeholders available to message templates,
+// e.g. "{{.Severity}} {{.Hostname}}/{{.DisplayName}}: {{.Message}}".
+type TemplateData struct {
+	Severity    string
+	EventType   string
+	Hostname    string
+	Serial      string
+	Model       string
+	Alias       string
+	DisplayName string // the drive's label per drives/display_name_order
+	Temperature string
+	Attribute   string
+	Message     string
+	Timestamp   time.Time
+	Metadata    map[string]string
+}
+
+// ValidateTemplates parses every template and renders it against sample data
+// so mistakes (unknown fields, bad syntax) surface when the service is saved
+// rather than when an alert fires.
+func ValidateTemplates(templates map[string]string) error {
+	sample := TemplateData{
+		Severity:    "critical",
+		EventType:   string(events.SmartCritical),
+		Hostname:    "nas01",
+		Serial:      "WD-EXAMPLE",
+		Model:       "WDC WD40EFRX",
+		Alias:       "Bay 1",
+		DisplayName: "Bay 1",
+		Temperature: "52",
+		Attribute:   "5",
+		Message:     "Example message",
+		Timestamp:   time.Now(),
+		Metadata:    map[string]string{},
+	}
+	for key, text := range templates {
+		if !templateKeys[key] {
+			return fmt.Errorf("unknown template key %q (use default, critical, warning or info)", key)
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if _, err := renderTemplate(text, sample); err != nil {
+			return fmt.Errorf("template %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// buildMessage renders the service's template for the event's severity,
+// falling back to the built-in format when no template applies or the
+// template fails at render time.
+func (d *Dispatcher) buildMessage(svc NotificationService, e events.Event) string {
+	text := svc.MessageTemplates[e.Severity.String()]
+	if strings.TrimSpace(text) == "" {
+		text = svc.MessageTemplates["default"]
+	}
+	if strings.TrimSpace(text) == "" {
+		return formatMessage(e)
+	}
+
+	msg, err := renderTemplate(text, d.templateData(e))
+	if err != nil {
+		return formatMessage(e)
+	}
+	return msg
+}
+
+// templateData maps an event onto the template placeholders.
+func (d *Dispatcher) templateData(e events.Event) TemplateData {
+	md := e.Metadata
+	if md == nil {
+		md = map[string]string{}
+	}
+	attr := md["attribute_name"]
+	if attr == "" {
+		attr = md["attribute_id"]
+	}
+	ts := e.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	return TemplateData{
+		Severity:    e.Severity.String(),
+		EventType:   string(e.Type),
+		Hostname:    e.Hostname,
+		Serial:      e.SerialNumber,
+		Model:       md["model"],
+		Alias:       lookupAlias(d.db, e.Hostname, e.SerialNumber),
+		DisplayName: lookupDisplayName(d.db, e.Hostname, e.SerialNumber),
+		Temperature: md["temperature"],
+		Attribute:   attr,
+		Message:     e.Message,
+		Timestamp:   ts,
+		Metadata:    md,
+	}
+}
+
+func renderTemplate(text string, data TemplateData) (string, error) {
+	tmpl, err := template.New("message").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// lookupAlias returns the user-assigned alias for a drive, or "" if none.
+func lookupAlias(db *sql.DB, hostname, serial string) string {
+	if hostname == "" || serial == "" {
+		return ""
+	}
+	var alias string
+	err := db.QueryRow(`SELECT alias FROM drive_aliases WHERE hostname = ? AND serial_number = ?`,
+		hostname, serial).Scan(&alias)
+	if err != nil {
+		return ""
+	}
+	return alias
+}
+
+// lookupDisplayName returns a drive's display name, or "" for events that
+// aren't about a drive.
+func lookupDisplayName(db *sql.DB, hostname, serial string) string {
+	if hostname == "" || serial == "" {
+		return ""
+	}
+	return drivename.DisplayName(db, hostname, serial)
+}
@@ -5,16 +5,22 @@ import "time"
 // NotificationService is a configured Shoutrrr destination.
 // Stored in the existing notification_settings table.
 type NotificationService struct {
-	ID               int64     `json:"id"`
-	Name             string    `json:"name"`
-	ServiceType      string    `json:"service_type"`
-	ConfigJSON       string    `json:"config_json"`
-	Enabled          bool      `json:"enabled"`
-	NotifyOnCritical bool      `json:"notify_on_critical"`
-	NotifyOnWarning  bool      `json:"notify_on_warning"`
-	NotifyOnHealthy  bool      `json:"notify_on_healthy"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	ID               int64             `json:"id"`
+	Name             string            `json:"name"`
+	ServiceType      string            `json:"service_type"`
+	ConfigJSON       string            `json:"config_json"`
+	Enabled          bool              `json:"enabled"`
+	NotifyOnCritical bool              `json:"notify_on_critical"`
+	NotifyOnWarning  bool              `json:"notify_on_warning"`
+	NotifyOnHealthy  bool              `json:"notify_on_healthy"`
+	MinIntervalSecs  int               `json:"min_interval_seconds"`        // hard rate cap per channel; 0 = unlimited
+	DryRun           bool              `json:"dry_run"`                     // record "would_send" history instead of sending
+	MessageTemplates map[string]string `json:"message_templates,omitempty"` // text/template per severity or "default"
+	MutedUntil       *time.Time        `json:"muted_until"`                 // one-off mute; nil when not muted
+	Muted            bool              `json:"muted"`                       // MutedUntil is in the future (computed on load)
+	GlobalPause      GlobalPause       `json:"global_pause"`                // the global kill switch, filled in by the API listing
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
 }
 
 // EventRule controls per-event-type notification behaviour for a service.
@@ -44,6 +50,15 @@ type DigestConfig struct {
 	SendAt    string `json:"send_at"` // "HH:MM" in UTC
 }
 
+// SummaryConfig controls the daily "what changed" summary for a service.
+type SummaryConfig struct {
+	ID         int64     `json:"id"`
+	ServiceID  int64     `json:"service_id"`
+	Enabled    bool      `json:"enabled"`
+	SendAt     string    `json:"send_at"` // "HH:MM" in UTC
+	LastSentAt time.Time `json:"last_sent_at,omitempty"`
+}
+
 // NotificationRecord is a row from notification_history.
 type NotificationRecord struct {
 	ID           int64     `json:"id"`
@@ -52,7 +67,8 @@ type NotificationRecord struct {
 	Hostname     string    `json:"hostname"`
 	SerialNumber string    `json:"serial_number"`
 	Message      string    `json:"message"`
-	Status       string    `json:"status"`
+	Category     string    `json:"category,omitempty"` // the event's cause, see events.Cause
+	Status       string    `json:"status"`             // "sent", "failed", "would_send" (dry run), "muted", "maintenance" or "paused"
 	ErrorMessage string    `json:"error_message,omitempty"`
 	SentAt       time.Time `json:"sent_at,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
@@ -0,0 +1,57 @@
+package projects
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Migrate creates the projects tables if they don't exist.
+func Migrate(db *sql.DB) error {
+	stmts := []struct {
+		name string
+		sql  string
+	}{
+		{"projects", `
+			CREATE TABLE IF NOT EXISTS projects (
+				id          INTEGER PRIMARY KEY AUTOINCREMENT,
+				name        TEXT    NOT NULL UNIQUE,
+				description TEXT    NOT NULL DEFAULT '',
+				created_at  DATETIME DEFAULT CURRENT_TIMESTAMP
+			)`},
+		// A host belongs to at most one project
+		{"project_hosts", `
+			CREATE TABLE IF NOT EXISTS project_hosts (
+				hostname   TEXT    NOT NULL PRIMARY KEY COLLATE NOCASE,
+				project_id INTEGER NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+			)`},
+		{"project_users", `
+			CREATE TABLE IF NOT EXISTS project_users (
+				project_id INTEGER NOT NULL,
+				user_id    INTEGER NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (project_id, user_id),
+				FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			)`},
+		{"project_services", `
+			CREATE TABLE IF NOT EXISTS project_services (
+				project_id INTEGER NOT NULL,
+				service_id INTEGER NOT NULL,
+				PRIMARY KEY (project_id, service_id),
+				FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+				FOREIGN KEY (service_id) REFERENCES notification_settings(id) ON DELETE CASCADE
+			)`},
+		{"project indexes", `
+			CREATE INDEX IF NOT EXISTS idx_project_hosts_project ON project_hosts(project_id);
+			CREATE INDEX IF NOT EXISTS idx_project_users_user    ON project_users(user_id);`},
+	}
+
+	for _, s := range stmts {
+		if _, err := db.Exec(s.sql); err != nil {
+			return fmt.Errorf("projects migration %s: %w", s.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package projects
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// Scope is what a project user may see: the projects they belong to and
+// those projects' hosts. A nil *Scope is an admin's and allows everything.
+type Scope struct {
+	projects map[int64]bool
+	hosts    map[string]bool // lowercased
+}
+
+// ForUser returns the user's scope: nil for an admin (users.is_admin),
+// else the user's projects and their hosts. A user who is neither an admin
+// nor in any project gets an empty scope and sees no hosts.
+func ForUser(db *sql.DB, userID int64) (*Scope, error) {
+	var isAdmin bool
+	err := db.QueryRow(`SELECT is_admin FROM users WHERE id = ?`, userID).Scan(&isAdmin)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	if isAdmin {
+		return nil, nil
+	}
+
+	rows, err := db.Query(`
+		SELECT pu.project_id, COALESCE(ph.hostname, '')
+		FROM project_users pu
+		LEFT JOIN project_hosts ph ON ph.project_id = pu.project_id
+		WHERE pu.user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	s := &Scope{projects: make(map[int64]bool), hosts: make(map[string]bool)}
+	for rows.Next() {
+		var projectID int64
+		var hostname string
+		if err := rows.Scan(&projectID, &hostname); err != nil {
+			return nil, err
+		}
+		s.projects[projectID] = true
+		if hostname != "" {
+			s.hosts[strings.ToLower(hostname)] = true
+		}
+	}
+	return s, rows.Err()
+}
+
+// IsAdmin reports whether the scope is unrestricted.
+func (s *Scope) IsAdmin() bool {
+	return s == nil
+}
+
+// AllowsHost reports whether the scope covers the host. Hostnames compare
+// case-insensitively, as everywhere else.
+func (s *Scope) AllowsHost(hostname string) bool {
+	return s == nil || s.hosts[strings.ToLower(hostname)]
+}
+
+// AllowsProject reports whether the scope covers the project.
+func (s *Scope) AllowsProject(id int64) bool {
+	return s == nil || s.projects[id]
+}
+
+// Hosts returns the hostnames the scope covers, lowercased, or nil for an
+// admin scope.
+func (s *Scope) Hosts() []string {
+	if s == nil {
+		return nil
+	}
+	hosts := make([]string, 0, len(s.hosts))
+	for h := range s.hosts {
+		hosts = append(hosts, h)
+	}
+	return hosts
+}
+
+// ServiceHosts maps each notification service linked to a project to the
+// hosts of its projects, lowercased. Services missing from the map aren't
+// tied to any project and receive events from every host.
+func ServiceHosts(db *sql.DB) (map[int64]map[string]bool, error) {
+	rows, err := db.Query(`
+		SELECT ps.service_id, COALESCE(ph.hostname, '')
+		FROM project_services ps
+		LEFT JOIN project_hosts ph ON ph.project_id = ps.project_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[int64]map[string]bool)
+	for rows.Next() {
+		var serviceID int64
+		var hostname string
+		if err := rows.Scan(&serviceID, &hostname); err != nil {
+			return nil, err
+		}
+		if out[serviceID] == nil {
+			out[serviceID] = make(map[string]bool)
+		}
+		if hostname != "" {
+			out[serviceID][strings.ToLower(hostname)] = true
+		}
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,221 @@
+package projects
+
+import (
+	"database/sql"
+	"fmt"
+
+	vigildb "vigil/internal/db"
+)
+
+// ── Project CRUD ────────────────────────────────────────────────────────
+
+// CreateProject inserts a new project and returns its ID.
+func CreateProject(db *sql.DB, p *Project) (int64, error) {
+	res, err := db.Exec(`INSERT INTO projects (name, description) VALUES (?, ?)`, p.Name, p.Description)
+	if err != nil {
+		return 0, fmt.Errorf("create project: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// UpdateProject updates a project's name and description.
+func UpdateProject(db *sql.DB, p *Project) error {
+	_, err := db.Exec(`UPDATE projects SET name = ?, description = ? WHERE id = ?`, p.Name, p.Description, p.ID)
+	return err
+}
+
+// DeleteProject removes a project. Its host, user and service links are
+// cascade-deleted, so its hosts become visible to admins only and its
+// users lose access to them; they don't become admins.
+func DeleteProject(db *sql.DB, id int64) error {
+	_, err := db.Exec(`DELETE FROM projects WHERE id = ?`, id)
+	return err
+}
+
+const projectColumns = `
+	p.id, p.name, p.description, p.created_at,
+	(SELECT COUNT(*) FROM project_hosts WHERE project_id = p.id),
+	(SELECT COUNT(*) FROM project_users WHERE project_id = p.id)`
+
+// ListProjects returns all projects with host and user counts.
+func ListProjects(db *sql.DB) ([]Project, error) {
+	rows, err := db.Query(`SELECT ` + projectColumns + ` FROM projects p ORDER BY p.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []Project
+	for rows.Next() {
+		var p Project
+		var ts string
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &ts, &p.HostCount, &p.UserCount); err != nil {
+			continue
+		}
+		p.CreatedAt = vigildb.ParseTime(ts)
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// GetProject returns a single project by ID, or nil if not found.
+func GetProject(db *sql.DB, id int64) (*Project, error) {
+	var p Project
+	var ts string
+	err := db.QueryRow(`SELECT `+projectColumns+` FROM projects p WHERE p.id = ?`, id).
+		Scan(&p.ID, &p.Name, &p.Description, &ts, &p.HostCount, &p.UserCount)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.CreatedAt = vigildb.ParseTime(ts)
+	return &p, nil
+}
+
+// ── Hosts ───────────────────────────────────────────────────────────────
+
+// AssignHost puts a host in a project. A host belongs to one project at a
+// time, so assigning it again moves it.
+func AssignHost(db *sql.DB, projectID int64, hostname string) error {
+	_, err := db.Exec(`
+		INSERT INTO project_hosts (hostname, project_id) VALUES (?, ?)
+		ON CONFLICT(hostname) DO UPDATE SET project_id = excluded.project_id`,
+		hostname, projectID,
+	)
+	return err
+}
+
+// UnassignHost takes a host out of its project. It reports whether the
+// host was in one.
+func UnassignHost(db *sql.DB, hostname string) (bool, error) {
+	res, err := db.Exec(`DELETE FROM project_hosts WHERE hostname = ?`, hostname)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ListHosts returns the hostnames in a project, sorted.
+func ListHosts(db *sql.DB, projectID int64) ([]string, error) {
+	rows, err := db.Query(`SELECT hostname FROM project_hosts WHERE project_id = ? ORDER BY hostname`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hosts := []string{}
+	for rows.Next() {
+		var h string
+		if err := rows.Scan(&h); err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, h)
+	}
+	return hosts, rows.Err()
+}
+
+// ── Users ───────────────────────────────────────────────────────────────
+
+// AddUser makes a user a member of a project, taking away their admin
+// flag so they only see the project's hosts. Adding an existing member is
+// a no-op.
+func AddUser(db *sql.DB, projectID, userID int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`INSERT OR IGNORE INTO project_users (project_id, user_id) VALUES (?, ?)`,
+		projectID, userID,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE users SET is_admin = 0 WHERE id = ?`, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RemoveUser takes a user out of a project. It reports whether the user
+// was a member. A user left in no project keeps no access rather than
+// becoming an admin.
+func RemoveUser(db *sql.DB, projectID, userID int64) (bool, error) {
+	res, err := db.Exec(`DELETE FROM project_users WHERE project_id = ? AND user_id = ?`, projectID, userID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ListUsers returns the members of a project, by username.
+func ListUsers(db *sql.DB, projectID int64) ([]User, error) {
+	rows, err := db.Query(`
+		SELECT u.id, u.username FROM project_users pu
+		JOIN users u ON u.id = pu.user_id
+		WHERE pu.project_id = ?
+		ORDER BY u.username`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		var u User
+		if err := rows.Scan(&u.ID, &u.Username); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// ── Notification services ───────────────────────────────────────────────
+
+// SetServices replaces the notification services that receive the
+// project's events.
+func SetServices(db *sql.DB, projectID int64, serviceIDs []int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM project_services WHERE project_id = ?`, projectID); err != nil {
+		return err
+	}
+	for _, id := range serviceIDs {
+		if _, err := tx.Exec(
+			`INSERT OR IGNORE INTO project_services (project_id, service_id) VALUES (?, ?)`, projectID, id,
+		); err != nil {
+			return fmt.Errorf("link service %d: %w", id, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ListServices returns the IDs of the notification services linked to a
+// project.
+func ListServices(db *sql.DB, projectID int64) ([]int64, error) {
+	rows, err := db.Query(`SELECT service_id FROM project_services WHERE project_id = ? ORDER BY service_id`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
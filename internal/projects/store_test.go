@@ -0,0 +1,182 @@
+package projects
+
+import (
+	"database/sql"
+	"sort"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	db.Exec("PRAGMA foreign_keys = ON")
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, username TEXT UNIQUE NOT NULL, is_admin INTEGER NOT NULL DEFAULT 0);
+		CREATE TABLE notification_settings (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT);
+		INSERT INTO users (username, is_admin) VALUES ('admin', 1), ('alice', 0), ('bob', 0), ('carol', 0);
+		INSERT INTO notification_settings (name) VALUES ('ops'), ('client-a');`); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestProjectCRUD(t *testing.T) {
+	db := setupTestDB(t)
+
+	id, err := CreateProject(db, &Project{Name: "Client A", Description: "Acme"})
+	if err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	if _, err := CreateProject(db, &Project{Name: "Client A"}); err == nil {
+		t.Error("expected duplicate name to fail")
+	}
+
+	AssignHost(db, id, "nas01")
+	AssignHost(db, id, "nas02")
+	AddUser(db, id, 2)
+	AddUser(db, id, 2) // no-op
+
+	p, err := GetProject(db, id)
+	if err != nil || p == nil {
+		t.Fatalf("GetProject: %v, %v", p, err)
+	}
+	if p.HostCount != 2 || p.UserCount != 1 || p.Description != "Acme" {
+		t.Errorf("project = %+v", p)
+	}
+	if users, _ := ListUsers(db, id); len(users) != 1 || users[0].Username != "alice" {
+		t.Errorf("users = %+v", users)
+	}
+
+	// A host belongs to one project: assigning it again moves it
+	other, _ := CreateProject(db, &Project{Name: "Client B"})
+	if err := AssignHost(db, other, "NAS02"); err != nil {
+		t.Fatalf("move host: %v", err)
+	}
+	if hosts, _ := ListHosts(db, id); len(hosts) != 1 || hosts[0] != "nas01" {
+		t.Errorf("hosts after move = %v", hosts)
+	}
+
+	if err := DeleteProject(db, id); err != nil {
+		t.Fatal(err)
+	}
+	var links int
+	db.QueryRow(`SELECT (SELECT COUNT(*) FROM project_hosts WHERE project_id = ?) + (SELECT COUNT(*) FROM project_users WHERE project_id = ?)`, id, id).Scan(&links)
+	if links != 0 {
+		t.Errorf("%d links survived the project", links)
+	}
+}
+
+func TestScopeForUser(t *testing.T) {
+	db := setupTestDB(t)
+	a, _ := CreateProject(db, &Project{Name: "A"})
+	b, _ := CreateProject(db, &Project{Name: "B"})
+	empty, _ := CreateProject(db, &Project{Name: "Empty"})
+	AssignHost(db, a, "nas01")
+	AssignHost(db, b, "nas02")
+	AddUser(db, a, 2)
+	AddUser(db, empty, 3)
+
+	admin, err := ForUser(db, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !admin.IsAdmin() || !admin.AllowsHost("anything") || !admin.AllowsProject(b) || admin.Hosts() != nil {
+		t.Error("admin should be unrestricted")
+	}
+
+	// Being in no project doesn't make a user an admin
+	carol, err := ForUser(db, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if carol.IsAdmin() || carol.AllowsHost("nas01") || len(carol.Hosts()) != 0 {
+		t.Errorf("carol = %+v, want an empty scope", carol)
+	}
+
+	alice, _ := ForUser(db, 2)
+	if alice.IsAdmin() {
+		t.Fatal("project member should be scoped")
+	}
+	if !alice.AllowsHost("NAS01") || alice.AllowsHost("nas02") {
+		t.Error("alice should see nas01 only")
+	}
+	if !alice.AllowsProject(a) || alice.AllowsProject(b) {
+		t.Error("alice should see project A only")
+	}
+
+	// A member of a project without hosts sees nothing, but isn't an admin
+	bob, _ := ForUser(db, 3)
+	if bob.IsAdmin() || bob.AllowsHost("nas01") || bob.Hosts() == nil || len(bob.Hosts()) != 0 {
+		t.Errorf("bob = %+v, want scoped to no hosts", bob)
+	}
+
+	// Losing the last project leaves an empty scope, not admin access
+	if err := DeleteProject(db, a); err != nil {
+		t.Fatal(err)
+	}
+	if alice, _ := ForUser(db, 2); alice.IsAdmin() || alice.AllowsHost("nas01") {
+		t.Errorf("alice = %+v after her project was deleted, want an empty scope", alice)
+	}
+	if _, err := RemoveUser(db, empty, 3); err != nil {
+		t.Fatal(err)
+	}
+	if bob, _ := ForUser(db, 3); bob.IsAdmin() {
+		t.Error("bob became an admin after leaving his last project")
+	}
+
+	// Adding an admin to a project scopes them to it
+	if err := AddUser(db, b, 1); err != nil {
+		t.Fatal(err)
+	}
+	if admin, _ := ForUser(db, 1); admin.IsAdmin() || !admin.AllowsHost("nas02") || admin.AllowsHost("nas01") {
+		t.Errorf("admin = %+v after joining project B, want scoped to nas02", admin)
+	}
+}
+
+func TestServiceHosts(t *testing.T) {
+	db := setupTestDB(t)
+	a, _ := CreateProject(db, &Project{Name: "A"})
+	b, _ := CreateProject(db, &Project{Name: "B"})
+	AssignHost(db, a, "nas01")
+	AssignHost(db, b, "NAS02")
+
+	if err := SetServices(db, a, []int64{2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetServices(db, b, []int64{2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetServices(db, a, []int64{99}); err == nil {
+		t.Error("expected unknown service to fail")
+	}
+	if ids, _ := ListServices(db, a); len(ids) != 1 || ids[0] != 2 {
+		t.Errorf("failed SetServices changed the links: %v", ids)
+	}
+
+	routes, err := ServiceHosts(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, linked := routes[1]; linked {
+		t.Error("service 1 isn't linked to a project")
+	}
+	var hosts []string
+	for h := range routes[2] {
+		hosts = append(hosts, h)
+	}
+	sort.Strings(hosts)
+	if len(hosts) != 2 || hosts[0] != "nas01" || hosts[1] != "nas02" {
+		t.Errorf("service 2 hosts = %v", hosts)
+	}
+}
@@ -0,0 +1,20 @@
+package projects
+
+import "time"
+
+// Project groups hosts for one tenant. Its users only see its hosts, and
+// its notification services only hear about them.
+type Project struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	HostCount   int       `json:"host_count"`
+	UserCount   int       `json:"user_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// User is a member of a project.
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
@@ -0,0 +1,26 @@
+package recovery
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Migrate creates the health state table if it doesn't exist.
+func Migrate(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS entity_health_states (
+			kind            TEXT     NOT NULL, -- 'drive' or 'pool'
+			hostname        TEXT     NOT NULL,
+			name            TEXT     NOT NULL, -- serial number or pool name
+			state           TEXT     NOT NULL, -- 'healthy', 'warning' or 'critical'
+			worst_state     TEXT     NOT NULL DEFAULT '', -- worst state still awaiting recovery; '' when none
+			unhealthy_since DATETIME,
+			healthy_since   DATETIME,
+			updated_at      DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (kind, hostname, name)
+		)`)
+	if err != nil {
+		return fmt.Errorf("recovery migration entity_health_states: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package recovery
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"vigil/internal/events"
+	"vigil/internal/settings"
+)
+
+// Entity kinds tracked for recovery.
+const (
+	KindDrive = "drive"
+	KindPool  = "pool"
+)
+
+// Health states, from best to worst.
+const (
+	StateHealthy  = "healthy"
+	StateWarning  = "warning"
+	StateCritical = "critical"
+)
+
+var stateRank = map[string]int{StateHealthy: 0, StateWarning: 1, StateCritical: 2}
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// Recovery describes an entity confirmed healthy again after a problem.
+type Recovery struct {
+	Kind     string
+	Hostname string
+	Name     string
+	// From is the worst state the entity reached while unhealthy.
+	From           string
+	UnhealthySince time.Time
+	HealthySince   time.Time
+}
+
+// ConfirmPeriod is how long an entity must stay healthy before its
+// recovery is announced, from notifications.recovery_confirm_minutes.
+func ConfirmPeriod(db *sql.DB) time.Duration {
+	return time.Duration(settings.GetInt(db, "notifications", "recovery_confirm_minutes", 30)) * time.Minute
+}
+
+// Observe records an entity's current state and publishes a recovery
+// event once it has stayed healthy for the confirmation period after
+// being warning or critical. Errors are logged: recovery tracking must
+// never hold up report ingestion.
+func Observe(db *sql.DB, bus *events.Bus, kind, hostname, name, state string) {
+	r, err := observe(db, kind, hostname, name, state, time.Now().UTC(), ConfirmPeriod(db))
+	if err != nil {
+		log.Printf("⚠️  Recovery tracking for %s %s/%s: %v", kind, hostname, name, err)
+		return
+	}
+	if r != nil && bus != nil {
+		publish(bus, r)
+	}
+}
+
+// observe moves the entity's stored state to state at now and returns the
+// recovery to announce, if any. First sight of an entity only records it:
+// there is no earlier problem to recover from. An entity that turns
+// unhealthy again while waiting out the confirmation period starts over,
+// so a flapping drive or pool announces one recovery once it settles.
+func observe(db *sql.DB, kind, hostname, name, state string, now time.Time, confirm time.Duration) (*Recovery, error) {
+	if _, ok := stateRank[state]; !ok {
+		return nil, fmt.Errorf("unknown state %q", state)
+	}
+
+	var prevState, worst string
+	var unhealthySince, healthySince sql.NullTime
+	err := db.QueryRow(`
+		SELECT state, worst_state, unhealthy_since, healthy_since
+		FROM entity_health_states WHERE kind = ? AND hostname = ? AND name = ?`,
+		kind, hostname, name,
+	).Scan(&prevState, &worst, &unhealthySince, &healthySince)
+	if err == sql.ErrNoRows {
+		prevState = StateHealthy
+		if state == StateHealthy {
+			healthySince = sql.NullTime{Time: now, Valid: true}
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	var recovered *Recovery
+	if state == StateHealthy {
+		if prevState != StateHealthy || !healthySince.Valid {
+			healthySince = sql.NullTime{Time: now, Valid: true}
+		}
+		if worst != "" && now.Sub(healthySince.Time) >= confirm {
+			recovered = &Recovery{
+				Kind:           kind,
+				Hostname:       hostname,
+				Name:           name,
+				From:           worst,
+				UnhealthySince: unhealthySince.Time,
+				HealthySince:   healthySince.Time,
+			}
+			worst = ""
+			unhealthySince = sql.NullTime{}
+		}
+	} else {
+		if worst == "" {
+			unhealthySince = sql.NullTime{Time: now, Valid: true}
+		}
+		if stateRank[state] > stateRank[worst] {
+			worst = state
+		}
+		healthySince = sql.NullTime{}
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO entity_health_states
+			(kind, hostname, name, state, worst_state, unhealthy_since, healthy_since, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(kind, hostname, name) DO UPDATE SET
+			state = excluded.state, worst_state = excluded.worst_state,
+			unhealthy_since = excluded.unhealthy_since, healthy_since = excluded.healthy_since,
+			updated_at = excluded.updated_at`,
+		kind, hostname, name, state, worst,
+		formatNullTime(unhealthySince), formatNullTime(healthySince), now.Format(timeLayout),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return recovered, nil
+}
+
+func formatNullTime(t sql.NullTime) interface{} {
+	if !t.Valid {
+		return nil
+	}
+	return t.Time.UTC().Format(timeLayout)
+}
+
+func publish(bus *events.Bus, r *Recovery) {
+	downFor := r.HealthySince.Sub(r.UnhealthySince).Round(time.Minute)
+	e := events.Event{
+		Severity: events.SeverityInfo,
+		Hostname: r.Hostname,
+		Metadata: map[string]string{
+			"previous_state":  r.From,
+			"unhealthy_since": r.UnhealthySince.Format(time.RFC3339),
+			"healthy_since":   r.HealthySince.Format(time.RFC3339),
+		},
+	}
+	switch r.Kind {
+	case KindDrive:
+		e.Type = events.DriveRecovered
+		e.SerialNumber = r.Name
+		e.Message = fmt.Sprintf("✅ Drive %s on %s recovered: SMART health is back to healthy (was %s for %s)",
+			r.Name, r.Hostname, r.From, downFor)
+	case KindPool:
+		e.Type = events.ZFSPoolRecovered
+		e.Metadata["pool_name"] = r.Name
+		was := "DEGRADED"
+		if r.From == StateCritical {
+			was = "FAULTED"
+		}
+		e.Message = fmt.Sprintf("✅ ZFS pool %q on %s recovered: back ONLINE (was %s for %s)",
+			r.Name, r.Hostname, was, downFor)
+	default:
+		return
+	}
+	bus.Publish(e)
+}
@@ -0,0 +1,82 @@
+package recovery
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestObserveConfirmsRecovery(t *testing.T) {
+	db := setupTestDB(t)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	confirm := 30 * time.Minute
+
+	step := func(minutes int, state string) *Recovery {
+		t.Helper()
+		r, err := observe(db, KindDrive, "nas01", "SN1", state, start.Add(time.Duration(minutes)*time.Minute), confirm)
+		if err != nil {
+			t.Fatalf("observe %s at +%dm: %v", state, minutes, err)
+		}
+		return r
+	}
+
+	if r := step(0, StateHealthy); r != nil {
+		t.Fatalf("first sight should not recover: %+v", r)
+	}
+	step(10, StateWarning)
+	step(20, StateCritical)
+	if r := step(30, StateHealthy); r != nil {
+		t.Fatalf("recovered before the confirmation period: %+v", r)
+	}
+	// Flapping back restarts the confirmation
+	step(40, StateWarning)
+	step(50, StateHealthy)
+	if r := step(75, StateHealthy); r != nil {
+		t.Fatalf("recovered 25m after settling: %+v", r)
+	}
+
+	r := step(80, StateHealthy)
+	if r == nil {
+		t.Fatal("expected a recovery 30m after settling")
+	}
+	if r.From != StateCritical || !r.UnhealthySince.Equal(start.Add(10*time.Minute)) || !r.HealthySince.Equal(start.Add(50*time.Minute)) {
+		t.Errorf("recovery = %+v", r)
+	}
+
+	if r := step(120, StateHealthy); r != nil {
+		t.Errorf("recovery announced twice: %+v", r)
+	}
+}
+
+func TestObserveFirstSightUnhealthy(t *testing.T) {
+	db := setupTestDB(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	observe(db, KindPool, "nas01", "tank", StateWarning, now, 0)
+	r, err := observe(db, KindPool, "nas01", "tank", StateHealthy, now.Add(time.Minute), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r == nil || r.From != StateWarning || r.Name != "tank" {
+		t.Errorf("expected an immediate recovery with no confirmation period, got %+v", r)
+	}
+
+	if _, err := observe(db, KindPool, "nas01", "tank", "ONLINE", now, 0); err == nil {
+		t.Error("expected an unknown state to be rejected")
+	}
+}
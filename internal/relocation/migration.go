@@ -0,0 +1,68 @@
+package relocation
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// Migrate creates the drive presence, relocation and replacement tables.
+func Migrate(db *sql.DB) error {
+	log.Println("🚚 Running migration: Drive relocation tables")
+
+	statements := []struct {
+		label string
+		sql   string
+	}{
+		{"drive_presence", `
+			CREATE TABLE IF NOT EXISTS drive_presence (
+				hostname      TEXT     NOT NULL,
+				serial_number TEXT     NOT NULL,
+				model         TEXT     DEFAULT '',
+				first_seen    DATETIME NOT NULL,
+				last_seen     DATETIME NOT NULL,
+				missing_since DATETIME,
+				relocated_to  TEXT     DEFAULT '',
+				PRIMARY KEY (hostname, serial_number)
+			);`},
+		{"drive_presence indexes", `
+			CREATE INDEX IF NOT EXISTS idx_drive_presence_serial ON drive_presence(serial_number);`},
+		{"drive_relocations", `
+			CREATE TABLE IF NOT EXISTS drive_relocations (
+				id             INTEGER PRIMARY KEY AUTOINCREMENT,
+				serial_number  TEXT     NOT NULL,
+				model          TEXT     DEFAULT '',
+				from_hostname  TEXT     NOT NULL,
+				to_hostname    TEXT     NOT NULL,
+				last_seen_from DATETIME,
+				relocated_at   DATETIME DEFAULT CURRENT_TIMESTAMP
+			);`},
+		{"drive_relocations indexes", `
+			CREATE INDEX IF NOT EXISTS idx_drive_relocations_serial ON drive_relocations(serial_number);`},
+		{"drive_replacements", `
+			CREATE TABLE IF NOT EXISTS drive_replacements (
+				id            INTEGER PRIMARY KEY AUTOINCREMENT,
+				hostname      TEXT     NOT NULL,
+				old_serial    TEXT     NOT NULL,
+				new_serial    TEXT     NOT NULL,
+				old_model     TEXT     DEFAULT '',
+				alias         TEXT     DEFAULT '',
+				carried_over  INTEGER  DEFAULT 0,
+				replaced_by   TEXT     DEFAULT '',
+				replaced_at   DATETIME DEFAULT CURRENT_TIMESTAMP
+			);`},
+		{"drive_replacements indexes", `
+			CREATE INDEX IF NOT EXISTS idx_drive_replacements_old ON drive_replacements(old_serial);
+			CREATE INDEX IF NOT EXISTS idx_drive_replacements_new ON drive_replacements(new_serial);`},
+	}
+
+	for _, s := range statements {
+		if _, err := db.Exec(s.sql); err != nil {
+			return fmt.Errorf("relocation migration failed at [%s]: %w", s.label, err)
+		}
+		log.Printf("  ✓ %s", s.label)
+	}
+
+	log.Println("🚚 Migration completed: Drive relocation tables ready")
+	return nil
+}
@@ -0,0 +1,321 @@
+// Package relocation tracks which hosts each drive serial has been seen on
+// and detects drives that were physically moved from one host to another,
+// so a drive's history can be followed across machines instead of showing
+// up as a missing drive on one host and a brand-new drive on another.
+package relocation
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	vigildb "vigil/internal/db"
+	"vigil/internal/events"
+)
+
+const timeFormat = "2006-01-02 15:04:05"
+
+// DefaultWindowHours is how long after going missing on one host a serial
+// may turn up on another and still be treated as the same drive moving.
+const DefaultWindowHours = 168
+
+// Presence is one host a drive has been attached to.
+type Presence struct {
+	Hostname     string     `json:"hostname"`
+	SerialNumber string     `json:"serial_number"`
+	Model        string     `json:"model"`
+	FirstSeen    time.Time  `json:"first_seen"`
+	LastSeen     time.Time  `json:"last_seen"`
+	MissingSince *time.Time `json:"missing_since,omitempty"`
+	RelocatedTo  string     `json:"relocated_to,omitempty"`
+}
+
+// Relocation records a drive moving from one host to another.
+type Relocation struct {
+	ID           int64     `json:"id"`
+	SerialNumber string    `json:"serial_number"`
+	Model        string    `json:"model"`
+	FromHostname string    `json:"from_hostname"`
+	ToHostname   string    `json:"to_hostname"`
+	LastSeenFrom time.Time `json:"last_seen_from"`
+	RelocatedAt  time.Time `json:"relocated_at"`
+}
+
+// Timeline is the cross-host history of a single drive serial.
+type Timeline struct {
+	SerialNumber string        `json:"serial_number"`
+	Hosts        []Presence    `json:"hosts"`
+	Relocations  []Relocation  `json:"relocations"`
+	Replacements []Replacement `json:"replacements"`
+}
+
+// presenceRow is the subset of drive_presence needed while processing a report.
+type presenceRow struct {
+	lastSeen time.Time
+	missing  bool
+}
+
+// ProcessReport updates drive presence for the serials in an agent report
+// and records any relocations it detects.
+func ProcessReport(db *sql.DB, bus *events.Bus, hostname string, payload map[string]interface{}, window time.Duration) {
+	drives, ok := payload["drives"].([]interface{})
+	if !ok {
+		return
+	}
+
+	seen := make(map[string]string, len(drives))
+	for _, d := range drives {
+		drive, ok := d.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		serial, _ := drive["serial_number"].(string)
+		if serial == "" {
+			continue
+		}
+		model, _ := drive["model_name"].(string)
+		seen[serial] = model
+	}
+
+	if err := Observe(db, bus, hostname, seen, time.Now(), window); err != nil {
+		log.Printf("⚠️  Drive relocation tracking failed for %s: %v", hostname, err)
+	}
+}
+
+// Observe reconciles the serials (serial → model) reported by hostname at
+// time now against stored presence. A serial that is new to this host and
+// went missing from another host within window — or a serial that has gone
+// missing here but appeared on another host within window of its last
+// sighting — is recorded as a relocation rather than a new/missing drive.
+//
+// An empty report is ignored so a failed collection does not mark every
+// drive on the host as missing.
+func Observe(db *sql.DB, bus *events.Bus, hostname string, seen map[string]string, now time.Time, window time.Duration) error {
+	if len(seen) == 0 {
+		return nil
+	}
+
+	existing, err := hostPresence(db, hostname)
+	if err != nil {
+		return err
+	}
+	nowStr := now.UTC().Format(timeFormat)
+
+	serials := make([]string, 0, len(seen))
+	for s := range seen {
+		serials = append(serials, s)
+	}
+	sort.Strings(serials)
+
+	for _, serial := range serials {
+		model := seen[serial]
+		row, known := existing[serial]
+
+		if known && !row.missing {
+			if _, err := db.Exec(`
+				UPDATE drive_presence SET last_seen = ?, model = ?
+				WHERE hostname = ? AND serial_number = ?
+			`, nowStr, model, hostname, serial); err != nil {
+				return fmt.Errorf("update drive presence: %w", err)
+			}
+			continue
+		}
+
+		// New to this host, or back after going missing / moving away.
+		if _, err := db.Exec(`
+			INSERT INTO drive_presence (hostname, serial_number, model, first_seen, last_seen)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(hostname, serial_number) DO UPDATE SET
+				model = excluded.model,
+				first_seen = CASE WHEN drive_presence.relocated_to != '' THEN excluded.first_seen ELSE drive_presence.first_seen END,
+				last_seen = excluded.last_seen,
+				missing_since = NULL,
+				relocated_to = ''
+		`, hostname, serial, model, nowStr, nowStr); err != nil {
+			return fmt.Errorf("insert drive presence: %w", err)
+		}
+
+		var from, lastSeenFrom string
+		err := db.QueryRow(`
+			SELECT hostname, last_seen FROM drive_presence
+			WHERE serial_number = ? AND hostname != ?
+			  AND missing_since IS NOT NULL AND missing_since >= ?
+			  AND relocated_to = ''
+			ORDER BY missing_since DESC LIMIT 1
+		`, serial, hostname, now.Add(-window).UTC().Format(timeFormat)).Scan(&from, &lastSeenFrom)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("find missing drive: %w", err)
+		}
+		if err := recordRelocation(db, bus, serial, model, from, hostname, vigildb.ParseTime(lastSeenFrom), now); err != nil {
+			return err
+		}
+	}
+
+	for serial, row := range existing {
+		if row.missing {
+			continue
+		}
+		if _, ok := seen[serial]; ok {
+			continue
+		}
+
+		if _, err := db.Exec(`
+			UPDATE drive_presence SET missing_since = ?
+			WHERE hostname = ? AND serial_number = ?
+		`, nowStr, hostname, serial); err != nil {
+			return fmt.Errorf("mark drive missing: %w", err)
+		}
+
+		// The destination host may have reported before this one noticed
+		// the drive was gone.
+		var to, model string
+		err := db.QueryRow(`
+			SELECT hostname, model FROM drive_presence
+			WHERE serial_number = ? AND hostname != ?
+			  AND missing_since IS NULL
+			  AND first_seen >= ? AND first_seen <= ?
+			ORDER BY first_seen DESC LIMIT 1
+		`, serial, hostname, row.lastSeen.Format(timeFormat), row.lastSeen.Add(window).Format(timeFormat)).Scan(&to, &model)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("find relocated drive: %w", err)
+		}
+		if err := recordRelocation(db, bus, serial, model, hostname, to, row.lastSeen, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func hostPresence(db *sql.DB, hostname string) (map[string]presenceRow, error) {
+	rows, err := db.Query(`
+		SELECT serial_number, last_seen, missing_since IS NOT NULL
+		FROM drive_presence WHERE hostname = ?
+	`, hostname)
+	if err != nil {
+		return nil, fmt.Errorf("query drive presence: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]presenceRow)
+	for rows.Next() {
+		var serial, lastSeen string
+		var r presenceRow
+		if err := rows.Scan(&serial, &lastSeen, &r.missing); err != nil {
+			return nil, fmt.Errorf("scan drive presence: %w", err)
+		}
+		r.lastSeen = vigildb.ParseTime(lastSeen)
+		out[serial] = r
+	}
+	return out, rows.Err()
+}
+
+func recordRelocation(db *sql.DB, bus *events.Bus, serial, model, from, to string, lastSeenFrom, now time.Time) error {
+	at := now.UTC().Format(timeFormat)
+	if _, err := db.Exec(`
+		INSERT INTO drive_relocations (serial_number, model, from_hostname, to_hostname, last_seen_from, relocated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, serial, model, from, to, lastSeenFrom.UTC().Format(timeFormat), at); err != nil {
+		return fmt.Errorf("record relocation: %w", err)
+	}
+	if _, err := db.Exec(`
+		UPDATE drive_presence SET relocated_to = ?, missing_since = COALESCE(missing_since, ?)
+		WHERE hostname = ? AND serial_number = ?
+	`, to, at, from, serial); err != nil {
+		return fmt.Errorf("mark drive relocated: %w", err)
+	}
+
+	log.Printf("🚚 Drive %s relocated: %s → %s", serial, from, to)
+	if bus != nil {
+		bus.Publish(events.Event{
+			Type:         events.DriveRelocated,
+			Severity:     events.SeverityInfo,
+			Hostname:     to,
+			SerialNumber: serial,
+			Message:      fmt.Sprintf("🚚 Drive %s moved from %s to %s", serial, from, to),
+			Metadata: map[string]string{
+				"model":         model,
+				"from_hostname": from,
+				"to_hostname":   to,
+			},
+		})
+	}
+	return nil
+}
+
+// GetTimeline returns every host a serial has been seen on (oldest first),
+// the relocations between them and the replacements it took part in.
+func GetTimeline(db *sql.DB, serial string) (*Timeline, error) {
+	t := &Timeline{SerialNumber: serial, Hosts: []Presence{}, Relocations: []Relocation{}}
+
+	rows, err := db.Query(`
+		SELECT hostname, serial_number, COALESCE(model, ''), first_seen, last_seen,
+		       missing_since, COALESCE(relocated_to, '')
+		FROM drive_presence
+		WHERE serial_number = ?
+		ORDER BY first_seen ASC
+	`, serial)
+	if err != nil {
+		return nil, fmt.Errorf("query drive presence: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p Presence
+		var first, last string
+		var missing sql.NullString
+		if err := rows.Scan(&p.Hostname, &p.SerialNumber, &p.Model, &first, &last, &missing, &p.RelocatedTo); err != nil {
+			return nil, fmt.Errorf("scan drive presence: %w", err)
+		}
+		p.FirstSeen = vigildb.ParseTime(first)
+		p.LastSeen = vigildb.ParseTime(last)
+		if missing.Valid {
+			if ts := vigildb.ParseTime(missing.String); !ts.IsZero() {
+				p.MissingSince = &ts
+			}
+		}
+		t.Hosts = append(t.Hosts, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	relRows, err := db.Query(`
+		SELECT id, serial_number, COALESCE(model, ''), from_hostname, to_hostname,
+		       COALESCE(last_seen_from, ''), relocated_at
+		FROM drive_relocations
+		WHERE serial_number = ?
+		ORDER BY relocated_at ASC, id ASC
+	`, serial)
+	if err != nil {
+		return nil, fmt.Errorf("query drive relocations: %w", err)
+	}
+	defer relRows.Close()
+
+	for relRows.Next() {
+		var r Relocation
+		var lastSeen, at string
+		if err := relRows.Scan(&r.ID, &r.SerialNumber, &r.Model, &r.FromHostname, &r.ToHostname, &lastSeen, &at); err != nil {
+			return nil, fmt.Errorf("scan drive relocation: %w", err)
+		}
+		r.LastSeenFrom = vigildb.ParseTime(lastSeen)
+		r.RelocatedAt = vigildb.ParseTime(at)
+		t.Relocations = append(t.Relocations, r)
+	}
+	if err := relRows.Err(); err != nil {
+		return nil, err
+	}
+
+	if t.Replacements, err = GetReplacements(db, serial); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
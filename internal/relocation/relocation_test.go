@@ -0,0 +1,130 @@
+package relocation
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"vigil/internal/events"
+
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func collectEvents(bus *events.Bus) func() []events.Event {
+	var mu sync.Mutex
+	var got []events.Event
+	bus.Subscribe(func(e events.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, e)
+	})
+	return func() []events.Event {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]events.Event(nil), got...)
+	}
+}
+
+func TestObserveMissingThenAppears(t *testing.T) {
+	db := setupTestDB(t)
+	bus := events.NewBus()
+	got := collectEvents(bus)
+	window := 24 * time.Hour
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// nas01 reports two drives, then SN1 is pulled.
+	Observe(db, bus, "nas01", map[string]string{"SN1": "WD40", "SN2": "WD40"}, t0, window)
+	Observe(db, bus, "nas01", map[string]string{"SN2": "WD40"}, t0.Add(time.Hour), window)
+	// SN1 shows up on nas02 a few hours later.
+	if err := Observe(db, bus, "nas02", map[string]string{"SN1": "WD40"}, t0.Add(3*time.Hour), window); err != nil {
+		t.Fatalf("Observe: %v", err)
+	}
+
+	tl, err := GetTimeline(db, "SN1")
+	if err != nil {
+		t.Fatalf("GetTimeline: %v", err)
+	}
+	if len(tl.Relocations) != 1 {
+		t.Fatalf("expected 1 relocation, got %d", len(tl.Relocations))
+	}
+	rel := tl.Relocations[0]
+	if rel.FromHostname != "nas01" || rel.ToHostname != "nas02" {
+		t.Errorf("relocation = %s → %s, want nas01 → nas02", rel.FromHostname, rel.ToHostname)
+	}
+	if !rel.LastSeenFrom.Equal(t0) {
+		t.Errorf("last_seen_from = %v, want %v", rel.LastSeenFrom, t0)
+	}
+	if len(tl.Hosts) != 2 || tl.Hosts[0].RelocatedTo != "nas02" {
+		t.Errorf("unexpected hosts: %+v", tl.Hosts)
+	}
+
+	evs := got()
+	if len(evs) != 1 || evs[0].Type != events.DriveRelocated || evs[0].Hostname != "nas02" {
+		t.Errorf("expected one drive_relocated event for nas02, got %+v", evs)
+	}
+}
+
+func TestObserveAppearsBeforeOldHostReports(t *testing.T) {
+	db := setupTestDB(t)
+	window := 24 * time.Hour
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	Observe(db, nil, "nas01", map[string]string{"SN1": "WD40", "SN2": "WD40"}, t0, window)
+	// nas02 reports the moved drive before nas01 notices it is gone.
+	Observe(db, nil, "nas02", map[string]string{"SN1": "WD40"}, t0.Add(30*time.Minute), window)
+	Observe(db, nil, "nas01", map[string]string{"SN2": "WD40"}, t0.Add(time.Hour), window)
+
+	tl, err := GetTimeline(db, "SN1")
+	if err != nil {
+		t.Fatalf("GetTimeline: %v", err)
+	}
+	if len(tl.Relocations) != 1 || tl.Relocations[0].ToHostname != "nas02" {
+		t.Fatalf("expected relocation to nas02, got %+v", tl.Relocations)
+	}
+}
+
+func TestObserveOutsideWindowIsNotRelocation(t *testing.T) {
+	db := setupTestDB(t)
+	window := 24 * time.Hour
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	Observe(db, nil, "nas01", map[string]string{"SN1": "WD40", "SN2": "WD40"}, t0, window)
+	Observe(db, nil, "nas01", map[string]string{"SN2": "WD40"}, t0.Add(time.Hour), window)
+	Observe(db, nil, "nas02", map[string]string{"SN1": "WD40"}, t0.Add(72*time.Hour), window)
+
+	tl, err := GetTimeline(db, "SN1")
+	if err != nil {
+		t.Fatalf("GetTimeline: %v", err)
+	}
+	if len(tl.Relocations) != 0 {
+		t.Errorf("expected no relocation outside window, got %+v", tl.Relocations)
+	}
+}
+
+func TestObserveEmptyReportKeepsDrives(t *testing.T) {
+	db := setupTestDB(t)
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	Observe(db, nil, "nas01", map[string]string{"SN1": "WD40"}, t0, time.Hour)
+	Observe(db, nil, "nas01", map[string]string{}, t0.Add(time.Minute), time.Hour)
+
+	tl, _ := GetTimeline(db, "SN1")
+	if len(tl.Hosts) != 1 || tl.Hosts[0].MissingSince != nil {
+		t.Errorf("empty report should not mark drives missing: %+v", tl.Hosts)
+	}
+}
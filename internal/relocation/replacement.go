@@ -0,0 +1,165 @@
+package relocation
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	vigildb "vigil/internal/db"
+)
+
+// ErrUnknownDrive is returned by ReplaceDrive when the old serial was never
+// seen on the host.
+var ErrUnknownDrive = errors.New("drive not seen on this host")
+
+// Replacement records a drive in a host being swapped for a new one.
+type Replacement struct {
+	ID          int64     `json:"id"`
+	Hostname    string    `json:"hostname"`
+	OldSerial   string    `json:"old_serial"`
+	NewSerial   string    `json:"new_serial"`
+	OldModel    string    `json:"old_model"`
+	Alias       string    `json:"alias,omitempty"` // the old drive's alias at the time
+	CarriedOver bool      `json:"carried_over"`
+	ReplacedBy  string    `json:"replaced_by,omitempty"`
+	ReplacedAt  time.Time `json:"replaced_at"`
+
+	// Changes holds the rows ReplaceDrive touched, per step. Only set on
+	// the Replacement it returns.
+	Changes map[string]int64 `json:"changes,omitempty"`
+}
+
+// ReplaceDrive records that oldSerial on hostname was swapped for
+// newSerial. The old drive's history stays under its serial; its open
+// temperature alerts and spikes are acknowledged and the learned
+// temperature baselines of both drives are dropped, so the new drive
+// starts fresh. With carryOver, the old drive's alias and group
+// memberships move to the new one. Everything happens in one transaction.
+func ReplaceDrive(db *sql.DB, hostname, oldSerial, newSerial string, carryOver bool, by string, now time.Time) (*Replacement, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rep := &Replacement{
+		Hostname:    hostname,
+		OldSerial:   oldSerial,
+		NewSerial:   newSerial,
+		CarriedOver: carryOver,
+		ReplacedBy:  by,
+		ReplacedAt:  now.UTC().Truncate(time.Second),
+		Changes:     make(map[string]int64),
+	}
+
+	err = tx.QueryRow(`
+		SELECT COALESCE(model, '') FROM drive_presence
+		WHERE hostname = ? AND serial_number = ?
+	`, hostname, oldSerial).Scan(&rep.OldModel)
+	if err == sql.ErrNoRows {
+		return nil, ErrUnknownDrive
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find old drive: %w", err)
+	}
+	err = tx.QueryRow(`
+		SELECT alias FROM drive_aliases WHERE hostname = ? AND serial_number = ?
+	`, hostname, oldSerial).Scan(&rep.Alias)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("find old alias: %w", err)
+	}
+
+	nowStr := rep.ReplacedAt.Format(timeFormat)
+	ackBy := "replacement"
+	if by != "" {
+		ackBy = by
+	}
+	steps := []struct {
+		label string
+		sql   string
+		args  []interface{}
+		carry bool
+	}{
+		{"temperature_alerts_acknowledged", `
+			UPDATE temperature_alerts SET acknowledged = 1, acknowledged_by = ?, acknowledged_at = ?
+			WHERE hostname = ? AND serial_number = ? AND acknowledged = 0`,
+			[]interface{}{ackBy, nowStr, hostname, oldSerial}, false},
+		{"temperature_spikes_acknowledged", `
+			UPDATE temperature_spikes SET acknowledged = 1, acknowledged_by = ?, acknowledged_at = ?
+			WHERE hostname = ? AND serial_number = ? AND acknowledged = 0`,
+			[]interface{}{ackBy, nowStr, hostname, oldSerial}, false},
+		{"temperature_baselines_reset", `
+			DELETE FROM temperature_baselines WHERE hostname = ? AND serial_number IN (?, ?)`,
+			[]interface{}{hostname, oldSerial, newSerial}, false},
+		{"alias_carried", `
+			INSERT INTO drive_aliases (hostname, serial_number, alias)
+			SELECT hostname, ?, alias FROM drive_aliases WHERE hostname = ? AND serial_number = ?
+			ON CONFLICT(hostname, serial_number) DO UPDATE SET alias = excluded.alias`,
+			[]interface{}{newSerial, hostname, oldSerial}, true},
+		{"old_alias_removed", `
+			DELETE FROM drive_aliases WHERE hostname = ? AND serial_number = ?`,
+			[]interface{}{hostname, oldSerial}, true},
+		{"groups_carried", `
+			UPDATE OR IGNORE drive_group_members SET serial_number = ?
+			WHERE hostname = ? AND serial_number = ?`,
+			[]interface{}{newSerial, hostname, oldSerial}, true},
+		// The new drive may already have been in a group of its own.
+		{"old_groups_removed", `
+			DELETE FROM drive_group_members WHERE hostname = ? AND serial_number = ?`,
+			[]interface{}{hostname, oldSerial}, true},
+	}
+	for _, s := range steps {
+		if s.carry && !carryOver {
+			continue
+		}
+		result, err := tx.Exec(s.sql, s.args...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.label, err)
+		}
+		rep.Changes[s.label], _ = result.RowsAffected()
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO drive_replacements (hostname, old_serial, new_serial, old_model, alias, carried_over, replaced_by, replaced_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, hostname, oldSerial, newSerial, rep.OldModel, rep.Alias, carryOver, by, nowStr)
+	if err != nil {
+		return nil, fmt.Errorf("record replacement: %w", err)
+	}
+	rep.ID, _ = result.LastInsertId()
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return rep, nil
+}
+
+// GetReplacements returns the replacements a serial took part in, as the
+// drive replaced or as its replacement, oldest first.
+func GetReplacements(db *sql.DB, serial string) ([]Replacement, error) {
+	rows, err := db.Query(`
+		SELECT id, hostname, old_serial, new_serial, COALESCE(old_model, ''), COALESCE(alias, ''),
+		       carried_over, COALESCE(replaced_by, ''), replaced_at
+		FROM drive_replacements
+		WHERE old_serial = ? OR new_serial = ?
+		ORDER BY replaced_at ASC, id ASC
+	`, serial, serial)
+	if err != nil {
+		return nil, fmt.Errorf("query drive replacements: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Replacement{}
+	for rows.Next() {
+		var r Replacement
+		var at string
+		if err := rows.Scan(&r.ID, &r.Hostname, &r.OldSerial, &r.NewSerial, &r.OldModel, &r.Alias,
+			&r.CarriedOver, &r.ReplacedBy, &at); err != nil {
+			return nil, fmt.Errorf("scan drive replacement: %w", err)
+		}
+		r.ReplacedAt = vigildb.ParseTime(at)
+		list = append(list, r)
+	}
+	return list, rows.Err()
+}
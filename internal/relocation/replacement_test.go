@@ -0,0 +1,115 @@
+package relocation
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+// setupReplacementDB adds the minimal tables ReplaceDrive touches outside
+// this package.
+func setupReplacementDB(t *testing.T) *sql.DB {
+	db := setupTestDB(t)
+	for _, stmt := range []string{
+		`CREATE TABLE temperature_alerts (id INTEGER PRIMARY KEY, hostname TEXT, serial_number TEXT,
+			acknowledged INTEGER DEFAULT 0, acknowledged_by TEXT, acknowledged_at DATETIME)`,
+		`CREATE TABLE temperature_spikes (id INTEGER PRIMARY KEY, hostname TEXT, serial_number TEXT,
+			acknowledged INTEGER DEFAULT 0, acknowledged_by TEXT, acknowledged_at DATETIME)`,
+		`CREATE TABLE temperature_baselines (hostname TEXT, serial_number TEXT, PRIMARY KEY (hostname, serial_number))`,
+		`CREATE TABLE drive_aliases (id INTEGER PRIMARY KEY, hostname TEXT, serial_number TEXT, alias TEXT,
+			UNIQUE(hostname, serial_number))`,
+		`CREATE TABLE drive_group_members (id INTEGER PRIMARY KEY, group_id INTEGER, hostname TEXT, serial_number TEXT,
+			UNIQUE(hostname, serial_number))`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("setup: %v", err)
+		}
+	}
+	return db
+}
+
+func TestReplaceDrive(t *testing.T) {
+	db := setupReplacementDB(t)
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := Observe(db, nil, "nas01", map[string]string{"OLD1": "WD Red"}, t0, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		`INSERT INTO temperature_alerts (hostname, serial_number) VALUES ('nas01', 'OLD1'), ('nas01', 'OTHER')`,
+		`INSERT INTO temperature_spikes (hostname, serial_number) VALUES ('nas01', 'OLD1')`,
+		`INSERT INTO temperature_baselines VALUES ('nas01', 'OLD1'), ('nas01', 'NEW1')`,
+		`INSERT INTO drive_aliases (hostname, serial_number, alias) VALUES ('nas01', 'OLD1', 'Bay 3')`,
+		`INSERT INTO drive_group_members (group_id, hostname, serial_number) VALUES (1, 'nas01', 'OLD1')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rep, err := ReplaceDrive(db, "nas01", "OLD1", "NEW1", true, "admin", t0.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("ReplaceDrive: %v", err)
+	}
+	if rep.OldModel != "WD Red" || rep.Alias != "Bay 3" {
+		t.Errorf("replacement = %+v, want model WD Red and alias Bay 3", rep)
+	}
+	for label, want := range map[string]int64{
+		"temperature_alerts_acknowledged": 1,
+		"temperature_spikes_acknowledged": 1,
+		"temperature_baselines_reset":     2,
+		"alias_carried":                   1,
+		"groups_carried":                  1,
+	} {
+		if rep.Changes[label] != want {
+			t.Errorf("changes[%s] = %d, want %d", label, rep.Changes[label], want)
+		}
+	}
+
+	var alias string
+	if err := db.QueryRow(`SELECT alias FROM drive_aliases WHERE serial_number = 'NEW1'`).Scan(&alias); err != nil || alias != "Bay 3" {
+		t.Errorf("new drive alias = %q (%v), want Bay 3", alias, err)
+	}
+	var open int
+	db.QueryRow(`SELECT COUNT(*) FROM temperature_alerts WHERE acknowledged = 0`).Scan(&open)
+	if open != 1 {
+		t.Errorf("open alerts = %d, want only the other drive's", open)
+	}
+
+	for _, serial := range []string{"OLD1", "NEW1"} {
+		tl, err := GetTimeline(db, serial)
+		if err != nil {
+			t.Fatalf("GetTimeline(%s): %v", serial, err)
+		}
+		if len(tl.Replacements) != 1 || tl.Replacements[0].NewSerial != "NEW1" || !tl.Replacements[0].CarriedOver {
+			t.Errorf("%s timeline replacements = %+v", serial, tl.Replacements)
+		}
+	}
+}
+
+func TestReplaceDriveWithoutCarryOver(t *testing.T) {
+	db := setupReplacementDB(t)
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	Observe(db, nil, "nas01", map[string]string{"OLD1": "WD Red"}, t0, time.Hour)
+	db.Exec(`INSERT INTO drive_aliases (hostname, serial_number, alias) VALUES ('nas01', 'OLD1', 'Bay 3')`)
+
+	rep, err := ReplaceDrive(db, "nas01", "OLD1", "NEW1", false, "", t0)
+	if err != nil {
+		t.Fatalf("ReplaceDrive: %v", err)
+	}
+	if rep.Alias != "Bay 3" {
+		t.Errorf("alias = %q, want the old drive's alias to offer", rep.Alias)
+	}
+	var n int
+	db.QueryRow(`SELECT COUNT(*) FROM drive_aliases WHERE serial_number = 'OLD1'`).Scan(&n)
+	if n != 1 {
+		t.Error("old alias should stay without carry_over")
+	}
+}
+
+func TestReplaceDriveUnknown(t *testing.T) {
+	db := setupReplacementDB(t)
+	if _, err := ReplaceDrive(db, "nas01", "NOPE", "NEW1", false, "", time.Now()); err != ErrUnknownDrive {
+		t.Errorf("err = %v, want ErrUnknownDrive", err)
+	}
+}
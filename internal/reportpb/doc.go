@@ -0,0 +1,5 @@
+// Package reportpb holds the protobuf schema and generated gRPC code for
+// report ingestion over gRPC.
+package reportpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative report.proto
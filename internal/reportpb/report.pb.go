@@ -0,0 +1,378 @@
+// Report ingestion over gRPC, an alternative to POST /api/report for large
+// fleets. DriveReport mirrors the agent's JSON report; the server converts
+// it to the same payload and stores it through the same path.
+//
+// Regenerate with go generate ./internal/reportpb (needs protoc,
+// protoc-gen-go and protoc-gen-go-grpc).
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: report.proto
+
+package reportpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DriveReport struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	SchemaVersion   int32                  `protobuf:"varint,1,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	Hostname        string                 `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Timestamp       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	AgentVersion    string                 `protobuf:"bytes,4,opt,name=agent_version,json=agentVersion,proto3" json:"agent_version,omitempty"`
+	SmartctlVersion string                 `protobuf:"bytes,5,opt,name=smartctl_version,json=smartctlVersion,proto3" json:"smartctl_version,omitempty"`
+	// smartctl -j output per drive, as in the JSON report.
+	Drives        []*structpb.Struct `protobuf:"bytes,6,rep,name=drives,proto3" json:"drives,omitempty"`
+	Zfs           *structpb.Struct   `protobuf:"bytes,7,opt,name=zfs,proto3" json:"zfs,omitempty"`
+	Capabilities  *Capabilities      `protobuf:"bytes,8,opt,name=capabilities,proto3" json:"capabilities,omitempty"`
+	Labels        map[string]string  `protobuf:"bytes,9,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Maintenance   bool               `protobuf:"varint,10,opt,name=maintenance,proto3" json:"maintenance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DriveReport) Reset() {
+	*x = DriveReport{}
+	mi := &file_report_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DriveReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DriveReport) ProtoMessage() {}
+
+func (x *DriveReport) ProtoReflect() protoreflect.Message {
+	mi := &file_report_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DriveReport.ProtoReflect.Descriptor instead.
+func (*DriveReport) Descriptor() ([]byte, []int) {
+	return file_report_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DriveReport) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *DriveReport) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *DriveReport) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *DriveReport) GetAgentVersion() string {
+	if x != nil {
+		return x.AgentVersion
+	}
+	return ""
+}
+
+func (x *DriveReport) GetSmartctlVersion() string {
+	if x != nil {
+		return x.SmartctlVersion
+	}
+	return ""
+}
+
+func (x *DriveReport) GetDrives() []*structpb.Struct {
+	if x != nil {
+		return x.Drives
+	}
+	return nil
+}
+
+func (x *DriveReport) GetZfs() *structpb.Struct {
+	if x != nil {
+		return x.Zfs
+	}
+	return nil
+}
+
+func (x *DriveReport) GetCapabilities() *Capabilities {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *DriveReport) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *DriveReport) GetMaintenance() bool {
+	if x != nil {
+		return x.Maintenance
+	}
+	return false
+}
+
+type Capabilities struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LedIdentify   bool                   `protobuf:"varint,1,opt,name=led_identify,json=ledIdentify,proto3" json:"led_identify,omitempty"`
+	LatencyProbe  bool                   `protobuf:"varint,2,opt,name=latency_probe,json=latencyProbe,proto3" json:"latency_probe,omitempty"`
+	ZfsClear      bool                   `protobuf:"varint,3,opt,name=zfs_clear,json=zfsClear,proto3" json:"zfs_clear,omitempty"`
+	ListenAddr    string                 `protobuf:"bytes,4,opt,name=listen_addr,json=listenAddr,proto3" json:"listen_addr,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Capabilities) Reset() {
+	*x = Capabilities{}
+	mi := &file_report_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Capabilities) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Capabilities) ProtoMessage() {}
+
+func (x *Capabilities) ProtoReflect() protoreflect.Message {
+	mi := &file_report_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Capabilities.ProtoReflect.Descriptor instead.
+func (*Capabilities) Descriptor() ([]byte, []int) {
+	return file_report_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Capabilities) GetLedIdentify() bool {
+	if x != nil {
+		return x.LedIdentify
+	}
+	return false
+}
+
+func (x *Capabilities) GetLatencyProbe() bool {
+	if x != nil {
+		return x.LatencyProbe
+	}
+	return false
+}
+
+func (x *Capabilities) GetZfsClear() bool {
+	if x != nil {
+		return x.ZfsClear
+	}
+	return false
+}
+
+func (x *Capabilities) GetListenAddr() string {
+	if x != nil {
+		return x.ListenAddr
+	}
+	return ""
+}
+
+type ReportResponse struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Status                string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	ReportIntervalSeconds int32                  `protobuf:"varint,2,opt,name=report_interval_seconds,json=reportIntervalSeconds,proto3" json:"report_interval_seconds,omitempty"`
+	IngestionErrors       int32                  `protobuf:"varint,3,opt,name=ingestion_errors,json=ingestionErrors,proto3" json:"ingestion_errors,omitempty"`
+	SchemaVersion         int32                  `protobuf:"varint,4,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *ReportResponse) Reset() {
+	*x = ReportResponse{}
+	mi := &file_report_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportResponse) ProtoMessage() {}
+
+func (x *ReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_report_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportResponse.ProtoReflect.Descriptor instead.
+func (*ReportResponse) Descriptor() ([]byte, []int) {
+	return file_report_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ReportResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ReportResponse) GetReportIntervalSeconds() int32 {
+	if x != nil {
+		return x.ReportIntervalSeconds
+	}
+	return 0
+}
+
+func (x *ReportResponse) GetIngestionErrors() int32 {
+	if x != nil {
+		return x.IngestionErrors
+	}
+	return 0
+}
+
+func (x *ReportResponse) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+var File_report_proto protoreflect.FileDescriptor
+
+const file_report_proto_rawDesc = "" +
+	"\n" +
+	"\freport.proto\x12\x0fvigil.report.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x98\x04\n" +
+	"\vDriveReport\x12%\n" +
+	"\x0eschema_version\x18\x01 \x01(\x05R\rschemaVersion\x12\x1a\n" +
+	"\bhostname\x18\x02 \x01(\tR\bhostname\x128\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12#\n" +
+	"\ragent_version\x18\x04 \x01(\tR\fagentVersion\x12)\n" +
+	"\x10smartctl_version\x18\x05 \x01(\tR\x0fsmartctlVersion\x12/\n" +
+	"\x06drives\x18\x06 \x03(\v2\x17.google.protobuf.StructR\x06drives\x12)\n" +
+	"\x03zfs\x18\a \x01(\v2\x17.google.protobuf.StructR\x03zfs\x12A\n" +
+	"\fcapabilities\x18\b \x01(\v2\x1d.vigil.report.v1.CapabilitiesR\fcapabilities\x12@\n" +
+	"\x06labels\x18\t \x03(\v2(.vigil.report.v1.DriveReport.LabelsEntryR\x06labels\x12 \n" +
+	"\vmaintenance\x18\n" +
+	" \x01(\bR\vmaintenance\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x94\x01\n" +
+	"\fCapabilities\x12!\n" +
+	"\fled_identify\x18\x01 \x01(\bR\vledIdentify\x12#\n" +
+	"\rlatency_probe\x18\x02 \x01(\bR\flatencyProbe\x12\x1b\n" +
+	"\tzfs_clear\x18\x03 \x01(\bR\bzfsClear\x12\x1f\n" +
+	"\vlisten_addr\x18\x04 \x01(\tR\n" +
+	"listenAddr\"\xb2\x01\n" +
+	"\x0eReportResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x126\n" +
+	"\x17report_interval_seconds\x18\x02 \x01(\x05R\x15reportIntervalSeconds\x12)\n" +
+	"\x10ingestion_errors\x18\x03 \x01(\x05R\x0fingestionErrors\x12%\n" +
+	"\x0eschema_version\x18\x04 \x01(\x05R\rschemaVersion2X\n" +
+	"\rReportService\x12G\n" +
+	"\x06Submit\x12\x1c.vigil.report.v1.DriveReport\x1a\x1f.vigil.report.v1.ReportResponseB\x19Z\x17vigil/internal/reportpbb\x06proto3"
+
+var (
+	file_report_proto_rawDescOnce sync.Once
+	file_report_proto_rawDescData []byte
+)
+
+func file_report_proto_rawDescGZIP() []byte {
+	file_report_proto_rawDescOnce.Do(func() {
+		file_report_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_report_proto_rawDesc), len(file_report_proto_rawDesc)))
+	})
+	return file_report_proto_rawDescData
+}
+
+var file_report_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_report_proto_goTypes = []any{
+	(*DriveReport)(nil),           // 0: vigil.report.v1.DriveReport
+	(*Capabilities)(nil),          // 1: vigil.report.v1.Capabilities
+	(*ReportResponse)(nil),        // 2: vigil.report.v1.ReportResponse
+	nil,                           // 3: vigil.report.v1.DriveReport.LabelsEntry
+	(*timestamppb.Timestamp)(nil), // 4: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),       // 5: google.protobuf.Struct
+}
+var file_report_proto_depIdxs = []int32{
+	4, // 0: vigil.report.v1.DriveReport.timestamp:type_name -> google.protobuf.Timestamp
+	5, // 1: vigil.report.v1.DriveReport.drives:type_name -> google.protobuf.Struct
+	5, // 2: vigil.report.v1.DriveReport.zfs:type_name -> google.protobuf.Struct
+	1, // 3: vigil.report.v1.DriveReport.capabilities:type_name -> vigil.report.v1.Capabilities
+	3, // 4: vigil.report.v1.DriveReport.labels:type_name -> vigil.report.v1.DriveReport.LabelsEntry
+	0, // 5: vigil.report.v1.ReportService.Submit:input_type -> vigil.report.v1.DriveReport
+	2, // 6: vigil.report.v1.ReportService.Submit:output_type -> vigil.report.v1.ReportResponse
+	6, // [6:7] is the sub-list for method output_type
+	5, // [5:6] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_report_proto_init() }
+func file_report_proto_init() {
+	if File_report_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_report_proto_rawDesc), len(file_report_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_report_proto_goTypes,
+		DependencyIndexes: file_report_proto_depIdxs,
+		MessageInfos:      file_report_proto_msgTypes,
+	}.Build()
+	File_report_proto = out.File
+	file_report_proto_goTypes = nil
+	file_report_proto_depIdxs = nil
+}
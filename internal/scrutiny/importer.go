@@ -0,0 +1,338 @@
+package scrutiny
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	agentsmart "vigil/cmd/agent/smart"
+	"vigil/internal/smart"
+	"vigil/internal/validate"
+)
+
+// device gathers everything an export holds about one Scrutiny device.
+type device struct {
+	Device
+	results []SmartResult
+	temps   []TempReading
+}
+
+// Export is a parsed Scrutiny export, by device.
+type Export struct {
+	devices map[string]*device // by WWN, else serial number
+}
+
+// Devices returns how many devices the export holds.
+func (e *Export) Devices() int {
+	return len(e.devices)
+}
+
+// Parse reads a Scrutiny export held in memory; see Read.
+func Parse(data []byte) (*Export, error) {
+	return Read(bytes.NewReader(data))
+}
+
+// Read decodes a Scrutiny export: the JSON of GET /api/device/{wwn}/details
+// or GET /api/summary?duration_key=forever, or a JSON array of any number
+// of them (e.g. `jq -s . summary.json details-*.json`). An array is decoded
+// one document at a time, so only the parsed history is held in memory.
+// Devices are merged by WWN across documents.
+func Read(r io.Reader) (*Export, error) {
+	br := bufio.NewReader(r)
+	array := isArray(br)
+	dec := json.NewDecoder(br)
+	if array {
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	devices := make(map[string]*device)
+	get := func(d Device) *device {
+		key := d.WWN
+		if key == "" {
+			key = d.SerialNumber
+		}
+		dev, ok := devices[key]
+		if !ok {
+			dev = &device{Device: d}
+			devices[key] = dev
+		}
+		mergeDevice(&dev.Device, d)
+		return dev
+	}
+
+	for i := 0; !array || dec.More(); i++ {
+		var env envelope
+		if err := dec.Decode(&env); err != nil {
+			return nil, fmt.Errorf("document %d: %w", i+1, err)
+		}
+		if env.Data.Device == nil && env.Data.Summary == nil {
+			return nil, fmt.Errorf("document %d is neither a Scrutiny device details nor a summary response", i+1)
+		}
+		if env.Data.Device != nil {
+			dev := get(*env.Data.Device)
+			dev.results = append(dev.results, env.Data.SmartResults...)
+		}
+		for wwn, entry := range env.Data.Summary {
+			if entry.Device.WWN == "" {
+				entry.Device.WWN = wwn
+			}
+			dev := get(entry.Device)
+			dev.temps = append(dev.temps, entry.TempHistory...)
+		}
+		if !array {
+			break
+		}
+	}
+	if array {
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+	return &Export{devices: devices}, nil
+}
+
+// isArray reports whether the JSON in br is an array, skipping leading
+// whitespace without consuming anything else.
+func isArray(br *bufio.Reader) bool {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return false
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		br.UnreadByte() //nolint:errcheck
+		return b == '['
+	}
+}
+
+// mergeDevice fills in fields of dst that another document knew and it
+// didn't.
+func mergeDevice(dst *Device, src Device) {
+	if dst.SerialNumber == "" {
+		dst.SerialNumber = src.SerialNumber
+	}
+	if dst.ModelName == "" {
+		dst.ModelName = src.ModelName
+	}
+	if dst.DeviceName == "" {
+		dst.DeviceName = src.DeviceName
+	}
+	if dst.DeviceProtocol == "" {
+		dst.DeviceProtocol = src.DeviceProtocol
+	}
+	if dst.HostID == "" {
+		dst.HostID = src.HostID
+	}
+	if dst.Firmware == "" {
+		dst.Firmware = src.Firmware
+	}
+	if dst.Capacity == 0 {
+		dst.Capacity = src.Capacity
+	}
+	if dst.RotationalSpeed == 0 {
+		dst.RotationalSpeed = src.RotationalSpeed
+	}
+}
+
+// Import stores the parsed devices' SMART results and temperatures. Each
+// device's serial number is matched against the drives Vigil already
+// monitors so its history joins theirs; a device Vigil hasn't seen goes to
+// defaultHost, else to Scrutiny's host_id, else is skipped. Each device is
+// stored inside one call of write (the server passes db.Write, so other
+// writers get their turn between devices); nil stores them directly.
+func Import(db *sql.DB, exp *Export, defaultHost string, write func(func() error) error) (*Result, error) {
+	if write == nil {
+		write = func(fn func() error) error { return fn() }
+	}
+	devices := exp.devices
+	keys := make([]string, 0, len(devices))
+	for k := range devices {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	res := &Result{Devices: make([]DeviceResult, 0, len(keys))}
+	for _, k := range keys {
+		dev := devices[k]
+		dr := DeviceResult{WWN: dev.WWN, SerialNumber: dev.SerialNumber, Model: dev.ModelName}
+
+		if dev.SerialNumber == "" {
+			dr.Skipped = "no serial number (add the device's /api/device/{wwn}/details to the export)"
+		} else {
+			dr.Hostname, dr.MatchedBy = matchHost(db, dev.SerialNumber, defaultHost, dev.HostID)
+			switch {
+			case dr.Hostname != "":
+			case dev.HostID != "":
+				dr.Skipped = fmt.Sprintf("drive not known to Vigil, no hostname given and host_id %q isn't a valid hostname", dev.HostID)
+			default:
+				dr.Skipped = "drive not known to Vigil and no hostname given"
+			}
+		}
+		if dr.Skipped != "" {
+			res.Skipped++
+			res.Devices = append(res.Devices, dr)
+			continue
+		}
+
+		if err := write(func() error { return importDevice(db, dev, &dr) }); err != nil {
+			return res, fmt.Errorf("store %s: %w", dev.SerialNumber, err)
+		}
+		res.SmartResults += dr.SmartResults
+		res.Temperatures += dr.Temperatures
+		res.Devices = append(res.Devices, dr)
+	}
+	return res, nil
+}
+
+// importDevice stores one device's SMART results and temperatures under
+// dr.Hostname, counting them in dr. Temperatures outside the plausible
+// range (see smart.TemperatureBounds) are dropped.
+func importDevice(db *sql.DB, dev *device, dr *DeviceResult) error {
+	bounds := smart.LoadTemperatureBounds(db)
+
+	// Temperatures that come with a SMART result are stored with it
+	withSmart := make(map[int64]bool)
+	for _, r := range dev.results {
+		if r.Date.IsZero() {
+			continue
+		}
+		drive, err := agentsmart.ParseSmartAttributes(smartctlDocument(dev.Device, r), dr.Hostname)
+		if err != nil {
+			continue
+		}
+		drive.Timestamp = r.Date.UTC()
+		if len(drive.Attributes) > 0 {
+			if err := smart.StoreSmartAttributes(db, drive); err != nil {
+				return err
+			}
+			dr.SmartResults++
+			if drive.Temperature > 0 {
+				if bounds.Valid(drive.Temperature) {
+					dr.Temperatures++
+				}
+				withSmart[drive.Timestamp.Unix()] = true
+			}
+		} else if drive.Temperature > 0 {
+			stored, err := smart.StoreTemperature(db, dr.Hostname, dev.SerialNumber, drive.Temperature, drive.Timestamp)
+			if err != nil {
+				return err
+			}
+			if stored {
+				dr.Temperatures++
+			}
+			withSmart[drive.Timestamp.Unix()] = true
+		}
+	}
+	for _, t := range dev.temps {
+		if t.Date.IsZero() || t.Temp <= 0 || withSmart[t.Date.Unix()] {
+			continue
+		}
+		stored, err := smart.StoreTemperature(db, dr.Hostname, dev.SerialNumber, int(t.Temp), t.Date)
+		if err != nil {
+			return err
+		}
+		if stored {
+			dr.Temperatures++
+		}
+	}
+	return nil
+}
+
+// matchHost picks the hostname a device's history is stored under.
+// Scrutiny's host_id is free text; it is only used if it is a valid
+// hostname.
+func matchHost(db *sql.DB, serial, defaultHost, hostID string) (hostname, matchedBy string) {
+	err := db.QueryRow(`
+		SELECT hostname FROM (
+			SELECT hostname, timestamp FROM smart_attributes WHERE serial_number = ?
+			UNION ALL
+			SELECT hostname, timestamp FROM temperature_history WHERE serial_number = ?
+		) ORDER BY timestamp DESC LIMIT 1`, serial, serial).Scan(&hostname)
+	switch {
+	case err == nil && hostname != "":
+		return hostname, "serial"
+	case defaultHost != "":
+		return defaultHost, "hostname"
+	case hostID != "" && validate.Hostname(hostID) == nil:
+		return hostID, "host_id"
+	}
+	return "", ""
+}
+
+// smartctlDocument rebuilds the smartctl JSON a Scrutiny result was
+// collected from, so it goes through the same parser as agent reports.
+// ATA attributes keep their IDs; NVMe attributes map back onto smartctl's
+// health log fields. SCSI attributes have no Vigil equivalent: only their
+// temperature and power counters are kept.
+func smartctlDocument(d Device, r SmartResult) map[string]interface{} {
+	protocol := r.DeviceProtocol
+	if protocol == "" {
+		protocol = d.DeviceProtocol
+	}
+	doc := map[string]interface{}{
+		"serial_number":    d.SerialNumber,
+		"model_name":       d.ModelName,
+		"firmware_version": d.Firmware,
+		"rotation_rate":    float64(d.RotationalSpeed),
+		"device":           map[string]interface{}{"name": d.DeviceName, "protocol": protocol},
+		"user_capacity":    map[string]interface{}{"bytes": float64(d.Capacity)},
+	}
+	if r.Temp > 0 {
+		doc["temperature"] = map[string]interface{}{"current": float64(r.Temp)}
+	}
+	if r.PowerOnHours > 0 {
+		doc["power_on_time"] = map[string]interface{}{"hours": float64(r.PowerOnHours)}
+	}
+	if r.PowerCycleCount > 0 {
+		doc["power_cycle_count"] = float64(r.PowerCycleCount)
+	}
+
+	switch protocol {
+	case "NVMe":
+		health := make(map[string]interface{}, len(r.Attrs))
+		for key, a := range r.Attrs {
+			health[key] = a.Value
+		}
+		doc["nvme_smart_health_information_log"] = health
+	case "SCSI":
+		// Nothing beyond the counters above maps onto Vigil's attributes
+	default:
+		ids := make([]int, 0, len(r.Attrs))
+		for key := range r.Attrs {
+			if id, err := strconv.Atoi(key); err == nil {
+				ids = append(ids, id)
+			}
+		}
+		sort.Ints(ids)
+
+		table := make([]interface{}, 0, len(ids))
+		for _, id := range ids {
+			a := r.Attrs[strconv.Itoa(id)]
+			name := "Unknown_Attribute"
+			if def, ok := agentsmart.GetAttributeDefinition(id); ok {
+				name = def.Name
+			}
+			table = append(table, map[string]interface{}{
+				"id":          float64(id),
+				"name":        name,
+				"value":       a.Value,
+				"worst":       a.Worst,
+				"thresh":      a.Thresh,
+				"raw":         map[string]interface{}{"value": a.RawValue, "string": a.RawString},
+				"when_failed": a.WhenFailed,
+			})
+		}
+		doc["ata_smart_attributes"] = map[string]interface{}{"table": table}
+	}
+	return doc
+}
@@ -0,0 +1,135 @@
+package scrutiny
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"vigil/internal/smart"
+
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if err := smart.MigrateSmartAttributes(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestImportExport(t *testing.T) {
+	db := setupTestDB(t)
+	data, err := os.ReadFile("testdata/export.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The NVMe drive is already monitored by Vigil on nas02
+	if _, err := smart.StoreTemperature(db, "nas02", "S4EWNX0R", 39, mustTime(t, "2026-06-01T00:00:00Z")); err != nil {
+		t.Fatal(err)
+	}
+
+	exp, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if exp.Devices() != 3 {
+		t.Fatalf("devices = %d, want 3 (merged by WWN)", exp.Devices())
+	}
+
+	res, err := Import(db, exp, "", nil)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	byWWN := make(map[string]DeviceResult)
+	for _, d := range res.Devices {
+		byWWN[d.WWN] = d
+	}
+
+	hdd := byWWN["0x5000cca264eb01d7"]
+	if hdd.Hostname != "scrutiny-nas" || hdd.MatchedBy != "host_id" || hdd.SmartResults != 2 || hdd.Temperatures != 3 {
+		t.Errorf("HDD = %+v, want 2 SMART results and 3 temperatures under host_id", hdd)
+	}
+	nvme := byWWN["eui.0025385b71b0aa11"]
+	if nvme.Hostname != "nas02" || nvme.MatchedBy != "serial" || nvme.SmartResults != 1 || nvme.Temperatures != 2 {
+		t.Errorf("NVMe = %+v, want it matched to nas02 by serial", nvme)
+	}
+	if sc := byWWN["0x5000c500a1b2c3d4"]; sc.Skipped == "" || res.Skipped != 1 {
+		t.Errorf("device without a serial = %+v, skipped %d", sc, res.Skipped)
+	}
+
+	attrs, err := smart.GetLatestSmartAttributes(db, "scrutiny-nas", "9LGABCDE")
+	if err != nil || len(attrs) != 1 || attrs[0].ID != 5 || attrs[0].RawValue != 8 {
+		t.Errorf("latest HDD attributes = %+v, %v", attrs, err)
+	}
+	nvmeAttrs, _ := smart.GetLatestSmartAttributes(db, "nas02", "S4EWNX0R")
+	if len(nvmeAttrs) == 0 {
+		t.Error("expected NVMe attributes to be imported")
+	}
+
+	var n int
+	db.QueryRow(`SELECT COUNT(*) FROM temperature_history WHERE serial_number = '9LGABCDE'`).Scan(&n)
+	if n != 3 {
+		t.Errorf("HDD temperature rows = %d, want 3", n)
+	}
+
+	// Importing again doesn't duplicate anything
+	Import(db, exp, "", nil)
+	db.QueryRow(`SELECT COUNT(*) FROM temperature_history WHERE serial_number = '9LGABCDE'`).Scan(&n)
+	if n != 3 {
+		t.Errorf("HDD temperature rows after re-import = %d, want 3", n)
+	}
+}
+
+func TestImportDefaultHostname(t *testing.T) {
+	db := setupTestDB(t)
+	exp, err := Parse([]byte(`{"data": {"summary": {"w1": {
+		"device": {"serial_number": "SN1", "host_id": "elsewhere"},
+		"temp_history": [{"date": "2026-05-01T10:00:00Z", "temp": 30}, {"date": "2026-05-01T11:00:00Z", "temp": 255}]}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Import(db, exp, "nas01", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := res.Devices[0]; d.Hostname != "nas01" || d.MatchedBy != "hostname" || d.Temperatures != 1 {
+		t.Errorf("device = %+v, want the default hostname to win over host_id and the 255 reading dropped", d)
+	}
+
+	exp, err = Parse([]byte(`{"data": {"summary": {"w2": {
+		"device": {"serial_number": "SN2", "host_id": "../../etc"},
+		"temp_history": [{"date": "2026-05-01T10:00:00Z", "temp": 30}]}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = Import(db, exp, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := res.Devices[0]; d.Hostname != "" || d.Skipped == "" || res.Skipped != 1 {
+		t.Errorf("device = %+v, want a host_id that isn't a hostname to be skipped", d)
+	}
+
+	if _, err := Parse([]byte(`{"data": {}}`)); err == nil {
+		t.Error("expected a document that isn't a Scrutiny response to be rejected")
+	}
+}
+
+func mustTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	ts, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts
+}
@@ -0,0 +1,91 @@
+package scrutiny
+
+import "time"
+
+// The types below mirror the JSON of Scrutiny's web API, which is the
+// practical way to get data out of it: device metadata lives in its SQLite
+// database and the time series in InfluxDB, and the API joins the two.
+// Only the fields Vigil imports are declared.
+
+// envelope is the common shape of Scrutiny API responses.
+type envelope struct {
+	Data struct {
+		// GET /api/device/{wwn}/details
+		Device       *Device       `json:"device"`
+		SmartResults []SmartResult `json:"smart_results"`
+		// GET /api/summary
+		Summary map[string]SummaryEntry `json:"summary"`
+	} `json:"data"`
+}
+
+// Device is Scrutiny's device metadata.
+type Device struct {
+	WWN             string `json:"wwn"`
+	DeviceName      string `json:"device_name"`
+	ModelName       string `json:"model_name"`
+	SerialNumber    string `json:"serial_number"`
+	Firmware        string `json:"firmware"`
+	RotationalSpeed int    `json:"rotational_speed"`
+	Capacity        int64  `json:"capacity"`
+	DeviceProtocol  string `json:"device_protocol"` // "ATA", "NVMe" or "SCSI"
+	HostID          string `json:"host_id"`
+}
+
+// SmartResult is one SMART collection for a device.
+type SmartResult struct {
+	Date            time.Time            `json:"date"`
+	DeviceProtocol  string               `json:"device_protocol"`
+	Temp            int64                `json:"temp"`
+	PowerOnHours    int64                `json:"power_on_hours"`
+	PowerCycleCount int64                `json:"power_cycle_count"`
+	Attrs           map[string]Attribute `json:"attrs"`
+}
+
+// Attribute is a SMART attribute of a result. ATA attributes are keyed by
+// their numeric ID; NVMe and SCSI ones by smartctl's field name, which
+// attribute_id repeats.
+type Attribute struct {
+	AttributeID interface{} `json:"attribute_id"`
+	Value       float64     `json:"value"`
+	Worst       float64     `json:"worst"`
+	Thresh      float64     `json:"thresh"`
+	RawValue    float64     `json:"raw_value"`
+	RawString   string      `json:"raw_string"`
+	WhenFailed  string      `json:"when_failed"`
+}
+
+// SummaryEntry is a device in the /api/summary response, with its
+// temperature history.
+type SummaryEntry struct {
+	Device      Device        `json:"device"`
+	TempHistory []TempReading `json:"temp_history"`
+}
+
+// TempReading is one point of a device's temperature history.
+type TempReading struct {
+	Date time.Time `json:"date"`
+	Temp int64     `json:"temp"`
+}
+
+// DeviceResult reports what was imported for one Scrutiny device.
+type DeviceResult struct {
+	WWN          string `json:"wwn"`
+	SerialNumber string `json:"serial_number"`
+	Model        string `json:"model,omitempty"`
+	Hostname     string `json:"hostname,omitempty"`
+	// MatchedBy says how the hostname was chosen: "serial" (a drive Vigil
+	// already knows), "hostname" (the request's default) or "host_id"
+	// (Scrutiny's own host label).
+	MatchedBy    string `json:"matched_by,omitempty"`
+	SmartResults int    `json:"smart_results"`
+	Temperatures int    `json:"temperatures"`
+	Skipped      string `json:"skipped,omitempty"`
+}
+
+// Result summarizes an import.
+type Result struct {
+	Devices      []DeviceResult `json:"devices"`
+	SmartResults int            `json:"smart_results"`
+	Temperatures int            `json:"temperatures"`
+	Skipped      int            `json:"skipped"`
+}
@@ -4,7 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 	"strconv"
-	"time"
+
+	vigildb "vigil/internal/db"
 )
 
 // InitSettingsTable creates the settings table and populates defaults
@@ -28,6 +29,9 @@ func InitSettingsTable(db *sql.DB) error {
 	if _, err := db.Exec(createTableSQL); err != nil {
 		return fmt.Errorf("failed to create settings table: %w", err)
 	}
+	if err := migrateRenamedSettings(db); err != nil {
+		return err
+	}
 
 	insertSQL := `
 	INSERT OR IGNORE INTO settings (category, key, value, value_type, description)
@@ -50,6 +54,37 @@ func InitSettingsTable(db *sql.DB) error {
 	return nil
 }
 
+// renamedSettings maps settings that were replaced by a new key to it, so
+// a value the user set carries over instead of silently going back to the
+// new key's default.
+var renamedSettings = []struct{ fromCategory, fromKey, toCategory, toKey string }{
+	{"temperature", "retention_days", "retention", "temperature_days"},
+}
+
+// migrateRenamedSettings moves each renamed setting's stored value to its
+// new key, unless that is already set, and drops the old row. Runs before
+// the defaults are seeded so the carried-over value wins.
+func migrateRenamedSettings(db *sql.DB) error {
+	for _, r := range renamedSettings {
+		var def Setting
+		for _, d := range DefaultSettings {
+			if d.Category == r.toCategory && d.Key == r.toKey {
+				def = d
+			}
+		}
+		if _, err := db.Exec(`
+			INSERT OR IGNORE INTO settings (category, key, value, value_type, description)
+			SELECT ?, ?, value, ?, ? FROM settings WHERE category = ? AND key = ?`,
+			r.toCategory, r.toKey, def.ValueType, def.Description, r.fromCategory, r.fromKey); err != nil {
+			return fmt.Errorf("failed to migrate setting %s.%s: %w", r.fromCategory, r.fromKey, err)
+		}
+		if _, err := db.Exec(`DELETE FROM settings WHERE category = ? AND key = ?`, r.fromCategory, r.fromKey); err != nil {
+			return fmt.Errorf("failed to remove setting %s.%s: %w", r.fromCategory, r.fromKey, err)
+		}
+	}
+	return nil
+}
+
 // GetAllSettings retrieves all settings from the database
 func GetAllSettings(db *sql.DB) ([]Setting, error) {
 	query := `
@@ -71,7 +106,8 @@ func GetAllSettings(db *sql.DB) ([]Setting, error) {
 		if err := rows.Scan(&s.ID, &s.Category, &s.Key, &s.Value, &s.ValueType, &s.Description, &updatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan setting: %w", err)
 		}
-		s.UpdatedAt, _ = time.Parse("2006-01-02 15:04:05", updatedAt)
+		s.UpdatedAt = vigildb.ParseTime(updatedAt)
+		s.Default, _ = DefaultValue(s.Category, s.Key)
 		settings = append(settings, s)
 	}
 
@@ -100,7 +136,8 @@ func GetSettingsByCategory(db *sql.DB, category string) ([]Setting, error) {
 		if err := rows.Scan(&s.ID, &s.Category, &s.Key, &s.Value, &s.ValueType, &s.Description, &updatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan setting: %w", err)
 		}
-		s.UpdatedAt, _ = time.Parse("2006-01-02 15:04:05", updatedAt)
+		s.UpdatedAt = vigildb.ParseTime(updatedAt)
+		s.Default, _ = DefaultValue(s.Category, s.Key)
 		settings = append(settings, s)
 	}
 
@@ -126,7 +163,8 @@ func GetSetting(db *sql.DB, category, key string) (*Setting, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get setting %s.%s: %w", category, key, err)
 	}
-	s.UpdatedAt, _ = time.Parse("2006-01-02 15:04:05", updatedAt)
+	s.UpdatedAt = vigildb.ParseTime(updatedAt)
+	s.Default, _ = DefaultValue(s.Category, s.Key)
 	return &s, nil
 }
 
@@ -143,6 +181,18 @@ func GetInt(db *sql.DB, category, key string, fallback int) int {
 	return v
 }
 
+// RetentionDays returns the retention.<key> setting in days, or fallback if
+// it is unset. A negative value defers to system.data_retention_days, so
+// one global setting covers every data type not given its own period.
+// 0 means keep forever.
+func RetentionDays(db *sql.DB, key string, fallback int) int {
+	days := GetInt(db, "retention", key, fallback)
+	if days < 0 {
+		days = GetInt(db, "system", "data_retention_days", 365)
+	}
+	return days
+}
+
 // GetBool returns the boolean value for a setting, or fallback if not found.
 func GetBool(db *sql.DB, category, key string, fallback bool) bool {
 	s, err := GetSetting(db, category, key)
@@ -188,6 +238,50 @@ func UpdateSetting(db *sql.DB, category, key, value string) error {
 	return nil
 }
 
+// UpdateSettings applies several updates at once, keyed by category then
+// key. Every value is validated first; if any is unknown or has the wrong
+// type nothing is written and a ValidationErrors is returned.
+func UpdateSettings(db *sql.DB, updates map[string]map[string]string) error {
+	invalid := ValidationErrors{}
+	for category, values := range updates {
+		for key, value := range values {
+			existing, err := GetSetting(db, category, key)
+			if err != nil {
+				return err
+			}
+			if existing == nil {
+				invalid[category+"."+key] = "unknown setting"
+				continue
+			}
+			if err := validateSettingValue(existing.ValueType, value); err != nil {
+				invalid[category+"."+key] = err.Error()
+			}
+		}
+	}
+	if len(invalid) > 0 {
+		return invalid
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for category, values := range updates {
+		for key, value := range values {
+			if _, err := tx.Exec(`
+				UPDATE settings
+				SET value = ?, updated_at = CURRENT_TIMESTAMP
+				WHERE category = ? AND key = ?
+			`, value, category, key); err != nil {
+				return fmt.Errorf("failed to update setting %s.%s: %w", category, key, err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
 // ResetCategoryToDefaults resets all settings in a category to their default values
 func ResetCategoryToDefaults(db *sql.DB, category string) error {
 	var defaults []Setting
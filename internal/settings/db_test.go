@@ -119,6 +119,10 @@ func TestGetSetting(t *testing.T) {
 		t.Errorf("Expected value type 'int', got '%s'", setting.ValueType)
 	}
 
+	if setting.UpdatedAt.IsZero() {
+		t.Error("Expected updated_at to be parsed")
+	}
+
 	notFound, err := GetSetting(db, "nonexistent", "key")
 	if err != nil {
 		t.Fatalf("GetSetting failed for nonexistent: %v", err)
@@ -176,6 +180,42 @@ func TestUpdateSettingValidation(t *testing.T) {
 	}
 }
 
+func TestUpdateSettings(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// One bad value rejects the whole batch.
+	err := UpdateSettings(db, map[string]map[string]string{
+		"temperature": {"warning_threshold": "50", "critical_threshold": "hot"},
+		"nonexistent": {"key": "value"},
+	})
+	invalid, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+	if len(invalid) != 2 || invalid["temperature.critical_threshold"] == "" || invalid["nonexistent.key"] == "" {
+		t.Errorf("Unexpected validation errors: %v", invalid)
+	}
+	if v, _ := GetIntSetting(db, "temperature", "warning_threshold"); v != 45 {
+		t.Errorf("Expected warning_threshold unchanged at 45 after rejected batch, got %d", v)
+	}
+
+	err = UpdateSettings(db, map[string]map[string]string{
+		"temperature": {"warning_threshold": "50", "critical_threshold": "60"},
+		"alerts":      {"enabled": "false"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateSettings failed: %v", err)
+	}
+	setting, _ := GetSetting(db, "temperature", "critical_threshold")
+	if setting.Value != "60" || setting.Default != "55" {
+		t.Errorf("Expected value 60 with default 55, got %q / %q", setting.Value, setting.Default)
+	}
+	if GetBool(db, "alerts", "enabled", true) {
+		t.Error("Expected alerts.enabled to be false")
+	}
+}
+
 func TestResetCategoryToDefaults(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -287,3 +327,61 @@ func TestGetCategories(t *testing.T) {
 		}
 	}
 }
+
+func TestRetentionDays(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// Unset per-type retention (-1) follows the global setting.
+	if got := RetentionDays(db, "temperature_days", 90); got != 365 {
+		t.Errorf("temperature_days: expected global 365, got %d", got)
+	}
+	if err := UpdateSetting(db, "system", "data_retention_days", "730"); err != nil {
+		t.Fatal(err)
+	}
+	if got := RetentionDays(db, "alert_days", 90); got != 730 {
+		t.Errorf("alert_days: expected global 730, got %d", got)
+	}
+
+	// An explicit value, including 0 (forever), wins over the global.
+	if got := RetentionDays(db, "smart_data_days", 15); got != 90 {
+		t.Errorf("smart_data_days: expected 90, got %d", got)
+	}
+	if err := UpdateSetting(db, "retention", "temperature_days", "0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := RetentionDays(db, "temperature_days", 90); got != 0 {
+		t.Errorf("temperature_days: expected 0, got %d", got)
+	}
+
+	// Missing keys use the caller's fallback.
+	if got := RetentionDays(db, "no_such_days", 42); got != 42 {
+		t.Errorf("missing key: expected fallback 42, got %d", got)
+	}
+}
+
+func TestInitSettingsTableMigratesRenamedSettings(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// A database from before temperature.retention_days was replaced.
+	if _, err := db.Exec(`DELETE FROM settings WHERE category = 'retention' AND key = 'temperature_days'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO settings (category, key, value, value_type) VALUES ('temperature', 'retention_days', '30', 'int')`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := InitSettingsTable(db); err != nil {
+		t.Fatalf("InitSettingsTable: %v", err)
+	}
+	if got := RetentionDays(db, "temperature_days", 90); got != 30 {
+		t.Errorf("temperature_days: expected the migrated 30, got %d", got)
+	}
+	if s, _ := GetSetting(db, "temperature", "retention_days"); s != nil {
+		t.Errorf("old temperature.retention_days still present: %+v", s)
+	}
+	if s, _ := GetSetting(db, "retention", "temperature_days"); s == nil || s.Description == "" {
+		t.Errorf("migrated setting lost its description: %+v", s)
+	}
+}
@@ -13,21 +13,53 @@ var DefaultSettings = []Setting{
 	{Category: "temperature", Key: "critical_threshold", Value: "55", ValueType: "int", Description: "Temperature critical threshold in Celsius"},
 	{Category: "temperature", Key: "spike_threshold", Value: "10", ValueType: "int", Description: "Temperature change considered a spike (degrees)"},
 	{Category: "temperature", Key: "spike_window_minutes", Value: "30", ValueType: "int", Description: "Time window for spike detection in minutes"},
-	{Category: "temperature", Key: "retention_days", Value: "90", ValueType: "int", Description: "Days to keep temperature history"},
+	{Category: "temperature", Key: "alert_mode", Value: "fixed", ValueType: "string", Description: "Warning alerts from fixed thresholds, each drive's learned range, or both (fixed, learned, both)"},
+	{Category: "temperature", Key: "baseline_window_days", Value: "30", ValueType: "int", Description: "Days of history used to learn each drive's normal temperature range"},
+	{Category: "temperature", Key: "emergency_threshold", Value: "65", ValueType: "int", Description: "Hard emergency limit in Celsius: a drive at or above it calls EMERGENCY_WEBHOOK_URL immediately, bypassing notification rules (0 = off)"},
+	{Category: "temperature", Key: "baseline_sigma", Value: "3", ValueType: "float", Description: "Learned range width in standard deviations around the drive's mean"},
+	{Category: "temperature", Key: "min_valid", Value: "5", ValueType: "int", Description: "Lowest plausible drive temperature in Celsius: readings below it are sensor glitches, discarded and logged as ingestion errors"},
+	{Category: "temperature", Key: "max_valid", Value: "100", ValueType: "int", Description: "Highest plausible drive temperature in Celsius: readings above it (e.g. 255) are discarded and logged as ingestion errors"},
+	{Category: "temperature", Key: "sample_interval_hdd", Value: "0", ValueType: "int", Description: "Store at most one HDD temperature reading per this many seconds (e.g. 300), dropping the rest at ingestion; readings near a threshold or spike are always kept (0 = every reading)"},
+	{Category: "temperature", Key: "sample_interval_ssd", Value: "0", ValueType: "int", Description: "Store at most one SATA SSD temperature reading per this many seconds (0 = every reading)"},
+	{Category: "temperature", Key: "sample_interval_nvme", Value: "0", ValueType: "int", Description: "Store at most one NVMe temperature reading per this many seconds (0 = every reading)"},
 
 	// Alert settings
 	{Category: "alerts", Key: "enabled", Value: "true", ValueType: "bool", Description: "Enable temperature alerts"},
-	{Category: "alerts", Key: "cooldown_minutes", Value: "60", ValueType: "int", Description: "Minutes between duplicate alerts for same drive"},
+	{Category: "alerts", Key: "cooldown_minutes", Value: "60", ValueType: "int", Description: "Minutes between duplicate alerts for same drive, when dedup_window_minutes is off"},
+	{Category: "alerts", Key: "escalation_minutes", Value: "0", ValueType: "int", Description: "Re-notify about an unacknowledged critical alert after this many minutes, and again every period until acknowledged (0 = off)"},
+	{Category: "alerts", Key: "escalation_service_id", Value: "0", ValueType: "int", Description: "Notification service to escalate to (0 = every enabled service that notifies on critical)"},
+	{Category: "alerts", Key: "dedup_window_minutes", Value: "0", ValueType: "int", Description: "A warning, critical or spike alert that repeats within this many minutes of the drive's last open alert of the same type bumps that alert's occurrence count instead of adding a new one, and isn't notified again; replaces cooldown_minutes when set (0 = off)"},
 	{Category: "alerts", Key: "recovery_enabled", Value: "true", ValueType: "bool", Description: "Generate recovery alerts when temperature returns to normal"},
 
+	// Notification settings
+	{Category: "notifications", Key: "paused", Value: "false", ValueType: "bool", Description: "Pause every notification on every service; skipped sends are recorded in history as paused"},
+	{Category: "notifications", Key: "paused_until", Value: "0", ValueType: "int", Description: "Unix time at which a global pause ends by itself (0 = until resumed)"},
+	{Category: "notifications", Key: "recovery_confirm_minutes", Value: "30", ValueType: "int", Description: "Minutes a drive or pool must stay healthy after a warning or critical state before a recovery notification is sent to services that notify on healthy (0 = on the first healthy report)"},
+
+	// Dashboard settings: the fleet health on /api/dashboard/overview. Open
+	// alerts and drives over a temperature threshold add their severity's
+	// weight to a score that is compared against the two thresholds.
+	{Category: "dashboard", Key: "count_acknowledged", Value: "false", ValueType: "bool", Description: "Count acknowledged temperature alerts towards fleet health until their drive recovers"},
+	{Category: "dashboard", Key: "warning_weight", Value: "1", ValueType: "int", Description: "Score added by each open warning or spike alert and each drive over the warning threshold"},
+	{Category: "dashboard", Key: "critical_weight", Value: "3", ValueType: "int", Description: "Score added by each open critical alert and each drive over the critical threshold"},
+	{Category: "dashboard", Key: "degraded_score", Value: "1", ValueType: "int", Description: "Score at which fleet health becomes degraded"},
+	{Category: "dashboard", Key: "critical_score", Value: "0", ValueType: "int", Description: "Score at which fleet health becomes critical (0 = only a drive over the critical threshold makes it critical)"},
+	{Category: "dashboard", Key: "aggregate_refresh_seconds", Value: "30", ValueType: "int", Description: "Seconds between background recomputes of the cached fleet aggregates (dashboard overview, temperature and ZFS summaries); each processed report also triggers one"},
+
 	// System settings
-	{Category: "system", Key: "data_retention_days", Value: "365", ValueType: "int", Description: "Days to keep historical data"},
+	{Category: "system", Key: "data_retention_days", Value: "365", ValueType: "int", Description: "Days to keep historical data; used by retention settings set to -1"},
 	{Category: "system", Key: "timezone", Value: "UTC", ValueType: "string", Description: "Display timezone for timestamps"},
+	{Category: "system", Key: "update_check_enabled", Value: "false", ValueType: "bool", Description: "Periodically check for new Vigil server releases (makes outbound requests)"},
+	{Category: "system", Key: "update_check_url", Value: "https://api.github.com/repos/pineappledr/vigil/releases/latest", ValueType: "string", Description: "Latest-release endpoint to query (GitHub releases API response format)"},
 
 	// Retention settings.
-	// For *_days keys: 0 means "keep forever" (no time-based pruning).
+	// For *_days keys: 0 means "keep forever" (no time-based pruning) and -1
+	// falls back to system.data_retention_days (see RetentionDays).
 	{Category: "retention", Key: "notification_history_days", Value: "90", ValueType: "int", Description: "Days to keep notification history (0 = forever)"},
-	{Category: "retention", Key: "smart_data_days", Value: "90", ValueType: "int", Description: "Days to keep SMART attribute and temperature history (0 = forever)"},
+	{Category: "retention", Key: "smart_data_days", Value: "90", ValueType: "int", Description: "Days to keep SMART attribute and latency history (0 = forever, -1 = system default)"},
+	{Category: "retention", Key: "temperature_days", Value: "-1", ValueType: "int", Description: "Days to keep temperature history and spikes (0 = forever, -1 = system default)"},
+	{Category: "retention", Key: "alert_days", Value: "-1", ValueType: "int", Description: "Days to keep temperature alerts (0 = forever, -1 = system default)"},
+	{Category: "retention", Key: "smart_downsample", Value: "true", ValueType: "bool", Description: "Roll SMART attribute and temperature history past its retention up into daily averages instead of deleting it"},
 	{Category: "retention", Key: "report_history_days", Value: "90", ValueType: "int", Description: "Days to keep agent report history (0 = forever)"},
 	{Category: "retention", Key: "audit_log_days", Value: "90", ValueType: "int", Description: "Days to keep audit / activity log entries (0 = forever)"},
 	{Category: "retention", Key: "addon_data_days", Value: "0", ValueType: "int", Description: "Auto-remove add-ons that have been offline this many days, and their notification history (0 = forever)"},
@@ -36,14 +68,37 @@ var DefaultSettings = []Setting{
 
 	// Agent settings
 	{Category: "agents", Key: "report_interval_seconds", Value: "3600", ValueType: "int", Description: "How often agents send reports (seconds). Presets: 60 / 900 / 1800 / 3600 / 43200 / 86400. The online/offline threshold is derived from this."},
+	{Category: "agents", Key: "notify_registration", Value: "true", ValueType: "bool", Description: "Send an Agent Registered notification with the hostname, fingerprint and source IP whenever a new agent enrolls"},
+	{Category: "agents", Key: "require_approval", Value: "false", ValueType: "bool", Description: "Hold newly registered agents as pending: their reports are refused until an admin approves them"},
+	{Category: "agents", Key: "min_report_interval_seconds", Value: "30", ValueType: "int", Description: "Reports from the same host arriving faster than this (seconds) are rejected with 429 (0 = no limit)"},
+
+	// Add-on settings
+	{Category: "addons", Key: "proxy_timeout_seconds", Value: "300", ValueType: "int", Description: "Timeout for requests proxied to add-ons, in seconds. Long because some add-on actions (pool creation, scrubs) legitimately take minutes"},
+	{Category: "addons", Key: "registry_timeout_seconds", Value: "10", ValueType: "int", Description: "Timeout for each container registry request made by the add-on update check, in seconds"},
+	{Category: "addons", Key: "registry_cache_minutes", Value: "5", ValueType: "int", Description: "Minutes to reuse an image's registry tags between update checks, so repeated checks don't get rate-limited (0 = always query)"},
+	{Category: "addons", Key: "sse_max_per_addon", Value: "8", ValueType: "int", Description: "Concurrent telemetry streams allowed per add-on; more are rejected with 429 (0 = no limit)"},
+	{Category: "addons", Key: "sse_max_subscribers", Value: "64", ValueType: "int", Description: "Concurrent telemetry streams allowed across all add-ons; more are rejected with 429 (0 = no limit)"},
+	{Category: "addons", Key: "sse_keepalive_seconds", Value: "15", ValueType: "int", Description: "Seconds between keep-alive comments on idle telemetry streams. Streams that can't take a frame for three intervals are dropped"},
+
+	// Drive settings
+	{Category: "drives", Key: "relocation_window_hours", Value: "168", ValueType: "int", Description: "Hours a drive may be missing from one host and still be linked as relocated when it appears on another"},
+	{Category: "drives", Key: "counter_trend_days", Value: "30", ValueType: "int", Description: "Days over which accumulating error counters (CRC errors, calibration retries) must increase to raise a warning"},
+	{Category: "drives", Key: "attribute_history", Value: "all", ValueType: "string", Description: "History kept for informational SMART attributes (power-on hours, LBAs written/read, vendor counters): all, on_change (only when the value changes) or latest_only. Critical and warning attributes always keep full history"},
+	{Category: "drives", Key: "history_attributes", Value: "9,12", ValueType: "string", Description: "Comma-separated informational attribute IDs that keep full history regardless of attribute_history (default: power-on hours and power cycles)"},
+	{Category: "drives", Key: "power_cycle_jump", Value: "3", ValueType: "int", Description: "Power cycles (attribute 12) gained between two reports that raise a power cycle spike warning (0 = disabled)"},
+	{Category: "drives", Key: "display_name_order", Value: "alias,bay,model,serial,device", ValueType: "string", Description: "Comma-separated order in which a drive's label is chosen everywhere it is shown: alias, bay (enclosure and slot), model, serial, device (by-id path or kernel name). The first one the drive has wins"},
+	{Category: "drives", Key: "unsafe_shutdown_warn", Value: "2", ValueType: "int", Description: "Unsafe shutdowns gained between two reports that escalate the unsafe shutdown event from info to warning (0 = no unsafe shutdown events)"},
 
 	// ZFS settings
 	{Category: "zfs", Key: "capacity_warning_pct", Value: "80", ValueType: "int", Description: "ZFS pool capacity warning threshold (%)"},
 	{Category: "zfs", Key: "capacity_critical_pct", Value: "90", ValueType: "int", Description: "ZFS pool capacity critical threshold (%)"},
 	{Category: "zfs", Key: "fragmentation_warning_pct", Value: "75", ValueType: "int", Description: "ZFS pool fragmentation warning threshold (%)"},
+	{Category: "zfs", Key: "fill_warning_days", Value: "30", ValueType: "int", Description: "Warn when a ZFS pool is projected to be full within this many days (0 = off)"},
+	{Category: "zfs", Key: "fill_projection_days", Value: "14", ValueType: "int", Description: "Days of pool usage history the fill projection is fitted to"},
 	{Category: "zfs", Key: "vdev_error_threshold", Value: "1", ValueType: "int", Description: "Minimum vdev error count to trigger notification"},
 	{Category: "zfs", Key: "scrub_overdue_days", Value: "14", ValueType: "int", Description: "Days since last scrub before triggering overdue alert"},
 	{Category: "zfs", Key: "dataset_quota_warning_pct", Value: "85", ValueType: "int", Description: "Dataset quota usage percentage to trigger warning"},
+	{Category: "zfs", Key: "property_rules", Value: `[{"property":"failmode","warn":["continue"],"message":"failmode=continue keeps the pool serving I/O (with errors) after a catastrophic failure; wait, the default, blocks until the devices return"},{"property":"autotrim","warn":["off"],"ssd_only":true,"message":"autotrim is off on an all-SSD pool; freed blocks are only trimmed by a manual zpool trim"}]`, ValueType: "json", Description: "Pool property values to warn about: [{property, warn: [values], pools: [names, empty = all], ssd_only, message}]"},
 
 	// Backup settings
 	{Category: "backup", Key: "enabled", Value: "true", ValueType: "bool", Description: "Enable scheduled database backups"},
@@ -51,6 +106,16 @@ var DefaultSettings = []Setting{
 	{Category: "backup", Key: "max_backups", Value: "7", ValueType: "int", Description: "Maximum number of backup files to retain"},
 }
 
+// DefaultValue returns the built-in default for a setting.
+func DefaultValue(category, key string) (string, bool) {
+	for _, s := range DefaultSettings {
+		if s.Category == category && s.Key == key {
+			return s.Value, true
+		}
+	}
+	return "", false
+}
+
 // validateSettingValue validates a value against its expected type
 func validateSettingValue(valueType, value string) error {
 	switch valueType {
@@ -0,0 +1,32 @@
+package settings
+
+import "sync"
+
+var (
+	reloadMu   sync.Mutex
+	reloadSubs []chan struct{}
+)
+
+// SubscribeReload returns a channel that receives a value after each
+// NotifyReload. Background workers that only re-read settings on their own
+// schedule select on it to pick up changes immediately. Signals coalesce: a
+// worker that is busy sees at most one pending reload.
+func SubscribeReload() <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	reloadMu.Lock()
+	reloadSubs = append(reloadSubs, ch)
+	reloadMu.Unlock()
+	return ch
+}
+
+// NotifyReload signals every subscriber to re-read its settings.
+func NotifyReload() {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	for _, ch := range reloadSubs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
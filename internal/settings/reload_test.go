@@ -0,0 +1,24 @@
+package settings
+
+import "testing"
+
+func TestNotifyReloadCoalesces(t *testing.T) {
+	a := SubscribeReload()
+	b := SubscribeReload()
+
+	NotifyReload()
+	NotifyReload()
+
+	for name, ch := range map[string]<-chan struct{}{"a": a, "b": b} {
+		select {
+		case <-ch:
+		default:
+			t.Fatalf("subscriber %s not signalled", name)
+		}
+		select {
+		case <-ch:
+			t.Errorf("subscriber %s received more than one pending signal", name)
+		default:
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package settings
 
-import "time"
+import (
+	"sort"
+	"strings"
+	"time"
+)
 
 // Setting represents a configuration setting in the database
 type Setting struct {
@@ -10,6 +14,7 @@ type Setting struct {
 	Value       string    `json:"value"`
 	ValueType   string    `json:"value_type"`
 	Description string    `json:"description,omitempty"`
+	Default     string    `json:"default"` // built-in default value ("" for settings without one)
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
@@ -20,3 +25,19 @@ type SettingUpdate struct {
 
 // SettingsGrouped represents settings grouped by category
 type SettingsGrouped map[string][]Setting
+
+// ValidationErrors maps "category.key" to why its new value was rejected.
+type ValidationErrors map[string]string
+
+func (v ValidationErrors) Error() string {
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + ": " + v[k]
+	}
+	return "invalid settings: " + strings.Join(parts, "; ")
+}
@@ -0,0 +1,105 @@
+package smart
+
+import (
+	"database/sql"
+	"fmt"
+
+	agentsmart "vigil/cmd/agent/smart"
+)
+
+const customRuleColumns = `id, attribute_id, model, field, operator, threshold, severity, description, enabled`
+
+func scanCustomRule(row interface{ Scan(...interface{}) error }) (agentsmart.CustomAttributeRule, error) {
+	var r agentsmart.CustomAttributeRule
+	err := row.Scan(&r.ID, &r.AttributeID, &r.Model, &r.Field, &r.Operator, &r.Threshold, &r.Severity, &r.Description, &r.Enabled)
+	return r, err
+}
+
+// ListCustomRules returns every custom attribute rule, enabled or not.
+func ListCustomRules(db *sql.DB) ([]agentsmart.CustomAttributeRule, error) {
+	rows, err := db.Query(`SELECT ` + customRuleColumns + ` FROM custom_attribute_rules ORDER BY attribute_id, id`)
+	if err != nil {
+		return nil, fmt.Errorf("list custom attribute rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []agentsmart.CustomAttributeRule{}
+	for rows.Next() {
+		r, err := scanCustomRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+// GetCustomRule returns one rule, or nil if it doesn't exist.
+func GetCustomRule(db *sql.DB, id int64) (*agentsmart.CustomAttributeRule, error) {
+	r, err := scanCustomRule(db.QueryRow(`SELECT `+customRuleColumns+` FROM custom_attribute_rules WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get custom attribute rule: %w", err)
+	}
+	return &r, nil
+}
+
+// CreateCustomRule stores a new rule and returns its ID. The rule must
+// already be normalized.
+func CreateCustomRule(db *sql.DB, r *agentsmart.CustomAttributeRule) (int64, error) {
+	res, err := db.Exec(`
+		INSERT INTO custom_attribute_rules (attribute_id, model, field, operator, threshold, severity, description, enabled)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, r.AttributeID, r.Model, r.Field, r.Operator, r.Threshold, r.Severity, r.Description, r.Enabled)
+	if err != nil {
+		return 0, fmt.Errorf("create custom attribute rule: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// UpdateCustomRule replaces a rule. Returns sql.ErrNoRows if it doesn't exist.
+func UpdateCustomRule(db *sql.DB, r *agentsmart.CustomAttributeRule) error {
+	res, err := db.Exec(`
+		UPDATE custom_attribute_rules
+		SET attribute_id = ?, model = ?, field = ?, operator = ?, threshold = ?, severity = ?, description = ?, enabled = ?
+		WHERE id = ?
+	`, r.AttributeID, r.Model, r.Field, r.Operator, r.Threshold, r.Severity, r.Description, r.Enabled, r.ID)
+	if err != nil {
+		return fmt.Errorf("update custom attribute rule: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteCustomRule removes a rule. Returns sql.ErrNoRows if it doesn't exist.
+func DeleteCustomRule(db *sql.DB, id int64) error {
+	res, err := db.Exec(`DELETE FROM custom_attribute_rules WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete custom attribute rule: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// LoadCustomRules hands the enabled rules to the health analysis. Call it at
+// startup and after every change.
+func LoadCustomRules(db *sql.DB) error {
+	rules, err := ListCustomRules(db)
+	if err != nil {
+		return err
+	}
+	enabled := rules[:0]
+	for _, r := range rules {
+		if r.Enabled {
+			enabled = append(enabled, r)
+		}
+	}
+	agentsmart.SetCustomAttributeRules(enabled)
+	return nil
+}
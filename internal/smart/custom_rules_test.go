@@ -0,0 +1,119 @@
+package smart
+
+import (
+	"database/sql"
+	"testing"
+
+	agentsmart "vigil/cmd/agent/smart"
+)
+
+func TestCustomRulesDriveHealthAnalysis(t *testing.T) {
+	db := setupSmartTestDB(t)
+	t.Cleanup(func() { agentsmart.SetCustomAttributeRules(nil) })
+
+	rule := agentsmart.CustomAttributeRule{AttributeID: 173, Model: "mx500", Threshold: 100, Severity: "warning", Enabled: true}
+	if err := rule.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	id, err := CreateCustomRule(db, &rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	disabled := agentsmart.CustomAttributeRule{AttributeID: 173, Field: "raw", Operator: "above", Threshold: 0, Severity: "CRITICAL"}
+	if _, err := CreateCustomRule(db, &disabled); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadCustomRules(db); err != nil {
+		t.Fatal(err)
+	}
+
+	drive := func(model string, raw int64) *agentsmart.DriveSmartData {
+		return &agentsmart.DriveSmartData{
+			ModelName:   model,
+			SmartPassed: true,
+			Attributes:  []agentsmart.SmartAttribute{{ID: 173, Name: "Ave_Block-Erase_Count", Value: 95, RawValue: raw}},
+		}
+	}
+
+	if a := agentsmart.AnalyzeDriveHealth(drive("CT1000MX500SSD1", 150)); a.OverallHealth != agentsmart.SeverityWarning {
+		t.Errorf("matching model above threshold: expected WARNING, got %s", a.OverallHealth)
+	}
+	if a := agentsmart.AnalyzeDriveHealth(drive("CT1000MX500SSD1", 50)); a.OverallHealth != agentsmart.SeverityHealthy {
+		t.Errorf("below threshold: expected HEALTHY, got %s", a.OverallHealth)
+	}
+	if a := agentsmart.AnalyzeDriveHealth(drive("Samsung SSD 870", 150)); a.OverallHealth != agentsmart.SeverityHealthy {
+		t.Errorf("other model (disabled rule must not apply): expected HEALTHY, got %s", a.OverallHealth)
+	}
+	// Without a model only unscoped rules apply.
+	if sev := agentsmart.GetAttributeSeverity(173, 150, 95, 0); sev != agentsmart.SeverityHealthy {
+		t.Errorf("GetAttributeSeverity without model: expected HEALTHY, got %s", sev)
+	}
+
+	if err := DeleteCustomRule(db, id); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteCustomRule(db, id); err != sql.ErrNoRows {
+		t.Errorf("deleting twice: expected sql.ErrNoRows, got %v", err)
+	}
+	if err := LoadCustomRules(db); err != nil {
+		t.Fatal(err)
+	}
+	if a := agentsmart.AnalyzeDriveHealth(drive("CT1000MX500SSD1", 150)); a.OverallHealth != agentsmart.SeverityHealthy {
+		t.Errorf("after delete: expected HEALTHY, got %s", a.OverallHealth)
+	}
+}
+
+func TestCustomRuleNormalize(t *testing.T) {
+	bad := []agentsmart.CustomAttributeRule{
+		{AttributeID: 0, Severity: "WARNING"},
+		{AttributeID: 173, Severity: "LOUD"},
+		{AttributeID: 173, Severity: "WARNING", Operator: "equals"},
+		{AttributeID: 173, Severity: "WARNING", Field: "worst"},
+	}
+	for _, r := range bad {
+		if err := r.Normalize(); err == nil {
+			t.Errorf("expected %+v to be rejected", r)
+		}
+	}
+}
+
+func TestAttributeDefinitions(t *testing.T) {
+	db := setupSmartTestDB(t)
+
+	rules := []agentsmart.CustomAttributeRule{
+		{AttributeID: 5, Threshold: 10, Severity: "WARNING", Enabled: true},
+		{AttributeID: 173, Model: "MX500", Threshold: 100, Severity: "WARNING", Description: "Average block erase count", Enabled: true},
+		{AttributeID: 173, Threshold: 1000, Severity: "CRITICAL"},
+	}
+	for i := range rules {
+		if err := rules[i].Normalize(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := CreateCustomRule(db, &rules[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	defs, err := AttributeDefinitions(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defs) != len(agentsmart.CriticalAttributeDefinitions)+1 {
+		t.Errorf("got %d definitions, want the built-ins plus 173", len(defs))
+	}
+
+	builtin := defs[5]
+	if !builtin.Builtin || builtin.Name != agentsmart.CriticalAttributeDefinitions[5].Name ||
+		builtin.Severity != agentsmart.SeverityCritical || len(builtin.CustomRules) != 1 {
+		t.Errorf("attribute 5 = %+v", builtin)
+	}
+
+	custom := defs[173]
+	if custom == nil || custom.Builtin || len(custom.CustomRules) != 2 {
+		t.Fatalf("attribute 173 = %+v", custom)
+	}
+	// The disabled CRITICAL rule doesn't count towards the severity
+	if custom.Severity != agentsmart.SeverityWarning || custom.Description != "Average block erase count" {
+		t.Errorf("attribute 173 severity = %s, description = %q", custom.Severity, custom.Description)
+	}
+}
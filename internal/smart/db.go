@@ -8,14 +8,25 @@ import (
 	"time"
 
 	agentsmart "vigil/cmd/agent/smart"
+	"vigil/internal/crypto"
+	vigildb "vigil/internal/db"
+	"vigil/internal/settings"
 )
 
-// StoreSmartAttributes saves SMART attributes to the database
+// StoreSmartAttributes saves SMART attributes to the database, keeping as
+// much history per attribute as the configured AttributeHistoryPolicy asks.
 func StoreSmartAttributes(db *sql.DB, driveData *agentsmart.DriveSmartData) error {
+	return storeSmartAttributes(db, driveData, LoadAttributeHistoryPolicy(db))
+}
+
+func storeSmartAttributes(db *sql.DB, driveData *agentsmart.DriveSmartData, policy AttributeHistoryPolicy) error {
 	if driveData == nil || len(driveData.Attributes) == 0 {
 		return nil
 	}
 
+	bounds := LoadTemperatureBounds(db)
+	sampling := LoadTemperatureSampling(db)
+
 	tx, err := db.Begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -24,9 +35,9 @@ func StoreSmartAttributes(db *sql.DB, driveData *agentsmart.DriveSmartData) erro
 
 	stmt, err := tx.Prepare(`
 		INSERT INTO smart_attributes
-		(hostname, serial_number, device_name, attribute_id, attribute_name,
+		(hostname, serial_number, device_name, device_by_id, attribute_id, attribute_name,
 		 value, worst, threshold, raw_value, flags, when_failed, timestamp)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(hostname, serial_number, attribute_id, timestamp) DO UPDATE SET
 			value = excluded.value,
 			worst = excluded.worst,
@@ -42,10 +53,16 @@ func StoreSmartAttributes(db *sql.DB, driveData *agentsmart.DriveSmartData) erro
 	timestamp := driveData.Timestamp.Format("2006-01-02 15:04:05")
 
 	for _, attr := range driveData.Attributes {
+		if !policy.fullHistory(attr) {
+			if err := policy.pruneHistory(tx, driveData, attr, timestamp); err != nil {
+				log.Printf("Warning: Failed to prune history of attribute %d for %s: %v", attr.ID, driveData.SerialNumber, err)
+			}
+		}
 		_, err = stmt.Exec(
 			driveData.Hostname,
 			driveData.SerialNumber,
 			driveData.DeviceName,
+			driveData.DeviceByID,
 			attr.ID,
 			attr.Name,
 			attr.Value,
@@ -61,8 +78,15 @@ func StoreSmartAttributes(db *sql.DB, driveData *agentsmart.DriveSmartData) erro
 		}
 	}
 
-	// Also store temperature history if temperature is available
-	if driveData.Temperature > 0 {
+	// Also store temperature history if temperature is available. Zero means
+	// the drive reported none; anything else outside the bounds is a sensor
+	// glitch, recorded as an ingestion error once the attributes are in.
+	// Readings within the drive's sampling interval are dropped.
+	var badTemp error
+	if driveData.Temperature != 0 && !bounds.Valid(driveData.Temperature) {
+		badTemp = fmt.Errorf("temperature %d°C outside valid range %d–%d°C, discarded",
+			driveData.Temperature, bounds.Min, bounds.Max)
+	} else if driveData.Temperature > 0 && sampleTemperature(tx, sampling, driveData, timestamp) {
 		_, err = tx.Exec(`
 			INSERT INTO temperature_history (hostname, serial_number, temperature, timestamp)
 			VALUES (?, ?, ?, ?)
@@ -74,7 +98,41 @@ func StoreSmartAttributes(db *sql.DB, driveData *agentsmart.DriveSmartData) erro
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if badTemp != nil {
+		log.Printf("Warning: %s (%s): %v", driveData.SerialNumber, driveData.Hostname, badTemp)
+		recordIngestionError(db, newIngestionError(driveData.Hostname, driveData, StageTemperature, badTemp))
+	}
+	return nil
+}
+
+// sampleTemperature reports whether the drive's temperature reading is
+// stored under the sampling policy. Errors are logged and keep the reading.
+func sampleTemperature(tx *sql.Tx, sampling TemperatureSampling, d *agentsmart.DriveSmartData, timestamp string) bool {
+	keep, err := sampling.shouldStore(tx, d, d.Temperature, timestamp)
+	if err != nil {
+		log.Printf("Warning: Failed to check temperature sampling for %s: %v", d.SerialNumber, err)
+	}
+	return keep
+}
+
+// StoreTemperature records a temperature reading on its own, for history
+// that has no SMART attributes to go with it (e.g. imports). Readings
+// outside TemperatureBounds are skipped; it reports whether the reading was
+// stored.
+func StoreTemperature(db *sql.DB, hostname, serialNumber string, temperature int, ts time.Time) (bool, error) {
+	if !LoadTemperatureBounds(db).Valid(temperature) {
+		return false, nil
+	}
+	_, err := db.Exec(`
+		INSERT INTO temperature_history (hostname, serial_number, temperature, timestamp)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(hostname, serial_number, timestamp) DO UPDATE SET
+			temperature = excluded.temperature
+	`, hostname, serialNumber, temperature, ts.UTC().Format("2006-01-02 15:04:05"))
+	return err == nil, err
 }
 
 // GetSmartAttributeHistory retrieves historical data for a specific attribute
@@ -194,10 +252,20 @@ func GetCriticalSmartAttributes(db *sql.DB) ([]agentsmart.CriticalAttribute, err
 
 // GetDriveHealthSummary analyzes SMART attributes and returns health status
 func GetDriveHealthSummary(db *sql.DB, hostname, serialNumber string) (*agentsmart.DriveHealthAnalysis, error) {
-	attributes, err := GetLatestSmartAttributes(db, hostname, serialNumber)
+	driveData, increases, err := latestDriveData(db, hostname, serialNumber)
 	if err != nil {
 		return nil, err
 	}
+	return agentsmart.AnalyzeDriveHealthWithHistory(driveData, increases), nil
+}
+
+// latestDriveData rebuilds a drive's latest stored state for analysis,
+// along with the recent growth of its counters.
+func latestDriveData(db *sql.DB, hostname, serialNumber string) (*agentsmart.DriveSmartData, map[int]int64, error) {
+	attributes, err := GetLatestSmartAttributes(db, hostname, serialNumber)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Build a DriveSmartData object for analysis
 	driveData := &agentsmart.DriveSmartData{
@@ -216,8 +284,8 @@ func GetDriveHealthSummary(db *sql.DB, hostname, serialNumber string) (*agentsma
 		driveData.SmartPassed = driveInfo.SmartPassed
 	}
 
-	// Perform health analysis
-	return agentsmart.AnalyzeDriveHealth(driveData), nil
+	increases := GetCounterIncreases(db, hostname, serialNumber, attributes, CounterTrendDays(db))
+	return driveData, increases, nil
 }
 
 // GetAllDrivesHealthSummary returns health summaries for all monitored drives.
@@ -268,7 +336,7 @@ func GetAllDrivesHealthSummary(db *sql.DB) ([]*agentsmart.DriveHealthAnalysis, e
 		if whenFailed.Valid {
 			attr.WhenFailed = whenFailed.String
 		}
-		attr.Timestamp, _ = time.Parse("2006-01-02 15:04:05", timestampStr)
+		attr.Timestamp = vigildb.ParseTime(timestampStr)
 
 		key := driveKey{hostname, serial}
 		if _, exists := driveAttrs[key]; !exists {
@@ -289,6 +357,9 @@ func GetAllDrivesHealthSummary(db *sql.DB) ([]*agentsmart.DriveHealthAnalysis, e
 
 		var dataJSON []byte
 		err := db.QueryRow(`SELECT data FROM reports WHERE hostname = ? ORDER BY timestamp DESC LIMIT 1`, key.host).Scan(&dataJSON)
+		if err == nil {
+			dataJSON, err = crypto.OpenReport(dataJSON)
+		}
 		if err != nil {
 			continue
 		}
@@ -307,11 +378,7 @@ func GetAllDrivesHealthSummary(db *sql.DB) ([]*agentsmart.DriveHealthAnalysis, e
 				continue
 			}
 			info := &DriveInfo{Hostname: key.host, SerialNumber: serial, SmartPassed: true}
-			if m, ok := dm["model_name"].(string); ok {
-				info.ModelName = m
-			} else if m, ok := dm["model_family"].(string); ok {
-				info.ModelName = m
-			}
+			info.ModelName = agentsmart.ModelName(dm)
 			if status, ok := dm["smart_status"].(map[string]interface{}); ok {
 				if passed, ok := status["passed"].(bool); ok {
 					info.SmartPassed = passed
@@ -332,6 +399,7 @@ func GetAllDrivesHealthSummary(db *sql.DB) ([]*agentsmart.DriveHealthAnalysis, e
 	}
 
 	// Analyse each drive in memory.
+	trendDays := CounterTrendDays(db)
 	var summaries []*agentsmart.DriveHealthAnalysis
 	for _, key := range order {
 		attrs := driveAttrs[key]
@@ -347,7 +415,8 @@ func GetAllDrivesHealthSummary(db *sql.DB) ([]*agentsmart.DriveHealthAnalysis, e
 			driveData.DriveType = info.DriveType
 			driveData.SmartPassed = info.SmartPassed
 		}
-		summaries = append(summaries, agentsmart.AnalyzeDriveHealth(driveData))
+		increases := GetCounterIncreases(db, key.host, key.serial, attrs, trendDays)
+		summaries = append(summaries, agentsmart.AnalyzeDriveHealthWithHistory(driveData, increases))
 	}
 
 	return summaries, nil
@@ -376,10 +445,6 @@ func GetAttributeTrend(db *sql.DB, hostname, serialNumber string, attributeID in
 		DataPoints:  make([]TrendDataPoint, 0),
 	}
 
-	var firstRaw, lastRaw int64
-	var firstVal, lastVal int
-	count := 0
-
 	for rows.Next() {
 		var rawValue int64
 		var value int
@@ -389,43 +454,153 @@ func GetAttributeTrend(db *sql.DB, hostname, serialNumber string, attributeID in
 			continue
 		}
 
-		if count == 0 {
-			firstRaw = rawValue
-			firstVal = value
+		timestamp := vigildb.ParseTime(timestampStr)
+		trend.DataPoints = append(trend.DataPoints, TrendDataPoint{
+			RawValue:  rawValue,
+			Value:     value,
+			Timestamp: timestamp.Unix(),
+		})
+	}
+
+	summarizeTrend(trend)
+	return trend, nil
+}
+
+// GetDriveTrends returns the trend of every critical and warning attribute
+// the drive reported over the last days, ordered by attribute ID, reading
+// smart_attributes once instead of once per attribute.
+func GetDriveTrends(db *sql.DB, hostname, serialNumber string, days int) ([]*AttributeTrend, error) {
+	since := time.Now().AddDate(0, 0, -days).Format("2006-01-02 15:04:05")
+
+	rows, err := db.Query(`
+		SELECT attribute_id, raw_value, value, timestamp
+		FROM smart_attributes
+		WHERE hostname = ? AND serial_number = ? AND timestamp >= ?
+		ORDER BY attribute_id ASC, timestamp ASC
+	`, hostname, serialNumber, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trends := make([]*AttributeTrend, 0)
+	var trend *AttributeTrend
+	for rows.Next() {
+		var attributeID int
+		var rawValue int64
+		var value int
+		var timestampStr string
+
+		if err := rows.Scan(&attributeID, &rawValue, &value, &timestampStr); err != nil {
+			continue
+		}
+		if !agentsmart.IsCriticalAttribute(attributeID) && !agentsmart.IsWarningAttribute(attributeID) {
+			continue
 		}
-		lastRaw = rawValue
-		lastVal = value
 
-		timestamp, _ := time.Parse("2006-01-02 15:04:05", timestampStr)
+		if trend == nil || trend.AttributeID != attributeID {
+			trend = &AttributeTrend{AttributeID: attributeID, DataPoints: make([]TrendDataPoint, 0)}
+			if def, ok := agentsmart.GetAttributeDefinition(attributeID); ok {
+				trend.AttributeName = def.Name
+				trend.Severity = def.Severity
+			}
+			trends = append(trends, trend)
+		}
 		trend.DataPoints = append(trend.DataPoints, TrendDataPoint{
 			RawValue:  rawValue,
 			Value:     value,
-			Timestamp: timestamp.Unix(),
+			Timestamp: vigildb.ParseTime(timestampStr).Unix(),
 		})
-		count++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
+	for _, t := range trends {
+		summarizeTrend(t)
+	}
+	return trends, nil
+}
+
+// summarizeTrend fills in a trend's first/last values, changes and
+// direction from its data points.
+func summarizeTrend(trend *AttributeTrend) {
+	count := len(trend.DataPoints)
 	if count == 0 {
-		return trend, nil
+		return
 	}
+	first, last := trend.DataPoints[0], trend.DataPoints[count-1]
 
-	trend.FirstRawValue = firstRaw
-	trend.LastRawValue = lastRaw
-	trend.FirstValue = firstVal
-	trend.LastValue = lastVal
-	trend.RawChange = lastRaw - firstRaw
-	trend.ValueChange = lastVal - firstVal
+	trend.FirstRawValue = first.RawValue
+	trend.LastRawValue = last.RawValue
+	trend.FirstValue = first.Value
+	trend.LastValue = last.Value
+	trend.RawChange = last.RawValue - first.RawValue
+	trend.ValueChange = last.Value - first.Value
 	trend.PointCount = count
 
 	// Determine trend direction
-	trend.Trend = determineTrend(attributeID, trend.RawChange, trend.ValueChange)
+	trend.Trend = determineTrend(trend.AttributeID, trend.RawChange, trend.ValueChange)
+}
 
-	return trend, nil
+// CounterTrendDays returns the window over which accumulating error counters
+// (CRC errors, calibration retries) are judged.
+func CounterTrendDays(db *sql.DB) int {
+	return settings.GetInt(db, "drives", "counter_trend_days", 30)
+}
+
+// GetCounterIncreases returns how much each non-zero accumulating counter in
+// attrs has grown over the last days. The baseline is the newest sample at or
+// before the window start; when the drive's history is shorter than the
+// window, a baseline is only known if the oldest sample was zero. Counters
+// without a known baseline are omitted so callers fall back to the absolute
+// value.
+func GetCounterIncreases(db *sql.DB, hostname, serialNumber string, attrs []agentsmart.SmartAttribute, days int) map[int]int64 {
+	if days <= 0 {
+		return nil
+	}
+	windowStart := time.Now().AddDate(0, 0, -days).Format("2006-01-02 15:04:05")
+
+	increases := make(map[int]int64)
+	for _, attr := range attrs {
+		if !agentsmart.AccumulatingCounters[attr.ID] || attr.RawValue <= 0 {
+			continue
+		}
+
+		var baseline int64
+		err := db.QueryRow(`
+			SELECT raw_value FROM smart_attributes
+			WHERE hostname = ? AND serial_number = ? AND attribute_id = ? AND timestamp <= ?
+			ORDER BY timestamp DESC LIMIT 1
+		`, hostname, serialNumber, attr.ID, windowStart).Scan(&baseline)
+		if err == sql.ErrNoRows {
+			err = db.QueryRow(`
+				SELECT raw_value FROM smart_attributes
+				WHERE hostname = ? AND serial_number = ? AND attribute_id = ?
+				ORDER BY timestamp ASC LIMIT 1
+			`, hostname, serialNumber, attr.ID).Scan(&baseline)
+			if err == nil && baseline != 0 {
+				continue // errors predate our history; can't tell when they happened
+			}
+		}
+		if err != nil {
+			continue
+		}
+
+		inc := attr.RawValue - baseline
+		if inc < 0 {
+			inc = 0 // counter was reset (e.g. drive firmware update)
+		}
+		increases[attr.ID] = inc
+	}
+	return increases
 }
 
 // AttributeTrend represents trend analysis data
 type AttributeTrend struct {
 	AttributeID   int              `json:"attribute_id"`
+	AttributeName string           `json:"attribute_name,omitempty"` // GetDriveTrends only
+	Severity      string           `json:"severity,omitempty"`       // GetDriveTrends only
 	DataPoints    []TrendDataPoint `json:"data_points"`
 	FirstRawValue int64            `json:"first_raw_value"`
 	LastRawValue  int64            `json:"last_raw_value"`
@@ -528,7 +703,7 @@ func GetTemperatureHistory(db *sql.DB, hostname, serialNumber string, hours int)
 			continue
 		}
 
-		timestamp, _ := time.Parse("2006-01-02 15:04:05", timestampStr)
+		timestamp := vigildb.ParseTime(timestampStr)
 		records = append(records, TemperatureRecord{
 			Temperature: temp,
 			Timestamp:   timestamp,
@@ -544,29 +719,41 @@ type TemperatureRecord struct {
 	Timestamp   time.Time `json:"timestamp"`
 }
 
-// CleanupOldSmartData removes SMART data older than specified days.
+// CleanupOldSmartData removes SMART attribute and temperature history older
+// than the specified days.
 // A daysToKeep value of 0 or less is a no-op ("keep forever").
 func CleanupOldSmartData(db *sql.DB, daysToKeep int) (int64, error) {
+	smartDeleted, err := CleanupOldSmartAttributes(db, daysToKeep)
+	if err != nil {
+		return 0, err
+	}
+	tempDeleted, err := CleanupOldTemperatureHistory(db, daysToKeep)
+	return smartDeleted + tempDeleted, err
+}
+
+// CleanupOldSmartAttributes removes smart_attributes rows older than the
+// specified days. A daysToKeep value of 0 or less is a no-op.
+func CleanupOldSmartAttributes(db *sql.DB, daysToKeep int) (int64, error) {
+	return deleteOlderThan(db, "smart_attributes", daysToKeep)
+}
+
+// CleanupOldTemperatureHistory removes temperature_history rows older than
+// the specified days. A daysToKeep value of 0 or less is a no-op.
+func CleanupOldTemperatureHistory(db *sql.DB, daysToKeep int) (int64, error) {
+	return deleteOlderThan(db, "temperature_history", daysToKeep)
+}
+
+func deleteOlderThan(db *sql.DB, table string, daysToKeep int) (int64, error) {
 	if daysToKeep <= 0 {
 		return 0, nil
 	}
 	cutoffDate := time.Now().AddDate(0, 0, -daysToKeep).Format("2006-01-02 15:04:05")
 
-	// Clean up smart_attributes
-	result, err := db.Exec(`DELETE FROM smart_attributes WHERE timestamp < ?`, cutoffDate)
+	result, err := db.Exec(`DELETE FROM `+table+` WHERE timestamp < ?`, cutoffDate)
 	if err != nil {
 		return 0, err
 	}
-	smartDeleted, _ := result.RowsAffected()
-
-	// Clean up temperature_history
-	result, err = db.Exec(`DELETE FROM temperature_history WHERE timestamp < ?`, cutoffDate)
-	if err != nil {
-		return smartDeleted, err
-	}
-	tempDeleted, _ := result.RowsAffected()
-
-	return smartDeleted + tempDeleted, nil
+	return result.RowsAffected()
 }
 
 // DriveInfo holds basic drive information
@@ -589,6 +776,9 @@ func GetDriveInfo(db *sql.DB, hostname, serialNumber string) (*DriveInfo, error)
 
 	var dataJSON []byte
 	err := db.QueryRow(query, hostname).Scan(&dataJSON)
+	if err == nil {
+		dataJSON, err = crypto.OpenReport(dataJSON)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -620,12 +810,7 @@ func GetDriveInfo(db *sql.DB, hostname, serialNumber string) (*DriveInfo, error)
 			SmartPassed:  true,
 		}
 
-		// Model name
-		if model, ok := drive["model_name"].(string); ok {
-			info.ModelName = model
-		} else if model, ok := drive["model_family"].(string); ok {
-			info.ModelName = model
-		}
+		info.ModelName = agentsmart.ModelName(drive)
 
 		// SMART status
 		if smartStatus, ok := drive["smart_status"].(map[string]interface{}); ok {
@@ -635,7 +820,7 @@ func GetDriveInfo(db *sql.DB, hostname, serialNumber string) (*DriveInfo, error)
 		}
 
 		// Drive type
-		info.DriveType = determineDriveTypeFromReport(drive)
+		info.DriveType = DriveTypeFromReport(drive)
 
 		return info, nil
 	}
@@ -643,8 +828,8 @@ func GetDriveInfo(db *sql.DB, hostname, serialNumber string) (*DriveInfo, error)
 	return nil, fmt.Errorf("drive not found in report")
 }
 
-// determineDriveTypeFromReport determines drive type from report data
-func determineDriveTypeFromReport(drive map[string]interface{}) string {
+// DriveTypeFromReport determines drive type from a report's drive entry
+func DriveTypeFromReport(drive map[string]interface{}) string {
 	// Check for NVMe
 	if device, ok := drive["device"].(map[string]interface{}); ok {
 		if protocol, ok := device["protocol"].(string); ok && protocol == "NVMe" {
@@ -666,43 +851,10 @@ func determineDriveTypeFromReport(drive map[string]interface{}) string {
 	return agentsmart.DriveTypeHDD // Default
 }
 
-// ProcessReportForSmartStorage extracts and stores SMART data from incoming report
+// ProcessReportForSmartStorage extracts and stores SMART data from incoming
+// report, without publishing events.
 func ProcessReportForSmartStorage(db *sql.DB, hostname string, reportData map[string]interface{}) error {
-	drives, ok := reportData["drives"].([]interface{})
-	if !ok {
-		return nil // No drives in report
-	}
-
-	var lastErr error
-	for _, driveInterface := range drives {
-		driveMap, ok := driveInterface.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		// Parse SMART data for this drive
-		driveData, err := agentsmart.ParseSmartAttributes(driveMap, hostname)
-		if err != nil {
-			log.Printf("Warning: Failed to parse SMART data for drive: %v", err)
-			lastErr = err
-			continue
-		}
-
-		// Skip if no serial number (can't identify the drive)
-		if driveData.SerialNumber == "" {
-			continue
-		}
-
-		// Store the SMART attributes
-		if len(driveData.Attributes) > 0 {
-			if err := StoreSmartAttributes(db, driveData); err != nil {
-				log.Printf("Warning: Failed to store SMART attributes for %s: %v", driveData.SerialNumber, err)
-				lastErr = err
-			}
-		}
-	}
-
-	return lastErr
+	return ProcessReportWithEvents(db, nil, hostname, reportData)
 }
 
 // ─── Helper Functions ────────────────────────────────────────────────────────
@@ -735,7 +887,7 @@ func scanSmartAttributes(rows *sql.Rows) ([]agentsmart.SmartAttribute, error) {
 			attr.WhenFailed = whenFailed.String
 		}
 
-		attr.Timestamp, _ = time.Parse("2006-01-02 15:04:05", timestampStr)
+		attr.Timestamp = vigildb.ParseTime(timestampStr)
 		attributes = append(attributes, attr)
 	}
 
@@ -0,0 +1,137 @@
+package smart
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	agentsmart "vigil/cmd/agent/smart"
+
+	_ "modernc.org/sqlite"
+)
+
+func setupSmartTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	if err := MigrateSmartAttributes(db); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func storeCRC(t *testing.T, db *sql.DB, serial string, raw int64, ago time.Duration) {
+	t.Helper()
+	err := StoreSmartAttributes(db, &agentsmart.DriveSmartData{
+		Hostname:     "nas01",
+		SerialNumber: serial,
+		DeviceName:   "/dev/sda",
+		Timestamp:    time.Now().Add(-ago),
+		Attributes: []agentsmart.SmartAttribute{
+			{ID: 199, Name: "UDMA_CRC_Error_Count", Value: 200, RawValue: raw},
+		},
+	})
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+}
+
+func TestCounterIncreasesDriveSeverity(t *testing.T) {
+	db := setupSmartTestDB(t)
+	day := 24 * time.Hour
+
+	// STALE: 50 errors from an old cable glitch, unchanged for months.
+	storeCRC(t, db, "STALE", 50, 90*day)
+	storeCRC(t, db, "STALE", 50, time.Hour)
+	// ACTIVE: still climbing inside the window.
+	storeCRC(t, db, "ACTIVE", 50, 90*day)
+	storeCRC(t, db, "ACTIVE", 62, time.Hour)
+	// NEW: only recent history that already started non-zero.
+	storeCRC(t, db, "NEW", 50, day)
+	storeCRC(t, db, "NEW", 50, time.Hour)
+
+	tests := []struct {
+		serial   string
+		known    bool
+		increase int64
+		health   string
+	}{
+		{"STALE", true, 0, agentsmart.SeverityHealthy},
+		{"ACTIVE", true, 12, agentsmart.SeverityWarning},
+		{"NEW", false, 0, agentsmart.SeverityWarning},
+	}
+
+	for _, tt := range tests {
+		summary, err := GetDriveHealthSummary(db, "nas01", tt.serial)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.serial, err)
+		}
+		attrs, _ := GetLatestSmartAttributes(db, "nas01", tt.serial)
+		inc, ok := GetCounterIncreases(db, "nas01", tt.serial, attrs, 30)[199]
+		if ok != tt.known || inc != tt.increase {
+			t.Errorf("%s: increase = %d (known %v), want %d (known %v)", tt.serial, inc, ok, tt.increase, tt.known)
+		}
+		if summary.OverallHealth != tt.health {
+			t.Errorf("%s: health = %s, want %s", tt.serial, summary.OverallHealth, tt.health)
+		}
+	}
+}
+
+func TestGetDriveTrends(t *testing.T) {
+	db := setupSmartTestDB(t)
+	day := 24 * time.Hour
+
+	store := func(ago time.Duration, realloc, crc int64) {
+		t.Helper()
+		err := StoreSmartAttributes(db, &agentsmart.DriveSmartData{
+			Hostname:     "nas01",
+			SerialNumber: "SN1",
+			DeviceName:   "/dev/sda",
+			Timestamp:    time.Now().Add(-ago),
+			Attributes: []agentsmart.SmartAttribute{
+				{ID: 5, Name: "Reallocated_Sector_Ct", Value: 100, RawValue: realloc},
+				{ID: 9, Name: "Power_On_Hours", Value: 99, RawValue: 1000},
+				{ID: 199, Name: "UDMA_CRC_Error_Count", Value: 200, RawValue: crc},
+			},
+		})
+		if err != nil {
+			t.Fatalf("store: %v", err)
+		}
+	}
+	store(60*day, 0, 0) // outside the window
+	store(20*day, 2, 3)
+	store(10*day, 8, 3)
+	store(time.Hour, 8, 3)
+
+	trends, err := GetDriveTrends(db, "nas01", "SN1", 30)
+	if err != nil {
+		t.Fatalf("GetDriveTrends: %v", err)
+	}
+	if len(trends) != 2 {
+		t.Fatalf("got %d trends, want 2 (power-on hours is informational)", len(trends))
+	}
+
+	realloc, crc := trends[0], trends[1]
+	if realloc.AttributeID != 5 || realloc.Severity != agentsmart.SeverityCritical || realloc.AttributeName == "" {
+		t.Errorf("first trend = %+v, want attribute 5 (critical)", realloc)
+	}
+	if realloc.PointCount != 3 || realloc.RawChange != 6 || realloc.Trend != "degrading" {
+		t.Errorf("reallocated: points=%d change=%d trend=%s", realloc.PointCount, realloc.RawChange, realloc.Trend)
+	}
+	if crc.AttributeID != 199 || crc.Severity != agentsmart.SeverityWarning || crc.Trend != "stable" {
+		t.Errorf("crc trend = %+v", crc)
+	}
+
+	// Matches the per-attribute trend
+	single, err := GetAttributeTrend(db, "nas01", "SN1", 5, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if single.PointCount != realloc.PointCount || single.RawChange != realloc.RawChange || single.Trend != realloc.Trend {
+		t.Errorf("GetAttributeTrend = %+v, GetDriveTrends = %+v", single, realloc)
+	}
+}
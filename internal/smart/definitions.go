@@ -0,0 +1,57 @@
+package smart
+
+import (
+	"database/sql"
+
+	agentsmart "vigil/cmd/agent/smart"
+)
+
+// AttributeDefinition describes how Vigil judges one SMART attribute: its
+// built-in definition, if it has one, and the custom rules that watch it.
+type AttributeDefinition struct {
+	agentsmart.CriticalAttribute
+	Builtin     bool                             `json:"builtin"`
+	CustomRules []agentsmart.CustomAttributeRule `json:"custom_rules"`
+}
+
+// AttributeDefinitions returns every attribute Vigil monitors, keyed by ID:
+// the built-in CriticalAttributeDefinitions plus any attribute only custom
+// rules watch. Disabled custom rules are included with enabled=false. An
+// attribute known only from custom rules takes its description from the
+// first rule that has one and its severity from the worst enabled rule.
+func AttributeDefinitions(db *sql.DB) (map[int]*AttributeDefinition, error) {
+	defs := make(map[int]*AttributeDefinition, len(agentsmart.CriticalAttributeDefinitions))
+	for id, attr := range agentsmart.CriticalAttributeDefinitions {
+		defs[id] = &AttributeDefinition{
+			CriticalAttribute: attr,
+			Builtin:           true,
+			CustomRules:       []agentsmart.CustomAttributeRule{},
+		}
+	}
+
+	rules, err := ListCustomRules(db)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		def, ok := defs[r.AttributeID]
+		if !ok {
+			def = &AttributeDefinition{
+				CriticalAttribute: agentsmart.CriticalAttribute{ID: r.AttributeID},
+				CustomRules:       []agentsmart.CustomAttributeRule{},
+			}
+			defs[r.AttributeID] = def
+		}
+		def.CustomRules = append(def.CustomRules, r)
+		if def.Builtin {
+			continue
+		}
+		if def.Description == "" {
+			def.Description = r.Description
+		}
+		if r.Enabled {
+			def.Severity = agentsmart.WorseSeverity(def.Severity, r.Severity)
+		}
+	}
+	return defs, nil
+}
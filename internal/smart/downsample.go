@@ -0,0 +1,143 @@
+package smart
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DownsampleOldData rolls SMART attribute samples older than smartDays and
+// temperature samples older than temperatureDays up into one row per drive
+// (and attribute) per day in smart_attributes_daily / temperature_daily,
+// then deletes the raw rows. Long-term trends survive at a fraction of the
+// row count; a raw poll every minute is ~1440 rows per attribute per day,
+// the rollup is one.
+//
+// Each cutoff is aligned to the start of a day so only whole days are rolled
+// up. If a day is rolled up twice (e.g. late-arriving samples), the new
+// samples are merged into the existing row as a weighted average.
+//
+// The work is done one day at a time, each day in its own transaction passed
+// through write (nil runs it directly), so the server can hand the write lock
+// to report ingestion between days instead of holding it for the whole
+// backlog. Returns the number of raw rows removed. A period <= 0 leaves that
+// data type alone.
+func DownsampleOldData(db *sql.DB, smartDays, temperatureDays int, write func(func() error) error) (int64, error) {
+	if write == nil {
+		write = func(fn func() error) error { return fn() }
+	}
+	var deleted int64
+	if temperatureDays > 0 {
+		n, err := downsampleByDay(db, "temperature_history", dayCutoff(temperatureDays), rollupTemperatureDay, write)
+		deleted += n
+		if err != nil {
+			return deleted, fmt.Errorf("roll up temperature_history: %w", err)
+		}
+	}
+	if smartDays > 0 {
+		n, err := downsampleByDay(db, "smart_attributes", dayCutoff(smartDays), rollupSmartDay, write)
+		deleted += n
+		if err != nil {
+			return deleted, fmt.Errorf("roll up smart_attributes: %w", err)
+		}
+	}
+	return deleted, nil
+}
+
+// downsampleByDay rolls table up one day at a time, oldest first, until no
+// raw rows before cutoff remain. The oldest day is looked up outside the
+// write so a reader-only pass costs ingestion nothing.
+func downsampleByDay(db *sql.DB, table, cutoff string, rollup func(tx *sql.Tx, from, to string) error,
+	write func(func() error) error) (int64, error) {
+	var deleted int64
+	for {
+		var n int64
+		var oldest sql.NullString
+		if err := db.QueryRow(`SELECT substr(MIN(timestamp), 1, 10) FROM `+table+` WHERE timestamp < ?`, cutoff).Scan(&oldest); err != nil {
+			return deleted, err
+		}
+		if !oldest.Valid || oldest.String == "" {
+			return deleted, nil
+		}
+		day, err := time.Parse("2006-01-02", oldest.String)
+		if err != nil {
+			return deleted, fmt.Errorf("parse day %q: %w", oldest.String, err)
+		}
+		from := day.Format("2006-01-02")
+		to := day.AddDate(0, 0, 1).Format("2006-01-02")
+		if to > cutoff {
+			to = cutoff
+		}
+
+		err = write(func() error {
+			tx, err := db.Begin()
+			if err != nil {
+				return fmt.Errorf("failed to begin transaction: %w", err)
+			}
+			defer tx.Rollback()
+
+			if err := rollup(tx, from, to); err != nil {
+				return err
+			}
+			result, err := tx.Exec(`DELETE FROM `+table+` WHERE timestamp >= ? AND timestamp < ?`, from, to)
+			if err != nil {
+				return err
+			}
+			n, _ = result.RowsAffected()
+			if err := tx.Commit(); err != nil {
+				return fmt.Errorf("failed to commit downsample: %w", err)
+			}
+			deleted += n
+			return nil
+		})
+		if err != nil {
+			return deleted, err
+		}
+		if n == 0 {
+			return deleted, nil // nothing matched the day's range; don't spin on it
+		}
+	}
+}
+
+func rollupTemperatureDay(tx *sql.Tx, from, to string) error {
+	_, err := tx.Exec(`
+		INSERT INTO temperature_daily
+			(hostname, serial_number, day, avg_temp, min_temp, max_temp, samples)
+		SELECT hostname, serial_number, date(timestamp),
+		       AVG(temperature), MIN(temperature), MAX(temperature), COUNT(*)
+		FROM temperature_history
+		WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY hostname, serial_number, date(timestamp)
+		ON CONFLICT(hostname, serial_number, day) DO UPDATE SET
+			avg_temp = (avg_temp * samples + excluded.avg_temp * excluded.samples) / (samples + excluded.samples),
+			min_temp = MIN(min_temp, excluded.min_temp),
+			max_temp = MAX(max_temp, excluded.max_temp),
+			samples  = samples + excluded.samples
+	`, from, to)
+	return err
+}
+
+func rollupSmartDay(tx *sql.Tx, from, to string) error {
+	_, err := tx.Exec(`
+		INSERT INTO smart_attributes_daily
+			(hostname, serial_number, attribute_id, attribute_name, day,
+			 avg_value, min_worst, avg_raw_value, max_raw_value, samples)
+		SELECT hostname, serial_number, attribute_id, MAX(attribute_name), date(timestamp),
+		       AVG(value), MIN(worst), AVG(raw_value), MAX(raw_value), COUNT(*)
+		FROM smart_attributes
+		WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY hostname, serial_number, attribute_id, date(timestamp)
+		ON CONFLICT(hostname, serial_number, attribute_id, day) DO UPDATE SET
+			avg_value     = (avg_value * samples + excluded.avg_value * excluded.samples) / (samples + excluded.samples),
+			min_worst     = MIN(min_worst, excluded.min_worst),
+			avg_raw_value = (avg_raw_value * samples + excluded.avg_raw_value * excluded.samples) / (samples + excluded.samples),
+			max_raw_value = MAX(max_raw_value, excluded.max_raw_value),
+			samples       = samples + excluded.samples
+	`, from, to)
+	return err
+}
+
+// dayCutoff is the start of the day days ago, as stored in timestamp columns.
+func dayCutoff(days int) string {
+	return time.Now().AddDate(0, 0, -days).Format("2006-01-02")
+}
@@ -0,0 +1,125 @@
+package smart
+
+import (
+	"testing"
+	"time"
+
+	agentsmart "vigil/cmd/agent/smart"
+)
+
+func TestDownsampleOldData(t *testing.T) {
+	db := setupSmartTestDB(t)
+
+	old := time.Now().AddDate(0, 0, -40)
+	old = time.Date(old.Year(), old.Month(), old.Day(), 10, 0, 0, 0, old.Location())
+	store := func(ts time.Time, temp int, raw int64) {
+		t.Helper()
+		err := StoreSmartAttributes(db, &agentsmart.DriveSmartData{
+			Hostname:     "nas01",
+			SerialNumber: "SER1",
+			DeviceName:   "/dev/sda",
+			Timestamp:    ts,
+			Temperature:  temp,
+			Attributes: []agentsmart.SmartAttribute{
+				{ID: 5, Name: "Reallocated_Sector_Ct", Value: 100, Worst: 100, RawValue: raw},
+			},
+		})
+		if err != nil {
+			t.Fatalf("store: %v", err)
+		}
+	}
+	store(old, 30, 0)
+	store(old.Add(time.Hour), 40, 4)
+	store(old.AddDate(0, 0, 1), 33, 4)
+	store(time.Now().Add(-time.Hour), 35, 4)
+
+	writes := 0
+	deleted, err := DownsampleOldData(db, 30, 30, func(fn func() error) error {
+		writes++
+		return fn()
+	})
+	if err != nil {
+		t.Fatalf("DownsampleOldData: %v", err)
+	}
+	if deleted != 6 {
+		t.Errorf("expected 6 raw rows removed (3 attribute + 3 temperature), got %d", deleted)
+	}
+	if writes != 4 {
+		t.Errorf("expected one write per table per day (4), got %d", writes)
+	}
+
+	var avg float64
+	var minT, maxT, samples int
+	err = db.QueryRow(`SELECT avg_temp, min_temp, max_temp, samples FROM temperature_daily
+		WHERE hostname = 'nas01' AND serial_number = 'SER1' AND day = ?`, old.Format("2006-01-02")).
+		Scan(&avg, &minT, &maxT, &samples)
+	if err != nil {
+		t.Fatalf("read temperature_daily: %v", err)
+	}
+	if avg != 35 || minT != 30 || maxT != 40 || samples != 2 {
+		t.Errorf("unexpected temperature rollup: avg=%v min=%d max=%d samples=%d", avg, minT, maxT, samples)
+	}
+
+	var avgRaw float64
+	var maxRaw int64
+	err = db.QueryRow(`SELECT avg_raw_value, max_raw_value FROM smart_attributes_daily
+		WHERE serial_number = 'SER1' AND attribute_id = 5`).Scan(&avgRaw, &maxRaw)
+	if err != nil {
+		t.Fatalf("read smart_attributes_daily: %v", err)
+	}
+	if avgRaw != 2 || maxRaw != 4 {
+		t.Errorf("unexpected attribute rollup: avg_raw=%v max_raw=%d", avgRaw, maxRaw)
+	}
+
+	var recent int
+	db.QueryRow(`SELECT COUNT(*) FROM temperature_history`).Scan(&recent)
+	if recent != 1 {
+		t.Errorf("recent raw temperature should be kept, got %d rows", recent)
+	}
+
+	// A late sample for an already rolled-up day is merged, not duplicated.
+	store(old.Add(2*time.Hour), 50, 4)
+	if _, err := DownsampleOldData(db, 30, 30, nil); err != nil {
+		t.Fatalf("second DownsampleOldData: %v", err)
+	}
+	db.QueryRow(`SELECT avg_temp, max_temp, samples FROM temperature_daily WHERE day = ?`, old.Format("2006-01-02")).
+		Scan(&avg, &maxT, &samples)
+	if samples != 3 || maxT != 50 || avg < 39.99 || avg > 40.01 {
+		t.Errorf("expected merged rollup avg=40 max=50 samples=3, got avg=%v max=%d samples=%d", avg, maxT, samples)
+	}
+}
+
+func TestDownsampleOldData_SeparateRetention(t *testing.T) {
+	db := setupSmartTestDB(t)
+
+	old := time.Now().AddDate(0, 0, -100)
+	err := StoreSmartAttributes(db, &agentsmart.DriveSmartData{
+		Hostname:     "nas01",
+		SerialNumber: "SER1",
+		DeviceName:   "/dev/sda",
+		Timestamp:    old,
+		Temperature:  30,
+		Attributes: []agentsmart.SmartAttribute{
+			{ID: 5, Name: "Reallocated_Sector_Ct", Value: 100, Worst: 100, RawValue: 0},
+		},
+	})
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+
+	// SMART past its 90 days, temperature kept for two years.
+	deleted, err := DownsampleOldData(db, 90, 730, nil)
+	if err != nil {
+		t.Fatalf("DownsampleOldData: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected only the attribute row removed, got %d", deleted)
+	}
+
+	var attrs, temps int
+	db.QueryRow(`SELECT COUNT(*) FROM smart_attributes`).Scan(&attrs)
+	db.QueryRow(`SELECT COUNT(*) FROM temperature_history`).Scan(&temps)
+	if attrs != 0 || temps != 1 {
+		t.Errorf("expected 0 raw attribute and 1 raw temperature rows, got %d and %d", attrs, temps)
+	}
+}
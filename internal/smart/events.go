@@ -4,58 +4,152 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"time"
 
 	agentsmart "vigil/cmd/agent/smart"
+	"vigil/internal/drivealerts"
 	"vigil/internal/events"
+	"vigil/internal/recovery"
 )
 
 // ProcessReportWithEvents extracts SMART data from an incoming report, stores
 // it, and publishes events for any drives with health warnings or failures.
+// Drives that can't be parsed or stored are recorded in ingestion_errors.
 func ProcessReportWithEvents(db *sql.DB, bus *events.Bus, hostname string, reportData map[string]interface{}) error {
+	return ProcessReportAt(db, bus, hostname, reportData, time.Time{})
+}
+
+// ProcessReportAt is ProcessReportWithEvents for a report collected at the
+// given time, e.g. one imported after the fact: its drives' attributes,
+// temperatures and health changes are recorded at that time instead of
+// now. A zero time means now.
+func ProcessReportAt(db *sql.DB, bus *events.Bus, hostname string, reportData map[string]interface{}, collectedAt time.Time) error {
 	drives, ok := reportData["drives"].([]interface{})
 	if !ok {
 		return nil
 	}
 
 	var lastErr error
-	for _, driveInterface := range drives {
-		driveMap, ok := driveInterface.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		driveData, err := agentsmart.ParseSmartAttributes(driveMap, hostname)
+	for _, entry := range drives {
+		driveData, err := parseReportDrive(entry, hostname)
 		if err != nil {
-			log.Printf("Warning: Failed to parse SMART data for drive: %v", err)
+			log.Printf("Warning: Failed to parse SMART data for a drive from %s: %v", hostname, err)
+			recordIngestionError(db, newIngestionError(hostname, driveData, StageParse, err))
 			lastErr = err
 			continue
 		}
+		if !collectedAt.IsZero() {
+			driveData.Timestamp = collectedAt.UTC()
+		}
 
-		if driveData.SerialNumber == "" {
-			continue
+		// Read the power counters before storing: the history policy may
+		// prune the previous sample.
+		var prevPower map[int]int64
+		if bus != nil {
+			prevPower = previousPowerCounters(db, hostname, driveData.SerialNumber)
 		}
 
 		// Store attributes
 		if len(driveData.Attributes) > 0 {
 			if err := StoreSmartAttributes(db, driveData); err != nil {
 				log.Printf("Warning: Failed to store SMART attributes for %s: %v", driveData.SerialNumber, err)
+				recordIngestionError(db, newIngestionError(hostname, driveData, StageStore, err))
 				lastErr = err
 			}
 		}
 
-		// Publish health events
-		if bus != nil {
-			publishSmartHealthEvents(bus, driveData)
+		increases := GetCounterIncreases(db, hostname, driveData.SerialNumber, driveData.Attributes, CounterTrendDays(db))
+		analysis := agentsmart.AnalyzeDriveHealthWithHistory(driveData, increases)
+		if _, err := RecordHealthStatus(db, driveData, analysis); err != nil {
+			log.Printf("Warning: Failed to record health status for %s: %v", driveData.SerialNumber, err)
+		}
+
+		// Publish health events, unless alerts are off for the drive
+		if bus != nil && drivealerts.Enabled(db, hostname, driveData.SerialNumber) {
+			publishHealthAnalysis(bus, driveData, analysis)
+			recovery.Observe(db, bus, recovery.KindDrive, hostname, driveData.SerialNumber, healthState(analysis))
+			publishPowerEvents(bus, db, driveData, prevPower)
 		}
 	}
 
 	return lastErr
 }
 
-// publishSmartHealthEvents analyzes a drive's SMART data and publishes events
-// for any warnings or critical issues detected.
-func publishSmartHealthEvents(bus *events.Bus, driveData *agentsmart.DriveSmartData) {
-	analysis := agentsmart.AnalyzeDriveHealth(driveData)
+// HealthReevaluation summarizes a health analysis pass over stored SMART data
+type HealthReevaluation struct {
+	DrivesAnalyzed int `json:"drives_analyzed"`
+	Critical       int `json:"critical"`
+	Warning        int `json:"warning"`
+}
+
+// ReevaluateHealth re-runs the health analysis on every drive's latest
+// stored attributes, against the current custom rules and trend window, and
+// publishes the resulting events of drives with alerts on to bus (nil for a
+// dry count).
+func ReevaluateHealth(db *sql.DB, bus *events.Bus) (*HealthReevaluation, error) {
+	rows, err := db.Query(`SELECT DISTINCT hostname, serial_number FROM smart_attributes ORDER BY hostname, serial_number`)
+	if err != nil {
+		return nil, fmt.Errorf("list drives: %w", err)
+	}
+	type drive struct{ hostname, serial string }
+	var drives []drive
+	for rows.Next() {
+		var d drive
+		if err := rows.Scan(&d.hostname, &d.serial); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		drives = append(drives, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &HealthReevaluation{}
+	for _, d := range drives {
+		driveData, increases, err := latestDriveData(db, d.hostname, d.serial)
+		if err != nil {
+			return result, err
+		}
+		analysis := agentsmart.AnalyzeDriveHealthWithHistory(driveData, increases)
+		result.DrivesAnalyzed++
+		switch {
+		case analysis.CriticalCount > 0:
+			result.Critical++
+		case analysis.WarningCount > 0:
+			result.Warning++
+		}
+		if bus != nil && drivealerts.Enabled(db, d.hostname, d.serial) {
+			publishHealthAnalysis(bus, driveData, analysis)
+		}
+	}
+	return result, nil
+}
+
+// recordIngestionError stores e, logging rather than returning a failure:
+// ingestion goes on for the report's other drives either way.
+func recordIngestionError(db *sql.DB, e IngestionError) {
+	if err := RecordIngestionError(db, e); err != nil {
+		log.Printf("Warning: Failed to record ingestion error for %s: %v", e.Hostname, err)
+	}
+}
+
+// healthState maps an analysis to the state tracked for recovery
+// notifications.
+func healthState(analysis *agentsmart.DriveHealthAnalysis) string {
+	switch {
+	case analysis.CriticalCount > 0:
+		return recovery.StateCritical
+	case analysis.WarningCount > 0:
+		return recovery.StateWarning
+	default:
+		return recovery.StateHealthy
+	}
+}
+
+// publishHealthAnalysis publishes the events for an analysis of driveData.
+func publishHealthAnalysis(bus *events.Bus, driveData *agentsmart.DriveSmartData, analysis *agentsmart.DriveHealthAnalysis) {
 	if analysis.OverallHealth == agentsmart.SeverityHealthy {
 		return
 	}
@@ -85,6 +179,7 @@ func publishSmartHealthEvents(bus *events.Bus, driveData *agentsmart.DriveSmartD
 			Severity:     events.SeverityCritical,
 			Hostname:     driveData.Hostname,
 			SerialNumber: driveData.SerialNumber,
+			Cause:        issuesCause(analysis.Issues, agentsmart.SeverityCritical),
 			Message: fmt.Sprintf("🔴 SMART critical: %d issue(s) on %s (%s)",
 				analysis.CriticalCount, driveData.SerialNumber, driveData.ModelName),
 			Metadata: map[string]string{
@@ -101,6 +196,7 @@ func publishSmartHealthEvents(bus *events.Bus, driveData *agentsmart.DriveSmartD
 			Severity:     events.SeverityWarning,
 			Hostname:     driveData.Hostname,
 			SerialNumber: driveData.SerialNumber,
+			Cause:        issuesCause(analysis.Issues, agentsmart.SeverityWarning),
 			Message: fmt.Sprintf("⚠️ SMART warning: %d issue(s) on %s (%s)",
 				analysis.WarningCount, driveData.SerialNumber, driveData.ModelName),
 			Metadata: map[string]string{
@@ -113,6 +209,43 @@ func publishSmartHealthEvents(bus *events.Bus, driveData *agentsmart.DriveSmartD
 	}
 }
 
+// attributeCauses classifies the SMART attributes whose issues aren't about
+// the media itself; everything else (sectors, read and seek errors, the
+// overall SMART status) counts as media.
+var attributeCauses = map[int]events.Cause{
+	190: events.CauseThermal,   // Airflow Temperature
+	194: events.CauseThermal,   // Temperature
+	188: events.CauseInterface, // Command Timeout
+	199: events.CauseInterface, // UDMA CRC Error Count
+	9:   events.CauseEndurance, // Power-On Hours
+	177: events.CauseEndurance, // Wear Leveling Count
+	179: events.CauseEndurance, // Used Reserved Block Count
+	193: events.CauseEndurance, // Load Cycle Count
+	202: events.CauseEndurance, // Percent Lifetime Used
+	232: events.CauseEndurance, // Available Reserved Space / NVMe available spare
+	233: events.CauseEndurance, // Media Wearout Indicator / NVMe percentage used
+	241: events.CauseEndurance, // Total LBAs Written
+	242: events.CauseEndurance, // Total LBAs Read
+}
+
+// AttributeCause returns the cause of an issue on a SMART attribute
+func AttributeCause(id int) events.Cause {
+	if c, ok := attributeCauses[id]; ok {
+		return c
+	}
+	return events.CauseMedia
+}
+
+// issuesCause returns the cause of the first issue of the given severity
+func issuesCause(issues []agentsmart.HealthIssue, severity string) events.Cause {
+	for _, issue := range issues {
+		if issue.Severity == severity {
+			return AttributeCause(issue.AttributeID)
+		}
+	}
+	return events.CauseMedia
+}
+
 func mapSeverity(s string) events.Severity {
 	switch s {
 	case agentsmart.SeverityCritical:
@@ -1,13 +1,16 @@
 package smart
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	agentsmart "vigil/cmd/agent/smart"
+	"vigil/internal/drivealerts"
 	"vigil/internal/events"
 )
 
-func TestPublishSmartHealthEvents_Healthy(t *testing.T) {
+func TestPublishHealthAnalysis_Healthy(t *testing.T) {
 	bus := events.NewBus()
 	var received []events.Event
 	bus.Subscribe(func(e events.Event) { received = append(received, e) })
@@ -20,14 +23,14 @@ func TestPublishSmartHealthEvents_Healthy(t *testing.T) {
 		Attributes:   []agentsmart.SmartAttribute{},
 	}
 
-	publishSmartHealthEvents(bus, driveData)
+	publishHealthAnalysis(bus, driveData, agentsmart.AnalyzeDriveHealthWithHistory(driveData, nil))
 
 	if len(received) != 0 {
 		t.Errorf("expected 0 events for healthy drive, got %d", len(received))
 	}
 }
 
-func TestPublishSmartHealthEvents_Critical(t *testing.T) {
+func TestPublishHealthAnalysis_Critical(t *testing.T) {
 	bus := events.NewBus()
 	var received []events.Event
 	bus.Subscribe(func(e events.Event) { received = append(received, e) })
@@ -40,7 +43,7 @@ func TestPublishSmartHealthEvents_Critical(t *testing.T) {
 		Attributes:   []agentsmart.SmartAttribute{},
 	}
 
-	publishSmartHealthEvents(bus, driveData)
+	publishHealthAnalysis(bus, driveData, agentsmart.AnalyzeDriveHealthWithHistory(driveData, nil))
 
 	if len(received) != 1 {
 		t.Fatalf("expected 1 event, got %d", len(received))
@@ -56,7 +59,7 @@ func TestPublishSmartHealthEvents_Critical(t *testing.T) {
 	}
 }
 
-func TestPublishSmartHealthEvents_ReallocatedSectors(t *testing.T) {
+func TestPublishHealthAnalysis_ReallocatedSectors(t *testing.T) {
 	bus := events.NewBus()
 	var received []events.Event
 	bus.Subscribe(func(e events.Event) { received = append(received, e) })
@@ -78,7 +81,7 @@ func TestPublishSmartHealthEvents_ReallocatedSectors(t *testing.T) {
 		},
 	}
 
-	publishSmartHealthEvents(bus, driveData)
+	publishHealthAnalysis(bus, driveData, agentsmart.AnalyzeDriveHealthWithHistory(driveData, nil))
 
 	// Should get both a ReallocatedSectors event and a SmartWarning/Critical event
 	hasRealloc := false
@@ -118,3 +121,98 @@ func TestMapSeverity(t *testing.T) {
 		}
 	}
 }
+
+func TestIssuesCause(t *testing.T) {
+	issues := []agentsmart.HealthIssue{
+		{AttributeID: 5, Severity: agentsmart.SeverityCritical},
+		{AttributeID: 199, Severity: agentsmart.SeverityWarning},
+	}
+	if got := issuesCause(issues, agentsmart.SeverityWarning); got != events.CauseInterface {
+		t.Errorf("warning cause = %q, want interface", got)
+	}
+	if got := issuesCause(issues, agentsmart.SeverityCritical); got != events.CauseMedia {
+		t.Errorf("critical cause = %q, want media", got)
+	}
+	if got := AttributeCause(194); got != events.CauseThermal {
+		t.Errorf("attribute 194 cause = %q, want thermal", got)
+	}
+}
+
+func TestProcessReportAtStoresAtCollectionTime(t *testing.T) {
+	db := setupSmartTestDB(t)
+	collected := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	report := map[string]interface{}{
+		"drives": []interface{}{
+			map[string]interface{}{
+				"serial_number": "OLD1",
+				"model_name":    "TestHDD",
+				"device":        map[string]interface{}{"name": "/dev/sda"},
+				"temperature":   map[string]interface{}{"current": float64(35)},
+				"ata_smart_attributes": map[string]interface{}{
+					"table": []interface{}{
+						map[string]interface{}{"id": float64(5), "name": "Reallocated_Sector_Ct", "value": float64(100), "raw": map[string]interface{}{"value": float64(0)}},
+					},
+				},
+			},
+		},
+	}
+	if err := ProcessReportAt(db, nil, "airgap01", report, collected); err != nil {
+		t.Fatal(err)
+	}
+
+	var ts string
+	if err := db.QueryRow(`SELECT timestamp FROM smart_attributes WHERE serial_number = 'OLD1' LIMIT 1`).Scan(&ts); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(ts, "2024-03-01") {
+		t.Errorf("expected attributes stored at the collection time, got %q", ts)
+	}
+
+	history, err := GetHealthStatusHistory(db, "airgap01", "OLD1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || !history[0].Timestamp.Equal(collected) {
+		t.Errorf("expected one health entry at %v, got %+v", collected, history)
+	}
+}
+
+func TestProcessReportSkipsEventsForDrivesWithAlertsOff(t *testing.T) {
+	db := setupSmartTestDB(t)
+	if err := drivealerts.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := drivealerts.SetEnabled(db, "nas01", "MUTED", false); err != nil {
+		t.Fatal(err)
+	}
+
+	bus := events.NewBus()
+	var received []events.Event
+	bus.Subscribe(func(e events.Event) { received = append(received, e) })
+
+	failing := func(serial string) map[string]interface{} {
+		return map[string]interface{}{
+			"serial_number": serial,
+			"model_name":    "TestHDD",
+			"device":        map[string]interface{}{"name": "/dev/sda"},
+			"smart_status":  map[string]interface{}{"passed": false},
+		}
+	}
+	report := map[string]interface{}{"drives": []interface{}{failing("MUTED"), failing("LOUD")}}
+	if err := ProcessReportWithEvents(db, bus, "nas01", report); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range received {
+		if e.SerialNumber == "MUTED" {
+			t.Errorf("expected no events for a drive with alerts off, got %+v", e)
+		}
+	}
+	if len(received) == 0 {
+		t.Error("expected events for the drive with alerts on")
+	}
+	if history, _ := GetHealthStatusHistory(db, "nas01", "MUTED"); len(history) != 1 {
+		t.Errorf("expected the muted drive's health to still be recorded, got %+v", history)
+	}
+}
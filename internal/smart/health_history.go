@@ -0,0 +1,119 @@
+package smart
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	agentsmart "vigil/cmd/agent/smart"
+)
+
+// HealthStatusChange is one transition of a drive's health status
+type HealthStatusChange struct {
+	ID             int64                    `json:"id"`
+	Hostname       string                   `json:"hostname"`
+	SerialNumber   string                   `json:"serial_number"`
+	ModelName      string                   `json:"model_name"`
+	PreviousStatus string                   `json:"previous_status"` // empty for the first status seen
+	Status         string                   `json:"status"`          // healthy, warning or critical
+	CriticalCount  int                      `json:"critical_count"`
+	WarningCount   int                      `json:"warning_count"`
+	Issues         []agentsmart.HealthIssue `json:"issues"`
+	Timestamp      time.Time                `json:"timestamp"`
+}
+
+// RecordHealthStatus adds a health_status_history row when the analysis
+// puts the drive in a different status than its last recorded one at or
+// before the reading, or when it has none yet. It reports whether a row was
+// added. A reading imported after the fact may land before later changes;
+// the change that follows it is then re-linked to it, or dropped if it no
+// longer changes anything.
+func RecordHealthStatus(db *sql.DB, driveData *agentsmart.DriveSmartData, analysis *agentsmart.DriveHealthAnalysis) (bool, error) {
+	status := healthState(analysis)
+	ts := driveData.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	at := ts.UTC().Format("2006-01-02 15:04:05")
+
+	var previous string
+	err := db.QueryRow(`
+		SELECT status FROM health_status_history
+		WHERE hostname = ? AND serial_number = ? AND timestamp <= ?
+		ORDER BY timestamp DESC, id DESC LIMIT 1`,
+		driveData.Hostname, driveData.SerialNumber, at).Scan(&previous)
+	if err != nil && err != sql.ErrNoRows {
+		return false, fmt.Errorf("read last health status: %w", err)
+	}
+	if previous == status {
+		return false, nil
+	}
+
+	issues, err := json.Marshal(analysis.Issues)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO health_status_history
+			(hostname, serial_number, model_name, previous_status, status, critical_count, warning_count, issues_json, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		driveData.Hostname, driveData.SerialNumber, driveData.ModelName, previous, status,
+		analysis.CriticalCount, analysis.WarningCount, string(issues), at)
+	if err != nil {
+		return false, fmt.Errorf("record health status: %w", err)
+	}
+
+	var nextID int64
+	var nextStatus string
+	err = db.QueryRow(`
+		SELECT id, status FROM health_status_history
+		WHERE hostname = ? AND serial_number = ? AND timestamp > ?
+		ORDER BY timestamp, id LIMIT 1`,
+		driveData.Hostname, driveData.SerialNumber, at).Scan(&nextID, &nextStatus)
+	switch {
+	case err == sql.ErrNoRows:
+		return true, nil
+	case err != nil:
+		return true, fmt.Errorf("read next health status: %w", err)
+	case nextStatus == status:
+		_, err = db.Exec(`DELETE FROM health_status_history WHERE id = ?`, nextID)
+	default:
+		_, err = db.Exec(`UPDATE health_status_history SET previous_status = ? WHERE id = ?`, status, nextID)
+	}
+	if err != nil {
+		return true, fmt.Errorf("relink next health status: %w", err)
+	}
+	return true, nil
+}
+
+// GetHealthStatusHistory returns a drive's health status changes, oldest
+// first.
+func GetHealthStatusHistory(db *sql.DB, hostname, serialNumber string) ([]HealthStatusChange, error) {
+	rows, err := db.Query(`
+		SELECT id, hostname, serial_number, model_name, previous_status, status,
+		       critical_count, warning_count, issues_json, timestamp
+		FROM health_status_history
+		WHERE hostname = ? AND serial_number = ?
+		ORDER BY timestamp, id`, hostname, serialNumber)
+	if err != nil {
+		return nil, fmt.Errorf("query health status history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []HealthStatusChange{}
+	for rows.Next() {
+		var c HealthStatusChange
+		var issues string
+		if err := rows.Scan(&c.ID, &c.Hostname, &c.SerialNumber, &c.ModelName, &c.PreviousStatus, &c.Status,
+			&c.CriticalCount, &c.WarningCount, &issues, &c.Timestamp); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(issues), &c.Issues); err != nil || c.Issues == nil {
+			c.Issues = []agentsmart.HealthIssue{}
+		}
+		history = append(history, c)
+	}
+	return history, rows.Err()
+}
@@ -0,0 +1,114 @@
+package smart
+
+import (
+	"testing"
+	"time"
+
+	agentsmart "vigil/cmd/agent/smart"
+)
+
+func TestHealthStatusHistoryRecordsChangesOnly(t *testing.T) {
+	db := setupSmartTestDB(t)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	record := func(day, warnings, criticals int) bool {
+		d := &agentsmart.DriveSmartData{
+			Hostname: "nas01", SerialNumber: "WD-1", ModelName: "WDC WD40EFRX",
+			Timestamp: start.AddDate(0, 0, day),
+		}
+		analysis := &agentsmart.DriveHealthAnalysis{WarningCount: warnings, CriticalCount: criticals}
+		if warnings > 0 {
+			analysis.Issues = []agentsmart.HealthIssue{{AttributeID: 5, AttributeName: "Reallocated_Sector_Ct", Severity: agentsmart.SeverityWarning}}
+		}
+		added, err := RecordHealthStatus(db, d, analysis)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return added
+	}
+
+	steps := []struct {
+		day, warnings, criticals int
+		added                    bool
+	}{
+		{0, 0, 0, true},  // first report
+		{1, 0, 0, false}, // still healthy
+		{2, 1, 0, true},  // healthy → warning
+		{3, 2, 0, false}, // more warnings, same status
+		{4, 1, 1, true},  // warning → critical
+		{5, 0, 0, true},  // critical → healthy
+	}
+	for _, s := range steps {
+		if got := record(s.day, s.warnings, s.criticals); got != s.added {
+			t.Errorf("day %d: added = %v, want %v", s.day, got, s.added)
+		}
+	}
+
+	history, err := GetHealthStatusHistory(db, "nas01", "WD-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]string{{"", "healthy"}, {"healthy", "warning"}, {"warning", "critical"}, {"critical", "healthy"}}
+	if len(history) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), history)
+	}
+	for i, c := range history {
+		if c.PreviousStatus != want[i][0] || c.Status != want[i][1] {
+			t.Errorf("change %d: %q → %q, want %q → %q", i, c.PreviousStatus, c.Status, want[i][0], want[i][1])
+		}
+	}
+	if !history[1].Timestamp.Equal(start.AddDate(0, 0, 2)) {
+		t.Errorf("warning recorded at %v, want the report's timestamp", history[1].Timestamp)
+	}
+	if len(history[1].Issues) != 1 || history[1].Issues[0].AttributeID != 5 {
+		t.Errorf("expected the warning's issue to be kept, got %+v", history[1].Issues)
+	}
+
+	if other, _ := GetHealthStatusHistory(db, "nas01", "OTHER"); len(other) != 0 {
+		t.Errorf("expected no history for another drive, got %+v", other)
+	}
+}
+
+func TestHealthStatusHistoryImportedOutOfOrder(t *testing.T) {
+	db := setupSmartTestDB(t)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	record := func(day, warnings, criticals int) {
+		d := &agentsmart.DriveSmartData{Hostname: "nas01", SerialNumber: "WD-1", Timestamp: start.AddDate(0, 0, day)}
+		analysis := &agentsmart.DriveHealthAnalysis{WarningCount: warnings, CriticalCount: criticals}
+		if _, err := RecordHealthStatus(db, d, analysis); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(want [][2]string, days []int) {
+		t.Helper()
+		history, err := GetHealthStatusHistory(db, "nas01", "WD-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != len(want) {
+			t.Fatalf("expected %d changes, got %+v", len(want), history)
+		}
+		for i, c := range history {
+			if c.PreviousStatus != want[i][0] || c.Status != want[i][1] || !c.Timestamp.Equal(start.AddDate(0, 0, days[i])) {
+				t.Errorf("change %d: %q → %q at %v, want %q → %q on day %d", i, c.PreviousStatus, c.Status, c.Timestamp, want[i][0], want[i][1], days[i])
+			}
+		}
+	}
+
+	record(0, 0, 0)
+	record(4, 1, 1)
+
+	// An imported day-2 warning goes between the two and the day-4
+	// change now follows it.
+	record(2, 1, 0)
+	check([][2]string{{"", "healthy"}, {"healthy", "warning"}, {"warning", "critical"}}, []int{0, 2, 4})
+
+	// An imported day-3 critical makes day 4 no change at all.
+	record(3, 1, 1)
+	check([][2]string{{"", "healthy"}, {"healthy", "warning"}, {"warning", "critical"}}, []int{0, 2, 3})
+
+	// A day-1 healthy reading changes nothing.
+	record(1, 0, 0)
+	check([][2]string{{"", "healthy"}, {"healthy", "warning"}, {"warning", "critical"}}, []int{0, 2, 3})
+}
@@ -0,0 +1,118 @@
+package smart
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	agentsmart "vigil/cmd/agent/smart"
+	"vigil/internal/settings"
+)
+
+// Attribute history modes for informational attributes (drives/attribute_history).
+const (
+	HistoryAll        = "all"         // store every attribute on every report
+	HistoryOnChange   = "on_change"   // store informational attributes when they change
+	HistoryLatestOnly = "latest_only" // keep only the latest informational sample
+)
+
+// AttributeHistoryPolicy decides how much history is kept per SMART
+// attribute. Critical and warning attributes, accumulating error counters
+// and any attribute at or past its failure threshold always get full
+// history; the mode applies to the rest (power-on hours, LBAs written and
+// read, vendor-specific counters), minus the IDs opted in through Keep.
+type AttributeHistoryPolicy struct {
+	Mode string
+	Keep map[int]bool
+}
+
+// LoadAttributeHistoryPolicy reads drives/attribute_history and
+// drives/history_attributes. Unknown modes fall back to keeping everything.
+func LoadAttributeHistoryPolicy(db *sql.DB) AttributeHistoryPolicy {
+	keep, _ := settings.DefaultValue("drives", "history_attributes")
+	p := AttributeHistoryPolicy{
+		Mode: settings.GetStringSettingWithDefault(db, "drives", "attribute_history", HistoryAll),
+		Keep: parseAttributeIDs(settings.GetStringSettingWithDefault(db, "drives", "history_attributes", keep)),
+	}
+	switch p.Mode {
+	case HistoryOnChange, HistoryLatestOnly:
+	default:
+		p.Mode = HistoryAll
+	}
+	return p
+}
+
+// fullHistory reports whether every sample of attr is stored.
+func (p AttributeHistoryPolicy) fullHistory(attr agentsmart.SmartAttribute) bool {
+	if p.Mode == HistoryAll || p.Keep[attr.ID] {
+		return true
+	}
+	if agentsmart.IsCriticalAttribute(attr.ID) || agentsmart.IsWarningAttribute(attr.ID) || agentsmart.AccumulatingCounters[attr.ID] {
+		return true
+	}
+	return attr.WhenFailed != "" || (attr.Threshold > 0 && attr.Value > 0 && attr.Value <= attr.Threshold)
+}
+
+// pruneHistory drops the samples of an informational attribute the policy
+// doesn't keep, relative to the sample about to be stored at timestamp.
+// The latest sample always stays, so views built on each drive's newest
+// report still see every attribute.
+//
+// latest_only deletes all earlier samples. on_change keeps the first and
+// last sample of each run of equal values: when the two previous samples
+// match the new one, the middle of the run is deleted, so the history still
+// shows when every change happened.
+func (p AttributeHistoryPolicy) pruneHistory(tx *sql.Tx, d *agentsmart.DriveSmartData, attr agentsmart.SmartAttribute, timestamp string) error {
+	switch p.Mode {
+	case HistoryLatestOnly:
+		_, err := tx.Exec(`
+			DELETE FROM smart_attributes
+			WHERE hostname = ? AND serial_number = ? AND attribute_id = ? AND timestamp < ?
+		`, d.Hostname, d.SerialNumber, attr.ID, timestamp)
+		return err
+
+	case HistoryOnChange:
+		rows, err := tx.Query(`
+			SELECT rowid, value, worst, raw_value
+			FROM smart_attributes
+			WHERE hostname = ? AND serial_number = ? AND attribute_id = ? AND timestamp < ?
+			ORDER BY timestamp DESC
+			LIMIT 2
+		`, d.Hostname, d.SerialNumber, attr.ID, timestamp)
+		if err != nil {
+			return err
+		}
+		var prev []int64
+		for rows.Next() {
+			var rowID, raw int64
+			var value, worst int
+			if err := rows.Scan(&rowID, &value, &worst, &raw); err != nil {
+				rows.Close()
+				return err
+			}
+			if value != attr.Value || worst != attr.Worst || raw != attr.RawValue {
+				break
+			}
+			prev = append(prev, rowID)
+		}
+		rows.Close()
+		if len(prev) < 2 {
+			return nil
+		}
+		_, err = tx.Exec("DELETE FROM smart_attributes WHERE rowid = ?", prev[0])
+		return err
+	}
+	return nil
+}
+
+// parseAttributeIDs parses a comma-separated list of attribute IDs,
+// skipping anything that isn't a number.
+func parseAttributeIDs(s string) map[int]bool {
+	ids := make(map[int]bool)
+	for _, f := range strings.Split(s, ",") {
+		if id, err := strconv.Atoi(strings.TrimSpace(f)); err == nil {
+			ids[id] = true
+		}
+	}
+	return ids
+}
@@ -0,0 +1,109 @@
+package smart
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	agentsmart "vigil/cmd/agent/smart"
+)
+
+func storeWithPolicy(t *testing.T, db *sql.DB, policy AttributeHistoryPolicy, ts time.Time, lbas, powerOn int64) {
+	t.Helper()
+	err := storeSmartAttributes(db, &agentsmart.DriveSmartData{
+		Hostname:     "nas01",
+		SerialNumber: "SER1",
+		DeviceName:   "/dev/sda",
+		Timestamp:    ts,
+		Attributes: []agentsmart.SmartAttribute{
+			{ID: 5, Name: "Reallocated_Sector_Ct", Value: 100, Worst: 100, RawValue: 0},
+			{ID: 9, Name: "Power_On_Hours", Value: 99, Worst: 99, RawValue: powerOn},
+			{ID: 241, Name: "Total_LBAs_Written", Value: 100, Worst: 100, RawValue: lbas},
+		},
+	}, policy)
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+}
+
+func countSamples(t *testing.T, db *sql.DB, attrID int) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM smart_attributes WHERE attribute_id = ?`, attrID).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestAttributeHistory_LatestOnly(t *testing.T) {
+	db := setupSmartTestDB(t)
+	policy := AttributeHistoryPolicy{Mode: HistoryLatestOnly, Keep: map[int]bool{9: true}}
+
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 4; i++ {
+		storeWithPolicy(t, db, policy, start.Add(time.Duration(i)*time.Minute), int64(1000*(i+1)), int64(100+i))
+	}
+
+	if n := countSamples(t, db, 5); n != 4 {
+		t.Errorf("critical attribute: expected full history (4), got %d", n)
+	}
+	if n := countSamples(t, db, 9); n != 4 {
+		t.Errorf("opted-in attribute: expected full history (4), got %d", n)
+	}
+	if n := countSamples(t, db, 241); n != 1 {
+		t.Errorf("informational attribute: expected only the latest sample, got %d", n)
+	}
+
+	attrs, err := GetLatestSmartAttributes(db, "nas01", "SER1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attrs) != 3 {
+		t.Fatalf("latest snapshot should still hold every attribute, got %d", len(attrs))
+	}
+	for _, a := range attrs {
+		if a.ID == 241 && a.RawValue != 4000 {
+			t.Errorf("expected latest LBAs written 4000, got %d", a.RawValue)
+		}
+	}
+}
+
+func TestAttributeHistory_OnChange(t *testing.T) {
+	db := setupSmartTestDB(t)
+	policy := AttributeHistoryPolicy{Mode: HistoryOnChange}
+
+	// 241 holds at 1000 for four reports, then moves to 2000 for two.
+	start := time.Now().Add(-time.Hour)
+	values := []int64{1000, 1000, 1000, 1000, 2000, 2000}
+	for i, v := range values {
+		storeWithPolicy(t, db, policy, start.Add(time.Duration(i)*time.Minute), v, 100)
+	}
+
+	// Runs keep their first and last sample: 1000, 1000, 2000, 2000.
+	history, err := GetSmartAttributeHistory(db, "nas01", "SER1", 241, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 4 {
+		t.Fatalf("expected 4 samples, got %d", len(history))
+	}
+	if history[0].RawValue != 2000 || history[3].RawValue != 1000 {
+		t.Errorf("unexpected history: %+v", history)
+	}
+	if n := countSamples(t, db, 5); n != len(values) {
+		t.Errorf("critical attribute: expected %d samples, got %d", len(values), n)
+	}
+}
+
+func TestAttributeHistory_AllByDefault(t *testing.T) {
+	db := setupSmartTestDB(t)
+	policy := LoadAttributeHistoryPolicy(db) // no settings table: defaults
+
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 3; i++ {
+		storeWithPolicy(t, db, policy, start.Add(time.Duration(i)*time.Minute), 1000, 100)
+	}
+	if n := countSamples(t, db, 241); n != 3 {
+		t.Errorf("expected every sample kept, got %d", n)
+	}
+}
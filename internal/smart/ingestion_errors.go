@@ -0,0 +1,113 @@
+package smart
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	agentsmart "vigil/cmd/agent/smart"
+)
+
+// Ingestion stages at which a drive from a report can be lost
+const (
+	StageParse = "parse"
+	StageStore = "store"
+)
+
+// IngestionError is one drive of a report that didn't make it into the
+// SMART history.
+type IngestionError struct {
+	ID           int64     `json:"id"`
+	Hostname     string    `json:"hostname"`
+	SerialNumber string    `json:"serial_number"`
+	DeviceName   string    `json:"device_name,omitempty"`
+	Stage        string    `json:"stage"`
+	Error        string    `json:"error"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// parseReportDrive turns one entry of a report's "drives" array into drive
+// data, failing for entries that can't be stored: not an object, or no
+// serial number to key the history on.
+func parseReportDrive(entry interface{}, hostname string) (*agentsmart.DriveSmartData, error) {
+	driveMap, ok := entry.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("drive entry is %T, not an object", entry)
+	}
+	driveData, err := agentsmart.ParseSmartAttributes(driveMap, hostname)
+	if err != nil {
+		return nil, err
+	}
+	if driveData.SerialNumber == "" {
+		return driveData, fmt.Errorf("no serial number in smartctl output")
+	}
+	return driveData, nil
+}
+
+// ReportParseErrors checks every drive of a report the way
+// ProcessReportWithEvents will, without storing anything, and returns the
+// ones that will be dropped. Store failures only show up later, in
+// ingestion_errors.
+func ReportParseErrors(hostname string, reportData map[string]interface{}) []IngestionError {
+	drives, _ := reportData["drives"].([]interface{})
+	var errs []IngestionError
+	for _, entry := range drives {
+		if driveData, err := parseReportDrive(entry, hostname); err != nil {
+			errs = append(errs, newIngestionError(hostname, driveData, StageParse, err))
+		}
+	}
+	return errs
+}
+
+func newIngestionError(hostname string, driveData *agentsmart.DriveSmartData, stage string, err error) IngestionError {
+	e := IngestionError{Hostname: hostname, Stage: stage, Error: err.Error(), Timestamp: time.Now().UTC()}
+	if driveData != nil {
+		e.SerialNumber = driveData.SerialNumber
+		e.DeviceName = driveData.DeviceName
+	}
+	return e
+}
+
+// RecordIngestionError stores a dropped drive in ingestion_errors.
+func RecordIngestionError(db *sql.DB, e IngestionError) error {
+	_, err := db.Exec(`
+		INSERT INTO ingestion_errors (hostname, serial_number, device_name, stage, error, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		e.Hostname, e.SerialNumber, e.DeviceName, e.Stage, e.Error, e.Timestamp.Format("2006-01-02 15:04:05"))
+	return err
+}
+
+// ListIngestionErrors returns the most recent ingestion errors, newest
+// first, optionally for one host only.
+func ListIngestionErrors(db *sql.DB, hostname string, limit int) ([]IngestionError, error) {
+	query := `SELECT id, hostname, serial_number, device_name, stage, error, timestamp FROM ingestion_errors`
+	var args []interface{}
+	if hostname != "" {
+		query += ` WHERE hostname = ?`
+		args = append(args, hostname)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list ingestion errors: %w", err)
+	}
+	defer rows.Close()
+
+	list := []IngestionError{}
+	for rows.Next() {
+		var e IngestionError
+		if err := rows.Scan(&e.ID, &e.Hostname, &e.SerialNumber, &e.DeviceName, &e.Stage, &e.Error, &e.Timestamp); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// CleanupOldIngestionErrors removes ingestion_errors rows older than the
+// specified days. A daysToKeep value of 0 or less is a no-op.
+func CleanupOldIngestionErrors(db *sql.DB, daysToKeep int) (int64, error) {
+	return deleteOlderThan(db, "ingestion_errors", daysToKeep)
+}
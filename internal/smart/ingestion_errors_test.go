@@ -0,0 +1,119 @@
+package smart
+
+import (
+	"testing"
+	"time"
+
+	agentsmart "vigil/cmd/agent/smart"
+)
+
+func TestIngestionErrorsRecorded(t *testing.T) {
+	db := setupSmartTestDB(t)
+
+	report := map[string]interface{}{
+		"drives": []interface{}{
+			map[string]interface{}{
+				"serial_number": "GOOD1",
+				"model_name":    "TestHDD",
+				"device":        map[string]interface{}{"name": "/dev/sda"},
+				"ata_smart_attributes": map[string]interface{}{
+					"table": []interface{}{
+						map[string]interface{}{"id": float64(5), "name": "Reallocated_Sector_Ct", "value": float64(100), "raw": map[string]interface{}{"value": float64(0)}},
+					},
+				},
+			},
+			map[string]interface{}{
+				"model_name": "NoSerial",
+				"device":     map[string]interface{}{"name": "/dev/sdb"},
+			},
+			"garbage",
+		},
+	}
+
+	if got := ReportParseErrors("nas01", report); len(got) != 2 {
+		t.Fatalf("expected 2 parse errors, got %+v", got)
+	}
+
+	if err := ProcessReportWithEvents(db, nil, "nas01", report); err == nil {
+		t.Fatal("expected an error for the dropped drives")
+	}
+
+	list, err := ListIngestionErrors(db, "nas01", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 ingestion errors, got %+v", list)
+	}
+	for _, e := range list {
+		if e.Stage != StageParse || e.Error == "" || e.Timestamp.IsZero() {
+			t.Errorf("unexpected ingestion error %+v", e)
+		}
+	}
+	if list[1].DeviceName != "/dev/sdb" {
+		t.Errorf("expected the serial-less drive's device name, got %q", list[1].DeviceName)
+	}
+
+	if other, _ := ListIngestionErrors(db, "other", 10); len(other) != 0 {
+		t.Errorf("expected no errors for another host, got %+v", other)
+	}
+
+	attrs, err := GetLatestSmartAttributes(db, "nas01", "GOOD1")
+	if err != nil || len(attrs) == 0 {
+		t.Errorf("expected the good drive to be stored, got %+v (%v)", attrs, err)
+	}
+}
+
+func TestOutOfRangeTemperatureDiscarded(t *testing.T) {
+	db := setupSmartTestDB(t)
+	ts := time.Now().UTC().Truncate(time.Second)
+
+	for i, temp := range []int{255, 0, 38, 3} {
+		err := StoreSmartAttributes(db, &agentsmart.DriveSmartData{
+			Hostname:     "nas01",
+			SerialNumber: "SER1",
+			DeviceName:   "/dev/sda",
+			Temperature:  temp,
+			Timestamp:    ts.Add(time.Duration(i) * time.Minute),
+			Attributes: []agentsmart.SmartAttribute{
+				{ID: 5, Name: "Reallocated_Sector_Ct", Value: 100, RawValue: 0},
+			},
+		})
+		if err != nil {
+			t.Fatalf("store %d°C: %v", temp, err)
+		}
+	}
+
+	var temps []int
+	rows, err := db.Query(`SELECT temperature FROM temperature_history ORDER BY timestamp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var v int
+		rows.Scan(&v)
+		temps = append(temps, v)
+	}
+	rows.Close()
+	if len(temps) != 1 || temps[0] != 38 {
+		t.Errorf("expected only the 38°C reading stored, got %v", temps)
+	}
+
+	// 0 means no reading at all, so only 255 and 3 are errors
+	list, err := ListIngestionErrors(db, "nas01", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 ingestion errors, got %+v", list)
+	}
+	for _, e := range list {
+		if e.Stage != StageTemperature || e.SerialNumber != "SER1" {
+			t.Errorf("unexpected ingestion error %+v", e)
+		}
+	}
+
+	if n := countSamples(t, db, 5); n != 4 {
+		t.Errorf("expected attributes stored despite bad temperatures, got %d samples", n)
+	}
+}
@@ -133,6 +133,96 @@ func MigrateSmartAttributes(db *sql.DB) error {
 			CREATE INDEX IF NOT EXISTS idx_health_serial    ON drive_health_snapshots(serial_number);
 			CREATE INDEX IF NOT EXISTS idx_health_timestamp ON drive_health_snapshots(timestamp);
 			CREATE INDEX IF NOT EXISTS idx_health_status    ON drive_health_snapshots(overall_health);`},
+
+		// ─── 4. daily rollups (filled by DownsampleOldData) ──────────────
+		{"temperature_daily", `
+			CREATE TABLE IF NOT EXISTS temperature_daily (
+				hostname      TEXT    NOT NULL,
+				serial_number TEXT    NOT NULL,
+				day           TEXT    NOT NULL, -- YYYY-MM-DD
+				avg_temp      REAL    NOT NULL,
+				min_temp      INTEGER NOT NULL,
+				max_temp      INTEGER NOT NULL,
+				samples       INTEGER NOT NULL,
+				PRIMARY KEY (hostname, serial_number, day)
+			);`},
+		{"smart_attributes_daily", `
+			CREATE TABLE IF NOT EXISTS smart_attributes_daily (
+				hostname       TEXT    NOT NULL,
+				serial_number  TEXT    NOT NULL,
+				attribute_id   INTEGER NOT NULL,
+				attribute_name TEXT    NOT NULL,
+				day            TEXT    NOT NULL, -- YYYY-MM-DD
+				avg_value      REAL,
+				min_worst      INTEGER,
+				avg_raw_value  REAL,
+				max_raw_value  INTEGER,
+				samples        INTEGER NOT NULL,
+				PRIMARY KEY (hostname, serial_number, attribute_id, day)
+			);`},
+
+		// ─── 5. custom_attribute_rules (user-defined watches) ────────────
+		{"custom_attribute_rules", `
+			CREATE TABLE IF NOT EXISTS custom_attribute_rules (
+				id           INTEGER  PRIMARY KEY AUTOINCREMENT,
+				attribute_id INTEGER  NOT NULL,
+				model        TEXT     NOT NULL DEFAULT '', -- substring of the model name; '' = every drive
+				field        TEXT     NOT NULL DEFAULT 'raw', -- 'raw' or 'value' (normalized)
+				operator     TEXT     NOT NULL DEFAULT 'above', -- 'above' or 'below'
+				threshold    INTEGER  NOT NULL,
+				severity     TEXT     NOT NULL, -- 'CRITICAL', 'WARNING', 'INFO'
+				description  TEXT     NOT NULL DEFAULT '',
+				enabled      INTEGER  NOT NULL DEFAULT 1,
+				created_at   DATETIME DEFAULT CURRENT_TIMESTAMP
+			);`},
+
+		// ─── 6. ingestion_errors (drives dropped from reports) ───────────
+		{"ingestion_errors", `
+			CREATE TABLE IF NOT EXISTS ingestion_errors (
+				id            INTEGER  PRIMARY KEY AUTOINCREMENT,
+				hostname      TEXT     NOT NULL,
+				serial_number TEXT     NOT NULL DEFAULT '', -- '' when the drive reported none
+				device_name   TEXT     NOT NULL DEFAULT '',
+				stage         TEXT     NOT NULL, -- 'parse' or 'store'
+				error         TEXT     NOT NULL,
+				timestamp     DATETIME NOT NULL
+			);`},
+		{"idx_ingestion_errors_host", `
+			CREATE INDEX IF NOT EXISTS idx_ingestion_errors_host
+			ON ingestion_errors(hostname, timestamp DESC);`},
+
+		// ─── 7. health_status_history (one row per status change) ────────
+		{"health_status_history", `
+			CREATE TABLE IF NOT EXISTS health_status_history (
+				id              INTEGER  PRIMARY KEY AUTOINCREMENT,
+				hostname        TEXT     NOT NULL,
+				serial_number   TEXT     NOT NULL,
+				model_name      TEXT     NOT NULL DEFAULT '',
+				previous_status TEXT     NOT NULL DEFAULT '', -- '' on the drive's first report
+				status          TEXT     NOT NULL, -- 'healthy', 'warning' or 'critical'
+				critical_count  INTEGER  NOT NULL DEFAULT 0,
+				warning_count   INTEGER  NOT NULL DEFAULT 0,
+				issues_json     TEXT     NOT NULL DEFAULT '[]',
+				timestamp       DATETIME NOT NULL
+			);`},
+		{"idx_health_status_history_drive", `
+			CREATE INDEX IF NOT EXISTS idx_health_status_history_drive
+			ON health_status_history(hostname, serial_number, id);`},
+
+		// ─── 8. drive_temperature_sampling (per-drive overrides) ──────────
+		{"drive_temperature_sampling", `
+			CREATE TABLE IF NOT EXISTS drive_temperature_sampling (
+				hostname         TEXT     NOT NULL COLLATE NOCASE,
+				serial_number    TEXT     NOT NULL,
+				interval_seconds INTEGER  NOT NULL, -- 0 = keep every reading
+				updated_at       DATETIME DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (hostname, serial_number)
+			);`},
+
+		// ─── 9. health_status_history is ordered by reading time ──────────
+		{"idx_health_status_history_drive_time", `
+			CREATE INDEX IF NOT EXISTS idx_health_status_history_drive_time
+			ON health_status_history(hostname, serial_number, timestamp);`},
 	}
 
 	for _, s := range statements {
@@ -142,6 +232,29 @@ func MigrateSmartAttributes(db *sql.DB) error {
 		log.Printf("  ✓ %s", s.label)
 	}
 
+	if err := migrateDeviceByID(db); err != nil {
+		return fmt.Errorf("migration failed at [smart_attributes.device_by_id]: %w", err)
+	}
+
 	log.Println("📊 Migration completed: SMART tables ready")
 	return nil
 }
+
+// migrateDeviceByID adds the device_by_id column to smart_attributes so the
+// stable /dev/disk/by-id path is stored alongside the kernel device name,
+// which can change across reboots. No-op if already present.
+func migrateDeviceByID(db *sql.DB) error {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('smart_attributes') WHERE name = 'device_by_id'`).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if _, err := db.Exec(`ALTER TABLE smart_attributes ADD COLUMN device_by_id TEXT DEFAULT ''`); err != nil {
+		return err
+	}
+	log.Println("  ✓ smart_attributes.device_by_id column")
+	return nil
+}
@@ -0,0 +1,123 @@
+package smart
+
+import (
+	"database/sql"
+	"fmt"
+
+	agentsmart "vigil/cmd/agent/smart"
+	"vigil/internal/events"
+	"vigil/internal/settings"
+)
+
+// Attributes that count power events. 174 is the NVMe unsafe shutdown count
+// (stored under that pseudo-ID) and the SSD "Unexpected Power Loss Count";
+// 192 is the HDD "Power-Off Retract Count", bumped when the heads are
+// emergency-parked because power went away.
+const powerCycleAttribute = 12
+
+var unsafeShutdownAttributes = []int{174, 192}
+
+// PowerCounterIncrease is the growth of a power counter between two reports.
+type PowerCounterIncrease struct {
+	AttributeID int
+	Name        string
+	Previous    int64
+	Current     int64
+}
+
+// Delta is how much the counter grew.
+func (p PowerCounterIncrease) Delta() int64 { return p.Current - p.Previous }
+
+// previousPowerCounters returns the last stored raw value of each power
+// counter for a drive. It must run before the new report is stored.
+func previousPowerCounters(db *sql.DB, hostname, serial string) map[int]int64 {
+	prev := make(map[int]int64)
+	for _, id := range append([]int{powerCycleAttribute}, unsafeShutdownAttributes...) {
+		var raw int64
+		err := db.QueryRow(`
+			SELECT raw_value FROM smart_attributes
+			WHERE hostname = ? AND serial_number = ? AND attribute_id = ?
+			ORDER BY timestamp DESC LIMIT 1
+		`, hostname, serial, id).Scan(&raw)
+		if err == nil {
+			prev[id] = raw
+		}
+	}
+	return prev
+}
+
+// powerCounterIncreases compares the power counters of a report against
+// the previous values. Counters without a previous value, or that went
+// down (counter reset), are skipped.
+func powerCounterIncreases(prev map[int]int64, attrs []agentsmart.SmartAttribute) (cycles *PowerCounterIncrease, unsafe []PowerCounterIncrease) {
+	for _, attr := range attrs {
+		before, ok := prev[attr.ID]
+		if !ok || attr.RawValue <= before {
+			continue
+		}
+		inc := PowerCounterIncrease{AttributeID: attr.ID, Name: attr.Name, Previous: before, Current: attr.RawValue}
+		if attr.ID == powerCycleAttribute {
+			cycles = &inc
+			continue
+		}
+		for _, id := range unsafeShutdownAttributes {
+			if attr.ID == id {
+				unsafe = append(unsafe, inc)
+			}
+		}
+	}
+	return cycles, unsafe
+}
+
+// publishPowerEvents reports unsafe shutdowns and bursts of power cycles
+// since the drive's previous report. Any new unsafe shutdown is
+// informational; drives/unsafe_shutdown_warn of them at once is a warning.
+// Power cycles only raise a warning once drives/power_cycle_jump are seen
+// between two reports. A threshold of 0 disables that check.
+func publishPowerEvents(bus *events.Bus, db *sql.DB, d *agentsmart.DriveSmartData, prev map[int]int64) {
+	if len(prev) == 0 {
+		return
+	}
+	cycles, unsafe := powerCounterIncreases(prev, d.Attributes)
+
+	if warnAt := settings.GetInt(db, "drives", "unsafe_shutdown_warn", 2); warnAt > 0 {
+		for _, inc := range unsafe {
+			severity := events.SeverityInfo
+			if inc.Delta() >= int64(warnAt) {
+				severity = events.SeverityWarning
+			}
+			bus.Publish(events.Event{
+				Type:         events.UnsafeShutdowns,
+				Severity:     severity,
+				Hostname:     d.Hostname,
+				SerialNumber: d.SerialNumber,
+				Message: fmt.Sprintf("%d unsafe shutdown(s) on %s (%s) since the last report (%s: %d → %d); check power and UPS",
+					inc.Delta(), d.SerialNumber, d.ModelName, inc.Name, inc.Previous, inc.Current),
+				Metadata: powerEventMetadata(d, inc),
+			})
+		}
+	}
+
+	if jump := settings.GetInt(db, "drives", "power_cycle_jump", 3); jump > 0 && cycles != nil && cycles.Delta() >= int64(jump) {
+		bus.Publish(events.Event{
+			Type:         events.PowerCycleSpike,
+			Severity:     events.SeverityWarning,
+			Hostname:     d.Hostname,
+			SerialNumber: d.SerialNumber,
+			Message: fmt.Sprintf("%s (%s) power-cycled %d times since the last report (%d → %d)",
+				d.SerialNumber, d.ModelName, cycles.Delta(), cycles.Previous, cycles.Current),
+			Metadata: powerEventMetadata(d, *cycles),
+		})
+	}
+}
+
+func powerEventMetadata(d *agentsmart.DriveSmartData, inc PowerCounterIncrease) map[string]string {
+	return map[string]string{
+		"model":        d.ModelName,
+		"drive_type":   d.DriveType,
+		"attribute_id": fmt.Sprintf("%d", inc.AttributeID),
+		"previous":     fmt.Sprintf("%d", inc.Previous),
+		"current":      fmt.Sprintf("%d", inc.Current),
+		"increase":     fmt.Sprintf("%d", inc.Delta()),
+	}
+}
@@ -0,0 +1,66 @@
+package smart
+
+import (
+	"testing"
+	"time"
+
+	agentsmart "vigil/cmd/agent/smart"
+	"vigil/internal/events"
+)
+
+func TestPublishPowerEvents(t *testing.T) {
+	db := setupSmartTestDB(t)
+	bus := events.NewBus()
+	var received []events.Event
+	bus.Subscribe(func(e events.Event) { received = append(received, e) })
+
+	report := func(ts time.Time, cycles, unsafe int64) {
+		d := &agentsmart.DriveSmartData{
+			Hostname:     "nas01",
+			SerialNumber: "NVME1",
+			ModelName:    "TestNVMe",
+			DriveType:    "NVMe",
+			Timestamp:    ts,
+			Attributes: []agentsmart.SmartAttribute{
+				{ID: 12, Name: "Power Cycles", RawValue: cycles},
+				{ID: 174, Name: "Unsafe Shutdowns", RawValue: unsafe},
+			},
+		}
+		prev := previousPowerCounters(db, d.Hostname, d.SerialNumber)
+		if err := StoreSmartAttributes(db, d); err != nil {
+			t.Fatal(err)
+		}
+		publishPowerEvents(bus, db, d, prev)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	report(start, 100, 5) // first report: nothing to compare against
+	if len(received) != 0 {
+		t.Fatalf("expected no events on first report, got %+v", received)
+	}
+
+	report(start.Add(time.Minute), 101, 6) // one of each: unsafe shutdown info only
+	if len(received) != 1 || received[0].Type != events.UnsafeShutdowns || received[0].Severity != events.SeverityInfo {
+		t.Fatalf("expected one info unsafe shutdown event, got %+v", received)
+	}
+
+	received = nil
+	report(start.Add(2*time.Minute), 105, 9)
+	if len(received) != 2 {
+		t.Fatalf("expected unsafe shutdown and power cycle events, got %+v", received)
+	}
+	for _, e := range received {
+		if e.Severity != events.SeverityWarning {
+			t.Errorf("%s: expected warning, got %v", e.Type, e.Severity)
+		}
+		if e.Type == events.PowerCycleSpike && e.Metadata["increase"] != "4" {
+			t.Errorf("expected power cycle increase 4, got %q", e.Metadata["increase"])
+		}
+	}
+
+	received = nil
+	report(start.Add(3*time.Minute), 2, 0) // counter reset after a controller swap
+	if len(received) != 0 {
+		t.Errorf("expected no events after a counter reset, got %+v", received)
+	}
+}
@@ -0,0 +1,124 @@
+package smart
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	vigildb "vigil/internal/db"
+)
+
+// Limits for QueryAttributes
+const (
+	DefaultQueryLimit = 500
+	MaxQueryLimit     = 5000
+)
+
+// AttributeQuery filters raw smart_attributes rows. Zero-valued fields
+// don't filter; From is inclusive and To exclusive.
+type AttributeQuery struct {
+	Hostname     string
+	SerialNumber string
+	AttributeID  *int
+	From         time.Time
+	To           time.Time
+	Limit        int // DefaultQueryLimit when 0, capped at MaxQueryLimit
+}
+
+// AttributeRow is one stored sample of one attribute, with both its
+// normalized (value, worst, threshold) and raw readings.
+type AttributeRow struct {
+	Hostname      string    `json:"hostname"`
+	SerialNumber  string    `json:"serial_number"`
+	DeviceName    string    `json:"device_name"`
+	AttributeID   int       `json:"attribute_id"`
+	AttributeName string    `json:"attribute_name"`
+	Value         int       `json:"value"`
+	Worst         int       `json:"worst"`
+	Threshold     int       `json:"threshold"`
+	RawValue      int64     `json:"raw_value"`
+	WhenFailed    string    `json:"when_failed,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// QueryAttributes returns the smart_attributes rows matching q, newest
+// first. truncated reports whether more rows matched than the limit let
+// through. Only raw samples are searched: rows already rolled up into
+// smart_attributes_daily by DownsampleOldData aren't returned.
+func QueryAttributes(db *sql.DB, q AttributeQuery) (rows []AttributeRow, truncated bool, err error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultQueryLimit
+	}
+	if limit > MaxQueryLimit {
+		limit = MaxQueryLimit
+	}
+
+	var where []string
+	var args []interface{}
+	if q.Hostname != "" {
+		where = append(where, "hostname = ?")
+		args = append(args, q.Hostname)
+	}
+	if q.SerialNumber != "" {
+		where = append(where, "serial_number = ?")
+		args = append(args, q.SerialNumber)
+	}
+	if q.AttributeID != nil {
+		where = append(where, "attribute_id = ?")
+		args = append(args, *q.AttributeID)
+	}
+	if !q.From.IsZero() {
+		where = append(where, "timestamp >= ?")
+		args = append(args, q.From.UTC().Format("2006-01-02 15:04:05"))
+	}
+	if !q.To.IsZero() {
+		where = append(where, "timestamp < ?")
+		args = append(args, q.To.UTC().Format("2006-01-02 15:04:05"))
+	}
+
+	query := `
+		SELECT hostname, serial_number, device_name, attribute_id, attribute_name,
+		       value, worst, threshold, raw_value, when_failed, timestamp
+		FROM smart_attributes`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	// One extra row tells a full page from a truncated one
+	query += " ORDER BY timestamp DESC, attribute_id LIMIT ?"
+	args = append(args, limit+1)
+
+	result, err := db.Query(query, args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("query smart attributes: %w", err)
+	}
+	defer result.Close()
+
+	rows = []AttributeRow{}
+	for result.Next() {
+		var row AttributeRow
+		var value, worst, threshold, raw sql.NullInt64
+		var whenFailed sql.NullString
+		var timestampStr string
+		if err := result.Scan(&row.Hostname, &row.SerialNumber, &row.DeviceName, &row.AttributeID, &row.AttributeName,
+			&value, &worst, &threshold, &raw, &whenFailed, &timestampStr); err != nil {
+			return nil, false, err
+		}
+		row.Value = int(value.Int64)
+		row.Worst = int(worst.Int64)
+		row.Threshold = int(threshold.Int64)
+		row.RawValue = raw.Int64
+		row.WhenFailed = whenFailed.String
+		row.Timestamp = vigildb.ParseTime(timestampStr)
+		rows = append(rows, row)
+	}
+	if err := result.Err(); err != nil {
+		return nil, false, err
+	}
+
+	if len(rows) > limit {
+		return rows[:limit], true, nil
+	}
+	return rows, false, nil
+}
@@ -0,0 +1,51 @@
+package smart
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueryAttributes(t *testing.T) {
+	db := setupSmartTestDB(t)
+	day := 24 * time.Hour
+
+	storeCRC(t, db, "SN1", 1, 10*day)
+	storeCRC(t, db, "SN1", 2, 5*day)
+	storeCRC(t, db, "SN1", 3, time.Hour)
+	storeCRC(t, db, "SN2", 7, 5*day)
+
+	rows, truncated, err := QueryAttributes(db, AttributeQuery{SerialNumber: "SN1"})
+	if err != nil {
+		t.Fatalf("QueryAttributes: %v", err)
+	}
+	if len(rows) != 3 || truncated {
+		t.Fatalf("got %d rows (truncated=%v), want 3", len(rows), truncated)
+	}
+	if rows[0].RawValue != 3 || rows[0].Value != 200 || rows[0].AttributeName != "UDMA_CRC_Error_Count" {
+		t.Errorf("newest row = %+v", rows[0])
+	}
+
+	// Window: from inclusive, to exclusive
+	crc := 199
+	rows, _, err = QueryAttributes(db, AttributeQuery{
+		AttributeID: &crc,
+		From:        time.Now().Add(-6 * day),
+		To:          time.Now().Add(-2 * day),
+	})
+	if err != nil {
+		t.Fatalf("QueryAttributes window: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("window: got %d rows, want 2 (SN1 and SN2 five days ago)", len(rows))
+	}
+
+	other := 5
+	if rows, _, _ := QueryAttributes(db, AttributeQuery{AttributeID: &other}); len(rows) != 0 {
+		t.Errorf("attribute 5: got %d rows, want 0", len(rows))
+	}
+
+	rows, truncated, _ = QueryAttributes(db, AttributeQuery{Hostname: "nas01", Limit: 2})
+	if len(rows) != 2 || !truncated {
+		t.Errorf("limit 2: got %d rows (truncated=%v), want 2 truncated", len(rows), truncated)
+	}
+}
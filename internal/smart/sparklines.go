@@ -0,0 +1,116 @@
+package smart
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SparklineTemperature is the sparkline key for drive temperature;
+	// any other key is a SMART attribute ID.
+	SparklineTemperature = "temp"
+	// SparklineDays is how far back sparklines reach, one point per day.
+	SparklineDays = 30
+	// maxSparklineKeys caps how many series one request may ask for.
+	maxSparklineKeys = 8
+)
+
+// ParseSparklineKeys parses a comma-separated sparkline selection such as
+// "temp,5,197" into its distinct keys.
+func ParseSparklineKeys(spec string) ([]string, error) {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, k := range strings.Split(spec, ",") {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k == "" || seen[k] {
+			continue
+		}
+		if k != SparklineTemperature {
+			if id, err := strconv.Atoi(k); err != nil || id < 1 || id > 255 {
+				return nil, fmt.Errorf("invalid sparkline %q: use %q or a SMART attribute ID (1-255)", k, SparklineTemperature)
+			}
+		}
+		seen[k] = true
+		keys = append(keys, k)
+	}
+	if len(keys) > maxSparklineKeys {
+		return nil, fmt.Errorf("at most %d sparklines may be requested", maxSparklineKeys)
+	}
+	return keys, nil
+}
+
+// GetSparklines returns, per "hostname:serial" and key, one value per day
+// over the last SparklineDays days, oldest first; days without samples are
+// skipped. Temperatures are daily averages and attributes daily maximum raw
+// values. Days already rolled up by DownsampleOldData are read from the
+// daily tables, so the series is the same whether or not they were.
+func GetSparklines(db *sql.DB, keys []string) (map[string]map[string][]float64, error) {
+	since := time.Now().UTC().AddDate(0, 0, -(SparklineDays - 1)).Format("2006-01-02")
+	out := make(map[string]map[string][]float64)
+
+	for _, key := range keys {
+		var rows *sql.Rows
+		var err error
+		if key == SparklineTemperature {
+			rows, err = db.Query(`
+				SELECT hostname, serial_number, day, SUM(total) * 1.0 / SUM(samples)
+				FROM (
+					SELECT hostname, serial_number, date(timestamp) AS day,
+					       SUM(temperature) AS total, COUNT(*) AS samples
+					FROM temperature_history
+					WHERE timestamp >= ?
+					GROUP BY hostname, serial_number, day
+					UNION ALL
+					SELECT hostname, serial_number, day, avg_temp * samples, samples
+					FROM temperature_daily
+					WHERE day >= ?
+				)
+				GROUP BY hostname, serial_number, day
+				ORDER BY hostname, serial_number, day`, since, since)
+		} else {
+			rows, err = db.Query(`
+				SELECT hostname, serial_number, day, MAX(raw)
+				FROM (
+					SELECT hostname, serial_number, date(timestamp) AS day, raw_value AS raw
+					FROM smart_attributes
+					WHERE attribute_id = ? AND timestamp >= ?
+					UNION ALL
+					SELECT hostname, serial_number, day, max_raw_value
+					FROM smart_attributes_daily
+					WHERE attribute_id = ? AND day >= ?
+				)
+				GROUP BY hostname, serial_number, day
+				ORDER BY hostname, serial_number, day`, key, since, key, since)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("sparkline %s: %w", key, err)
+		}
+
+		for rows.Next() {
+			var host, serial, day string
+			var v sql.NullFloat64
+			if err := rows.Scan(&host, &serial, &day, &v); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if !v.Valid {
+				continue
+			}
+			drive := host + ":" + serial
+			if out[drive] == nil {
+				out[drive] = make(map[string][]float64)
+			}
+			out[drive][key] = append(out[drive][key], math.Round(v.Float64*10)/10)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
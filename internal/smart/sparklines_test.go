@@ -0,0 +1,72 @@
+package smart
+
+import (
+	"testing"
+	"time"
+
+	agentsmart "vigil/cmd/agent/smart"
+)
+
+func TestParseSparklineKeys(t *testing.T) {
+	keys, err := ParseSparklineKeys(" temp, 5,197,5 ")
+	if err != nil || len(keys) != 3 || keys[0] != "temp" || keys[1] != "5" || keys[2] != "197" {
+		t.Errorf("ParseSparklineKeys = %v, %v", keys, err)
+	}
+	if keys, err := ParseSparklineKeys(""); err != nil || len(keys) != 0 {
+		t.Errorf("empty spec = %v, %v", keys, err)
+	}
+	for _, bad := range []string{"temperature", "0", "256", "1,2,3,4,5,6,7,8,9"} {
+		if _, err := ParseSparklineKeys(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestGetSparklines(t *testing.T) {
+	db := setupSmartTestDB(t)
+
+	day := func(daysAgo, hour int) time.Time {
+		d := time.Now().AddDate(0, 0, -daysAgo)
+		return time.Date(d.Year(), d.Month(), d.Day(), hour, 0, 0, 0, d.Location())
+	}
+	store := func(ts time.Time, temp int, raw int64) {
+		t.Helper()
+		err := StoreSmartAttributes(db, &agentsmart.DriveSmartData{
+			Hostname:     "nas01",
+			SerialNumber: "SER1",
+			DeviceName:   "/dev/sda",
+			Timestamp:    ts,
+			Temperature:  temp,
+			Attributes: []agentsmart.SmartAttribute{
+				{ID: 5, Name: "Reallocated_Sector_Ct", Value: 100, Worst: 100, RawValue: raw},
+			},
+		})
+		if err != nil {
+			t.Fatalf("store: %v", err)
+		}
+	}
+	store(day(40, 10), 50, 0) // outside the window
+	store(day(20, 10), 30, 0)
+	store(day(20, 11), 35, 2)
+	store(day(2, 10), 40, 8)
+
+	// Roll the older day up; its points must come from the daily tables
+	if _, err := DownsampleOldData(db, 10, 10, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := GetSparklines(db, []string{"temp", "5", "197"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := lines["nas01:SER1"]
+	if temps := got["temp"]; len(temps) != 2 || temps[0] != 32.5 || temps[1] != 40 {
+		t.Errorf("temp sparkline = %v, want [32.5 40]", temps)
+	}
+	if realloc := got["5"]; len(realloc) != 2 || realloc[0] != 2 || realloc[1] != 8 {
+		t.Errorf("attribute 5 sparkline = %v, want [2 8]", realloc)
+	}
+	if _, ok := got["197"]; ok {
+		t.Errorf("expected no series for an attribute the drive never reported, got %v", got["197"])
+	}
+}
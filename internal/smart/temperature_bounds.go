@@ -0,0 +1,33 @@
+package smart
+
+import (
+	"database/sql"
+
+	"vigil/internal/settings"
+)
+
+// StageTemperature marks a temperature reading discarded at ingestion for
+// falling outside TemperatureBounds. The drive's attributes were stored.
+const StageTemperature = "temperature"
+
+// TemperatureBounds are the sanity limits a reported temperature must fall
+// within, inclusive, to be stored or alerted on. Readings outside them are
+// sensor glitches (0, 255, 127 and the like) that would otherwise skew
+// averages and fire false spike alerts.
+type TemperatureBounds struct {
+	Min int
+	Max int
+}
+
+// LoadTemperatureBounds reads temperature/min_valid and temperature/max_valid.
+func LoadTemperatureBounds(db *sql.DB) TemperatureBounds {
+	return TemperatureBounds{
+		Min: settings.GetInt(db, "temperature", "min_valid", 5),
+		Max: settings.GetInt(db, "temperature", "max_valid", 100),
+	}
+}
+
+// Valid reports whether temp is within the bounds.
+func (b TemperatureBounds) Valid(temp int) bool {
+	return temp >= b.Min && temp <= b.Max
+}
@@ -0,0 +1,136 @@
+package smart
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	agentsmart "vigil/cmd/agent/smart"
+	vigildb "vigil/internal/db"
+	"vigil/internal/settings"
+)
+
+// TemperatureSampling thins temperature history at ingestion: a drive
+// stores at most one reading per interval, set per drive type
+// (temperature/sample_interval_hdd, _ssd, _nvme) or per drive (see
+// SetDriveSampleInterval). Readings that matter for alerting are stored
+// whatever the interval: any at or above the warning threshold, any that
+// crosses a threshold relative to the last stored reading, and any that
+// moves by the spike threshold or more.
+type TemperatureSampling struct {
+	ByType   map[string]time.Duration
+	Warning  int
+	Critical int
+	Spike    int
+}
+
+// LoadTemperatureSampling reads the sampling intervals and the alert
+// thresholds that bypass them.
+func LoadTemperatureSampling(db *sql.DB) TemperatureSampling {
+	seconds := func(key string) time.Duration {
+		return time.Duration(settings.GetInt(db, "temperature", key, 0)) * time.Second
+	}
+	return TemperatureSampling{
+		ByType: map[string]time.Duration{
+			agentsmart.DriveTypeHDD:  seconds("sample_interval_hdd"),
+			agentsmart.DriveTypeSSD:  seconds("sample_interval_ssd"),
+			agentsmart.DriveTypeNVMe: seconds("sample_interval_nvme"),
+		},
+		Warning:  settings.GetInt(db, "temperature", "warning_threshold", 45),
+		Critical: settings.GetInt(db, "temperature", "critical_threshold", 55),
+		Spike:    settings.GetInt(db, "temperature", "spike_threshold", 10),
+	}
+}
+
+// interval returns the drive's sampling interval: its own override if it
+// has one, else its drive type's.
+func (s TemperatureSampling) interval(tx *sql.Tx, d *agentsmart.DriveSmartData) time.Duration {
+	var secs int
+	err := tx.QueryRow(
+		`SELECT interval_seconds FROM drive_temperature_sampling WHERE hostname = ? AND serial_number = ?`,
+		d.Hostname, d.SerialNumber,
+	).Scan(&secs)
+	if err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	return s.ByType[d.DriveType]
+}
+
+// shouldStore reports whether a reading of temp at timestamp is stored,
+// given the drive's last stored reading at or before it.
+func (s TemperatureSampling) shouldStore(tx *sql.Tx, d *agentsmart.DriveSmartData, temp int, timestamp string) (bool, error) {
+	interval := s.interval(tx, d)
+	if interval <= 0 || (s.Warning > 0 && temp >= s.Warning) {
+		return true, nil
+	}
+
+	var lastTemp int
+	var lastAt string
+	err := tx.QueryRow(`
+		SELECT temperature, timestamp FROM temperature_history
+		WHERE hostname = ? AND serial_number = ? AND timestamp <= ?
+		ORDER BY timestamp DESC
+		LIMIT 1
+	`, d.Hostname, d.SerialNumber, timestamp).Scan(&lastTemp, &lastAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true, nil
+	}
+	if err != nil {
+		return true, err
+	}
+
+	if s.band(temp) != s.band(lastTemp) {
+		return true, nil
+	}
+	if delta := temp - lastTemp; s.Spike > 0 && (delta >= s.Spike || -delta >= s.Spike) {
+		return true, nil
+	}
+	return vigildb.ParseTime(timestamp).Sub(vigildb.ParseTime(lastAt)) >= interval, nil
+}
+
+// band places temp relative to the alert thresholds: 0 below warning, 1
+// at or above warning, 2 at or above critical.
+func (s TemperatureSampling) band(temp int) int {
+	switch {
+	case s.Critical > 0 && temp >= s.Critical:
+		return 2
+	case s.Warning > 0 && temp >= s.Warning:
+		return 1
+	}
+	return 0
+}
+
+// DriveSampleInterval returns the drive's own temperature sampling
+// interval in seconds, and false if it has none and follows its drive type.
+func DriveSampleInterval(db *sql.DB, hostname, serial string) (int, bool, error) {
+	var secs int
+	err := db.QueryRow(
+		`SELECT interval_seconds FROM drive_temperature_sampling WHERE hostname = ? AND serial_number = ?`,
+		hostname, serial,
+	).Scan(&secs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return secs, true, nil
+}
+
+// SetDriveSampleInterval gives the drive its own temperature sampling
+// interval in seconds (0 keeps every reading). nil removes the override so
+// the drive follows its drive type again.
+func SetDriveSampleInterval(db *sql.DB, hostname, serial string, seconds *int) error {
+	if seconds == nil {
+		_, err := db.Exec(`DELETE FROM drive_temperature_sampling WHERE hostname = ? AND serial_number = ?`, hostname, serial)
+		return err
+	}
+	_, err := db.Exec(`
+		INSERT INTO drive_temperature_sampling (hostname, serial_number, interval_seconds) VALUES (?, ?, ?)
+		ON CONFLICT(hostname, serial_number) DO UPDATE SET
+			interval_seconds = excluded.interval_seconds,
+			updated_at = CURRENT_TIMESTAMP`,
+		hostname, serial, *seconds,
+	)
+	return err
+}
@@ -0,0 +1,142 @@
+package smart
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	agentsmart "vigil/cmd/agent/smart"
+)
+
+func TestTemperatureSamplingDropsIntermediateReadings(t *testing.T) {
+	db := setupSmartTestDB(t)
+	interval := 300
+	if err := SetDriveSampleInterval(db, "nas01", "ARCH1", &interval); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	readings := []struct {
+		offset time.Duration
+		temp   int
+		stored bool
+	}{
+		{0, 30, true},                 // first reading
+		{1 * time.Minute, 31, false},  // within the interval
+		{2 * time.Minute, 32, false},  // within the interval
+		{3 * time.Minute, 46, true},   // crosses the warning threshold
+		{4 * time.Minute, 47, true},   // at or above warning
+		{5 * time.Minute, 40, true},   // back below warning
+		{6 * time.Minute, 41, false},  // within the interval
+		{10 * time.Minute, 41, true},  // interval elapsed
+		{11 * time.Minute, 29, true},  // spike
+		{12 * time.Minute, 30, false}, // within the interval
+		{17 * time.Minute, 30, true},  // interval elapsed
+		{17*time.Minute + 30*time.Second, 30, false},
+	}
+	var want []int
+	for _, r := range readings {
+		err := StoreSmartAttributes(db, &agentsmart.DriveSmartData{
+			Hostname:     "nas01",
+			SerialNumber: "ARCH1",
+			DeviceName:   "/dev/sda",
+			DriveType:    agentsmart.DriveTypeHDD,
+			Temperature:  r.temp,
+			Timestamp:    start.Add(r.offset),
+			Attributes: []agentsmart.SmartAttribute{
+				{ID: 194, Name: "Temperature_Celsius", Value: 100, RawValue: int64(r.temp)},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.stored {
+			want = append(want, r.temp)
+		}
+	}
+
+	got := storedTemperatures(t, db, "ARCH1")
+	if len(got) != len(want) {
+		t.Fatalf("expected temperatures %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected temperatures %v, got %v", want, got)
+		}
+	}
+	if n := countSamples(t, db, 194); n != len(readings) {
+		t.Errorf("expected every attribute sample stored, got %d", n)
+	}
+
+	// Without the override the drive follows its type, unsampled by default.
+	if err := SetDriveSampleInterval(db, "nas01", "ARCH1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := DriveSampleInterval(db, "nas01", "ARCH1"); ok {
+		t.Error("expected the override removed")
+	}
+	err := StoreSmartAttributes(db, &agentsmart.DriveSmartData{
+		Hostname:     "nas01",
+		SerialNumber: "ARCH1",
+		DriveType:    agentsmart.DriveTypeHDD,
+		Temperature:  31,
+		Timestamp:    start.Add(18 * time.Minute),
+		Attributes:   []agentsmart.SmartAttribute{{ID: 194, Name: "Temperature_Celsius", Value: 100, RawValue: 31}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := storedTemperatures(t, db, "ARCH1"); len(got) != len(want)+1 {
+		t.Errorf("expected the reading stored without sampling, got %v", got)
+	}
+}
+
+func TestTemperatureSamplingByDriveType(t *testing.T) {
+	db := setupSmartTestDB(t)
+	sampling := TemperatureSampling{
+		ByType:   map[string]time.Duration{agentsmart.DriveTypeHDD: 5 * time.Minute},
+		Warning:  45,
+		Critical: 55,
+		Spike:    10,
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO temperature_history (hostname, serial_number, temperature, timestamp)
+		VALUES ('nas01', 'HDD1', 30, '2025-01-01 00:00:00'), ('nas01', 'NVME1', 40, '2025-01-01 00:00:00')`); err != nil {
+		t.Fatal(err)
+	}
+	hdd := &agentsmart.DriveSmartData{Hostname: "nas01", SerialNumber: "HDD1", DriveType: agentsmart.DriveTypeHDD}
+	nvme := &agentsmart.DriveSmartData{Hostname: "nas01", SerialNumber: "NVME1", DriveType: agentsmart.DriveTypeNVMe}
+
+	if keep, _ := sampling.shouldStore(tx, hdd, 31, "2025-01-01 00:01:00"); keep {
+		t.Error("expected an HDD reading within the interval dropped")
+	}
+	if keep, _ := sampling.shouldStore(tx, hdd, 31, "2025-01-01 00:05:00"); !keep {
+		t.Error("expected an HDD reading after the interval stored")
+	}
+	if keep, _ := sampling.shouldStore(tx, nvme, 41, "2025-01-01 00:01:00"); !keep {
+		t.Error("expected every NVMe reading stored")
+	}
+}
+
+func storedTemperatures(t *testing.T, db *sql.DB, serial string) []int {
+	t.Helper()
+	rows, err := db.Query(`SELECT temperature FROM temperature_history WHERE serial_number = ? ORDER BY timestamp`, serial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var temps []int
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			t.Fatal(err)
+		}
+		temps = append(temps, v)
+	}
+	return temps
+}
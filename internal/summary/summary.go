@@ -0,0 +1,386 @@
+// Package summary builds the once-daily "what changed" rollup: drives that
+// appeared or went missing, drives whose health changed, ZFS scans that
+// completed and the largest temperature swings. It only reads the existing
+// history tables and never writes.
+package summary
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"vigil/internal/crypto"
+	vigildb "vigil/internal/db"
+)
+
+const timeFormat = "2006-01-02 15:04:05"
+
+// TopTempMovers caps how many temperature movers a summary lists.
+const TopTempMovers = 5
+
+// Summary is the set of changes seen between Since and Until.
+type Summary struct {
+	Since          time.Time       `json:"since"`
+	Until          time.Time       `json:"until"`
+	NewDrives      []DriveRef      `json:"new_drives"`
+	MissingDrives  []DriveRef      `json:"missing_drives"`
+	HealthChanges  []HealthChange  `json:"health_changes"`
+	CompletedScans []CompletedScan `json:"completed_scans"`
+	TempMovers     []TempMover     `json:"temperature_movers"`
+}
+
+// DriveRef identifies a drive on a host. Label is the alias when one is
+// set, otherwise the serial number.
+type DriveRef struct {
+	Hostname     string `json:"hostname"`
+	SerialNumber string `json:"serial_number"`
+	Label        string `json:"label"`
+	Model        string `json:"model,omitempty"`
+}
+
+// HealthChange is a drive whose health differs from the last report
+// received before the window started.
+type HealthChange struct {
+	DriveRef
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// CompletedScan is a scrub, resilver, trim or initialize that finished
+// inside the window.
+type CompletedScan struct {
+	Hostname    string    `json:"hostname"`
+	PoolName    string    `json:"pool_name"`
+	ScanType    string    `json:"scan_type"`
+	ErrorsFound int64     `json:"errors_found"`
+	EndTime     time.Time `json:"end_time"`
+}
+
+// TempMover is the change between a drive's first and last temperature
+// reading inside the window.
+type TempMover struct {
+	DriveRef
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Delta int `json:"delta"`
+}
+
+// Build assembles the summary for the window (since, until].
+func Build(db *sql.DB, since, until time.Time) (*Summary, error) {
+	s := &Summary{Since: since.UTC(), Until: until.UTC()}
+	aliases := loadAliases(db)
+	from := s.Since.Format(timeFormat)
+
+	var err error
+	if s.NewDrives, err = newDrives(db, aliases, from); err != nil {
+		return nil, err
+	}
+	if s.MissingDrives, err = missingDrives(db, aliases, from); err != nil {
+		return nil, err
+	}
+	if s.HealthChanges, err = healthChanges(db, aliases, from); err != nil {
+		return nil, err
+	}
+	if s.CompletedScans, err = completedScans(db, from); err != nil {
+		return nil, err
+	}
+	if s.TempMovers, err = tempMovers(db, aliases, from); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Empty reports whether nothing changed during the window.
+func (s *Summary) Empty() bool {
+	return len(s.NewDrives) == 0 && len(s.MissingDrives) == 0 && len(s.HealthChanges) == 0 &&
+		len(s.CompletedScans) == 0 && len(s.TempMovers) == 0
+}
+
+// Format renders the summary as a plain-text notification message.
+func (s *Summary) Format() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Vigil daily summary (%s to %s UTC)\n",
+		s.Since.Format("2006-01-02 15:04"), s.Until.Format("2006-01-02 15:04"))
+
+	if s.Empty() {
+		b.WriteString("\nNo changes in the last 24 hours.")
+		return b.String()
+	}
+
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s (%d):\n", title, len(lines))
+		for _, l := range lines {
+			fmt.Fprintf(&b, "  • %s\n", l)
+		}
+	}
+
+	var lines []string
+	for _, d := range s.NewDrives {
+		lines = append(lines, d.describe())
+	}
+	section("New drives", lines)
+
+	lines = nil
+	for _, d := range s.MissingDrives {
+		lines = append(lines, d.describe())
+	}
+	section("Missing drives", lines)
+
+	lines = nil
+	for _, h := range s.HealthChanges {
+		lines = append(lines, fmt.Sprintf("%s/%s: %s → %s", h.Hostname, h.Label, h.From, h.To))
+	}
+	section("Health changes", lines)
+
+	lines = nil
+	for _, c := range s.CompletedScans {
+		lines = append(lines, fmt.Sprintf("%s/%s: %s, %d errors", c.Hostname, c.PoolName, c.ScanType, c.ErrorsFound))
+	}
+	section("Completed scans", lines)
+
+	lines = nil
+	for _, t := range s.TempMovers {
+		lines = append(lines, fmt.Sprintf("%s/%s: %d°C → %d°C (%+d)", t.Hostname, t.Label, t.From, t.To, t.Delta))
+	}
+	section("Temperature movers", lines)
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func (d DriveRef) describe() string {
+	if d.Model != "" {
+		return fmt.Sprintf("%s/%s (%s)", d.Hostname, d.Label, d.Model)
+	}
+	return d.Hostname + "/" + d.Label
+}
+
+// ─── Queries ─────────────────────────────────────────────────────────────────
+
+func newDrives(db *sql.DB, aliases map[string]string, from string) ([]DriveRef, error) {
+	return presenceQuery(db, aliases, `
+		SELECT hostname, serial_number, COALESCE(model, '')
+		FROM drive_presence
+		WHERE first_seen >= ?
+		ORDER BY hostname, serial_number`, from)
+}
+
+// missingDrives excludes drives that were relocated to another host; those
+// show up under new drives on their new host instead.
+func missingDrives(db *sql.DB, aliases map[string]string, from string) ([]DriveRef, error) {
+	return presenceQuery(db, aliases, `
+		SELECT hostname, serial_number, COALESCE(model, '')
+		FROM drive_presence
+		WHERE missing_since IS NOT NULL AND missing_since >= ?
+		  AND COALESCE(relocated_to, '') = ''
+		ORDER BY hostname, serial_number`, from)
+}
+
+func presenceQuery(db *sql.DB, aliases map[string]string, query, from string) ([]DriveRef, error) {
+	rows, err := db.Query(query, from)
+	if err != nil {
+		return nil, fmt.Errorf("query drive presence: %w", err)
+	}
+	defer rows.Close()
+
+	drives := []DriveRef{}
+	for rows.Next() {
+		var d DriveRef
+		if err := rows.Scan(&d.Hostname, &d.SerialNumber, &d.Model); err != nil {
+			return nil, fmt.Errorf("scan drive presence: %w", err)
+		}
+		d.Label = label(aliases, d.Hostname, d.SerialNumber)
+		drives = append(drives, d)
+	}
+	return drives, rows.Err()
+}
+
+// healthChanges compares each host's latest report with the last report it
+// sent before the window. Health is the SMART self-assessment, the same
+// fallback the drive inventory uses.
+func healthChanges(db *sql.DB, aliases map[string]string, from string) ([]HealthChange, error) {
+	rows, err := db.Query(`
+		SELECT r.hostname,
+		       (SELECT b.data FROM reports b
+		        WHERE b.hostname = r.hostname AND b.timestamp < ?
+		        ORDER BY b.timestamp DESC LIMIT 1),
+		       r.data
+		FROM reports r
+		JOIN (SELECT id FROM (
+		        SELECT id, ROW_NUMBER() OVER (PARTITION BY hostname ORDER BY timestamp DESC, id DESC) AS rn
+		        FROM reports) WHERE rn = 1) latest
+		  ON r.id = latest.id
+		WHERE r.timestamp >= ?
+		ORDER BY r.hostname`, from, from)
+	if err != nil {
+		return nil, fmt.Errorf("query reports: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []HealthChange{}
+	for rows.Next() {
+		var host string
+		var before sql.NullString
+		var after string
+		if err := rows.Scan(&host, &before, &after); err != nil {
+			return nil, fmt.Errorf("scan reports: %w", err)
+		}
+		if !before.Valid {
+			continue
+		}
+		was := reportHealth(before.String)
+		now := reportHealth(after)
+
+		serials := make([]string, 0, len(now))
+		for serial := range now {
+			serials = append(serials, serial)
+		}
+		sort.Strings(serials)
+		for _, serial := range serials {
+			prev, ok := was[serial]
+			if !ok || prev == now[serial] {
+				continue
+			}
+			changes = append(changes, HealthChange{
+				DriveRef: DriveRef{Hostname: host, SerialNumber: serial, Label: label(aliases, host, serial)},
+				From:     prev,
+				To:       now[serial],
+			})
+		}
+	}
+	return changes, rows.Err()
+}
+
+// reportHealth maps serial number to "healthy" or "critical" for every
+// drive in a stored report.
+func reportHealth(data string) map[string]string {
+	var report struct {
+		Drives []struct {
+			SerialNumber string `json:"serial_number"`
+			SmartStatus  *struct {
+				Passed *bool `json:"passed"`
+			} `json:"smart_status"`
+		} `json:"drives"`
+	}
+	health := make(map[string]string)
+	raw, err := crypto.OpenReport([]byte(data))
+	if err != nil {
+		return health
+	}
+	if err := json.Unmarshal(raw, &report); err != nil {
+		return health
+	}
+	for _, d := range report.Drives {
+		if d.SerialNumber == "" {
+			continue
+		}
+		health[d.SerialNumber] = "healthy"
+		if d.SmartStatus != nil && d.SmartStatus.Passed != nil && !*d.SmartStatus.Passed {
+			health[d.SerialNumber] = "critical"
+		}
+	}
+	return health
+}
+
+func completedScans(db *sql.DB, from string) ([]CompletedScan, error) {
+	rows, err := db.Query(`
+		SELECT hostname, pool_name, scan_type, COALESCE(errors_found, 0), end_time
+		FROM zfs_scrub_history
+		WHERE state = 'finished' AND end_time IS NOT NULL AND end_time >= ?
+		ORDER BY end_time`, from)
+	if err != nil {
+		return nil, fmt.Errorf("query scrub history: %w", err)
+	}
+	defer rows.Close()
+
+	scans := []CompletedScan{}
+	for rows.Next() {
+		var c CompletedScan
+		var end string
+		if err := rows.Scan(&c.Hostname, &c.PoolName, &c.ScanType, &c.ErrorsFound, &end); err != nil {
+			return nil, fmt.Errorf("scan scrub history: %w", err)
+		}
+		c.EndTime = vigildb.ParseTime(end)
+		scans = append(scans, c)
+	}
+	return scans, rows.Err()
+}
+
+// tempMovers returns the drives whose temperature moved the most between
+// their first and last reading in the window, largest swing first.
+func tempMovers(db *sql.DB, aliases map[string]string, from string) ([]TempMover, error) {
+	rows, err := db.Query(`
+		SELECT d.hostname, d.serial_number,
+		       (SELECT f.temperature FROM temperature_history f
+		        WHERE f.hostname = d.hostname AND f.serial_number = d.serial_number AND f.timestamp >= ?
+		        ORDER BY f.timestamp ASC LIMIT 1),
+		       (SELECT l.temperature FROM temperature_history l
+		        WHERE l.hostname = d.hostname AND l.serial_number = d.serial_number AND l.timestamp >= ?
+		        ORDER BY l.timestamp DESC LIMIT 1)
+		FROM (SELECT DISTINCT hostname, serial_number FROM temperature_history WHERE timestamp >= ?) d`,
+		from, from, from)
+	if err != nil {
+		return nil, fmt.Errorf("query temperature history: %w", err)
+	}
+	defer rows.Close()
+
+	movers := []TempMover{}
+	for rows.Next() {
+		var m TempMover
+		if err := rows.Scan(&m.Hostname, &m.SerialNumber, &m.From, &m.To); err != nil {
+			return nil, fmt.Errorf("scan temperature history: %w", err)
+		}
+		m.Delta = m.To - m.From
+		if m.Delta == 0 {
+			continue
+		}
+		m.Label = label(aliases, m.Hostname, m.SerialNumber)
+		movers = append(movers, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(movers, func(i, j int) bool {
+		ai, aj := math.Abs(float64(movers[i].Delta)), math.Abs(float64(movers[j].Delta))
+		if ai != aj {
+			return ai > aj
+		}
+		return movers[i].Hostname+movers[i].SerialNumber < movers[j].Hostname+movers[j].SerialNumber
+	})
+	if len(movers) > TopTempMovers {
+		movers = movers[:TopTempMovers]
+	}
+	return movers, nil
+}
+
+// ─── Helpers ─────────────────────────────────────────────────────────────────
+
+func loadAliases(db *sql.DB) map[string]string {
+	aliases := make(map[string]string)
+	rows, err := db.Query("SELECT hostname, serial_number, alias FROM drive_aliases")
+	if err != nil {
+		return aliases
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var host, serial, alias string
+		if rows.Scan(&host, &serial, &alias) == nil && alias != "" {
+			aliases[host+":"+serial] = alias
+		}
+	}
+	return aliases
+}
+
+func label(aliases map[string]string, host, serial string) string {
+	if a, ok := aliases[host+":"+serial]; ok {
+		return a
+	}
+	return serial
+}
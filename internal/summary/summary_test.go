@@ -0,0 +1,160 @@
+package summary
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+		CREATE TABLE reports (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			hostname TEXT NOT NULL,
+			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+			data JSON NOT NULL
+		);
+		CREATE TABLE drive_aliases (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			hostname TEXT NOT NULL,
+			serial_number TEXT NOT NULL,
+			alias TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE drive_presence (
+			hostname      TEXT     NOT NULL,
+			serial_number TEXT     NOT NULL,
+			model         TEXT     DEFAULT '',
+			first_seen    DATETIME NOT NULL,
+			last_seen     DATETIME NOT NULL,
+			missing_since DATETIME,
+			relocated_to  TEXT     DEFAULT '',
+			PRIMARY KEY (hostname, serial_number)
+		);
+		CREATE TABLE zfs_scrub_history (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			hostname     TEXT NOT NULL,
+			pool_name    TEXT NOT NULL,
+			scan_type    TEXT NOT NULL DEFAULT 'scrub',
+			state        TEXT NOT NULL,
+			end_time     DATETIME,
+			errors_found INTEGER DEFAULT 0
+		);
+		CREATE TABLE temperature_history (
+			id            INTEGER  PRIMARY KEY AUTOINCREMENT,
+			hostname      TEXT     NOT NULL,
+			serial_number TEXT     NOT NULL,
+			temperature   INTEGER  NOT NULL,
+			timestamp     DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func exec(t *testing.T, db *sql.DB, query string, args ...interface{}) {
+	t.Helper()
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+}
+
+func TestBuildSummary(t *testing.T) {
+	db := setupTestDB(t)
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	ts := func(ago time.Duration) string { return now.Add(-ago).Format(timeFormat) }
+
+	exec(t, db, `INSERT INTO drive_aliases (hostname, serial_number, alias) VALUES ('nas01', 'NEW1', 'cache-ssd')`)
+
+	// Presence: one new drive, one missing, one relocated away, one old
+	exec(t, db, `INSERT INTO drive_presence VALUES ('nas01', 'NEW1', 'Samsung 870', ?, ?, NULL, '')`, ts(2*time.Hour), ts(0))
+	exec(t, db, `INSERT INTO drive_presence VALUES ('nas01', 'GONE1', 'WD Red', ?, ?, ?, '')`, ts(30*24*time.Hour), ts(5*time.Hour), ts(4*time.Hour))
+	exec(t, db, `INSERT INTO drive_presence VALUES ('nas01', 'MOVED1', 'WD Red', ?, ?, ?, 'nas02')`, ts(30*24*time.Hour), ts(5*time.Hour), ts(4*time.Hour))
+	exec(t, db, `INSERT INTO drive_presence VALUES ('nas01', 'OLD1', 'WD Red', ?, ?, NULL, '')`, ts(30*24*time.Hour), ts(0))
+
+	// Reports: OLD1 fails SMART inside the window
+	exec(t, db, `INSERT INTO reports (hostname, timestamp, data) VALUES ('nas01', ?, ?)`, ts(26*time.Hour),
+		`{"drives":[{"serial_number":"OLD1","smart_status":{"passed":true}}]}`)
+	exec(t, db, `INSERT INTO reports (hostname, timestamp, data) VALUES ('nas01', ?, ?)`, ts(time.Hour),
+		`{"drives":[{"serial_number":"OLD1","smart_status":{"passed":false}},{"serial_number":"NEW1","smart_status":{"passed":true}}]}`)
+	// Imported after them, an older report doesn't become the latest
+	exec(t, db, `INSERT INTO reports (hostname, timestamp, data) VALUES ('nas01', ?, ?)`, ts(48*time.Hour),
+		`{"drives":[{"serial_number":"OLD1","smart_status":{"passed":true}}]}`)
+
+	// Scrub history: one finished in window, one before, one still running
+	exec(t, db, `INSERT INTO zfs_scrub_history (hostname, pool_name, scan_type, state, end_time, errors_found) VALUES ('nas01', 'tank', 'scrub', 'finished', ?, 2)`, ts(3*time.Hour))
+	exec(t, db, `INSERT INTO zfs_scrub_history (hostname, pool_name, scan_type, state, end_time) VALUES ('nas01', 'tank', 'scrub', 'finished', ?)`, ts(48*time.Hour))
+	exec(t, db, `INSERT INTO zfs_scrub_history (hostname, pool_name, scan_type, state) VALUES ('nas01', 'flash', 'trim', 'scanning')`)
+
+	// Temperatures: NEW1 warms by 9, OLD1 steady, old reading outside window ignored
+	for _, r := range []struct {
+		serial string
+		temp   int
+		ago    time.Duration
+	}{
+		{"NEW1", 20, 30 * time.Hour},
+		{"NEW1", 32, 20 * time.Hour},
+		{"NEW1", 41, time.Hour},
+		{"OLD1", 38, 20 * time.Hour},
+		{"OLD1", 38, time.Hour},
+	} {
+		exec(t, db, `INSERT INTO temperature_history (hostname, serial_number, temperature, timestamp) VALUES ('nas01', ?, ?, ?)`, r.serial, r.temp, ts(r.ago))
+	}
+
+	s, err := Build(db, now.Add(-24*time.Hour), now)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	if len(s.NewDrives) != 1 || s.NewDrives[0].Label != "cache-ssd" {
+		t.Errorf("new drives = %+v, want NEW1 labelled by alias", s.NewDrives)
+	}
+	if len(s.MissingDrives) != 1 || s.MissingDrives[0].SerialNumber != "GONE1" {
+		t.Errorf("missing drives = %+v, want only GONE1", s.MissingDrives)
+	}
+	if len(s.HealthChanges) != 1 || s.HealthChanges[0].SerialNumber != "OLD1" ||
+		s.HealthChanges[0].From != "healthy" || s.HealthChanges[0].To != "critical" {
+		t.Errorf("health changes = %+v, want OLD1 healthy → critical", s.HealthChanges)
+	}
+	if len(s.CompletedScans) != 1 || s.CompletedScans[0].ErrorsFound != 2 {
+		t.Errorf("completed scans = %+v, want the one in-window scrub", s.CompletedScans)
+	}
+	if len(s.TempMovers) != 1 || s.TempMovers[0].SerialNumber != "NEW1" || s.TempMovers[0].Delta != 9 {
+		t.Errorf("temp movers = %+v, want NEW1 +9", s.TempMovers)
+	}
+
+	msg := s.Format()
+	for _, want := range []string{"New drives (1)", "nas01/cache-ssd (Samsung 870)", "healthy → critical", "tank: scrub, 2 errors", "32°C → 41°C (+9)"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message missing %q:\n%s", want, msg)
+		}
+	}
+}
+
+func TestBuildSummaryEmpty(t *testing.T) {
+	db := setupTestDB(t)
+	now := time.Now()
+
+	s, err := Build(db, now.Add(-24*time.Hour), now)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if !s.Empty() {
+		t.Errorf("expected empty summary, got %+v", s)
+	}
+	if !strings.Contains(s.Format(), "No changes") {
+		t.Errorf("unexpected message: %s", s.Format())
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"vigil/internal/drivealerts"
+	"vigil/internal/events"
 	"vigil/internal/settings"
 )
 
@@ -24,6 +26,7 @@ type TemperatureAlert struct {
 	DeviceName     string    `json:"device_name,omitempty"`
 	Model          string    `json:"model,omitempty"`
 	AlertType      string    `json:"alert_type"` // warning, critical, spike, recovery
+	Category       string    `json:"category"`   // an events.Cause; thermal
 	Temperature    int       `json:"temperature"`
 	Threshold      int       `json:"threshold,omitempty"`
 	Message        string    `json:"message"`
@@ -31,6 +34,11 @@ type TemperatureAlert struct {
 	AcknowledgedBy string    `json:"acknowledged_by,omitempty"`
 	AcknowledgedAt time.Time `json:"acknowledged_at,omitempty"`
 	CreatedAt      time.Time `json:"created_at"`
+
+	// OccurrenceCount is how many times the alert fired; repeats within
+	// alerts.dedup_window_minutes update the alert instead of adding one.
+	OccurrenceCount int       `json:"occurrence_count"`
+	LastOccurred    time.Time `json:"last_occurred"`
 }
 
 // AlertSummary holds alert statistics
@@ -51,6 +59,7 @@ type AlertFilter struct {
 	Hostname     string
 	SerialNumber string
 	AlertType    string
+	Category     string
 	Acknowledged *bool
 	Since        time.Time
 	Limit        int
@@ -92,16 +101,39 @@ func InitTemperatureAlertsTable(db *sql.DB) error {
 		return fmt.Errorf("failed to create temperature_alerts table: %w", err)
 	}
 
+	// These columns were added after the table shipped.
+	for _, col := range []struct{ name, def string }{
+		{"last_escalated_at", "DATETIME"},
+		{"occurrence_count", "INTEGER NOT NULL DEFAULT 1"},
+		{"last_occurred", "DATETIME"},
+		{"category", "TEXT NOT NULL DEFAULT 'thermal'"},
+	} {
+		var count int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('temperature_alerts') WHERE name = ?`, col.name).Scan(&count); err != nil {
+			return fmt.Errorf("failed to inspect temperature_alerts: %w", err)
+		}
+		if count > 0 {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE temperature_alerts ADD COLUMN ` + col.name + ` ` + col.def); err != nil {
+			return fmt.Errorf("failed to add %s: %w", col.name, err)
+		}
+	}
+
 	return nil
 }
 
-// CreateAlert saves a new temperature alert
+// CreateAlert saves a new temperature alert. Its category defaults to
+// thermal.
 func CreateAlert(db *sql.DB, alert *TemperatureAlert) error {
+	if alert.Category == "" {
+		alert.Category = string(events.CauseThermal)
+	}
 	query := `
 		INSERT INTO temperature_alerts (
 			hostname, serial_number, alert_type, temperature,
-			threshold, message
-		) VALUES (?, ?, ?, ?, ?, ?)
+			threshold, message, category
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := db.Exec(query,
@@ -111,6 +143,7 @@ func CreateAlert(db *sql.DB, alert *TemperatureAlert) error {
 		alert.Temperature,
 		alert.Threshold,
 		alert.Message,
+		alert.Category,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create alert: %w", err)
@@ -120,16 +153,77 @@ func CreateAlert(db *sql.DB, alert *TemperatureAlert) error {
 	if err == nil {
 		alert.ID = id
 	}
+	alert.OccurrenceCount = 1
 
 	return nil
 }
 
+// RecordAlert saves alert, unless the drive already has an open alert of the
+// same type that last fired within window: then that alert's occurrence
+// count, last occurrence, temperature and message are updated instead and
+// alert is filled in from it. An alert is open while it is unacknowledged
+// and no recovery was recorded for the drive after it. Recovery alerts and
+// a window of zero always create a new alert.
+func RecordAlert(db *sql.DB, alert *TemperatureAlert, window time.Duration) error {
+	if window <= 0 || alert.AlertType == AlertTypeRecovery {
+		return CreateAlert(db, alert)
+	}
+
+	now := time.Now().UTC()
+	cutoff := now.Add(-window).Format("2006-01-02 15:04:05")
+	var id int64
+	err := db.QueryRow(`
+		SELECT a.id FROM temperature_alerts a
+		WHERE a.hostname = ? AND a.serial_number = ? AND a.alert_type = ?
+		  AND a.acknowledged = 0
+		  AND COALESCE(a.last_occurred, a.created_at) >= ?
+		  AND NOT EXISTS (
+			SELECT 1 FROM temperature_alerts r
+			WHERE r.hostname = a.hostname AND r.serial_number = a.serial_number
+			  AND r.alert_type = ? AND r.id > a.id
+		  )
+		ORDER BY a.id DESC
+		LIMIT 1
+	`, alert.Hostname, alert.SerialNumber, alert.AlertType, cutoff, AlertTypeRecovery).Scan(&id)
+	if err == sql.ErrNoRows {
+		return CreateAlert(db, alert)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find open alert: %w", err)
+	}
+
+	_, err = db.Exec(`
+		UPDATE temperature_alerts
+		SET occurrence_count = occurrence_count + 1, last_occurred = ?,
+		    temperature = ?, threshold = ?, message = ?
+		WHERE id = ?
+	`, now.Format("2006-01-02 15:04:05"), alert.Temperature, alert.Threshold, alert.Message, id)
+	if err != nil {
+		return fmt.Errorf("failed to update alert: %w", err)
+	}
+
+	updated, err := GetAlertByID(db, id)
+	if err != nil {
+		return err
+	}
+	if updated != nil {
+		*alert = *updated
+	}
+	return nil
+}
+
+// alertDedupWindow is alerts.dedup_window_minutes.
+func alertDedupWindow(db *sql.DB) time.Duration {
+	return time.Duration(settings.GetIntSettingWithDefault(db, "alerts", "dedup_window_minutes", 0)) * time.Minute
+}
+
 // GetAlerts retrieves alerts based on filter criteria
 func GetAlerts(db *sql.DB, filter AlertFilter) ([]TemperatureAlert, error) {
 	query := `
 		SELECT id, hostname, serial_number, alert_type, temperature,
 			   COALESCE(threshold, 0), message, acknowledged,
-			   COALESCE(acknowledged_by, ''), acknowledged_at, created_at
+			   COALESCE(acknowledged_by, ''), acknowledged_at, created_at,
+			   occurrence_count, last_occurred, category
 		FROM temperature_alerts
 		WHERE 1=1
 	`
@@ -150,6 +244,11 @@ func GetAlerts(db *sql.DB, filter AlertFilter) ([]TemperatureAlert, error) {
 		args = append(args, filter.AlertType)
 	}
 
+	if filter.Category != "" {
+		query += " AND category = ?"
+		args = append(args, filter.Category)
+	}
+
 	if filter.Acknowledged != nil {
 		if *filter.Acknowledged {
 			query += " AND acknowledged = 1"
@@ -184,7 +283,8 @@ func GetAlertByID(db *sql.DB, id int64) (*TemperatureAlert, error) {
 	query := `
 		SELECT id, hostname, serial_number, alert_type, temperature,
 			   COALESCE(threshold, 0), message, acknowledged,
-			   COALESCE(acknowledged_by, ''), acknowledged_at, created_at
+			   COALESCE(acknowledged_by, ''), acknowledged_at, created_at,
+			   occurrence_count, last_occurred, category
 		FROM temperature_alerts
 		WHERE id = ?
 	`
@@ -238,6 +338,45 @@ func AcknowledgeAllAlerts(db *sql.DB, username string) (int64, error) {
 	return result.RowsAffected()
 }
 
+// AcknowledgeAlerts marks the unacknowledged alerts matching filter as
+// acknowledged. Hostname, SerialNumber, AlertType and Since are applied;
+// Acknowledged and Limit are ignored. Returns the number acknowledged.
+func AcknowledgeAlerts(db *sql.DB, filter AlertFilter, username string) (int64, error) {
+	query := `
+		UPDATE temperature_alerts
+		SET acknowledged = 1, acknowledged_by = ?, acknowledged_at = CURRENT_TIMESTAMP
+		WHERE acknowledged = 0
+	`
+	args := []interface{}{username}
+
+	if filter.Hostname != "" {
+		query += " AND hostname = ?"
+		args = append(args, filter.Hostname)
+	}
+
+	if filter.SerialNumber != "" {
+		query += " AND serial_number = ?"
+		args = append(args, filter.SerialNumber)
+	}
+
+	if filter.AlertType != "" {
+		query += " AND alert_type = ?"
+		args = append(args, filter.AlertType)
+	}
+
+	if !filter.Since.IsZero() {
+		query += " AND created_at >= ?"
+		args = append(args, filter.Since)
+	}
+
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acknowledge alerts: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
 // DeleteAlert removes an alert
 func DeleteAlert(db *sql.DB, id int64) error {
 	result, err := db.Exec("DELETE FROM temperature_alerts WHERE id = ?", id)
@@ -297,13 +436,51 @@ func GetAlertsByDrive(db *sql.DB, hostname, serial string, limit int) ([]Tempera
 	})
 }
 
-// CleanupOldAlerts removes alerts older than retention period
+// AlertsDueForEscalation returns unacknowledged critical alerts that have
+// gone unanswered for at least after: created (or last escalated) before
+// now-after, with no recovery recorded for the drive since. Oldest first.
+func AlertsDueForEscalation(db *sql.DB, after time.Duration, now time.Time) ([]TemperatureAlert, error) {
+	cutoff := now.Add(-after).UTC().Format("2006-01-02 15:04:05")
+	query := `
+		SELECT a.id, a.hostname, a.serial_number, a.alert_type, a.temperature,
+			   COALESCE(a.threshold, 0), a.message, a.acknowledged,
+			   COALESCE(a.acknowledged_by, ''), a.acknowledged_at, a.created_at,
+			   a.occurrence_count, a.last_occurred, a.category
+		FROM temperature_alerts a
+		WHERE a.acknowledged = 0 AND a.alert_type = ?
+		  AND COALESCE(a.last_escalated_at, a.created_at) <= ?
+		  AND NOT EXISTS (
+			SELECT 1 FROM temperature_alerts r
+			WHERE r.hostname = a.hostname AND r.serial_number = a.serial_number
+			  AND r.alert_type = ? AND r.created_at >= a.created_at
+		  )
+		ORDER BY a.created_at ASC
+	`
+	return queryAlerts(db, query, AlertTypeCritical, cutoff, AlertTypeRecovery)
+}
+
+// MarkAlertEscalated records when an alert was last escalated so the next
+// escalation waits another full period.
+func MarkAlertEscalated(db *sql.DB, id int64, at time.Time) error {
+	_, err := db.Exec(`UPDATE temperature_alerts SET last_escalated_at = ? WHERE id = ?`,
+		at.UTC().Format("2006-01-02 15:04:05"), id)
+	if err != nil {
+		return fmt.Errorf("failed to mark alert %d escalated: %w", id, err)
+	}
+	return nil
+}
+
+// CleanupOldAlerts removes alerts older than retention period.
+// A retentionDays of 0 or less is a no-op ("keep forever").
 func CleanupOldAlerts(db *sql.DB, retentionDays int) (int64, error) {
+	if retentionDays <= 0 {
+		return 0, nil
+	}
 	cutoff := time.Now().AddDate(0, 0, -retentionDays)
 
 	result, err := db.Exec(`
 		DELETE FROM temperature_alerts
-		WHERE created_at < ?
+		WHERE COALESCE(last_occurred, created_at) < ?
 	`, cutoff)
 	if err != nil {
 		return 0, fmt.Errorf("failed to cleanup old alerts: %w", err)
@@ -323,13 +500,14 @@ func queryAlerts(db *sql.DB, query string, args ...interface{}) ([]TemperatureAl
 	var alerts []TemperatureAlert
 	for rows.Next() {
 		var alert TemperatureAlert
-		var ackAt sql.NullTime
+		var ackAt, lastOccurred sql.NullTime
 
 		err := rows.Scan(
 			&alert.ID, &alert.Hostname, &alert.SerialNumber,
 			&alert.AlertType, &alert.Temperature, &alert.Threshold,
 			&alert.Message, &alert.Acknowledged, &alert.AcknowledgedBy,
-			&ackAt, &alert.CreatedAt,
+			&ackAt, &alert.CreatedAt, &alert.OccurrenceCount, &lastOccurred,
+			&alert.Category,
 		)
 		if err != nil {
 			continue
@@ -338,6 +516,10 @@ func queryAlerts(db *sql.DB, query string, args ...interface{}) ([]TemperatureAl
 		if ackAt.Valid {
 			alert.AcknowledgedAt = ackAt.Time
 		}
+		alert.LastOccurred = alert.CreatedAt
+		if lastOccurred.Valid {
+			alert.LastOccurred = lastOccurred.Time
+		}
 
 		// Get drive info
 		driveInfo, _ := getDriveInfo(db, alert.Hostname, alert.SerialNumber)
@@ -356,18 +538,37 @@ func queryAlerts(db *sql.DB, query string, args ...interface{}) ([]TemperatureAl
 // ALERT GENERATION LOGIC
 // ============================================
 
-// AlertState tracks the current alert state for a drive
-type AlertState struct {
-	DriveKey      string    // "hostname:serial"
-	LastAlertType string    // Last alert type generated
-	LastAlertTime time.Time // When last alert was generated
-	InAlertState  bool      // Currently in alert state
+// lastThresholdAlert returns the type and last occurrence of the drive's
+// latest warning, critical or recovery alert; an empty type if it has none.
+// It is the drive's alert state: a warning or critical means it is still
+// hot as far as alerting is concerned.
+func lastThresholdAlert(db *sql.DB, hostname, serial string) (string, time.Time, error) {
+	var alertType string
+	var createdAt time.Time
+	var lastOccurred sql.NullTime
+	err := db.QueryRow(`
+		SELECT alert_type, created_at, last_occurred
+		FROM temperature_alerts
+		WHERE hostname = ? AND serial_number = ? AND alert_type != ?
+		ORDER BY id DESC
+		LIMIT 1
+	`, hostname, serial, AlertTypeSpike).Scan(&alertType, &createdAt, &lastOccurred)
+	if err == sql.ErrNoRows {
+		return "", time.Time{}, nil
+	}
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get last alert: %w", err)
+	}
+	if lastOccurred.Valid {
+		return alertType, lastOccurred.Time, nil
+	}
+	return alertType, createdAt, nil
 }
 
-// alertStateCache stores recent alert states to prevent duplicates
-var alertStateCache = make(map[string]*AlertState)
-
-// CheckTemperatureAndAlert checks temperature against thresholds and generates alerts
+// CheckTemperatureAndAlert checks temperature against thresholds and generates alerts.
+// A repeat of the drive's last alert is folded into it by RecordAlert when
+// alerts.dedup_window_minutes is set; otherwise it is dropped while within
+// alerts.cooldown_minutes of that alert.
 func CheckTemperatureAndAlert(db *sql.DB, hostname, serial string, temperature int) (*TemperatureAlert, error) {
 	// Get thresholds from settings
 	warningThreshold := settings.GetIntSettingWithDefault(db, "temperature", "warning_threshold", 45)
@@ -376,53 +577,52 @@ func CheckTemperatureAndAlert(db *sql.DB, hostname, serial string, temperature i
 	alertsEnabled := settings.GetBoolSettingWithDefault(db, "alerts", "enabled", true)
 	recoveryEnabled := settings.GetBoolSettingWithDefault(db, "alerts", "recovery_enabled", true)
 
-	if !alertsEnabled {
+	if !alertsEnabled || !drivealerts.Enabled(db, hostname, serial) {
 		return nil, nil
 	}
 
-	driveKey := fmt.Sprintf("%s:%s", hostname, serial)
-	state := alertStateCache[driveKey]
-	if state == nil {
-		state = &AlertState{DriveKey: driveKey}
-		alertStateCache[driveKey] = state
+	lastType, lastAt, err := lastThresholdAlert(db, hostname, serial)
+	if err != nil {
+		return nil, err
 	}
-
-	now := time.Now()
-	cooldown := time.Duration(cooldownMinutes) * time.Minute
+	inAlertState := lastType == AlertTypeWarning || lastType == AlertTypeCritical
 
 	// Determine current status
 	var alertType string
 	var threshold int
 	var message string
 
+	// The critical threshold is always fixed; alert_mode only moves the
+	// warning limit onto the drive's learned range.
+	warningThreshold, baseline := warningLimit(db, hostname, serial, warningThreshold)
+
 	if temperature >= criticalThreshold {
 		alertType = AlertTypeCritical
 		threshold = criticalThreshold
 		message = fmt.Sprintf("🔴 Temperature %d°C exceeds critical threshold (%d°C)", temperature, criticalThreshold)
+	} else if temperature >= warningThreshold && baseline != nil {
+		alertType = AlertTypeWarning
+		threshold = warningThreshold
+		message = fmt.Sprintf("⚠️ Temperature %d°C is above this drive's learned range (%.0f–%.0f°C)", temperature, baseline.Low, baseline.High)
 	} else if temperature >= warningThreshold {
 		alertType = AlertTypeWarning
 		threshold = warningThreshold
 		message = fmt.Sprintf("⚠️ Temperature %d°C exceeds warning threshold (%d°C)", temperature, warningThreshold)
-	} else if state.InAlertState && recoveryEnabled {
+	} else if inAlertState && recoveryEnabled {
 		// Temperature returned to normal - generate recovery alert
 		alertType = AlertTypeRecovery
 		threshold = warningThreshold
 		message = fmt.Sprintf("✅ Temperature recovered to %d°C (below warning threshold %d°C)", temperature, warningThreshold)
-		state.InAlertState = false
 	} else {
 		// Normal temperature, no alert needed
-		state.InAlertState = false
 		return nil, nil
 	}
 
-	// Check cooldown for non-recovery alerts
-	if alertType != AlertTypeRecovery {
-		// Check if we should generate a new alert
-		if state.LastAlertType == alertType && now.Sub(state.LastAlertTime) < cooldown {
-			// Within cooldown period for same alert type
-			return nil, nil
-		}
-		state.InAlertState = true
+	window := alertDedupWindow(db)
+	if alertType != AlertTypeRecovery && window <= 0 && lastType == alertType &&
+		time.Since(lastAt) < time.Duration(cooldownMinutes)*time.Minute {
+		// Within cooldown period for same alert type
+		return nil, nil
 	}
 
 	// Create alert
@@ -435,21 +635,17 @@ func CheckTemperatureAndAlert(db *sql.DB, hostname, serial string, temperature i
 		Message:      message,
 	}
 
-	if err := CreateAlert(db, alert); err != nil {
+	if err := RecordAlert(db, alert, window); err != nil {
 		return nil, err
 	}
 
-	// Update state
-	state.LastAlertType = alertType
-	state.LastAlertTime = now
-
 	return alert, nil
 }
 
 // CreateSpikeAlert creates an alert for a temperature spike
 func CreateSpikeAlert(db *sql.DB, spike *TemperatureSpike) (*TemperatureAlert, error) {
 	alertsEnabled := settings.GetBoolSettingWithDefault(db, "alerts", "enabled", true)
-	if !alertsEnabled {
+	if !alertsEnabled || !drivealerts.Enabled(db, spike.Hostname, spike.SerialNumber) {
 		return nil, nil
 	}
 
@@ -465,7 +661,7 @@ func CreateSpikeAlert(db *sql.DB, spike *TemperatureSpike) (*TemperatureAlert, e
 		Message:      message,
 	}
 
-	if err := CreateAlert(db, alert); err != nil {
+	if err := RecordAlert(db, alert, alertDedupWindow(db)); err != nil {
 		return nil, err
 	}
 
@@ -534,9 +730,3 @@ func GetDriveAlertStatus(db *sql.DB, hostname, serial string) (string, error) {
 
 	return alertType, nil
 }
-
-// ClearAlertStateCache clears the in-memory alert state cache
-// Useful for testing or after restart
-func ClearAlertStateCache() {
-	alertStateCache = make(map[string]*AlertState)
-}
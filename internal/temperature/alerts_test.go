@@ -7,6 +7,8 @@ import (
 
 	_ "modernc.org/sqlite"
 
+	"vigil/internal/drivealerts"
+	"vigil/internal/events"
 	"vigil/internal/settings"
 )
 
@@ -44,9 +46,6 @@ func setupAlertTestDB(t *testing.T) *sql.DB {
 		t.Fatalf("Failed to initialize alerts table: %v", err)
 	}
 
-	// Clear alert state cache for clean tests
-	ClearAlertStateCache()
-
 	return db
 }
 
@@ -231,6 +230,51 @@ func TestAcknowledgeAllAlerts(t *testing.T) {
 	}
 }
 
+func TestAcknowledgeAlertsByFilter(t *testing.T) {
+	db := setupAlertTestDB(t)
+	defer db.Close()
+
+	for _, a := range []struct{ host, serial, typ string }{
+		{"server1", "SERIAL001", AlertTypeWarning},
+		{"server1", "SERIAL001", AlertTypeCritical},
+		{"server1", "SERIAL002", AlertTypeWarning},
+		{"server2", "SERIAL003", AlertTypeWarning},
+	} {
+		CreateAlert(db, &TemperatureAlert{
+			Hostname:     a.host,
+			SerialNumber: a.serial,
+			AlertType:    a.typ,
+			Temperature:  50,
+			Message:      "Test alert",
+		})
+	}
+
+	// Warnings on server1 only: the critical and server2 stay active.
+	count, err := AcknowledgeAlerts(db, AlertFilter{Hostname: "server1", AlertType: AlertTypeWarning}, "admin")
+	if err != nil {
+		t.Fatalf("AcknowledgeAlerts failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 acknowledged, got %d", count)
+	}
+
+	active, _ := GetActiveAlerts(db)
+	if len(active) != 2 {
+		t.Fatalf("Expected 2 active alerts, got %d", len(active))
+	}
+	for _, a := range active {
+		if a.Hostname == "server1" && a.AlertType == AlertTypeWarning {
+			t.Errorf("Alert %d should have been acknowledged", a.ID)
+		}
+	}
+
+	// Already-acknowledged rows aren't counted again.
+	count, _ = AcknowledgeAlerts(db, AlertFilter{SerialNumber: "SERIAL002"}, "admin")
+	if count != 0 {
+		t.Errorf("Expected 0 acknowledged, got %d", count)
+	}
+}
+
 func TestDeleteAlert(t *testing.T) {
 	db := setupAlertTestDB(t)
 	defer db.Close()
@@ -313,10 +357,38 @@ func TestGetAlertSummary(t *testing.T) {
 	}
 }
 
+func TestGetAlertsByCategory(t *testing.T) {
+	db := setupAlertTestDB(t)
+	defer db.Close()
+
+	hot := &TemperatureAlert{Hostname: "server1", SerialNumber: "SERIAL001", AlertType: AlertTypeWarning, Temperature: 50, Message: "warm"}
+	if err := CreateAlert(db, hot); err != nil {
+		t.Fatalf("CreateAlert failed: %v", err)
+	}
+	if hot.Category != string(events.CauseThermal) {
+		t.Errorf("default category = %q, want thermal", hot.Category)
+	}
+	CreateAlert(db, &TemperatureAlert{Hostname: "server1", SerialNumber: "SERIAL002", AlertType: AlertTypeWarning,
+		Temperature: 40, Message: "other", Category: string(events.CauseInterface)})
+
+	thermal, err := GetAlerts(db, AlertFilter{Category: string(events.CauseThermal)})
+	if err != nil {
+		t.Fatalf("GetAlerts failed: %v", err)
+	}
+	if len(thermal) != 1 || thermal[0].SerialNumber != "SERIAL001" || thermal[0].Category != string(events.CauseThermal) {
+		t.Errorf("thermal alerts = %+v, want only SERIAL001", thermal)
+	}
+	if media, _ := GetAlerts(db, AlertFilter{Category: string(events.CauseMedia)}); len(media) != 0 {
+		t.Errorf("expected no media alerts, got %+v", media)
+	}
+	if all, _ := GetAlerts(db, AlertFilter{}); len(all) != 2 {
+		t.Errorf("expected 2 alerts without a category filter, got %d", len(all))
+	}
+}
+
 func TestCheckTemperatureAndAlert_Warning(t *testing.T) {
 	db := setupAlertTestDB(t)
 	defer db.Close()
-	ClearAlertStateCache()
 
 	// Temperature above warning but below critical
 	alert, err := CheckTemperatureAndAlert(db, "server1", "SERIAL001", 48)
@@ -336,7 +408,6 @@ func TestCheckTemperatureAndAlert_Warning(t *testing.T) {
 func TestCheckTemperatureAndAlert_Critical(t *testing.T) {
 	db := setupAlertTestDB(t)
 	defer db.Close()
-	ClearAlertStateCache()
 
 	// Temperature above critical
 	alert, err := CheckTemperatureAndAlert(db, "server1", "SERIAL001", 60)
@@ -353,10 +424,94 @@ func TestCheckTemperatureAndAlert_Critical(t *testing.T) {
 	}
 }
 
+func TestCheckTemperatureAndAlert_DriveAlertsDisabled(t *testing.T) {
+	db := setupAlertTestDB(t)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	if err := drivealerts.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := drivealerts.SetEnabled(db, "server1", "SERIAL001", false); err != nil {
+		t.Fatal(err)
+	}
+
+	alert, err := CheckTemperatureAndAlert(db, "server1", "SERIAL001", 60)
+	if err != nil {
+		t.Fatalf("CheckTemperatureAndAlert failed: %v", err)
+	}
+	if alert != nil {
+		t.Errorf("Expected no alert for a drive with alerts disabled, got %+v", alert)
+	}
+
+	spike := &TemperatureSpike{Hostname: "server1", SerialNumber: "SERIAL001", StartTemp: 30, EndTemp: 60, Change: 30,
+		StartTime: time.Now().Add(-10 * time.Minute), EndTime: time.Now()}
+	if alert, err := CreateSpikeAlert(db, spike); err != nil || alert != nil {
+		t.Errorf("Expected no spike alert for a drive with alerts disabled, got %+v (%v)", alert, err)
+	}
+
+	// Other drives still alert
+	alert, err = CheckTemperatureAndAlert(db, "server1", "SERIAL002", 60)
+	if err != nil || alert == nil {
+		t.Errorf("Expected an alert for another drive, got %+v (%v)", alert, err)
+	}
+}
+
+func TestProcessReport_DriveAlertsDisabled(t *testing.T) {
+	db := setupAlertTestDB(t)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	if err := drivealerts.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := drivealerts.SetEnabled(db, "server1", "MUTED", false); err != nil {
+		t.Fatal(err)
+	}
+	// Both drives jumped from 30°C, so spike detection fires too.
+	for _, serial := range []string{"MUTED", "LOUD"} {
+		db.Exec(`INSERT INTO temperature_history (hostname, serial_number, temperature, timestamp) VALUES
+			('server1', ?, 30, datetime('now', '-10 minutes')), ('server1', ?, 60, datetime('now'))`, serial, serial)
+	}
+
+	bus := events.NewBus()
+	var published []events.Event
+	bus.Subscribe(func(e events.Event) { published = append(published, e) })
+
+	hot := func(serial string) map[string]interface{} {
+		return map[string]interface{}{
+			"serial_number": serial,
+			"model_name":    "TestHDD",
+			"device":        map[string]interface{}{"name": "/dev/sda"},
+			"temperature":   map[string]interface{}{"current": float64(60)},
+		}
+	}
+	ProcessReport(db, bus, "server1", map[string]interface{}{"drives": []interface{}{hot("MUTED"), hot("LOUD")}})
+
+	count := func(serial string) int {
+		var n int
+		db.QueryRow(`SELECT COUNT(*) FROM temperature_alerts WHERE serial_number = ?`, serial).Scan(&n)
+		return n
+	}
+	if n := count("MUTED"); n != 0 {
+		t.Errorf("expected no alerts for a drive with alerts disabled, got %d", n)
+	}
+	for _, e := range published {
+		if e.SerialNumber == "MUTED" {
+			t.Errorf("expected no events for a drive with alerts disabled, got %+v", e)
+		}
+	}
+	if n := count("LOUD"); n != 2 {
+		t.Errorf("expected critical and spike alerts for the other drive, got %d", n)
+	}
+	if len(published) != 2 {
+		t.Errorf("expected 2 events for the other drive, got %d", len(published))
+	}
+}
+
 func TestCheckTemperatureAndAlert_Normal(t *testing.T) {
 	db := setupAlertTestDB(t)
 	defer db.Close()
-	ClearAlertStateCache()
 
 	// Normal temperature
 	alert, err := CheckTemperatureAndAlert(db, "server1", "SERIAL001", 35)
@@ -372,7 +527,6 @@ func TestCheckTemperatureAndAlert_Normal(t *testing.T) {
 func TestCheckTemperatureAndAlert_Recovery(t *testing.T) {
 	db := setupAlertTestDB(t)
 	defer db.Close()
-	ClearAlertStateCache()
 
 	// First, trigger a warning alert
 	CheckTemperatureAndAlert(db, "server1", "SERIAL001", 50)
@@ -395,7 +549,6 @@ func TestCheckTemperatureAndAlert_Recovery(t *testing.T) {
 func TestCheckTemperatureAndAlert_Cooldown(t *testing.T) {
 	db := setupAlertTestDB(t)
 	defer db.Close()
-	ClearAlertStateCache()
 
 	// Set a very long cooldown for testing
 	settings.UpdateSetting(db, "alerts", "cooldown_minutes", "60")
@@ -413,10 +566,57 @@ func TestCheckTemperatureAndAlert_Cooldown(t *testing.T) {
 	}
 }
 
+func TestCheckTemperatureAndAlert_DedupWindowOverridesCooldown(t *testing.T) {
+	db := setupAlertTestDB(t)
+	defer db.Close()
+
+	settings.UpdateSetting(db, "alerts", "cooldown_minutes", "60")
+	settings.UpdateSetting(db, "alerts", "dedup_window_minutes", "10")
+
+	first, err := CheckTemperatureAndAlert(db, "server1", "SERIAL001", 50)
+	if err != nil || first == nil {
+		t.Fatalf("expected first alert, got %+v (%v)", first, err)
+	}
+	repeat, err := CheckTemperatureAndAlert(db, "server1", "SERIAL001", 51)
+	if err != nil || repeat == nil {
+		t.Fatalf("expected the repeat to be folded into the first alert, got %+v (%v)", repeat, err)
+	}
+	if repeat.ID != first.ID || repeat.OccurrenceCount != 2 {
+		t.Errorf("repeat = id %d count %d, want id %d count 2", repeat.ID, repeat.OccurrenceCount, first.ID)
+	}
+
+	// Only the first occurrence is published.
+	bus := events.NewBus()
+	var published int
+	bus.Subscribe(func(events.Event) { published++ })
+	publishAlert(bus, "server1", "SERIAL001", first)
+	publishAlert(bus, "server1", "SERIAL001", repeat)
+	if published != 1 {
+		t.Errorf("published %d events, want 1", published)
+	}
+}
+
+func TestCheckTemperatureAndAlert_StateFromStoredAlerts(t *testing.T) {
+	db := setupAlertTestDB(t)
+	defer db.Close()
+
+	// A warning recorded earlier, e.g. before a restart, still leads to a
+	// recovery once the drive cools down.
+	CreateAlert(db, &TemperatureAlert{Hostname: "server1", SerialNumber: "SERIAL001",
+		AlertType: AlertTypeWarning, Temperature: 50, Threshold: 45, Message: "warm"})
+
+	alert, err := CheckTemperatureAndAlert(db, "server1", "SERIAL001", 35)
+	if err != nil || alert == nil || alert.AlertType != AlertTypeRecovery {
+		t.Fatalf("expected a recovery alert, got %+v (%v)", alert, err)
+	}
+	if alert, _ := CheckTemperatureAndAlert(db, "server1", "SERIAL001", 35); alert != nil {
+		t.Errorf("expected a single recovery, got another %+v", alert)
+	}
+}
+
 func TestCheckTemperatureAndAlert_Disabled(t *testing.T) {
 	db := setupAlertTestDB(t)
 	defer db.Close()
-	ClearAlertStateCache()
 
 	// Disable alerts
 	settings.UpdateSetting(db, "alerts", "enabled", "false")
@@ -574,3 +774,116 @@ func TestAlertFilter(t *testing.T) {
 		t.Errorf("Expected 1 alert for server1/SERIAL001, got %d", len(alerts))
 	}
 }
+
+func TestAlertsDueForEscalation(t *testing.T) {
+	db := setupAlertTestDB(t)
+	defer db.Close()
+
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	insert := func(serial, alertType string, age time.Duration, acked int) int64 {
+		t.Helper()
+		res, err := db.Exec(`INSERT INTO temperature_alerts
+			(hostname, serial_number, alert_type, temperature, message, acknowledged, created_at)
+			VALUES ('nas01', ?, ?, 60, 'hot', ?, ?)`,
+			serial, alertType, acked, now.Add(-age).Format("2006-01-02 15:04:05"))
+		if err != nil {
+			t.Fatalf("insert alert: %v", err)
+		}
+		id, _ := res.LastInsertId()
+		return id
+	}
+
+	due := insert("HOT", AlertTypeCritical, 2*time.Hour, 0)
+	insert("FRESH", AlertTypeCritical, 10*time.Minute, 0)
+	insert("ACKED", AlertTypeCritical, 2*time.Hour, 1)
+	insert("WARN", AlertTypeWarning, 2*time.Hour, 0)
+	insert("COOLED", AlertTypeCritical, 2*time.Hour, 0)
+	insert("COOLED", AlertTypeRecovery, time.Hour, 0)
+
+	alerts, err := AlertsDueForEscalation(db, 30*time.Minute, now)
+	if err != nil {
+		t.Fatalf("AlertsDueForEscalation: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].ID != due {
+		t.Fatalf("expected only alert %d to be due, got %+v", due, alerts)
+	}
+
+	if err := MarkAlertEscalated(db, due, now); err != nil {
+		t.Fatalf("MarkAlertEscalated: %v", err)
+	}
+	if alerts, _ := AlertsDueForEscalation(db, 30*time.Minute, now.Add(10*time.Minute)); len(alerts) != 0 {
+		t.Errorf("expected no escalation within a period of the last one, got %d", len(alerts))
+	}
+	// By now FRESH has also been waiting longer than a period.
+	alerts, _ = AlertsDueForEscalation(db, 30*time.Minute, now.Add(31*time.Minute))
+	if len(alerts) != 2 || alerts[0].ID != due {
+		t.Errorf("expected HOT re-escalated after a full period (plus FRESH), got %+v", alerts)
+	}
+}
+
+func TestRecordAlertDedupWindow(t *testing.T) {
+	db := setupAlertTestDB(t)
+	defer db.Close()
+
+	warn := func(temp int) *TemperatureAlert {
+		return &TemperatureAlert{Hostname: "server1", SerialNumber: "SERIAL001", AlertType: AlertTypeWarning,
+			Temperature: temp, Threshold: 45, Message: "warm"}
+	}
+
+	first := warn(47)
+	if err := RecordAlert(db, first, time.Hour); err != nil {
+		t.Fatalf("RecordAlert failed: %v", err)
+	}
+	second := warn(49)
+	if err := RecordAlert(db, second, time.Hour); err != nil {
+		t.Fatalf("RecordAlert failed: %v", err)
+	}
+	if second.ID != first.ID || second.OccurrenceCount != 2 || second.Temperature != 49 {
+		t.Errorf("repeat = id %d (first %d), count %d, temp %d; want the first alert updated",
+			second.ID, first.ID, second.OccurrenceCount, second.Temperature)
+	}
+	if second.LastOccurred.IsZero() || second.LastOccurred.Before(second.CreatedAt) {
+		t.Errorf("last_occurred = %v, created_at = %v", second.LastOccurred, second.CreatedAt)
+	}
+
+	// Another type is its own alert
+	critical := &TemperatureAlert{Hostname: "server1", SerialNumber: "SERIAL001", AlertType: AlertTypeCritical, Temperature: 56, Message: "hot"}
+	RecordAlert(db, critical, time.Hour)
+	if critical.ID == first.ID {
+		t.Error("critical alert should not merge into the warning")
+	}
+
+	// Outside the window the old alert is left alone
+	db.Exec(`UPDATE temperature_alerts SET last_occurred = datetime('now', '-2 hours') WHERE id = ?`, first.ID)
+	stale := warn(48)
+	RecordAlert(db, stale, time.Hour)
+	if stale.ID == first.ID {
+		t.Error("alert outside the window should be new")
+	}
+
+	// A recovery closes the open alert; so does acknowledging it
+	RecordAlert(db, &TemperatureAlert{Hostname: "server1", SerialNumber: "SERIAL001", AlertType: AlertTypeRecovery, Message: "ok"}, time.Hour)
+	afterRecovery := warn(47)
+	RecordAlert(db, afterRecovery, time.Hour)
+	if afterRecovery.ID == stale.ID || afterRecovery.OccurrenceCount != 1 {
+		t.Error("alert after a recovery should be new")
+	}
+	AcknowledgeAlert(db, afterRecovery.ID, "admin")
+	afterAck := warn(47)
+	RecordAlert(db, afterAck, time.Hour)
+	if afterAck.ID == afterRecovery.ID {
+		t.Error("alert after acknowledging should be new")
+	}
+
+	// Without a window every alert is new
+	noWindow := warn(47)
+	RecordAlert(db, noWindow, 0)
+	if noWindow.ID == afterAck.ID {
+		t.Error("window 0 should always create an alert")
+	}
+
+	alerts, _ := GetAlerts(db, AlertFilter{SerialNumber: "SERIAL001", AlertType: AlertTypeWarning})
+	if len(alerts) != 5 {
+		t.Errorf("warning alerts = %d, want 5", len(alerts))
+	}
+}
@@ -0,0 +1,168 @@
+package temperature
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"vigil/internal/settings"
+)
+
+// Alert modes for the "temperature" / "alert_mode" setting. The critical
+// threshold always applies as a hard ceiling; the mode only decides what
+// raises a warning.
+const (
+	AlertModeFixed   = "fixed"   // warning_threshold only
+	AlertModeLearned = "learned" // the drive's learned range (fixed threshold until one exists)
+	AlertModeBoth    = "both"    // whichever trips first
+)
+
+const (
+	DefaultBaselineWindowDays = 30
+	DefaultBaselineSigma      = 3
+
+	// minBaselineSamples keeps a drive on the fixed threshold until there is
+	// enough history for mean/σ to mean anything.
+	minBaselineSamples = 100
+	// minBaselineStdDev stops a drive that always reads the same value from
+	// warning on a 1°C wobble.
+	minBaselineStdDev = 1.0
+)
+
+// Baseline is a drive's learned normal operating range: mean ± sigma·σ of
+// its temperature over the last WindowDays.
+type Baseline struct {
+	Hostname     string    `json:"hostname"`
+	SerialNumber string    `json:"serial_number"`
+	Mean         float64   `json:"mean"`
+	StdDev       float64   `json:"stddev"`
+	Low          float64   `json:"low"`
+	High         float64   `json:"high"`
+	Samples      int       `json:"samples"`
+	WindowDays   int       `json:"window_days"`
+	ComputedAt   time.Time `json:"computed_at"`
+}
+
+// InitTemperatureBaselinesTable creates the temperature_baselines table
+func InitTemperatureBaselinesTable(db *sql.DB) error {
+	_, err := db.Exec(`
+	CREATE TABLE IF NOT EXISTS temperature_baselines (
+		hostname TEXT NOT NULL,
+		serial_number TEXT NOT NULL,
+		mean REAL NOT NULL,
+		stddev REAL NOT NULL,
+		low REAL NOT NULL,
+		high REAL NOT NULL,
+		samples INTEGER NOT NULL,
+		window_days INTEGER NOT NULL,
+		computed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (hostname, serial_number)
+	);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create temperature_baselines table: %w", err)
+	}
+	return nil
+}
+
+// ComputeBaselines recomputes the learned range of every drive with at least
+// minBaselineSamples readings in the last windowDays and stores it. Returns
+// the number of drives updated.
+func ComputeBaselines(db *sql.DB, windowDays int, sigma float64) (int, error) {
+	if windowDays <= 0 {
+		windowDays = DefaultBaselineWindowDays
+	}
+	if sigma <= 0 {
+		sigma = DefaultBaselineSigma
+	}
+	since := time.Now().AddDate(0, 0, -windowDays).Format("2006-01-02 15:04:05")
+
+	rows, err := db.Query(`
+		SELECT hostname, serial_number,
+			   AVG(temperature), AVG(temperature * temperature), COUNT(*)
+		FROM temperature_history
+		WHERE timestamp >= ?
+		GROUP BY hostname, serial_number
+		HAVING COUNT(*) >= ?
+	`, since, minBaselineSamples)
+	if err != nil {
+		return 0, fmt.Errorf("failed to aggregate temperature history: %w", err)
+	}
+
+	var baselines []Baseline
+	for rows.Next() {
+		var b Baseline
+		var meanSq float64
+		if err := rows.Scan(&b.Hostname, &b.SerialNumber, &b.Mean, &meanSq, &b.Samples); err != nil {
+			continue
+		}
+		b.StdDev = math.Max(math.Sqrt(math.Max(meanSq-b.Mean*b.Mean, 0)), minBaselineStdDev)
+		b.Low = b.Mean - sigma*b.StdDev
+		b.High = b.Mean + sigma*b.StdDev
+		b.WindowDays = windowDays
+		baselines = append(baselines, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, b := range baselines {
+		_, err := db.Exec(`
+			INSERT INTO temperature_baselines
+				(hostname, serial_number, mean, stddev, low, high, samples, window_days, computed_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(hostname, serial_number) DO UPDATE SET
+				mean = excluded.mean, stddev = excluded.stddev,
+				low = excluded.low, high = excluded.high,
+				samples = excluded.samples, window_days = excluded.window_days,
+				computed_at = excluded.computed_at
+		`, b.Hostname, b.SerialNumber, b.Mean, b.StdDev, b.Low, b.High, b.Samples, b.WindowDays)
+		if err != nil {
+			return 0, fmt.Errorf("failed to store baseline for %s/%s: %w", b.Hostname, b.SerialNumber, err)
+		}
+	}
+	return len(baselines), nil
+}
+
+// GetBaseline returns a drive's learned range, or nil if none has been
+// computed yet.
+func GetBaseline(db *sql.DB, hostname, serial string) (*Baseline, error) {
+	var b Baseline
+	var computedAt string
+	err := db.QueryRow(`
+		SELECT hostname, serial_number, mean, stddev, low, high, samples, window_days, computed_at
+		FROM temperature_baselines
+		WHERE hostname = ? AND serial_number = ?
+	`, hostname, serial).Scan(&b.Hostname, &b.SerialNumber, &b.Mean, &b.StdDev, &b.Low, &b.High,
+		&b.Samples, &b.WindowDays, &computedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get baseline: %w", err)
+	}
+	b.ComputedAt, _ = parseTimestamp(computedAt)
+	return &b, nil
+}
+
+// warningLimit returns the temperature at which a drive raises a warning
+// under the configured alert mode, and whether that limit came from the
+// drive's learned baseline rather than the fixed threshold.
+func warningLimit(db *sql.DB, hostname, serial string, fixed int) (int, *Baseline) {
+	mode := settings.GetStringSettingWithDefault(db, "temperature", "alert_mode", AlertModeFixed)
+	if mode != AlertModeLearned && mode != AlertModeBoth {
+		return fixed, nil
+	}
+	b, err := GetBaseline(db, hostname, serial)
+	if err != nil || b == nil {
+		return fixed, nil
+	}
+	// Readings are whole degrees: the first one above High trips it.
+	learned := int(math.Floor(b.High)) + 1
+	if mode == AlertModeBoth && fixed <= learned {
+		return fixed, nil
+	}
+	return learned, b
+}
@@ -0,0 +1,138 @@
+package temperature
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"vigil/internal/events"
+	"vigil/internal/settings"
+)
+
+// insertHistory adds n readings alternating between a and b, one per minute
+// ending now.
+func insertHistory(t *testing.T, db *sql.DB, hostname, serial string, n, a, b int) {
+	t.Helper()
+	now := time.Now()
+	for i := 0; i < n; i++ {
+		temp := a
+		if i%2 == 1 {
+			temp = b
+		}
+		ts := now.Add(-time.Duration(i) * time.Minute).Format("2006-01-02 15:04:05")
+		if _, err := db.Exec(`INSERT INTO temperature_history (hostname, serial_number, temperature, timestamp) VALUES (?, ?, ?, ?)`,
+			hostname, serial, temp, ts); err != nil {
+			t.Fatalf("insert history: %v", err)
+		}
+	}
+}
+
+func TestComputeBaselines(t *testing.T) {
+	db := setupAlertTestDB(t)
+	defer db.Close()
+	if err := InitTemperatureBaselinesTable(db); err != nil {
+		t.Fatalf("InitTemperatureBaselinesTable: %v", err)
+	}
+
+	insertHistory(t, db, "host1", "STEADY", 200, 35, 37)
+	insertHistory(t, db, "host1", "NEWDRIVE", 10, 30, 30)
+
+	n, err := ComputeBaselines(db, 30, 3)
+	if err != nil {
+		t.Fatalf("ComputeBaselines: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 baseline, got %d", n)
+	}
+
+	b, err := GetBaseline(db, "host1", "STEADY")
+	if err != nil || b == nil {
+		t.Fatalf("GetBaseline: %v, %v", b, err)
+	}
+	if b.Mean != 36 || b.StdDev != 1 || b.Low != 33 || b.High != 39 {
+		t.Errorf("unexpected baseline: mean=%v stddev=%v range=%v–%v", b.Mean, b.StdDev, b.Low, b.High)
+	}
+	if b.Samples != 200 || b.WindowDays != 30 {
+		t.Errorf("unexpected samples/window: %d/%d", b.Samples, b.WindowDays)
+	}
+
+	if b, _ := GetBaseline(db, "host1", "NEWDRIVE"); b != nil {
+		t.Errorf("expected no baseline for drive with too few samples, got %+v", b)
+	}
+}
+
+func TestCheckTemperatureAndAlert_LearnedMode(t *testing.T) {
+	db := setupAlertTestDB(t)
+	defer db.Close()
+	if err := InitTemperatureBaselinesTable(db); err != nil {
+		t.Fatalf("InitTemperatureBaselinesTable: %v", err)
+	}
+	insertHistory(t, db, "host1", "STEADY", 200, 35, 37)
+	if _, err := ComputeBaselines(db, 30, 3); err != nil {
+		t.Fatalf("ComputeBaselines: %v", err)
+	}
+
+	// 41°C is under the fixed 45°C warning but outside the learned 33–39°C.
+	alert, err := CheckTemperatureAndAlert(db, "host1", "STEADY", 41)
+	if err != nil || alert != nil {
+		t.Fatalf("fixed mode: expected no alert, got %+v (%v)", alert, err)
+	}
+
+	if err := settings.UpdateSetting(db, "temperature", "alert_mode", AlertModeLearned); err != nil {
+		t.Fatalf("UpdateSetting: %v", err)
+	}
+	alert, err = CheckTemperatureAndAlert(db, "host1", "STEADY", 41)
+	if err != nil || alert == nil {
+		t.Fatalf("learned mode: expected warning, got %+v (%v)", alert, err)
+	}
+	if alert.AlertType != AlertTypeWarning || alert.Threshold != 40 {
+		t.Errorf("expected warning at learned limit 40, got %s at %d", alert.AlertType, alert.Threshold)
+	}
+
+	// Without a baseline, learned mode falls back to the fixed threshold.
+	if alert, _ := CheckTemperatureAndAlert(db, "host1", "OTHER", 41); alert != nil {
+		t.Errorf("expected no alert for drive without baseline, got %+v", alert)
+	}
+}
+
+func TestProcessReport_LearnedLimit(t *testing.T) {
+	db := setupAlertTestDB(t)
+	defer db.Close()
+	if err := InitTemperatureBaselinesTable(db); err != nil {
+		t.Fatalf("InitTemperatureBaselinesTable: %v", err)
+	}
+	insertHistory(t, db, "host1", "STEADY", 200, 35, 37)
+	if _, err := ComputeBaselines(db, 30, 3); err != nil {
+		t.Fatalf("ComputeBaselines: %v", err)
+	}
+	if err := settings.UpdateSetting(db, "temperature", "alert_mode", AlertModeLearned); err != nil {
+		t.Fatalf("UpdateSetting: %v", err)
+	}
+
+	bus := events.NewBus()
+	var published []events.Event
+	bus.Subscribe(func(e events.Event) { published = append(published, e) })
+
+	// 41°C: above the learned limit of 40°C, below the fixed 45°C warning.
+	ProcessReport(db, bus, "host1", map[string]interface{}{
+		"drives": []interface{}{
+			map[string]interface{}{
+				"serial_number": "STEADY",
+				"model_name":    "TestHDD",
+				"device":        map[string]interface{}{"name": "/dev/sda"},
+				"temperature":   map[string]interface{}{"current": float64(41)},
+			},
+		},
+	})
+
+	alerts, err := GetAlertsByDrive(db, "host1", "STEADY", 10)
+	if err != nil {
+		t.Fatalf("GetAlertsByDrive: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].AlertType != AlertTypeWarning || alerts[0].Threshold != 40 {
+		t.Fatalf("expected a warning at the learned limit 40, got %+v", alerts)
+	}
+	if len(published) != 1 || published[0].Type != events.TempAlert {
+		t.Errorf("expected one temp_alert event, got %+v", published)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"math"
 	"time"
+
+	"vigil/internal/settings"
 )
 
 // DashboardTemperatureData holds all temperature data for the dashboard
@@ -84,6 +86,76 @@ type DashboardOverview struct {
 	AvgTemperature   float64 `json:"avg_temperature"`
 	MaxTemperature   int     `json:"max_temperature"`
 	Status           string  `json:"status"` // "normal", "warning", "critical"
+	Health           string  `json:"health"` // "healthy", "degraded", "critical"
+	HealthScore      int     `json:"health_score"`
+}
+
+// StatusPolicy decides fleet health from open alerts and drive
+// temperatures. Each warning (a warning or spike alert, or a drive over the
+// warning threshold) adds WarningWeight to a score and each critical one
+// CriticalWeight; the score is then compared against DegradedScore and
+// CriticalScore. A drive over the critical threshold always makes the
+// fleet critical.
+type StatusPolicy struct {
+	CountAcknowledged bool `json:"count_acknowledged"`
+	WarningWeight     int  `json:"warning_weight"`
+	CriticalWeight    int  `json:"critical_weight"`
+	DegradedScore     int  `json:"degraded_score"`
+	CriticalScore     int  `json:"critical_score"` // 0 = score never makes the fleet critical
+}
+
+// LoadStatusPolicy reads the dashboard.* settings.
+func LoadStatusPolicy(db *sql.DB) StatusPolicy {
+	p := StatusPolicy{
+		CountAcknowledged: settings.GetBool(db, "dashboard", "count_acknowledged", false),
+		WarningWeight:     settings.GetInt(db, "dashboard", "warning_weight", 1),
+		CriticalWeight:    settings.GetInt(db, "dashboard", "critical_weight", 3),
+		DegradedScore:     settings.GetInt(db, "dashboard", "degraded_score", 1),
+		CriticalScore:     settings.GetInt(db, "dashboard", "critical_score", 0),
+	}
+	if p.DegradedScore < 1 {
+		p.DegradedScore = 1
+	}
+	return p
+}
+
+// Score weighs warning and critical findings.
+func (p StatusPolicy) Score(warnings, criticals int) int {
+	return warnings*p.WarningWeight + criticals*p.CriticalWeight
+}
+
+// Health maps a score to "healthy", "degraded" or "critical".
+func (p StatusPolicy) Health(score int) string {
+	switch {
+	case p.CriticalScore > 0 && score >= p.CriticalScore:
+		return "critical"
+	case score >= p.DegradedScore:
+		return "degraded"
+	}
+	return "healthy"
+}
+
+// countOpenAlerts counts the warning (warning and spike) and critical
+// temperature alerts that are still open: unacknowledged ones, plus with
+// includeAcknowledged the acknowledged ones whose drive hasn't recovered
+// since.
+func countOpenAlerts(db *sql.DB, includeAcknowledged bool) (warnings, criticals int, err error) {
+	err = db.QueryRow(`
+		SELECT
+			COALESCE(SUM(CASE WHEN a.alert_type IN ('warning', 'spike') THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN a.alert_type = 'critical' THEN 1 ELSE 0 END), 0)
+		FROM temperature_alerts a
+		WHERE a.alert_type IN ('warning', 'spike', 'critical')
+		  AND (a.acknowledged = 0 OR (? AND NOT EXISTS (
+			SELECT 1 FROM temperature_alerts r
+			WHERE r.hostname = a.hostname AND r.serial_number = a.serial_number
+			  AND r.alert_type = ? AND r.created_at >= a.created_at
+		  )))
+	`, includeAcknowledged, AlertTypeRecovery).Scan(&warnings, &criticals)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count open alerts: %w", err)
+	}
+	return warnings, criticals, nil
 }
 
 // GetDashboardTemperatureData retrieves comprehensive dashboard data
@@ -213,6 +285,7 @@ func GetDashboardOverview(db *sql.DB) (*DashboardOverview, error) {
 
 	if len(temps) == 0 {
 		overview.Status = "normal"
+		overview.Health = "healthy"
 		return overview, nil
 	}
 
@@ -220,6 +293,7 @@ func GetDashboardOverview(db *sql.DB) (*DashboardOverview, error) {
 
 	var totalTemp int
 	var maxStatus string = "normal"
+	var warnings, criticals int
 
 	for _, t := range temps {
 		totalTemp += t.Temperature
@@ -232,9 +306,11 @@ func GetDashboardOverview(db *sql.DB) (*DashboardOverview, error) {
 		switch t.Status {
 		case "critical":
 			overview.DrivesWithIssues++
+			criticals++
 			maxStatus = "critical"
 		case "warning":
 			overview.DrivesWithIssues++
+			warnings++
 			if maxStatus != "critical" {
 				maxStatus = "warning"
 			}
@@ -250,8 +326,22 @@ func GetDashboardOverview(db *sql.DB) (*DashboardOverview, error) {
 		overview.ActiveAlerts = alertSummary.Unacknowledged
 	}
 
+	policy := LoadStatusPolicy(db)
+	if w, c, err := countOpenAlerts(db, policy.CountAcknowledged); err == nil {
+		warnings += w
+		criticals += c
+	}
+	overview.HealthScore = policy.Score(warnings, criticals)
+	overview.Health = policy.Health(overview.HealthScore)
+	if maxStatus == "critical" {
+		overview.Health = "critical"
+	}
+
 	// Adjust status based on alerts
-	if overview.ActiveAlerts > 0 && overview.Status == "normal" {
+	switch {
+	case overview.Health == "critical":
+		overview.Status = "critical"
+	case overview.Health == "degraded" && overview.Status == "normal":
 		overview.Status = "warning"
 	}
 
@@ -455,12 +545,16 @@ func getRecentDashboardSpikes(db *sql.DB, limit int) []DashboardSpike {
 // Helper: convert period to SQL interval
 func periodToSQLInterval(p TemperaturePeriod) string {
 	switch p {
+	case Period1Hour:
+		return "-1 hours"
 	case Period24Hours:
 		return "-24 hours"
 	case Period7Days:
 		return "-7 days"
 	case Period30Days:
 		return "-30 days"
+	case Period90Days:
+		return "-90 days"
 	default:
 		return "-365 days"
 	}
@@ -220,6 +220,56 @@ func TestGetDashboardOverviewEmpty(t *testing.T) {
 	}
 }
 
+func TestDashboardOverviewHealthPolicy(t *testing.T) {
+	db := setupDashboardTestDB(t)
+	defer db.Close()
+
+	// Two normal drives and one acknowledged, unrecovered warning alert
+	for _, serial := range []string{"SERIAL001", "SERIAL002"} {
+		db.Exec(`INSERT INTO temperature_history (hostname, serial_number, temperature, timestamp)
+			VALUES ('server1', ?, 35, datetime('now'))`, serial)
+	}
+	db.Exec(`INSERT INTO temperature_alerts (hostname, serial_number, alert_type, temperature, message, acknowledged, created_at)
+		VALUES ('server1', 'SERIAL001', 'warning', 47, 'warm', 1, datetime('now', '-1 hour'))`)
+
+	overview, err := GetDashboardOverview(db)
+	if err != nil {
+		t.Fatalf("GetDashboardOverview failed: %v", err)
+	}
+	if overview.Health != "healthy" || overview.Status != "normal" {
+		t.Errorf("acknowledged alert: health = %s, status = %s, want healthy/normal", overview.Health, overview.Status)
+	}
+
+	settings.UpdateSettings(db, map[string]map[string]string{"dashboard": {"count_acknowledged": "true"}})
+	overview, _ = GetDashboardOverview(db)
+	if overview.Health != "degraded" || overview.HealthScore != 1 || overview.Status != "warning" {
+		t.Errorf("counting acknowledged: health = %s (score %d), status = %s", overview.Health, overview.HealthScore, overview.Status)
+	}
+
+	// A recovery closes the acknowledged alert again
+	db.Exec(`INSERT INTO temperature_alerts (hostname, serial_number, alert_type, temperature, message, created_at)
+		VALUES ('server1', 'SERIAL001', 'recovery', 38, 'ok', datetime('now'))`)
+	overview, _ = GetDashboardOverview(db)
+	if overview.Health != "healthy" {
+		t.Errorf("after recovery: health = %s, want healthy", overview.Health)
+	}
+
+	// Unacknowledged critical alerts weigh 3; at 6 the fleet is critical
+	settings.UpdateSettings(db, map[string]map[string]string{"dashboard": {"degraded_score": "4", "critical_score": "6"}})
+	db.Exec(`INSERT INTO temperature_alerts (hostname, serial_number, alert_type, temperature, message)
+		VALUES ('server1', 'SERIAL002', 'critical', 56, 'hot')`)
+	overview, _ = GetDashboardOverview(db)
+	if overview.Health != "healthy" || overview.HealthScore != 3 {
+		t.Errorf("one critical alert: health = %s (score %d), want healthy below degraded_score", overview.Health, overview.HealthScore)
+	}
+	db.Exec(`INSERT INTO temperature_alerts (hostname, serial_number, alert_type, temperature, message)
+		VALUES ('server1', 'SERIAL002', 'critical', 57, 'hotter')`)
+	overview, _ = GetDashboardOverview(db)
+	if overview.Health != "critical" || overview.Status != "critical" {
+		t.Errorf("two critical alerts: health = %s, status = %s, want critical", overview.Health, overview.Status)
+	}
+}
+
 func TestGetTemperatureTrends(t *testing.T) {
 	// TODO: This test has issues with SQLite datetime comparisons in the test environment
 	// The underlying functionality works in production with real timestamps
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"vigil/internal/settings"
@@ -17,18 +18,7 @@ func GetTemperatureStats(db *sql.DB, hostname, serial string, period Temperature
 
 	if period != PeriodAllTime {
 		// Use SQLite's datetime function for reliable comparison
-		var interval string
-		switch period {
-		case Period24Hours:
-			interval = "-24 hours"
-		case Period7Days:
-			interval = "-7 days"
-		case Period30Days:
-			interval = "-30 days"
-		default:
-			interval = "-365 days"
-		}
-		timeFilter = fmt.Sprintf("AND timestamp >= datetime('now', '%s')", interval)
+		timeFilter = fmt.Sprintf("AND timestamp >= datetime('now', '%s')", periodToSQLInterval(period))
 	}
 
 	// Query for basic stats
@@ -96,6 +86,9 @@ func GetTemperatureStats(db *sql.DB, hostname, serial string, period Temperature
 	// Calculate trend
 	stats.TrendSlope, stats.TrendDesc = calculateTrend(db, hostname, serial, period)
 
+	// Time spent above the thresholds
+	stats.ThermalStress, _ = GetThermalStress(db, hostname, serial, period)
+
 	// Get drive info
 	driveInfo, _ := getDriveInfo(db, hostname, serial)
 	if driveInfo != nil {
@@ -267,6 +260,65 @@ func GetAllDrivesTemperatureStats(db *sql.DB, period TemperaturePeriod) ([]Tempe
 	return stats, nil
 }
 
+// GetHostTemperatureStats retrieves aggregate temperature statistics for all
+// drives on a host, with each drive's own stats. Returns nil if the host has
+// no readings in the period.
+func GetHostTemperatureStats(db *sql.DB, hostname string, period TemperaturePeriod) (*HostTemperatureStats, error) {
+	timeFilter := ""
+	if period != PeriodAllTime {
+		timeFilter = fmt.Sprintf("AND timestamp >= datetime('now', '%s')", periodToSQLInterval(period))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT MIN(temperature), MAX(temperature), AVG(temperature), COUNT(*)
+		FROM temperature_history
+		WHERE hostname = ? %s
+	`, timeFilter)
+
+	stats := HostTemperatureStats{Hostname: hostname, Period: string(period)}
+	var minTemp, maxTemp sql.NullInt64
+	var avgTemp sql.NullFloat64
+	if err := db.QueryRow(query, hostname).Scan(&minTemp, &maxTemp, &avgTemp, &stats.DataPoints); err != nil {
+		return nil, fmt.Errorf("failed to get host temperature stats: %w", err)
+	}
+	if stats.DataPoints == 0 {
+		return nil, nil
+	}
+	stats.MinTemp = int(minTemp.Int64)
+	stats.MaxTemp = int(maxTemp.Int64)
+	stats.AvgTemp = math.Round(avgTemp.Float64*100) / 100
+
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT DISTINCT serial_number
+		FROM temperature_history
+		WHERE hostname = ? %s
+		ORDER BY serial_number
+	`, timeFilter), hostname)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get host drives: %w", err)
+	}
+	var serials []string
+	for rows.Next() {
+		var serial string
+		if err := rows.Scan(&serial); err == nil {
+			serials = append(serials, serial)
+		}
+	}
+	rows.Close()
+
+	stats.Drives = make([]TemperatureStats, 0, len(serials))
+	for _, serial := range serials {
+		driveStats, err := GetTemperatureStats(db, hostname, serial, period)
+		if err != nil || driveStats == nil {
+			continue
+		}
+		stats.Drives = append(stats.Drives, *driveStats)
+	}
+	stats.DriveCount = len(stats.Drives)
+
+	return &stats, nil
+}
+
 // GetTemperatureTimeSeries retrieves time series data for charting
 func GetTemperatureTimeSeries(db *sql.DB, hostname, serial string, period TemperaturePeriod, interval AggregationInterval) (*TimeSeriesData, error) {
 	// Build time filter using SQLite datetime function
@@ -274,26 +326,15 @@ func GetTemperatureTimeSeries(db *sql.DB, hostname, serial string, period Temper
 	args := []interface{}{hostname, serial}
 
 	if period != PeriodAllTime {
-		var sqlInterval string
-		switch period {
-		case Period24Hours:
-			sqlInterval = "-24 hours"
-		case Period7Days:
-			sqlInterval = "-7 days"
-		case Period30Days:
-			sqlInterval = "-30 days"
-		default:
-			sqlInterval = "-365 days"
-		}
-		timeFilter = fmt.Sprintf("AND timestamp >= datetime('now', '%s')", sqlInterval)
+		timeFilter = fmt.Sprintf("AND timestamp >= datetime('now', '%s')", periodToSQLInterval(period))
 	}
 
 	// Build aggregation query
-	timeFormat := IntervalToSQLite(interval)
+	bucket := IntervalToSQLite(interval)
 
 	query := fmt.Sprintf(`
 		SELECT
-			strftime('%s', timestamp) as time_bucket,
+			%s as time_bucket,
 			MIN(temperature) as min_temp,
 			MAX(temperature) as max_temp,
 			AVG(temperature) as avg_temp,
@@ -302,7 +343,7 @@ func GetTemperatureTimeSeries(db *sql.DB, hostname, serial string, period Temper
 		WHERE hostname = ? AND serial_number = ? %s
 		GROUP BY time_bucket
 		ORDER BY time_bucket ASC
-	`, timeFormat, timeFilter)
+	`, bucket, timeFilter)
 
 	rows, err := db.Query(query, args...) // #nosec G701 -- query is built from hardcoded format strings, user values are parameterized
 	if err != nil {
@@ -346,6 +387,53 @@ func GetTemperatureTimeSeries(db *sql.DB, hostname, serial string, period Temper
 	return result, nil
 }
 
+// GetFleetTemperatureTimeSeries aggregates the readings of all drives into
+// min/max/avg per time bucket in a single query
+func GetFleetTemperatureTimeSeries(db *sql.DB, period TemperaturePeriod, interval AggregationInterval) (*FleetTimeSeriesData, error) {
+	timeFilter := ""
+	if period != PeriodAllTime {
+		timeFilter = fmt.Sprintf("WHERE timestamp >= datetime('now', '%s')", periodToSQLInterval(period))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			%s as time_bucket,
+			MIN(temperature) as min_temp,
+			MAX(temperature) as max_temp,
+			AVG(temperature) as avg_temp,
+			COUNT(*) as data_points,
+			COUNT(DISTINCT hostname || char(31) || serial_number) as drive_count
+		FROM temperature_history
+		%s
+		GROUP BY time_bucket
+		ORDER BY time_bucket ASC
+	`, IntervalToSQLite(interval), timeFilter)
+
+	rows, err := db.Query(query) // #nosec G701 -- query is built from hardcoded format strings
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fleet time series: %w", err)
+	}
+	defer rows.Close()
+
+	points := []FleetTimeSeriesPoint{}
+	for rows.Next() {
+		var timeBucket string
+		var point FleetTimeSeriesPoint
+		if err := rows.Scan(&timeBucket, &point.MinTemp, &point.MaxTemp, &point.AvgTemp, &point.DataPoints, &point.DriveCount); err != nil {
+			continue
+		}
+		point.Timestamp, _ = time.Parse("2006-01-02 15:04:05", timeBucket)
+		point.AvgTemp = math.Round(point.AvgTemp*100) / 100
+		points = append(points, point)
+	}
+
+	return &FleetTimeSeriesData{
+		Period:   string(period),
+		Interval: string(interval),
+		Points:   points,
+	}, rows.Err()
+}
+
 // GetCurrentTemperature retrieves the most recent temperature for a drive
 func GetCurrentTemperature(db *sql.DB, hostname, serial string) (*CurrentTemperature, error) {
 	query := `
@@ -434,6 +522,64 @@ func GetAllCurrentTemperatures(db *sql.DB) ([]CurrentTemperature, error) {
 	return temps, nil
 }
 
+// GetCurrentTemperaturesFor retrieves current temperatures for the given
+// drives in one query. Drives without temperature data are left out.
+func GetCurrentTemperaturesFor(db *sql.DB, drives []DriveRef) ([]CurrentTemperature, error) {
+	temps := []CurrentTemperature{}
+	if len(drives) == 0 {
+		return temps, nil
+	}
+
+	placeholders := make([]string, len(drives))
+	args := make([]interface{}, 0, 2*len(drives))
+	for i, d := range drives {
+		placeholders[i] = "(?, ?)"
+		args = append(args, d.Hostname, d.SerialNumber)
+	}
+
+	query := `
+		SELECT th.hostname, th.serial_number, th.temperature, th.timestamp
+		FROM temperature_history th
+		INNER JOIN (
+			SELECT hostname, serial_number, MAX(timestamp) as max_ts
+			FROM temperature_history
+			WHERE (hostname, serial_number) IN (VALUES ` + strings.Join(placeholders, ", ") + `)
+			GROUP BY hostname, serial_number
+		) latest ON th.hostname = latest.hostname
+			AND th.serial_number = latest.serial_number
+			AND th.timestamp = latest.max_ts
+		ORDER BY th.hostname, th.serial_number
+	`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current temperatures: %w", err)
+	}
+	defer rows.Close()
+
+	thresholds := getThresholdsFromSettings(db)
+	for rows.Next() {
+		var ct CurrentTemperature
+		var timestampStr string
+		if err := rows.Scan(&ct.Hostname, &ct.SerialNumber, &ct.Temperature, &timestampStr); err != nil {
+			continue
+		}
+
+		ct.Timestamp, _ = parseTimestamp(timestampStr)
+		ct.Status = thresholds.GetStatus(ct.Temperature)
+
+		driveInfo, _ := getDriveInfo(db, ct.Hostname, ct.SerialNumber)
+		if driveInfo != nil {
+			ct.DeviceName = driveInfo.DeviceName
+			ct.Model = driveInfo.Model
+		}
+
+		temps = append(temps, ct)
+	}
+
+	return temps, rows.Err()
+}
+
 // GetTemperatureSummary provides an overview of all drive temperatures
 func GetTemperatureSummary(db *sql.DB) (*TemperatureSummary, error) {
 	temps, err := GetAllCurrentTemperatures(db)
@@ -487,24 +633,67 @@ func GetTemperatureSummary(db *sql.DB) (*TemperatureSummary, error) {
 	return summary, nil
 }
 
-// GetTemperatureRange retrieves temperature records within a time range
+// GetTemperatureRange retrieves temperature records within a time range.
+// At most MaxRangeRows readings are returned; use GetTemperatureRangePage to
+// page through or downsample larger ranges.
 func GetTemperatureRange(db *sql.DB, hostname, serial string, from, to time.Time) ([]TempReading, error) {
+	page, err := GetTemperatureRangePage(db, hostname, serial, from, to, RangeOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return page.Records, nil
+}
+
+// GetTemperatureRangePage retrieves one bounded page of temperature records
+// within a time range. With Downsample set, a step is chosen so the whole
+// range fits in a single page and only every step-th reading is returned.
+func GetTemperatureRangePage(db *sql.DB, hostname, serial string, from, to time.Time, opts RangeOptions) (*TemperatureRangePage, error) {
+	limit := opts.Limit
+	if limit <= 0 || limit > MaxRangeRows {
+		limit = MaxRangeRows
+	}
+	offset := opts.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	fromStr := from.UTC().Format("2006-01-02 15:04:05")
+	toStr := to.UTC().Format("2006-01-02 15:04:05")
+
+	var total int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM temperature_history
+		WHERE hostname = ? AND serial_number = ? AND timestamp BETWEEN ? AND ?
+	`, hostname, serial, fromStr, toStr).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count temperature range: %w", err)
+	}
+
+	step := 1
+	if opts.Downsample && total > limit {
+		step = (total + limit - 1) / limit
+	}
+
 	query := `
 		SELECT id, hostname, serial_number, temperature, timestamp
-		FROM temperature_history
-		WHERE hostname = ? AND serial_number = ? AND timestamp BETWEEN ? AND ?
-		ORDER BY timestamp ASC
+		FROM (
+			SELECT id, hostname, serial_number, temperature, timestamp,
+				ROW_NUMBER() OVER (ORDER BY timestamp ASC, id ASC) AS rn
+			FROM temperature_history
+			WHERE hostname = ? AND serial_number = ? AND timestamp BETWEEN ? AND ?
+		)
+		WHERE (rn - 1) % ? = 0
+		ORDER BY rn
+		LIMIT ? OFFSET ?
 	`
 
-	rows, err := db.Query(query, hostname, serial,
-		from.UTC().Format("2006-01-02 15:04:05"),
-		to.UTC().Format("2006-01-02 15:04:05"))
+	rows, err := db.Query(query, hostname, serial, fromStr, toStr, step, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get temperature range: %w", err)
 	}
 	defer rows.Close()
 
-	var records []TempReading
+	records := []TempReading{}
 	for rows.Next() {
 		var r TempReading
 		var timestampStr string
@@ -516,7 +705,15 @@ func GetTemperatureRange(db *sql.DB, hostname, serial string, from, to time.Time
 		records = append(records, r)
 	}
 
-	return records, nil
+	sampled := (total + step - 1) / step
+	return &TemperatureRangePage{
+		Records:   records,
+		Total:     total,
+		Limit:     limit,
+		Offset:    offset,
+		Step:      step,
+		Truncated: offset+len(records) < sampled,
+	}, nil
 }
 
 // GetHeatmapData retrieves data for temperature heatmap visualization
@@ -613,6 +810,24 @@ func getDriveInfo(db *sql.DB, hostname, serial string) (*driveInfo, error) {
 		return &info, nil
 	}
 
+	// Latest SMART sample: show the stable by-id path when the agent sent
+	// one, since /dev/sdX can point at a different drive after a reboot
+	var deviceName, byID string
+	err = db.QueryRow(`
+		SELECT device_name, COALESCE(device_by_id, '')
+		FROM smart_attributes
+		WHERE hostname = ? AND serial_number = ?
+		ORDER BY timestamp DESC
+		LIMIT 1
+	`, hostname, serial).Scan(&deviceName, &byID)
+	if err == nil {
+		info.DeviceName = deviceName
+		if byID != "" {
+			info.DeviceName = byID
+		}
+		return &info, nil
+	}
+
 	// Fallback: try drives table
 	query = `
 		SELECT device, model
@@ -629,8 +844,12 @@ func getDriveInfo(db *sql.DB, hostname, serial string) (*driveInfo, error) {
 	return nil, err
 }
 
-// CleanupOldTemperatureData removes temperature data older than retention period
+// CleanupOldTemperatureData removes temperature data older than retention period.
+// A retentionDays of 0 or less is a no-op ("keep forever").
 func CleanupOldTemperatureData(db *sql.DB, retentionDays int) (int64, error) {
+	if retentionDays <= 0 {
+		return 0, nil
+	}
 	cutoff := time.Now().AddDate(0, 0, -retentionDays)
 
 	result, err := db.Exec(`
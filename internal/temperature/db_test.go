@@ -88,6 +88,8 @@ func TestParsePeriod(t *testing.T) {
 		{"1w", Period7Days},
 		{"30d", Period30Days},
 		{"1m", Period30Days},
+		{"1h", Period1Hour},
+		{"90d", Period90Days},
 		{"all", PeriodAllTime},
 		{"", PeriodAllTime},
 		{"invalid", Period24Hours}, // Default
@@ -106,6 +108,8 @@ func TestParseInterval(t *testing.T) {
 		input    string
 		expected AggregationInterval
 	}{
+		{"5m", Interval5Min},
+		{"15m", Interval15Min},
 		{"1h", IntervalHourly},
 		{"hour", IntervalHourly},
 		{"6h", Interval6Hours},
@@ -124,6 +128,65 @@ func TestParseInterval(t *testing.T) {
 	}
 }
 
+func TestValidateInterval(t *testing.T) {
+	tests := []struct {
+		period   TemperaturePeriod
+		interval AggregationInterval
+		wantErr  bool
+	}{
+		{Period1Hour, Interval5Min, false},
+		{Period24Hours, Interval5Min, false},
+		{Period30Days, Interval15Min, true},
+		{Period90Days, IntervalHourly, false},
+		{Period90Days, IntervalWeekly, false},
+		{PeriodAllTime, IntervalHourly, true},
+		{PeriodAllTime, IntervalDaily, false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateInterval(tt.period, tt.interval)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateInterval(%s, %s) err = %v, wantErr %v", tt.period, tt.interval, err, tt.wantErr)
+		}
+	}
+}
+
+func TestTimeSeriesBuckets(t *testing.T) {
+	db := setupTempTestDB(t)
+	defer db.Close()
+
+	for _, ts := range []string{"2026-01-07 10:01:00", "2026-01-07 10:03:00", "2026-01-07 10:07:00"} {
+		if _, err := db.Exec(`INSERT INTO temperature_history (hostname, serial_number, temperature, timestamp) VALUES ('h', 's', 40, ?)`, ts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		interval AggregationInterval
+		want     []string
+	}{
+		{Interval5Min, []string{"2026-01-07 10:00:00", "2026-01-07 10:05:00"}},
+		{Interval15Min, []string{"2026-01-07 10:00:00"}},
+		{IntervalWeekly, []string{"2026-01-05 00:00:00"}}, // Monday
+		{IntervalMonthly, []string{"2026-01-01 00:00:00"}},
+	}
+
+	for _, tt := range tests {
+		data, err := GetTemperatureTimeSeries(db, "h", "s", PeriodAllTime, tt.interval)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.interval, err)
+		}
+		if len(data.Points) != len(tt.want) {
+			t.Fatalf("%s: got %d buckets, want %d", tt.interval, len(data.Points), len(tt.want))
+		}
+		for i, p := range data.Points {
+			if got := p.Timestamp.Format("2006-01-02 15:04:05"); got != tt.want[i] {
+				t.Errorf("%s bucket %d = %s, want %s", tt.interval, i, got, tt.want[i])
+			}
+		}
+	}
+}
+
 func TestGetTemperatureStats(t *testing.T) {
 	db := setupTempTestDB(t)
 	defer db.Close()
@@ -219,6 +282,104 @@ func TestGetAllDrivesTemperatureStats(t *testing.T) {
 	}
 }
 
+func TestGetHostTemperatureStats(t *testing.T) {
+	db := setupTempTestDB(t)
+	defer db.Close()
+
+	insertTestTemperatureData(t, db, "server1", "SERIAL001", []int{30, 32, 34}, 3)
+	insertTestTemperatureData(t, db, "server1", "SERIAL002", []int{40, 44}, 2)
+	insertTestTemperatureData(t, db, "server2", "SERIAL003", []int{60}, 1)
+
+	stats, err := GetHostTemperatureStats(db, "server1", PeriodAllTime)
+	if err != nil {
+		t.Fatalf("GetHostTemperatureStats failed: %v", err)
+	}
+	if stats == nil {
+		t.Fatal("Expected stats, got nil")
+	}
+
+	if stats.MinTemp != 30 || stats.MaxTemp != 44 {
+		t.Errorf("Expected min/max 30/44, got %d/%d", stats.MinTemp, stats.MaxTemp)
+	}
+	if stats.AvgTemp != 36 {
+		t.Errorf("Expected avg 36, got %.2f", stats.AvgTemp)
+	}
+	if stats.DataPoints != 5 {
+		t.Errorf("Expected 5 data points, got %d", stats.DataPoints)
+	}
+	if stats.DriveCount != 2 || len(stats.Drives) != 2 {
+		t.Fatalf("Expected 2 drives, got %d", len(stats.Drives))
+	}
+	if stats.Drives[1].SerialNumber != "SERIAL002" || stats.Drives[1].MaxTemp != 44 {
+		t.Errorf("Unexpected per-drive stats: %+v", stats.Drives[1])
+	}
+
+	none, err := GetHostTemperatureStats(db, "nohost", PeriodAllTime)
+	if err != nil || none != nil {
+		t.Errorf("Expected nil stats for unknown host, got %+v, %v", none, err)
+	}
+}
+
+func TestGetCurrentTemperaturesFor(t *testing.T) {
+	db := setupTempTestDB(t)
+	defer db.Close()
+
+	insertTestTemperatureData(t, db, "server1", "SERIAL001", []int{30, 32, 34}, 3)
+	insertTestTemperatureData(t, db, "server1", "SERIAL002", []int{40, 44}, 2)
+	insertTestTemperatureData(t, db, "server2", "SERIAL001", []int{60}, 1)
+
+	temps, err := GetCurrentTemperaturesFor(db, []DriveRef{
+		{Hostname: "server1", SerialNumber: "SERIAL001"},
+		{Hostname: "server2", SerialNumber: "SERIAL001"},
+		{Hostname: "server2", SerialNumber: "SERIAL002"}, // no data
+	})
+	if err != nil {
+		t.Fatalf("GetCurrentTemperaturesFor failed: %v", err)
+	}
+	if len(temps) != 2 {
+		t.Fatalf("Expected 2 drives, got %+v", temps)
+	}
+	if temps[0].Hostname != "server1" || temps[0].Temperature != 34 {
+		t.Errorf("Expected server1/SERIAL001 at 34, got %+v", temps[0])
+	}
+	if temps[1].Hostname != "server2" || temps[1].Temperature != 60 || temps[1].Status != "critical" {
+		t.Errorf("Expected server2/SERIAL001 at 60 (critical), got %+v", temps[1])
+	}
+
+	none, err := GetCurrentTemperaturesFor(db, nil)
+	if err != nil || len(none) != 0 {
+		t.Errorf("Expected no drives for an empty request, got %+v, %v", none, err)
+	}
+}
+
+func TestGetFleetTemperatureTimeSeries(t *testing.T) {
+	db := setupTempTestDB(t)
+	defer db.Close()
+
+	insertTestTemperatureData(t, db, "server1", "SERIAL001", []int{30, 32}, 2)
+	insertTestTemperatureData(t, db, "server2", "SERIAL002", []int{40, 50}, 2)
+	insertTestTemperatureData(t, db, "server2", "SERIAL003", []int{99}, 30) // outside the period
+
+	data, err := GetFleetTemperatureTimeSeries(db, Period24Hours, IntervalHourly)
+	if err != nil {
+		t.Fatalf("GetFleetTemperatureTimeSeries failed: %v", err)
+	}
+	if len(data.Points) != 2 {
+		t.Fatalf("Expected 2 hourly buckets, got %+v", data.Points)
+	}
+
+	first, second := data.Points[0], data.Points[1]
+	if first.MinTemp != 30 || first.MaxTemp != 40 || first.AvgTemp != 35 || first.DriveCount != 2 {
+		t.Errorf("Unexpected first bucket: %+v", first)
+	}
+	if second.MinTemp != 32 || second.MaxTemp != 50 || second.AvgTemp != 41 || second.DataPoints != 2 {
+		t.Errorf("Unexpected second bucket: %+v", second)
+	}
+	if !first.Timestamp.Before(second.Timestamp) {
+		t.Errorf("Buckets out of order: %v, %v", first.Timestamp, second.Timestamp)
+	}
+}
+
 func TestGetTemperatureTimeSeries(t *testing.T) {
 	db := setupTempTestDB(t)
 	defer db.Close()
@@ -409,6 +570,52 @@ func TestGetTemperatureRange(t *testing.T) {
 	}
 }
 
+func TestGetTemperatureRangePage(t *testing.T) {
+	db := setupTempTestDB(t)
+	defer db.Close()
+
+	insertTestTemperatureData(t, db, "server1", "SERIAL001", []int{30, 31, 32, 33, 34, 35, 36, 37, 38, 39}, 10)
+
+	from := time.Now().Add(-11 * time.Hour)
+	to := time.Now().Add(time.Hour)
+
+	page, err := GetTemperatureRangePage(db, "server1", "SERIAL001", from, to, RangeOptions{Limit: 4, Offset: 2})
+	if err != nil {
+		t.Fatalf("GetTemperatureRangePage failed: %v", err)
+	}
+	if page.Total != 10 {
+		t.Errorf("Total = %d, want 10", page.Total)
+	}
+	if len(page.Records) != 4 || page.Records[0].Temperature != 32 {
+		t.Errorf("Expected 4 records starting at 32, got %+v", page.Records)
+	}
+	if !page.Truncated {
+		t.Error("Expected truncated page")
+	}
+
+	page, err = GetTemperatureRangePage(db, "server1", "SERIAL001", from, to, RangeOptions{Limit: 5, Downsample: true})
+	if err != nil {
+		t.Fatalf("GetTemperatureRangePage failed: %v", err)
+	}
+	if page.Step != 2 {
+		t.Errorf("Step = %d, want 2", page.Step)
+	}
+	if len(page.Records) != 5 || page.Records[1].Temperature != 32 {
+		t.Errorf("Expected every 2nd reading, got %+v", page.Records)
+	}
+	if page.Truncated {
+		t.Error("Downsampled page should cover the whole range")
+	}
+
+	page, err = GetTemperatureRangePage(db, "server1", "SERIAL001", from, to, RangeOptions{Limit: MaxRangeRows + 1})
+	if err != nil {
+		t.Fatalf("GetTemperatureRangePage failed: %v", err)
+	}
+	if page.Limit != MaxRangeRows {
+		t.Errorf("Limit = %d, want clamp to %d", page.Limit, MaxRangeRows)
+	}
+}
+
 func TestTemperatureThresholds(t *testing.T) {
 	thresholds := TemperatureThresholds{Warning: 45, Critical: 55}
 
@@ -487,3 +694,42 @@ func TestCleanupOldTemperatureData(t *testing.T) {
 		t.Errorf("Remaining records = %d, want 1", count)
 	}
 }
+
+func TestGetDriveInfoPrefersByIDPath(t *testing.T) {
+	db := setupTempTestDB(t)
+	defer db.Close()
+
+	_, err := db.Exec(`
+		CREATE TABLE smart_attributes (
+			hostname      TEXT NOT NULL,
+			serial_number TEXT NOT NULL,
+			device_name   TEXT NOT NULL,
+			device_by_id  TEXT DEFAULT '',
+			timestamp     DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		INSERT INTO smart_attributes (hostname, serial_number, device_name, device_by_id, timestamp)
+		VALUES ('host1', 'SER1', '/dev/sda', '', '2026-01-01 00:00:00'),
+		       ('host1', 'SER1', '/dev/sdb', '/dev/disk/by-id/ata-WDC_WD40EFRX_SER1', '2026-01-02 00:00:00'),
+		       ('host1', 'SER2', '/dev/sdc', '', '2026-01-02 00:00:00');
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create smart_attributes: %v", err)
+	}
+
+	info, err := getDriveInfo(db, "host1", "SER1")
+	if err != nil {
+		t.Fatalf("getDriveInfo failed: %v", err)
+	}
+	if info.DeviceName != "/dev/disk/by-id/ata-WDC_WD40EFRX_SER1" {
+		t.Errorf("Expected by-id path, got %q", info.DeviceName)
+	}
+
+	// Older agents don't send a by-id path; fall back to the kernel name
+	info, err = getDriveInfo(db, "host1", "SER2")
+	if err != nil {
+		t.Fatalf("getDriveInfo failed: %v", err)
+	}
+	if info.DeviceName != "/dev/sdc" {
+		t.Errorf("Expected kernel device name, got %q", info.DeviceName)
+	}
+}
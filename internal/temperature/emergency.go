@@ -0,0 +1,126 @@
+package temperature
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"vigil/internal/drivealerts"
+	"vigil/internal/settings"
+)
+
+const (
+	// emergencyAttempts is how often a webhook delivery is tried before
+	// giving up; attempt n waits n seconds before the next one.
+	emergencyAttempts = 3
+	// emergencyRepeat re-fires the hook for a drive that stays above the
+	// threshold, in case the receiver missed the first call.
+	emergencyRepeat = 10 * time.Minute
+)
+
+// EmergencyPayload is the JSON body POSTed to the emergency webhook
+type EmergencyPayload struct {
+	Event        string    `json:"event"` // always "temperature_emergency"
+	Hostname     string    `json:"hostname"`
+	SerialNumber string    `json:"serial_number"`
+	DeviceName   string    `json:"device_name,omitempty"`
+	Model        string    `json:"model,omitempty"`
+	Temperature  int       `json:"temperature"`
+	Threshold    int       `json:"threshold"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// EmergencyHook calls an external webhook as soon as a drive reaches
+// temperature.emergency_threshold, e.g. to start extra cooling or shut an
+// enclosure down. It is deliberately separate from notifications: no event
+// rules, quiet hours, digests or templates, just a JSON POST retried a few
+// times.
+type EmergencyHook struct {
+	URL    string
+	Client *http.Client
+
+	mu    sync.Mutex
+	fired map[string]time.Time // "hostname:serial" → last fire, while above the threshold
+}
+
+// NewEmergencyHook creates a hook posting to url
+func NewEmergencyHook(url string) *EmergencyHook {
+	return &EmergencyHook{
+		URL:    url,
+		Client: &http.Client{Timeout: 5 * time.Second},
+		fired:  make(map[string]time.Time),
+	}
+}
+
+// Check fires the hook in the background when temp is at or above the
+// emergency threshold and the drive has just crossed it, or is still above
+// it emergencyRepeat after the last call. Returns true when it fired.
+func (h *EmergencyHook) Check(db *sql.DB, hostname, serial, deviceName, model string, temp int) bool {
+	if h == nil || h.URL == "" {
+		return false
+	}
+	threshold := settings.GetInt(db, "temperature", "emergency_threshold", 0)
+	key := hostname + ":" + serial
+
+	h.mu.Lock()
+	if threshold <= 0 || temp < threshold {
+		delete(h.fired, key)
+		h.mu.Unlock()
+		return false
+	}
+	if last, ok := h.fired[key]; ok && time.Since(last) < emergencyRepeat {
+		h.mu.Unlock()
+		return false
+	}
+	if !drivealerts.Enabled(db, hostname, serial) {
+		h.mu.Unlock()
+		return false
+	}
+	h.fired[key] = time.Now()
+	h.mu.Unlock()
+
+	log.Printf("[Temperature] EMERGENCY: %s/%s at %d°C (threshold %d°C), calling emergency webhook", hostname, serial, temp, threshold)
+	go h.send(EmergencyPayload{
+		Event:        "temperature_emergency",
+		Hostname:     hostname,
+		SerialNumber: serial,
+		DeviceName:   deviceName,
+		Model:        model,
+		Temperature:  temp,
+		Threshold:    threshold,
+		Timestamp:    time.Now().UTC(),
+	})
+	return true
+}
+
+// send POSTs the payload, retrying on network errors and non-2xx replies
+func (h *EmergencyHook) send(p EmergencyPayload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= emergencyAttempts; attempt++ {
+		resp, err := h.Client.Post(h.URL, "application/json", bytes.NewReader(body))
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < 300 {
+				log.Printf("[Temperature] Emergency webhook accepted for %s/%s", p.Hostname, p.SerialNumber)
+				return nil
+			}
+			err = fmt.Errorf("HTTP %d", resp.StatusCode)
+		}
+		lastErr = err
+		log.Printf("[Temperature] Emergency webhook attempt %d/%d for %s/%s failed: %v", attempt, emergencyAttempts, p.Hostname, p.SerialNumber, err)
+		if attempt < emergencyAttempts {
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,68 @@
+package temperature
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEmergencyHook(t *testing.T) {
+	db := setupTempTestDB(t)
+	defer db.Close()
+
+	received := make(chan EmergencyPayload, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p EmergencyPayload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Errorf("bad payload: %v", err)
+		}
+		received <- p
+	}))
+	defer srv.Close()
+
+	hook := NewEmergencyHook(srv.URL)
+	wait := func() EmergencyPayload {
+		t.Helper()
+		select {
+		case p := <-received:
+			return p
+		case <-time.After(2 * time.Second):
+			t.Fatal("webhook not called")
+			return EmergencyPayload{}
+		}
+	}
+
+	// Default threshold is 65°C.
+	if hook.Check(db, "nas01", "SER1", "/dev/sda", "Disk", 60) {
+		t.Error("fired below the threshold")
+	}
+	if !hook.Check(db, "nas01", "SER1", "/dev/sda", "Disk", 66) {
+		t.Fatal("did not fire on crossing the threshold")
+	}
+	p := wait()
+	if p.Event != "temperature_emergency" || p.SerialNumber != "SER1" || p.Temperature != 66 || p.Threshold != 65 {
+		t.Errorf("unexpected payload: %+v", p)
+	}
+
+	if hook.Check(db, "nas01", "SER1", "/dev/sda", "Disk", 67) {
+		t.Error("fired again while still above the threshold")
+	}
+	if !hook.Check(db, "nas01", "SER2", "/dev/sdb", "Disk", 70) {
+		t.Error("another drive crossing should fire")
+	}
+	wait()
+
+	// Cooling down re-arms the hook.
+	hook.Check(db, "nas01", "SER1", "/dev/sda", "Disk", 50)
+	if !hook.Check(db, "nas01", "SER1", "/dev/sda", "Disk", 66) {
+		t.Error("did not fire on crossing again after cooling down")
+	}
+	wait()
+
+	var disabled *EmergencyHook
+	if disabled.Check(db, "nas01", "SER1", "/dev/sda", "Disk", 90) {
+		t.Error("nil hook fired")
+	}
+}
@@ -3,10 +3,13 @@ package temperature
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"vigil/internal/auth"
+	"vigil/internal/events"
 	"vigil/internal/settings"
 )
 
@@ -71,8 +74,29 @@ func (h *TemperatureHandler) GetAllTemperatureStats(w http.ResponseWriter, r *ht
 	})
 }
 
+// GetHostTemperatureStats handles GET /api/temperature/stats/host/{hostname}
+// Query params: period (24h, 7d, 30d, all)
+func (h *TemperatureHandler) GetHostTemperatureStats(w http.ResponseWriter, r *http.Request) {
+	hostname := r.PathValue("hostname")
+	period := ParsePeriod(r.URL.Query().Get("period"))
+
+	stats, err := GetHostTemperatureStats(h.DB, hostname, period)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if stats == nil {
+		http.Error(w, "no temperature data found", http.StatusNotFound)
+		return
+	}
+
+	jsonResponse(w, stats)
+}
+
 // GetTemperatureTimeSeries handles GET /api/temperature/timeseries
-// Query params: hostname, serial, period (24h, 7d, 30d, all), interval (1h, 6h, 1d)
+// Query params: hostname, serial, period (1h, 24h, 7d, 30d, 90d, all),
+// interval (5m, 15m, 1h, 6h, 1d, 1w, 1m) — validated against the period
 func (h *TemperatureHandler) GetTemperatureTimeSeries(w http.ResponseWriter, r *http.Request) {
 	hostname := r.URL.Query().Get("hostname")
 	serial := r.URL.Query().Get("serial")
@@ -91,6 +115,10 @@ func (h *TemperatureHandler) GetTemperatureTimeSeries(w http.ResponseWriter, r *
 	if intervalStr == "" {
 		interval = autoSelectInterval(period)
 	}
+	if err := ValidateInterval(period, interval); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	data, err := GetTemperatureTimeSeries(h.DB, hostname, serial, period, interval)
 	if err != nil {
@@ -106,6 +134,31 @@ func (h *TemperatureHandler) GetTemperatureTimeSeries(w http.ResponseWriter, r *
 	jsonResponse(w, data)
 }
 
+// GetFleetTemperatureTimeSeries handles GET /api/temperature/fleet/timeseries
+// Query params: period (1h, 24h, 7d, 30d, 90d, all), interval (5m, 15m, 1h,
+// 6h, 1d, 1w, 1m) — validated against the period
+func (h *TemperatureHandler) GetFleetTemperatureTimeSeries(w http.ResponseWriter, r *http.Request) {
+	intervalStr := r.URL.Query().Get("interval")
+	period := ParsePeriod(r.URL.Query().Get("period"))
+	interval := ParseInterval(intervalStr)
+
+	if intervalStr == "" {
+		interval = autoSelectInterval(period)
+	}
+	if err := ValidateInterval(period, interval); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := GetFleetTemperatureTimeSeries(h.DB, period, interval)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, data)
+}
+
 // GetCurrentTemperatures handles GET /api/temperature/current
 // Query params: hostname, serial (both optional - if not provided, returns all)
 func (h *TemperatureHandler) GetCurrentTemperatures(w http.ResponseWriter, r *http.Request) {
@@ -140,6 +193,65 @@ func (h *TemperatureHandler) GetCurrentTemperatures(w http.ResponseWriter, r *ht
 	})
 }
 
+// GetCurrentTemperaturesBatch handles POST /api/temperature/current/batch
+// Body: {"drives": [{"hostname", "serial"}, ...]} — up to MaxBatchDrives.
+// Returns current temperature and status for exactly those drives; requested
+// drives without temperature data are listed under "missing".
+func (h *TemperatureHandler) GetCurrentTemperaturesBatch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Drives []DriveRef `json:"drives"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Drives) == 0 {
+		http.Error(w, "drives is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Drives) > MaxBatchDrives {
+		http.Error(w, fmt.Sprintf("at most %d drives per request", MaxBatchDrives), http.StatusBadRequest)
+		return
+	}
+
+	seen := make(map[DriveRef]bool, len(req.Drives))
+	drives := make([]DriveRef, 0, len(req.Drives))
+	for _, d := range req.Drives {
+		if d.Hostname == "" || d.SerialNumber == "" {
+			http.Error(w, "each drive needs hostname and serial", http.StatusBadRequest)
+			return
+		}
+		if !seen[d] {
+			seen[d] = true
+			drives = append(drives, d)
+		}
+	}
+
+	temps, err := GetCurrentTemperaturesFor(h.DB, drives)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	found := make(map[DriveRef]bool, len(temps))
+	for _, t := range temps {
+		found[DriveRef{Hostname: t.Hostname, SerialNumber: t.SerialNumber}] = true
+	}
+	missing := []DriveRef{}
+	for _, d := range drives {
+		if !found[d] {
+			missing = append(missing, d)
+		}
+	}
+
+	jsonResponse(w, map[string]interface{}{
+		"drives":  temps,
+		"count":   len(temps),
+		"missing": missing,
+	})
+}
+
 // GetTemperatureSummary handles GET /api/temperature/summary
 func (h *TemperatureHandler) GetTemperatureSummary(w http.ResponseWriter, r *http.Request) {
 	summary, err := GetTemperatureSummary(h.DB)
@@ -152,7 +264,8 @@ func (h *TemperatureHandler) GetTemperatureSummary(w http.ResponseWriter, r *htt
 }
 
 // GetTemperatureHeatmap handles GET /api/temperature/heatmap
-// Query params: period (24h, 7d, 30d), interval (1h, 6h, 1d)
+// Query params: period (1h, 24h, 7d, 30d, 90d, all),
+// interval (5m, 15m, 1h, 6h, 1d, 1w, 1m) — validated against the period
 func (h *TemperatureHandler) GetTemperatureHeatmap(w http.ResponseWriter, r *http.Request) {
 	periodStr := r.URL.Query().Get("period")
 	intervalStr := r.URL.Query().Get("interval")
@@ -164,6 +277,10 @@ func (h *TemperatureHandler) GetTemperatureHeatmap(w http.ResponseWriter, r *htt
 	if intervalStr == "" {
 		interval = autoSelectInterval(period)
 	}
+	if err := ValidateInterval(period, interval); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	data, err := GetHeatmapData(h.DB, period, interval)
 	if err != nil {
@@ -175,7 +292,8 @@ func (h *TemperatureHandler) GetTemperatureHeatmap(w http.ResponseWriter, r *htt
 }
 
 // GetTemperatureRange handles GET /api/temperature/range
-// Query params: hostname, serial, from, to (ISO timestamps)
+// Query params: hostname, serial, from, to (ISO timestamps),
+// limit, offset, downsample (true to return every Nth point)
 func (h *TemperatureHandler) GetTemperatureRange(w http.ResponseWriter, r *http.Request) {
 	hostname := r.URL.Query().Get("hostname")
 	serial := r.URL.Query().Get("serial")
@@ -211,7 +329,19 @@ func (h *TemperatureHandler) GetTemperatureRange(w http.ResponseWriter, r *http.
 		to = time.Now()
 	}
 
-	records, err := GetTemperatureRange(h.DB, hostname, serial, from, to)
+	opts := RangeOptions{Downsample: r.URL.Query().Get("downsample") == "true"}
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if n, err := strconv.Atoi(l); err == nil && n > 0 {
+			opts.Limit = n
+		}
+	}
+	if o := r.URL.Query().Get("offset"); o != "" {
+		if n, err := strconv.Atoi(o); err == nil && n >= 0 {
+			opts.Offset = n
+		}
+	}
+
+	page, err := GetTemperatureRangePage(h.DB, hostname, serial, from, to, opts)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -222,8 +352,13 @@ func (h *TemperatureHandler) GetTemperatureRange(w http.ResponseWriter, r *http.
 		"serial_number": serial,
 		"from":          from,
 		"to":            to,
-		"records":       records,
-		"count":         len(records),
+		"records":       page.Records,
+		"count":         len(page.Records),
+		"total":         page.Total,
+		"limit":         page.Limit,
+		"offset":        page.Offset,
+		"step":          page.Step,
+		"truncated":     page.Truncated,
 	})
 }
 
@@ -278,11 +413,13 @@ func (h *TemperatureHandler) GetDashboardTemperature(w http.ResponseWriter, r *h
 // autoSelectInterval chooses an appropriate interval based on period
 func autoSelectInterval(period TemperaturePeriod) AggregationInterval {
 	switch period {
+	case Period1Hour:
+		return Interval5Min
 	case Period24Hours:
 		return IntervalHourly
 	case Period7Days:
 		return Interval6Hours
-	case Period30Days:
+	case Period30Days, Period90Days:
 		return IntervalDaily
 	case PeriodAllTime:
 		return IntervalDaily
@@ -306,14 +443,19 @@ func NewAlertHandler(database *sql.DB) *AlertHandler {
 }
 
 // GetAlerts handles GET /api/alerts/temperature
-// Query params: hostname, serial, type, acknowledged, since, limit
+// Query params: hostname, serial, type, category, acknowledged, since, limit
 func (h *AlertHandler) GetAlerts(w http.ResponseWriter, r *http.Request) {
 	filter := AlertFilter{
 		Hostname:     r.URL.Query().Get("hostname"),
 		SerialNumber: r.URL.Query().Get("serial"),
 		AlertType:    r.URL.Query().Get("type"),
+		Category:     r.URL.Query().Get("category"),
 		Limit:        50,
 	}
+	if filter.Category != "" && !events.ValidCause(filter.Category) {
+		http.Error(w, "unknown category: "+filter.Category, http.StatusBadRequest)
+		return
+	}
 
 	// Parse acknowledged filter
 	if ack := r.URL.Query().Get("acknowledged"); ack != "" {
@@ -439,6 +581,61 @@ func (h *AlertHandler) AcknowledgeAllAlerts(w http.ResponseWriter, r *http.Reque
 	})
 }
 
+// AcknowledgeMatchingAlerts handles POST /api/alerts/temperature/acknowledge
+// Body: {"hostname", "serial", "type"} — any combination, at least one.
+// "severity" is accepted as another name for type (warning, critical).
+func (h *AlertHandler) AcknowledgeMatchingAlerts(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Hostname string `json:"hostname"`
+		Serial   string `json:"serial"`
+		Type     string `json:"type"`
+		Severity string `json:"severity"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Type == "" {
+		req.Type = req.Severity
+	} else if req.Severity != "" && req.Severity != req.Type {
+		http.Error(w, "type and severity disagree", http.StatusBadRequest)
+		return
+	}
+
+	// An empty filter would acknowledge everything; that has its own
+	// endpoint so it can't happen by accident here.
+	if req.Hostname == "" && req.Serial == "" && req.Type == "" {
+		http.Error(w, "at least one of hostname, serial or type is required (use acknowledge-all to acknowledge everything)", http.StatusBadRequest)
+		return
+	}
+
+	switch req.Type {
+	case "", AlertTypeWarning, AlertTypeCritical, AlertTypeSpike, AlertTypeRecovery:
+	default:
+		http.Error(w, "invalid alert type", http.StatusBadRequest)
+		return
+	}
+
+	filter := AlertFilter{
+		Hostname:     req.Hostname,
+		SerialNumber: req.Serial,
+		AlertType:    req.Type,
+	}
+
+	count, err := AcknowledgeAlerts(h.DB, filter, getUsernameFromRequest(r))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, map[string]interface{}{
+		"message":      "alerts acknowledged",
+		"acknowledged": count,
+	})
+}
+
 // DeleteAlert handles DELETE /api/alerts/temperature/{id}
 func (h *AlertHandler) DeleteAlert(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
@@ -538,7 +735,7 @@ func (h *AlertHandler) TestAlert(w http.ResponseWriter, r *http.Request) {
 // CleanupAlerts handles POST /api/alerts/temperature/cleanup
 // Removes old alerts based on retention settings
 func (h *AlertHandler) CleanupAlerts(w http.ResponseWriter, r *http.Request) {
-	retentionDays := settings.GetIntSettingWithDefault(h.DB, "system", "data_retention_days", 365)
+	retentionDays := settings.RetentionDays(h.DB, "alert_days", 365)
 
 	deleted, err := CleanupOldAlerts(h.DB, retentionDays)
 	if err != nil {
@@ -568,36 +765,69 @@ func NewSpikeHandler(database *sql.DB) *SpikeHandler {
 }
 
 // GetSpikes handles GET /api/temperature/spikes
-// Query params: hostname, serial (optional - filter by drive), limit (default 50)
+// Query params (all optional): hostname, serial, since, until (RFC3339),
+// acknowledged (true/false), min_change (only spikes of more than N degrees),
+// limit (default 50, max MaxSpikePageSize), offset
 func (h *SpikeHandler) GetSpikes(w http.ResponseWriter, r *http.Request) {
-	hostname := r.URL.Query().Get("hostname")
-	serial := r.URL.Query().Get("serial")
-	limitStr := r.URL.Query().Get("limit")
+	q := r.URL.Query()
+	filter := SpikeFilter{
+		Hostname:     q.Get("hostname"),
+		SerialNumber: q.Get("serial"),
+		Limit:        50,
+	}
 
-	limit := 50
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 200 {
-			limit = l
+	if ack := q.Get("acknowledged"); ack != "" {
+		acknowledged := ack == "true"
+		filter.Acknowledged = &acknowledged
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		if v := q.Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				http.Error(w, "invalid '"+p.name+"' timestamp format (use RFC3339)", http.StatusBadRequest)
+				return
+			}
+			*p.dst = t
 		}
 	}
 
-	var spikes []TemperatureSpike
-	var err error
+	if v := q.Get("min_change"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "min_change must be a non-negative number of degrees", http.StatusBadRequest)
+			return
+		}
+		filter.MinChange = n
+	}
 
-	if hostname != "" && serial != "" {
-		spikes, err = GetRecentSpikes(h.DB, hostname, serial, limit)
-	} else {
-		spikes, err = GetAllRecentSpikes(h.DB, limit)
+	if l := q.Get("limit"); l != "" {
+		if n, err := strconv.Atoi(l); err == nil && n > 0 {
+			filter.Limit = n
+		}
+	}
+	if o := q.Get("offset"); o != "" {
+		if n, err := strconv.Atoi(o); err == nil && n >= 0 {
+			filter.Offset = n
+		}
 	}
 
+	page, err := GetSpikesPage(h.DB, filter)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	jsonResponse(w, map[string]interface{}{
-		"spikes": spikes,
-		"count":  len(spikes),
+		"spikes":    page.Spikes,
+		"count":     len(page.Spikes),
+		"total":     page.Total,
+		"limit":     page.Limit,
+		"offset":    page.Offset,
+		"truncated": page.Truncated,
 	})
 }
 
@@ -880,16 +1110,9 @@ func (h *DashboardHandler) GetDashboardStatus(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Determine overall health
-	health := "healthy"
-	if overview.Status == "critical" {
-		health = "critical"
-	} else if overview.Status == "warning" || overview.ActiveAlerts > 0 {
-		health = "degraded"
-	}
-
 	jsonResponse(w, map[string]interface{}{
-		"health":             health,
+		"health":             overview.Health,
+		"health_score":       overview.HealthScore,
 		"status":             overview.Status,
 		"total_drives":       overview.TotalDrives,
 		"drives_with_issues": overview.DrivesWithIssues,
@@ -1043,18 +1266,12 @@ func (h *DashboardHandler) getDriveStatusWidget(w http.ResponseWriter, _ *http.R
 // SHARED HELPERS
 // ============================================
 
-// getUsernameFromRequest extracts username from request context or headers
+// getUsernameFromRequest returns the signed-in user, or "system" when
+// authentication is disabled
 func getUsernameFromRequest(r *http.Request) string {
-	// Try to get from context (set by auth middleware)
-	if username, ok := r.Context().Value("username").(string); ok {
-		return username
-	}
-
-	// Fallback to header
-	if username := r.Header.Get("X-Username"); username != "" {
-		return username
+	if s := auth.GetSessionFromContext(r); s != nil {
+		return s.Username
 	}
-
 	return "system"
 }
 
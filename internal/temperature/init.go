@@ -4,7 +4,10 @@ import (
 	"database/sql"
 	"log"
 
+	agentsmart "vigil/cmd/agent/smart"
+	"vigil/internal/events"
 	"vigil/internal/settings"
+	"vigil/internal/smart"
 )
 
 // InitializeTables creates all temperature-related database tables
@@ -26,6 +29,11 @@ func InitializeTables(database *sql.DB) error {
 		return err
 	}
 
+	// Initialize learned temperature baselines table
+	if err := InitTemperatureBaselinesTable(database); err != nil {
+		return err
+	}
+
 	// Create additional indexes for temperature_history if needed
 	_, err := database.Exec(`
 		CREATE INDEX IF NOT EXISTS idx_temp_hist_combined 
@@ -108,9 +116,10 @@ func ValidateTemperature(temp int) bool {
 	return temp >= -40 && temp <= 100
 }
 
-// ProcessDriveTemperature is a convenience function to process a single drive's temperature
-// Call this from your report handler after storing SMART data
-func ProcessDriveTemperature(database *sql.DB, hostname, serial string, temperature int) error {
+// ProcessDriveTemperature checks a single drive's temperature against the
+// alert thresholds and spike detection, and publishes the alerts it raises
+// to bus, which may be nil. Call this after storing the drive's SMART data.
+func ProcessDriveTemperature(database *sql.DB, bus *events.Bus, hostname, serial string, temperature int) error {
 	if !ValidateTemperature(temperature) {
 		return nil // Invalid temperature, skip silently
 	}
@@ -120,10 +129,33 @@ func ProcessDriveTemperature(database *sql.DB, hostname, serial string, temperat
 		return err
 	}
 
-	for _, alert := range alerts {
+	for i := range alerts {
 		log.Printf("[Temperature] Alert generated: %s - %s (%s/%s)",
-			alert.AlertType, alert.Message, hostname, serial)
+			alerts[i].AlertType, alerts[i].Message, hostname, serial)
+		publishAlert(bus, hostname, serial, &alerts[i])
 	}
 
 	return nil
 }
+
+// ProcessReport runs ProcessDriveTemperature for every drive of a live
+// report whose reading is within temperature/min_valid and max_valid, i.e.
+// was stored. Imported reports shouldn't be passed here: their readings are
+// not current.
+func ProcessReport(database *sql.DB, bus *events.Bus, hostname string, payload map[string]interface{}) {
+	drives, _ := payload["drives"].([]interface{})
+	bounds := smart.LoadTemperatureBounds(database)
+	for _, d := range drives {
+		dm, ok := d.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		drive, err := agentsmart.ParseSmartAttributes(dm, hostname)
+		if err != nil || drive.SerialNumber == "" || !bounds.Valid(drive.Temperature) {
+			continue
+		}
+		if err := ProcessDriveTemperature(database, bus, hostname, drive.SerialNumber, drive.Temperature); err != nil {
+			log.Printf("[Temperature] Processing error for %s/%s: %v", hostname, drive.SerialNumber, err)
+		}
+	}
+}
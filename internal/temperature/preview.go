@@ -0,0 +1,81 @@
+package temperature
+
+import "database/sql"
+
+// StatusCounts is how many drives are in each temperature status
+type StatusCounts struct {
+	Normal   int `json:"normal"`
+	Warning  int `json:"warning"`
+	Critical int `json:"critical"`
+}
+
+func (c *StatusCounts) add(status string) {
+	switch status {
+	case "normal":
+		c.Normal++
+	case "warning":
+		c.Warning++
+	case "critical":
+		c.Critical++
+	}
+}
+
+// PreviewDrive is a drive whose status would change under the proposed
+// thresholds.
+type PreviewDrive struct {
+	Hostname       string `json:"hostname"`
+	SerialNumber   string `json:"serial_number"`
+	DeviceName     string `json:"device_name,omitempty"`
+	Model          string `json:"model,omitempty"`
+	Temperature    int    `json:"temperature"`
+	CurrentStatus  string `json:"current_status"`
+	ProposedStatus string `json:"proposed_status"`
+}
+
+// ThresholdPreview compares the drives' current temperature statuses with
+// the ones they would have under proposed thresholds.
+type ThresholdPreview struct {
+	Current        TemperatureThresholds `json:"current"`
+	Proposed       TemperatureThresholds `json:"proposed"`
+	TotalDrives    int                   `json:"total_drives"`
+	CurrentCounts  StatusCounts          `json:"current_counts"`
+	ProposedCounts StatusCounts          `json:"proposed_counts"`
+	Changed        []PreviewDrive        `json:"changed"`
+}
+
+// PreviewThresholds re-classifies every drive's latest reading against
+// proposed thresholds without saving them, to show what changing the
+// temperature settings would do before doing it.
+func PreviewThresholds(db *sql.DB, proposed TemperatureThresholds) (*ThresholdPreview, error) {
+	temps, err := GetAllCurrentTemperatures(db)
+	if err != nil {
+		return nil, err
+	}
+	return previewThresholds(temps, getThresholdsFromSettings(db), proposed), nil
+}
+
+func previewThresholds(temps []CurrentTemperature, current, proposed TemperatureThresholds) *ThresholdPreview {
+	p := &ThresholdPreview{
+		Current:     current,
+		Proposed:    proposed,
+		TotalDrives: len(temps),
+		Changed:     []PreviewDrive{},
+	}
+	for _, t := range temps {
+		was, would := current.GetStatus(t.Temperature), proposed.GetStatus(t.Temperature)
+		p.CurrentCounts.add(was)
+		p.ProposedCounts.add(would)
+		if was != would {
+			p.Changed = append(p.Changed, PreviewDrive{
+				Hostname:       t.Hostname,
+				SerialNumber:   t.SerialNumber,
+				DeviceName:     t.DeviceName,
+				Model:          t.Model,
+				Temperature:    t.Temperature,
+				CurrentStatus:  was,
+				ProposedStatus: would,
+			})
+		}
+	}
+	return p
+}
@@ -0,0 +1,36 @@
+package temperature
+
+import "testing"
+
+func TestPreviewThresholds(t *testing.T) {
+	temps := []CurrentTemperature{
+		{Hostname: "nas01", SerialNumber: "A", Temperature: 40},
+		{Hostname: "nas01", SerialNumber: "B", Temperature: 47},
+		{Hostname: "nas02", SerialNumber: "C", Temperature: 52},
+		{Hostname: "nas02", SerialNumber: "D", Temperature: 58},
+		{Hostname: "nas02", SerialNumber: "E", Temperature: 61},
+	}
+
+	p := previewThresholds(temps, DefaultThresholds(), TemperatureThresholds{Warning: 50, Critical: 60})
+
+	if p.TotalDrives != 5 {
+		t.Errorf("expected 5 drives, got %d", p.TotalDrives)
+	}
+	if want := (StatusCounts{Normal: 1, Warning: 2, Critical: 2}); p.CurrentCounts != want {
+		t.Errorf("current counts = %+v, want %+v", p.CurrentCounts, want)
+	}
+	if want := (StatusCounts{Normal: 2, Warning: 2, Critical: 1}); p.ProposedCounts != want {
+		t.Errorf("proposed counts = %+v, want %+v", p.ProposedCounts, want)
+	}
+
+	// B warning→normal, D critical→warning; C and E keep their status
+	if len(p.Changed) != 2 {
+		t.Fatalf("expected 2 changed drives, got %+v", p.Changed)
+	}
+	if c := p.Changed[0]; c.SerialNumber != "B" || c.CurrentStatus != "warning" || c.ProposedStatus != "normal" {
+		t.Errorf("unexpected change %+v", c)
+	}
+	if c := p.Changed[1]; c.SerialNumber != "D" || c.CurrentStatus != "critical" || c.ProposedStatus != "warning" {
+		t.Errorf("unexpected change %+v", c)
+	}
+}
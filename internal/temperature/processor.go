@@ -116,7 +116,13 @@ func (p *Processor) processReadingSync(hostname, serial string, temperature int)
 
 // publishAlert sends a temperature alert to the event bus.
 func (p *Processor) publishAlert(hostname, serial string, alert *TemperatureAlert) {
-	if p.Bus == nil {
+	publishAlert(p.Bus, hostname, serial, alert)
+}
+
+// publishAlert sends a temperature alert to bus, if there is one. A repeat
+// folded into an already published alert (see RecordAlert) isn't sent again.
+func publishAlert(bus *events.Bus, hostname, serial string, alert *TemperatureAlert) {
+	if bus == nil || alert.OccurrenceCount > 1 {
 		return
 	}
 
@@ -137,7 +143,7 @@ func (p *Processor) publishAlert(hostname, serial string, alert *TemperatureAler
 		return
 	}
 
-	p.Bus.Publish(events.Event{
+	bus.Publish(events.Event{
 		Type:         evtType,
 		Severity:     severity,
 		Hostname:     hostname,
@@ -180,7 +186,7 @@ func (p *Processor) periodicTasks() {
 
 // runCleanup removes old temperature data based on retention settings
 func (p *Processor) runCleanup() {
-	retentionDays := settings.GetIntSettingWithDefault(p.DB, "temperature", "retention_days", 90)
+	retentionDays := settings.RetentionDays(p.DB, "temperature_days", 90)
 
 	// Cleanup temperature history
 	deleted, err := CleanupOldTemperatureData(p.DB, retentionDays)
@@ -199,7 +205,7 @@ func (p *Processor) runCleanup() {
 	}
 
 	// Cleanup old alerts
-	alertRetention := settings.GetIntSettingWithDefault(p.DB, "system", "data_retention_days", 365)
+	alertRetention := settings.RetentionDays(p.DB, "alert_days", 365)
 	deleted, err = CleanupOldAlerts(p.DB, alertRetention)
 	if err != nil {
 		log.Printf("[Temperature] Alert cleanup error: %v", err)
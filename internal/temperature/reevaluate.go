@@ -0,0 +1,113 @@
+package temperature
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"vigil/internal/events"
+	"vigil/internal/settings"
+)
+
+// Reevaluation is what a pass over the stored temperatures created
+type Reevaluation struct {
+	DrivesChecked int                `json:"drives_checked"`
+	Alerts        []TemperatureAlert `json:"alerts"`
+	Spikes        []TemperatureSpike `json:"spikes"`
+}
+
+// Reevaluate checks every drive's latest reading against the current
+// thresholds and runs spike detection over its whole retained history, so
+// backfilled data and changed settings are assessed like a new reading
+// would be. New alerts are published to bus, which may be nil.
+//
+// The history is scanned without holding anything; only the alerts and
+// spikes found for a drive are written, one drive per call to write (nil
+// runs it directly), so the server's report ingestion isn't held up for the
+// whole pass.
+func Reevaluate(db *sql.DB, bus *events.Bus, write func(func() error) error) (*Reevaluation, error) {
+	if write == nil {
+		write = func(fn func() error) error { return fn() }
+	}
+
+	rows, err := db.Query(`
+		SELECT th.hostname, th.serial_number, th.temperature
+		FROM temperature_history th
+		INNER JOIN (
+			SELECT hostname, serial_number, MAX(timestamp) AS max_ts
+			FROM temperature_history
+			GROUP BY hostname, serial_number
+		) latest ON th.hostname = latest.hostname
+			AND th.serial_number = latest.serial_number
+			AND th.timestamp = latest.max_ts
+		ORDER BY th.hostname, th.serial_number
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest temperatures: %w", err)
+	}
+
+	type reading struct {
+		hostname, serial string
+		temperature      int
+	}
+	var latest []reading
+	for rows.Next() {
+		var r reading
+		if err := rows.Scan(&r.hostname, &r.serial, &r.temperature); err != nil {
+			continue
+		}
+		latest = append(latest, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	windowMinutes := settings.GetIntSettingWithDefault(db, "temperature", "spike_window_minutes", 30)
+	thresholdDegrees := settings.GetIntSettingWithDefault(db, "temperature", "spike_threshold", 10)
+
+	result := &Reevaluation{Alerts: []TemperatureAlert{}, Spikes: []TemperatureSpike{}}
+	seen := make(map[string]bool)
+	for _, r := range latest {
+		// Two readings can share a drive's latest timestamp.
+		key := r.hostname + ":" + r.serial
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result.DrivesChecked++
+
+		detected, err := detectSpikesSince(db, r.hostname, r.serial, time.Time{}, windowMinutes, thresholdDegrees)
+		if err != nil {
+			return result, err
+		}
+
+		var alerts []TemperatureAlert
+		err = write(func() error {
+			alert, err := CheckTemperatureAndAlert(db, r.hostname, r.serial, r.temperature)
+			if err != nil {
+				return err
+			}
+			if alert != nil {
+				alerts = append(alerts, *alert)
+			}
+			for _, spike := range recordNewSpikes(db, r.hostname, r.serial, detected) {
+				result.Spikes = append(result.Spikes, spike)
+				spikeAlert, err := CreateSpikeAlert(db, &spike)
+				if err != nil || spikeAlert == nil {
+					continue
+				}
+				alerts = append(alerts, *spikeAlert)
+			}
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+		for i := range alerts {
+			result.Alerts = append(result.Alerts, alerts[i])
+			publishAlert(bus, r.hostname, r.serial, &alerts[i])
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package temperature
+
+import (
+	"testing"
+	"time"
+
+	"vigil/internal/events"
+)
+
+func TestReevaluate(t *testing.T) {
+	db := setupAlertTestDB(t)
+	defer db.Close()
+
+	// A spike three days ago, long outside the live detection window, and
+	// a latest reading above the warning threshold.
+	now := time.Now()
+	readings := []struct {
+		temp int
+		at   time.Time
+	}{
+		{35, now.Add(-72 * time.Hour)},
+		{50, now.Add(-72*time.Hour + 10*time.Minute)},
+		{48, now},
+	}
+	for _, r := range readings {
+		if _, err := db.Exec(`INSERT INTO temperature_history (hostname, serial_number, temperature, timestamp) VALUES (?, ?, ?, ?)`,
+			"server1", "SERIAL001", r.temp, r.at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bus := events.NewBus()
+	var published []events.Event
+	bus.Subscribe(func(e events.Event) { published = append(published, e) })
+
+	writes := 0
+	result, err := Reevaluate(db, bus, func(fn func() error) error {
+		writes++
+		return fn()
+	})
+	if err != nil {
+		t.Fatalf("Reevaluate failed: %v", err)
+	}
+	if result.DrivesChecked != 1 {
+		t.Errorf("DrivesChecked = %d, want 1", result.DrivesChecked)
+	}
+	if writes != 1 {
+		t.Errorf("expected one write per drive, got %d", writes)
+	}
+	if len(result.Spikes) != 1 {
+		t.Fatalf("expected 1 spike, got %d", len(result.Spikes))
+	}
+	if len(result.Alerts) != 2 {
+		t.Fatalf("expected warning and spike alerts, got %d", len(result.Alerts))
+	}
+	if len(published) != 2 {
+		t.Errorf("expected 2 published events, got %d", len(published))
+	}
+
+	// A second pass finds nothing new: the spike is recorded and the
+	// warning is within its cooldown.
+	again, err := Reevaluate(db, nil, nil)
+	if err != nil {
+		t.Fatalf("second Reevaluate failed: %v", err)
+	}
+	if len(again.Spikes) != 0 || len(again.Alerts) != 0 {
+		t.Errorf("second pass created %d spikes and %d alerts, want none", len(again.Spikes), len(again.Alerts))
+	}
+}
@@ -110,6 +110,109 @@ func GetAllRecentSpikes(db *sql.DB, limit int) ([]TemperatureSpike, error) {
 	return querySpikes(db, query, limit)
 }
 
+// MaxSpikePageSize caps how many spikes a single GetSpikesPage call returns.
+const MaxSpikePageSize = 500
+
+// SpikeFilter for querying spikes. Zero values match everything.
+type SpikeFilter struct {
+	Hostname     string
+	SerialNumber string
+	Acknowledged *bool
+	Since        time.Time // spikes starting at or after
+	Until        time.Time // spikes starting before
+	MinChange    int       // only spikes of more than this many degrees, either direction
+	Limit        int       // page size; 0 or anything above MaxSpikePageSize is clamped to it
+	Offset       int
+}
+
+// SpikePage is one page of the spikes matching a SpikeFilter, newest first
+type SpikePage struct {
+	Spikes    []TemperatureSpike `json:"spikes"`
+	Total     int                `json:"total"` // Spikes matching the filter across all pages
+	Limit     int                `json:"limit"` // Effective page size after clamping
+	Offset    int                `json:"offset"`
+	Truncated bool               `json:"truncated"` // More spikes exist beyond this page
+}
+
+// GetSpikesPage retrieves one page of spikes matching filter, with the total
+// number that match so a client can page through them.
+func GetSpikesPage(db *sql.DB, filter SpikeFilter) (*SpikePage, error) {
+	limit := filter.Limit
+	if limit <= 0 || limit > MaxSpikePageSize {
+		limit = MaxSpikePageSize
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	where := " WHERE 1=1"
+	args := []interface{}{}
+
+	if filter.Hostname != "" {
+		where += " AND hostname = ?"
+		args = append(args, filter.Hostname)
+	}
+
+	if filter.SerialNumber != "" {
+		where += " AND serial_number = ?"
+		args = append(args, filter.SerialNumber)
+	}
+
+	if filter.Acknowledged != nil {
+		if *filter.Acknowledged {
+			where += " AND acknowledged = 1"
+		} else {
+			where += " AND acknowledged = 0"
+		}
+	}
+
+	if !filter.Since.IsZero() {
+		where += " AND start_time >= ?"
+		args = append(args, filter.Since.UTC())
+	}
+
+	if !filter.Until.IsZero() {
+		where += " AND start_time < ?"
+		args = append(args, filter.Until.UTC())
+	}
+
+	if filter.MinChange > 0 {
+		where += " AND ABS(change_degrees) > ?"
+		args = append(args, filter.MinChange)
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM temperature_spikes"+where, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count spikes: %w", err)
+	}
+
+	query := `
+		SELECT id, hostname, serial_number, start_time, end_time,
+			   start_temp, end_temp, change_degrees, rate_per_minute,
+			   direction, acknowledged, COALESCE(acknowledged_by, ''),
+			   acknowledged_at, created_at
+		FROM temperature_spikes` + where + `
+		ORDER BY start_time DESC, id DESC
+		LIMIT ? OFFSET ?
+	`
+	spikes, err := querySpikes(db, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, err
+	}
+	if spikes == nil {
+		spikes = []TemperatureSpike{}
+	}
+
+	return &SpikePage{
+		Spikes:    spikes,
+		Total:     total,
+		Limit:     limit,
+		Offset:    offset,
+		Truncated: offset+len(spikes) < total,
+	}, nil
+}
+
 // GetUnacknowledgedSpikes retrieves all unacknowledged spikes
 func GetUnacknowledgedSpikes(db *sql.DB) ([]TemperatureSpike, error) {
 	query := `
@@ -246,6 +349,8 @@ func querySpikes(db *sql.DB, query string, args ...interface{}) ([]TemperatureSp
 	}
 	defer rows.Close()
 
+	threshold := settings.GetIntSettingWithDefault(db, "temperature", "spike_threshold", 10)
+
 	var spikes []TemperatureSpike
 	for rows.Next() {
 		var spike TemperatureSpike
@@ -264,6 +369,7 @@ func querySpikes(db *sql.DB, query string, args ...interface{}) ([]TemperatureSp
 		if ackAt.Valid {
 			spike.AcknowledgedAt = ackAt.Time
 		}
+		spike.Severity = SpikeSeverity(spike.Change, threshold)
 
 		// Get drive info
 		driveInfo, _ := getDriveInfo(db, spike.Hostname, spike.SerialNumber)
@@ -278,6 +384,19 @@ func querySpikes(db *sql.DB, query string, args ...interface{}) ([]TemperatureSp
 	return spikes, rows.Err()
 }
 
+// SpikeSeverity rates a spike by its size: at least twice the configured
+// spike threshold, in either direction, is critical; anything smaller a
+// warning.
+func SpikeSeverity(change, thresholdDegrees int) string {
+	if change < 0 {
+		change = -change
+	}
+	if thresholdDegrees > 0 && change >= 2*thresholdDegrees {
+		return "critical"
+	}
+	return "warning"
+}
+
 // ============================================
 // SPIKE DETECTION ALGORITHM
 // ============================================
@@ -286,7 +405,12 @@ func querySpikes(db *sql.DB, query string, args ...interface{}) ([]TemperatureSp
 func DetectSpikes(db *sql.DB, hostname, serial string, windowMinutes, thresholdDegrees int) ([]TemperatureSpike, error) {
 	// Get temperature readings within the detection window
 	cutoff := time.Now().Add(-time.Duration(windowMinutes*2) * time.Minute)
+	return detectSpikesSince(db, hostname, serial, cutoff, windowMinutes, thresholdDegrees)
+}
 
+// detectSpikesSince runs spike detection over a drive's readings from
+// cutoff on.
+func detectSpikesSince(db *sql.DB, hostname, serial string, cutoff time.Time, windowMinutes, thresholdDegrees int) ([]TemperatureSpike, error) {
 	query := `
 		SELECT temperature, timestamp
 		FROM temperature_history
@@ -355,6 +479,7 @@ func DetectSpikes(db *sql.DB, hostname, serial string, windowMinutes, thresholdD
 					Change:       absChange,
 					RatePerMin:   math.Round(ratePerMin*100) / 100,
 					Direction:    direction,
+					Severity:     SpikeSeverity(absChange, thresholdDegrees),
 				}
 
 				spikes = append(spikes, spike)
@@ -381,10 +506,12 @@ func DetectAndRecordSpikes(db *sql.DB, hostname, serial string) ([]TemperatureSp
 		return nil, err
 	}
 
-	if len(detected) == 0 {
-		return nil, nil
-	}
+	return recordNewSpikes(db, hostname, serial, detected), nil
+}
 
+// recordNewSpikes saves the detected spikes that aren't recorded yet and
+// returns them.
+func recordNewSpikes(db *sql.DB, hostname, serial string, detected []TemperatureSpike) []TemperatureSpike {
 	// Check which spikes are new (not already recorded)
 	var newSpikes []TemperatureSpike
 
@@ -404,7 +531,7 @@ func DetectAndRecordSpikes(db *sql.DB, hostname, serial string) ([]TemperatureSp
 		}
 	}
 
-	return newSpikes, nil
+	return newSpikes
 }
 
 // spikeExists checks if a spike with similar time range already exists
@@ -468,8 +595,12 @@ func DetectAllDrivesSpikes(db *sql.DB) ([]TemperatureSpike, error) {
 	return allSpikes, nil
 }
 
-// CleanupOldSpikes removes spike records older than retention period
+// CleanupOldSpikes removes spike records older than retention period.
+// A retentionDays of 0 or less is a no-op ("keep forever").
 func CleanupOldSpikes(db *sql.DB, retentionDays int) (int64, error) {
+	if retentionDays <= 0 {
+		return 0, nil
+	}
 	cutoff := time.Now().AddDate(0, 0, -retentionDays)
 
 	result, err := db.Exec(`
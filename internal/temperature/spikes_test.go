@@ -167,6 +167,57 @@ func TestGetAllRecentSpikes(t *testing.T) {
 	}
 }
 
+func TestGetSpikesPage(t *testing.T) {
+	db := setupSpikeTestDB(t)
+	defer db.Close()
+
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, change := range []int{3, 12, 25, 4, 15} {
+		spike := &TemperatureSpike{
+			Hostname:     "server1",
+			SerialNumber: "SERIAL001",
+			StartTime:    t0.Add(time.Duration(i) * time.Hour),
+			EndTime:      t0.Add(time.Duration(i)*time.Hour + 10*time.Minute),
+			StartTemp:    35,
+			EndTemp:      35 + change,
+			Change:       change,
+			Direction:    "heating",
+		}
+		if err := RecordSpike(db, spike); err != nil {
+			t.Fatalf("RecordSpike: %v", err)
+		}
+		if i == 2 {
+			AcknowledgeSpike(db, spike.ID, "admin")
+		}
+	}
+
+	page, err := GetSpikesPage(db, SpikeFilter{MinChange: 10})
+	if err != nil {
+		t.Fatalf("GetSpikesPage failed: %v", err)
+	}
+	if page.Total != 3 || len(page.Spikes) != 3 {
+		t.Fatalf("min_change 10: total %d, got %d spikes, want 3", page.Total, len(page.Spikes))
+	}
+	if page.Spikes[0].Change != 15 || page.Spikes[1].Severity != "critical" || page.Spikes[0].Severity != "warning" {
+		t.Errorf("spikes = %+v, want newest first with severity", page.Spikes)
+	}
+
+	page, _ = GetSpikesPage(db, SpikeFilter{MinChange: 10, Since: t0.Add(time.Hour), Until: t0.Add(4 * time.Hour)})
+	if page.Total != 2 {
+		t.Errorf("window: total = %d, want 2", page.Total)
+	}
+
+	acked := false
+	page, _ = GetSpikesPage(db, SpikeFilter{MinChange: 10, Acknowledged: &acked, Limit: 1})
+	if page.Total != 2 || len(page.Spikes) != 1 || !page.Truncated {
+		t.Errorf("unacknowledged page 1 = %+v", page)
+	}
+	page, _ = GetSpikesPage(db, SpikeFilter{MinChange: 10, Acknowledged: &acked, Limit: 1, Offset: 1})
+	if len(page.Spikes) != 1 || page.Spikes[0].Change != 12 || page.Truncated {
+		t.Errorf("unacknowledged page 2 = %+v", page)
+	}
+}
+
 func TestGetUnacknowledgedSpikes(t *testing.T) {
 	db := setupSpikeTestDB(t)
 	defer db.Close()
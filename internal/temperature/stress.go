@@ -0,0 +1,138 @@
+package temperature
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ThermalStress is how long a drive spent at or above the temperature
+// thresholds over a period: cumulative heat exposure that the current
+// reading alone doesn't show. Time at or above critical also counts as
+// time at or above warning.
+type ThermalStress struct {
+	Hostname             string  `json:"hostname"`
+	SerialNumber         string  `json:"serial_number"`
+	Period               string  `json:"period"`
+	WarningThreshold     int     `json:"warning_threshold"`
+	CriticalThreshold    int     `json:"critical_threshold"`
+	DataPoints           int     `json:"data_points"`
+	TrackedHours         float64 `json:"tracked_hours"`
+	HoursAboveWarning    float64 `json:"hours_above_warning"`
+	HoursAboveCritical   float64 `json:"hours_above_critical"`
+	PercentAboveWarning  float64 `json:"percent_above_warning"`
+	PercentAboveCritical float64 `json:"percent_above_critical"`
+}
+
+// GetThermalStress estimates the time the drive spent above the configured
+// warning and critical thresholds in the period. Returns nil if the drive
+// has no readings in the period.
+func GetThermalStress(db *sql.DB, hostname, serial string, period TemperaturePeriod) (*ThermalStress, error) {
+	timeFilter := ""
+	args := []interface{}{hostname, serial}
+	if period != PeriodAllTime {
+		timeFilter = "AND timestamp >= datetime('now', ?)"
+		args = append(args, periodToSQLInterval(period))
+	}
+
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT temperature, timestamp
+		FROM temperature_history
+		WHERE hostname = ? AND serial_number = ? %s
+		ORDER BY timestamp ASC
+	`, timeFilter), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get temperature readings: %w", err)
+	}
+	defer rows.Close()
+
+	var readings []TempReading
+	for rows.Next() {
+		var r TempReading
+		var ts string
+		if err := rows.Scan(&r.Temperature, &ts); err != nil {
+			continue
+		}
+		if r.Timestamp, err = parseTimestamp(ts); err != nil {
+			continue
+		}
+		readings = append(readings, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(readings) == 0 {
+		return nil, nil
+	}
+
+	stress := computeThermalStress(readings, getThresholdsFromSettings(db), time.Now().UTC())
+	stress.Hostname = hostname
+	stress.SerialNumber = serial
+	stress.Period = string(period)
+	return stress, nil
+}
+
+// computeThermalStress credits each reading with the time until the next
+// one, so the estimate follows the agent's actual reporting interval. A gap
+// longer than twice the median interval means the agent was down; only
+// twice the median is credited, since nobody knows how hot the drive ran
+// meanwhile. The last reading gets one median interval, cut short at now.
+// readings must be sorted by time.
+func computeThermalStress(readings []TempReading, thresholds TemperatureThresholds, now time.Time) *ThermalStress {
+	stress := &ThermalStress{
+		WarningThreshold:  thresholds.Warning,
+		CriticalThreshold: thresholds.Critical,
+		DataPoints:        len(readings),
+	}
+	if len(readings) < 2 {
+		return stress
+	}
+
+	gaps := make([]time.Duration, 0, len(readings)-1)
+	for i := 1; i < len(readings); i++ {
+		gaps = append(gaps, readings[i].Timestamp.Sub(readings[i-1].Timestamp))
+	}
+	sorted := append([]time.Duration(nil), gaps...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	median := sorted[len(sorted)/2]
+	maxGap := 2 * median
+
+	var tracked, warning, critical time.Duration
+	for i, r := range readings {
+		span := median
+		if i < len(gaps) {
+			span = gaps[i]
+		} else if left := now.Sub(r.Timestamp); left < span {
+			span = left
+		}
+		if span > maxGap {
+			span = maxGap
+		}
+		if span <= 0 {
+			continue
+		}
+
+		tracked += span
+		if r.Temperature >= thresholds.Warning {
+			warning += span
+		}
+		if r.Temperature >= thresholds.Critical {
+			critical += span
+		}
+	}
+
+	stress.TrackedHours = roundHours(tracked)
+	stress.HoursAboveWarning = roundHours(warning)
+	stress.HoursAboveCritical = roundHours(critical)
+	if tracked > 0 {
+		stress.PercentAboveWarning = math.Round(float64(warning)/float64(tracked)*10000) / 100
+		stress.PercentAboveCritical = math.Round(float64(critical)/float64(tracked)*10000) / 100
+	}
+	return stress
+}
+
+func roundHours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
@@ -0,0 +1,67 @@
+package temperature
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeThermalStress(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	at := func(hoursAgo float64, temp int) TempReading {
+		return TempReading{Temperature: temp, Timestamp: now.Add(-time.Duration(hoursAgo * float64(time.Hour)))}
+	}
+	thresholds := TemperatureThresholds{Warning: 45, Critical: 55}
+
+	stress := computeThermalStress([]TempReading{
+		at(30, 40),
+		at(29, 50), // warning for 1h
+		at(28, 56), // critical for 1h, then the agent goes quiet
+		at(8, 40),  // 20h gap: only 2h (twice the median) are credited above
+		at(7, 40),
+		at(6, 40), // last reading: one median interval
+	}, thresholds, now)
+
+	if stress.TrackedHours != 7 {
+		t.Errorf("TrackedHours = %.2f, want 7", stress.TrackedHours)
+	}
+	if stress.HoursAboveWarning != 3 || stress.HoursAboveCritical != 2 {
+		t.Errorf("above warning/critical = %.2f/%.2f, want 3/2", stress.HoursAboveWarning, stress.HoursAboveCritical)
+	}
+	if stress.PercentAboveWarning != 42.86 || stress.PercentAboveCritical != 28.57 {
+		t.Errorf("percent above warning/critical = %.2f/%.2f", stress.PercentAboveWarning, stress.PercentAboveCritical)
+	}
+
+	if single := computeThermalStress([]TempReading{at(1, 60)}, thresholds, now); single.TrackedHours != 0 || single.DataPoints != 1 {
+		t.Errorf("single reading = %+v, want nothing tracked", single)
+	}
+}
+
+func TestGetThermalStress(t *testing.T) {
+	db := setupTempTestDB(t)
+	defer db.Close()
+
+	insertTestTemperatureData(t, db, "server1", "SERIAL001", []int{40, 46, 47, 56, 42}, 5)
+
+	stress, err := GetThermalStress(db, "server1", "SERIAL001", Period24Hours)
+	if err != nil {
+		t.Fatalf("GetThermalStress failed: %v", err)
+	}
+	if stress == nil {
+		t.Fatal("Expected thermal stress to be returned")
+	}
+	if stress.WarningThreshold != 45 || stress.CriticalThreshold != 55 {
+		t.Errorf("thresholds = %d/%d, want the defaults", stress.WarningThreshold, stress.CriticalThreshold)
+	}
+	if stress.HoursAboveWarning != 3 || stress.HoursAboveCritical != 1 {
+		t.Errorf("above warning/critical = %.2f/%.2f, want 3/1", stress.HoursAboveWarning, stress.HoursAboveCritical)
+	}
+
+	if none, err := GetThermalStress(db, "server1", "OTHER", Period24Hours); err != nil || none != nil {
+		t.Errorf("unknown drive = %+v, %v; want nil", none, err)
+	}
+
+	stats, err := GetTemperatureStats(db, "server1", "SERIAL001", Period24Hours)
+	if err != nil || stats == nil || stats.ThermalStress == nil {
+		t.Fatalf("GetTemperatureStats thermal stress missing: %+v, %v", stats, err)
+	}
+}
@@ -34,9 +34,6 @@ func setupTestDB(t *testing.T) *sql.DB {
 		t.Fatalf("Failed to initialize tables: %v", err)
 	}
 
-	// Clear alert state cache
-	ClearAlertStateCache()
-
 	return database
 }
 
@@ -261,7 +258,7 @@ func TestProcessDriveTemperature(t *testing.T) {
 	defer database.Close()
 
 	// Process a high temperature
-	err := ProcessDriveTemperature(database, "server1", "SERIAL001", 60)
+	err := ProcessDriveTemperature(database, nil, "server1", "SERIAL001", 60)
 	if err != nil {
 		t.Fatalf("ProcessDriveTemperature failed: %v", err)
 	}
@@ -283,7 +280,7 @@ func TestProcessDriveTemperatureInvalid(t *testing.T) {
 	defer database.Close()
 
 	// Process invalid temperature (should be skipped)
-	err := ProcessDriveTemperature(database, "server1", "SERIAL001", 150)
+	err := ProcessDriveTemperature(database, nil, "server1", "SERIAL001", 150)
 	if err != nil {
 		t.Errorf("Expected no error for invalid temperature, got: %v", err)
 	}
@@ -1,6 +1,7 @@
 package temperature
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -8,21 +9,27 @@ import (
 type TemperaturePeriod string
 
 const (
+	Period1Hour   TemperaturePeriod = "1h"
 	Period24Hours TemperaturePeriod = "24h"
 	Period7Days   TemperaturePeriod = "7d"
 	Period30Days  TemperaturePeriod = "30d"
+	Period90Days  TemperaturePeriod = "90d"
 	PeriodAllTime TemperaturePeriod = "all"
 )
 
 // ParsePeriod converts a string to TemperaturePeriod with validation
 func ParsePeriod(s string) TemperaturePeriod {
 	switch s {
+	case "1h":
+		return Period1Hour
 	case "24h", "1d":
 		return Period24Hours
 	case "7d", "1w":
 		return Period7Days
 	case "30d", "1m":
 		return Period30Days
+	case "90d", "3m":
+		return Period90Days
 	case "all", "":
 		return PeriodAllTime
 	default:
@@ -33,12 +40,16 @@ func ParsePeriod(s string) TemperaturePeriod {
 // PeriodToDuration converts a period to time.Duration
 func PeriodToDuration(p TemperaturePeriod) time.Duration {
 	switch p {
+	case Period1Hour:
+		return time.Hour
 	case Period24Hours:
 		return 24 * time.Hour
 	case Period7Days:
 		return 7 * 24 * time.Hour
 	case Period30Days:
 		return 30 * 24 * time.Hour
+	case Period90Days:
+		return 90 * 24 * time.Hour
 	case PeriodAllTime:
 		return 365 * 24 * time.Hour * 10 // 10 years
 	default:
@@ -50,6 +61,8 @@ func PeriodToDuration(p TemperaturePeriod) time.Duration {
 type AggregationInterval string
 
 const (
+	Interval5Min    AggregationInterval = "5m"
+	Interval15Min   AggregationInterval = "15m"
 	IntervalHourly  AggregationInterval = "1h"
 	Interval6Hours  AggregationInterval = "6h"
 	IntervalDaily   AggregationInterval = "1d"
@@ -57,9 +70,17 @@ const (
 	IntervalMonthly AggregationInterval = "1m"
 )
 
+// MaxSeriesBuckets caps how many buckets a single time series may contain,
+// so fine intervals cannot be requested over long periods.
+const MaxSeriesBuckets = 2500
+
 // ParseInterval converts a string to AggregationInterval
 func ParseInterval(s string) AggregationInterval {
 	switch s {
+	case "5m", "5min":
+		return Interval5Min
+	case "15m", "15min":
+		return Interval15Min
 	case "1h", "hour", "hourly":
 		return IntervalHourly
 	case "6h":
@@ -75,21 +96,58 @@ func ParseInterval(s string) AggregationInterval {
 	}
 }
 
-// IntervalToSQLite returns SQLite strftime format for grouping
-func IntervalToSQLite(i AggregationInterval) string {
+// IntervalToDuration returns the nominal bucket width (months count as 30 days).
+func IntervalToDuration(i AggregationInterval) time.Duration {
 	switch i {
-	case IntervalHourly:
-		return "%Y-%m-%d %H:00:00"
+	case Interval5Min:
+		return 5 * time.Minute
+	case Interval15Min:
+		return 15 * time.Minute
 	case Interval6Hours:
-		return "%Y-%m-%d %H:00:00" // Will need post-processing
+		return 6 * time.Hour
 	case IntervalDaily:
-		return "%Y-%m-%d 00:00:00"
+		return 24 * time.Hour
 	case IntervalWeekly:
-		return "%Y-%W" // Year-Week
+		return 7 * 24 * time.Hour
+	case IntervalMonthly:
+		return 30 * 24 * time.Hour
+	default:
+		return time.Hour
+	}
+}
+
+// ValidateInterval rejects intervals that would produce more than
+// MaxSeriesBuckets buckets over the period. All-time views need at least
+// daily buckets.
+func ValidateInterval(p TemperaturePeriod, i AggregationInterval) error {
+	width := IntervalToDuration(i)
+	if p == PeriodAllTime {
+		if width < 24*time.Hour {
+			return fmt.Errorf("interval %s is too fine for period %s (use 1d or coarser)", i, p)
+		}
+		return nil
+	}
+	if buckets := int(PeriodToDuration(p) / width); buckets > MaxSeriesBuckets {
+		return fmt.Errorf("interval %s is too fine for period %s (%d buckets, max %d)", i, p, buckets, MaxSeriesBuckets)
+	}
+	return nil
+}
+
+// IntervalToSQLite returns a SQLite expression that truncates the timestamp
+// column to the start of its bucket, formatted as "YYYY-MM-DD HH:MM:SS".
+// Fixed-width buckets are aligned on the Unix epoch (weeks on Mondays);
+// months use calendar months.
+func IntervalToSQLite(i AggregationInterval) string {
+	const epochBucket = "strftime('%%Y-%%m-%%d %%H:%%M:%%S', (CAST(strftime('%%s', timestamp) AS INTEGER) / %d) * %d, 'unixepoch')"
+	switch i {
 	case IntervalMonthly:
-		return "%Y-%m-01 00:00:00"
+		return "strftime('%Y-%m-01 00:00:00', timestamp)"
+	case IntervalWeekly:
+		// The epoch was a Thursday; shift by 4 days so weeks start on Monday.
+		return "strftime('%Y-%m-%d %H:%M:%S', ((CAST(strftime('%s', timestamp) AS INTEGER) - 345600) / 604800) * 604800 + 345600, 'unixepoch')"
 	default:
-		return "%Y-%m-%d %H:00:00"
+		secs := int(IntervalToDuration(i).Seconds())
+		return fmt.Sprintf(epochBucket, secs, secs)
 	}
 }
 
@@ -111,6 +169,22 @@ type TemperatureStats struct {
 	LastReading  time.Time `json:"last_reading"`
 	TrendSlope   float64   `json:"trend_slope"` // Positive = heating, negative = cooling
 	TrendDesc    string    `json:"trend_desc"`  // "heating", "cooling", "stable"
+
+	ThermalStress *ThermalStress `json:"thermal_stress,omitempty"`
+}
+
+// HostTemperatureStats aggregates temperature statistics across all of a
+// host's drives. Min, max and average are over every reading in the period,
+// so drives that report more often weigh more in AvgTemp.
+type HostTemperatureStats struct {
+	Hostname   string             `json:"hostname"`
+	Period     string             `json:"period"`
+	MinTemp    int                `json:"min_temp"`
+	MaxTemp    int                `json:"max_temp"`
+	AvgTemp    float64            `json:"avg_temp"`
+	DataPoints int                `json:"data_points"`
+	DriveCount int                `json:"drive_count"`
+	Drives     []TemperatureStats `json:"drives"`
 }
 
 // TempReading represents a single temperature reading from the database
@@ -122,6 +196,27 @@ type TempReading struct {
 	Timestamp    time.Time `json:"timestamp"`
 }
 
+// MaxRangeRows caps how many raw readings a single range query may return.
+// Clients wanting more must page with offset or ask for a downsampled series.
+const MaxRangeRows = 5000
+
+// RangeOptions controls paging and downsampling for GetTemperatureRangePage
+type RangeOptions struct {
+	Limit      int  // Page size; 0 or anything above MaxRangeRows is clamped to MaxRangeRows
+	Offset     int  // Number of (sampled) rows to skip
+	Downsample bool // Keep every Nth reading so the whole range fits in one page
+}
+
+// TemperatureRangePage is one bounded page of a temperature range query
+type TemperatureRangePage struct {
+	Records   []TempReading `json:"records"`
+	Total     int           `json:"total"` // Raw readings in the range, before sampling
+	Limit     int           `json:"limit"` // Effective page size after clamping
+	Offset    int           `json:"offset"`
+	Step      int           `json:"step"`      // 1 = raw data, N = every Nth reading
+	Truncated bool          `json:"truncated"` // More rows exist beyond this page
+}
+
 // TimeSeriesPoint represents a single point in a time series
 type TimeSeriesPoint struct {
 	Timestamp   time.Time `json:"timestamp"`
@@ -143,6 +238,23 @@ type TimeSeriesData struct {
 	Points       []TimeSeriesPoint `json:"points"`
 }
 
+// FleetTimeSeriesPoint aggregates every drive's readings in one time bucket
+type FleetTimeSeriesPoint struct {
+	Timestamp  time.Time `json:"timestamp"`
+	MinTemp    int       `json:"min_temp"`
+	MaxTemp    int       `json:"max_temp"`
+	AvgTemp    float64   `json:"avg_temp"`
+	DataPoints int       `json:"data_points"`
+	DriveCount int       `json:"drive_count"` // Drives with at least one reading in the bucket
+}
+
+// FleetTimeSeriesData is the fleet-wide temperature time series
+type FleetTimeSeriesData struct {
+	Period   string                 `json:"period"`
+	Interval string                 `json:"interval"`
+	Points   []FleetTimeSeriesPoint `json:"points"`
+}
+
 // CurrentTemperature represents the current temperature of a drive
 type CurrentTemperature struct {
 	Hostname     string    `json:"hostname"`
@@ -154,6 +266,15 @@ type CurrentTemperature struct {
 	Status       string    `json:"status"` // "normal", "warning", "critical"
 }
 
+// DriveRef identifies one drive in a batch request
+type DriveRef struct {
+	Hostname     string `json:"hostname"`
+	SerialNumber string `json:"serial"`
+}
+
+// MaxBatchDrives caps the drives accepted by one batch request
+const MaxBatchDrives = 500
+
 // TemperatureThresholds holds threshold values for status determination
 type TemperatureThresholds struct {
 	Warning  int `json:"warning"`
@@ -207,6 +328,7 @@ type TemperatureSpike struct {
 	Change         int       `json:"change"`
 	RatePerMin     float64   `json:"rate_per_min"`
 	Direction      string    `json:"direction"` // "heating" or "cooling"
+	Severity       string    `json:"severity"`  // "warning" or "critical", see SpikeSeverity
 	Acknowledged   bool      `json:"acknowledged"`
 	AcknowledgedBy string    `json:"acknowledged_by,omitempty"`
 	AcknowledgedAt time.Time `json:"acknowledged_at,omitempty"`
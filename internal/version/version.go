@@ -41,6 +41,7 @@ type Checker struct {
 	currentVersion string
 	owner          string
 	repo           string
+	releaseURL     string // overrides the GitHub API URL when set
 	httpClient     *http.Client
 
 	mu          sync.RWMutex
@@ -82,6 +83,20 @@ func (c *Checker) SetCacheTTL(ttl time.Duration) {
 	c.cacheTTL = ttl
 }
 
+// SetReleaseURL points the checker at a custom "latest release" endpoint
+// (e.g. a self-hosted mirror returning the GitHub release JSON shape).
+// An empty URL restores the default GitHub API URL. Changing the URL
+// drops any cached result.
+func (c *Checker) SetReleaseURL(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if url == c.releaseURL {
+		return
+	}
+	c.releaseURL = url
+	c.cachedInfo = nil
+}
+
 // Check fetches the latest release info, using cache if available
 func (c *Checker) Check() (*ReleaseInfo, error) {
 	// Check cache first
@@ -135,7 +150,12 @@ func (c *Checker) ForceCheck() (*ReleaseInfo, error) {
 
 // fetchLatestRelease makes the actual API call to GitHub
 func (c *Checker) fetchLatestRelease() (*ReleaseInfo, error) {
-	url := fmt.Sprintf(GitHubAPIURL, c.owner, c.repo)
+	c.mu.RLock()
+	url := c.releaseURL
+	c.mu.RUnlock()
+	if url == "" {
+		url = fmt.Sprintf(GitHubAPIURL, c.owner, c.repo)
+	}
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -146,7 +166,7 @@ func (c *Checker) fetchLatestRelease() (*ReleaseInfo, error) {
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("User-Agent", fmt.Sprintf("Vigil/%s", c.currentVersion))
 
-	resp, err := c.httpClient.Do(req) // #nosec G107 G704 -- URL is constructed from hardcoded GitHub API pattern
+	resp, err := c.httpClient.Do(req) // #nosec G107 G704 -- URL is the GitHub API pattern or an admin-configured setting
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch release info: %w", err)
 	}
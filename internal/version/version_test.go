@@ -108,13 +108,26 @@ func TestChecker_Check(t *testing.T) {
 	checker := NewChecker("1.0.0", "test", "repo")
 	checker.httpClient = server.Client()
 
-	// Override the URL for testing (we'll need to modify the package for this)
-	// For now, we test the version comparison logic
+	checker.SetReleaseURL(server.URL)
+
 	t.Run("version comparison logic", func(t *testing.T) {
 		if CompareVersions("1.0.0", "1.2.0") >= 0 {
 			t.Error("Expected 1.2.0 to be newer than 1.0.0")
 		}
 	})
+
+	t.Run("custom release URL", func(t *testing.T) {
+		info, err := checker.Check()
+		if err != nil {
+			t.Fatalf("Check failed: %v", err)
+		}
+		if !info.UpdateAvailable || info.LatestVersion != "1.2.0" {
+			t.Errorf("Expected update to 1.2.0, got %+v", info)
+		}
+		if info.ReleaseURL != "https://github.com/test/repo/releases/tag/v1.2.0" {
+			t.Errorf("ReleaseURL = %q", info.ReleaseURL)
+		}
+	})
 }
 
 func TestChecker_CacheTTL(t *testing.T) {
@@ -17,7 +17,8 @@ var strategies = map[string]WearoutStrategy{
 	"NVMe": &NVMeStrategy{},
 }
 
-// CalculateAndStore runs the wearout calculation for a drive and persists the result.
+// CalculateAndStore runs the wearout calculation for a drive and persists the result
+// at the drive data's timestamp (now if unset).
 // When bus is non-nil, threshold-crossing events are published.
 func CalculateAndStore(db *sql.DB, bus *events.Bus, driveData *agentsmart.DriveSmartData) (*WearoutResult, error) {
 	strategy, ok := strategies[driveData.DriveType]
@@ -25,9 +26,14 @@ func CalculateAndStore(db *sql.DB, bus *events.Bus, driveData *agentsmart.DriveS
 		return nil, nil // unsupported drive type — skip silently
 	}
 
+	at := driveData.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+
 	// Capture previous percentage before storing the new one.
 	var prevPct float64
-	if prev, err := GetLatestSnapshot(db, driveData.Hostname, driveData.SerialNumber); err == nil && prev != nil {
+	if prev, err := getSnapshotAtOrBefore(db, driveData.Hostname, driveData.SerialNumber, at); err == nil && prev != nil {
 		prevPct = prev.Percentage
 	}
 
@@ -47,12 +53,15 @@ func CalculateAndStore(db *sql.DB, bus *events.Bus, driveData *agentsmart.DriveS
 		DriveType:    driveData.DriveType,
 		Percentage:   result.Percentage,
 		FactorsJSON:  marshalFactors(result.Factors),
-		Timestamp:    time.Now().UTC(),
+		Timestamp:    at.UTC(),
 	}
 
 	if err := StoreSnapshot(db, snapshot); err != nil {
 		log.Printf("Warning: failed to store wearout snapshot for %s: %v", driveData.SerialNumber, err)
 	}
+	if err := RecordWriteSnapshot(db, driveData, snapshot.Timestamp); err != nil {
+		log.Printf("Warning: failed to store write snapshot for %s: %v", driveData.SerialNumber, err)
+	}
 
 	// Publish wearout threshold events on crossing boundaries.
 	if bus != nil {
@@ -65,6 +74,14 @@ func CalculateAndStore(db *sql.DB, bus *events.Bus, driveData *agentsmart.DriveS
 // ProcessWearoutFromReport calculates and stores wearout for all drives in a report.
 // When bus is non-nil, threshold-crossing events are published.
 func ProcessWearoutFromReport(db *sql.DB, bus *events.Bus, hostname string, reportData map[string]interface{}) {
+	ProcessWearoutFromReportAt(db, bus, hostname, reportData, time.Time{})
+}
+
+// ProcessWearoutFromReportAt is ProcessWearoutFromReport for a report
+// collected at the given time, e.g. one imported after the fact: wearout and
+// write snapshots are recorded at that time instead of now. A zero time
+// means now.
+func ProcessWearoutFromReportAt(db *sql.DB, bus *events.Bus, hostname string, reportData map[string]interface{}, collectedAt time.Time) {
 	drives, ok := reportData["drives"].([]interface{})
 	if !ok {
 		return
@@ -80,6 +97,9 @@ func ProcessWearoutFromReport(db *sql.DB, bus *events.Bus, hostname string, repo
 		if err != nil || driveData.SerialNumber == "" {
 			continue
 		}
+		if !collectedAt.IsZero() {
+			driveData.Timestamp = collectedAt.UTC()
+		}
 
 		if _, err := CalculateAndStore(db, bus, driveData); err != nil {
 			log.Printf("Warning: wearout calculation failed for %s: %v", driveData.SerialNumber, err)
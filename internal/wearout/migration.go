@@ -6,7 +6,8 @@ import (
 	"log"
 )
 
-// MigrateWearoutTables creates the wearout_history and drive_specs tables.
+// MigrateWearoutTables creates the wearout_history, write_snapshots and
+// drive_specs tables.
 func MigrateWearoutTables(db *sql.DB) error {
 	log.Println("Running migration: Wearout tables")
 
@@ -28,6 +29,20 @@ func MigrateWearoutTables(db *sql.DB) error {
 		{"wearout_history indexes", `
 			CREATE INDEX IF NOT EXISTS idx_wearout_host_serial ON wearout_history(hostname, serial_number);
 			CREATE INDEX IF NOT EXISTS idx_wearout_timestamp   ON wearout_history(timestamp);`},
+		{"write_snapshots", `
+			CREATE TABLE IF NOT EXISTS write_snapshots (
+				id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+				hostname            TEXT    NOT NULL,
+				serial_number       TEXT    NOT NULL,
+				host_bytes_written  INTEGER NOT NULL,
+				host_write_commands INTEGER,
+				write_amplification REAL,
+				power_on_hours      INTEGER NOT NULL DEFAULT 0,
+				timestamp           DATETIME DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE(hostname, serial_number, timestamp)
+			);`},
+		{"write_snapshots indexes", `
+			CREATE INDEX IF NOT EXISTS idx_write_snap_host_serial ON write_snapshots(hostname, serial_number, timestamp);`},
 		{"drive_specs", `
 			CREATE TABLE IF NOT EXISTS drive_specs (
 				id                INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	vigildb "vigil/internal/db"
 )
 
 // StoreSnapshot persists a wearout calculation to the database.
@@ -31,6 +33,19 @@ func GetLatestSnapshot(db *sql.DB, hostname, serialNumber string) (*WearoutSnaps
 	return scanSnapshot(row)
 }
 
+// getSnapshotAtOrBefore returns the drive's latest wearout snapshot taken at
+// or before at.
+func getSnapshotAtOrBefore(db *sql.DB, hostname, serialNumber string, at time.Time) (*WearoutSnapshot, error) {
+	row := db.QueryRow(`
+		SELECT id, hostname, serial_number, drive_type, percentage, factors_json, timestamp
+		FROM wearout_history
+		WHERE hostname = ? AND serial_number = ? AND timestamp <= ?
+		ORDER BY timestamp DESC LIMIT 1
+	`, hostname, serialNumber, at.UTC().Format(timeFormat))
+
+	return scanSnapshot(row)
+}
+
 // GetAllLatestSnapshots returns the most recent wearout for every drive.
 func GetAllLatestSnapshots(db *sql.DB) ([]WearoutSnapshot, error) {
 	rows, err := db.Query(`
@@ -58,7 +73,7 @@ func GetAllLatestSnapshots(db *sql.DB) ([]WearoutSnapshot, error) {
 		if err := rows.Scan(&s.ID, &s.Hostname, &s.SerialNumber, &s.DriveType, &s.Percentage, &factorsJSON, &ts); err != nil {
 			continue
 		}
-		s.Timestamp = parseDBTime(ts)
+		s.Timestamp = vigildb.ParseTime(ts)
 		if factorsJSON.Valid {
 			s.FactorsJSON = factorsJSON.String
 		}
@@ -90,7 +105,7 @@ func GetSnapshotHistory(db *sql.DB, hostname, serialNumber string, days int) ([]
 		if err := rows.Scan(&s.ID, &s.Hostname, &s.SerialNumber, &s.DriveType, &s.Percentage, &factorsJSON, &ts); err != nil {
 			continue
 		}
-		s.Timestamp = parseDBTime(ts)
+		s.Timestamp = vigildb.ParseTime(ts)
 		if factorsJSON.Valid {
 			s.FactorsJSON = factorsJSON.String
 		}
@@ -214,7 +229,7 @@ func scanSnapshot(row *sql.Row) (*WearoutSnapshot, error) {
 		return nil, err
 	}
 
-	s.Timestamp = parseDBTime(ts)
+	s.Timestamp = vigildb.ParseTime(ts)
 	if factorsJSON.Valid {
 		s.FactorsJSON = factorsJSON.String
 	}
@@ -7,6 +7,8 @@ import (
 	"testing"
 	"time"
 
+	agentsmart "vigil/cmd/agent/smart"
+
 	_ "modernc.org/sqlite"
 )
 
@@ -338,3 +340,32 @@ func TestMigrateWearoutTables_Idempotent(t *testing.T) {
 		t.Errorf("second migration should be idempotent, got: %v", err)
 	}
 }
+
+// ── CalculateAndStore at a past reading ─────────────────────────────────────
+
+func TestCalculateAndStoreAtReadingTime(t *testing.T) {
+	db := setupTestDB(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	if err := StoreSnapshot(db, testSnapshot("h", "s", "SSD", 40, now)); err != nil {
+		t.Fatal(err)
+	}
+
+	// An imported reading from ten days ago lands at its own time and
+	// doesn't displace the latest snapshot.
+	past := now.AddDate(0, 0, -10)
+	d := &agentsmart.DriveSmartData{Hostname: "h", SerialNumber: "s", DriveType: "SSD", Timestamp: past}
+	if _, err := CalculateAndStore(db, nil, d); err != nil {
+		t.Fatalf("CalculateAndStore: %v", err)
+	}
+
+	history, err := GetSnapshotHistory(db, "h", "s", 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || !history[0].Timestamp.Equal(past) {
+		t.Fatalf("expected a snapshot at %v before the current one, got %+v", past, history)
+	}
+	if got, _ := GetLatestSnapshot(db, "h", "s"); got == nil || got.Percentage != 40 {
+		t.Errorf("latest snapshot = %+v, want the current one", got)
+	}
+}
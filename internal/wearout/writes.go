@@ -0,0 +1,199 @@
+package wearout
+
+import (
+	"database/sql"
+	"time"
+
+	agentsmart "vigil/cmd/agent/smart"
+	vigildb "vigil/internal/db"
+)
+
+// Write counters used for write-rate and amplification estimates. NVMe IDs
+// are the pseudo-attributes the agent parser maps the health log onto.
+const (
+	attrNVMeHostWriteCommands = 178 // NVMe host_writes (command count)
+	attrHostProgramPages      = 247 // Micron/Crucial: NAND pages programmed for host writes
+	attrFTLProgramPages       = 248 // Micron/Crucial: NAND pages programmed by the FTL (GC, wear leveling)
+	attrNANDWritesGiB         = 249 // Intel: NAND writes in GiB
+
+	nvmeDataUnitBytes  = 512 * 1000 // one NVMe "data unit" is 1000 512-byte sectors
+	sectorBytes        = 512
+	intelHostWriteUnit = 32 << 20
+
+	// writeSnapshotInterval throttles write_snapshots to one row per drive
+	// per hour; reports arrive every minute but rates are read over days.
+	writeSnapshotInterval = time.Hour
+)
+
+// WriteStats summarises how hard an SSD is being written.
+//
+// WriteAmplification is NAND writes divided by host writes, and is only set
+// for drives that expose a NAND write counter (Intel 249, Micron/Crucial
+// 247/248). NVMe drives don't report NAND writes at all; for them
+// AvgWriteSizeBytes (host bytes per write command) is the closest proxy,
+// since small random writes are what drive amplification up.
+type WriteStats struct {
+	HostBytesWritten    int64     `json:"host_bytes_written"`
+	PowerOnHours        int64     `json:"power_on_hours"`
+	LifetimeBytesPerDay float64   `json:"lifetime_bytes_per_day"`
+	RecentBytesPerDay   *float64  `json:"recent_bytes_per_day,omitempty"`
+	RecentWindowDays    float64   `json:"recent_window_days,omitempty"`
+	AvgWriteSizeBytes   *float64  `json:"avg_write_size_bytes,omitempty"`
+	WriteAmplification  *float64  `json:"write_amplification,omitempty"`
+	Timestamp           time.Time `json:"timestamp"`
+}
+
+// writeSnapshot is one row of write_snapshots.
+type writeSnapshot struct {
+	hostBytes     int64
+	hostCommands  sql.NullInt64
+	amplification sql.NullFloat64
+	powerOnHours  int64
+	timestamp     time.Time
+}
+
+// snapshotFromDrive extracts write counters from parsed SMART data. ok is
+// false for drives without a host-writes counter.
+func snapshotFromDrive(d *agentsmart.DriveSmartData) (s writeSnapshot, ok bool) {
+	attrs := make(map[int]int64, len(d.Attributes))
+	for _, a := range d.Attributes {
+		attrs[a.ID] = a.RawValue
+	}
+
+	written, ok := attrs[AttrTotalLBAsWritten]
+	if !ok || written <= 0 {
+		return s, false
+	}
+	s.powerOnHours = d.PowerOnHours
+
+	if d.DriveType == "NVMe" {
+		s.hostBytes = written * nvmeDataUnitBytes
+		if cmds, ok := attrs[attrNVMeHostWriteCommands]; ok && cmds > 0 {
+			s.hostCommands = sql.NullInt64{Int64: cmds, Valid: true}
+		}
+		return s, true
+	}
+
+	// Intel drives that report 249 count 241 in 32 MiB units
+	// (Host_Writes_32MiB); everyone else uses 512-byte LBAs.
+	if nand, ok := attrs[attrNANDWritesGiB]; ok && nand > 0 {
+		s.hostBytes = written * intelHostWriteUnit
+		s.amplification = sql.NullFloat64{Float64: float64(nand) * (1 << 30) / float64(s.hostBytes), Valid: true}
+		return s, true
+	}
+	s.hostBytes = written * sectorBytes
+	if host, ok := attrs[attrHostProgramPages]; ok && host > 0 {
+		s.amplification = sql.NullFloat64{Float64: float64(host+attrs[attrFTLProgramPages]) / float64(host), Valid: true}
+	}
+	return s, true
+}
+
+// RecordWriteSnapshot stores the drive's write counters if it is an SSD or
+// NVMe drive with a host-writes counter and its last snapshot before now is
+// at least writeSnapshotInterval older.
+func RecordWriteSnapshot(db *sql.DB, d *agentsmart.DriveSmartData, now time.Time) error {
+	if d.DriveType != "SSD" && d.DriveType != "NVMe" {
+		return nil
+	}
+	s, ok := snapshotFromDrive(d)
+	if !ok {
+		return nil
+	}
+
+	var last string
+	err := db.QueryRow(`
+		SELECT timestamp FROM write_snapshots
+		WHERE hostname = ? AND serial_number = ? AND timestamp <= ?
+		ORDER BY timestamp DESC LIMIT 1
+	`, d.Hostname, d.SerialNumber, now.UTC().Format(timeFormat)).Scan(&last)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == nil && now.Sub(vigildb.ParseTime(last)) < writeSnapshotInterval {
+		return nil
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO write_snapshots
+			(hostname, serial_number, host_bytes_written, host_write_commands,
+			 write_amplification, power_on_hours, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(hostname, serial_number, timestamp) DO NOTHING
+	`, d.Hostname, d.SerialNumber, s.hostBytes, s.hostCommands, s.amplification, s.powerOnHours,
+		now.UTC().Format(timeFormat))
+	return err
+}
+
+// GetWriteStats computes write statistics from a drive's write snapshots.
+// The recent rate covers the last `days` days (from the oldest snapshot in
+// that window to the newest); it is omitted until the window spans at least
+// a few hours. Returns nil if the drive has no snapshots.
+func GetWriteStats(db *sql.DB, hostname, serialNumber string, days int) (*WriteStats, error) {
+	since := time.Now().AddDate(0, 0, -days).UTC().Format(timeFormat)
+
+	rows, err := db.Query(`
+		SELECT host_bytes_written, host_write_commands, write_amplification, power_on_hours, timestamp
+		FROM write_snapshots
+		WHERE hostname = ? AND serial_number = ?
+		  AND (timestamp >= ? OR timestamp = (
+			SELECT MAX(timestamp) FROM write_snapshots WHERE hostname = ? AND serial_number = ?))
+		ORDER BY timestamp ASC
+	`, hostname, serialNumber, since, hostname, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snaps []writeSnapshot
+	for rows.Next() {
+		var s writeSnapshot
+		var ts string
+		if err := rows.Scan(&s.hostBytes, &s.hostCommands, &s.amplification, &s.powerOnHours, &ts); err != nil {
+			return nil, err
+		}
+		s.timestamp = vigildb.ParseTime(ts)
+		snaps = append(snaps, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(snaps) == 0 {
+		return nil, nil
+	}
+
+	return computeWriteStats(snaps), nil
+}
+
+// minRecentWindow is the shortest span a recent write rate is reported for;
+// over less, one burst dominates the per-day figure.
+const minRecentWindow = 6 * time.Hour
+
+func computeWriteStats(snaps []writeSnapshot) *WriteStats {
+	first, last := snaps[0], snaps[len(snaps)-1]
+	stats := &WriteStats{
+		HostBytesWritten: last.hostBytes,
+		PowerOnHours:     last.powerOnHours,
+		Timestamp:        last.timestamp,
+	}
+	if last.powerOnHours > 0 {
+		stats.LifetimeBytesPerDay = float64(last.hostBytes) / (float64(last.powerOnHours) / 24)
+	}
+	if last.hostCommands.Valid {
+		avg := float64(last.hostBytes) / float64(last.hostCommands.Int64)
+		stats.AvgWriteSizeBytes = &avg
+	}
+	if last.amplification.Valid {
+		waf := last.amplification.Float64
+		stats.WriteAmplification = &waf
+	}
+
+	// A counter going backwards means a different drive or a firmware reset;
+	// no rate is better than a negative one.
+	if span := last.timestamp.Sub(first.timestamp); span >= minRecentWindow && last.hostBytes >= first.hostBytes {
+		spanDays := span.Hours() / 24
+		rate := float64(last.hostBytes-first.hostBytes) / spanDays
+		stats.RecentBytesPerDay = &rate
+		stats.RecentWindowDays = spanDays
+	}
+	return stats
+}
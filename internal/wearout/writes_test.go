@@ -0,0 +1,106 @@
+package wearout
+
+import (
+	"testing"
+	"time"
+
+	agentsmart "vigil/cmd/agent/smart"
+)
+
+func nvmeDrive(dataUnits, writeCmds, poh int64) *agentsmart.DriveSmartData {
+	return &agentsmart.DriveSmartData{
+		Hostname:     "host1",
+		SerialNumber: "NVME001",
+		DriveType:    "NVMe",
+		PowerOnHours: poh,
+		Attributes: []agentsmart.SmartAttribute{
+			{ID: AttrTotalLBAsWritten, RawValue: dataUnits},
+			{ID: attrNVMeHostWriteCommands, RawValue: writeCmds},
+		},
+	}
+}
+
+func TestRecordWriteSnapshotThrottles(t *testing.T) {
+	db := setupTestDB(t)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	if err := RecordWriteSnapshot(db, nvmeDrive(1000, 100, 10), now); err != nil {
+		t.Fatalf("RecordWriteSnapshot: %v", err)
+	}
+	// Within the interval: skipped.
+	if err := RecordWriteSnapshot(db, nvmeDrive(1100, 110, 10), now.Add(10*time.Minute)); err != nil {
+		t.Fatalf("RecordWriteSnapshot: %v", err)
+	}
+	if err := RecordWriteSnapshot(db, nvmeDrive(1200, 120, 11), now.Add(writeSnapshotInterval)); err != nil {
+		t.Fatalf("RecordWriteSnapshot: %v", err)
+	}
+
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM write_snapshots`).Scan(&count)
+	if count != 2 {
+		t.Errorf("expected 2 snapshots, got %d", count)
+	}
+
+	// HDDs are ignored.
+	hdd := nvmeDrive(1000, 100, 10)
+	hdd.DriveType = "HDD"
+	hdd.SerialNumber = "HDD001"
+	RecordWriteSnapshot(db, hdd, now)
+	db.QueryRow(`SELECT COUNT(*) FROM write_snapshots WHERE serial_number = 'HDD001'`).Scan(&count)
+	if count != 0 {
+		t.Errorf("expected no snapshots for HDD, got %d", count)
+	}
+}
+
+func TestGetWriteStats(t *testing.T) {
+	db := setupTestDB(t)
+	start := time.Now().UTC().Add(-48 * time.Hour).Truncate(time.Second)
+
+	// 2 days apart, 2,000,000 data units (1.024 TB) written in between.
+	RecordWriteSnapshot(db, nvmeDrive(1_000_000, 10_000_000, 1000), start)
+	RecordWriteSnapshot(db, nvmeDrive(3_000_000, 30_000_000, 1048), start.Add(48*time.Hour))
+
+	stats, err := GetWriteStats(db, "host1", "NVME001", 7)
+	if err != nil || stats == nil {
+		t.Fatalf("GetWriteStats: %v, %v", stats, err)
+	}
+	if stats.HostBytesWritten != 3_000_000*nvmeDataUnitBytes {
+		t.Errorf("host bytes = %d", stats.HostBytesWritten)
+	}
+	if stats.RecentBytesPerDay == nil || *stats.RecentBytesPerDay != 1_000_000*nvmeDataUnitBytes {
+		t.Errorf("recent bytes/day = %v, want %d", stats.RecentBytesPerDay, 1_000_000*nvmeDataUnitBytes)
+	}
+	if stats.AvgWriteSizeBytes == nil || *stats.AvgWriteSizeBytes != 51200 {
+		t.Errorf("avg write size = %v, want 51200", stats.AvgWriteSizeBytes)
+	}
+	if stats.WriteAmplification != nil {
+		t.Errorf("NVMe should have no write amplification, got %v", *stats.WriteAmplification)
+	}
+}
+
+func TestSnapshotFromDriveAmplification(t *testing.T) {
+	crucial := &agentsmart.DriveSmartData{
+		DriveType: "SSD",
+		Attributes: []agentsmart.SmartAttribute{
+			{ID: AttrTotalLBAsWritten, RawValue: 1000},
+			{ID: attrHostProgramPages, RawValue: 400},
+			{ID: attrFTLProgramPages, RawValue: 200},
+		},
+	}
+	s, ok := snapshotFromDrive(crucial)
+	if !ok || !s.amplification.Valid || s.amplification.Float64 != 1.5 {
+		t.Errorf("Crucial WAF = %+v, want 1.5", s.amplification)
+	}
+
+	intel := &agentsmart.DriveSmartData{
+		DriveType: "SSD",
+		Attributes: []agentsmart.SmartAttribute{
+			{ID: AttrTotalLBAsWritten, RawValue: 64}, // 64 × 32 MiB = 2 GiB
+			{ID: attrNANDWritesGiB, RawValue: 5},
+		},
+	}
+	s, ok = snapshotFromDrive(intel)
+	if !ok || s.hostBytes != 2<<30 || s.amplification.Float64 != 2.5 {
+		t.Errorf("Intel: host bytes %d, WAF %v; want %d, 2.5", s.hostBytes, s.amplification.Float64, 2<<30)
+	}
+}
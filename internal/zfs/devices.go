@@ -283,6 +283,48 @@ func DeleteStaleZFSDevices(db *sql.DB, poolID int64, cutoff time.Time) (int64, e
 	return result.RowsAffected()
 }
 
+// LatestZFSDeviceSeen returns the most recent last_seen of any device in a
+// pool — i.e. when the pool's latest report was ingested. Zero if none.
+func LatestZFSDeviceSeen(db *sql.DB, poolID int64) (time.Time, error) {
+	var seen sql.NullTime
+	err := db.QueryRow(
+		"SELECT last_seen FROM zfs_pool_devices WHERE pool_id = ? ORDER BY last_seen DESC LIMIT 1",
+		poolID,
+	).Scan(&seen)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return seen.Time, nil
+}
+
+// ClearZFSDeviceErrors zeroes the stored read/write/checksum counters after a
+// successful `zpool clear`. An empty deviceName clears every device and the
+// pool-level totals; otherwise only that device is reset.
+func ClearZFSDeviceErrors(db *sql.DB, poolID int64, deviceName string) (int64, error) {
+	query := "UPDATE zfs_pool_devices SET read_errors = 0, write_errors = 0, checksum_errors = 0 WHERE pool_id = ?"
+	args := []interface{}{poolID}
+	if deviceName != "" {
+		query += " AND device_name = ?"
+		args = append(args, deviceName)
+	}
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	if deviceName == "" {
+		if _, err := db.Exec(
+			"UPDATE zfs_pools SET read_errors = 0, write_errors = 0, checksum_errors = 0 WHERE id = ?",
+			poolID,
+		); err != nil {
+			return 0, err
+		}
+	}
+	return result.RowsAffected()
+}
+
 // DeleteZFSPoolDevices removes all devices for a pool
 func DeleteZFSPoolDevices(db *sql.DB, poolID int64) error {
 	_, err := db.Exec("DELETE FROM zfs_pool_devices WHERE pool_id = ?", poolID)
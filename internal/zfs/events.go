@@ -8,12 +8,19 @@ import (
 	"time"
 
 	"vigil/internal/events"
+	"vigil/internal/recovery"
 	"vigil/internal/settings"
 )
 
 // ProcessZFSReportWithEvents processes a ZFS report and publishes events
 // for any unhealthy pools or failed devices.
 func ProcessZFSReportWithEvents(db *sql.DB, bus *events.Bus, hostname string, zfsData json.RawMessage) error {
+	return ProcessZFSReportWithEventsAt(db, bus, hostname, zfsData, time.Time{})
+}
+
+// ProcessZFSReportWithEventsAt is ProcessZFSReportWithEvents for a report
+// collected at the given time (see ProcessZFSReportAt).
+func ProcessZFSReportWithEventsAt(db *sql.DB, bus *events.Bus, hostname string, zfsData json.RawMessage, collectedAt time.Time) error {
 	if len(zfsData) == 0 || string(zfsData) == "null" {
 		return nil
 	}
@@ -31,11 +38,18 @@ func ProcessZFSReportWithEvents(db *sql.DB, bus *events.Bus, hostname string, zf
 	for _, pool := range report.Pools {
 		// Fetch previous pool state before ingest overwrites it
 		var prevPool *ZFSPool
+		prevRedundancy := ""
+		var prevSpares map[string]string
 		if bus != nil {
 			prevPool, _ = GetZFSPool(db, hostname, pool.Name)
+			if prevPool != nil {
+				prevDevices, _ := GetZFSPoolDevices(db, prevPool.ID)
+				prevRedundancy = PoolRedundancy(prevDevices)
+				prevSpares = spareStates(prevDevices)
+			}
 		}
 
-		poolID, err := processPool(db, hostname, pool)
+		poolID, err := processPool(db, hostname, pool, collectedAt)
 		if err != nil {
 			log.Printf("⚠️  Failed to process pool %s: %v", pool.Name, err)
 			continue
@@ -44,11 +58,18 @@ func ProcessZFSReportWithEvents(db *sql.DB, bus *events.Bus, hostname string, zf
 
 		if bus != nil {
 			publishPoolEvents(bus, hostname, pool)
+			if state, ok := poolHealthStates[pool.Health]; ok {
+				recovery.Observe(db, bus, recovery.KindPool, hostname, pool.Name, state)
+			}
 			publishDeviceEvents(bus, hostname, pool)
 			publishCapacityEvents(bus, db, hostname, pool)
 			publishVdevErrorEvents(bus, db, hostname, pool)
 			publishScrubOverdueEvents(bus, db, hostname, pool, poolID)
 			publishScanTransitionEvents(bus, hostname, pool, prevPool)
+			publishPropertyEvents(bus, db, hostname, pool, poolID)
+			publishRedundancyEvents(bus, hostname, pool, prevRedundancy)
+			publishSpareEvents(bus, hostname, pool, prevSpares)
+			publishFillEvents(bus, db, hostname, pool, poolID)
 		}
 	}
 
@@ -63,6 +84,15 @@ func ProcessZFSReportWithEvents(db *sql.DB, bus *events.Bus, hostname string, zf
 	return nil
 }
 
+// poolHealthStates maps the pool health states that publishPoolEvents
+// alerts on, and ONLINE, to the states tracked for recovery notifications.
+var poolHealthStates = map[string]string{
+	"ONLINE":   recovery.StateHealthy,
+	"DEGRADED": recovery.StateWarning,
+	"FAULTED":  recovery.StateCritical,
+	"UNAVAIL":  recovery.StateCritical,
+}
+
 // publishPoolEvents publishes events for unhealthy ZFS pools.
 func publishPoolEvents(bus *events.Bus, hostname string, pool ZFSAgentPool) {
 	switch pool.Health {
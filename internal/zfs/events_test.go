@@ -121,3 +121,141 @@ func TestPublishDeviceEvents_NestedChildren(t *testing.T) {
 		t.Errorf("expected serial S2, got %q", received[0].SerialNumber)
 	}
 }
+
+func TestClassifyRedundancy(t *testing.T) {
+	cases := []struct {
+		types []string
+		want  string
+	}{
+		{[]string{"disk"}, RedundancyNone},
+		{[]string{"disk", "disk"}, RedundancyNone},
+		{[]string{"mirror", "mirror"}, RedundancyMirror},
+		{[]string{"raidz2", "raidz2", "log", "cache", "spare"}, RedundancyRaidz2},
+		{[]string{"raidz2", "disk"}, RedundancyNone},
+		{[]string{"raidz3", "raidz1"}, RedundancyRaidz1},
+		{[]string{"draid2", "special", "mirror", "dedup", "mirror"}, RedundancyMirror},
+		{[]string{"draid3", "draid2"}, RedundancyDraid2},
+		{[]string{"raidz2", "special", "disk"}, RedundancyNone},
+		{[]string{"cache"}, ""},
+		{nil, ""},
+	}
+	for _, c := range cases {
+		if got := classifyRedundancy(c.types); got != c.want {
+			t.Errorf("classifyRedundancy(%v) = %q, want %q", c.types, got, c.want)
+		}
+	}
+}
+
+func TestPoolRedundancyAllocationClasses(t *testing.T) {
+	mirroredSpecial := []ZFSPoolDevice{
+		{DeviceName: "draid2:4d:8c:1s-0", VdevType: "draid2"},
+		{DeviceName: "sda", VdevType: "disk", VdevParent: "draid2:4d:8c:1s-0"},
+		{DeviceName: "special", VdevType: "special"},
+		{DeviceName: "mirror-1", VdevType: "mirror"},
+		{DeviceName: "nvme0n1", VdevType: "disk", VdevParent: "mirror-1"},
+		{DeviceName: "nvme1n1", VdevType: "disk", VdevParent: "mirror-1"},
+	}
+	if got := PoolRedundancy(mirroredSpecial); got != RedundancyMirror {
+		t.Errorf("draid2 + mirrored special = %q, want %q", got, RedundancyMirror)
+	}
+
+	bareSpecial := []ZFSPoolDevice{
+		{DeviceName: "raidz2-0", VdevType: "raidz2"},
+		{DeviceName: "sda", VdevType: "disk", VdevParent: "raidz2-0"},
+		{DeviceName: "special", VdevType: "special"},
+		{DeviceName: "nvme0n1", VdevType: "disk", VdevParent: "special"},
+	}
+	if got := PoolRedundancy(bareSpecial); got != RedundancyNone {
+		t.Errorf("raidz2 + single-disk special = %q, want %q", got, RedundancyNone)
+	}
+
+	agent := []ZFSAgentDevice{
+		{Name: "raidz2-0", VdevType: "raidz2", Children: []ZFSAgentDevice{{Name: "sda", VdevType: "disk"}}},
+		{Name: "dedup", VdevType: "dedup", Children: []ZFSAgentDevice{{Name: "nvme0n1", VdevType: "disk"}}},
+	}
+	if got := agentPoolRedundancy(agent); got != RedundancyNone {
+		t.Errorf("raidz2 + single-disk dedup = %q, want %q", got, RedundancyNone)
+	}
+}
+
+func TestPublishRedundancyEvents(t *testing.T) {
+	bus := events.NewBus()
+	var received []events.Event
+	bus.Subscribe(func(e events.Event) { received = append(received, e) })
+
+	striped := ZFSAgentPool{Name: "scratch", Devices: []ZFSAgentDevice{
+		{Name: "sda", VdevType: "disk"},
+		{Name: "sdb", VdevType: "disk"},
+		{Name: "logs", VdevType: "log", IsLog: true, Children: []ZFSAgentDevice{{Name: "nvme0n1", VdevType: "disk"}}},
+	}}
+	mirrored := ZFSAgentPool{Name: "tank", Devices: []ZFSAgentDevice{
+		{Name: "mirror-0", VdevType: "mirror", Children: []ZFSAgentDevice{{Name: "sdc", VdevType: "disk"}, {Name: "sdd", VdevType: "disk"}}},
+	}}
+
+	publishRedundancyEvents(bus, "server1", striped, "")
+	if len(received) != 1 || received[0].Type != events.ZFSNoRedundancy || received[0].Severity != events.SeverityInfo {
+		t.Fatalf("expected one info no-redundancy event for a new striped pool, got %+v", received)
+	}
+
+	publishRedundancyEvents(bus, "server1", striped, RedundancyNone)
+	publishRedundancyEvents(bus, "server1", mirrored, "")
+	if len(received) != 1 {
+		t.Errorf("expected no further events, got %+v", received[1:])
+	}
+
+	publishRedundancyEvents(bus, "server1", striped, RedundancyRaidz1)
+	if len(received) != 2 {
+		t.Errorf("expected an event when a pool loses its redundancy, got %d", len(received))
+	}
+}
+
+func TestPublishSpareEvents(t *testing.T) {
+	bus := events.NewBus()
+	var received []events.Event
+	bus.Subscribe(func(e events.Event) { received = append(received, e) })
+
+	pool := func(sdcState string) ZFSAgentPool {
+		return ZFSAgentPool{Name: "tank", Devices: []ZFSAgentDevice{
+			{Name: "mirror-0", VdevType: "mirror", Children: []ZFSAgentDevice{{Name: "sda", VdevType: "disk"}, {Name: "sdb", VdevType: "disk"}}},
+			{Name: "spares", VdevType: "spare", IsSpare: true, Children: []ZFSAgentDevice{
+				{Name: "sdc", VdevType: "disk", State: sdcState, SerialNumber: "SPARE1"},
+				{Name: "sdd", VdevType: "disk", State: "AVAIL"},
+			}},
+		}}
+	}
+
+	publishSpareEvents(bus, "server1", pool("AVAIL"), nil)
+	if len(received) != 0 {
+		t.Fatalf("expected no events while spares are available, got %+v", received)
+	}
+
+	publishSpareEvents(bus, "server1", pool("INUSE"), map[string]string{"sdc": "AVAIL", "sdd": "AVAIL"})
+	if len(received) != 1 {
+		t.Fatalf("expected one event when a spare is activated, got %+v", received)
+	}
+	e := received[0]
+	if e.Type != events.ZFSSpareActivated || e.Severity != events.SeverityWarning ||
+		e.SerialNumber != "SPARE1" || e.Metadata["device_name"] != "sdc" {
+		t.Errorf("unexpected event %+v", e)
+	}
+
+	publishSpareEvents(bus, "server1", pool("INUSE"), map[string]string{"sdc": "INUSE", "sdd": "AVAIL"})
+	if len(received) != 1 {
+		t.Errorf("expected no repeat while the spare stays in use, got %+v", received[1:])
+	}
+}
+
+func TestSpareStatus(t *testing.T) {
+	tests := map[string]string{
+		"AVAIL":   SpareAvailable,
+		"INUSE":   SpareInUse,
+		"FAULTED": SpareUnavailable,
+		"REMOVED": SpareUnavailable,
+		"ONLINE":  SpareUnknown,
+	}
+	for state, want := range tests {
+		if got := SpareStatus(state); got != want {
+			t.Errorf("SpareStatus(%q) = %q, want %q", state, got, want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package zfs
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 )
 
@@ -23,6 +24,33 @@ func parseNullTime(ns sql.NullString) time.Time {
 	return time.Time{}
 }
 
+// FormatBytes renders a byte count the way zpool and zfs list do: 1024-based
+// with three significant digits (512B, 96.0K, 931G, 3.62T). It is the
+// inverse of the agent's parseHumanSize.
+func FormatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+	v := float64(b)
+	suffix := ""
+	for _, s := range []string{"K", "M", "G", "T", "P", "E"} {
+		v /= unit
+		suffix = s
+		if v < unit {
+			break
+		}
+	}
+	switch {
+	case v < 10:
+		return fmt.Sprintf("%.2f%s", v, suffix)
+	case v < 100:
+		return fmt.Sprintf("%.1f%s", v, suffix)
+	default:
+		return fmt.Sprintf("%.0f%s", v, suffix)
+	}
+}
+
 func nullTimeString(t time.Time) interface{} {
 	if t.IsZero() {
 		return nil
@@ -0,0 +1,20 @@
+package zfs
+
+import "testing"
+
+func TestFormatBytes(t *testing.T) {
+	cases := map[int64]string{
+		0:                     "0B",
+		512:                   "512B",
+		1024:                  "1.00K",
+		98304:                 "96.0K",
+		999653638144:          "931G",
+		3980464442573:         "3.62T",
+		5 * 1024 * 1024 << 30: "5.00P",
+	}
+	for in, want := range cases {
+		if got := FormatBytes(in); got != want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", in, got, want)
+		}
+	}
+}
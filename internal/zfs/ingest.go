@@ -21,23 +21,39 @@ type ZFSAgentReport struct {
 
 // ZFSAgentPool represents a pool from the agent report
 type ZFSAgentPool struct {
-	Name           string           `json:"name"`
-	GUID           string           `json:"guid"`
-	Status         string           `json:"status"`
-	Health         string           `json:"health"`
-	Size           int64            `json:"size_bytes"`
-	Allocated      int64            `json:"allocated_bytes"`
-	Free           int64            `json:"free_bytes"`
-	Fragmentation  int              `json:"fragmentation"`
-	CapacityPct    int              `json:"capacity_pct"`
-	DedupRatio     float64          `json:"dedup_ratio"`
-	Altroot        string           `json:"altroot"`
-	ReadErrors     int64            `json:"read_errors"`
-	WriteErrors    int64            `json:"write_errors"`
-	ChecksumErrors int64            `json:"checksum_errors"`
-	CompressRatio  float64          `json:"compress_ratio"`
-	Scan           *ZFSAgentScan    `json:"scan"`
-	Devices        []ZFSAgentDevice `json:"devices"`
+	Name           string            `json:"name"`
+	GUID           string            `json:"guid"`
+	Status         string            `json:"status"`
+	Health         string            `json:"health"`
+	Size           int64             `json:"size_bytes"`
+	Allocated      int64             `json:"allocated_bytes"`
+	Free           int64             `json:"free_bytes"`
+	Fragmentation  int               `json:"fragmentation"`
+	CapacityPct    int               `json:"capacity_pct"`
+	DedupRatio     float64           `json:"dedup_ratio"`
+	Altroot        string            `json:"altroot"`
+	ReadErrors     int64             `json:"read_errors"`
+	WriteErrors    int64             `json:"write_errors"`
+	ChecksumErrors int64             `json:"checksum_errors"`
+	CompressRatio  float64           `json:"compress_ratio"`
+	Scan           *ZFSAgentScan     `json:"scan"`
+	Operations     []ZFSAgentScan    `json:"operations,omitempty"`
+	Devices        []ZFSAgentDevice  `json:"devices"`
+	Properties     []ZFSPoolProperty `json:"properties,omitempty"`
+	IOStats        *ZFSAgentIOStats  `json:"iostats,omitempty"`
+}
+
+// ZFSAgentIOStats is a pool's sampled `zpool iostat` activity, with the
+// per-vdev breakdown
+type ZFSAgentIOStats struct {
+	IOStatRates
+	Vdevs []ZFSAgentVdevIOStats `json:"vdevs,omitempty"`
+}
+
+// ZFSAgentVdevIOStats is one vdev or leaf device's sampled activity
+type ZFSAgentVdevIOStats struct {
+	Name string `json:"name"`
+	IOStatRates
 }
 
 // ZFSAgentDataset represents a dataset from the agent report
@@ -95,6 +111,13 @@ type ZFSAgentDevice struct {
 
 // ProcessZFSReport handles incoming ZFS data from an agent report
 func ProcessZFSReport(db *sql.DB, hostname string, zfsData json.RawMessage) error {
+	return ProcessZFSReportAt(db, hostname, zfsData, time.Time{})
+}
+
+// ProcessZFSReportAt is ProcessZFSReport for a report collected at the
+// given time, e.g. one imported after the fact: pool usage and I/O samples
+// are recorded at that time instead of now. A zero time means now.
+func ProcessZFSReportAt(db *sql.DB, hostname string, zfsData json.RawMessage, collectedAt time.Time) error {
 	if len(zfsData) == 0 || string(zfsData) == "null" {
 		return nil
 	}
@@ -110,7 +133,7 @@ func ProcessZFSReport(db *sql.DB, hostname string, zfsData json.RawMessage) erro
 
 	poolIDs := make(map[string]int64) // pool name -> pool ID
 	for _, pool := range report.Pools {
-		poolID, err := processPool(db, hostname, pool)
+		poolID, err := processPool(db, hostname, pool, collectedAt)
 		if err != nil {
 			log.Printf("⚠️  Failed to process pool %s: %v", pool.Name, err)
 			continue
@@ -124,8 +147,9 @@ func ProcessZFSReport(db *sql.DB, hostname string, zfsData json.RawMessage) erro
 	return nil
 }
 
-// processPool handles a single pool from the agent report
-func processPool(db *sql.DB, hostname string, pool ZFSAgentPool) (int64, error) {
+// processPool handles a single pool from the agent report, collected at
+// collectedAt (zero for now)
+func processPool(db *sql.DB, hostname string, pool ZFSAgentPool, collectedAt time.Time) (int64, error) {
 	// Build pool record
 	dbPool := &ZFSPool{
 		Hostname:       hostname,
@@ -173,10 +197,35 @@ func processPool(db *sql.DB, hostname string, pool ZFSAgentPool) (int64, error)
 		processDeviceRecursive(db, poolID, hostname, pool.Name, dev, "", &vdevIndex)
 	}
 
+	// Older agents don't send properties; keep what's stored rather than
+	// wiping it.
+	if len(pool.Properties) > 0 {
+		if err := ReplacePoolProperties(db, poolID, hostname, pool.Name, pool.Properties); err != nil {
+			log.Printf("⚠️  Failed to store properties for pool %s: %v", pool.Name, err)
+		}
+	}
+
+	at := collectedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if err := RecordPoolUsage(db, poolID, hostname, pool.Name, pool.Allocated, pool.Size, at); err != nil {
+		log.Printf("⚠️  Failed to record usage for pool %s: %v", pool.Name, err)
+	}
+
+	if pool.IOStats != nil {
+		if err := RecordPoolIOStats(db, poolID, hostname, pool.Name, pool.IOStats, at); err != nil {
+			log.Printf("⚠️  Failed to record I/O stats for pool %s: %v", pool.Name, err)
+		}
+	}
+
 	// Record scrub history if applicable
 	if pool.Scan != nil {
 		processScrubHistory(db, poolID, hostname, pool.Name, pool.Scan)
 	}
+	for i := range pool.Operations {
+		processScrubHistory(db, poolID, hostname, pool.Name, &pool.Operations[i])
+	}
 
 	return poolID, nil
 }
@@ -232,23 +281,18 @@ func processDeviceRecursive(db *sql.DB, poolID int64, hostname, poolName string,
 	}
 }
 
-// processScrubHistory records scrub history if needed
+// processScrubHistory records scrub, resilver, trim or initialize history if
+// needed. A scan keeps one record, keyed by its start time, for its whole
+// life: every report while it runs updates that record's progress, and the
+// record is final once a report shows the scan finished or canceled.
 func processScrubHistory(db *sql.DB, poolID int64, hostname, poolName string, scan *ZFSAgentScan) {
 	if scan.Function == "" || scan.Function == "none" {
 		return
 	}
 
-	// Check if we should record this scrub
-	lastScrub, _ := GetLastScrub(db, poolID)
-	shouldRecord := shouldRecordScrub(lastScrub, scan)
-
-	if !shouldRecord {
-		return
-	}
-
-	startTime := scan.StartTime
-	if startTime.IsZero() && !scan.EndTime.IsZero() {
-		startTime = scan.EndTime // Use end time as fallback
+	startTime := scanStartTime(scan)
+	if startTime.IsZero() {
+		return // no useful timestamp at all
 	}
 
 	record := &ZFSScrubHistory{
@@ -269,15 +313,86 @@ func processScrubHistory(db *sql.DB, poolID int64, hostname, poolName string, sc
 		TimeRemaining:   scan.TimeRemaining,
 	}
 
+	current, err := GetScanByStartTime(db, poolID, scan.Function, startTime)
+	if err != nil {
+		log.Printf("⚠️  Failed to look up scrub history: %v", err)
+		return
+	}
+	if current == nil && scanFinalized(scan.State) {
+		// A finished or canceled scan has no "since" time, so a start
+		// derived from its duration may miss the running scan's record
+		// by a few seconds (or entirely, for a canceled one). It ends the
+		// latest scan of its type still open, if that started before it
+		// ended. Reports after that keep showing the same ended scan, which
+		// is recognized by its end time.
+		last, err := GetLastScanOfType(db, poolID, scan.Function)
+		if err != nil {
+			log.Printf("⚠️  Failed to look up scrub history: %v", err)
+			return
+		}
+		switch {
+		case last == nil:
+		case !scanFinalized(last.State) && !last.StartTime.After(scanEnd(scan)):
+			current = last
+		case scanFinalized(last.State) && !scan.EndTime.IsZero() && last.EndTime.Equal(scan.EndTime):
+			return
+		}
+	}
+	if current != nil {
+		if scanFinalized(current.State) {
+			return
+		}
+		record.ID = current.ID
+		record.StartTime = current.StartTime
+		if err := UpdateZFSScrubHistory(db, record); err != nil {
+			log.Printf("⚠️  Failed to update scrub history: %v", err)
+		}
+		return
+	}
+
+	// Compare against the last record of the same type so a TRIM doesn't
+	// mask a scrub that runs alongside it
+	lastScrub, _ := GetLastScanOfType(db, poolID, scan.Function)
+	if !shouldRecordScrub(lastScrub, scan) {
+		return
+	}
+
 	if _, err := InsertZFSScrubHistory(db, record); err != nil {
 		log.Printf("⚠️  Failed to insert scrub history: %v", err)
 	}
 }
 
-// shouldRecordScrub determines if a scrub should be recorded
+// scanStartTime identifies a scan: its start time, or when the agent only
+// saw it finish (zpool status then gives no "since" time), its end time
+// minus its duration, or its end time alone.
+func scanStartTime(scan *ZFSAgentScan) time.Time {
+	if !scan.StartTime.IsZero() {
+		return scan.StartTime
+	}
+	if !scan.EndTime.IsZero() && scan.Duration > 0 {
+		return scan.EndTime.Add(-time.Duration(scan.Duration) * time.Second)
+	}
+	return scan.EndTime
+}
+
+// scanEnd is when a finalized scan ended, or now if the agent didn't say.
+func scanEnd(scan *ZFSAgentScan) time.Time {
+	if scan.EndTime.IsZero() {
+		return time.Now()
+	}
+	return scan.EndTime
+}
+
+// scanFinalized reports whether a recorded scan state is terminal
+func scanFinalized(state string) bool {
+	return state == "finished" || state == "canceled"
+}
+
+// shouldRecordScrub determines if a scan without a record of its own is new
+// rather than an older one showing up late
 func shouldRecordScrub(lastScrub *ZFSScrubHistory, scan *ZFSAgentScan) bool {
-	// Skip scrubs with no useful timestamp at all
-	if scan.StartTime.IsZero() && scan.EndTime.IsZero() {
+	scanTime := scanStartTime(scan)
+	if scanTime.IsZero() {
 		return false
 	}
 
@@ -286,25 +401,11 @@ func shouldRecordScrub(lastScrub *ZFSScrubHistory, scan *ZFSAgentScan) bool {
 		return true
 	}
 
-	// State changed to finished - record completion
-	if scan.State == "finished" && lastScrub.State != "finished" {
-		return true
-	}
-
-	// New scrub started (different start time or end time)
-	scanTime := scan.StartTime
-	if scanTime.IsZero() {
-		scanTime = scan.EndTime
-	}
 	lastTime := lastScrub.StartTime
 	if lastTime.IsZero() {
 		lastTime = lastScrub.EndTime
 	}
-	if !scanTime.IsZero() && !lastTime.IsZero() && scanTime.After(lastTime) {
-		return true
-	}
-
-	return false
+	return lastTime.IsZero() || scanTime.After(lastTime)
 }
 
 // processDatasets handles incoming dataset data from an agent report
@@ -0,0 +1,152 @@
+package zfs
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// poolIOStatRetentionDays bounds zfs_pool_iostat. Samples are stored per
+// report and per vdev, so this is kept shorter than usage history.
+const poolIOStatRetentionDays = 30
+
+// IOStatRates is read/write activity averaged over the agent's sample
+// window
+type IOStatRates struct {
+	ReadOps    int64 `json:"read_ops"`    // operations per second
+	WriteOps   int64 `json:"write_ops"`   // operations per second
+	ReadBytes  int64 `json:"read_bytes"`  // bytes per second
+	WriteBytes int64 `json:"write_bytes"` // bytes per second
+}
+
+// IOStatSample is one stored iostat sample
+type IOStatSample struct {
+	IOStatRates
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// IOStatSeries is the iostat history of a pool or one of its vdevs, with
+// the mean over the period
+type IOStatSeries struct {
+	Name    string         `json:"name,omitempty"` // vdev name; empty for the pool
+	Average IOStatRates    `json:"average"`
+	Samples []IOStatSample `json:"samples"`
+}
+
+// PoolIOStatHistory is a pool's iostat history since a point in time
+type PoolIOStatHistory struct {
+	Pool  IOStatSeries   `json:"pool"`
+	Vdevs []IOStatSeries `json:"vdevs"`
+}
+
+// RecordPoolIOStats stores a pool's iostat sample and its vdev breakdown
+// in the current minute (a later report in the same minute replaces it)
+// and drops samples past poolIOStatRetentionDays.
+func RecordPoolIOStats(db *sql.DB, poolID int64, hostname, poolName string, stats *ZFSAgentIOStats, at time.Time) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	ts := at.UTC().Truncate(time.Minute).Format(timeFormat)
+	insert := func(vdev string, r IOStatRates) error {
+		_, err := tx.Exec(`
+			INSERT INTO zfs_pool_iostat (pool_id, hostname, pool_name, vdev, read_ops, write_ops, read_bytes, write_bytes, timestamp)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(pool_id, vdev, timestamp) DO UPDATE SET
+				read_ops    = excluded.read_ops,
+				write_ops   = excluded.write_ops,
+				read_bytes  = excluded.read_bytes,
+				write_bytes = excluded.write_bytes
+		`, poolID, hostname, poolName, vdev, r.ReadOps, r.WriteOps, r.ReadBytes, r.WriteBytes, ts)
+		return err
+	}
+
+	if err := insert("", stats.IOStatRates); err != nil {
+		return fmt.Errorf("record pool iostat: %w", err)
+	}
+	for _, v := range stats.Vdevs {
+		if v.Name == "" {
+			continue
+		}
+		if err := insert(v.Name, v.IOStatRates); err != nil {
+			return fmt.Errorf("record vdev %s iostat: %w", v.Name, err)
+		}
+	}
+
+	cutoff := at.UTC().AddDate(0, 0, -poolIOStatRetentionDays).Format(timeFormat)
+	if _, err := tx.Exec(`DELETE FROM zfs_pool_iostat WHERE pool_id = ? AND timestamp < ?`, poolID, cutoff); err != nil {
+		return fmt.Errorf("prune pool iostat: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetPoolIOStatHistory returns a pool's iostat samples since the given
+// time, oldest first, with vdevs ordered by name.
+func GetPoolIOStatHistory(db *sql.DB, poolID int64, since time.Time) (*PoolIOStatHistory, error) {
+	rows, err := db.Query(`
+		SELECT vdev, read_ops, write_ops, read_bytes, write_bytes, timestamp
+		FROM zfs_pool_iostat
+		WHERE pool_id = ? AND timestamp >= ?
+		ORDER BY vdev, timestamp
+	`, poolID, since.UTC().Format(timeFormat))
+	if err != nil {
+		return nil, fmt.Errorf("query pool iostat: %w", err)
+	}
+	defer rows.Close()
+
+	history := &PoolIOStatHistory{
+		Pool:  IOStatSeries{Samples: []IOStatSample{}},
+		Vdevs: []IOStatSeries{},
+	}
+	var current *IOStatSeries
+	for rows.Next() {
+		var vdev string
+		var s IOStatSample
+		var ts sql.NullString
+		if err := rows.Scan(&vdev, &s.ReadOps, &s.WriteOps, &s.ReadBytes, &s.WriteBytes, &ts); err != nil {
+			return nil, err
+		}
+		s.Timestamp = parseNullTime(ts)
+
+		switch {
+		case vdev == "":
+			current = &history.Pool
+		case current == nil || current.Name != vdev:
+			history.Vdevs = append(history.Vdevs, IOStatSeries{Name: vdev})
+			current = &history.Vdevs[len(history.Vdevs)-1]
+		}
+		current.Samples = append(current.Samples, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	history.Pool.Average = averageRates(history.Pool.Samples)
+	for i := range history.Vdevs {
+		history.Vdevs[i].Average = averageRates(history.Vdevs[i].Samples)
+	}
+	return history, nil
+}
+
+// averageRates returns the mean of each rate across the samples
+func averageRates(samples []IOStatSample) IOStatRates {
+	var avg IOStatRates
+	if len(samples) == 0 {
+		return avg
+	}
+	for _, s := range samples {
+		avg.ReadOps += s.ReadOps
+		avg.WriteOps += s.WriteOps
+		avg.ReadBytes += s.ReadBytes
+		avg.WriteBytes += s.WriteBytes
+	}
+	n := int64(len(samples))
+	avg.ReadOps /= n
+	avg.WriteOps /= n
+	avg.ReadBytes /= n
+	avg.WriteBytes /= n
+	return avg
+}
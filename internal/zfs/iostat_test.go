@@ -0,0 +1,54 @@
+package zfs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPoolIOStatHistory(t *testing.T) {
+	db := setupZFSTestDB(t)
+	now := time.Now().UTC()
+
+	record := func(at time.Time, poolReads, sdaReads int64) {
+		stats := &ZFSAgentIOStats{
+			IOStatRates: IOStatRates{ReadOps: poolReads, WriteBytes: 1 << 20},
+			Vdevs: []ZFSAgentVdevIOStats{
+				{Name: "mirror-0", IOStatRates: IOStatRates{ReadOps: poolReads}},
+				{Name: "sda", IOStatRates: IOStatRates{ReadOps: sdaReads}},
+			},
+		}
+		if err := RecordPoolIOStats(db, 1, "nas01", "tank", stats, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	record(now.AddDate(0, 0, -(poolIOStatRetentionDays+1)), 999, 999) // pruned
+	record(now.Add(-2*time.Hour), 100, 40)
+	record(now.Add(-time.Hour), 200, 80)
+	record(now.Add(-time.Hour), 300, 120) // same minute: replaces the sample above
+
+	h, err := GetPoolIOStatHistory(db, 1, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Pool.Samples) != 2 {
+		t.Fatalf("expected 2 pool samples, got %d", len(h.Pool.Samples))
+	}
+	if h.Pool.Average.ReadOps != 200 || h.Pool.Average.WriteBytes != 1<<20 {
+		t.Errorf("unexpected pool average: %+v", h.Pool.Average)
+	}
+	if len(h.Vdevs) != 2 || h.Vdevs[0].Name != "mirror-0" || h.Vdevs[1].Name != "sda" {
+		t.Fatalf("unexpected vdevs: %+v", h.Vdevs)
+	}
+	if got := h.Vdevs[1].Average.ReadOps; got != 80 {
+		t.Errorf("expected sda average of 80 read ops, got %d", got)
+	}
+
+	var total int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM zfs_pool_iostat`).Scan(&total); err != nil {
+		t.Fatal(err)
+	}
+	if total != 6 {
+		t.Errorf("expected samples past retention to be pruned, %d rows left", total)
+	}
+}
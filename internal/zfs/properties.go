@@ -0,0 +1,215 @@
+package zfs
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"vigil/internal/events"
+	"vigil/internal/settings"
+)
+
+// ─── Pool Property Types ─────────────────────────────────────────────────────
+
+// ZFSPoolProperty is one pool property from `zpool get`, as sent by the agent
+// and stored per pool.
+type ZFSPoolProperty struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// PropertyRule flags a pool property value worth a warning. Rules live in
+// the zfs/property_rules setting.
+type PropertyRule struct {
+	Property string   `json:"property"`
+	Warn     []string `json:"warn"`               // values that trigger the warning
+	Pools    []string `json:"pools,omitempty"`    // pool names it applies to; empty = all
+	SSDOnly  bool     `json:"ssd_only,omitempty"` // only pools whose data disks are all SSD/NVMe
+	Message  string   `json:"message"`
+}
+
+// PropertyViolation is a pool property matching a rule.
+type PropertyViolation struct {
+	Property string `json:"property"`
+	Value    string `json:"value"`
+	Message  string `json:"message"`
+}
+
+// ─── Property Storage ────────────────────────────────────────────────────────
+
+// ReplacePoolProperties stores the latest properties of a pool, dropping
+// any the agent no longer reports.
+func ReplacePoolProperties(db *sql.DB, poolID int64, hostname, poolName string, props []ZFSPoolProperty) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.Exec("DELETE FROM zfs_pool_properties WHERE pool_id = ?", poolID); err != nil {
+		return fmt.Errorf("clear pool properties: %w", err)
+	}
+
+	now := nowString()
+	for _, p := range props {
+		if p.Name == "" {
+			continue
+		}
+		_, err := tx.Exec(`
+			INSERT OR REPLACE INTO zfs_pool_properties (pool_id, hostname, pool_name, property, value, source, last_seen)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, poolID, hostname, poolName, p.Name, p.Value, p.Source, now)
+		if err != nil {
+			return fmt.Errorf("insert pool property %s: %w", p.Name, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetPoolProperties retrieves the stored properties of a pool, by name.
+func GetPoolProperties(db *sql.DB, poolID int64) ([]ZFSPoolProperty, error) {
+	rows, err := db.Query(`
+		SELECT property, value, COALESCE(source, '')
+		FROM zfs_pool_properties
+		WHERE pool_id = ?
+		ORDER BY property
+	`, poolID)
+	if err != nil {
+		return nil, fmt.Errorf("query pool properties: %w", err)
+	}
+	defer rows.Close()
+
+	props := []ZFSPoolProperty{}
+	for rows.Next() {
+		var p ZFSPoolProperty
+		if err := rows.Scan(&p.Name, &p.Value, &p.Source); err != nil {
+			return nil, fmt.Errorf("scan pool property row: %w", err)
+		}
+		props = append(props, p)
+	}
+	return props, rows.Err()
+}
+
+// PoolIsSolidState reports whether every data disk of the pool with a known
+// serial is an SSD or NVMe drive, going by each drive's latest health
+// snapshot. Log, cache and spare devices don't count; a pool with no
+// identifiable disks is not solid state.
+func PoolIsSolidState(db *sql.DB, poolID int64) bool {
+	rows, err := db.Query(`
+		SELECT (
+			SELECT h.drive_type FROM drive_health_snapshots h
+			WHERE h.hostname = d.hostname AND h.serial_number = d.serial_number
+			ORDER BY h.timestamp DESC LIMIT 1
+		)
+		FROM zfs_pool_devices d
+		WHERE d.pool_id = ? AND COALESCE(d.serial_number, '') != ''
+			AND d.is_log = 0 AND d.is_cache = 0 AND d.is_spare = 0
+	`, poolID)
+	if err != nil {
+		return false
+	}
+	defer rows.Close()
+
+	disks := 0
+	for rows.Next() {
+		var driveType sql.NullString
+		if err := rows.Scan(&driveType); err != nil {
+			return false
+		}
+		switch strings.ToUpper(driveType.String) {
+		case "SSD", "NVME":
+			disks++
+		default:
+			return false
+		}
+	}
+	return disks > 0 && rows.Err() == nil
+}
+
+// ─── Property Rules ──────────────────────────────────────────────────────────
+
+// LoadPropertyRules reads the zfs/property_rules setting, falling back to
+// the built-in rules when it is missing or malformed.
+func LoadPropertyRules(db *sql.DB) []PropertyRule {
+	fallback, _ := settings.DefaultValue("zfs", "property_rules")
+	raw := settings.GetStringSettingWithDefault(db, "zfs", "property_rules", fallback)
+
+	var rules []PropertyRule
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		log.Printf("⚠️  Invalid zfs.property_rules setting, using defaults: %v", err)
+		rules = nil
+		json.Unmarshal([]byte(fallback), &rules) //nolint:errcheck
+	}
+	return rules
+}
+
+// CheckPoolProperties returns the properties of poolName that match a rule.
+func CheckPoolProperties(rules []PropertyRule, poolName string, props []ZFSPoolProperty, solidState bool) []PropertyViolation {
+	values := make(map[string]string, len(props))
+	for _, p := range props {
+		values[p.Name] = p.Value
+	}
+
+	violations := []PropertyViolation{}
+	for _, rule := range rules {
+		value, ok := values[rule.Property]
+		if !ok || (rule.SSDOnly && !solidState) || !ruleAppliesToPool(rule, poolName) {
+			continue
+		}
+		for _, warn := range rule.Warn {
+			if strings.EqualFold(value, warn) {
+				violations = append(violations, PropertyViolation{
+					Property: rule.Property,
+					Value:    value,
+					Message:  rule.Message,
+				})
+				break
+			}
+		}
+	}
+	return violations
+}
+
+func ruleAppliesToPool(rule PropertyRule, poolName string) bool {
+	if len(rule.Pools) == 0 {
+		return true
+	}
+	for _, p := range rule.Pools {
+		if p == poolName {
+			return true
+		}
+	}
+	return false
+}
+
+// publishPropertyEvents fires one warning per pool listing every property
+// that matches a rule.
+func publishPropertyEvents(bus *events.Bus, db *sql.DB, hostname string, pool ZFSAgentPool, poolID int64) {
+	if len(pool.Properties) == 0 {
+		return
+	}
+
+	violations := CheckPoolProperties(LoadPropertyRules(db), pool.Name, pool.Properties, PoolIsSolidState(db, poolID))
+	if len(violations) == 0 {
+		return
+	}
+
+	pairs := make([]string, len(violations))
+	for i, v := range violations {
+		pairs[i] = v.Property + "=" + v.Value
+	}
+	bus.Publish(events.Event{
+		Type:     events.ZFSPropertyWarning,
+		Severity: events.SeverityWarning,
+		Hostname: hostname,
+		Message:  fmt.Sprintf("ZFS pool %q has risky properties: %s", pool.Name, strings.Join(pairs, ", ")),
+		Metadata: map[string]string{
+			"pool_name":  pool.Name,
+			"properties": strings.Join(pairs, ","),
+		},
+	})
+}
@@ -0,0 +1,75 @@
+package zfs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"vigil/internal/settings"
+)
+
+func defaultPropertyRules(t *testing.T) []PropertyRule {
+	t.Helper()
+	raw, ok := settings.DefaultValue("zfs", "property_rules")
+	if !ok {
+		t.Fatal("zfs.property_rules has no default")
+	}
+	var rules []PropertyRule
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		t.Fatalf("default property rules don't parse: %v", err)
+	}
+	return rules
+}
+
+func TestCheckPoolProperties_Defaults(t *testing.T) {
+	rules := defaultPropertyRules(t)
+	props := []ZFSPoolProperty{
+		{Name: "failmode", Value: "continue", Source: "local"},
+		{Name: "autotrim", Value: "off", Source: "default"},
+		{Name: "ashift", Value: "12", Source: "local"},
+	}
+
+	// HDD pool: only failmode is flagged.
+	got := CheckPoolProperties(rules, "tank", props, false)
+	if len(got) != 1 || got[0].Property != "failmode" || got[0].Value != "continue" {
+		t.Fatalf("HDD pool: expected failmode violation only, got %+v", got)
+	}
+
+	// All-SSD pool: autotrim=off is flagged too.
+	got = CheckPoolProperties(rules, "fast", props, true)
+	if len(got) != 2 {
+		t.Fatalf("SSD pool: expected 2 violations, got %+v", got)
+	}
+	if got[1].Property != "autotrim" || got[1].Message == "" {
+		t.Errorf("SSD pool: expected autotrim violation with message, got %+v", got[1])
+	}
+}
+
+func TestCheckPoolProperties_Healthy(t *testing.T) {
+	props := []ZFSPoolProperty{
+		{Name: "failmode", Value: "wait"},
+		{Name: "autotrim", Value: "on"},
+	}
+	if got := CheckPoolProperties(defaultPropertyRules(t), "fast", props, true); len(got) != 0 {
+		t.Errorf("expected no violations, got %+v", got)
+	}
+}
+
+func TestCheckPoolProperties_PoolScope(t *testing.T) {
+	rules := []PropertyRule{{Property: "autoreplace", Warn: []string{"off"}, Pools: []string{"critical"}}}
+	props := []ZFSPoolProperty{{Name: "autoreplace", Value: "off"}}
+
+	if got := CheckPoolProperties(rules, "scratch", props, false); len(got) != 0 {
+		t.Errorf("rule scoped to another pool fired: %+v", got)
+	}
+	if got := CheckPoolProperties(rules, "critical", props, false); len(got) != 1 {
+		t.Errorf("expected violation on scoped pool, got %+v", got)
+	}
+}
+
+func TestCheckPoolProperties_MissingProperty(t *testing.T) {
+	// Older ZFS builds don't report every property; absent ones never match.
+	rules := []PropertyRule{{Property: "compatibility", Warn: []string{"off"}}}
+	if got := CheckPoolProperties(rules, "tank", nil, false); len(got) != 0 {
+		t.Errorf("expected no violations, got %+v", got)
+	}
+}
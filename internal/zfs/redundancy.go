@@ -0,0 +1,117 @@
+package zfs
+
+import (
+	"fmt"
+
+	"vigil/internal/events"
+)
+
+// Pool redundancy levels, from the pool's top-level data vdevs
+const (
+	RedundancyNone   = "none" // at least one vdev is a bare disk: losing it loses the pool
+	RedundancyMirror = "mirror"
+	RedundancyRaidz1 = "raidz1"
+	RedundancyRaidz2 = "raidz2"
+	RedundancyRaidz3 = "raidz3"
+	RedundancyDraid1 = "draid1"
+	RedundancyDraid2 = "draid2"
+	RedundancyDraid3 = "draid3"
+)
+
+// redundancyRank orders the levels by how many disk failures per vdev they
+// survive. A 2-way mirror survives one, like raidz1.
+var redundancyRank = map[string]int{
+	RedundancyNone:   0,
+	RedundancyMirror: 1,
+	RedundancyRaidz1: 1,
+	RedundancyRaidz2: 2,
+	RedundancyRaidz3: 3,
+	RedundancyDraid1: 1,
+	RedundancyDraid2: 2,
+	RedundancyDraid3: 3,
+}
+
+// classifyRedundancy returns the redundancy of a pool made of top-level
+// vdevs of the given types: the weakest of them, since the pool is lost
+// with any one vdev. Spares, logs and cache devices hold no pool data and
+// are ignored, as are the special and dedup section headers: their mirrors
+// are top-level vdevs of their own, and a bare disk under them is passed
+// in as "disk". Returns "" when there is no data vdev to judge by.
+func classifyRedundancy(vdevTypes []string) string {
+	level := ""
+	for _, t := range vdevTypes {
+		switch t {
+		case "spare", "log", "cache", "special", "dedup":
+			continue
+		case RedundancyMirror, RedundancyRaidz1, RedundancyRaidz2, RedundancyRaidz3,
+			RedundancyDraid1, RedundancyDraid2, RedundancyDraid3:
+		default:
+			t = RedundancyNone // a plain disk (or file) striped into the pool
+		}
+		if level == "" || redundancyRank[t] < redundancyRank[level] {
+			level = t
+		}
+	}
+	return level
+}
+
+// PoolRedundancy classifies a pool from its stored devices
+func PoolRedundancy(devices []ZFSPoolDevice) string {
+	allocClass := make(map[string]bool)
+	for _, d := range devices {
+		if d.VdevParent == "" && isAllocationClass(d.VdevType) {
+			allocClass[d.DeviceName] = true
+		}
+	}
+	var types []string
+	for _, d := range devices {
+		if d.VdevParent == "" && !d.IsSpare && !d.IsLog && !d.IsCache ||
+			allocClass[d.VdevParent] && d.VdevType == "disk" {
+			types = append(types, d.VdevType)
+		}
+	}
+	return classifyRedundancy(types)
+}
+
+// agentPoolRedundancy classifies a pool from its reported device tree
+func agentPoolRedundancy(devices []ZFSAgentDevice) string {
+	var types []string
+	for _, d := range devices {
+		if !d.IsSpare && !d.IsLog && !d.IsCache {
+			types = append(types, d.VdevType)
+		}
+		if isAllocationClass(d.VdevType) {
+			for _, c := range d.Children {
+				types = append(types, c.VdevType)
+			}
+		}
+	}
+	return classifyRedundancy(types)
+}
+
+// isAllocationClass reports whether vdevType heads the special or dedup
+// section. Unlike logs and cache, losing a device in either loses the
+// pool, so a bare disk listed under them counts against its redundancy.
+func isAllocationClass(vdevType string) bool {
+	return vdevType == "special" || vdevType == "dedup"
+}
+
+// publishRedundancyEvents fires once when a pool is first seen without
+// redundancy, or loses it (e.g. a single disk was striped into a raidz
+// pool); not again on every report while it stays that way. prevLevel is
+// the pool's redundancy before this report, "" if it is new.
+func publishRedundancyEvents(bus *events.Bus, hostname string, pool ZFSAgentPool, prevLevel string) {
+	if agentPoolRedundancy(pool.Devices) != RedundancyNone || prevLevel == RedundancyNone {
+		return
+	}
+	bus.Publish(events.Event{
+		Type:     events.ZFSNoRedundancy,
+		Severity: events.SeverityInfo,
+		Hostname: hostname,
+		Message:  fmt.Sprintf("ZFS pool %q has no redundancy: a single disk failure will lose its data", pool.Name),
+		Metadata: map[string]string{
+			"pool_name":  pool.Name,
+			"redundancy": RedundancyNone,
+		},
+	})
+}
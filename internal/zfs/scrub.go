@@ -8,6 +8,11 @@ import (
 
 // ─── Scrub History Operations ────────────────────────────────────────────────
 
+// integrityScanFilter restricts scrub history queries to scans that verify
+// pool data. TRIM and initialize records share the table but don't count as
+// a scrub for "last scrub" or overdue checks.
+const integrityScanFilter = "scan_type IN ('scrub', 'resilver')"
+
 // InsertZFSScrubHistory adds a new scrub/resilver history record
 func InsertZFSScrubHistory(db *sql.DB, record *ZFSScrubHistory) (int64, error) {
 	// CRITICAL: Validate start_time - use current time if not set (NOT NULL constraint)
@@ -41,6 +46,29 @@ func InsertZFSScrubHistory(db *sql.DB, record *ZFSScrubHistory) (int64, error) {
 	return result.LastInsertId()
 }
 
+// UpdateZFSScrubHistory overwrites the scan fields of record.ID with a newer
+// view of the same scan.
+func UpdateZFSScrubHistory(db *sql.DB, record *ZFSScrubHistory) error {
+	_, err := db.Exec(`
+		UPDATE zfs_scrub_history SET
+			state = ?, end_time = ?, duration_secs = ?,
+			data_examined = ?, data_total = ?, errors_found = ?,
+			bytes_repaired = ?, blocks_repaired = ?,
+			progress_pct = ?, rate_bytes_sec = ?, time_remaining = ?
+		WHERE id = ?
+	`,
+		record.State, nullTimeString(record.EndTime), record.DurationSecs,
+		record.DataExamined, record.DataTotal, record.ErrorsFound,
+		record.BytesRepaired, record.BlocksRepaired,
+		record.ProgressPct, record.RateBytesPerSec, record.TimeRemaining,
+		record.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update scrub history: %w", err)
+	}
+	return nil
+}
+
 // GetZFSScrubHistory retrieves scrub history for a pool
 func GetZFSScrubHistory(db *sql.DB, poolID int64, limit int) ([]ZFSScrubHistory, error) {
 	if limit <= 0 {
@@ -67,9 +95,42 @@ func GetZFSScrubHistory(db *sql.DB, poolID int64, limit int) ([]ZFSScrubHistory,
 	return scanScrubHistory(rows)
 }
 
-// GetLastScrub retrieves the most recent scrub for a pool
+// GetLastScrub retrieves the most recent scrub or resilver for a pool
 func GetLastScrub(db *sql.DB, poolID int64) (*ZFSScrubHistory, error) {
-	history, err := GetZFSScrubHistory(db, poolID, 1)
+	return getLastScan(db, `WHERE pool_id = ? AND `+integrityScanFilter, poolID)
+}
+
+// GetLastScanOfType retrieves the most recent record of one scan type
+// (scrub, resilver, trim or initialize) for a pool
+func GetLastScanOfType(db *sql.DB, poolID int64, scanType string) (*ZFSScrubHistory, error) {
+	return getLastScan(db, `WHERE pool_id = ? AND scan_type = ?`, poolID, scanType)
+}
+
+// GetScanByStartTime retrieves the record of one scan, identified by its
+// type and start time, or nil if it hasn't been recorded
+func GetScanByStartTime(db *sql.DB, poolID int64, scanType string, startTime time.Time) (*ZFSScrubHistory, error) {
+	return getLastScan(db, `WHERE pool_id = ? AND scan_type = ? AND start_time = ?`, poolID, scanType, startTime.Format(timeFormat))
+}
+
+func getLastScan(db *sql.DB, where string, args ...interface{}) (*ZFSScrubHistory, error) {
+	rows, err := db.Query(`
+		SELECT id, pool_id, hostname, pool_name, scan_type, state,
+			start_time, end_time, duration_secs,
+			data_examined, data_total, errors_found,
+			bytes_repaired, blocks_repaired,
+			progress_pct, rate_bytes_sec, time_remaining,
+			created_at
+		FROM zfs_scrub_history
+		`+where+`
+		ORDER BY start_time DESC
+		LIMIT 1
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query last scan: %w", err)
+	}
+	defer rows.Close()
+
+	history, err := scanScrubHistory(rows)
 	if err != nil {
 		return nil, err
 	}
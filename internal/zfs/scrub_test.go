@@ -0,0 +1,176 @@
+package zfs
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	vigildb "vigil/internal/db"
+
+	_ "modernc.org/sqlite"
+)
+
+func setupZFSTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	if err := vigildb.MigrateSchemaExtensions(db); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestTrimHistoryKeptSeparateFromScrubs(t *testing.T) {
+	db := setupZFSTestDB(t)
+	scrubEnd := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+	trimStart := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+
+	processScrubHistory(db, 1, "nas01", "flash", &ZFSAgentScan{
+		Function: "scrub", State: "finished", EndTime: scrubEnd, ProgressPct: 100,
+	})
+	processScrubHistory(db, 1, "nas01", "flash", &ZFSAgentScan{
+		Function: "trim", State: "scanning", StartTime: trimStart, ProgressPct: 40,
+	})
+
+	last, err := GetLastScrub(db, 1)
+	if err != nil || last == nil {
+		t.Fatalf("GetLastScrub: %v, %v", last, err)
+	}
+	if last.ScanType != "scrub" {
+		t.Errorf("last scrub should ignore trim records, got %q", last.ScanType)
+	}
+
+	trim, err := GetLastScanOfType(db, 1, "trim")
+	if err != nil || trim == nil {
+		t.Fatalf("GetLastScanOfType: %v, %v", trim, err)
+	}
+	if trim.State != "scanning" || trim.ProgressPct != 40 {
+		t.Errorf("unexpected trim record: %+v", trim)
+	}
+
+	if n := countActiveOperations(db, "trim", "nas01"); n != 1 {
+		t.Errorf("expected 1 active trim, got %d", n)
+	}
+	if n := countActiveOperations(db, "trim", "other"); n != 0 {
+		t.Errorf("expected no active trims on other host, got %d", n)
+	}
+
+	processScrubHistory(db, 1, "nas01", "flash", &ZFSAgentScan{
+		Function: "trim", State: "finished", StartTime: trimStart, EndTime: trimStart.Add(30 * time.Minute), ProgressPct: 100,
+	})
+	if n := countActiveOperations(db, "trim", ""); n != 0 {
+		t.Errorf("expected finished trim to no longer count as active, got %d", n)
+	}
+
+	history, err := GetZFSScrubHistory(db, 1, 10)
+	if err != nil {
+		t.Fatalf("GetZFSScrubHistory: %v", err)
+	}
+	if len(history) != 2 {
+		t.Errorf("expected 2 history records (scrub + trim), got %d", len(history))
+	}
+}
+
+func TestScrubLifecycleAcrossReports(t *testing.T) {
+	db := setupZFSTestDB(t)
+	start := time.Now().Add(-6 * time.Hour).UTC().Truncate(time.Second)
+
+	// What the agent parses from zpool status: a running scan has a
+	// "since" start time; a finished one only "in HH:MM:SS ... on <end>";
+	// a canceled one only "canceled on <end>".
+	scanning := func(pct float64) {
+		processScrubHistory(db, 1, "nas01", "tank", &ZFSAgentScan{
+			Function: "scrub", State: "scanning", StartTime: start, ProgressPct: pct,
+		})
+	}
+	ended := func(state string, pct float64, end time.Time, duration time.Duration) {
+		processScrubHistory(db, 1, "nas01", "tank", &ZFSAgentScan{
+			Function: "scrub", State: state, EndTime: end, Duration: int64(duration.Seconds()), ProgressPct: pct,
+		})
+	}
+
+	// A long scrub seen by several consecutive reports, then finishing
+	for _, pct := range []float64{5, 30, 30, 75} {
+		scanning(pct)
+	}
+	history, _ := GetZFSScrubHistory(db, 1, 10)
+	if len(history) != 1 {
+		t.Fatalf("expected one record for the running scrub, got %d", len(history))
+	}
+	if history[0].State != "scanning" || history[0].ProgressPct != 75 {
+		t.Errorf("expected progress to follow the latest report, got %+v", history[0])
+	}
+
+	// The reported duration leaves out time the scrub spent paused, so
+	// end minus duration doesn't land exactly on the start
+	end := start.Add(5 * time.Hour)
+	ended("finished", 100, end, 5*time.Hour-90*time.Second)
+	ended("finished", 100, end, 5*time.Hour-90*time.Second) // later reports keep showing the last scan
+	history, _ = GetZFSScrubHistory(db, 1, 10)
+	if len(history) != 1 {
+		t.Fatalf("expected the finished scrub to reuse its record, got %d", len(history))
+	}
+	if history[0].State != "finished" || history[0].ProgressPct != 100 || !history[0].EndTime.Equal(end) || !history[0].StartTime.Equal(start) {
+		t.Errorf("expected a finalized record, got %+v", history[0])
+	}
+
+	// A finalized record stays put even if a stale report comes in
+	scanning(50)
+	if last, _ := GetLastScrub(db, 1); last.State != "finished" {
+		t.Errorf("finalized scrub was reopened: %+v", last)
+	}
+
+	// The next scrub gets its own record; a canceled one is finalized too
+	start = start.Add(24 * time.Hour)
+	scanning(10)
+	ended("canceled", 40, start.Add(time.Hour), 0)
+	history, _ = GetZFSScrubHistory(db, 1, 10)
+	if len(history) != 2 {
+		t.Fatalf("expected 2 scrub records, got %d", len(history))
+	}
+	if history[0].State != "canceled" || history[0].ProgressPct != 40 {
+		t.Errorf("expected the second scrub to be canceled at 40%%, got %+v", history[0])
+	}
+
+	// An older scan turning up late doesn't add a record
+	ended("finished", 100, start.Add(-48*time.Hour), 2*time.Hour)
+	if history, _ = GetZFSScrubHistory(db, 1, 10); len(history) != 2 {
+		t.Errorf("expected a late older scan to be ignored, got %d records", len(history))
+	}
+
+	// A scrub the agent only ever saw finished gets one record, started
+	// its duration before it ended
+	end = start.Add(30 * time.Hour)
+	ended("finished", 100, end, 4*time.Hour)
+	ended("finished", 100, end, 4*time.Hour)
+	history, _ = GetZFSScrubHistory(db, 1, 10)
+	if len(history) != 3 {
+		t.Fatalf("expected 3 scrub records, got %d", len(history))
+	}
+	if !history[0].StartTime.Equal(end.Add(-4 * time.Hour)) {
+		t.Errorf("expected the start derived from the duration, got %+v", history[0])
+	}
+}
+
+func TestZFSSummaryWithoutPools(t *testing.T) {
+	db := setupZFSTestDB(t)
+
+	global, err := GetGlobalZFSSummary(db)
+	if err != nil {
+		t.Fatalf("GetGlobalZFSSummary: %v", err)
+	}
+	if global.TotalPools != 0 || global.HealthyPools != 0 || global.ActiveScrubs != 0 {
+		t.Errorf("expected an all-zero summary, got %+v", global)
+	}
+
+	if _, err := GetZFSPoolSummary(db, "nas01"); err != nil {
+		t.Fatalf("GetZFSPoolSummary: %v", err)
+	}
+	if _, err := GetZFSGlobalStats(db); err != nil {
+		t.Fatalf("GetZFSGlobalStats: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package zfs
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"vigil/internal/events"
+)
+
+// Hot spare statuses, from the state zpool status shows in the spares
+// section
+const (
+	SpareAvailable   = "available"   // AVAIL: ready to take over
+	SpareInUse       = "in_use"      // INUSE: standing in for a failed disk
+	SpareUnavailable = "unavailable" // FAULTED, REMOVED, UNAVAIL, …: can't take over
+	SpareUnknown     = "unknown"     // agents before spare states were parsed report ONLINE
+)
+
+// sparesVdev is the name zpool status gives the section listing a pool's
+// hot spares
+const sparesVdev = "spares"
+
+// ZFSSpare is a hot spare of a pool
+type ZFSSpare struct {
+	Hostname     string    `json:"hostname"`
+	PoolName     string    `json:"pool_name"`
+	DeviceName   string    `json:"device_name"`
+	DevicePath   string    `json:"device_path,omitempty"`
+	SerialNumber string    `json:"serial_number,omitempty"`
+	State        string    `json:"state"`  // as zpool status shows it
+	Status       string    `json:"status"` // available, in_use, unavailable or unknown
+	LastSeen     time.Time `json:"last_seen"`
+}
+
+// SpareStatus maps a spare's zpool state to one of the Spare* statuses
+func SpareStatus(state string) string {
+	switch state {
+	case "AVAIL":
+		return SpareAvailable
+	case "INUSE":
+		return SpareInUse
+	case "ONLINE", "":
+		return SpareUnknown
+	default:
+		return SpareUnavailable
+	}
+}
+
+// GetAllZFSSpares returns the hot spares of every pool, by host and pool
+func GetAllZFSSpares(db *sql.DB) ([]ZFSSpare, error) {
+	rows, err := db.Query(`
+		SELECT hostname, pool_name, device_name, COALESCE(device_path, ''),
+		       COALESCE(serial_number, ''), state, last_seen
+		FROM zfs_pool_devices
+		WHERE vdev_parent = ?
+		ORDER BY hostname, pool_name, vdev_index`, sparesVdev)
+	if err != nil {
+		return nil, fmt.Errorf("query spares: %w", err)
+	}
+	defer rows.Close()
+
+	spares := []ZFSSpare{}
+	for rows.Next() {
+		var s ZFSSpare
+		if err := rows.Scan(&s.Hostname, &s.PoolName, &s.DeviceName, &s.DevicePath,
+			&s.SerialNumber, &s.State, &s.LastSeen); err != nil {
+			return nil, err
+		}
+		s.Status = SpareStatus(s.State)
+		spares = append(spares, s)
+	}
+	return spares, rows.Err()
+}
+
+// spareStates maps each spare of a pool's stored devices to its zpool state
+func spareStates(devices []ZFSPoolDevice) map[string]string {
+	states := make(map[string]string)
+	for _, d := range devices {
+		if d.VdevParent == sparesVdev {
+			states[d.DeviceName] = d.State
+		}
+	}
+	return states
+}
+
+// agentSpares returns the devices of a report's spares section
+func agentSpares(devices []ZFSAgentDevice) []ZFSAgentDevice {
+	for _, d := range devices {
+		if d.Name == sparesVdev {
+			return d.Children
+		}
+	}
+	return nil
+}
+
+// publishSpareEvents warns when a hot spare went into use since the last
+// report: a disk failed and the spare took its place, leaving the pool one
+// spare short. prevStates holds the pool's spares' states before this
+// report; a spare already in use when first seen is reported too.
+func publishSpareEvents(bus *events.Bus, hostname string, pool ZFSAgentPool, prevStates map[string]string) {
+	for _, spare := range agentSpares(pool.Devices) {
+		if spare.State != "INUSE" || prevStates[spare.Name] == "INUSE" {
+			continue
+		}
+		bus.Publish(events.Event{
+			Type:         events.ZFSSpareActivated,
+			Severity:     events.SeverityWarning,
+			Hostname:     hostname,
+			SerialNumber: spare.SerialNumber,
+			Message:      fmt.Sprintf("Hot spare %q in ZFS pool %q was activated: a disk failed and the spare is standing in for it", spare.Name, pool.Name),
+			Metadata: map[string]string{
+				"pool_name":   pool.Name,
+				"device_name": spare.Name,
+			},
+		})
+	}
+}
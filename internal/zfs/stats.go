@@ -14,14 +14,14 @@ func GetZFSPoolSummary(db *sql.DB, hostname string) (*ZFSPoolSummary, error) {
 	err := db.QueryRow(`
 		SELECT
 			COUNT(*) as total_pools,
-			SUM(CASE WHEN health = 'ONLINE' THEN 1 ELSE 0 END) as healthy,
-			SUM(CASE WHEN health = 'DEGRADED' THEN 1 ELSE 0 END) as degraded,
-			SUM(CASE WHEN health = 'FAULTED' THEN 1 ELSE 0 END) as faulted,
+			COALESCE(SUM(CASE WHEN health = 'ONLINE' THEN 1 ELSE 0 END), 0) as healthy,
+			COALESCE(SUM(CASE WHEN health = 'DEGRADED' THEN 1 ELSE 0 END), 0) as degraded,
+			COALESCE(SUM(CASE WHEN health = 'FAULTED' THEN 1 ELSE 0 END), 0) as faulted,
 			COALESCE(SUM(size_bytes), 0) as total_size,
 			COALESCE(SUM(allocated_bytes), 0) as total_used,
 			COALESCE(SUM(free_bytes), 0) as total_free,
 			COALESCE(SUM(read_errors + write_errors + checksum_errors), 0) as total_errors,
-			SUM(CASE WHEN scan_state = 'scanning' THEN 1 ELSE 0 END) as active_scrubs
+			COALESCE(SUM(CASE WHEN scan_state = 'scanning' THEN 1 ELSE 0 END), 0) as active_scrubs
 		FROM zfs_pools
 		WHERE hostname = ?
 	`, hostname).Scan(
@@ -40,6 +40,9 @@ func GetZFSPoolSummary(db *sql.DB, hostname string) (*ZFSPoolSummary, error) {
 		return nil, fmt.Errorf("get ZFS pool summary: %w", err)
 	}
 
+	summary.ActiveTrims = countActiveOperations(db, "trim", hostname)
+	summary.ActiveInits = countActiveOperations(db, "initialize", hostname)
+
 	return summary, nil
 }
 
@@ -50,14 +53,14 @@ func GetGlobalZFSSummary(db *sql.DB) (*ZFSPoolSummary, error) {
 	err := db.QueryRow(`
 		SELECT
 			COUNT(*) as total_pools,
-			SUM(CASE WHEN health = 'ONLINE' THEN 1 ELSE 0 END) as healthy,
-			SUM(CASE WHEN health = 'DEGRADED' THEN 1 ELSE 0 END) as degraded,
-			SUM(CASE WHEN health = 'FAULTED' THEN 1 ELSE 0 END) as faulted,
+			COALESCE(SUM(CASE WHEN health = 'ONLINE' THEN 1 ELSE 0 END), 0) as healthy,
+			COALESCE(SUM(CASE WHEN health = 'DEGRADED' THEN 1 ELSE 0 END), 0) as degraded,
+			COALESCE(SUM(CASE WHEN health = 'FAULTED' THEN 1 ELSE 0 END), 0) as faulted,
 			COALESCE(SUM(size_bytes), 0) as total_size,
 			COALESCE(SUM(allocated_bytes), 0) as total_used,
 			COALESCE(SUM(free_bytes), 0) as total_free,
 			COALESCE(SUM(read_errors + write_errors + checksum_errors), 0) as total_errors,
-			SUM(CASE WHEN scan_state = 'scanning' THEN 1 ELSE 0 END) as active_scrubs
+			COALESCE(SUM(CASE WHEN scan_state = 'scanning' THEN 1 ELSE 0 END), 0) as active_scrubs
 		FROM zfs_pools
 	`).Scan(
 		&summary.TotalPools,
@@ -75,6 +78,9 @@ func GetGlobalZFSSummary(db *sql.DB) (*ZFSPoolSummary, error) {
 		return nil, fmt.Errorf("get global ZFS summary: %w", err)
 	}
 
+	summary.ActiveTrims = countActiveOperations(db, "trim", "")
+	summary.ActiveInits = countActiveOperations(db, "initialize", "")
+
 	return summary, nil
 }
 
@@ -85,11 +91,11 @@ func GetZFSGlobalStats(db *sql.DB) (*ZFSGlobalStats, error) {
 	err := db.QueryRow(`
 		SELECT
 			COUNT(*) as total_pools,
-			SUM(CASE WHEN health = 'ONLINE' THEN 1 ELSE 0 END) as healthy,
-			SUM(CASE WHEN health = 'DEGRADED' THEN 1 ELSE 0 END) as degraded,
-			SUM(CASE WHEN health = 'FAULTED' THEN 1 ELSE 0 END) as faulted,
+			COALESCE(SUM(CASE WHEN health = 'ONLINE' THEN 1 ELSE 0 END), 0) as healthy,
+			COALESCE(SUM(CASE WHEN health = 'DEGRADED' THEN 1 ELSE 0 END), 0) as degraded,
+			COALESCE(SUM(CASE WHEN health = 'FAULTED' THEN 1 ELSE 0 END), 0) as faulted,
 			COALESCE(SUM(read_errors + write_errors + checksum_errors), 0) as total_errors,
-			SUM(CASE WHEN scan_state = 'scanning' THEN 1 ELSE 0 END) as active_scrubs
+			COALESCE(SUM(CASE WHEN scan_state = 'scanning' THEN 1 ELSE 0 END), 0) as active_scrubs
 		FROM zfs_pools
 	`).Scan(
 		&stats.TotalPools,
@@ -107,6 +113,9 @@ func GetZFSGlobalStats(db *sql.DB) (*ZFSGlobalStats, error) {
 	// Get device count
 	db.QueryRow("SELECT COUNT(*) FROM zfs_pool_devices").Scan(&stats.TotalDevices)
 
+	stats.ActiveTrims = countActiveOperations(db, "trim", "")
+	stats.ActiveInits = countActiveOperations(db, "initialize", "")
+
 	return stats, nil
 }
 
@@ -119,7 +128,7 @@ func GetZFSPoolListItems(db *sql.DB) ([]ZFSPoolListItem, error) {
 			p.read_errors, p.write_errors, p.checksum_errors,
 			p.scan_function, p.scan_state, p.scan_progress, p.scan_speed, p.scan_errors, p.scan_time_remaining,
 			(SELECT COUNT(*) FROM zfs_pool_devices d WHERE d.pool_id = p.id) as device_count,
-			(SELECT MAX(start_time) FROM zfs_scrub_history s WHERE s.pool_id = p.id) as last_scrub
+			(SELECT MAX(start_time) FROM zfs_scrub_history s WHERE s.pool_id = p.id AND s.scan_type IN ('scrub', 'resilver')) as last_scrub
 		FROM zfs_pools p
 		ORDER BY p.hostname, p.pool_name
 	`)
@@ -181,11 +190,12 @@ func GetPoolsNeedingScrub(db *sql.DB, daysSinceLastScrub int) ([]ZFSPoolListItem
 			p.read_errors, p.write_errors, p.checksum_errors,
 			p.scan_function, p.scan_state, p.scan_progress, p.scan_speed, p.scan_errors, p.scan_time_remaining,
 			(SELECT COUNT(*) FROM zfs_pool_devices d WHERE d.pool_id = p.id) as device_count,
-			(SELECT MAX(start_time) FROM zfs_scrub_history s WHERE s.pool_id = p.id) as last_scrub
+			(SELECT MAX(start_time) FROM zfs_scrub_history s WHERE s.pool_id = p.id AND s.scan_type IN ('scrub', 'resilver')) as last_scrub
 		FROM zfs_pools p
 		WHERE NOT EXISTS (
 			SELECT 1 FROM zfs_scrub_history s
 			WHERE s.pool_id = p.id
+			AND s.scan_type IN ('scrub', 'resilver')
 			AND s.start_time > datetime('now', ?)
 		)
 		ORDER BY p.hostname, p.pool_name
@@ -220,3 +230,21 @@ func GetPoolsNeedingScrub(db *sql.DB, daysSinceLastScrub int) ([]ZFSPoolListItem
 
 	return items, nil
 }
+
+// countActiveOperations counts pools whose latest trim or initialize record is
+// still in progress. These operations aren't reflected in the pool's scan_*
+// columns, so they're derived from scrub history. An empty hostname counts
+// across every host.
+func countActiveOperations(db *sql.DB, scanType, hostname string) int {
+	var count int
+	db.QueryRow(`
+		SELECT COUNT(*) FROM zfs_scrub_history h
+		WHERE h.scan_type = ? AND h.state = 'scanning'
+		AND (? = '' OR h.hostname = ?)
+		AND h.id = (
+			SELECT MAX(id) FROM zfs_scrub_history x
+			WHERE x.pool_id = h.pool_id AND x.scan_type = h.scan_type
+		)
+	`, scanType, hostname, hostname).Scan(&count)
+	return count
+}
@@ -71,7 +71,7 @@ type ZFSScrubHistory struct {
 	PoolID          int64     `json:"pool_id"`
 	Hostname        string    `json:"hostname"`
 	PoolName        string    `json:"pool_name"`
-	ScanType        string    `json:"scan_type"`
+	ScanType        string    `json:"scan_type"` // scrub, resilver, trim, initialize
 	State           string    `json:"state"`
 	StartTime       time.Time `json:"start_time"`
 	EndTime         time.Time `json:"end_time,omitempty"`
@@ -120,6 +120,8 @@ type ZFSPoolSummary struct {
 	TotalFreeBytes int64  `json:"total_free_bytes"`
 	TotalErrors    int64  `json:"total_errors"`
 	ActiveScrubs   int    `json:"active_scrubs"`
+	ActiveTrims    int    `json:"active_trims"`
+	ActiveInits    int    `json:"active_initializes"`
 }
 
 // ZFSGlobalStats provides system-wide ZFS statistics
@@ -131,6 +133,8 @@ type ZFSGlobalStats struct {
 	TotalDevices  int   `json:"total_devices"`
 	TotalErrors   int64 `json:"total_errors"`
 	ActiveScrubs  int   `json:"active_scrubs"`
+	ActiveTrims   int   `json:"active_trims"`
+	ActiveInits   int   `json:"active_initializes"`
 }
 
 // ─── ZFS API Response Types ──────────────────────────────────────────────────
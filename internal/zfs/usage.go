@@ -0,0 +1,179 @@
+package zfs
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"vigil/internal/events"
+	"vigil/internal/settings"
+)
+
+// poolUsageRetentionDays bounds zfs_pool_usage, and so the longest
+// projection window.
+const poolUsageRetentionDays = 90
+
+// PoolUsageSample is a pool's allocated space at one point in time
+type PoolUsageSample struct {
+	AllocatedBytes int64     `json:"allocated_bytes"`
+	SizeBytes      int64     `json:"size_bytes"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// FillProjection extrapolates a pool's recent growth to when it is full
+type FillProjection struct {
+	BytesPerDay   float64    `json:"bytes_per_day"`
+	DaysUntilFull *float64   `json:"days_until_full,omitempty"` // nil when usage isn't growing
+	FullAt        *time.Time `json:"full_at,omitempty"`
+	Samples       int        `json:"samples"`
+	SpanDays      float64    `json:"span_days"`
+}
+
+// RecordPoolUsage stores the pool's allocated space in the current hour's
+// sample (a later report in the same hour replaces it) and drops samples
+// past poolUsageRetentionDays.
+func RecordPoolUsage(db *sql.DB, poolID int64, hostname, poolName string, allocated, size int64, at time.Time) error {
+	if size <= 0 {
+		return nil
+	}
+	_, err := db.Exec(`
+		INSERT INTO zfs_pool_usage (pool_id, hostname, pool_name, allocated_bytes, size_bytes, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(pool_id, timestamp) DO UPDATE SET
+			allocated_bytes = excluded.allocated_bytes,
+			size_bytes      = excluded.size_bytes
+	`, poolID, hostname, poolName, allocated, size, at.UTC().Truncate(time.Hour).Format(timeFormat))
+	if err != nil {
+		return fmt.Errorf("record pool usage: %w", err)
+	}
+
+	cutoff := at.UTC().AddDate(0, 0, -poolUsageRetentionDays).Format(timeFormat)
+	_, err = db.Exec(`DELETE FROM zfs_pool_usage WHERE pool_id = ? AND timestamp < ?`, poolID, cutoff)
+	return err
+}
+
+// GetPoolUsageHistory returns a pool's usage samples since the given time,
+// oldest first.
+func GetPoolUsageHistory(db *sql.DB, poolID int64, since time.Time) ([]PoolUsageSample, error) {
+	rows, err := db.Query(`
+		SELECT allocated_bytes, size_bytes, timestamp
+		FROM zfs_pool_usage
+		WHERE pool_id = ? AND timestamp >= ?
+		ORDER BY timestamp
+	`, poolID, since.UTC().Format(timeFormat))
+	if err != nil {
+		return nil, fmt.Errorf("query pool usage: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []PoolUsageSample
+	for rows.Next() {
+		var s PoolUsageSample
+		var ts sql.NullString
+		if err := rows.Scan(&s.AllocatedBytes, &s.SizeBytes, &ts); err != nil {
+			return nil, err
+		}
+		s.Timestamp = parseNullTime(ts)
+		samples = append(samples, s)
+	}
+	return samples, rows.Err()
+}
+
+// PoolFillProjection fits the pool's usage over the last
+// zfs.fill_projection_days. Returns nil without enough history.
+func PoolFillProjection(db *sql.DB, poolID int64) *FillProjection {
+	days := settings.GetInt(db, "zfs", "fill_projection_days", 14)
+	if days <= 0 || days > poolUsageRetentionDays {
+		days = poolUsageRetentionDays
+	}
+	samples, err := GetPoolUsageHistory(db, poolID, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil
+	}
+	return ProjectFill(samples)
+}
+
+// ProjectFill fits a line through allocated bytes over time (least squares)
+// and projects when it reaches the pool's size. Returns nil for fewer than
+// three samples or less than a day of history, too little to tell growth
+// from noise.
+func ProjectFill(samples []PoolUsageSample) *FillProjection {
+	if len(samples) < 3 {
+		return nil
+	}
+	first := samples[0].Timestamp
+	last := samples[len(samples)-1]
+	span := last.Timestamp.Sub(first).Hours() / 24
+	if span < 1 {
+		return nil
+	}
+
+	xs := make([]float64, len(samples))
+	ys := make([]float64, len(samples))
+	for i, s := range samples {
+		xs[i] = s.Timestamp.Sub(first).Hours() / 24
+		ys[i] = float64(s.AllocatedBytes)
+	}
+
+	p := &FillProjection{
+		BytesPerDay: fitSlope(xs, ys),
+		Samples:     len(samples),
+		SpanDays:    span,
+	}
+	if p.BytesPerDay > 0 {
+		remaining := float64(last.SizeBytes - last.AllocatedBytes)
+		if remaining < 0 {
+			remaining = 0
+		}
+		days := remaining / p.BytesPerDay
+		fullAt := last.Timestamp.Add(time.Duration(days * 24 * float64(time.Hour)))
+		p.DaysUntilFull = &days
+		p.FullAt = &fullAt
+	}
+	return p
+}
+
+// fitSlope returns the least-squares slope of ys over xs
+func fitSlope(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	var sumX, sumY, sumXY, sumX2 float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+		sumXY += xs[i] * ys[i]
+		sumX2 += xs[i] * xs[i]
+	}
+	denom := n*sumX2 - sumX*sumX
+	if math.Abs(denom) < 1e-10 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denom
+}
+
+// publishFillEvents warns when the pool is projected to be full within
+// zfs.fill_warning_days at its current rate of growth.
+func publishFillEvents(bus *events.Bus, db *sql.DB, hostname string, pool ZFSAgentPool, poolID int64) {
+	threshold := settings.GetInt(db, "zfs", "fill_warning_days", 30)
+	if threshold <= 0 {
+		return
+	}
+	p := PoolFillProjection(db, poolID)
+	if p == nil || p.DaysUntilFull == nil || *p.DaysUntilFull >= float64(threshold) {
+		return
+	}
+
+	bus.Publish(events.Event{
+		Type:     events.ZFSFillPredicted,
+		Severity: events.SeverityWarning,
+		Hostname: hostname,
+		Message: fmt.Sprintf("ZFS pool %q will be full in ~%.0f days at the current growth (%s/day)",
+			pool.Name, math.Ceil(*p.DaysUntilFull), FormatBytes(int64(p.BytesPerDay))),
+		Metadata: map[string]string{
+			"pool_name":       pool.Name,
+			"days_until_full": fmt.Sprintf("%.1f", *p.DaysUntilFull),
+			"bytes_per_day":   fmt.Sprintf("%.0f", p.BytesPerDay),
+			"threshold_days":  fmt.Sprintf("%d", threshold),
+		},
+	})
+}
@@ -0,0 +1,90 @@
+package zfs
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"vigil/internal/events"
+)
+
+const tib = int64(1) << 40
+
+func TestProjectFill(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var samples []PoolUsageSample
+	for day := 0; day <= 10; day++ {
+		samples = append(samples, PoolUsageSample{
+			AllocatedBytes: 2*tib + int64(day)*(tib/20), // +51.2 GiB/day
+			SizeBytes:      4 * tib,
+			Timestamp:      start.AddDate(0, 0, day),
+		})
+	}
+
+	p := ProjectFill(samples)
+	if p == nil || p.DaysUntilFull == nil {
+		t.Fatalf("expected a projection, got %+v", p)
+	}
+	// 1.5 TiB left at 1/20 TiB per day
+	if math.Abs(*p.DaysUntilFull-30) > 0.01 {
+		t.Errorf("expected ~30 days until full, got %.2f", *p.DaysUntilFull)
+	}
+	if want := start.AddDate(0, 0, 40); p.FullAt.Sub(want).Abs() > time.Minute {
+		t.Errorf("expected full at %v, got %v", want, p.FullAt)
+	}
+
+	if ProjectFill(samples[:2]) != nil {
+		t.Error("expected no projection from two samples")
+	}
+
+	flat := []PoolUsageSample{
+		{AllocatedBytes: tib, SizeBytes: 4 * tib, Timestamp: start},
+		{AllocatedBytes: tib, SizeBytes: 4 * tib, Timestamp: start.AddDate(0, 0, 1)},
+		{AllocatedBytes: tib, SizeBytes: 4 * tib, Timestamp: start.AddDate(0, 0, 2)},
+	}
+	if p := ProjectFill(flat); p == nil || p.DaysUntilFull != nil {
+		t.Errorf("expected a projection without days until full for flat usage, got %+v", p)
+	}
+}
+
+func TestPublishFillEvents(t *testing.T) {
+	db := setupZFSTestDB(t)
+	bus := events.NewBus()
+	var received []events.Event
+	bus.Subscribe(func(e events.Event) { received = append(received, e) })
+
+	pool := ZFSAgentPool{Name: "tank"}
+	now := time.Now().UTC()
+	record := func(daysAgo int, allocated int64) {
+		if err := RecordPoolUsage(db, 1, "nas01", "tank", allocated, 4*tib, now.AddDate(0, 0, -daysAgo)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Slow growth: 1/100 TiB per day leaves ~150 days
+	for d := 5; d >= 0; d-- {
+		record(d, 2*tib+int64(5-d)*(tib/100))
+	}
+	publishFillEvents(bus, db, "nas01", pool, 1)
+	if len(received) != 0 {
+		t.Fatalf("expected no event for a pool months from full, got %+v", received)
+	}
+
+	// Same hour replaces the sample rather than adding one
+	record(0, 2*tib+5*(tib/100))
+	if history, _ := GetPoolUsageHistory(db, 1, now.AddDate(0, 0, -10)); len(history) != 6 {
+		t.Errorf("expected 6 hourly samples, got %d", len(history))
+	}
+
+	// Fast growth: 1/4 TiB per day fills the rest in days
+	for d := 5; d >= 0; d-- {
+		record(d, 2*tib+int64(5-d)*(tib/4))
+	}
+	publishFillEvents(bus, db, "nas01", pool, 1)
+	if len(received) != 1 || received[0].Type != events.ZFSFillPredicted {
+		t.Fatalf("expected one fill warning, got %+v", received)
+	}
+	if received[0].Metadata["threshold_days"] != "30" {
+		t.Errorf("expected the default 30 day threshold, got %q", received[0].Metadata["threshold_days"])
+	}
+}
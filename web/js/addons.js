@@ -256,7 +256,7 @@ const Addons = {
         const truncated = token.token; // Already masked by the server (first 16 chars)
         const now = new Date();
         const isUsed = !!token.used_at;
-        const isExpired = token.expires_at ? new Date(token.expires_at + 'Z') < now : false;
+        const isExpired = token.expires_at ? Utils.parseUTC(token.expires_at) < now : false;
 
         let badgeClass = 'available';
         let badgeLabel = 'Available';
@@ -67,8 +67,15 @@ const Agents = {
         const lastSeen = lastActivity ? Utils.timeAgo(lastActivity) : null;
         const lastDate = lastActivity ? Utils.parseUTC(lastActivity) : null;
         const isOnline = lastDate && (Date.now() - lastDate.getTime()) < State.offlineThresholdMinutes() * 60 * 1000;
-        const statusClass = isOnline ? 'online' : 'not-reporting';
-        const statusLabel = isOnline ? 'Online' : 'Not Reporting';
+        let statusClass = isOnline ? 'online' : 'not-reporting';
+        let statusLabel = isOnline ? 'Online' : 'Not Reporting';
+        if (agent.approval === 'pending') {
+            statusClass = 'pending';
+            statusLabel = 'Pending Approval';
+        } else if (agent.approval === 'rejected') {
+            statusClass = 'rejected';
+            statusLabel = 'Rejected';
+        }
         const fp = agent.fingerprint ? agent.fingerprint.substring(0, 16) + '...' : '';
         const displayName = agent.name || agent.hostname;
         const showHostname = agent.name && agent.name !== agent.hostname;
@@ -84,7 +91,11 @@ const Agents = {
         }
 
         let statusHint = '';
-        if (!isOnline) {
+        if (agent.approval === 'pending') {
+            statusHint = 'New agent awaiting approval. Its reports are refused until you approve it; check the fingerprint matches the machine you enrolled.';
+        } else if (agent.approval === 'rejected') {
+            statusHint = 'Agent was rejected and is disabled. Approve it to let it report again, or remove it.';
+        } else if (!isOnline) {
             statusHint = lastActivity
                 ? 'Agent has not sent data in over 5 minutes. Check if the agent service is running or re-register it.'
                 : 'Agent was registered but has never connected. Verify the agent service is running on this system.';
@@ -117,6 +128,10 @@ const Agents = {
                             <span class="agent-status ${statusClass}">${statusLabel}</span>
                             ${statusHint ? `<span class="agent-status-tooltip">${statusHint}</span>` : ''}
                         </span>
+                        ${agent.approval === 'pending' || agent.approval === 'rejected' ? `
+                        <button class="btn-agent-approve" onclick="Agents.approveAgent(${agent.id}, '${Utils.escapeJSString(agent.hostname)}')" title="Approve agent">Approve</button>` : ''}
+                        ${agent.approval === 'pending' ? `
+                        <button class="btn-agent-reject" onclick="Agents.rejectAgent(${agent.id}, '${Utils.escapeJSString(agent.hostname)}')" title="Reject agent">Reject</button>` : ''}
                         <button class="btn-agent-delete" onclick="Agents.deleteAgent(${agent.id}, '${Utils.escapeHtml(agent.hostname)}')" title="Remove agent">
                             <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2">
                                 <polyline points="3 6 5 6 21 6"/>
@@ -134,7 +149,7 @@ const Agents = {
         const now = new Date();
         const isUsed = !!token.used_at;
         const isExpired = token.expires_at
-            ? new Date(token.expires_at + 'Z') < now  // stored as UTC, append Z so JS parses as UTC
+            ? Utils.parseUTC(token.expires_at) < now
             : false;  // null expires_at = never expires
 
         let badgeClass = 'available';
@@ -183,6 +198,29 @@ const Agents = {
         }
     },
 
+    async approveAgent(id, hostname) {
+        try {
+            const resp = await API.approveAgent(id);
+            if (!resp.ok) throw new Error();
+            Utils.toast(`Agent "${hostname}" approved`, 'success');
+            this.render();
+        } catch (e) {
+            Utils.toast('Failed to approve agent', 'error');
+        }
+    },
+
+    async rejectAgent(id, hostname) {
+        if (!await Utils.confirm(`Reject agent "${hostname}"? It will be disabled and its reports refused.`)) return;
+        try {
+            const resp = await API.rejectAgent(id);
+            if (!resp.ok) throw new Error();
+            Utils.toast(`Agent "${hostname}" rejected`, 'info');
+            this.render();
+        } catch (e) {
+            Utils.toast('Failed to reject agent', 'error');
+        }
+    },
+
     async deleteToken(id) {
         if (!await Utils.confirm('Delete this registration token?')) return;
         try {
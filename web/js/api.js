@@ -174,6 +174,14 @@ const API = {
         return this.delete(`/api/v1/agents/${id}`);
     },
 
+    async approveAgent(id) {
+        return this.post(`/api/v1/agents/${id}/approve`, {});
+    },
+
+    async rejectAgent(id) {
+        return this.post(`/api/v1/agents/${id}/reject`, {});
+    },
+
     async createRegistrationToken(name) {
         return this.post('/api/v1/tokens', { name });
     },
@@ -274,22 +282,35 @@ const API = {
         return this.put(`/api/notifications/services/${serviceId}/digest`, digest);
     },
 
-    async testFireNotification(serviceId, message) {
-        return this.post('/api/notifications/test', { service_id: serviceId, message });
+    async updateSummaryConfig(serviceId, summary) {
+        return this.put(`/api/notifications/services/${serviceId}/summary`, summary);
+    },
+
+    async testFireNotification(serviceId, message, validateOnly = false) {
+        return this.post('/api/notifications/test', { service_id: serviceId, message, validate_only: validateOnly });
     },
 
-    async testNotificationURL(url, message) {
-        return this.post('/api/notifications/test-url', { url, message });
+    async testNotificationURL(url, message, validateOnly = false) {
+        return this.post('/api/notifications/test-url', { url, message, validate_only: validateOnly });
     },
 
-    async testNotificationFields(serviceType, configFields, message) {
+    async testNotificationFields(serviceType, configFields, message, validateOnly = false) {
         return this.post('/api/notifications/test-url', {
             service_type: serviceType,
             config_fields: configFields,
-            message
+            message,
+            validate_only: validateOnly
         });
     },
 
+    async pauseNotifications(minutes) {
+        return this.post('/api/notifications/pause', minutes ? { minutes } : {});
+    },
+
+    async resumeNotifications() {
+        return this.post('/api/notifications/resume', {});
+    },
+
     async getNotificationHistory(limit = 50) {
         return this.get(`/api/notifications/history?limit=${limit}`);
     },
@@ -85,8 +85,8 @@ const DriveGroups = {
                 html = '<div class="group-member-item"><span class="member-drive" style="opacity:0.6">No drives assigned</span></div>';
             } else {
                 html = members.map(m => {
-                    const alias = this._driveAlias(m.hostname, m.serial_number);
-                    const display = alias ? `${m.hostname} / ${alias} (${m.serial_number})` : `${m.hostname} / ${m.serial_number}`;
+                    const name = this._driveName(m.hostname, m.serial_number);
+                    const display = name && name !== m.serial_number ? `${m.hostname} / ${name} (${m.serial_number})` : `${m.hostname} / ${m.serial_number}`;
                     return `
                     <div class="group-member-item">
                         <span class="member-drive">${Utils.escapeHtml(display)}</span>
@@ -111,9 +111,7 @@ const DriveGroups = {
             (server.details?.drives || []).forEach(d => {
                 const serial = d.serial_number || '';
                 if (serial && !assigned.has(`${server.hostname}:${serial}`)) {
-                    const alias = d._alias || '';
-                    const model = d.model_name || d.device?.name || serial;
-                    const name = alias || model;
+                    const name = Utils.getDriveName(d);
                     drives.push({ hostname: server.hostname, serial, label: `${server.hostname} / ${name} (${serial})` });
                 }
             });
@@ -226,12 +224,12 @@ const DriveGroups = {
         }
     },
 
-    _driveAlias(hostname, serial) {
+    _driveName(hostname, serial) {
         for (const server of (State.data || [])) {
             if (server.hostname !== hostname) continue;
             for (const d of (server.details?.drives || [])) {
                 if (d.serial_number === serial) {
-                    return d._alias || '';
+                    return d._display_name || d._alias || '';
                 }
             }
         }
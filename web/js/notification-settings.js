@@ -12,6 +12,7 @@ const NotificationSettings = {
     eventRules: [],
     quietHours: null,
     digest: null,
+    summary: null,
     providerDefs: null,
     eventTypeMeta: null,
 
@@ -64,6 +65,7 @@ const NotificationSettings = {
         return `
             <div class="notif-header">
                 <h2>Notification Services</h2>
+                ${this._pauseButton()}
                 <button class="btn-add-agent" onclick="NotificationSettings.showAddService()">
                     <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2">
                         <line x1="12" y1="5" x2="12" y2="19"/>
@@ -72,6 +74,7 @@ const NotificationSettings = {
                     Add Service
                 </button>
             </div>
+            ${this._pauseBanner()}
             <div class="notif-layout">
                 <div class="notif-sidebar">
                     ${this._serviceList()}
@@ -91,6 +94,49 @@ const NotificationSettings = {
         `;
     },
 
+    // The global pause is the same on every service; the listing carries it.
+    _globalPause() {
+        return this.services.length > 0 ? this.services[0].global_pause : null;
+    },
+
+    _pauseButton() {
+        if (this.services.length === 0) return '';
+        const pause = this._globalPause();
+        return pause && pause.paused
+            ? '<button class="btn btn-secondary" onclick="NotificationSettings.resumeAll()">Resume All</button>'
+            : '<button class="btn btn-secondary" onclick="NotificationSettings.pauseAll()">Pause All</button>';
+    },
+
+    _pauseBanner() {
+        const pause = this._globalPause();
+        if (!pause || !pause.paused) return '';
+        const until = pause.until ? `until ${new Date(pause.until).toLocaleString()}` : 'until resumed';
+        return `<div class="notif-pause-banner">All notifications are paused ${until}. Skipped notifications are kept in history.</div>`;
+    },
+
+    async pauseAll() {
+        const input = prompt('Pause all notifications for how many minutes? Leave empty to pause until resumed.', '');
+        if (input === null) return;
+        const minutes = parseInt(input, 10);
+        if (input.trim() !== '' && !(minutes > 0)) {
+            Utils.toast('Enter a number of minutes, or leave it empty', 'error');
+            return;
+        }
+        try {
+            const resp = await API.pauseNotifications(minutes || 0);
+            if (resp.ok) this.render();
+            else Utils.toast('Failed to pause notifications', 'error');
+        } catch { Utils.toast('Connection error', 'error'); }
+    },
+
+    async resumeAll() {
+        try {
+            const resp = await API.resumeNotifications();
+            if (resp.ok) this.render();
+            else Utils.toast('Failed to resume notifications', 'error');
+        } catch { Utils.toast('Connection error', 'error'); }
+    },
+
     _serviceList() {
         if (this.services.length === 0) return '';
 
@@ -104,6 +150,7 @@ const NotificationSettings = {
                         <span class="notif-service-badge ${s.enabled ? 'enabled' : 'disabled'}">
                             ${s.enabled ? 'Active' : 'Disabled'}
                         </span>
+                        ${s.muted ? `<span class="notif-service-badge muted" title="Muted until ${new Date(s.muted_until).toLocaleString()}">Muted</span>` : ''}
                     </div>
                     <button class="notif-service-test-btn"
                             title="Send test notification"
@@ -166,6 +213,7 @@ const NotificationSettings = {
                 this.eventRules = data.event_rules || [];
                 this.quietHours = data.quiet_hours || { enabled: false, start_time: '22:00', end_time: '07:00' };
                 this.digest = data.digest || { enabled: false, send_at: '08:00' };
+                this.summary = data.summary || { enabled: false, send_at: '08:00' };
             }
         } catch (e) {
             console.error('Failed to load service:', e);
@@ -235,6 +283,11 @@ const NotificationSettings = {
                                 onchange="NotificationSettings.updateGeneral(${s.id})">
                             Healthy / Recovered
                         </label>
+                        <label class="addon-checkbox" title="Record messages in history as 'would send' without delivering them">
+                            <input type="checkbox" id="notif-dry-run" ${s.dry_run ? 'checked' : ''}
+                                onchange="NotificationSettings.updateGeneral(${s.id})">
+                            Dry run
+                        </label>
                     </div>
                 </div>
 
@@ -261,6 +314,11 @@ const NotificationSettings = {
                     ${this._digestForm(s.id)}
                 </div>
 
+                <div class="notif-section">
+                    <h4>Daily Summary</h4>
+                    ${this._summaryForm(s.id)}
+                </div>
+
                 <div class="notif-status" id="notif-status"></div>
             </div>
         `;
@@ -401,6 +459,25 @@ const NotificationSettings = {
         `;
     },
 
+    _summaryForm(serviceId) {
+        const d = this.summary;
+        return `
+            <div class="notif-form-row">
+                <label class="addon-checkbox">
+                    <input type="checkbox" id="summary-enabled" ${d.enabled ? 'checked' : ''}>
+                    Send "what changed" summary
+                </label>
+                <div class="notif-time-range">
+                    <label>Send at:</label>
+                    <input type="time" id="summary-time" class="form-input form-input-sm" value="${d.send_at || '08:00'}">
+                    <span class="form-hint">(UTC)</span>
+                </div>
+                <button class="btn btn-secondary btn-sm" onclick="NotificationSettings.saveSummary(${serviceId})">Save</button>
+            </div>
+            <span class="form-hint">New and missing drives, health changes, completed scans and top temperature movers over the last 24h.</span>
+        `;
+    },
+
     // ─── Actions ──────────────────────────────────────────────────────────
 
     async updateGeneral(id) {
@@ -414,7 +491,8 @@ const NotificationSettings = {
             enabled: document.getElementById('notif-enabled')?.checked ?? s.enabled,
             notify_on_critical: document.getElementById('notif-critical')?.checked ?? s.notify_on_critical,
             notify_on_warning: document.getElementById('notif-warning')?.checked ?? s.notify_on_warning,
-            notify_on_healthy: document.getElementById('notif-healthy')?.checked ?? s.notify_on_healthy
+            notify_on_healthy: document.getElementById('notif-healthy')?.checked ?? s.notify_on_healthy,
+            dry_run: document.getElementById('notif-dry-run')?.checked ?? s.dry_run
         };
 
         try {
@@ -496,6 +574,19 @@ const NotificationSettings = {
         } catch { this._showStatus('Connection error', true); }
     },
 
+    async saveSummary(serviceId) {
+        const body = {
+            enabled: document.getElementById('summary-enabled')?.checked ?? false,
+            send_at: document.getElementById('summary-time')?.value || '08:00'
+        };
+
+        try {
+            const resp = await API.updateSummaryConfig(serviceId, body);
+            if (resp.ok) this._showStatus('Summary config saved');
+            else this._showStatus('Failed to save', true);
+        } catch { this._showStatus('Connection error', true); }
+    },
+
     async testFire(serviceId) {
         this._showStatus('Sending test notification...');
         try {
@@ -571,6 +662,11 @@ const NotificationSettings = {
                     <div id="prov-error" class="form-error"></div>
                 </div>
                 <div class="modal-footer">
+                    <button class="btn btn-outline" id="prov-check-btn"
+                            title="Check the configuration and connection without sending a message"
+                            onclick="NotificationSettings._testProvider(true)">
+                        Check
+                    </button>
                     <button class="btn btn-outline" id="prov-test-btn"
                             onclick="NotificationSettings._testProvider()">
                         ${this._icons.zap} Test
@@ -721,26 +817,31 @@ const NotificationSettings = {
         }
     },
 
-    async _testProvider() {
+    async _testProvider(validateOnly = false) {
         const type = document.getElementById('prov-type')?.value;
         const fields = this._collectProviderFields();
         const statusEl = document.getElementById('prov-status');
         const errorEl = document.getElementById('prov-error');
-        const btn = document.getElementById('prov-test-btn');
+        const btn = document.getElementById(validateOnly ? 'prov-check-btn' : 'prov-test-btn');
+        const label = btn?.innerHTML;
 
         if (errorEl) errorEl.textContent = '';
-        if (btn) { btn.disabled = true; btn.innerHTML = 'Sending...'; }
+        if (btn) { btn.disabled = true; btn.innerHTML = validateOnly ? 'Checking...' : 'Sending...'; }
         if (statusEl) { statusEl.textContent = ''; statusEl.className = 'notif-test-status'; }
 
         try {
             const resp = await API.testNotificationFields(
                 type, fields,
-                'Vigil test notification \u2014 if you see this, it works!'
+                'Vigil test notification \u2014 if you see this, it works!',
+                validateOnly
             );
             const data = await resp.json().catch(() => ({}));
             if (statusEl) {
+                const ok = validateOnly
+                    ? (data.message || 'Configuration is valid')
+                    : 'Test sent successfully! Check your service.';
                 statusEl.textContent = data.success
-                    ? 'Test sent successfully! Check your service.'
+                    ? ok
                     : (data.error || 'Test failed \u2014 check your settings');
                 statusEl.className = `notif-test-status ${data.success ? 'success' : 'error'}`;
             }
@@ -753,7 +854,7 @@ const NotificationSettings = {
 
         if (btn) {
             btn.disabled = false;
-            btn.innerHTML = `${this._icons.zap} Test`;
+            btn.innerHTML = label;
         }
     },
 
@@ -811,6 +912,11 @@ const NotificationSettings = {
                     <div id="prov-error" class="form-error"></div>
                 </div>
                 <div class="modal-footer">
+                    <button class="btn btn-outline" id="prov-check-btn"
+                            title="Check the configuration and connection without sending a message"
+                            onclick="NotificationSettings._testProvider(true)">
+                        Check
+                    </button>
                     <button class="btn btn-outline" id="prov-test-btn"
                             onclick="NotificationSettings._testProvider()">
                         ${this._icons.zap} Test
@@ -844,7 +950,8 @@ const NotificationSettings = {
                 enabled: s?.enabled ?? true,
                 notify_on_critical: s?.notify_on_critical ?? true,
                 notify_on_warning: s?.notify_on_warning ?? true,
-                notify_on_healthy: s?.notify_on_healthy ?? false
+                notify_on_healthy: s?.notify_on_healthy ?? false,
+                dry_run: s?.dry_run ?? false
             });
 
             if (resp.ok) {
@@ -907,7 +1014,7 @@ const NotificationSettings = {
                             <td class="notif-msg">${Utils.escapeHtml(r.message)}</td>
                             <td>
                                 <span class="notif-status-badge ${r.status}">
-                                    ${r.status === 'sent' ? 'Sent' : r.status === 'failed' ? 'Failed' : Utils.escapeHtml(r.status)}
+                                    ${r.status === 'sent' ? 'Sent' : r.status === 'failed' ? 'Failed' : r.status === 'would_send' ? 'Would send' : r.status === 'muted' ? 'Muted' : r.status === 'maintenance' ? 'Host in maintenance' : r.status === 'paused' ? 'Paused globally' : Utils.escapeHtml(r.status)}
                                 </span>
                                 ${r.error_message ? `<span class="notif-error-hint" title="${Utils.escapeHtml(r.error_message)}">!</span>` : ''}
                             </td>
@@ -422,12 +422,11 @@ const Renderer = {
         const wearoutPct = wearoutRaw !== null ? Math.round(wearoutRaw * 10) / 10 : null;
         const smartPassed = drive.smart_status?.passed;
         const alias = drive._alias || '';
-        const displayName = alias || driveName;
 
         return `
             <tr class="drive-table-row ${status}" onclick="Navigation.showDriveDetails(${drive._serverIdx}, ${drive._driveIdx})">
                 <td><span class="drive-status-dot ${status}"></span></td>
-                <td class="drive-table-name" title="${Utils.escapeHtml(driveName)}">${Utils.escapeHtml(displayName)}</td>
+                <td class="drive-table-name" title="${Utils.escapeHtml(driveName)}">${Utils.escapeHtml(driveName)}</td>
                 <td class="drive-table-serial">${Utils.escapeHtml(serial)}</td>
                 <td class="drive-table-host">${Utils.escapeHtml(hostname)}</td>
                 <td>${Utils.formatSize(drive.user_capacity?.bytes)}</td>
@@ -667,8 +666,8 @@ const Renderer = {
                         </div>
                         <div class="settings-item">
                             <div class="settings-item-info">
-                                <div class="settings-item-title">SMART &amp; Temperature History</div>
-                                <div class="settings-item-desc">How long to keep SMART attribute and temperature history</div>
+                                <div class="settings-item-title">SMART History</div>
+                                <div class="settings-item-desc">How long to keep raw SMART attribute history</div>
                             </div>
                             <select class="settings-input" id="retention-smart-days"
                                 onchange="Settings.saveRetention('smart_data_days', this.value)">
@@ -677,6 +676,36 @@ const Renderer = {
                                 <option value="60">60 days</option>
                                 <option value="90">90 days</option>
                                 <option value="0">Forever</option>
+                                <option value="-1">System default</option>
+                            </select>
+                        </div>
+                        <div class="settings-item">
+                            <div class="settings-item-info">
+                                <div class="settings-item-title">Temperature History</div>
+                                <div class="settings-item-desc">How long to keep raw temperature history and spikes</div>
+                            </div>
+                            <select class="settings-input" id="retention-temperature-days"
+                                onchange="Settings.saveRetention('temperature_days', this.value)">
+                                <option value="30">30 days</option>
+                                <option value="90">90 days</option>
+                                <option value="365">1 year</option>
+                                <option value="730">2 years</option>
+                                <option value="0">Forever</option>
+                                <option value="-1">System default</option>
+                            </select>
+                        </div>
+                        <div class="settings-item">
+                            <div class="settings-item-info">
+                                <div class="settings-item-title">Temperature Alerts</div>
+                                <div class="settings-item-desc">How long to keep temperature alerts</div>
+                            </div>
+                            <select class="settings-input" id="retention-alert-days"
+                                onchange="Settings.saveRetention('alert_days', this.value)">
+                                <option value="30">30 days</option>
+                                <option value="90">90 days</option>
+                                <option value="365">1 year</option>
+                                <option value="0">Forever</option>
+                                <option value="-1">System default</option>
                             </select>
                         </div>
                         <div class="settings-item">
@@ -17,6 +17,8 @@ const Settings = {
             const selects = {
                 'retention-notification-days': 'notification_history_days',
                 'retention-smart-days': 'smart_data_days',
+                'retention-temperature-days': 'temperature_days',
+                'retention-alert-days': 'alert_days',
                 'retention-report-days': 'report_history_days',
                 'retention-audit-days': 'audit_log_days',
                 'retention-addon-days': 'addon_data_days',
@@ -206,7 +206,7 @@ const Temperature = {
                     const driveData = {
                         hostname: server.hostname,
                         serial_number: drive.serial_number,
-                        device_name: drive.device?.name || '/dev/sd?',
+                        device_name: Utils.getDevicePath(drive) || '/dev/sd?',
                         model: drive.model_name || 'Unknown',
                         temperature: temp,
                         status: status,
@@ -369,7 +369,9 @@ const Temperature = {
                 <div class="alert-content">
                     <div class="alert-title">${alert.hostname}</div>
                     <div class="alert-message">${alert.message}</div>
-                    <div class="alert-time">${this.formatTime(alert.created_at)}</div>
+                    <div class="alert-time">${alert.occurrence_count > 1
+                        ? `${alert.occurrence_count}× since ${this.formatTime(alert.created_at)}, last ${this.formatTime(alert.last_occurred)}`
+                        : this.formatTime(alert.created_at)}</div>
                 </div>
                 <button class="btn-icon" onclick="Temperature.acknowledgeAlert(${alert.id})" title="Acknowledge">
                     ${this.icons.check}
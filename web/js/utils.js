@@ -75,7 +75,10 @@ const Utils = {
         return 'healthy';
     },
 
+    // The server resolves _display_name from drives.display_name_order so
+    // every view agrees; the fallbacks cover drives it couldn't name.
     getDriveName(drive) {
+        if (drive._display_name) return drive._display_name;
         if (drive._alias) return drive._alias;
         if (drive.model_name) return drive.model_name;
         if (drive.model_number) return drive.model_number;
@@ -83,8 +86,9 @@ const Utils = {
         if (drive.model_family) return drive.model_family;
         if (drive.device?.model) return drive.device.model;
         
-        if (drive.device?.name) {
-            const name = drive.device.name.replace('/dev/', '');
+        const devicePath = this.getDevicePath(drive);
+        if (devicePath) {
+            const name = devicePath.replace('/dev/disk/by-id/', '').replace('/dev/', '');
             if (drive.serial_number) {
                 return `${name} (${drive.serial_number.slice(-8)})`;
             }
@@ -98,6 +102,12 @@ const Utils = {
         return 'Unknown Drive';
     },
 
+    // Stable /dev/disk/by-id path when the agent reports one, otherwise the
+    // kernel name (/dev/sdX), which can shuffle between reboots.
+    getDevicePath(drive) {
+        return drive.device?.by_id || drive.device?.name || '';
+    },
+
     getDriveType(drive) {
         const isNvme = drive.device?.type?.toLowerCase() === 'nvme' || 
                        drive.device?.protocol === 'NVMe';